@@ -0,0 +1,143 @@
+// Package avroserializer encodes the output of the bserializer pipeline with Avro, using a schema
+// derived from the serializer itself, so Kafka topics and REST APIs share one definition of an
+// event's fields. Messages can be framed in the Confluent wire format and their schemas
+// registered in and fetched from a Confluent-compatible schema registry.
+package avroserializer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// ContentType is the media type of Avro binary payloads.
+const ContentType = "avro/binary"
+
+// wireMagic starts messages in the Confluent wire format, followed by the 4-byte schema id.
+const wireMagic = 0
+
+// AvroSerializer serializes values with the configuration of a BaseSerializer and encodes the
+// output with Avro.
+type AvroSerializer struct {
+	Serializer   *serializer.BaseSerializer
+	Schema       *Schema         // Schema values are written with, and read with without a registry
+	Registry     *RegistryClient // Schema registry; messages use the Confluent wire format when set
+	Subject      string          // Registry subject of Schema, e.g. "orders-value"
+	AutoRegister bool            // Register Schema under Subject on first use instead of looking it up
+}
+
+// New creates an AvroSerializer for s, deriving the schema from the struct type of prototype
+// (see SchemaFor).
+func New(s *serializer.BaseSerializer, prototype interface{}) (*AvroSerializer, error) {
+	if s == nil {
+		s = &serializer.BaseSerializer{}
+	}
+	schema, err := SchemaFor(s, prototype)
+	if err != nil {
+		return nil, err
+	}
+	return &AvroSerializer{Serializer: s, Schema: schema}, nil
+}
+
+// Serialize serializes data and encodes the output with the schema.
+func (a *AvroSerializer) Serialize(data interface{}) ([]byte, error) {
+	return a.SerializeWithContext(context.Background(), data)
+}
+
+// SerializeWithContext is like Serialize, passing ctx to the context-aware pipeline stages and to
+// the schema registry. With a Registry, the schema is registered or looked up under Subject and
+// the payload is prefixed with a zero byte and the big-endian schema id.
+func (a *AvroSerializer) SerializeWithContext(ctx context.Context, data interface{}) ([]byte, error) {
+	result, err := a.Serializer.SerializeWithContext(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := Encode(a.Schema, result)
+	if err != nil {
+		return nil, err
+	}
+	if a.Registry == nil {
+		return encoded, nil
+	}
+
+	var id int
+	if a.AutoRegister {
+		id, err = a.Registry.Register(ctx, a.Subject, a.Schema)
+	} else {
+		id, err = a.Registry.Lookup(ctx, a.Subject, a.Schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 5, 5+len(encoded))
+	header[0] = wireMagic
+	binary.BigEndian.PutUint32(header[1:], uint32(id))
+	return append(header, encoded...), nil
+}
+
+// Deserialize decodes an Avro payload and deserializes it into out.
+func (a *AvroSerializer) Deserialize(data []byte, out interface{}) error {
+	return a.DeserializeWithContext(context.Background(), data, out)
+}
+
+// DeserializeWithContext is like Deserialize, passing ctx to the context-aware pipeline stages
+// and to the schema registry. With a Registry, the payload is decoded with the schema it was
+// written with, fetched by the id in its header, and fields are matched by name, so producers
+// and consumers can evolve their schemas independently.
+func (a *AvroSerializer) DeserializeWithContext(ctx context.Context, data []byte, out interface{}) error {
+	input, err := a.Decode(ctx, data)
+	if err != nil {
+		return err
+	}
+	return a.Serializer.DeserializeWithContext(ctx, input, out)
+}
+
+// Decode decodes an Avro payload into a map without deserializing it.
+func (a *AvroSerializer) Decode(ctx context.Context, data []byte) (map[string]interface{}, error) {
+	schema := a.Schema
+	if a.Registry != nil {
+		if len(data) < 5 || data[0] != wireMagic {
+			return nil, &serializer.SerializationError{Message: "failed to decode Avro: missing schema registry header"}
+		}
+		var err error
+		if schema, err = a.Registry.SchemaByID(ctx, int(binary.BigEndian.Uint32(data[1:5]))); err != nil {
+			return nil, err
+		}
+		data = data[5:]
+	}
+
+	value, err := Decode(schema, data)
+	if err != nil {
+		return nil, err
+	}
+	input, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to decode Avro: expected a record, got %T", value)}
+	}
+	if !a.Serializer.UseNumber {
+		floats(input)
+	}
+	return input, nil
+}
+
+// floats converts the json.Number values of decoded input into float64, as Deserialize receives
+// numbers from encoding/json without UseNumber.
+func floats(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = floats(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = floats(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package avroserializer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+type event struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type eventV2 struct {
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	Where *string `json:"where"`
+}
+
+func TestAvroSerializerWireFormat(t *testing.T) {
+	_, server := newFakeRegistry(t)
+	producer, err := New(nil, event{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	producer.Registry = NewRegistryClient(server.URL)
+	producer.Subject = "events-value"
+
+	if _, err := producer.Serialize(event{ID: 1}); err == nil {
+		t.Fatalf("Serialize() without AutoRegister error = nil, want an unknown schema error")
+	}
+	producer.AutoRegister = true
+	encoded, err := producer.Serialize(event{ID: 1, Name: "a"})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if want := []byte{wireMagic, 0, 0, 0, 1, 2, 2, 'a'}; !bytes.Equal(encoded, want) {
+		t.Errorf("Serialize() = %v, want %v", encoded, want)
+	}
+
+	// A consumer with a newer schema reads the message with the schema it was written with
+	consumer, err := New(nil, eventV2{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	consumer.Registry = NewRegistryClient(server.URL)
+	var got eventV2
+	if err := consumer.Deserialize(encoded, &got); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if want := (eventV2{ID: 1, Name: "a"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Deserialize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAvroSerializerDecode(t *testing.T) {
+	_, server := newFakeRegistry(t)
+	plain, err := New(&serializer.BaseSerializer{UseNumber: true}, event{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	floats, err := New(nil, event{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	registered, err := New(nil, event{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	registered.Registry = NewRegistryClient(server.URL)
+
+	tests := []struct {
+		name    string
+		avro    *AvroSerializer
+		data    []byte
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"numbers kept with UseNumber", plain, []byte{2, 2, 'a'}, map[string]interface{}{"id": json.Number("1"), "name": "a"}, ""},
+		{"numbers as floats", floats, []byte{2, 2, 'a'}, map[string]interface{}{"id": 1.0, "name": "a"}, ""},
+		{"missing header", registered, []byte{2, 2, 'a'}, nil, "missing schema registry header"},
+		{"unknown schema id", registered, []byte{wireMagic, 0, 0, 0, 9, 2, 2, 'a'}, nil, "Schema not found"},
+		{"not a record", &AvroSerializer{Serializer: &serializer.BaseSerializer{}, Schema: &Schema{Type: "string"}}, []byte{2, 'a'}, nil, "expected a record"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.avro.Decode(context.Background(), tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Decode() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
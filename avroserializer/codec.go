@@ -0,0 +1,505 @@
+package avroserializer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// maxDepth bounds the nesting of decoded values, so hostile input can't exhaust the stack.
+const maxDepth = 1000
+
+// Encode encodes a serialized value (maps, lists and scalars) with the Avro binary encoding of
+// schema. Record fields missing from a map are written as null if their type allows it, or as
+// their default; keys without a field are dropped. Null lists and maps, as encoding/json writes
+// nil slices and maps, are written as empty arrays and maps. Bytes and fixed values are base64
+// strings, as encoding/json writes []byte, and timestamps are RFC 3339 strings, as it writes
+// time.Time, or numbers in the unit of their logical type. JSONValue records are written from
+// the value they hold.
+func Encode(schema *Schema, value interface{}) ([]byte, error) {
+	normalized, err := normalize(value)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := appendValue(nil, schema, normalized, "")
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to encode Avro: %v", err), Err: err}
+	}
+	return encoded, nil
+}
+
+// Decode decodes a value written with the Avro binary encoding of schema. Numbers are decoded as
+// json.Number, enums as their symbol, bytes and fixed values as base64 strings, timestamps as
+// RFC 3339 strings in UTC and JSONValue records as the value they hold.
+func Decode(schema *Schema, data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	value, err := d.value(schema, 0)
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to decode Avro at offset %d: %v", d.pos, err)}
+	}
+	if d.pos != len(data) {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to decode Avro: %d trailing bytes", len(data)-d.pos)}
+	}
+	return value, nil
+}
+
+// normalize reduces values set by transformations and hooks to maps, lists and scalars, with
+// numbers as json.Number.
+func normalize(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to encode Avro: %v", err), Err: err}
+	}
+	var normalized interface{}
+	if err := unmarshalJSON(encoded, &normalized); err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to encode Avro: %v", err), Err: err}
+	}
+	return normalized, nil
+}
+
+// unmarshalJSON decodes JSON into value with numbers as json.Number.
+func unmarshalJSON(data []byte, value interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(value)
+}
+
+// appendValue appends the encoding of value to buf. path locates the value in errors.
+func appendValue(buf []byte, schema *Schema, value interface{}, path string) ([]byte, error) {
+	mismatch := func() error {
+		return fmt.Errorf("%s: cannot encode %T as %s", pathName(path), value, schema.Type)
+	}
+
+	if s, ok := value.(string); ok && isTimestamp(schema) {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: '%s' is not an RFC 3339 time", pathName(path), s)
+		}
+		return binary.AppendVarint(buf, timestampValue(schema, t)), nil
+	}
+
+	switch schema.Type {
+	case "null":
+		if value != nil {
+			return nil, mismatch()
+		}
+		return buf, nil
+	case "boolean":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, mismatch()
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case "int", "long":
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, mismatch()
+		}
+		i, err := n.Int64()
+		if err != nil || (schema.Type == "int" && (i < math.MinInt32 || i > math.MaxInt32)) {
+			return nil, fmt.Errorf("%s: %s is not a valid %s", pathName(path), n, schema.Type)
+		}
+		return binary.AppendVarint(buf, i), nil
+	case "float", "double":
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, mismatch()
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s is not a valid %s", pathName(path), n, schema.Type)
+		}
+		if schema.Type == "float" {
+			return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(f))), nil
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, mismatch()
+		}
+		return appendBytes(buf, []byte(s)), nil
+	case "bytes", "fixed":
+		s, ok := value.(string)
+		if !ok {
+			return nil, mismatch()
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid base64 data", pathName(path))
+		}
+		if schema.Type == "fixed" {
+			if len(b) != schema.Size {
+				return nil, fmt.Errorf("%s: expected %d bytes, got %d", pathName(path), schema.Size, len(b))
+			}
+			return append(buf, b...), nil
+		}
+		return appendBytes(buf, b), nil
+	case "enum":
+		s, ok := value.(string)
+		if !ok {
+			return nil, mismatch()
+		}
+		for i, symbol := range schema.Symbols {
+			if symbol == s {
+				return binary.AppendVarint(buf, int64(i)), nil
+			}
+		}
+		return nil, fmt.Errorf("%s: '%s' is not a symbol of %s", pathName(path), s, schema.Name)
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok && value != nil {
+			return nil, mismatch()
+		}
+		if len(list) > 0 {
+			buf = binary.AppendVarint(buf, int64(len(list)))
+			for i, item := range list {
+				var err error
+				if buf, err = appendValue(buf, schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(buf, 0), nil
+	case "map":
+		object, ok := value.(map[string]interface{})
+		if !ok && value != nil {
+			return nil, mismatch()
+		}
+		if len(object) > 0 {
+			buf = binary.AppendVarint(buf, int64(len(object)))
+			for _, key := range sortedKeys(object) {
+				buf = appendBytes(buf, []byte(key))
+				var err error
+				if buf, err = appendValue(buf, schema.Values, object[key], joinPath(path, key)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(buf, 0), nil
+	case "record":
+		if isJSONValue(schema) {
+			return appendValue(buf, schema.Fields[0].Type, value, path)
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, mismatch()
+		}
+		for _, field := range schema.Fields {
+			item, exists := object[field.Name]
+			if !exists && field.HasDefault && !acceptsNull(field.Type) {
+				item = field.Default
+				if normalized, err := normalize(item); err == nil {
+					item = normalized
+				}
+			}
+			var err error
+			if buf, err = appendValue(buf, field.Type, item, joinPath(path, field.Name)); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case "union":
+		for i, branch := range schema.Branches {
+			if matches(branch, value) {
+				return appendValue(binary.AppendVarint(buf, int64(i)), branch, value, path)
+			}
+		}
+		return nil, fmt.Errorf("%s: %T matches no branch of the union", pathName(path), value)
+	default:
+		return nil, fmt.Errorf("%s: unsupported type '%s'", pathName(path), schema.Type)
+	}
+}
+
+// matches reports whether value can be encoded as schema, to choose the branch of a union.
+func matches(schema *Schema, value interface{}) bool {
+	if isJSONValue(schema) {
+		return true
+	}
+	switch v := value.(type) {
+	case nil:
+		return schema.Type == "null"
+	case bool:
+		return schema.Type == "boolean"
+	case json.Number:
+		if schema.Type == "int" || schema.Type == "long" {
+			_, err := v.Int64()
+			return err == nil
+		}
+		return schema.Type == "float" || schema.Type == "double"
+	case string:
+		if isTimestamp(schema) {
+			_, err := time.Parse(time.RFC3339Nano, v)
+			return err == nil
+		}
+		switch schema.Type {
+		case "string", "bytes", "fixed":
+			return true
+		case "enum":
+			for _, symbol := range schema.Symbols {
+				if symbol == v {
+					return true
+				}
+			}
+		}
+		return false
+	case []interface{}:
+		return schema.Type == "array"
+	case map[string]interface{}:
+		return schema.Type == "map" || schema.Type == "record"
+	default:
+		return false
+	}
+}
+
+// acceptsNull reports whether schema is null, a union with null or JSONValue.
+func acceptsNull(schema *Schema) bool {
+	if isJSONValue(schema) {
+		return true
+	}
+	if schema.Type == "union" {
+		for _, branch := range schema.Branches {
+			if branch.Type == "null" {
+				return true
+			}
+		}
+	}
+	return schema.Type == "null"
+}
+
+// appendBytes appends the length and the bytes of b.
+func appendBytes(buf, b []byte) []byte {
+	return append(binary.AppendVarint(buf, int64(len(b))), b...)
+}
+
+// decoder reads values written with the Avro binary encoding.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) value(schema *Schema, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("nesting deeper than %d", maxDepth)
+	}
+
+	switch schema.Type {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := d.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case "int", "long":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if isTimestamp(schema) {
+			return timestampTime(schema, i).Format(time.RFC3339Nano), nil
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	case "float":
+		b, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		f := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		return json.Number(strconv.FormatFloat(float64(f), 'g', -1, 32)), nil
+	case "double":
+		b, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(b))
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	case "string":
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case "bytes":
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "fixed":
+		b, err := d.take(schema.Size)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "enum":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(schema.Symbols)) {
+			return nil, fmt.Errorf("invalid symbol index %d", i)
+		}
+		return schema.Symbols[i], nil
+	case "array":
+		list := []interface{}{}
+		err := d.blocks(func() error {
+			item, err := d.value(schema.Items, depth+1)
+			list = append(list, item)
+			return err
+		})
+		return list, err
+	case "map":
+		object := make(map[string]interface{})
+		err := d.blocks(func() error {
+			key, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			object[string(key)], err = d.value(schema.Values, depth+1)
+			return err
+		})
+		return object, err
+	case "record":
+		if isJSONValue(schema) {
+			return d.value(schema.Fields[0].Type, depth+1)
+		}
+		object := make(map[string]interface{}, len(schema.Fields))
+		for _, field := range schema.Fields {
+			value, err := d.value(field.Type, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			object[field.Name] = value
+		}
+		return object, nil
+	case "union":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(schema.Branches)) {
+			return nil, fmt.Errorf("invalid union branch %d", i)
+		}
+		return d.value(schema.Branches[i], depth+1)
+	default:
+		return nil, fmt.Errorf("unsupported type '%s'", schema.Type)
+	}
+}
+
+// isTimestamp reports whether schema is a long with the TimestampMicros or TimestampMillis
+// logical type.
+func isTimestamp(schema *Schema) bool {
+	return schema.Type == "long" && (schema.LogicalType == TimestampMicros || schema.LogicalType == TimestampMillis)
+}
+
+// timestampValue returns t in the unit of the logical type of schema.
+func timestampValue(schema *Schema, t time.Time) int64 {
+	if schema.LogicalType == TimestampMillis {
+		return t.UnixMilli()
+	}
+	return t.UnixMicro()
+}
+
+// timestampTime returns the time of a value in the unit of the logical type of schema, in UTC.
+func timestampTime(schema *Schema, i int64) time.Time {
+	if schema.LogicalType == TimestampMillis {
+		return time.UnixMilli(i).UTC()
+	}
+	return time.UnixMicro(i).UTC()
+}
+
+// blocks reads the blocks of an array or map, calling item for each of their items.
+func (d *decoder) blocks(item func() error) error {
+	for {
+		count, err := d.long()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// A negative count is followed by the size of the block in bytes
+			count = -count
+			if _, err := d.long(); err != nil {
+				return err
+			}
+		}
+		if count > int64(len(d.data)-d.pos) {
+			return fmt.Errorf("unexpected end of data")
+		}
+		for ; count > 0; count-- {
+			if err := item(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *decoder) long() (int64, error) {
+	i, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("malformed integer")
+	}
+	d.pos += n
+	return i, nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	length, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || length > int64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	return d.take(int(length))
+}
+
+func (d *decoder) take(n int) ([]byte, error) {
+	if n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// sortedKeys returns the keys of an object in sorted order, so equal maps encode the same bytes.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinPath appends key to a dotted path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// pathName names the value at path in errors.
+func pathName(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
@@ -0,0 +1,199 @@
+package avroserializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+func mustParseSchema(t *testing.T, definition string) *Schema {
+	t.Helper()
+	schema, err := ParseSchema([]byte(definition))
+	if err != nil {
+		t.Fatalf("ParseSchema(%s) error = %v", definition, err)
+	}
+	return schema
+}
+
+// jsonValueDefinition is the JSON form of the JSONValue record.
+var jsonValueDefinition = jsonValueSchema().String()
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  interface{}
+		want   []byte
+	}{
+		{"null", `"null"`, nil, nil},
+		{"boolean", `"boolean"`, true, []byte{1}},
+		{"zigzag int", `"int"`, -1, []byte{1}},
+		{"zigzag long", `"long"`, 64, []byte{0x80, 0x01}},
+		{"float", `"float"`, 1.0, []byte{0, 0, 0x80, 0x3f}},
+		{"double", `"double"`, 1.0, []byte{0, 0, 0, 0, 0, 0, 0xf0, 0x3f}},
+		{"string", `"string"`, "ab", []byte{4, 'a', 'b'}},
+		{"bytes from base64", `"bytes"`, "AQI=", []byte{4, 1, 2}},
+		{"fixed", `{"type":"fixed","name":"F","size":2}`, "AQI=", []byte{1, 2}},
+		{"enum", `{"type":"enum","name":"E","symbols":["A","B"]}`, "B", []byte{2}},
+		{"array", `{"type":"array","items":"int"}`, []interface{}{1, 2}, []byte{4, 2, 4, 0}},
+		{"empty array", `{"type":"array","items":"int"}`, []interface{}{}, []byte{0}},
+		{"null array", `{"type":"array","items":"int"}`, nil, []byte{0}},
+		{"map in key order", `{"type":"map","values":"int"}`, map[string]interface{}{"b": 2, "a": 1}, []byte{4, 2, 'a', 2, 2, 'b', 4, 0}},
+		{"union branch", `["null","string"]`, "a", []byte{2, 2, 'a'}},
+		{"union null", `["null","string"]`, nil, []byte{0}},
+		{"first matching union branch", `["null","long","double"]`, 3, []byte{2, 6}},
+		{"record", `{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`, map[string]interface{}{"b": "x", "a": 1, "extra": true}, []byte{2, 2, 'x'}},
+		{"record missing nullable field", `{"type":"record","name":"R","fields":[{"name":"a","type":["null","int"]}]}`, map[string]interface{}{}, []byte{0}},
+		{"record field default", `{"type":"record","name":"R","fields":[{"name":"a","type":"int","default":5}]}`, map[string]interface{}{}, []byte{10}},
+		{"JSONValue", jsonValueDefinition, map[string]interface{}{"a": []interface{}{1}}, []byte{12, 2, 2, 'a', 10, 2, 4, 2, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(mustParseSchema(t, tt.schema), tt.value)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Encode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  interface{}
+		want   string
+	}{
+		{"wrong type", `"boolean"`, "true", "cannot encode string as boolean"},
+		{"int overflow", `"int"`, 1 << 40, "is not a valid int"},
+		{"fraction as long", `"long"`, 1.5, "is not a valid long"},
+		{"bad base64", `"bytes"`, "!", "invalid base64"},
+		{"fixed size", `{"type":"fixed","name":"F","size":4}`, "AQI=", "expected 4 bytes, got 2"},
+		{"unknown symbol", `{"type":"enum","name":"E","symbols":["A"]}`, "B", "'B' is not a symbol of E"},
+		{"no union branch", `["null","string"]`, true, "matches no branch"},
+		{"nested path", `{"type":"record","name":"R","fields":[{"name":"items","type":{"type":"array","items":"int"}}]}`, map[string]interface{}{"items": []interface{}{"x"}}, "items[0]"},
+		{"bad timestamp", `{"type":"long","logicalType":"timestamp-millis"}`, "yesterday", "is not an RFC 3339 time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Encode(mustParseSchema(t, tt.schema), tt.value)
+			var serializationErr *serializer.SerializationError
+			if !errors.As(err, &serializationErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Encode() error = %v, want a serialization error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		data   []byte
+		want   interface{}
+	}{
+		{"long", `"long"`, []byte{0x80, 0x01}, json.Number("64")},
+		{"float", `"float"`, []byte{0, 0, 0xc0, 0x3f}, json.Number("1.5")},
+		{"bytes as base64", `"bytes"`, []byte{4, 1, 2}, "AQI="},
+		{"enum", `{"type":"enum","name":"E","symbols":["A","B"]}`, []byte{2}, "B"},
+		{"array in blocks", `{"type":"array","items":"int"}`, []byte{1, 2, 2, 2, 4, 0}, []interface{}{json.Number("1"), json.Number("2")}},
+		{"union", `["null","string"]`, []byte{2, 2, 'a'}, "a"},
+		{"record", `{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`, []byte{2}, map[string]interface{}{"a": json.Number("1")}},
+		{"JSONValue", jsonValueDefinition, []byte{12, 2, 2, 'a', 8, 0, 0}, map[string]interface{}{"a": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(mustParseSchema(t, tt.schema), tt.data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		data   []byte
+		want   string
+	}{
+		{"truncated", `"string"`, []byte{10, 'a'}, "unexpected end of data"},
+		{"trailing bytes", `"int"`, []byte{2, 0}, "1 trailing bytes"},
+		{"malformed integer", `"long"`, []byte{0x80}, "malformed integer"},
+		{"bad symbol", `{"type":"enum","name":"E","symbols":["A"]}`, []byte{4}, "invalid symbol index 2"},
+		{"bad branch", `["null","int"]`, []byte{6}, "invalid union branch 3"},
+		{"huge block", `{"type":"array","items":"null"}`, []byte{0xfe, 0xff, 0xff, 0xff, 0x0f}, "unexpected end of data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(mustParseSchema(t, tt.schema), tt.data); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Decode() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTooDeep(t *testing.T) {
+	schema := mustParseSchema(t, `{"type":"record","name":"N","fields":[{"name":"next","type":["null","N"]}]}`)
+	data := bytes.Repeat([]byte{2}, maxDepth+1)
+	data = append(data, 0)
+	if _, err := Decode(schema, data); err == nil || !strings.Contains(err.Error(), "nesting deeper") {
+		t.Errorf("Decode() error = %v, want a nesting error", err)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		want       string // JSON form of the parsed schema
+	}{
+		{"primitive", `"string"`, `"string"`},
+		{"wrapped primitive", `{"type":"long"}`, `"long"`},
+		{"logical type", `{"type":"long","logicalType":"timestamp-millis"}`, `{"logicalType":"timestamp-millis","type":"long"}`},
+		{"named reference", `{"type":"record","name":"R","namespace":"a","fields":[{"name":"x","type":{"type":"enum","name":"E","symbols":["A"]}},{"name":"y","type":"E"}]}`,
+			`{"fields":[{"name":"x","type":{"name":"E","namespace":"a","symbols":["A"],"type":"enum"}},{"name":"y","type":"a.E"}],"name":"R","namespace":"a","type":"record"}`},
+		{"error record", `{"type":"error","name":"Oops","fields":[]}`, `{"fields":[],"name":"Oops","type":"record"}`},
+		{"field default", `{"type":"record","name":"R","fields":[{"name":"x","type":"int","default":1}]}`, `{"fields":[{"default":1,"name":"x","type":"int"}],"name":"R","type":"record"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustParseSchema(t, tt.definition).String(); got != tt.want {
+				t.Errorf("ParseSchema() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		want       string
+	}{
+		{"not JSON", `{`, "failed to parse Avro schema"},
+		{"unknown type", `"decimal"`, "unknown type 'decimal'"},
+		{"unknown complex type", `{"type":"tuple"}`, "unknown type 'tuple'"},
+		{"unnamed record", `{"type":"record","fields":[]}`, "record without a name"},
+		{"invalid field", `{"type":"record","name":"R","fields":[1]}`, "invalid field of record 'R'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSchema([]byte(tt.definition)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseSchema() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package avroserializer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// RegistryContentType is the media type of schema registry requests.
+const RegistryContentType = "application/vnd.schemaregistry.v1+json"
+
+// RegistryClient talks to a Confluent-compatible schema registry. Schemas are cached by id and
+// ids by subject and schema, so each is fetched once. It is safe for concurrent use.
+type RegistryClient struct {
+	URL      string       // Base URL of the registry, e.g. "http://localhost:8081"
+	Client   *http.Client // HTTP client; http.DefaultClient when nil
+	Username string       // Basic auth credentials, if the registry requires them
+	Password string
+
+	mu      sync.Mutex
+	schemas map[int]*Schema
+	ids     map[string]int
+}
+
+// NewRegistryClient returns a client of the schema registry at baseURL.
+func NewRegistryClient(baseURL string) *RegistryClient {
+	return &RegistryClient{URL: strings.TrimRight(baseURL, "/")}
+}
+
+// RegistryError is an error response of the schema registry.
+type RegistryError struct {
+	StatusCode int    // HTTP status of the response
+	ErrorCode  int    // Registry error code, e.g. 40401 for an unknown subject
+	Message    string // Message of the registry
+}
+
+func (e *RegistryError) Error() string {
+	return fmt.Sprintf("Schema registry error (%d): %s", e.ErrorCode, e.Message)
+}
+
+// Register registers schema under subject, or finds it if it is already registered, and returns
+// its id. The registry rejects schemas incompatible with the subject's compatibility level.
+func (c *RegistryClient) Register(ctx context.Context, subject string, schema *Schema) (int, error) {
+	return c.schemaID(ctx, "/subjects/"+url.PathEscape(subject)+"/versions", subject, schema)
+}
+
+// Lookup returns the id of schema if it is registered under subject, without registering it.
+func (c *RegistryClient) Lookup(ctx context.Context, subject string, schema *Schema) (int, error) {
+	return c.schemaID(ctx, "/subjects/"+url.PathEscape(subject), subject, schema)
+}
+
+// SchemaByID returns the schema with the given id.
+func (c *RegistryClient) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	c.mu.Lock()
+	schema, ok := c.schemas[id]
+	c.mu.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	var response struct {
+		Schema string `json:"schema"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &response); err != nil {
+		return nil, err
+	}
+	schema, err := ParseSchema([]byte(response.Schema))
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.cacheSchema(id, schema)
+	c.mu.Unlock()
+	return schema, nil
+}
+
+// Latest returns the id and the latest version of the schema registered under subject.
+func (c *RegistryClient) Latest(ctx context.Context, subject string) (int, *Schema, error) {
+	var response struct {
+		ID     int    `json:"id"`
+		Schema string `json:"schema"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &response); err != nil {
+		return 0, nil, err
+	}
+	schema, err := ParseSchema([]byte(response.Schema))
+	if err != nil {
+		return 0, nil, err
+	}
+	c.mu.Lock()
+	c.cacheSchema(response.ID, schema)
+	c.mu.Unlock()
+	return response.ID, schema, nil
+}
+
+// schemaID posts schema to path and returns the id in the response.
+func (c *RegistryClient) schemaID(ctx context.Context, path, subject string, schema *Schema) (int, error) {
+	definition := schema.String()
+	cacheKey := subject + "\x00" + definition
+	c.mu.Lock()
+	id, ok := c.ids[cacheKey]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var response struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, path, map[string]string{"schema": definition}, &response); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	if c.ids == nil {
+		c.ids = make(map[string]int)
+	}
+	c.ids[cacheKey] = response.ID
+	c.cacheSchema(response.ID, schema)
+	c.mu.Unlock()
+	return response.ID, nil
+}
+
+// cacheSchema caches schema under id. c.mu must be held.
+func (c *RegistryClient) cacheSchema(id int, schema *Schema) {
+	if c.schemas == nil {
+		c.schemas = make(map[int]*Schema)
+	}
+	c.schemas[id] = schema
+}
+
+// do sends a request to the registry and decodes the JSON response into out.
+func (c *RegistryClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return &serializer.SerializationError{Message: fmt.Sprintf("failed to encode registry request: %v", err), Err: err}
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, reader)
+	if err != nil {
+		return &serializer.SerializationError{Message: fmt.Sprintf("failed to create registry request: %v", err), Err: err}
+	}
+	req.Header.Set("Accept", RegistryContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", RegistryContentType)
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return &serializer.SerializationError{Message: fmt.Sprintf("failed to reach schema registry: %v", err), Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		registryErr := &RegistryError{StatusCode: resp.StatusCode}
+		var response struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response) == nil {
+			registryErr.ErrorCode, registryErr.Message = response.ErrorCode, response.Message
+		}
+		if registryErr.Message == "" {
+			registryErr.Message = resp.Status
+		}
+		return registryErr
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(out); err != nil {
+		return &serializer.SerializationError{Message: fmt.Sprintf("failed to decode registry response: %v", err), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package avroserializer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry is an in-memory schema registry serving the endpoints RegistryClient uses.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	schemas  []string                  // Schemas by id - 1
+	subjects map[string]map[string]int // Ids by subject and schema
+	requests int
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *httptest.Server) {
+	t.Helper()
+	registry := &fakeRegistry{subjects: make(map[string]map[string]int)}
+	server := httptest.NewServer(registry)
+	t.Cleanup(server.Close)
+	return registry, server
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	w.Header().Set("Content-Type", RegistryContentType)
+	if user, password, ok := r.BasicAuth(); ok && (user != "user" || password != "secret") {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 40101, "message": "Unauthorized"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPost && parts[0] == "subjects":
+		var body struct {
+			Schema string `json:"schema"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		id, ok := f.subjects[parts[1]][body.Schema]
+		if !ok && len(parts) == 3 {
+			f.schemas = append(f.schemas, body.Schema)
+			id = len(f.schemas)
+			if f.subjects[parts[1]] == nil {
+				f.subjects[parts[1]] = make(map[string]int)
+			}
+			f.subjects[parts[1]][body.Schema] = id
+		} else if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 40403, "message": "Schema not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	case r.Method == http.MethodGet && parts[0] == "schemas":
+		for id, schema := range f.schemas {
+			if parts[2] == strconv.Itoa(id+1) {
+				json.NewEncoder(w).Encode(map[string]interface{}{"schema": schema})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 40403, "message": "Schema not found"})
+	case r.Method == http.MethodGet && parts[0] == "subjects":
+		latest := 0
+		for _, id := range f.subjects[parts[1]] {
+			if id > latest {
+				latest = id
+			}
+		}
+		if latest == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 40401, "message": "Subject not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": latest, "schema": f.schemas[latest-1]})
+	default:
+		w.WriteHeader(http.StatusBadGateway)
+	}
+}
+
+func (f *fakeRegistry) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func TestRegistryClient(t *testing.T) {
+	registry, server := newFakeRegistry(t)
+	client := NewRegistryClient(server.URL + "/")
+	ctx := context.Background()
+	schema := mustParseSchema(t, `{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`)
+
+	if _, err := client.Lookup(ctx, "r-value", schema); err == nil {
+		t.Fatalf("Lookup() of an unregistered schema error = nil, want an error")
+	}
+	id, err := client.Register(ctx, "r-value", schema)
+	if err != nil || id != 1 {
+		t.Fatalf("Register() = %d, %v, want 1", id, err)
+	}
+	requests := registry.requestCount()
+
+	tests := []struct {
+		name string
+		call func() (int, error)
+	}{
+		{"cached Register", func() (int, error) { return client.Register(ctx, "r-value", schema) }},
+		{"cached SchemaByID", func() (int, error) {
+			got, err := client.SchemaByID(ctx, 1)
+			if err == nil && got.String() != schema.String() {
+				t.Errorf("SchemaByID() = %s, want %s", got, schema)
+			}
+			return 1, err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if id, err := tt.call(); err != nil || id != 1 {
+				t.Errorf("%s = %d, %v, want 1", tt.name, id, err)
+			}
+			if got := registry.requestCount(); got != requests {
+				t.Errorf("%s sent %d requests, want none", tt.name, got-requests)
+			}
+		})
+	}
+
+	other := NewRegistryClient(server.URL)
+	if id, err := other.Lookup(ctx, "r-value", schema); err != nil || id != 1 {
+		t.Errorf("Lookup() = %d, %v, want 1", id, err)
+	}
+	got, err := other.SchemaByID(ctx, 1)
+	if err != nil || got.String() != schema.String() {
+		t.Errorf("SchemaByID() = %v, %v, want %s", got, err, schema)
+	}
+	id, latest, err := other.Latest(ctx, "r-value")
+	if err != nil || id != 1 || latest.String() != schema.String() {
+		t.Errorf("Latest() = %d, %v, %v, want 1, %s", id, latest, err, schema)
+	}
+}
+
+func TestRegistryClientErrors(t *testing.T) {
+	_, server := newFakeRegistry(t)
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		call       func(client *RegistryClient) error
+		wantStatus int
+		wantCode   int
+	}{
+		{"unknown schema id", func(client *RegistryClient) error {
+			_, err := client.SchemaByID(ctx, 99)
+			return err
+		}, http.StatusNotFound, 40403},
+		{"unknown subject", func(client *RegistryClient) error {
+			_, _, err := client.Latest(ctx, "missing")
+			return err
+		}, http.StatusNotFound, 40401},
+		{"bad credentials", func(client *RegistryClient) error {
+			client.Username, client.Password = "user", "wrong"
+			_, err := client.Register(ctx, "r-value", &Schema{Type: "string"})
+			return err
+		}, http.StatusUnauthorized, 40101},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(NewRegistryClient(server.URL))
+			var registryErr *RegistryError
+			if !errors.As(err, &registryErr) || registryErr.StatusCode != tt.wantStatus || registryErr.ErrorCode != tt.wantCode {
+				t.Errorf("error = %v, want a RegistryError with status %d and code %d", err, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
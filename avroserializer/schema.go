@@ -0,0 +1,467 @@
+package avroserializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// Logical types of longs holding instants, as the microseconds or milliseconds since the Unix
+// epoch. SchemaFor writes time.Time fields as TimestampMicros.
+const (
+	TimestampMicros = "timestamp-micros"
+	TimestampMillis = "timestamp-millis"
+)
+
+// JSONValueName is the full name of the recursive record SchemaFor uses for fields without a fixed
+// type, such as interfaces and values with custom marshalers. Its only field, "value", is a union
+// of null, boolean, long, double, string, and arrays and maps of JSONValue. Encode and Decode
+// write and read the value directly, without the record around it.
+const JSONValueName = "bserializer.JSONValue"
+
+// Schema is an Avro schema. Primitive types only set Type; the other fields apply to the complex
+// type named by Type. Unions have the Type "union".
+type Schema struct {
+	Type        string    // "null", "boolean", "int", "long", "float", "double", "bytes", "string", "record", "enum", "array", "map", "fixed" or "union"
+	Name        string    // Name of records, enums and fixed
+	Namespace   string    // Namespace of records, enums and fixed
+	Doc         string    // Documentation of records and enums
+	Fields      []*Field  // Fields of records
+	Symbols     []string  // Symbols of enums
+	Items       *Schema   // Element schema of arrays
+	Values      *Schema   // Value schema of maps
+	Branches    []*Schema // Branches of unions
+	Size        int       // Size in bytes of fixed
+	LogicalType string    // Logical type annotating the schema, if any, e.g. TimestampMicros
+}
+
+// Field is a field of a record schema.
+type Field struct {
+	Name       string
+	Doc        string
+	Type       *Schema
+	Default    interface{} // Default value; only used when HasDefault is set
+	HasDefault bool
+}
+
+// SchemaFor derives the Avro schema (as of the Avro 1.11 specification) of the serialized form of
+// the struct type of prototype from the serializer's JSON Schema, so it follows the same field
+// selection, key naming and enums. Integers become longs, floats doubles, time.Time longs with
+// the TimestampMicros logical type and []byte bytes. Pointer and omitempty fields are unions with
+// null that default to null; the other fields default to the zero value of their type, which
+// keeps the schema evolvable under the BACKWARD and FORWARD compatibility rules of schema
+// registries. Nested structs become nested records named after the path to them, and fields
+// without a fixed type become JSONValue records. Keys must be valid Avro names, e.g. by using
+// NamingSnakeCase or NamingCamelCase.
+func SchemaFor(s *serializer.BaseSerializer, prototype interface{}) (*Schema, error) {
+	jsonSchema, err := s.JSONSchema(prototype)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := jsonSchema["title"].(string)
+	return recordSchema(s, name, jsonSchema, true)
+}
+
+// ParseSchema parses an Avro schema in its JSON form, such as the schemas of a schema registry.
+func ParseSchema(data []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to parse Avro schema: %v", err), Err: err}
+	}
+	schema, err := parseSchema(raw, "", make(map[string]*Schema))
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to parse Avro schema: %v", err), Err: err}
+	}
+	return schema, nil
+}
+
+// String returns the JSON form of the schema.
+func (s *Schema) String() string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
+
+// MarshalJSON writes the JSON form of the schema. Named types are written in full the first time
+// they appear and by name afterwards.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.jsonValue(make(map[string]bool)))
+}
+
+// FullName returns the name of a named type qualified by its namespace.
+func (s *Schema) FullName() string {
+	if s.Namespace == "" || strings.Contains(s.Name, ".") {
+		return s.Name
+	}
+	return s.Namespace + "." + s.Name
+}
+
+func (s *Schema) jsonValue(defined map[string]bool) interface{} {
+	switch s.Type {
+	case "union":
+		branches := make([]interface{}, len(s.Branches))
+		for i, branch := range s.Branches {
+			branches[i] = branch.jsonValue(defined)
+		}
+		return branches
+	case "record", "enum", "fixed":
+		if defined[s.FullName()] {
+			return s.FullName()
+		}
+		defined[s.FullName()] = true
+	}
+
+	value := map[string]interface{}{"type": s.Type}
+	if s.Name != "" {
+		value["name"] = s.Name
+	}
+	if s.Namespace != "" {
+		value["namespace"] = s.Namespace
+	}
+	if s.Doc != "" {
+		value["doc"] = s.Doc
+	}
+	if s.LogicalType != "" {
+		value["logicalType"] = s.LogicalType
+	}
+	switch s.Type {
+	case "record":
+		fields := make([]interface{}, len(s.Fields))
+		for i, field := range s.Fields {
+			f := map[string]interface{}{"name": field.Name, "type": field.Type.jsonValue(defined)}
+			if field.Doc != "" {
+				f["doc"] = field.Doc
+			}
+			if field.HasDefault {
+				f["default"] = field.Default
+			}
+			fields[i] = f
+		}
+		value["fields"] = fields
+	case "enum":
+		value["symbols"] = s.Symbols
+	case "array":
+		value["items"] = s.Items.jsonValue(defined)
+	case "map":
+		value["values"] = s.Values.jsonValue(defined)
+	case "fixed":
+		value["size"] = s.Size
+	default:
+		if len(value) == 1 {
+			return s.Type
+		}
+	}
+	return value
+}
+
+// recordSchema converts a JSON Schema object with properties into a record. Top-level properties
+// are ordered by x-order, nested ones by name.
+func recordSchema(s *serializer.BaseSerializer, name string, jsonSchema map[string]interface{}, topLevel bool) (*Schema, error) {
+	if !validName(name) {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("'%s' is not a valid Avro name", name)}
+	}
+	properties, _ := jsonSchema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if topLevel {
+		order := func(key string) int {
+			position, _ := properties[key].(map[string]interface{})["x-order"].(int)
+			return position
+		}
+		sort.SliceStable(keys, func(i, j int) bool { return order(keys[i]) < order(keys[j]) })
+	}
+
+	record := &Schema{Type: "record", Name: name}
+	record.Doc, _ = jsonSchema["description"].(string)
+	for _, key := range keys {
+		property := properties[key].(map[string]interface{})
+		fieldName := key
+		if !topLevel {
+			// Nested keys are renamed like top-level ones
+			fieldName = serializer.ConvertKey(key, s.KeyNaming, s.Acronyms)
+		}
+		if !validName(fieldName) {
+			return nil, &serializer.SerializationError{Message: fmt.Sprintf("'%s' is not a valid Avro field name; choose a KeyNaming producing valid names", fieldName)}
+		}
+		typ, err := propertySchema(s, name+exportedName(fieldName), property)
+		if err != nil {
+			return nil, err
+		}
+		if property["x-nullable"] == true || property["x-omitempty"] == true {
+			typ = nullable(typ)
+		}
+		field := &Field{Name: fieldName, Type: typ, Default: zeroValue(typ), HasDefault: true}
+		field.Doc, _ = property["description"].(string)
+		record.Fields = append(record.Fields, field)
+	}
+	return record, nil
+}
+
+// propertySchema converts a JSON Schema property into an Avro schema. name names the records and
+// enums it defines.
+func propertySchema(s *serializer.BaseSerializer, name string, property map[string]interface{}) (*Schema, error) {
+	if enum, ok := property["enum"].([]interface{}); ok {
+		symbols := make([]string, 0, len(enum))
+		for _, value := range enum {
+			symbol, ok := value.(string)
+			if !ok || !validName(symbol) {
+				return jsonValueSchema(), nil
+			}
+			symbols = append(symbols, symbol)
+		}
+		return &Schema{Type: "enum", Name: name, Symbols: symbols}, nil
+	}
+
+	switch property["type"] {
+	case "boolean":
+		return &Schema{Type: "boolean"}, nil
+	case "integer":
+		return &Schema{Type: "long"}, nil
+	case "number":
+		return &Schema{Type: "double"}, nil
+	case "string":
+		switch {
+		case property["format"] == "date-time":
+			return &Schema{Type: "long", LogicalType: TimestampMicros}, nil
+		case property["contentEncoding"] == "base64":
+			return &Schema{Type: "bytes"}, nil
+		}
+		return &Schema{Type: "string"}, nil
+	case "array":
+		items, _ := property["items"].(map[string]interface{})
+		itemSchema, err := elementSchema(s, name+"Item", items)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: itemSchema}, nil
+	case "object":
+		if _, ok := property["properties"]; ok {
+			return recordSchema(s, name, property, false)
+		}
+		if values, ok := property["additionalProperties"].(map[string]interface{}); ok {
+			valueSchema, err := elementSchema(s, name+"Value", values)
+			if err != nil {
+				return nil, err
+			}
+			return &Schema{Type: "map", Values: valueSchema}, nil
+		}
+	}
+	return jsonValueSchema(), nil
+}
+
+// elementSchema converts the JSON Schema of the items of a list or map, which are nullable when
+// they are pointers.
+func elementSchema(s *serializer.BaseSerializer, name string, property map[string]interface{}) (*Schema, error) {
+	schema, err := propertySchema(s, name, property)
+	if err != nil {
+		return nil, err
+	}
+	if property["x-nullable"] == true {
+		schema = nullable(schema)
+	}
+	return schema, nil
+}
+
+// nullable returns the union of null and schema, or schema itself when it already accepts null.
+func nullable(schema *Schema) *Schema {
+	if acceptsNull(schema) {
+		return schema
+	}
+	return &Schema{Type: "union", Branches: []*Schema{{Type: "null"}, schema}}
+}
+
+// jsonValueSchema returns the JSONValue record.
+func jsonValueSchema() *Schema {
+	record := &Schema{Type: "record", Name: "JSONValue", Namespace: "bserializer"}
+	value := &Schema{Type: "union", Branches: []*Schema{
+		{Type: "null"}, {Type: "boolean"}, {Type: "long"}, {Type: "double"}, {Type: "string"},
+		{Type: "array", Items: record}, {Type: "map", Values: record},
+	}}
+	record.Fields = []*Field{{Name: "value", Type: value, HasDefault: true}}
+	return record
+}
+
+// isJSONValue reports whether schema is the JSONValue record.
+func isJSONValue(schema *Schema) bool {
+	return schema.Type == "record" && schema.FullName() == JSONValueName
+}
+
+// zeroValue returns the default of fields of type schema: null for unions starting with null and
+// JSONValue, and the zero value of the type otherwise, written as Avro writes defaults in JSON.
+func zeroValue(schema *Schema) interface{} {
+	switch schema.Type {
+	case "boolean":
+		return false
+	case "int", "long", "float", "double":
+		return 0
+	case "string", "bytes":
+		return ""
+	case "enum":
+		if len(schema.Symbols) == 0 {
+			return nil
+		}
+		return schema.Symbols[0]
+	case "array":
+		return []interface{}{}
+	case "map":
+		return map[string]interface{}{}
+	case "record":
+		object := make(map[string]interface{}, len(schema.Fields))
+		for _, field := range schema.Fields {
+			object[field.Name] = field.Default
+		}
+		return object
+	case "union":
+		return zeroValue(schema.Branches[0])
+	default:
+		return nil
+	}
+}
+
+// exportedName capitalizes the first letter of name and drops underscores, for nested type names.
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// validName reports whether name is a valid Avro name: a letter or underscore followed by
+// letters, digits and underscores.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseSchema converts the decoded JSON form of a schema. named holds the named types defined so
+// far, by full name.
+func parseSchema(raw interface{}, namespace string, named map[string]*Schema) (*Schema, error) {
+	switch v := raw.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &Schema{Type: v}, nil
+		}
+		if schema, ok := named[v]; ok {
+			return schema, nil
+		}
+		if schema, ok := named[namespace+"."+v]; ok && namespace != "" {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("unknown type '%s'", v)
+	case []interface{}:
+		union := &Schema{Type: "union"}
+		for _, branch := range v {
+			schema, err := parseSchema(branch, namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			union.Branches = append(union.Branches, schema)
+		}
+		return union, nil
+	case map[string]interface{}:
+		return parseComplexSchema(v, namespace, named)
+	default:
+		return nil, fmt.Errorf("invalid schema %v", raw)
+	}
+}
+
+func parseComplexSchema(raw map[string]interface{}, namespace string, named map[string]*Schema) (*Schema, error) {
+	typ, ok := raw["type"].(string)
+	if !ok {
+		// {"type": [...]} and {"type": {...}} wrap another schema
+		return parseSchema(raw["type"], namespace, named)
+	}
+	schema := &Schema{Type: typ}
+	schema.LogicalType, _ = raw["logicalType"].(string)
+	schema.Doc, _ = raw["doc"].(string)
+
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		schema.Name, _ = raw["name"].(string)
+		schema.Namespace, _ = raw["namespace"].(string)
+		if schema.Namespace == "" && !strings.Contains(schema.Name, ".") {
+			schema.Namespace = namespace
+		}
+		if schema.Name == "" {
+			return nil, fmt.Errorf("%s without a name", typ)
+		}
+		named[schema.FullName()] = schema
+	}
+
+	switch typ {
+	case "record", "error":
+		schema.Type = "record"
+		fields, _ := raw["fields"].([]interface{})
+		childNamespace := ""
+		if i := strings.LastIndex(schema.FullName(), "."); i >= 0 {
+			childNamespace = schema.FullName()[:i]
+		}
+		for _, rawField := range fields {
+			f, ok := rawField.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid field of record '%s'", schema.Name)
+			}
+			field := &Field{}
+			field.Name, _ = f["name"].(string)
+			field.Doc, _ = f["doc"].(string)
+			field.Default, field.HasDefault = f["default"]
+			var err error
+			if field.Type, err = parseSchema(f["type"], childNamespace, named); err != nil {
+				return nil, err
+			}
+			schema.Fields = append(schema.Fields, field)
+		}
+	case "enum":
+		symbols, _ := raw["symbols"].([]interface{})
+		for _, symbol := range symbols {
+			name, _ := symbol.(string)
+			schema.Symbols = append(schema.Symbols, name)
+		}
+	case "array":
+		items, err := parseSchema(raw["items"], namespace, named)
+		if err != nil {
+			return nil, err
+		}
+		schema.Items = items
+	case "map":
+		values, err := parseSchema(raw["values"], namespace, named)
+		if err != nil {
+			return nil, err
+		}
+		schema.Values = values
+	case "fixed":
+		size, _ := raw["size"].(float64)
+		schema.Size = int(size)
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", typ)
+	}
+	return schema, nil
+}
@@ -0,0 +1,155 @@
+package avroserializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+type line struct {
+	SKU string `json:"sku"`
+}
+
+type order struct {
+	ID        int64                  `json:"id"`
+	Price     float64                `json:"price"`
+	Paid      bool                   `json:"paid"`
+	Note      *string                `json:"note"`
+	Coupon    string                 `json:"coupon,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	ShippedAt *time.Time             `json:"shipped_at"`
+	Receipt   []byte                 `json:"receipt"`
+	Extra     interface{}            `json:"extra"`
+	Lines     []*line                `json:"lines"`
+	Tags      []string               `json:"tags"`
+	Meta      map[string]interface{} `json:"meta"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := SchemaFor(&serializer.BaseSerializer{}, order{})
+	if err != nil {
+		t.Fatalf("SchemaFor() error = %v", err)
+	}
+	fields := make(map[string]*Field)
+	for _, field := range schema.Fields {
+		fields[field.Name] = field
+	}
+
+	tests := []struct {
+		field       string
+		want        string // JSON form of the field's type
+		wantDefault interface{}
+	}{
+		{"id", `"long"`, 0},
+		{"price", `"double"`, 0},
+		{"paid", `"boolean"`, false},
+		{"note", `["null","string"]`, nil},
+		{"coupon", `["null","string"]`, nil},
+		{"created_at", `{"logicalType":"timestamp-micros","type":"long"}`, 0},
+		{"shipped_at", `["null",{"logicalType":"timestamp-micros","type":"long"}]`, nil},
+		{"receipt", `"bytes"`, ""},
+		{"lines", `{"items":["null",{"fields":[{"default":"","name":"sku","type":"string"}],"name":"orderLinesItem","type":"record"}],"type":"array"}`, []interface{}{}},
+		{"tags", `{"items":"string","type":"array"}`, []interface{}{}},
+		{"meta", `{"type":"map","values":"bserializer.JSONValue"}`, map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			field, ok := fields[tt.field]
+			if !ok {
+				t.Fatalf("SchemaFor() has no field %s", tt.field)
+			}
+			// extra defines JSONValue first, so later fields refer to it by name
+			if got := string(mustMarshal(t, field.Type.jsonValue(map[string]bool{JSONValueName: true}))); got != tt.want {
+				t.Errorf("type = %s, want %s", got, tt.want)
+			}
+			if !field.HasDefault || !reflect.DeepEqual(field.Default, tt.wantDefault) {
+				t.Errorf("default = %#v (%v), want %#v", field.Default, field.HasDefault, tt.wantDefault)
+			}
+		})
+	}
+
+	if extra := fields["extra"].Type; !isJSONValue(extra) || !acceptsNull(extra) {
+		t.Errorf("extra = %s, want the JSONValue record", extra)
+	}
+	parsed, err := ParseSchema([]byte(schema.String()))
+	if err != nil {
+		t.Fatalf("ParseSchema() error = %v", err)
+	}
+	if parsed.String() != schema.String() {
+		t.Errorf("ParseSchema(SchemaFor()) = %s, want %s", parsed, schema)
+	}
+}
+
+func TestSchemaForRoundTrip(t *testing.T) {
+	avro, err := New(&serializer.BaseSerializer{}, order{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	note := "fragile"
+	shipped := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	in := order{
+		ID:        7,
+		Price:     19.5,
+		Note:      &note,
+		CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC),
+		ShippedAt: &shipped,
+		Receipt:   []byte{1, 2, 3},
+		Extra:     map[string]interface{}{"gift": true, "codes": []interface{}{"a", 1.5, nil}},
+		Lines:     []*line{{SKU: "a"}, nil},
+		Tags:      []string{"x"},
+		Meta:      map[string]interface{}{"n": 1.0},
+	}
+	encoded, err := avro.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	var out order
+	if err := avro.Deserialize(encoded, &out); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Deserialize() = %+v, want %+v", out, in)
+	}
+}
+
+func TestTimestampLogicalTypes(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	tests := []struct {
+		logicalType string
+		value       interface{}
+		wantLong    int64
+		wantTime    string
+	}{
+		{TimestampMicros, at.Format(time.RFC3339Nano), at.UnixMicro(), "2024-05-01T12:30:00.123456Z"},
+		{TimestampMillis, at.Format(time.RFC3339Nano), at.UnixMilli(), "2024-05-01T12:30:00.123Z"},
+		{TimestampMillis, json.Number("0"), 0, "1970-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.logicalType, func(t *testing.T) {
+			schema := &Schema{Type: "long", LogicalType: tt.logicalType}
+			encoded, err := Encode(schema, tt.value)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if plain, err := Decode(&Schema{Type: "long"}, encoded); err != nil || plain != json.Number(strconv.FormatInt(tt.wantLong, 10)) {
+				t.Errorf("encoded long = %v (%v), want %d", plain, err, tt.wantLong)
+			}
+			if decoded, err := Decode(schema, encoded); err != nil || decoded != tt.wantTime {
+				t.Errorf("Decode() = %v (%v), want %s", decoded, err, tt.wantTime)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
@@ -0,0 +1,81 @@
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// User is a small flat struct, the most common payload.
+type User struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Email  string  `json:"email"`
+	Score  float64 `json:"score"`
+	Active bool    `json:"active"`
+}
+
+// Profile is a struct with nested values, which takes the general conversion path.
+type Profile struct {
+	User    User              `json:"user"`
+	Tags    []string          `json:"tags"`
+	Links   map[string]string `json:"links"`
+	Updated time.Time         `json:"updated"`
+}
+
+var (
+	profile = Profile{
+		User:    user,
+		Tags:    []string{"admin", "beta"},
+		Links:   map[string]string{"self": "/users/1"},
+		Updated: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	user      = User{ID: 1, Name: "Ana", Email: "ana@example.com", Score: 9.5, Active: true}
+	userInput = map[string]interface{}{"id": 1.0, "name": "Ana", "email": "ana@example.com", "score": 9.5, "active": true}
+)
+
+// BenchmarkSerialize measures Serialize on User, a flat struct converted by the fast path.
+func BenchmarkSerialize(b *testing.B) {
+	s := &serializer.BaseSerializer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Serialize(user); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSerializeNested measures Serialize on Profile.
+func BenchmarkSerializeNested(b *testing.B) {
+	s := &serializer.BaseSerializer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Serialize(profile); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSerializeJSON measures SerializeAs with FormatJSON on User.
+func BenchmarkSerializeJSON(b *testing.B) {
+	s := &serializer.BaseSerializer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.SerializeAs(user, serializer.FormatJSON); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDeserialize measures Deserialize into User.
+func BenchmarkDeserialize(b *testing.B) {
+	s := &serializer.BaseSerializer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var out User
+		if err := s.Deserialize(userInput, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
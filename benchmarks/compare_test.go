@@ -0,0 +1,241 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"github.com/mitchellh/mapstructure"
+)
+
+// The comparisons measure the serializer against plain encoding/json and mapstructure on structs
+// of three sizes. Serialize is compared with json.Marshal and with the json.Marshal and
+// json.Unmarshal round trip into a map that Serialize replaces. Deserialize is compared with the
+// round trip from a map into a struct, and with mapstructure, which decodes maps without JSON.
+
+// Order is a medium-sized struct: scalar fields, a nested object, a list of objects and a time.
+type Order struct {
+	ID        int         `json:"id"`
+	Number    string      `json:"number"`
+	Status    string      `json:"status"`
+	Customer  User        `json:"customer"`
+	Currency  string      `json:"currency"`
+	Subtotal  float64     `json:"subtotal"`
+	Tax       float64     `json:"tax"`
+	Total     float64     `json:"total"`
+	Paid      bool        `json:"paid"`
+	Notes     string      `json:"notes"`
+	Shipping  Address     `json:"shipping"`
+	Items     []OrderItem `json:"items"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Address is the shipping address of an Order.
+type Address struct {
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	Zip     string `json:"zip"`
+	Country string `json:"country"`
+}
+
+// OrderItem is a line of an Order.
+type OrderItem struct {
+	SKU      string  `json:"sku"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// Catalog is a large struct holding a page of orders.
+type Catalog struct {
+	Page   int     `json:"page"`
+	Total  int     `json:"total"`
+	Orders []Order `json:"orders"`
+}
+
+var (
+	order = Order{
+		ID: 1001, Number: "SO-1001", Status: "shipped", Customer: user, Currency: "EUR",
+		Subtotal: 90, Tax: 18.9, Total: 108.9, Paid: true, Notes: "Leave at the door",
+		Shipping:  Address{Street: "Gran Vía 1", City: "Madrid", Zip: "28013", Country: "ES"},
+		Items:     orderItems(5),
+		CreatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	catalog = Catalog{Page: 1, Total: 50, Orders: orders(50)}
+)
+
+func BenchmarkSerializeSmall(b *testing.B) {
+	benchmarkSerialize(b, user)
+}
+
+func BenchmarkSerializeMedium(b *testing.B) {
+	benchmarkSerialize(b, order)
+}
+
+func BenchmarkSerializeLarge(b *testing.B) {
+	benchmarkSerialize(b, catalog)
+}
+
+func BenchmarkDeserializeSmall(b *testing.B) {
+	benchmarkDeserialize(b, user, func() interface{} { return new(User) })
+}
+
+func BenchmarkDeserializeMedium(b *testing.B) {
+	benchmarkDeserialize(b, order, func() interface{} { return new(Order) })
+}
+
+func BenchmarkDeserializeLarge(b *testing.B) {
+	benchmarkDeserialize(b, catalog, func() interface{} { return new(Catalog) })
+}
+
+// benchmarkSerialize compares Serialize on v with json.Marshal, and with the conversion of v into
+// a map through json.Marshal and json.Unmarshal.
+func benchmarkSerialize(b *testing.B, v interface{}) {
+	b.Run("bserializer", func(b *testing.B) {
+		s := &serializer.BaseSerializer{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := s.Serialize(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json-marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json-map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := json.Marshal(v)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal(data, &m); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// benchmarkDeserialize compares Deserialize of the map form of v into a new value with the
+// conversion through json.Marshal and json.Unmarshal, and with mapstructure.
+func benchmarkDeserialize(b *testing.B, v interface{}, newValue func() interface{}) {
+	input := jsonMap(v)
+	b.Run("bserializer", func(b *testing.B) {
+		s := &serializer.BaseSerializer{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := s.Deserialize(input, newValue()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json-map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := json.Marshal(input)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := json.Unmarshal(data, newValue()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("mapstructure", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				TagName:    "json",
+				DecodeHook: mapstructure.StringToTimeHookFunc(time.RFC3339),
+				Result:     newValue(),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := decoder.Decode(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestComparisonsDecodeAlike checks that the compared decoders produce the same values, so that
+// the comparisons measure the same work.
+func TestComparisonsDecodeAlike(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		newValue func() interface{}
+	}{
+		{"Small", user, func() interface{} { return new(User) }},
+		{"Medium", order, func() interface{} { return new(Order) }},
+		{"Large", catalog, func() interface{} { return new(Catalog) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := jsonMap(tt.value)
+			deserialized, decoded := tt.newValue(), tt.newValue()
+			if err := (&serializer.BaseSerializer{}).Deserialize(input, deserialized); err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				TagName:    "json",
+				DecodeHook: mapstructure.StringToTimeHookFunc(time.RFC3339),
+				Result:     decoded,
+			})
+			if err == nil {
+				err = decoder.Decode(input)
+			}
+			if err != nil {
+				t.Fatalf("mapstructure error = %v", err)
+			}
+			want, _ := json.Marshal(tt.value)
+			for name, got := range map[string]interface{}{"Deserialize": deserialized, "mapstructure": decoded} {
+				if encoded, _ := json.Marshal(got); string(encoded) != string(want) {
+					t.Errorf("%s() = %s, want %s", name, encoded, want)
+				}
+			}
+		})
+	}
+}
+
+// jsonMap returns v as a map, as decoded from its JSON encoding.
+func jsonMap(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// orderItems returns n order lines.
+func orderItems(n int) []OrderItem {
+	items := make([]OrderItem, n)
+	for i := range items {
+		items[i] = OrderItem{SKU: fmt.Sprintf("SKU-%03d", i), Name: fmt.Sprintf("Item %d", i), Quantity: i + 1, Price: 18}
+	}
+	return items
+}
+
+// orders returns n copies of order with distinct IDs.
+func orders(n int) []Order {
+	list := make([]Order, n)
+	for i := range list {
+		list[i] = order
+		list[i].ID = order.ID + i
+	}
+	return list
+}
@@ -0,0 +1,138 @@
+// Package benchmarks measures the speed and allocations of the serializer, on its own and against
+// encoding/json and mapstructure, with benchmarks run by go test -bench. Its functions read the
+// results of such runs and compare them with a baseline to catch performance regressions, as
+// done by cmd/bserializer-bench.
+package benchmarks
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Result is the measurement of a benchmark, in the units of go test -bench -benchmem.
+type Result struct {
+	Name        string  `json:"name"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+// Regression is a metric of a benchmark that got worse than its baseline.
+type Regression struct {
+	Name     string  // Benchmark name
+	Metric   string  // "ns/op", "B/op" or "allocs/op"
+	Baseline float64 // Value of the baseline
+	Current  float64 // Value of the current run
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s went from %.0f to %.0f (%+.1f%%)", r.Name, r.Metric, r.Baseline, r.Current, (r.Current/r.Baseline-1)*100)
+}
+
+// ParseResults reads the results printed by go test -bench -benchmem. Benchmark names lose their
+// "Benchmark" prefix and GOMAXPROCS suffix, e.g. "BenchmarkSerializeSmall/json-map-8" becomes
+// "SerializeSmall/json-map". Other lines are skipped.
+func ParseResults(r io.Reader) ([]Result, error) {
+	var results []Result
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue // Not a result line, e.g. the output of a failed benchmark
+		}
+
+		result := Result{Name: benchmarkName(fields[0])}
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q of %s", fields[i], fields[0])
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				result.NsPerOp = value
+			case "B/op":
+				result.BytesPerOp = int64(value)
+			case "allocs/op":
+				result.AllocsPerOp = int64(value)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, scanner.Err()
+}
+
+// benchmarkName strips the "Benchmark" prefix and the GOMAXPROCS suffix from the name of a
+// benchmark as printed by go test.
+func benchmarkName(name string) string {
+	name = strings.TrimPrefix(name, "Benchmark")
+	if i := strings.LastIndexByte(name, '-'); i >= 0 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			name = name[:i]
+		}
+	}
+	return name
+}
+
+// Compare returns the regressions of current against baseline: ns/op and B/op more than
+// tolerance above the baseline (0.1 for 10%), and any increase of allocs/op, which doesn't vary
+// between runs. Benchmarks missing from either list are skipped.
+func Compare(baseline, current []Result, tolerance float64) []Regression {
+	previous := make(map[string]Result, len(baseline))
+	for _, result := range baseline {
+		previous[result.Name] = result
+	}
+	var regressions []Regression
+	for _, result := range current {
+		base, ok := previous[result.Name]
+		if !ok {
+			continue
+		}
+		if base.NsPerOp > 0 && result.NsPerOp > base.NsPerOp*(1+tolerance) {
+			regressions = append(regressions, Regression{result.Name, "ns/op", base.NsPerOp, result.NsPerOp})
+		}
+		if base.BytesPerOp > 0 && float64(result.BytesPerOp) > float64(base.BytesPerOp)*(1+tolerance) {
+			regressions = append(regressions, Regression{result.Name, "B/op", float64(base.BytesPerOp), float64(result.BytesPerOp)})
+		}
+		if result.AllocsPerOp > base.AllocsPerOp {
+			regressions = append(regressions, Regression{result.Name, "allocs/op", float64(base.AllocsPerOp), float64(result.AllocsPerOp)})
+		}
+	}
+	return regressions
+}
+
+// WriteResults writes results to w, in the format of go test -bench -benchmem.
+func WriteResults(w io.Writer, results []Result) {
+	for _, result := range results {
+		fmt.Fprintf(w, "%-50s %12.0f ns/op %10d B/op %8d allocs/op\n", "Benchmark"+result.Name, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp)
+	}
+}
+
+// SaveResults writes results to a JSON file, to be loaded as a baseline with LoadResults.
+func SaveResults(path string, results []Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadResults reads results saved with SaveResults.
+func LoadResults(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package benchmarks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseResults(t *testing.T) {
+	output := `goos: linux
+goarch: amd64
+pkg: github.com/alun-dra/bserializer/benchmarks
+BenchmarkSerialize-8                      	 2000000	       612.5 ns/op	     336 B/op	       2 allocs/op
+BenchmarkSerializeSmall/json-map-16       	  500000	      2410 ns/op	    1120 B/op	      25 allocs/op
+BenchmarkDeserialize                      	 1000000	      1043 ns/op
+--- FAIL: BenchmarkBroken
+BenchmarkBroken-8 	 fails
+PASS
+ok  	github.com/alun-dra/bserializer/benchmarks	4.210s
+`
+	want := []Result{
+		{Name: "Serialize", NsPerOp: 612.5, BytesPerOp: 336, AllocsPerOp: 2},
+		{Name: "SerializeSmall/json-map", NsPerOp: 2410, BytesPerOp: 1120, AllocsPerOp: 25},
+		{Name: "Deserialize", NsPerOp: 1043},
+	}
+	got, err := ParseResults(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ParseResults() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseResults() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []Result{{Name: "Serialize", NsPerOp: 100, BytesPerOp: 100, AllocsPerOp: 2}}
+	tests := []struct {
+		name    string
+		current Result
+		metrics []string
+	}{
+		{"unchanged", Result{Name: "Serialize", NsPerOp: 100, BytesPerOp: 100, AllocsPerOp: 2}, nil},
+		{"within tolerance", Result{Name: "Serialize", NsPerOp: 109, BytesPerOp: 109, AllocsPerOp: 2}, nil},
+		{"faster", Result{Name: "Serialize", NsPerOp: 50, BytesPerOp: 50, AllocsPerOp: 1}, nil},
+		{"slower", Result{Name: "Serialize", NsPerOp: 111, BytesPerOp: 100, AllocsPerOp: 2}, []string{"ns/op"}},
+		{"more bytes and allocations", Result{Name: "Serialize", NsPerOp: 100, BytesPerOp: 200, AllocsPerOp: 3}, []string{"B/op", "allocs/op"}},
+		{"not in the baseline", Result{Name: "Deserialize", NsPerOp: 1000}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var metrics []string
+			for _, regression := range Compare(baseline, []Result{tt.current}, 0.1) {
+				metrics = append(metrics, regression.Metric)
+			}
+			if !reflect.DeepEqual(metrics, tt.metrics) {
+				t.Errorf("Compare() regressions = %v, want %v", metrics, tt.metrics)
+			}
+		})
+	}
+}
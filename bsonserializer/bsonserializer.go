@@ -0,0 +1,88 @@
+// Package bsonserializer encodes the bserializer pipeline's output as BSON, the document format of
+// MongoDB, with the bson package of the MongoDB Go driver. Importing it registers the "bson"
+// format with serializer.RegisterFormat, so SerializeAs, DeserializeAs, FormatFromExtension and
+// content negotiation handle it like the built-in formats; packages that don't import it don't
+// depend on the driver.
+//
+// Unlike the other formats, times are stored as BSON datetimes (with millisecond precision), byte
+// slices as binary data, and the driver's types (ObjectID, Decimal128, Timestamp, Regex, Binary
+// with its subtype, ...) as the BSON values they represent. Integers are stored as int32, or int64
+// when they don't fit. Keys listed in the serializer's Order come first, the others follow sorted.
+package bsonserializer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FormatBSON is the format registered by the package.
+const FormatBSON serializer.Format = "bson"
+
+func init() {
+	if err := serializer.RegisterFormat(FormatBSON, Codec{}, "application/bson", ".bson"); err != nil {
+		panic(err)
+	}
+}
+
+// ObjectID is a MongoDB ObjectId, stored as a BSON ObjectId and written as its hex string in the
+// other formats. It is the ObjectID of the MongoDB Go driver.
+type ObjectID = primitive.ObjectID
+
+// NewObjectID returns a new ObjectID made of the current time, a value unique to the process and
+// an incrementing counter, like the MongoDB drivers generate them.
+func NewObjectID() ObjectID {
+	return primitive.NewObjectID()
+}
+
+// ObjectIDFromHex parses the 24-character hex form of an ObjectID.
+func ObjectIDFromHex(s string) (ObjectID, error) {
+	return primitive.ObjectIDFromHex(s)
+}
+
+// BSONSerializer serializes values to BSON documents with the configuration of a BaseSerializer.
+type BSONSerializer struct {
+	Serializer *serializer.BaseSerializer
+}
+
+// New creates a BSONSerializer for s.
+func New(s *serializer.BaseSerializer) *BSONSerializer {
+	if s == nil {
+		s = &serializer.BaseSerializer{}
+	}
+	return &BSONSerializer{Serializer: s}
+}
+
+// Serialize runs data through the serializer pipeline and encodes the output as a BSON document,
+// as stored by MongoDB.
+func (b *BSONSerializer) Serialize(data interface{}) ([]byte, error) {
+	return b.SerializeWithContext(context.Background(), data)
+}
+
+// SerializeWithContext is like Serialize, passing ctx to the context-aware pipeline stages.
+func (b *BSONSerializer) SerializeWithContext(ctx context.Context, data interface{}) ([]byte, error) {
+	return b.Serializer.SerializeAsWithContext(ctx, data, FormatBSON)
+}
+
+// Deserialize decodes a BSON document and deserializes it into out. Values are read the way the
+// serializer writes them in JSON: datetimes as RFC 3339 strings, ObjectIDs as their hex strings,
+// decimals as their string form and binary data as base64, so time.Time, ObjectID, Decimal128
+// and []byte fields receive them unchanged and validations see the same values in every format.
+func (b *BSONSerializer) Deserialize(data []byte, out interface{}) error {
+	return b.Serializer.DeserializeAs(data, FormatBSON, out)
+}
+
+// Decode decodes a BSON document into a map. Datetimes are decoded as time.Time in UTC, generic
+// binary data as []byte, numbers as float64, or as json.Number with UseNumber, and the other
+// BSON values as the driver's types: ObjectID, primitive.Decimal128, primitive.Timestamp,
+// primitive.Regex, primitive.Binary for the other binary subtypes, and so on.
+func (b *BSONSerializer) Decode(data []byte) (map[string]interface{}, error) {
+	document, err := decode(data)
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to decode BSON: %v", err), Err: err}
+	}
+	decoded, _ := fromBSONValue(document, b.Serializer.UseNumber).(map[string]interface{})
+	return decoded, nil
+}
@@ -0,0 +1,220 @@
+package bsonserializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type bsonRecord struct {
+	ID        ObjectID             `json:"_id"`
+	Name      string               `json:"name"`
+	Count     int64                `json:"count"`
+	Price     primitive.Decimal128 `json:"price"`
+	Seen      primitive.Timestamp  `json:"seen"`
+	Pattern   primitive.Regex      `json:"pattern"`
+	UUID      primitive.Binary     `json:"uuid"`
+	Data      []byte               `json:"data"`
+	CreatedAt time.Time            `json:"created_at"`
+	Tags      []string             `json:"tags"`
+}
+
+func newBSONRecord() bsonRecord {
+	return bsonRecord{
+		ID:        NewObjectID(),
+		Name:      "Ada",
+		Count:     1 << 40,
+		Price:     primitive.NewDecimal128(0, 1999),
+		Seen:      primitive.Timestamp{T: 1700000000, I: 3},
+		Pattern:   primitive.Regex{Pattern: "^a", Options: "i"},
+		UUID:      primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: bytes.Repeat([]byte{7}, 16)},
+		Data:      []byte{1, 2, 3},
+		CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123000000, time.UTC),
+		Tags:      []string{"a", "b"},
+	}
+}
+
+func TestSerializeDriverCompatible(t *testing.T) {
+	record := newBSONRecord()
+	b := New(&serializer.BaseSerializer{Order: []string{"_id"}})
+	encoded, err := b.Serialize(record)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+
+	var raw bson.Raw = encoded
+	if first, err := raw.IndexErr(0); err != nil || first.Key() != "_id" {
+		t.Errorf("first key = %v (%v), want _id", first, err)
+	}
+	tests := []struct {
+		key  string
+		want bsontype.Type
+	}{
+		{"_id", bson.TypeObjectID},
+		{"name", bson.TypeString},
+		{"count", bson.TypeInt64},
+		{"price", bson.TypeDecimal128},
+		{"seen", bson.TypeTimestamp},
+		{"pattern", bson.TypeRegex},
+		{"uuid", bson.TypeBinary},
+		{"data", bson.TypeBinary},
+		{"created_at", bson.TypeDateTime},
+		{"tags", bson.TypeArray},
+	}
+	for _, tt := range tests {
+		if got := raw.Lookup(tt.key).Type; got != tt.want {
+			t.Errorf("type of %s = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	// The driver reads the document into the same struct
+	var decoded struct {
+		ID        primitive.ObjectID   `bson:"_id"`
+		Price     primitive.Decimal128 `bson:"price"`
+		Seen      primitive.Timestamp  `bson:"seen"`
+		Pattern   primitive.Regex      `bson:"pattern"`
+		UUID      primitive.Binary     `bson:"uuid"`
+		CreatedAt time.Time            `bson:"created_at"`
+	}
+	if err := bson.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("bson.Unmarshal() error = %v", err)
+	}
+	if decoded.ID != record.ID || decoded.Price != record.Price || decoded.Seen != record.Seen ||
+		decoded.Pattern != record.Pattern || !decoded.UUID.Equal(record.UUID) || !decoded.CreatedAt.Equal(record.CreatedAt) {
+		t.Errorf("bson.Unmarshal() = %+v, want the values of %+v", decoded, record)
+	}
+}
+
+func TestDeserializeRoundTrip(t *testing.T) {
+	record := newBSONRecord()
+	b := New(nil)
+	encoded, err := b.Serialize(record)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	var got bsonRecord
+	if err := b.Deserialize(encoded, &got); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, record) {
+		t.Errorf("Deserialize() = %+v, want %+v", got, record)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	id := NewObjectID()
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		value     interface{}
+		useNumber bool
+		want      interface{}
+	}{
+		{"int32", int32(7), false, float64(7)},
+		{"int64 with UseNumber", int64(1) << 40, true, json.Number("1099511627776")},
+		{"double", 1.5, false, 1.5},
+		{"string", "a", false, "a"},
+		{"null", nil, false, nil},
+		{"ObjectID", id, false, id},
+		{"datetime", primitive.NewDateTimeFromTime(created), false, created},
+		{"generic binary", primitive.Binary{Data: []byte{1}}, false, []byte{1}},
+		{"UUID binary", primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: []byte{1}}, false, primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: []byte{1}}},
+		{"decimal128", primitive.NewDecimal128(0, 5), false, primitive.NewDecimal128(0, 5)},
+		{"timestamp", primitive.Timestamp{T: 1, I: 2}, false, primitive.Timestamp{T: 1, I: 2}},
+		{"regex", primitive.Regex{Pattern: "a", Options: "i"}, false, primitive.Regex{Pattern: "a", Options: "i"}},
+		{"javascript", primitive.JavaScript("x"), false, primitive.JavaScript("x")},
+		{"min key", primitive.MinKey{}, false, primitive.MinKey{}},
+		{"document", bson.D{{Key: "a", Value: int32(1)}}, false, map[string]interface{}{"a": float64(1)}},
+		{"array", bson.A{"a", int32(1)}, false, []interface{}{"a", float64(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := bson.Marshal(bson.D{{Key: "v", Value: tt.value}})
+			if err != nil {
+				t.Fatalf("bson.Marshal() error = %v", err)
+			}
+			got, err := New(&serializer.BaseSerializer{UseNumber: tt.useNumber}).Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got["v"], tt.want) {
+				t.Errorf("Decode() = %#v, want %#v", got["v"], tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid, _ := bson.Marshal(bson.D{{Key: "a", Value: "b"}})
+	nested := bson.D{{Key: "a", Value: "b"}}
+	for i := 0; i <= maxDepth; i++ {
+		nested = bson.D{{Key: "a", Value: nested}}
+	}
+	deep, err := bson.Marshal(nested)
+	if err != nil {
+		t.Fatalf("bson.Marshal() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", valid[:len(valid)-2]},
+		{"trailing bytes", append(append([]byte(nil), valid...), 0)},
+		{"too deep", deep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serializationErr *serializer.SerializationError
+			if _, err := New(nil).Decode(tt.data); !errors.As(err, &serializationErr) {
+				t.Errorf("Decode() error = %v, want a serialization error", err)
+			}
+		})
+	}
+}
+
+func TestRegisteredFormat(t *testing.T) {
+	if got := serializer.FormatFromExtension("users.bson"); got != FormatBSON {
+		t.Errorf("FormatFromExtension() = %q, want %q", got, FormatBSON)
+	}
+	if got := serializer.FormatFromMediaType("application/bson"); got != FormatBSON {
+		t.Errorf("FormatFromMediaType() = %q, want %q", got, FormatBSON)
+	}
+	if got := FormatBSON.ContentType(); got != "application/bson" {
+		t.Errorf("ContentType() = %q, want application/bson", got)
+	}
+
+	record := newBSONRecord()
+	s := &serializer.BaseSerializer{UseNumber: true}
+	encoded, err := s.SerializeAs(record, FormatBSON)
+	if err != nil {
+		t.Fatalf("SerializeAs() error = %v", err)
+	}
+	if got := bson.Raw(encoded).Lookup("created_at").Type; got != bson.TypeDateTime {
+		t.Errorf("type of created_at = %v, want a datetime", got)
+	}
+	var got bsonRecord
+	if err := s.DeserializeAs(encoded, FormatBSON, &got); err != nil {
+		t.Fatalf("DeserializeAs() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, record) {
+		t.Errorf("DeserializeAs() = %+v, want %+v", got, record)
+	}
+
+	// JSON keeps writing the values as strings
+	result, err := s.Serialize(record)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if result["_id"] != record.ID.Hex() || result["created_at"] != "2024-05-01T12:30:00.123Z" {
+		t.Errorf("Serialize() = %v, want strings for _id and created_at", result)
+	}
+}
@@ -0,0 +1,252 @@
+package bsonserializer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxDepth bounds the nesting of decoded documents, so hostile input can't exhaust the stack.
+const maxDepth = 1000
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+
+	// primitivePackage is the package of the types the driver uses for BSON values without a Go
+	// counterpart, such as Decimal128, Timestamp and Regex.
+	primitivePackage = reflect.TypeOf(primitive.ObjectID{}).PkgPath()
+)
+
+// Codec converts serialized output to and from BSON documents. It implements
+// serializer.NativeCodec and is registered for FormatBSON.
+type Codec struct{}
+
+// Native reports whether BSON represents values of type t natively: times, byte slices and the
+// driver's types for BSON values (ObjectID, Decimal128, Timestamp, Regex, Binary, ...).
+// Documents and arrays of the driver (bson.D, bson.M and bson.A) are converted like other maps
+// and slices.
+func (Codec) Native(t reflect.Type) bool {
+	if t == timeType || t == bytesType {
+		return true
+	}
+	if t.PkgPath() != primitivePackage {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice:
+		return false
+	}
+	return true
+}
+
+// Encode encodes serialized output as a BSON document, with its keys in sorted order.
+func (c Codec) Encode(value map[string]interface{}) ([]byte, error) {
+	return c.EncodeOrdered(value, nil)
+}
+
+// EncodeOrdered encodes serialized output as a BSON document, writing the top-level keys listed in
+// order first and the others in sorted order. time.Time, []byte and the driver's values are
+// stored natively.
+func (Codec) EncodeOrdered(value map[string]interface{}, order []string) ([]byte, error) {
+	document, err := toBSONDocument(value, orderedKeys(value, order))
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(document)
+}
+
+// Decode decodes a BSON document into a map, like BSONSerializer.Decode with numbers as
+// json.Number.
+func (Codec) Decode(data []byte) (map[string]interface{}, error) {
+	document, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	decoded, _ := fromBSONValue(document, true).(map[string]interface{})
+	return decoded, nil
+}
+
+// decode decodes a BSON document with the driver, rejecting trailing bytes and deep nesting.
+func decode(data []byte) (bson.M, error) {
+	if len(data) >= 4 {
+		if length := int(int32(binary.LittleEndian.Uint32(data))); length >= 4 && length < len(data) {
+			return nil, fmt.Errorf("%d trailing bytes", len(data)-length)
+		}
+	}
+	// The driver decodes embedded documents recursively
+	if err := checkDepth(bson.Raw(data), 0); err != nil {
+		return nil, err
+	}
+
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	if err != nil {
+		return nil, err
+	}
+	dec.DefaultDocumentM()
+	var document bson.M
+	if err := dec.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// orderedKeys returns the keys of an object listed in order, in that order, followed by the
+// others in sorted order.
+func orderedKeys(object map[string]interface{}, order []string) []string {
+	keys := make([]string, 0, len(object))
+	listed := make(map[string]bool, len(order))
+	for _, key := range order {
+		if _, ok := object[key]; ok && !listed[key] {
+			keys = append(keys, key)
+			listed[key] = true
+		}
+	}
+	rest := make([]string, 0, len(object)-len(keys))
+	for key := range object {
+		if !listed[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// toBSONDocument converts object into a document of the driver with its keys in the given order.
+func toBSONDocument(object map[string]interface{}, keys []string) (bson.D, error) {
+	document := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		if strings.IndexByte(key, 0) >= 0 {
+			return nil, fmt.Errorf("key %q contains a NUL byte", key)
+		}
+		value, err := toBSONValue(key, object[key])
+		if err != nil {
+			return nil, err
+		}
+		document = append(document, bson.E{Key: key, Value: value})
+	}
+	return document, nil
+}
+
+// toBSONValue converts a value of serialized output into the value the driver encodes.
+func toBSONValue(key string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return bsonNumber(key, v)
+	case []interface{}:
+		array := make(bson.A, len(v))
+		for i, item := range v {
+			converted, err := toBSONValue(key, item)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = converted
+		}
+		return array, nil
+	case map[string]interface{}:
+		return toBSONDocument(v, orderedKeys(v, nil))
+	}
+	switch rv := reflect.ValueOf(value); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return bsonNumber(key, json.Number(strconv.FormatInt(rv.Int(), 10)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return bsonNumber(key, json.Number(strconv.FormatUint(rv.Uint(), 10)))
+	}
+	return value, nil
+}
+
+// bsonNumber stores integers as int32 or int64 and other numbers as doubles.
+func bsonNumber(key string, n json.Number) (interface{}, error) {
+	if i, err := n.Int64(); err == nil {
+		if i >= math.MinInt32 && i <= math.MaxInt32 {
+			return int32(i), nil
+		}
+		return i, nil
+	}
+	if !strings.ContainsAny(n.String(), ".eE") {
+		return nil, fmt.Errorf("integer %s of key '%s' overflows int64", n, key)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %s of key '%s'", n, key)
+	}
+	return f, nil
+}
+
+// checkDepth rejects documents nested deeper than maxDepth.
+func checkDepth(document bson.Raw, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("nesting deeper than %d", maxDepth)
+	}
+	elements, err := document.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		value := element.Value()
+		if nested, ok := value.DocumentOK(); ok {
+			err = checkDepth(nested, depth+1)
+		} else if nested, ok := value.ArrayOK(); ok {
+			err = checkDepth(bson.Raw(nested), depth+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromBSONValue converts a value decoded by the driver into the values Decode returns.
+func fromBSONValue(value interface{}, useNumber bool) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = fromBSONValue(item, useNumber)
+		}
+		return object
+	case bson.A:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = fromBSONValue(item, useNumber)
+		}
+		return list
+	case primitive.DateTime:
+		return v.Time().UTC()
+	case primitive.Binary:
+		if v.Subtype == bson.TypeBinaryGeneric || v.Subtype == bson.TypeBinaryBinaryOld {
+			return v.Data
+		}
+		return v
+	case primitive.Undefined:
+		return nil
+	case int32:
+		return bsonInteger(int64(v), useNumber)
+	case int64:
+		return bsonInteger(v, useNumber)
+	case float64:
+		if useNumber {
+			return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func bsonInteger(i int64, useNumber bool) interface{} {
+	if useNumber {
+		return json.Number(strconv.FormatInt(i, 10))
+	}
+	return float64(i)
+}
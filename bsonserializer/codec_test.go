@@ -0,0 +1,87 @@
+package bsonserializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEncodeNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bsontype.Type
+	}{
+		{"small integer", 1, bson.TypeInt32},
+		{"large integer", int64(1) << 40, bson.TypeInt64},
+		{"fraction", 1.5, bson.TypeDouble},
+		{"decoded JSON number", 2.0, bson.TypeDouble},
+		{"exact number", json.Number("9007199254740993"), bson.TypeInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := Codec{}.Encode(map[string]interface{}{"v": tt.value})
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got := bson.Raw(encoded).Lookup("v").Type; got != tt.want {
+				t.Errorf("type = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeOrdered(t *testing.T) {
+	encoded, err := Codec{}.EncodeOrdered(map[string]interface{}{"b": 1, "_id": "x", "a": map[string]interface{}{"d": 1, "c": 2}}, []string{"_id", "missing"})
+	if err != nil {
+		t.Fatalf("EncodeOrdered() error = %v", err)
+	}
+	elements, _ := bson.Raw(encoded).Elements()
+	var keys []string
+	for _, element := range elements {
+		keys = append(keys, element.Key())
+	}
+	if want := []string{"_id", "a", "b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+	nested, _ := bson.Raw(encoded).Lookup("a").Document().Elements()
+	if nested[0].Key() != "c" {
+		t.Errorf("nested keys aren't sorted: %v", nested)
+	}
+
+	if _, err := (Codec{}).Encode(map[string]interface{}{"a\x00b": 1}); err == nil {
+		t.Error("Encode() error = nil, want an error for a NUL byte in a key")
+	}
+	if _, err := (Codec{}).Encode(map[string]interface{}{"n": json.Number("18446744073709551616")}); err == nil {
+		t.Error("Encode() error = nil, want an error for an integer overflowing int64")
+	}
+}
+
+func TestNative(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{time.Time{}, true},
+		{[]byte{}, true},
+		{primitive.ObjectID{}, true},
+		{primitive.Decimal128{}, true},
+		{primitive.Binary{}, true},
+		{bson.M{}, false},
+		{bson.A{}, false},
+		{"", false},
+		{[]string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(reflect.TypeOf(tt.value).String(), func(t *testing.T) {
+			if got := (Codec{}).Native(reflect.TypeOf(tt.value)); got != tt.want {
+				t.Errorf("Native() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
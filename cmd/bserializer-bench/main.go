@@ -0,0 +1,90 @@
+// Command bserializer-bench runs the benchmarks of the benchmarks package with go test, the
+// serializer's own and its comparisons with encoding/json and mapstructure, and checks them
+// against a baseline to catch performance regressions in CI. It must be run from within the
+// module, where go test can build the package.
+//
+// Usage:
+//
+//	bserializer-bench [-run regexp] [-benchtime d] [-o results.json] [-baseline results.json] [-tolerance 0.1]
+//
+// The output of go test -bench -benchmem is passed through, and the results are written as JSON
+// with -o. With -baseline, the command exits with status 1 when a benchmark is more than
+// tolerance slower than in the baseline, allocates more than tolerance more bytes, or allocates
+// more often.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+
+	"github.com/alun-dra/bserializer/benchmarks"
+)
+
+// benchmarksPackage is the package whose benchmarks are run.
+const benchmarksPackage = "github.com/alun-dra/bserializer/benchmarks"
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bserializer-bench: ")
+
+	run := flag.String("run", ".", "run only the benchmarks whose names match the regular expression")
+	benchtime := flag.String("benchtime", "", "run each benchmark for `d`, as with go test -benchtime")
+	output := flag.String("o", "", "write the results as JSON to `file`")
+	baseline := flag.String("baseline", "", "compare the results with those saved in `file`")
+	tolerance := flag.Float64("tolerance", 0.1, "allowed slowdown against the baseline, as a fraction")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: bserializer-bench [-run regexp] [-benchtime d] [-o file] [-baseline file] [-tolerance fraction]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var previous []benchmarks.Result
+	var err error
+	if *baseline != "" {
+		// Loaded first, so that a missing baseline fails before the benchmarks run
+		if previous, err = benchmarks.LoadResults(*baseline); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	args := []string{"test", "-run", "^$", "-bench", *run, "-benchmem"}
+	if *benchtime != "" {
+		args = append(args, "-benchtime", *benchtime)
+	}
+	var out bytes.Buffer
+	cmd := exec.Command("go", append(args, benchmarksPackage)...)
+	cmd.Stdout = io.MultiWriter(os.Stdout, &out)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("go test: %v", err)
+	}
+	results, err := benchmarks.ParseResults(&out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output != "" {
+		if err := benchmarks.SaveResults(*output, results); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *baseline != "" {
+		regressions := benchmarks.Compare(previous, results, *tolerance)
+		for _, regression := range regressions {
+			fmt.Fprintln(os.Stderr, regression)
+		}
+		if len(regressions) > 0 {
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fieldKind is the conversion the generated code applies to a field.
+type fieldKind int
+
+const (
+	kindValue fieldKind = iota // Converted with the reflection-based pipeline
+	kindBool
+	kindInt
+	kindUint
+	kindFloat32
+	kindFloat64
+	kindString
+	kindTime
+)
+
+// basicKinds are the kinds of the predeclared types.
+var basicKinds = map[string]fieldKind{
+	"bool":    kindBool,
+	"int":     kindInt,
+	"int8":    kindInt,
+	"int16":   kindInt,
+	"int32":   kindInt,
+	"int64":   kindInt,
+	"rune":    kindInt,
+	"uint":    kindUint,
+	"uint8":   kindUint,
+	"uint16":  kindUint,
+	"uint32":  kindUint,
+	"uint64":  kindUint,
+	"uintptr": kindUint,
+	"byte":    kindUint,
+	"float32": kindFloat32,
+	"float64": kindFloat64,
+	"string":  kindString,
+}
+
+// customMethods are the methods that replace the default encoding of a type.
+var customMethods = []string{"SerializeField", "MarshalJSON", "MarshalText", "UnmarshalJSON", "UnmarshalText"}
+
+// field is a serialized field of a struct.
+type field struct {
+	name      string    // Go name
+	key       string    // Serialized key
+	kind      fieldKind // Conversion of the field, or of its pointee when pointer is set
+	pointer   bool      // Pointer to a bool, number or string
+	elem      string    // Pointee type, when pointer is set
+	omitEmpty bool
+	nonEmpty  string // Condition true when the field isn't empty, with %s for the field; never empty when ""
+}
+
+// generator writes the methods of the structs of a package.
+type generator struct {
+	pkg     string
+	types   map[string]*ast.TypeSpec
+	methods map[string]map[string]bool // Method names by receiver type name
+}
+
+// newGenerator parses the package in dir, skipping tests and the previously generated output.
+func newGenerator(dir, output string) (*generator, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	g := &generator{types: make(map[string]*ast.TypeSpec), methods: make(map[string]map[string]bool)}
+	for name, pkg := range pkgs {
+		g.pkg = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						if spec, ok := spec.(*ast.TypeSpec); ok {
+							g.types[spec.Name.Name] = spec
+						}
+					}
+				case *ast.FuncDecl:
+					if decl.Recv != nil && len(decl.Recv.List) == 1 {
+						receiver := receiverName(decl.Recv.List[0].Type)
+						if g.methods[receiver] == nil {
+							g.methods[receiver] = make(map[string]bool)
+						}
+						g.methods[receiver][decl.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// receiverName returns the type name of a method receiver.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// custom reports whether the named type has custom encoding methods.
+func (g *generator) custom(name string) bool {
+	for _, method := range customMethods {
+		if g.methods[name][method] {
+			return true
+		}
+	}
+	return false
+}
+
+// generate returns the formatted source of the methods of the named structs.
+func (g *generator) generate(names []string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by bserializer-gen. DO NOT EDIT.\n\npackage %s\n\n", g.pkg)
+	fmt.Fprintf(&buf, "import \"github.com/alun-dra/bserializer/serializer\"\n")
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		fields, err := g.fields(name)
+		if err != nil {
+			return nil, err
+		}
+		writeEncode(&buf, name, fields)
+		writeDecode(&buf, name, fields)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %v", err)
+	}
+	return source, nil
+}
+
+// fields returns the serialized fields of the named struct.
+func (g *generator) fields(name string) ([]field, error) {
+	spec, ok := g.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", name, g.pkg)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok || spec.TypeParams != nil {
+		return nil, fmt.Errorf("type %s is not a non-generic struct", name)
+	}
+	if g.custom(name) {
+		return nil, fmt.Errorf("type %s has custom encoding methods", name)
+	}
+	if g.methods[name]["EncodeMap"] || g.methods[name]["DecodeMap"] {
+		return nil, fmt.Errorf("type %s already has an EncodeMap or DecodeMap method", name)
+	}
+
+	var fields []field
+	keys := make(map[string]bool)
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded field %s is not supported", name, types.ExprString(f.Type))
+		}
+
+		var tag string
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid tag %s", name, f.Tag.Value)
+			}
+			tag = reflect.StructTag(unquoted).Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		key, opts, _ := strings.Cut(tag, ",")
+		if !validTagName(key) {
+			key = ""
+		}
+
+		kind, nonEmpty := g.classify(f.Type)
+		pointer, elem := false, ""
+		if star, ok := f.Type.(*ast.StarExpr); ok {
+			if elemKind, _ := g.classify(star.X); elemKind != kindValue {
+				kind, pointer, elem = elemKind, true, types.ExprString(star.X)
+			}
+		}
+		if hasOption(opts, "string") && kind != kindValue && kind != kindTime {
+			return nil, fmt.Errorf("%s: the ,string option of field %s is not supported", name, f.Names[0].Name)
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			fieldKey := key
+			if fieldKey == "" {
+				fieldKey = ident.Name
+			}
+			if keys[fieldKey] {
+				return nil, fmt.Errorf("%s: duplicate field %q", name, fieldKey)
+			}
+			keys[fieldKey] = true
+			fields = append(fields, field{
+				name:      ident.Name,
+				key:       fieldKey,
+				kind:      kind,
+				pointer:   pointer,
+				elem:      elem,
+				omitEmpty: hasOption(opts, "omitempty"),
+				nonEmpty:  nonEmpty,
+			})
+		}
+	}
+	return fields, nil
+}
+
+// classify returns the kind of a field type, and the condition true when a field of that type
+// isn't empty. Types that can't be resolved from the package source are converted with the
+// reflection-based pipeline.
+func (g *generator) classify(expr ast.Expr) (fieldKind, string) {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return g.classify(t.X)
+	case *ast.Ident:
+		spec, local := g.types[t.Name]
+		if !local {
+			if kind, ok := basicKinds[t.Name]; ok {
+				return kind, nonEmptyOf(kind)
+			}
+			if t.Name == "any" || t.Name == "error" {
+				return kindValue, "%s != nil"
+			}
+			return kindValue, "!serializer.IsEmpty(%s)"
+		}
+		if spec.TypeParams != nil {
+			return kindValue, "!serializer.IsEmpty(%s)"
+		}
+		kind, nonEmpty := g.classify(spec.Type)
+		if g.custom(t.Name) {
+			kind = kindValue
+		}
+		return kind, nonEmpty
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Time" {
+			return kindTime, ""
+		}
+	case *ast.StarExpr, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return kindValue, "%s != nil"
+	case *ast.ArrayType, *ast.MapType:
+		return kindValue, "len(%s) != 0"
+	case *ast.StructType:
+		return kindValue, ""
+	}
+	return kindValue, "!serializer.IsEmpty(%s)"
+}
+
+// nonEmptyOf returns the non-empty condition of the bool, number and string kinds.
+func nonEmptyOf(kind fieldKind) string {
+	switch kind {
+	case kindBool:
+		return "%s"
+	case kindString:
+		return `%s != ""`
+	default:
+		return "%s != 0"
+	}
+}
+
+// writeEncode writes the EncodeMap method of a struct.
+func writeEncode(buf *bytes.Buffer, name string, fields []field) {
+	fmt.Fprintf(buf, "\n// EncodeMap converts a %s into a serialized map. It implements serializer.MapEncoder.\n", name)
+	fmt.Fprintf(buf, "func (v %s) EncodeMap(numbers bool) (map[string]interface{}, error) {\n", name)
+	fmt.Fprintf(buf, "result := make(map[string]interface{}, %d)\n", len(fields))
+	for _, f := range fields {
+		if f.fallible() {
+			buf.WriteString("var err error\n")
+			break
+		}
+	}
+
+	for _, f := range fields {
+		selector := "v." + f.name
+		key := strconv.Quote(f.key)
+		switch {
+		case f.pointer && f.omitEmpty:
+			fmt.Fprintf(buf, "if %s != nil {\n%s}\n", selector, f.encode(key, "*"+selector))
+		case f.pointer:
+			fmt.Fprintf(buf, "if %s == nil {\nresult[%s] = nil\n} else {\n%s}\n", selector, key, f.encode(key, "*"+selector))
+		case f.omitEmpty && f.nonEmpty != "":
+			fmt.Fprintf(buf, "if %s {\n%s}\n", fmt.Sprintf(f.nonEmpty, selector), f.encode(key, selector))
+		default:
+			buf.WriteString(f.encode(key, selector))
+		}
+	}
+	buf.WriteString("return result, nil\n}\n")
+}
+
+// fallible reports whether converting the field can fail.
+func (f *field) fallible() bool {
+	switch f.kind {
+	case kindBool, kindInt, kindUint, kindString:
+		return false
+	}
+	return true
+}
+
+// encode returns the statement storing the converted value under key.
+func (f *field) encode(key, value string) string {
+	var call string
+	switch f.kind {
+	case kindBool:
+		return fmt.Sprintf("result[%s] = bool(%s)\n", key, value)
+	case kindInt:
+		return fmt.Sprintf("result[%s] = serializer.EncodeInt(%s, numbers)\n", key, value)
+	case kindUint:
+		return fmt.Sprintf("result[%s] = serializer.EncodeUint(%s, numbers)\n", key, value)
+	case kindString:
+		return fmt.Sprintf("result[%s] = serializer.EncodeString(%s)\n", key, value)
+	case kindFloat32:
+		call = fmt.Sprintf("serializer.EncodeFloat32(%s, numbers)", value)
+	case kindFloat64:
+		call = fmt.Sprintf("serializer.EncodeFloat64(%s, numbers)", value)
+	case kindTime:
+		call = fmt.Sprintf("serializer.EncodeTime(%s)", value)
+	default:
+		// Passed by address, so methods with pointer receivers apply like they do for the
+		// fields of addressable structs
+		call = fmt.Sprintf("serializer.EncodeValue(&%s, numbers)", value)
+	}
+	return fmt.Sprintf("if result[%s], err = %s; err != nil {\nreturn nil, err\n}\n", key, call)
+}
+
+// writeDecode writes the DecodeMap method of a struct and the list of its keys.
+func writeDecode(buf *bytes.Buffer, name string, fields []field) {
+	keys := "bserializerKeys" + name
+	fmt.Fprintf(buf, "\n// DecodeMap fills a %s from a serialized map. It implements serializer.MapDecoder.\n", name)
+	fmt.Fprintf(buf, "func (v *%s) DecodeMap(input map[string]interface{}) error {\n", name)
+	if len(fields) == 0 {
+		buf.WriteString("return nil\n}\n")
+		return
+	}
+	buf.WriteString("for key, value := range input {\nvar err error\n")
+	fmt.Fprintf(buf, "switch serializer.MatchKey(key, %s) {\n", keys)
+	for _, f := range fields {
+		selector := "v." + f.name
+		fmt.Fprintf(buf, "case %s:\n", strconv.Quote(f.key))
+		if f.pointer && f.kind != kindTime {
+			fmt.Fprintf(buf, "if value == nil {\n%s = nil\nbreak\n}\n", selector)
+			fmt.Fprintf(buf, "if %s == nil {\n%s = new(%s)\n}\n", selector, selector, f.elem)
+			fmt.Fprintf(buf, "err = %s\n", f.decode(selector))
+			continue
+		}
+		fmt.Fprintf(buf, "err = %s\n", f.decode("&"+selector))
+	}
+	buf.WriteString("}\nif err != nil {\nreturn err\n}\n}\nreturn nil\n}\n")
+
+	fmt.Fprintf(buf, "\nvar %s = []string{", keys)
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.Quote(f.key))
+	}
+	buf.WriteString("}\n")
+}
+
+// decode returns the call storing value into the field through the pointer out.
+func (f *field) decode(out string) string {
+	switch f.kind {
+	case kindBool:
+		return fmt.Sprintf("serializer.DecodeBool(key, value, %s)", out)
+	case kindInt:
+		return fmt.Sprintf("serializer.DecodeInt(key, value, %s)", out)
+	case kindUint:
+		return fmt.Sprintf("serializer.DecodeUint(key, value, %s)", out)
+	case kindFloat32, kindFloat64:
+		return fmt.Sprintf("serializer.DecodeFloat(key, value, %s)", out)
+	case kindString:
+		return fmt.Sprintf("serializer.DecodeString(key, value, %s)", out)
+	default:
+		return fmt.Sprintf("serializer.DecodeValue(key, value, %s)", out)
+	}
+}
+
+// hasOption reports whether a comma-separated list of tag options contains option.
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts, _ = strings.Cut(opts, ",")
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
+
+// validTagName reports whether name can be used as a key, like encoding/json checks it.
+func validTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const source = `package models
+
+import "time"
+
+type Status string
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	ID        int64             ` + "`json:\"id\"`" + `
+	Name      string            ` + "`json:\"name,omitempty\"`" + `
+	Score     float64
+	Admin     *bool             ` + "`json:\"admin,omitempty\"`" + `
+	Status    Status            ` + "`json:\"status\"`" + `
+	Created   time.Time         ` + "`json:\"created\"`" + `
+	Tags      []string          ` + "`json:\"tags,omitempty\"`" + `
+	Home      Address           ` + "`json:\"home\"`" + `
+	Secret    string            ` + "`json:\"-\"`" + `
+	internal  int
+}
+
+type Token string
+
+func (t Token) MarshalText() ([]byte, error) { return []byte(t), nil }
+
+type Custom struct{ A int }
+
+func (c Custom) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type Base struct{ ID int }
+
+type Embedded struct {
+	Base
+}
+
+type Duplicate struct {
+	A int ` + "`json:\"x\"`" + `
+	B int ` + "`json:\"x\"`" + `
+}
+
+type Quoted struct {
+	N int ` + "`json:\"n,string\"`" + `
+}
+
+type Coded struct {
+	Token Token ` + "`json:\"token\"`" + `
+}
+
+type List []int
+`
+
+// newTestGenerator parses source written to a temporary package directory.
+func newTestGenerator(t *testing.T) *generator {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"models.go":          source,
+		"models_test.go":     "package models_test\n",
+		"bserializer_gen.go": "package stale\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := newGenerator(dir, "bserializer_gen.go")
+	if err != nil {
+		t.Fatalf("newGenerator() error = %v", err)
+	}
+	return g
+}
+
+func TestFields(t *testing.T) {
+	g := newTestGenerator(t)
+	if g.pkg != "models" {
+		t.Errorf("pkg = %q, want models", g.pkg)
+	}
+	fields, err := g.fields("User")
+	if err != nil {
+		t.Fatalf("fields() error = %v", err)
+	}
+	want := []field{
+		{name: "ID", key: "id", kind: kindInt, nonEmpty: "%s != 0"},
+		{name: "Name", key: "name", kind: kindString, omitEmpty: true, nonEmpty: `%s != ""`},
+		{name: "Score", key: "Score", kind: kindFloat64, nonEmpty: "%s != 0"},
+		{name: "Admin", key: "admin", kind: kindBool, pointer: true, elem: "bool", omitEmpty: true, nonEmpty: "%s != nil"},
+		{name: "Status", key: "status", kind: kindString, nonEmpty: `%s != ""`},
+		{name: "Created", key: "created", kind: kindTime},
+		{name: "Tags", key: "tags", kind: kindValue, omitEmpty: true, nonEmpty: "len(%s) != 0"},
+		{name: "Home", key: "home", kind: kindValue},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields() = %+v, want %+v", fields, want)
+	}
+
+	coded, err := g.fields("Coded")
+	if err != nil || len(coded) != 1 || coded[0].kind != kindValue {
+		t.Errorf("fields(Coded) = %+v, %v, want a field converted by reflection", coded, err)
+	}
+}
+
+func TestFieldsErrors(t *testing.T) {
+	g := newTestGenerator(t)
+	tests := []struct {
+		name    string
+		typ     string
+		wantErr string
+	}{
+		{"missing", "Missing", "type Missing not found in package models"},
+		{"not a struct", "List", "is not a non-generic struct"},
+		{"custom methods", "Custom", "has custom encoding methods"},
+		{"embedded", "Embedded", "embedded field Base is not supported"},
+		{"duplicate key", "Duplicate", `duplicate field "x"`},
+		{"string option", "Quoted", "the ,string option of field N is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.fields(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("fields() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	g := newTestGenerator(t)
+	source, err := g.generate([]string{"User", " Address"})
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	for _, want := range []string{
+		"// Code generated by bserializer-gen. DO NOT EDIT.",
+		"package models",
+		"func (v User) EncodeMap(numbers bool) (map[string]interface{}, error) {",
+		`result["id"] = serializer.EncodeInt(v.ID, numbers)`,
+		"if v.Name != \"\" {\n\t\tresult[\"name\"] = serializer.EncodeString(v.Name)",
+		"if v.Admin != nil {\n\t\tresult[\"admin\"] = bool(*v.Admin)",
+		`if result["created"], err = serializer.EncodeTime(v.Created); err != nil {`,
+		`if result["home"], err = serializer.EncodeValue(&v.Home, numbers); err != nil {`,
+		"func (v *User) DecodeMap(input map[string]interface{}) error {",
+		"v.Admin = new(bool)",
+		`err = serializer.DecodeFloat(key, value, &v.Score)`,
+		`var bserializerKeysUser = []string{"id", "name", "Score", "admin", "status", "created", "tags", "home"}`,
+		"func (v Address) EncodeMap(numbers bool) (map[string]interface{}, error) {",
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("generate() doesn't contain %q:\n%s", want, source)
+		}
+	}
+	if strings.Contains(string(source), "Secret") || strings.Contains(string(source), "internal") {
+		t.Errorf("generate() includes ignored fields:\n%s", source)
+	}
+
+	if _, err := g.generate([]string{"Missing"}); err == nil {
+		t.Error("generate() error = nil, want an error for a missing type")
+	}
+}
+
+func TestValidTagName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"", false},
+		{"id", true},
+		{"user-id", true},
+		{"naïve", true},
+		{"a\"b", false},
+		{"a,b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validTagName(tt.name); got != tt.want {
+				t.Errorf("validTagName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
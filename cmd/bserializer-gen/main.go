@@ -0,0 +1,59 @@
+// Command bserializer-gen generates EncodeMap and DecodeMap methods for structs, so the serializer
+// package converts them to and from maps without reflection. Fields are read from the struct
+// definitions and their json tags, like the reflection-based pipeline reads them.
+//
+// Add a directive to the package declaring the types and run go generate:
+//
+//	//go:generate go run github.com/alun-dra/bserializer/cmd/bserializer-gen -type User,Address
+//
+// The methods are written to bserializer_gen.go in the package directory. Fields of types the
+// generator can't resolve, such as slices, maps or types from other packages, fall back to the
+// reflection-based conversion.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bserializer-gen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", "bserializer_gen.go", "output file name, relative to the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: bserializer-gen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	g, err := newGenerator(dir, filepath.Base(path))
+	if err != nil {
+		log.Fatal(err)
+	}
+	source, err := g.generate(strings.Split(*typeNames, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(path, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,282 @@
+// Command bserializer converts documents between JSON, YAML, XML and TOML and validates them
+// against a schema file, applying the same rules as a serializer built with
+// NewSchemaSerializer, so CI pipelines can check configuration and fixtures.
+//
+// Usage:
+//
+//	bserializer convert [-from format] [-to format] [-o output] [file]
+//	bserializer validate -schema schema.yaml [-from format] [-strict] [file...]
+//
+// Formats are json, yaml, xml and toml, and default to the extension of the files. Documents are
+// read from standard input when no file (or "-") is given; convert writes to standard output
+// without -o. Validate prints every error of every document and exits with status 1 when one is
+// invalid.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// formatTOML is the TOML format, which only the command supports.
+const formatTOML serializer.Format = "toml"
+
+const usage = `Usage:
+  bserializer convert [-from format] [-to format] [-o output] [file]
+  bserializer validate -schema file [-from format] [-strict] [file...]
+
+Formats: json, yaml, xml, toml.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "convert":
+		err = convert(os.Args[2:], os.Stdin, os.Stdout)
+	case "validate":
+		var valid bool
+		valid, err = validate(os.Args[2:], os.Stdin, os.Stdout)
+		if err == nil && !valid {
+			os.Exit(1)
+		}
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "bserializer: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(os.Stderr, "bserializer: %v\n\n%s", err, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bserializer: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usageError reports invalid arguments.
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// convert implements the convert command.
+func convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	from := flags.String("from", "", "input format; from the input file extension by default")
+	to := flags.String("to", "", "output format; from the output file extension by default")
+	output := flags.String("o", "", "output file; standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return &usageError{err.Error()}
+	}
+	if flags.NArg() > 1 {
+		return &usageError{"convert takes at most one input file"}
+	}
+
+	input := flags.Arg(0)
+	document, err := readDocument(input, *from, stdin)
+	if err != nil {
+		return err
+	}
+
+	format, err := formatOf(*to, *output)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodeDocument(document, format)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = stdout.Write(encoded)
+		return err
+	}
+	return os.WriteFile(*output, encoded, 0o644)
+}
+
+// validate implements the validate command, reporting whether every document is valid.
+func validate(args []string, stdin io.Reader, stdout io.Writer) (bool, error) {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	schemaPath := flags.String("schema", "", "schema file (.json, .yaml or .yml); required")
+	from := flags.String("from", "", "input format; from the file extensions by default")
+	strict := flags.Bool("strict", false, "report fields missing from the schema")
+	if err := flags.Parse(args); err != nil {
+		return false, &usageError{err.Error()}
+	}
+	if *schemaPath == "" {
+		return false, &usageError{"validate needs a -schema file"}
+	}
+	schema, err := serializer.LoadSchema(*schemaPath)
+	if err != nil {
+		return false, err
+	}
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	valid := true
+	for _, input := range inputs {
+		name := input
+		if name == "" || name == "-" {
+			name = "<stdin>"
+		}
+
+		document, err := readDocument(input, *from, stdin)
+		if err != nil {
+			valid = false
+			fmt.Fprintf(stdout, "%s: %v\n", name, err)
+			continue
+		}
+		errs := validateDocument(schema, document, *strict)
+		if len(errs) == 0 {
+			fmt.Fprintf(stdout, "%s: valid\n", name)
+			continue
+		}
+		valid = false
+		fmt.Fprintf(stdout, "%s: %d error(s)\n", name, len(errs))
+		for _, err := range errs {
+			fmt.Fprintf(stdout, "  %s\n", describe(err))
+		}
+	}
+	return valid, nil
+}
+
+// validateDocument checks every field of the schema, returning all the errors in field order.
+func validateDocument(schema map[string]serializer.Field, document map[string]interface{}, strict bool) []error {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	if strict {
+		for name := range document {
+			if _, ok := schema[name]; !ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		field, ok := schema[name]
+		if !ok {
+			errs = append(errs, &serializer.ValidationError{Field: name, Value: document[name], Message: "field is not in the schema", Code: serializer.CodeNotAllowed})
+			continue
+		}
+		// One serializer per field, so a failing field doesn't hide the errors of the others
+		s := serializer.NewSchemaSerializer(map[string]serializer.Field{name: field})
+		if err := s.Validate(document); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// describe formats a validation error for the terminal.
+func describe(err error) string {
+	var validationErr *serializer.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err.Error()
+	}
+	if validationErr.Value == nil {
+		return fmt.Sprintf("%s: %s [%s]", validationErr.Field, validationErr.Message, validationErr.Code)
+	}
+	value, _ := json.Marshal(validationErr.Value)
+	return fmt.Sprintf("%s: %s [%s] (value: %s)", validationErr.Field, validationErr.Message, validationErr.Code, value)
+}
+
+// readDocument reads and decodes the document in the file at path, or in stdin when path is
+// empty or "-".
+func readDocument(path, format string, stdin io.Reader) (map[string]interface{}, error) {
+	var data []byte
+	var err error
+	if path == "" || path == "-" {
+		path = ""
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := formatOf(format, path)
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are kept exact while converting
+	s := &serializer.BaseSerializer{UseNumber: true, DuplicateKeys: serializer.DuplicateKeysError}
+	switch f {
+	case serializer.FormatJSON:
+		return s.DecodeJSON(data)
+	case serializer.FormatYAML:
+		return s.DecodeYAML(data)
+	case serializer.FormatXML:
+		return s.DecodeXML(data)
+	default:
+		return decodeTOML(data)
+	}
+}
+
+// encodeDocument encodes a document in the given format, with sorted keys.
+func encodeDocument(document map[string]interface{}, format serializer.Format) ([]byte, error) {
+	switch format {
+	case serializer.FormatJSON:
+		encoded, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON: %v", err)
+		}
+		return append(encoded, '\n'), nil
+	case serializer.FormatXML:
+		encoded, err := serializer.EncodeSorted(document, format)
+		if err != nil {
+			return nil, err
+		}
+		return append(encoded, '\n'), nil
+	case formatTOML:
+		return encodeTOML(document)
+	default:
+		return serializer.EncodeSorted(document, format)
+	}
+}
+
+// formatOf returns the format named by a flag, or else the format of the file extension.
+func formatOf(name, path string) (serializer.Format, error) {
+	if name == "" {
+		if path == "" {
+			return "", &usageError{"the format of standard input and output must be given with -from and -to"}
+		}
+		name = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if name == "yml" {
+			name = "yaml"
+		}
+	}
+	switch format := serializer.Format(strings.ToLower(name)); format {
+	case serializer.FormatJSON, serializer.FormatYAML, serializer.FormatXML, formatTOML:
+		return format, nil
+	default:
+		return "", &usageError{fmt.Sprintf("unsupported format %q; expected json, yaml, xml or toml", name)}
+	}
+}
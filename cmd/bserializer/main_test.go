@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files into a temporary directory and returns its path.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  string
+	}{
+		{"json to yaml", []string{"-from", "json", "-to", "yaml"}, `{"b": 1.50, "a": "x"}`, "a: x\nb: 1.50\n"},
+		{"yaml to json", []string{"-from", "YAML", "-to", "json"}, "name: ana\nage: 30\n", "{\n  \"age\": 30,\n  \"name\": \"ana\"\n}\n"},
+		{"json to toml", []string{"-from", "json", "-to", "TOML"}, `{"id": 9007199254740993, "big": 12345678901234567890}`, "big = 1.2345678901234567e+19\nid = 9007199254740993\n"},
+		{"toml to json", []string{"-from", "toml", "-to", "json"}, "[db]\nport = 5432\n", "{\n  \"db\": {\n    \"port\": 5432\n  }\n}\n"},
+		{"json to xml", []string{"-from", "json", "-to", "xml", "-"}, `{"name": "ana"}`, "<object>\n  <name>ana</name>\n</object>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			if err := convert(tt.args, strings.NewReader(tt.stdin), &stdout); err != nil {
+				t.Fatalf("convert() error = %v", err)
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("convert() output = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": "port: 8080\n"})
+	output := filepath.Join(dir, "config.json")
+	var stdout bytes.Buffer
+	if err := convert([]string{"-o", output, filepath.Join(dir, "config.yaml")}, nil, &stdout); err != nil {
+		t.Fatalf("convert() error = %v", err)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "{\n  \"port\": 8080\n}\n" || stdout.Len() != 0 {
+		t.Errorf("convert() wrote %q and %q to standard output", got, stdout.String())
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.json": "{}", "b.json": "{}", "bad.json": `{"a": 1, "a": 2}`})
+	tests := []struct {
+		name      string
+		args      []string
+		wantUsage bool
+		wantErr   string
+	}{
+		{"unknown flag", []string{"-x"}, true, "flag provided but not defined"},
+		{"two inputs", []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")}, true, "at most one input file"},
+		{"standard input without a format", []string{"-to", "json"}, true, "must be given with -from and -to"},
+		{"standard output without a format", []string{filepath.Join(dir, "a.json")}, true, "must be given with -from and -to"},
+		{"unsupported format", []string{"-to", "csv", filepath.Join(dir, "a.json")}, true, `unsupported format "csv"`},
+		{"missing file", []string{"-to", "json", filepath.Join(dir, "missing.json")}, false, "no such file"},
+		{"duplicate keys", []string{"-to", "yaml", filepath.Join(dir, "bad.json")}, false, "duplicate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := convert(tt.args, strings.NewReader("{}"), &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("convert() error = %v, want %q", err, tt.wantErr)
+			}
+			var usageErr *usageError
+			if errors.As(err, &usageErr) != tt.wantUsage {
+				t.Errorf("convert() error = %T, want a usage error: %v", err, tt.wantUsage)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.yaml": "name:\n  type: string\n  required: true\nage:\n  type: int\n  min: 18\n",
+		"valid.json":  `{"name": "ana", "age": 30}`,
+		"two.json":    `{"age": 12}`,
+		"extra.yaml":  "name: ana\nnickname: an\n",
+		"broken.json": `{"name":`,
+	})
+	schema := filepath.Join(dir, "schema.yaml")
+	tests := []struct {
+		name      string
+		args      []string
+		stdin     string
+		wantValid bool
+		want      []string
+	}{
+		{"valid", []string{filepath.Join(dir, "valid.json")}, "", true, []string{"valid.json: valid"}},
+		{"every error", []string{filepath.Join(dir, "two.json")}, "", false, []string{"two.json: 2 error(s)", "  age: ", "(value: 12)", "  name: "}},
+		{"extra field", []string{filepath.Join(dir, "extra.yaml")}, "", true, []string{"extra.yaml: valid"}},
+		{"strict", []string{"-strict", filepath.Join(dir, "extra.yaml")}, "", false, []string{"nickname: field is not in the schema [not_allowed] (value: \"an\")"}},
+		{"unreadable document", []string{filepath.Join(dir, "broken.json"), filepath.Join(dir, "valid.json")}, "", false, []string{"broken.json: ", "valid.json: valid"}},
+		{"standard input", []string{"-from", "yaml"}, "name: ana\n", true, []string{"<stdin>: valid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			valid, err := validate(append([]string{"-schema", schema}, tt.args...), strings.NewReader(tt.stdin), &stdout)
+			if err != nil {
+				t.Fatalf("validate() error = %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("validate() = %v, want %v:\n%s", valid, tt.wantValid, stdout.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("validate() output doesn't contain %q:\n%s", want, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantUsage bool
+	}{
+		{"no schema", []string{"doc.json"}, true},
+		{"unknown flag", []string{"-x"}, true},
+		{"missing schema", []string{"-schema", filepath.Join(t.TempDir(), "missing.yaml")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validate(tt.args, strings.NewReader(""), &bytes.Buffer{})
+			if err == nil {
+				t.Fatal("validate() error = nil, want an error")
+			}
+			var usageErr *usageError
+			if errors.As(err, &usageErr) != tt.wantUsage {
+				t.Errorf("validate() error = %v, want a usage error: %v", err, tt.wantUsage)
+			}
+		})
+	}
+}
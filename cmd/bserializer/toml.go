@@ -0,0 +1,734 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The module has no TOML dependency, so documents are read and written by this small codec. It
+// covers TOML 1.0 except for the checks against redefining tables with dotted keys. Dates and
+// times are kept as strings.
+
+// maxTOMLDepth bounds the nesting of arrays and inline tables.
+const maxTOMLDepth = 1000
+
+// decodeTOML parses a TOML document into a map.
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("failed to parse TOML: invalid UTF-8")
+	}
+	p := &tomlParser{src: string(data), line: 1, defined: make(map[string]bool)}
+	root, err := p.document()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: line %d: %v", p.line, err)
+	}
+	return root, nil
+}
+
+// tomlParser reads a TOML document.
+type tomlParser struct {
+	src     string
+	pos     int
+	line    int
+	defined map[string]bool // Tables defined by a header, by their dotted path
+}
+
+func (p *tomlParser) document() (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return root, nil
+		}
+
+		var err error
+		if p.src[p.pos] == '[' {
+			current, err = p.header(root)
+		} else {
+			err = p.keyValue(current, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// header reads a [table] or [[array]] header and returns the table it opens.
+func (p *tomlParser) header(root map[string]interface{}) (map[string]interface{}, error) {
+	array := strings.HasPrefix(p.src[p.pos:], "[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	p.skipSpace()
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return nil, fmt.Errorf("expected %s after table name", closing)
+	}
+	p.pos += len(closing)
+
+	table, err := tableAt(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	if array {
+		var items []interface{}
+		if existing, ok := table[last]; ok {
+			if items, ok = existing.([]interface{}); !ok || !p.defined[strings.Join(keys, "\x00")+"[]"] {
+				return nil, fmt.Errorf("key '%s' is already defined", last)
+			}
+		}
+		p.defined[strings.Join(keys, "\x00")+"[]"] = true
+		item := make(map[string]interface{})
+		table[last] = append(items, item)
+		return item, nil
+	}
+
+	path := strings.Join(keys, "\x00")
+	if p.defined[path] {
+		return nil, fmt.Errorf("table '%s' is already defined", strings.Join(keys, "."))
+	}
+	p.defined[path] = true
+	return subtable(table, last)
+}
+
+// tableAt returns the table at the dotted path keys, creating missing tables. Arrays of tables
+// resolve to their last element.
+func tableAt(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		var err error
+		if table, err = subtable(table, key); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+// subtable returns the table under key, creating it when missing.
+func subtable(table map[string]interface{}, key string) (map[string]interface{}, error) {
+	switch existing := table[key].(type) {
+	case nil:
+		created := make(map[string]interface{})
+		table[key] = created
+		return created, nil
+	case map[string]interface{}:
+		return existing, nil
+	case []interface{}:
+		if len(existing) > 0 {
+			if last, ok := existing[len(existing)-1].(map[string]interface{}); ok {
+				return last, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("key '%s' is not a table", key)
+}
+
+// keyValue reads a key/value pair into table.
+func (p *tomlParser) keyValue(table map[string]interface{}, depth int) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return fmt.Errorf("expected = after key")
+	}
+	p.pos++
+	p.skipSpace()
+	value, err := p.value(depth)
+	if err != nil {
+		return err
+	}
+
+	parent, err := tableAt(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return fmt.Errorf("key '%s' is already defined", last)
+	}
+	parent[last] = value
+	return nil
+}
+
+// key reads a bare, quoted or dotted key.
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("expected a key")
+		}
+		switch p.src[p.pos] {
+		case '"':
+			key, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case '\'':
+			key, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		default:
+			start := p.pos
+			for p.pos < len(p.src) && isBareKeyChar(p.src[p.pos]) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("expected a key")
+			}
+			keys = append(keys, p.src[start:p.pos])
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// value reads a value.
+func (p *tomlParser) value(depth int) (interface{}, error) {
+	if depth > maxTOMLDepth {
+		return nil, fmt.Errorf("values nested deeper than %d", maxTOMLDepth)
+	}
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("expected a value")
+	}
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return p.multilineString(`"""`)
+	case strings.HasPrefix(rest, "'''"):
+		return p.multilineString("'''")
+	case rest[0] == '"':
+		return p.basicString()
+	case rest[0] == '\'':
+		return p.literalString()
+	case rest[0] == '[':
+		return p.array(depth)
+	case rest[0] == '{':
+		return p.inlineTable(depth)
+	}
+
+	// Booleans, numbers and dates run up to a delimiter; a space may separate a date and a time
+	end := p.pos
+	for end < len(p.src) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[end])) {
+		end++
+	}
+	if end-p.pos == 10 && end+1 < len(p.src) && p.src[end] == ' ' && isDigit(p.src[end+1]) {
+		for end++; end < len(p.src) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[end])); end++ {
+		}
+	}
+	token := p.src[p.pos:end]
+	p.pos = end
+	return scalar(token)
+}
+
+// scalar converts a boolean, number or date token.
+func scalar(token string) (interface{}, error) {
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+	if len(token) >= 8 && (token[2] == ':' || len(token) >= 10 && token[4] == '-' && token[7] == '-') {
+		return token, nil
+	}
+
+	number := strings.ReplaceAll(token, "_", "")
+	if strings.Contains(token, "__") || strings.HasPrefix(token, "_") || strings.HasSuffix(token, "_") {
+		return nil, fmt.Errorf("invalid number '%s'", token)
+	}
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(number, prefix) {
+			n, err := strconv.ParseInt(number[2:], base, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s'", token)
+			}
+			return n, nil
+		}
+	}
+	if strings.ContainsAny(number, ".eE") {
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", token)
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value '%s'", token)
+	}
+	return n, nil
+}
+
+// array reads an array.
+func (p *tomlParser) array(depth int) ([]interface{}, error) {
+	p.pos++
+	items := make([]interface{}, 0)
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if p.src[p.pos] == ']' {
+			p.pos++
+			return items, nil
+		}
+		item, err := p.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipBlank()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		} else if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return nil, fmt.Errorf("expected , or ] in array")
+		}
+	}
+}
+
+// inlineTable reads an inline table.
+func (p *tomlParser) inlineTable(depth int) (map[string]interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+		return table, nil
+	}
+	for {
+		if err := p.keyValue(table, depth+1); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("unterminated inline table")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, fmt.Errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// basicString reads a "basic string".
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\n':
+			return "", fmt.Errorf("unterminated string")
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// literalString reads a 'literal string'.
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.src[p.pos:], "'\n")
+	if end < 0 || p.src[p.pos+end] != '\'' {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// multilineString reads a multi-line basic or literal string opened by delim.
+func (p *tomlParser) multilineString(delim string) (string, error) {
+	p.pos += 3
+	// A newline right after the opening delimiter is trimmed
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if strings.HasPrefix(p.src[p.pos:], "\n") {
+		p.pos++
+		p.line++
+	}
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		if strings.HasPrefix(p.src[p.pos:], delim) {
+			// Up to two quotes may end the content just before the closing delimiter
+			end := p.pos + 3
+			for i := 0; i < 2 && end < len(p.src) && p.src[end] == delim[0]; i++ {
+				end++
+			}
+			b.WriteString(p.src[p.pos : end-3])
+			p.pos = end
+			return b.String(), nil
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && delim == `"""`:
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+				// A line ending backslash trims the whitespace up to the next non-blank character
+				p.pos++
+				for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+					if p.src[p.pos] == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// escape reads an escape sequence of a basic string.
+func (p *tomlParser) escape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.src) {
+		return fmt.Errorf("unterminated string")
+	}
+	c := p.src[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return fmt.Errorf("invalid escape sequence")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return fmt.Errorf("invalid escape sequence")
+		}
+		b.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape sequence '\\%c'", c)
+	}
+	return nil
+}
+
+// skipSpace skips spaces and tabs.
+func (p *tomlParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *tomlParser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.line++
+			p.pos++
+		case '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine checks that only a comment follows on the current line.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == '#' {
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		return fmt.Errorf("unexpected '%c'", p.src[p.pos])
+	}
+	return nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_' || c == '-'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// encodeTOML writes a document as TOML, with sorted keys. TOML has no null, so null values are
+// rejected.
+func encodeTOML(document map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, document); err != nil {
+		return nil, fmt.Errorf("failed to encode TOML: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTOMLTable writes the keys of the table at path, then its subtables.
+func writeTOMLTable(buf *bytes.Buffer, path []string, table map[string]interface{}) error {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := table[key]
+		if isTOMLTable(value) || isTOMLTableArray(value) {
+			continue
+		}
+		encoded, err := tomlValue(value, append(path, key))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), encoded)
+	}
+
+	for _, key := range keys {
+		childPath := append(path[:len(path):len(path)], key)
+		switch value := table[key].(type) {
+		case map[string]interface{}:
+			writeTOMLHeader(buf, "[%s]\n", childPath)
+			if err := writeTOMLTable(buf, childPath, value); err != nil {
+				return err
+			}
+		case []interface{}:
+			if !isTOMLTableArray(value) {
+				continue
+			}
+			for _, item := range value {
+				writeTOMLHeader(buf, "[[%s]]\n", childPath)
+				if err := writeTOMLTable(buf, childPath, item.(map[string]interface{})); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeTOMLHeader writes a table header, separated from the previous line.
+func writeTOMLHeader(buf *bytes.Buffer, format string, path []string) {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	fmt.Fprintf(buf, format, strings.Join(keys, "."))
+}
+
+func isTOMLTable(value interface{}) bool {
+	_, ok := value.(map[string]interface{})
+	return ok
+}
+
+// isTOMLTableArray reports whether value is a non-empty list of tables, written as [[headers]].
+func isTOMLTableArray(value interface{}) bool {
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlValue encodes an inline value.
+func tomlValue(value interface{}, path []string) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("'%s' is null, which TOML can't represent", strings.Join(path, "."))
+	case string:
+		return tomlString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return tomlFloat(v), nil
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return string(v), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a number", strings.Join(path, "."))
+		}
+		return tomlFloat(f), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			encoded, err := tomlValue(item, append(path, strconv.Itoa(i)))
+			if err != nil {
+				return "", err
+			}
+			items[i] = encoded
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			encoded, err := tomlValue(v[key], append(path, key))
+			if err != nil {
+				return "", err
+			}
+			pairs[i] = tomlKey(key) + " = " + encoded
+		}
+		return "{" + strings.Join(pairs, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("'%s' has unsupported type %T", strings.Join(path, "."), value)
+	}
+}
+
+// tomlFloat encodes a float, which TOML requires to have a fraction or an exponent.
+func tomlFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// tomlKey writes key bare when possible and quoted otherwise.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for i := 0; i < len(key); i++ {
+		if !isBareKeyChar(key[i]) {
+			return tomlString(key)
+		}
+	}
+	return key
+}
+
+// tomlString encodes a basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
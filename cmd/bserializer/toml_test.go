@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]interface{}
+	}{
+		{"scalars", "s = \"a\"\nn = 1_000\nf = 1.5e3\nb = true\n", map[string]interface{}{"s": "a", "n": int64(1000), "f": 1500.0, "b": true}},
+		{"integer bases", "h = 0xff\no = 0o17\nb = 0b101\n", map[string]interface{}{"h": int64(255), "o": int64(15), "b": int64(5)}},
+		{"comments and blank lines", "# header\n\na = 1 # trailing\n", map[string]interface{}{"a": int64(1)}},
+		{"escapes", `s = "tab\tquote\" \u00e9"`, map[string]interface{}{"s": "tab\tquote\" \u00e9"}},
+		{"literal string", `s = 'C:\path'`, map[string]interface{}{"s": `C:\path`}},
+		{"multiline string", "s = \"\"\"\nline one\nline two\"\"\"", map[string]interface{}{"s": "line one\nline two"}},
+		{"multiline literal string", "s = '''\nraw \\n'''", map[string]interface{}{"s": "raw \\n"}},
+		{"dates kept as strings", "d = 1979-05-27\nt = 1979-05-27T07:32:00Z\ns = 1979-05-27 07:32:00\nl = 07:32:00", map[string]interface{}{
+			"d": "1979-05-27", "t": "1979-05-27T07:32:00Z", "s": "1979-05-27 07:32:00", "l": "07:32:00",
+		}},
+		{"dotted keys", "a.b.c = 1\n\"quoted key\" = 2", map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": int64(1)}}, "quoted key": int64(2)}},
+		{"tables", "[server]\nhost = \"x\"\n[server.tls]\non = true", map[string]interface{}{"server": map[string]interface{}{"host": "x", "tls": map[string]interface{}{"on": true}}}},
+		{"array of tables", "[[item]]\nid = 1\n[[item]]\nid = 2", map[string]interface{}{"item": []interface{}{map[string]interface{}{"id": int64(1)}, map[string]interface{}{"id": int64(2)}}}},
+		{"arrays", "a = [1, [\"x\", 'y'],\n  {k = false}, ]", map[string]interface{}{"a": []interface{}{int64(1), []interface{}{"x", "y"}, map[string]interface{}{"k": false}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTOML([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("decodeTOML() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeTOML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTOMLSpecialFloats(t *testing.T) {
+	got, err := decodeTOML([]byte("p = inf\nm = -inf\nn = nan"))
+	if err != nil {
+		t.Fatalf("decodeTOML() error = %v", err)
+	}
+	if !math.IsInf(got["p"].(float64), 1) || !math.IsInf(got["m"].(float64), -1) || !math.IsNaN(got["n"].(float64)) {
+		t.Errorf("decodeTOML() = %v, want inf, -inf and nan", got)
+	}
+}
+
+func TestDecodeTOMLInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"missing value", "a = "},
+		{"missing equals", "a 1"},
+		{"duplicate key", "a = 1\na = 2"},
+		{"duplicate table", "[a]\n[a]"},
+		{"unterminated string", `a = "x`},
+		{"unterminated array", "a = [1, 2"},
+		{"bad number", "a = 1__0"},
+		{"two values on a line", "a = 1 b = 2"},
+		{"invalid UTF-8", "a = \"\xff\""},
+		{"too deep", "a = " + strings.Repeat("[", maxTOMLDepth+2) + strings.Repeat("]", maxTOMLDepth+2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := decodeTOML([]byte(tt.doc)); err == nil {
+				t.Errorf("decodeTOML() = %v, want an error", got)
+			}
+		})
+	}
+}
+
+func TestEncodeTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  map[string]interface{}
+		want string
+	}{
+		{"scalars", map[string]interface{}{"b": true, "s": "a\"b", "n": json.Number("3"), "f": 2.0}, "b = true\nf = 2.0\nn = 3\ns = \"a\\\"b\"\n"},
+		{"quoted keys", map[string]interface{}{"a b": 1}, "\"a b\" = 1\n"},
+		{"tables after keys", map[string]interface{}{"z": 1, "t": map[string]interface{}{"k": "v"}}, "z = 1\n\n[t]\nk = \"v\"\n"},
+		{"array of tables", map[string]interface{}{"item": []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}}}, "[[item]]\nid = 1\n\n[[item]]\nid = 2\n"},
+		{"inline values", map[string]interface{}{"a": []interface{}{1, "x", map[string]interface{}{"k": false}}}, "a = [1, \"x\", {k = false}]\n"},
+		{"control characters", map[string]interface{}{"s": "\x01\n"}, "s = \"\\u0001\\n\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeTOML(tt.doc)
+			if err != nil {
+				t.Fatalf("encodeTOML() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("encodeTOML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTOMLInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  map[string]interface{}
+		want string
+	}{
+		{"null", map[string]interface{}{"a": nil}, "'a' is null"},
+		{"nested null", map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": nil}}}, "'a.b' is null"},
+		{"unsupported type", map[string]interface{}{"a": struct{}{}}, "unsupported type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := encodeTOML(tt.doc); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("encodeTOML() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	doc := map[string]interface{}{
+		"title": "Example",
+		"owner": map[string]interface{}{"name": "Tom", "dob": "1979-05-27T07:32:00-08:00"},
+		"database": map[string]interface{}{
+			"ports":   []interface{}{int64(8000), int64(8001)},
+			"enabled": true,
+			"temp":    map[string]interface{}{"max": 79.5},
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"ip": "10.0.0.1", "role": "frontend"},
+			map[string]interface{}{"ip": "10.0.0.2", "role": "backend"},
+		},
+	}
+	encoded, err := encodeTOML(doc)
+	if err != nil {
+		t.Fatalf("encodeTOML() error = %v", err)
+	}
+	decoded, err := decodeTOML(encoded)
+	if err != nil {
+		t.Fatalf("decodeTOML(%s) error = %v", encoded, err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("round trip = %#v, want %#v", decoded, doc)
+	}
+}
@@ -0,0 +1,201 @@
+// Package httpserializer binds net/http requests to structs and renders responses through a
+// serializer.Serializer, handling content negotiation, validation and error formatting.
+// The helpers only depend on net/http, so they work with any router built on it, such as chi.
+package httpserializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxBodySize is the largest request body read by Bind and BindJSON.
+var MaxBodySize int64 = 10 << 20
+
+// jsonDecoder is implemented by serializers that decode JSON with their own duplicate key policy.
+type jsonDecoder interface {
+	DecodeJSON(data []byte) (map[string]interface{}, error)
+}
+
+// yamlDecoder is implemented by serializers that decode YAML with their own duplicate key policy.
+type yamlDecoder interface {
+	DecodeYAML(data []byte) (map[string]interface{}, error)
+}
+
+// contextValidator is implemented by serializers with context-aware validations.
+type contextValidator interface {
+	ValidateWithContext(ctx context.Context, data map[string]interface{}) error
+}
+
+// contextDeserializer is implemented by serializers with context-aware hooks.
+type contextDeserializer interface {
+	DeserializeWithContext(ctx context.Context, input map[string]interface{}, out interface{}) error
+}
+
+// formDeserializer is implemented by serializers that bind form data.
+type formDeserializer interface {
+	DeserializeFromURLValuesWithContext(ctx context.Context, values url.Values, out interface{}) error
+	DeserializeFromMultipartWithContext(ctx context.Context, form *multipart.Form, out interface{}) error
+}
+
+// BindJSON reads a JSON object from the body of r, validates it with s and deserializes it into out.
+// Requests declaring a Content-Type other than JSON are rejected. A nil s is looked up in
+// serializer.DefaultRegistry by the type of out.
+func BindJSON(r *http.Request, s serializer.Serializer, out interface{}) error {
+	s, err := resolve(s, out)
+	if err != nil {
+		return err
+	}
+	if mediaType := requestMediaType(r); mediaType != "" && serializer.FormatFromMediaType(mediaType) != serializer.FormatJSON {
+		return &serializer.SerializationError{Message: fmt.Sprintf("unsupported content type '%s'", mediaType)}
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	input, err := decodeJSON(s, body)
+	if err != nil {
+		return err
+	}
+	return bind(r.Context(), s, input, out)
+}
+
+// Bind is like BindJSON but also accepts YAML bodies, HTML forms (urlencoded or multipart) and the
+// formats registered with serializer.RegisterFormat, chosen by the Content-Type of r. Requests
+// without a Content-Type are read as JSON.
+func Bind(r *http.Request, s serializer.Serializer, out interface{}) error {
+	mediaType := requestMediaType(r)
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data" {
+		return bindForm(r, s, out, mediaType)
+	}
+	format := serializer.FormatFromMediaType(mediaType)
+	codec, custom := serializer.LookupFormat(format)
+	if format != serializer.FormatYAML && !custom {
+		return BindJSON(r, s, out)
+	}
+	s, err := resolve(s, out)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	var input map[string]interface{}
+	if custom {
+		if input, err = codec.Decode(body); err != nil {
+			err = &serializer.SerializationError{Message: fmt.Sprintf("failed to decode %s: %v", format, err), Err: err}
+		}
+	} else {
+		input, err = decodeYAML(s, body)
+	}
+	if err != nil {
+		return err
+	}
+	return bind(r.Context(), s, input, out)
+}
+
+// bindForm parses the form in the body of r, up to MaxBodySize bytes, and binds it to out.
+func bindForm(r *http.Request, s serializer.Serializer, out interface{}, mediaType string) error {
+	s, err := resolve(s, out)
+	if err != nil {
+		return err
+	}
+	d, ok := s.(formDeserializer)
+	if !ok {
+		return &serializer.SerializationError{Message: fmt.Sprintf("serializer doesn't support content type '%s'", mediaType)}
+	}
+	if r.Body == nil {
+		return &serializer.SerializationError{Message: "request body is empty"}
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, MaxBodySize)
+
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(MaxBodySize); err != nil {
+			return &serializer.SerializationError{Message: fmt.Sprintf("failed to parse multipart form: %v", err), Err: err}
+		}
+		return d.DeserializeFromMultipartWithContext(r.Context(), r.MultipartForm, out)
+	}
+	if err := r.ParseForm(); err != nil {
+		return &serializer.SerializationError{Message: fmt.Sprintf("failed to parse form: %v", err), Err: err}
+	}
+	return d.DeserializeFromURLValuesWithContext(r.Context(), r.PostForm, out)
+}
+
+// bind validates input and deserializes it into out.
+func bind(ctx context.Context, s serializer.Serializer, input map[string]interface{}, out interface{}) error {
+	var err error
+	if v, ok := s.(contextValidator); ok {
+		err = v.ValidateWithContext(ctx, input)
+	} else {
+		err = s.Validate(input)
+	}
+	if err != nil {
+		return err
+	}
+	if d, ok := s.(contextDeserializer); ok {
+		return d.DeserializeWithContext(ctx, input, out)
+	}
+	return s.Deserialize(input, out)
+}
+
+// readBody reads the body of r, up to MaxBodySize bytes.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, &serializer.SerializationError{Message: "request body is empty"}
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to read request body: %v", err), Err: err}
+	}
+	if int64(len(body)) > MaxBodySize {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("request body exceeds %d bytes", MaxBodySize)}
+	}
+	return body, nil
+}
+
+// decodeJSON parses a JSON object, using the serializer's decoder when it has one.
+func decodeJSON(s serializer.Serializer, body []byte) (map[string]interface{}, error) {
+	if d, ok := s.(jsonDecoder); ok {
+		return d.DecodeJSON(body)
+	}
+	var input map[string]interface{}
+	if err := json.Unmarshal(body, &input); err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to parse JSON: %v", err), Err: err}
+	}
+	return input, nil
+}
+
+// decodeYAML parses a YAML mapping, using the serializer's decoder when it has one.
+func decodeYAML(s serializer.Serializer, body []byte) (map[string]interface{}, error) {
+	if d, ok := s.(yamlDecoder); ok {
+		return d.DecodeYAML(body)
+	}
+	var input map[string]interface{}
+	if err := yaml.Unmarshal(body, &input); err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to parse YAML: %v", err), Err: err}
+	}
+	return input, nil
+}
+
+// requestMediaType returns the media type of the body of r, or "" if it isn't declared.
+func requestMediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
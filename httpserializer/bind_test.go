@@ -0,0 +1,131 @@
+package httpserializer
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+func TestBind(t *testing.T) {
+	var multipartBody bytes.Buffer
+	form := multipart.NewWriter(&multipartBody)
+	form.WriteField("name", "Ann")
+	form.WriteField("salary", "100")
+	form.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        employee
+		wantErr     string
+	}{
+		{"json", "application/json; charset=utf-8", `{"name": "Ann", "salary": 100}`, employee{"Ann", 100}, ""},
+		{"no content type", "", `{"name": "Ann"}`, employee{Name: "Ann"}, ""},
+		{"yaml", "application/yaml", "name: Ann\nsalary: 100\n", employee{"Ann", 100}, ""},
+		{"form", "application/x-www-form-urlencoded", "name=Ann&salary=100", employee{"Ann", 100}, ""},
+		{"multipart", form.FormDataContentType(), multipartBody.String(), employee{"Ann", 100}, ""},
+		{"malformed json", "application/json", `{"name": `, employee{}, "failed to parse JSON"},
+		{"malformed yaml", "application/yaml", "name: [", employee{}, "failed to parse YAML"},
+		{"unsupported", "text/plain", "Ann", employee{}, "unsupported content type 'text/plain'"},
+		{"invalid", "application/json", `{"name": ""}`, employee{}, "value cannot be empty"},
+		{"duplicate key", "application/json", `{"name": "Ann", "name": "Bob"}`, employee{}, "duplicate key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &serializer.BaseSerializer{
+				DuplicateKeys: serializer.DuplicateKeysError,
+				Validations:   map[string][]func(interface{}) error{"name": {serializer.NotEmpty}},
+			}
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			var got employee
+			err := Bind(r, s, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Bind() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Bind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           serializer.Serializer
+		contentType string
+		body        string
+		maxBodySize int64
+		wantErr     string
+	}{
+		{"valid", &serializer.BaseSerializer{}, "application/problem+json", `{"name": "Ann"}`, MaxBodySize, ""},
+		{"yaml rejected", &serializer.BaseSerializer{}, "application/yaml", "name: Ann", MaxBodySize, "unsupported content type 'application/yaml'"},
+		{"too large", &serializer.BaseSerializer{}, "application/json", `{"name": "Ann"}`, 8, "request body exceeds 8 bytes"},
+		{"enveloped", &serializer.BaseSerializer{Envelope: &serializer.Envelope{}, Validations: map[string][]func(interface{}) error{"name": {serializer.NotEmpty}}},
+			"application/json", `{"data": {"name": "Ann"}, "meta": {}}`, MaxBodySize, ""},
+		{"enveloped and invalid", &serializer.BaseSerializer{Envelope: &serializer.Envelope{}, Validations: map[string][]func(interface{}) error{"name": {serializer.NotEmpty}}},
+			"application/json", `{"data": {"name": ""}}`, MaxBodySize, "value cannot be empty"},
+		{"unregistered", nil, "application/json", `{"name": "Ann"}`, MaxBodySize, "no serializer registered for *httpserializer.employee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(size int64) { MaxBodySize = size }(MaxBodySize)
+			MaxBodySize = tt.maxBodySize
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			var got employee
+			err := BindJSON(r, tt.s, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("BindJSON() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.Name != "Ann" {
+				t.Errorf("BindJSON() = %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestBindDefaults(t *testing.T) {
+	s := &serializer.BaseSerializer{
+		Validations: map[string][]func(interface{}) error{"name": {serializer.NotEmpty}},
+		Defaults:    map[string]interface{}{"name": "Ann"},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"salary": 100}`))
+	r.Header.Set("Content-Type", "application/json")
+	var got employee
+	if err := Bind(r, s, &got); err != nil || got != (employee{"Ann", 100}) {
+		t.Errorf("Bind() = %+v, %v, want the default name", got, err)
+	}
+}
+
+func TestBindWarnings(t *testing.T) {
+	s := &serializer.BaseSerializer{RenamedFields: map[string]string{"full_name": "name"}}
+	warnings := &serializer.Warnings{}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"full_name": "Ann"}`))
+	r = r.WithContext(serializer.ContextWithWarnings(r.Context(), warnings))
+	var got employee
+	if err := Bind(r, s, &got); err != nil || got.Name != "Ann" {
+		t.Fatalf("Bind() = %+v, %v", got, err)
+	}
+	if list := warnings.List(); len(list) != 1 || list[0].Code != serializer.CodeDeprecated {
+		t.Errorf("Bind() warnings = %v, want a deprecation warning", list)
+	}
+}
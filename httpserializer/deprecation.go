@@ -0,0 +1,59 @@
+package httpserializer
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// Deprecation describes the deprecation of an endpoint, or of fields it still accepts, as
+// announced by SetDeprecation.
+type Deprecation struct {
+	Since  time.Time // When the deprecation took effect; the time of the call when zero
+	Sunset time.Time // When the endpoint or fields stop working; no Sunset header when zero
+	Link   string    // URL of the migration guide; no Link header when empty
+}
+
+// SetDeprecation announces a deprecation with the Deprecation header (RFC 9745), e.g.
+// "Deprecation: @1717200000", along with the Sunset header (RFC 8594) and a Link to the
+// migration guide with the "deprecation" relation type.
+func SetDeprecation(w http.ResponseWriter, d Deprecation) {
+	since := d.Since
+	if since.IsZero() {
+		since = time.Now()
+	}
+	w.Header().Set("Deprecation", fmt.Sprintf("@%d", since.Unix()))
+	if !d.Sunset.IsZero() {
+		w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, d.Link))
+	}
+}
+
+// AddWarnings adds a Warning header for each warning, such as those collected with
+// serializer.ContextWithWarnings while binding a request that sent deprecated fields.
+func AddWarnings(w http.ResponseWriter, warnings []*serializer.ValidationError) {
+	for _, warning := range warnings {
+		w.Header().Add("Warning", warning.WarningHeader())
+	}
+}
+
+// WarnDeprecated adds the Warning headers of the warnings with the serializer.CodeDeprecated code
+// and announces d with SetDeprecation when there is any. It reports whether there was.
+func WarnDeprecated(w http.ResponseWriter, warnings []*serializer.ValidationError, d Deprecation) bool {
+	var deprecated []*serializer.ValidationError
+	for _, warning := range warnings {
+		if warning.Code == serializer.CodeDeprecated {
+			deprecated = append(deprecated, warning)
+		}
+	}
+	if len(deprecated) == 0 {
+		return false
+	}
+	AddWarnings(w, deprecated)
+	SetDeprecation(w, d)
+	return true
+}
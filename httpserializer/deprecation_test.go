@@ -0,0 +1,81 @@
+package httpserializer
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+func TestSetDeprecation(t *testing.T) {
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name       string
+		d          Deprecation
+		wantSunset string
+		wantLink   string
+	}{
+		{"all headers", Deprecation{Since: since, Sunset: sunset, Link: "https://example.com/migrate"}, "Wed, 01 Jan 2025 11:00:00 GMT", `<https://example.com/migrate>; rel="deprecation"`},
+		{"deprecation only", Deprecation{Since: since}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			SetDeprecation(w, tt.d)
+			if got := w.Header().Get("Deprecation"); got != "@1717200000" {
+				t.Errorf("Deprecation = %q, want @1717200000", got)
+			}
+			if got := w.Header().Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	SetDeprecation(w, Deprecation{})
+	if got := w.Header().Get("Deprecation"); !strings.HasPrefix(got, "@") || got == "@0" {
+		t.Errorf("Deprecation without Since = %q, want the current time", got)
+	}
+}
+
+func TestWarnDeprecated(t *testing.T) {
+	deprecated := &serializer.ValidationError{Field: "full_name", Message: `use "name" instead`, Code: serializer.CodeDeprecated}
+	other := &serializer.ValidationError{Field: "nick", Message: "value is empty", Code: serializer.CodeEmpty}
+	tests := []struct {
+		name         string
+		warnings     []*serializer.ValidationError
+		want         bool
+		wantWarnings []string
+	}{
+		{"deprecated field", []*serializer.ValidationError{other, deprecated}, true, []string{`299 - "full_name: use \"name\" instead"`}},
+		{"other warnings", []*serializer.ValidationError{other}, false, nil},
+		{"none", nil, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if got := WarnDeprecated(w, tt.warnings, Deprecation{}); got != tt.want {
+				t.Errorf("WarnDeprecated() = %v, want %v", got, tt.want)
+			}
+			if got := w.Header().Values("Warning"); !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("Warning = %q, want %q", got, tt.wantWarnings)
+			}
+			if announced := w.Header().Get("Deprecation") != ""; announced != tt.want {
+				t.Errorf("Deprecation header set = %v, want %v", announced, tt.want)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	AddWarnings(w, []*serializer.ValidationError{other, deprecated})
+	if got := w.Header().Values("Warning"); len(got) != 2 {
+		t.Errorf("AddWarnings() headers = %q, want 2", got)
+	}
+}
@@ -0,0 +1,273 @@
+package httpserializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// Content types written by Render and Respond.
+const (
+	ContentTypeJSON = "application/json; charset=utf-8"
+	ContentTypeXML  = "application/xml; charset=utf-8"
+	ContentTypeYAML = "application/yaml; charset=utf-8"
+)
+
+// contextSerializer is implemented by serializers with context-aware pipeline stages.
+type contextSerializer interface {
+	SerializeWithContext(ctx context.Context, data interface{}, opts ...serializer.Option) (map[string]interface{}, error)
+}
+
+// formatSerializer is implemented by serializers encoding their output in a given format with
+// context-aware pipeline stages.
+type formatSerializer interface {
+	SerializeAsWithContext(ctx context.Context, data interface{}, format serializer.Format, opts ...serializer.Option) ([]byte, error)
+}
+
+// deprecationReporter is implemented by serializers that report deprecated fields.
+type deprecationReporter interface {
+	DeprecationWarnings(result map[string]interface{}) []string
+}
+
+// valueEncoder is implemented by serializers encoding serialized values in a given format.
+type valueEncoder interface {
+	EncodeAs(value interface{}, format serializer.Format) ([]byte, error)
+}
+
+// jsonEncoder is implemented by serializers with their own JSON layout.
+type jsonEncoder interface {
+	EncodeJSON(value interface{}) ([]byte, error)
+}
+
+// Render writes data serialized with s along with status. The format follows the Content-Type
+// already set on w (JSON, XML, YAML or a format registered with serializer.RegisterFormat), and is
+// JSON when none is set. Slices and arrays are rendered as a list of serialized elements, except in
+// registered formats. A nil s is looked up in serializer.DefaultRegistry by the type of data. Serialization failures are written with RenderError and returned.
+func Render(w http.ResponseWriter, s serializer.Serializer, data interface{}, status int) error {
+	s, err := resolve(s, data)
+	if err != nil {
+		RenderError(w, err)
+		return err
+	}
+	return render(context.Background(), w, s, data, status, responseFormat(w))
+}
+
+// Respond is like Render but negotiates the format from the Accept header of r and passes the
+// request context to the serializer, so context-aware transformations, roles and locales apply.
+// When the header accepts no supported format, a 406 Not Acceptable problem is written and returned.
+func Respond(w http.ResponseWriter, r *http.Request, s serializer.Serializer, data interface{}, status int) error {
+	w.Header().Add("Vary", "Accept")
+	s, err := resolve(s, data)
+	if err != nil {
+		RenderError(w, err)
+		return err
+	}
+	accept := r.Header.Get("Accept")
+	format := serializer.Negotiate(accept)
+	if format == "" {
+		err := &serializer.SerializationError{Message: fmt.Sprintf("no supported format is acceptable for '%s'", accept)}
+		problem := serializer.NewProblem(err)
+		problem.Status, problem.Title = http.StatusNotAcceptable, http.StatusText(http.StatusNotAcceptable)
+		writeProblem(w, problem)
+		return err
+	}
+	return render(r.Context(), w, s, data, status, format)
+}
+
+// RenderError writes err as an RFC 7807 problem document, with the status chosen by serializer.NewProblem.
+func RenderError(w http.ResponseWriter, err error) {
+	writeProblem(w, serializer.NewProblem(err))
+}
+
+// writeProblem writes an RFC 7807 problem document with its status.
+func writeProblem(w http.ResponseWriter, problem *serializer.Problem) {
+	body, _ := json.Marshal(problem) // A Problem only holds strings and ints
+	w.Header().Set("Content-Type", serializer.ProblemContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(body)
+}
+
+// resolve returns s, or the serializer registered in serializer.DefaultRegistry for the type of data when s is nil.
+func resolve(s serializer.Serializer, data interface{}) (serializer.Serializer, error) {
+	if s != nil {
+		return s, nil
+	}
+	if registered, ok := serializer.LookupSerializer(data); ok {
+		return registered, nil
+	}
+	return nil, &serializer.SerializationError{Message: fmt.Sprintf("no serializer registered for %T", data)}
+}
+
+// render serializes data in the given format and writes the response, with a Warning header for
+// every deprecated field of the output.
+func render(ctx context.Context, w http.ResponseWriter, s serializer.Serializer, data interface{}, status int, format serializer.Format) error {
+	var body []byte
+	var warnings []string
+	var contentType string
+	switch format {
+	case serializer.FormatXML, serializer.FormatYAML:
+		encoded, markupWarnings, err := encodeMarkup(ctx, s, data, format)
+		if err != nil {
+			RenderError(w, err)
+			return err
+		}
+		body, warnings, contentType = encoded, markupWarnings, ContentTypeXML
+		if format == serializer.FormatYAML {
+			contentType = ContentTypeYAML
+		}
+	default:
+		value, valueWarnings, err := serializeValue(ctx, s, data)
+		if err != nil {
+			RenderError(w, err)
+			return err
+		}
+		warnings = valueWarnings
+		if codec, ok := serializer.LookupFormat(format); ok {
+			if body, err = encodeWithCodec(codec, format, value); err != nil {
+				RenderError(w, err)
+				return err
+			}
+			contentType = format.ContentType()
+			break
+		}
+		if encoder, ok := s.(jsonEncoder); ok {
+			body, err = encoder.EncodeJSON(value)
+		} else if body, err = json.Marshal(value); err != nil {
+			err = &serializer.SerializationError{Message: fmt.Sprintf("failed to encode JSON: %v", err), Err: err}
+		}
+		if err != nil {
+			RenderError(w, err)
+			return err
+		}
+		contentType = ContentTypeJSON
+	}
+
+	for _, warning := range warnings {
+		w.Header().Add("Warning", warning)
+	}
+	w.Header().Set("Content-Type", contentType)
+	if !bodyAllowed(status) {
+		w.WriteHeader(status)
+		return nil
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	_, err := w.Write(body)
+	return err
+}
+
+// encodeMarkup serializes data as XML or YAML, passing ctx to serializers with context-aware
+// pipeline stages, and returns the deprecation warnings of the output. Serializers reporting
+// deprecated fields encode the results they were collected from, so that data is serialized once.
+// Serializers without context-aware stages only encode single objects.
+func encodeMarkup(ctx context.Context, s serializer.Serializer, data interface{}, format serializer.Format) ([]byte, []string, error) {
+	if encoder, ok := s.(valueEncoder); ok {
+		if _, reports := s.(deprecationReporter); reports {
+			value, warnings, err := serializeValue(ctx, s, data)
+			if err != nil {
+				return nil, nil, err
+			}
+			encoded, err := encoder.EncodeAs(value, format)
+			return encoded, warnings, err
+		}
+	}
+	if fs, ok := s.(formatSerializer); ok {
+		encoded, err := fs.SerializeAsWithContext(ctx, data, format)
+		return encoded, nil, err
+	}
+	var encoded string
+	var err error
+	if format == serializer.FormatXML {
+		encoded, err = s.SerializeToXML(data)
+	} else {
+		encoded, err = s.SerializeToYAML(data)
+	}
+	return []byte(encoded), nil, err
+}
+
+// encodeWithCodec encodes a serialized object in a format registered with serializer.RegisterFormat.
+func encodeWithCodec(codec serializer.FormatCodec, format serializer.Format, value interface{}) ([]byte, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("format '%s' can only encode a single object", format)}
+	}
+	body, err := codec.Encode(object)
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to encode %s: %v", format, err), Err: err}
+	}
+	return body, nil
+}
+
+// serializeValue serializes data, or every element of data when it is a slice or an array,
+// and collects the deprecation warnings of the results.
+func serializeValue(ctx context.Context, s serializer.Serializer, data interface{}) (interface{}, []string, error) {
+	if data == nil {
+		return nil, nil, nil
+	}
+
+	warnings := make(map[string]bool)
+	serialize := func(item interface{}) (map[string]interface{}, error) {
+		var result map[string]interface{}
+		var err error
+		if cs, ok := s.(contextSerializer); ok {
+			result, err = cs.SerializeWithContext(ctx, item)
+		} else {
+			result, err = s.Serialize(item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reporter, ok := s.(deprecationReporter); ok {
+			for _, warning := range reporter.DeprecationWarnings(result) {
+				warnings[warning] = true
+			}
+		}
+		return result, nil
+	}
+
+	var value interface{}
+	v := reflect.ValueOf(data)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		results := make([]map[string]interface{}, v.Len())
+		for i := range results {
+			result, err := serialize(v.Index(i).Interface())
+			if err != nil {
+				return nil, nil, &serializer.BulkError{Index: i, Err: err}
+			}
+			results[i] = result
+		}
+		value = results
+	} else {
+		result, err := serialize(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		value = result
+	}
+
+	sorted := make([]string, 0, len(warnings))
+	for warning := range warnings {
+		sorted = append(sorted, warning)
+	}
+	sort.Strings(sorted)
+	return value, sorted, nil
+}
+
+// responseFormat returns the format matching the Content-Type already set on w, JSON by default.
+func responseFormat(w http.ResponseWriter) serializer.Format {
+	if format := serializer.FormatFromMediaType(w.Header().Get("Content-Type")); format != "" {
+		return format
+	}
+	return serializer.FormatJSON
+}
+
+// bodyAllowed reports whether a response with the given status may have a body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
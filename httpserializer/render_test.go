@@ -0,0 +1,109 @@
+package httpserializer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+type employee struct {
+	Name   string `json:"name"`
+	Salary int    `json:"salary"`
+}
+
+func TestRespondFormats(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		data        interface{}
+		contentType string
+		contains    []string
+	}{
+		{"json", "application/json", employee{"Ann", 100}, ContentTypeJSON, []string{`"salary":100`}},
+		{"xml", "application/xml", employee{"Ann", 100}, ContentTypeXML, []string{"<salary>100</salary>"}},
+		{"yaml", "application/yaml", employee{"Ann", 100}, ContentTypeYAML, []string{"salary: 100"}},
+		{"json list", "application/json", []employee{{"Ann", 100}, {"Bob", 90}}, ContentTypeJSON, []string{`"salary":100`, `"salary":90`}},
+		{"xml list", "application/xml", []employee{{"Ann", 100}, {"Bob", 90}}, ContentTypeXML, []string{"<item>", "<salary>100</salary>", "<salary>90</salary>"}},
+		{"yaml list", "application/yaml", []employee{{"Ann", 100}, {"Bob", 90}}, ContentTypeYAML, []string{"- name: Ann", "salary: 90"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &serializer.BaseSerializer{FieldPermissions: map[string][]string{"salary": {"admin"}}}
+			for _, roles := range [][]string{{"admin"}, nil} {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("Accept", tt.accept)
+				r = r.WithContext(serializer.WithRoles(r.Context(), roles...))
+				w := httptest.NewRecorder()
+				if err := Respond(w, r, s, tt.data, http.StatusOK); err != nil {
+					t.Fatalf("Respond() error = %v", err)
+				}
+				if w.Code != http.StatusOK || w.Header().Get("Content-Type") != tt.contentType {
+					t.Fatalf("Respond() = %d %q, want 200 %q", w.Code, w.Header().Get("Content-Type"), tt.contentType)
+				}
+				body := w.Body.String()
+				if roles == nil {
+					if strings.Contains(body, "salary") {
+						t.Errorf("Respond() without roles = %s, want no salary", body)
+					}
+					continue
+				}
+				for _, want := range tt.contains {
+					if !strings.Contains(body, want) {
+						t.Errorf("Respond() = %s, want it to contain %q", body, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRespondNotAcceptable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/json;q=0, text/html")
+	w := httptest.NewRecorder()
+	if err := Respond(w, r, &serializer.BaseSerializer{}, employee{"Ann", 100}, http.StatusOK); err == nil {
+		t.Fatal("Respond() error = nil, want an error")
+	}
+	if w.Code != http.StatusNotAcceptable || w.Header().Get("Content-Type") != serializer.ProblemContentType {
+		t.Errorf("Respond() = %d %q, want 406 %q", w.Code, w.Header().Get("Content-Type"), serializer.ProblemContentType)
+	}
+}
+
+func TestRenderDeprecationWarnings(t *testing.T) {
+	type profile struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	}
+	s := &serializer.BaseSerializer{DeprecatedFields: map[string]string{"full_name": `use "name" instead`}}
+	tests := []struct {
+		name   string
+		accept string
+		data   interface{}
+	}{
+		{"json", "application/json", profile{"Ann", "Ann Lee"}},
+		{"xml", "application/xml", profile{"Ann", "Ann Lee"}},
+		{"yaml", "application/yaml", profile{"Ann", "Ann Lee"}},
+		{"xml list", "application/xml", []profile{{"Ann", "Ann Lee"}, {"Bob", "Bob Ray"}}},
+		{"yaml list", "application/yaml", []profile{{"Ann", "Ann Lee"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			if err := Respond(w, r, s, tt.data, http.StatusOK); err != nil {
+				t.Fatalf("Respond() error = %v", err)
+			}
+			if got := w.Header().Values("Warning"); len(got) != 1 || !strings.Contains(got[0], "full_name") {
+				t.Errorf("Warning = %q, want one warning about full_name", got)
+			}
+			if !strings.Contains(w.Body.String(), "Ann Lee") {
+				t.Errorf("Respond() = %s, want the deprecated field", w.Body.String())
+			}
+		})
+	}
+
+}
@@ -0,0 +1,141 @@
+// Package messaging wraps the bserializer pipeline into queue messages, so the producers and the
+// consumers of an event share one definition of its shape. An Event serializes values into a
+// Message (key, headers and payload) and validates and deserializes incoming ones. Messages are
+// plain values that map directly onto the records of Kafka, NATS or AMQP clients.
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+// Headers set on the messages written by Event.
+const (
+	HeaderContentType = "content-type" // Media type of the payload
+	HeaderEventType   = "event-type"   // Event.Type
+)
+
+// Message is a queue message.
+type Message struct {
+	Key     []byte
+	Headers map[string]string
+	Value   []byte
+}
+
+// Event defines the shape of an event: the serializer of its payload, the format it is encoded
+// in and the serialized field holding the message key.
+type Event struct {
+	Type       string                     // Event type, written to the event-type header and checked on Decode
+	Serializer *serializer.BaseSerializer // Serializer of the payload
+	Format     serializer.Format          // Format of the payload: FormatJSON (when empty), FormatYAML or FormatBinary
+	KeyField   string                     // Serialized field used as the message key; no key when empty
+	Headers    map[string]string          // Extra headers set on every message
+}
+
+// NewEvent creates an Event of the given type with JSON payloads.
+func NewEvent(eventType string, s *serializer.BaseSerializer) *Event {
+	if s == nil {
+		s = &serializer.BaseSerializer{}
+	}
+	return &Event{Type: eventType, Serializer: s, Format: serializer.FormatJSON}
+}
+
+// Encode serializes data into a message.
+func (e *Event) Encode(ctx context.Context, data interface{}) (*Message, error) {
+	result, err := e.Serializer.SerializeWithContext(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	value, err := e.encode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{Value: value, Headers: make(map[string]string, len(e.Headers)+2)}
+	for name, header := range e.Headers {
+		msg.Headers[name] = header
+	}
+	msg.Headers[HeaderContentType] = e.format().ContentType()
+	if e.Type != "" {
+		msg.Headers[HeaderEventType] = e.Type
+	}
+	if e.KeyField != "" {
+		key, exists := result[e.KeyField]
+		if !exists || key == nil {
+			return nil, &serializer.ValidationError{Field: e.KeyField, Message: "message key is missing", Code: serializer.CodeRequired}
+		}
+		msg.Key = []byte(fmt.Sprint(key))
+	}
+	return msg, nil
+}
+
+// Decode validates the payload of msg and deserializes it into out. Messages of another event
+// type are rejected. The payload is decoded in the format of its content-type header, or in the
+// event's Format without one.
+func (e *Event) Decode(ctx context.Context, msg *Message, out interface{}) error {
+	if eventType, ok := msg.Headers[HeaderEventType]; ok && e.Type != "" && eventType != e.Type {
+		return &serializer.SerializationError{Message: fmt.Sprintf("unexpected event type '%s', expected '%s'", eventType, e.Type)}
+	}
+	input, err := e.decode(msg)
+	if err != nil {
+		return err
+	}
+	if err := e.Serializer.ValidateWithContext(ctx, input); err != nil {
+		return err
+	}
+	return e.Serializer.DeserializeWithContext(ctx, input, out)
+}
+
+// format returns the format payloads are written in.
+func (e *Event) format() serializer.Format {
+	if e.Format == "" {
+		return serializer.FormatJSON
+	}
+	return e.Format
+}
+
+// encode encodes serialized output in the event's format, with sorted keys so equal events
+// produce equal payloads.
+func (e *Event) encode(result map[string]interface{}) ([]byte, error) {
+	switch format := e.format(); format {
+	case serializer.FormatJSON, serializer.FormatYAML:
+		return serializer.EncodeSorted(result, format)
+	case serializer.FormatBinary:
+		return serializer.EncodeBinary(result)
+	default:
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("unsupported message format '%s'", format)}
+	}
+}
+
+// decode decodes the payload of msg into a map.
+func (e *Event) decode(msg *Message) (map[string]interface{}, error) {
+	format := e.format()
+	if contentType, ok := msg.Headers[HeaderContentType]; ok {
+		if format = formatOf(contentType); format == "" {
+			return nil, &serializer.SerializationError{Message: fmt.Sprintf("unsupported content type '%s'", contentType)}
+		}
+	}
+
+	s := e.Serializer
+	switch format {
+	case serializer.FormatJSON:
+		return s.DecodeJSON(msg.Value)
+	case serializer.FormatYAML:
+		return s.DecodeYAML(msg.Value)
+	case serializer.FormatBinary:
+		return s.DecodeBinary(msg.Value)
+	default:
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("unsupported message format '%s'", format)}
+	}
+}
+
+// formatOf returns the format of a content-type header, including the binary format, which
+// isn't negotiated over HTTP.
+func formatOf(contentType string) serializer.Format {
+	if contentType == serializer.FormatBinary.ContentType() {
+		return serializer.FormatBinary
+	}
+	return serializer.FormatFromMediaType(contentType)
+}
@@ -0,0 +1,103 @@
+package messaging
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+type orderPlaced struct {
+	ID    int     `json:"id"`
+	Total float64 `json:"total"`
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      serializer.Format
+		contentType string
+		wantValue   string
+	}{
+		{"default", "", "application/json", `{"id":7,"total":9.5}`},
+		{"yaml", serializer.FormatYAML, "application/yaml", "id: 7\ntotal: 9.5\n"},
+		{"binary", serializer.FormatBinary, serializer.FormatBinary.ContentType(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &Event{Type: "order.placed", Serializer: &serializer.BaseSerializer{}, Format: tt.format, KeyField: "id", Headers: map[string]string{"source": "shop"}}
+			msg, err := event.Encode(context.Background(), orderPlaced{ID: 7, Total: 9.5})
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if string(msg.Key) != "7" || msg.Headers[HeaderEventType] != "order.placed" || msg.Headers[HeaderContentType] != tt.contentType || msg.Headers["source"] != "shop" {
+				t.Errorf("Encode() = key %q, headers %v", msg.Key, msg.Headers)
+			}
+			if tt.wantValue != "" && string(msg.Value) != tt.wantValue {
+				t.Errorf("Encode() value = %q, want %q", msg.Value, tt.wantValue)
+			}
+
+			var got orderPlaced
+			if err := event.Decode(context.Background(), msg, &got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got != (orderPlaced{ID: 7, Total: 9.5}) {
+				t.Errorf("Decode() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestEventEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   *Event
+		wantErr string
+	}{
+		{"missing key", &Event{Serializer: &serializer.BaseSerializer{ExcludedFields: []string{"id"}}, KeyField: "id"}, "message key is missing"},
+		{"unsupported format", &Event{Serializer: &serializer.BaseSerializer{}, Format: serializer.FormatXML}, "unsupported message format 'xml'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.event.Encode(context.Background(), orderPlaced{ID: 7}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Encode() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventDecode(t *testing.T) {
+	event := NewEvent("order.placed", &serializer.BaseSerializer{Validations: map[string][]func(interface{}) error{"total": {serializer.Positive}}})
+	tests := []struct {
+		name    string
+		msg     *Message
+		wantErr string
+	}{
+		{"no headers", &Message{Value: []byte(`{"id": 7, "total": 1}`)}, ""},
+		{"content type of the payload", &Message{Headers: map[string]string{HeaderContentType: "application/yaml"}, Value: []byte("id: 7\ntotal: 1\n")}, ""},
+		{"other event type", &Message{Headers: map[string]string{HeaderEventType: "order.shipped"}, Value: []byte(`{}`)}, "unexpected event type 'order.shipped', expected 'order.placed'"},
+		{"unsupported content type", &Message{Headers: map[string]string{HeaderContentType: "text/csv"}, Value: []byte("id\n7\n")}, "unsupported content type 'text/csv'"},
+		{"malformed payload", &Message{Value: []byte(`{"id": `)}, "failed to parse JSON"},
+		{"invalid payload", &Message{Value: []byte(`{"id": 7, "total": 0}`)}, "value must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got orderPlaced
+			err := event.Decode(context.Background(), tt.msg, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.ID != 7 {
+				t.Errorf("Decode() = %+v, %v", got, err)
+			}
+		})
+	}
+
+	if event := NewEvent("", nil); event.Serializer == nil || event.Format != serializer.FormatJSON {
+		t.Errorf("NewEvent() = %+v, want a JSON event with a serializer", event)
+	}
+}
@@ -0,0 +1,143 @@
+// Package protoserializer runs protobuf messages through the bserializer pipeline, so REST and
+// gRPC services can share one definition of field filtering, transformations and validations.
+// Messages are converted with protojson semantics: keys are the lowerCamelCase JSON names of the
+// fields (or the proto names with UseProtoNames), 64-bit integers are strings, enums are names
+// and well-known types use their canonical JSON form.
+package protoserializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtoSerializer serializes protobuf messages with the configuration of a BaseSerializer.
+// Field names in the serializer configuration are the protojson keys of the message.
+type ProtoSerializer struct {
+	Serializer       *serializer.BaseSerializer
+	MarshalOptions   protojson.MarshalOptions   // Options used to convert messages into maps
+	UnmarshalOptions protojson.UnmarshalOptions // Options used to convert maps into messages
+}
+
+// New creates a ProtoSerializer for s. Unknown fields are discarded on Deserialize.
+func New(s *serializer.BaseSerializer) *ProtoSerializer {
+	if s == nil {
+		s = &serializer.BaseSerializer{}
+	}
+	return &ProtoSerializer{
+		Serializer:       s,
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}
+}
+
+// Serialize converts msg into a map and applies the serializer pipeline to it.
+func (p *ProtoSerializer) Serialize(msg proto.Message) (map[string]interface{}, error) {
+	return p.SerializeWithContext(context.Background(), msg)
+}
+
+// SerializeWithContext is like Serialize, passing ctx to the context-aware pipeline stages.
+func (p *ProtoSerializer) SerializeWithContext(ctx context.Context, msg proto.Message) (map[string]interface{}, error) {
+	encoded, err := p.MarshalOptions.Marshal(msg)
+	if err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to convert message to JSON: %v", err), Err: err}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, &serializer.SerializationError{Message: fmt.Sprintf("failed to convert message to map: %v", err), Err: err}
+	}
+	return p.Serializer.SerializeWithContext(ctx, fields)
+}
+
+// Deserialize fills msg from input. Keys renamed by the serializer's KeyNaming are accepted,
+// read-only fields are ignored (or rejected with RejectReadOnly) and Defaults fill missing fields.
+// msg is reset first.
+func (p *ProtoSerializer) Deserialize(input map[string]interface{}, msg proto.Message) error {
+	s := p.Serializer
+	fields := msg.ProtoReflect().Descriptor().Fields()
+
+	// Accept converted keys for every spelling protojson understands
+	names := make(map[string]string, fields.Len()*2)
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		for _, name := range []string{field.JSONName(), string(field.Name())} {
+			names[serializer.ConvertKey(name, s.KeyNaming, s.Acronyms)] = name
+		}
+	}
+
+	prepared := make(map[string]interface{}, len(input)+len(s.Defaults))
+	for key, value := range input {
+		if name, ok := names[key]; ok {
+			key = name
+		}
+		if isReadOnly(s, fields, key) {
+			if s.RejectReadOnly {
+				return &serializer.ValidationError{Field: key, Value: value, Message: "field is read-only", Code: serializer.CodeReadOnly}
+			}
+			continue
+		}
+		prepared[key] = value
+	}
+	for field, value := range s.Defaults {
+		if hasField(prepared, fields, field) {
+			continue
+		}
+		if factory, ok := value.(func() interface{}); ok {
+			value = factory()
+		}
+		prepared[field] = value
+	}
+
+	encoded, err := json.Marshal(prepared)
+	if err != nil {
+		return &serializer.SerializationError{Message: fmt.Sprintf("failed to convert map to JSON: %v", err), Err: err}
+	}
+	proto.Reset(msg)
+	if err := p.UnmarshalOptions.Unmarshal(encoded, msg); err != nil {
+		return &serializer.SerializationError{Message: fmt.Sprintf("failed to deserialize JSON to message: %v", err), Err: err}
+	}
+	return nil
+}
+
+// Validate checks input against the serializer's validations.
+func (p *ProtoSerializer) Validate(input map[string]interface{}) error {
+	return p.Serializer.Validate(input)
+}
+
+// isReadOnly reports whether key names a read-only field, under either of its protojson spellings.
+func isReadOnly(s *serializer.BaseSerializer, fields protoreflect.FieldDescriptors, key string) bool {
+	for _, name := range spellings(fields, key) {
+		for _, readOnly := range s.ReadOnlyFields {
+			if readOnly == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasField reports whether input sets the field named key, under either of its protojson spellings.
+func hasField(input map[string]interface{}, fields protoreflect.FieldDescriptors, key string) bool {
+	for _, name := range spellings(fields, key) {
+		if _, ok := input[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// spellings returns the JSON and proto names of the field named key, or key alone if there is no such field.
+func spellings(fields protoreflect.FieldDescriptors, key string) []string {
+	field := fields.ByJSONName(key)
+	if field == nil {
+		field = fields.ByName(protoreflect.Name(key))
+	}
+	if field == nil {
+		return []string{key}
+	}
+	return []string{field.JSONName(), string(field.Name())}
+}
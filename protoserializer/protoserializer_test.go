@@ -0,0 +1,89 @@
+package protoserializer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestSerialize(t *testing.T) {
+	method := &apipb.Method{Name: "GetUser", RequestTypeUrl: "type.googleapis.com/GetUserRequest", ResponseStreaming: true, Syntax: typepb.Syntax_SYNTAX_PROTO3}
+	tests := []struct {
+		name string
+		p    *ProtoSerializer
+		want map[string]interface{}
+	}{
+		{"json names", New(&serializer.BaseSerializer{ExcludedFields: []string{"responseStreaming"}}),
+			map[string]interface{}{"name": "GetUser", "requestTypeUrl": "type.googleapis.com/GetUserRequest", "syntax": "SYNTAX_PROTO3"}},
+		{"proto names", &ProtoSerializer{Serializer: &serializer.BaseSerializer{Fields: []string{"request_type_url"}}, MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}},
+			map[string]interface{}{"request_type_url": "type.googleapis.com/GetUserRequest"}},
+		{"key naming", New(&serializer.BaseSerializer{Fields: []string{"requestTypeUrl"}, KeyNaming: serializer.NamingSnakeCase}),
+			map[string]interface{}{"request_type_url": "type.googleapis.com/GetUserRequest"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.Serialize(method)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserialize(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       *serializer.BaseSerializer
+		input   map[string]interface{}
+		want    *apipb.Method
+		wantErr string
+	}{
+		{"json and proto names", &serializer.BaseSerializer{}, map[string]interface{}{"name": "GetUser", "request_type_url": "a", "responseStreaming": true, "unknown": 1},
+			&apipb.Method{Name: "GetUser", RequestTypeUrl: "a", ResponseStreaming: true}, ""},
+		{"converted keys", &serializer.BaseSerializer{KeyNaming: serializer.NamingKebabCase}, map[string]interface{}{"request-type-url": "a", "syntax": "SYNTAX_PROTO3"},
+			&apipb.Method{RequestTypeUrl: "a", Syntax: typepb.Syntax_SYNTAX_PROTO3}, ""},
+		{"read-only under either name", &serializer.BaseSerializer{ReadOnlyFields: []string{"request_type_url"}}, map[string]interface{}{"name": "GetUser", "requestTypeUrl": "a"},
+			&apipb.Method{Name: "GetUser"}, ""},
+		{"rejected read-only", &serializer.BaseSerializer{ReadOnlyFields: []string{"name"}, RejectReadOnly: true}, map[string]interface{}{"name": "GetUser"}, nil, "field is read-only"},
+		{"defaults", &serializer.BaseSerializer{Defaults: map[string]interface{}{"request_type_url": "default", "name": func() interface{} { return "Generated" }}},
+			map[string]interface{}{"requestTypeUrl": "a"}, &apipb.Method{Name: "Generated", RequestTypeUrl: "a"}, ""},
+		{"wrong type", &serializer.BaseSerializer{}, map[string]interface{}{"name": 1}, nil, "failed to deserialize JSON to message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &apipb.Method{Name: "stale", Options: []*typepb.Option{{Name: "stale"}}}
+			err := New(tt.s).Deserialize(tt.input, got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Deserialize() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if got.Name != tt.want.Name || got.RequestTypeUrl != tt.want.RequestTypeUrl || got.ResponseStreaming != tt.want.ResponseStreaming ||
+				got.Syntax != tt.want.Syntax || len(got.Options) != 0 {
+				t.Errorf("Deserialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := New(&serializer.BaseSerializer{Validations: map[string][]func(interface{}) error{"name": {serializer.NotEmpty}}})
+	if err := p.Validate(map[string]interface{}{"name": ""}); serializer.ErrorCode(err) != serializer.CodeEmpty {
+		t.Errorf("Validate() error = %v, want %s", err, serializer.CodeEmpty)
+	}
+	if p := New(nil); p.Serializer == nil || !p.UnmarshalOptions.DiscardUnknown {
+		t.Errorf("New(nil) = %+v", p)
+	}
+}
@@ -0,0 +1,92 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// validateContextFields runs the ContextValidations on data. With ValidationTimeout, they share a
+// deadline, and with ParallelValidations, the validations of each field run in their own
+// goroutine, in order within the field. The first failure cancels the context of the others.
+func (s *BaseSerializer) validateContextFields(ctx context.Context, data map[string]interface{}) error {
+	if len(s.ContextValidations) == 0 {
+		return nil
+	}
+	if s.ValidationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.ValidationTimeout)
+		defer cancel()
+	}
+
+	fields := make([]string, 0, len(s.ContextValidations))
+	for field := range s.ContextValidations {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	if !s.ParallelValidations || len(fields) == 1 {
+		for _, field := range fields {
+			if err := s.validateContextField(ctx, field, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make([]error, len(fields))
+	var wg sync.WaitGroup
+	for i, field := range fields {
+		wg.Add(1)
+		go func(i int, field string) {
+			defer wg.Done()
+			if errs[i] = s.validateContextField(ctx, field, data); errs[i] != nil {
+				cancel()
+			}
+		}(i, field)
+	}
+	wg.Wait()
+
+	// Report the first failure in field order, skipping the validations stopped by the cancellation
+	var canceled error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled):
+			if canceled == nil {
+				canceled = err
+			}
+		default:
+			return err
+		}
+	}
+	return canceled
+}
+
+// validateContextField runs the ContextValidations of a field in order.
+func (s *BaseSerializer) validateContextField(ctx context.Context, field string, data map[string]interface{}) error {
+	value, exists := data[field]
+	if !exists {
+		return &ValidationError{
+			Field:   field,
+			Message: s.translate(ctx, CodeRequired, nil, "field is missing"),
+			Code:    CodeRequired,
+		}
+	}
+	for _, validation := range s.ContextValidations[field] {
+		err := validation(ctx, value)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == context.DeadlineExceeded {
+			return &ValidationError{Field: field, Value: value, Message: s.translate(ctx, CodeValidationTimeout, nil, "validation timed out"), Code: CodeValidationTimeout, Err: err}
+		}
+		if err := s.validationFailure(ctx, field, value, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
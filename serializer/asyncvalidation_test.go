@@ -0,0 +1,93 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor returns a context validation that takes d unless its context is done first.
+func waitFor(d time.Duration) func(context.Context, interface{}) error {
+	return func(ctx context.Context, _ interface{}) error {
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestContextValidations(t *testing.T) {
+	fail := func(context.Context, interface{}) error { return NewCodedError("taken", "already taken") }
+	tests := []struct {
+		name        string
+		s           *BaseSerializer
+		data        map[string]interface{}
+		wantField   string
+		wantCode    string
+		maxDuration time.Duration
+	}{
+		{"valid", &BaseSerializer{ContextValidations: map[string][]func(context.Context, interface{}) error{
+			"name": {waitFor(0)}, "role": {waitFor(0)},
+		}}, map[string]interface{}{"name": "ana", "role": "admin"}, "", "", time.Second},
+		{"missing field", &BaseSerializer{ContextValidations: map[string][]func(context.Context, interface{}) error{
+			"name": {waitFor(0)},
+		}}, map[string]interface{}{}, "name", CodeRequired, time.Second},
+		{"first failure in field order", &BaseSerializer{ContextValidations: map[string][]func(context.Context, interface{}) error{
+			"role": {fail}, "name": {fail},
+		}}, map[string]interface{}{"name": "ana", "role": "admin"}, "name", "taken", time.Second},
+		{"timeout", &BaseSerializer{ValidationTimeout: 10 * time.Millisecond, ContextValidations: map[string][]func(context.Context, interface{}) error{
+			"name": {waitFor(time.Minute)},
+		}}, map[string]interface{}{"name": "ana"}, "name", CodeValidationTimeout, 30 * time.Second},
+		{"parallel failure cancels the others", &BaseSerializer{ParallelValidations: true, ContextValidations: map[string][]func(context.Context, interface{}) error{
+			"name": {waitFor(time.Minute)}, "role": {waitFor(time.Millisecond), fail},
+		}}, map[string]interface{}{"name": "ana", "role": "admin"}, "role", "taken", 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := tt.s.ValidateWithContext(context.Background(), tt.data)
+			if elapsed := time.Since(start); elapsed > tt.maxDuration {
+				t.Errorf("ValidateWithContext() took %v", elapsed)
+			}
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateWithContext() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField || validationErr.Code != tt.wantCode {
+				t.Errorf("ValidateWithContext() error = %v, want a ValidationError of %q with code %q", err, tt.wantField, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParallelValidationsRunConcurrently(t *testing.T) {
+	var running, peak int32
+	validation := func(context.Context, interface{}) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	s := &BaseSerializer{ParallelValidations: true, ContextValidations: map[string][]func(context.Context, interface{}) error{
+		"a": {validation}, "b": {validation}, "c": {validation},
+	}}
+	if err := s.Validate(map[string]interface{}{"a": 1, "b": 2, "c": 3}); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if peak < 2 {
+		t.Errorf("at most %d validations ran at once, want them to run concurrently", peak)
+	}
+}
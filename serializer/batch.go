@@ -0,0 +1,96 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// SerializeMany serializes every element of a slice or array. When Workers is greater than one,
+// elements are serialized in parallel; the order of the results always matches the input.
+func (s *BaseSerializer) SerializeMany(items interface{}) ([]map[string]interface{}, error) {
+	return s.SerializeManyWithContext(context.Background(), items)
+}
+
+// SerializeManyWithContext serializes every element of a slice or array like SerializeMany,
+// passing ctx to the context-aware pipeline stages. All elements share the call's Metadata.
+// The elements are not wrapped in the Envelope.
+// Failures are reported as a *BulkError carrying the index of the element.
+func (s *BaseSerializer) SerializeManyWithContext(ctx context.Context, items interface{}) ([]map[string]interface{}, error) {
+	v := reflect.ValueOf(items)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, &SerializationError{Message: "SerializeMany expects a slice or an array"}
+	}
+
+	if MetadataFromContext(ctx) == nil {
+		ctx = ContextWithMetadata(ctx, NewMetadata())
+	}
+
+	results := make([]map[string]interface{}, v.Len())
+	if s.Workers <= 1 || v.Len() < 2 {
+		for i := range results {
+			result, err := s.serializeObject(ctx, v.Index(i).Interface())
+			if err != nil {
+				return nil, &BulkError{Index: i, Err: err}
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	indexes := make(chan int)
+	workers := s.Workers
+	if workers > len(results) {
+		workers = len(results)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := s.serializeObject(ctx, v.Index(i).Interface())
+				if err != nil {
+					once.Do(func() {
+						firstErr = &BulkError{Index: i, Err: err}
+						cancel()
+					})
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+
+feed:
+	for i := range results {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &SerializationError{Message: err.Error(), Err: err}
+	}
+	return results, nil
+}
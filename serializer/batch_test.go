@@ -0,0 +1,90 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSerializeMany(t *testing.T) {
+	items := make([]account, 20)
+	want := make([]map[string]interface{}, len(items))
+	for i := range items {
+		items[i] = account{ID: i, Name: "n"}
+		want[i] = map[string]interface{}{"id": float64(i), "name": "n"}
+	}
+	tests := []struct {
+		name    string
+		workers int
+		items   interface{}
+		want    []map[string]interface{}
+	}{
+		{"sequential", 0, items, want},
+		{"parallel keeps the order", 4, items, want},
+		{"more workers than items", 50, items[:2], want[:2]},
+		{"pointer to an array", 2, &[2]account{items[0], items[1]}, want[:2]},
+		{"nil", 2, nil, nil},
+		{"nil pointer", 2, (*[]account)(nil), nil},
+		{"empty", 2, []account{}, []map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{Fields: []string{"id", "name"}, Workers: tt.workers}
+			got, err := s.SerializeMany(tt.items)
+			if err != nil {
+				t.Fatalf("SerializeMany() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeMany() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializeManyErrors(t *testing.T) {
+	errRejected := errors.New("rejected")
+	items := make([]account, 20)
+	for i := range items {
+		items[i].ID = i
+	}
+	for _, workers := range []int{1, 4} {
+		s := &BaseSerializer{Workers: workers, Hooks: Hooks{PreSerialize: func(_ context.Context, data interface{}) error {
+			if data.(account).ID == 13 {
+				return errRejected
+			}
+			return nil
+		}}}
+		_, err := s.SerializeMany(items)
+		var bulkErr *BulkError
+		if !errors.As(err, &bulkErr) || bulkErr.Index != 13 || !errors.Is(err, errRejected) {
+			t.Errorf("SerializeMany() with %d workers error = %v, want a BulkError of item 13", workers, err)
+		}
+	}
+
+	if _, err := (&BaseSerializer{}).SerializeMany(account{}); err == nil {
+		t.Errorf("SerializeMany() of a struct error = nil, want an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (&BaseSerializer{Workers: 2}).SerializeManyWithContext(ctx, items); !errors.Is(err, context.Canceled) {
+		t.Errorf("SerializeManyWithContext() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestSerializeManySharesMetadata(t *testing.T) {
+	s := &BaseSerializer{Workers: 3, MetadataTransformations: map[string]func(interface{}, *Metadata) interface{}{
+		"name": func(value interface{}, meta *Metadata) interface{} {
+			meta.Set(value.(string), true)
+			return value
+		},
+	}}
+	meta := NewMetadata()
+	if _, err := s.SerializeManyWithContext(ContextWithMetadata(context.Background(), meta), []account{{Name: "a"}, {Name: "b"}, {Name: "c"}}); err != nil {
+		t.Fatalf("SerializeManyWithContext() error = %v", err)
+	}
+	if got := len(meta.Values()); got != 3 {
+		t.Errorf("Metadata holds %d values, want one per item", got)
+	}
+}
@@ -0,0 +1,303 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// binaryMagic starts every document written by EncodeBinary; its last byte is the version.
+const binaryMagic = "BSR\x01"
+
+// maxBinaryDepth bounds the nesting of decoded documents, so hostile input can't exhaust the stack.
+const maxBinaryDepth = 1000
+
+// Value tags of the binary format.
+const (
+	binaryNull   byte = iota
+	binaryFalse       // false
+	binaryTrue        // true
+	binaryInt         // Zigzag varint
+	binaryFloat       // IEEE 754 double, little endian
+	binaryString      // Uvarint length and UTF-8 bytes
+	binaryNumber      // Integer beyond int64, as a length-prefixed decimal string
+	binaryList        // Uvarint length and the elements
+	binaryObject      // Uvarint length and, per key, its uvarint index in the field table and the value
+)
+
+// SerializeToBinary serializes data and encodes the output with EncodeBinary.
+//
+// Experimental: the format may still change between versions.
+func (s *BaseSerializer) SerializeToBinary(data interface{}) ([]byte, error) {
+	return s.SerializeAsWithContext(context.Background(), data, FormatBinary)
+}
+
+// DeserializeFromBinary decodes output written by SerializeToBinary and deserializes it into out.
+//
+// Experimental: the format may still change between versions.
+func (s *BaseSerializer) DeserializeFromBinary(data []byte, out interface{}) error {
+	input, err := s.DecodeBinary(data)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(input, out)
+}
+
+// DecodeBinary decodes a document written by EncodeBinary into a map. Numbers are decoded as
+// float64, or as json.Number with UseNumber.
+func (s *BaseSerializer) DecodeBinary(data []byte) (map[string]interface{}, error) {
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return nil, &SerializationError{Message: "failed to decode binary: not a binary document or unsupported version"}
+	}
+	d := &binaryDecoder{data: data, pos: len(binaryMagic), useNumber: s.UseNumber}
+
+	count, err := d.length()
+	if err != nil {
+		return nil, d.fail(err)
+	}
+	d.fields = make([]string, count)
+	for i := range d.fields {
+		if d.fields[i], err = d.string(); err != nil {
+			return nil, d.fail(err)
+		}
+	}
+
+	value, err := d.value(0)
+	if err != nil {
+		return nil, d.fail(err)
+	}
+	if d.pos != len(d.data) {
+		return nil, d.fail(fmt.Errorf("%d trailing bytes", len(d.data)-d.pos))
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok && value != nil {
+		return nil, d.fail(fmt.Errorf("top-level value is not an object"))
+	}
+	return object, nil
+}
+
+// EncodeBinary encodes serialized output (maps, lists and scalars) in a compact self-describing
+// binary format, for queue messages where JSON is too large and maintaining .proto schemas too
+// heavy. A document holds a table of the object keys it uses, each written once, and the values,
+// which refer to keys by their index in the table. Integers are written as varints and keys in
+// sorted order, so equal values always produce the same bytes.
+//
+// Experimental: the format may still change between versions. Documents start with a version
+// byte, so decoders reject the ones they don't understand.
+func EncodeBinary(value interface{}) ([]byte, error) {
+	// Reduce values set by transformations and hooks to maps, lists and scalars
+	normalized, err := toJSONValueWith(reflect.ValueOf(value), sortedOptions)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode binary: %v", err), Err: err}
+	}
+	e := &binaryEncoder{index: make(map[string]uint64)}
+	if err := e.value(normalized); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode binary: %v", err), Err: err}
+	}
+
+	encoded := append([]byte(binaryMagic), binary.AppendUvarint(nil, uint64(len(e.fields)))...)
+	for _, field := range e.fields {
+		encoded = appendBinaryString(encoded, field)
+	}
+	return append(encoded, e.body...), nil
+}
+
+// binaryEncoder writes values while collecting the field table.
+type binaryEncoder struct {
+	fields []string
+	index  map[string]uint64
+	body   []byte
+}
+
+func (e *binaryEncoder) value(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.body = append(e.body, binaryNull)
+	case bool:
+		if v {
+			e.body = append(e.body, binaryTrue)
+		} else {
+			e.body = append(e.body, binaryFalse)
+		}
+	case string:
+		e.body = appendBinaryString(append(e.body, binaryString), v)
+	case json.Number:
+		e.number(v)
+	case float64:
+		e.float(v)
+	case []interface{}:
+		e.body = binary.AppendUvarint(append(e.body, binaryList), uint64(len(v)))
+		for _, item := range v {
+			if err := e.value(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		e.body = binary.AppendUvarint(append(e.body, binaryObject), uint64(len(v)))
+		for _, key := range objectKeys(v) {
+			index, exists := e.index[key]
+			if !exists {
+				index = uint64(len(e.fields))
+				e.index[key] = index
+				e.fields = append(e.fields, key)
+			}
+			e.body = binary.AppendUvarint(e.body, index)
+			if err := e.value(v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value of type %T", value)
+	}
+	return nil
+}
+
+// number writes integers as varints, or as strings beyond int64, and other numbers as doubles.
+func (e *binaryEncoder) number(n json.Number) {
+	if i, err := n.Int64(); err == nil {
+		e.body = binary.AppendVarint(append(e.body, binaryInt), i)
+		return
+	}
+	if f, err := n.Float64(); err == nil && strings.ContainsAny(n.String(), ".eE") {
+		e.float(f)
+		return
+	}
+	e.body = appendBinaryString(append(e.body, binaryNumber), n.String())
+}
+
+func (e *binaryEncoder) float(f float64) {
+	e.body = binary.LittleEndian.AppendUint64(append(e.body, binaryFloat), math.Float64bits(f))
+}
+
+// appendBinaryString appends the length and the bytes of s.
+func appendBinaryString(buf []byte, s string) []byte {
+	return append(binary.AppendUvarint(buf, uint64(len(s))), s...)
+}
+
+// binaryDecoder reads a document written by EncodeBinary.
+type binaryDecoder struct {
+	data      []byte
+	pos       int
+	fields    []string
+	useNumber bool
+}
+
+func (d *binaryDecoder) value(depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, fmt.Errorf("nesting deeper than %d", maxBinaryDepth)
+	}
+	if d.pos >= len(d.data) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	tag := d.data[d.pos]
+	d.pos++
+
+	switch tag {
+	case binaryNull:
+		return nil, nil
+	case binaryFalse:
+		return false, nil
+	case binaryTrue:
+		return true, nil
+	case binaryInt:
+		i, n := binary.Varint(d.data[d.pos:])
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed integer")
+		}
+		d.pos += n
+		if d.useNumber {
+			return json.Number(strconv.FormatInt(i, 10)), nil
+		}
+		return float64(i), nil
+	case binaryFloat:
+		if len(d.data)-d.pos < 8 {
+			return nil, fmt.Errorf("unexpected end of data")
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+		d.pos += 8
+		if d.useNumber {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+		}
+		return f, nil
+	case binaryString:
+		return d.string()
+	case binaryNumber:
+		s, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		if d.useNumber {
+			return json.Number(s), nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", s)
+		}
+		return f, nil
+	case binaryList:
+		count, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, count)
+		for i := range list {
+			if list[i], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case binaryObject:
+		count, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		object := make(map[string]interface{}, count)
+		for i := 0; i < count; i++ {
+			index, n := binary.Uvarint(d.data[d.pos:])
+			if n <= 0 || index >= uint64(len(d.fields)) {
+				return nil, fmt.Errorf("invalid field index")
+			}
+			d.pos += n
+			if object[d.fields[index]], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	default:
+		return nil, fmt.Errorf("unknown value tag %d", tag)
+	}
+}
+
+// length reads a uvarint count of items or bytes, which can't exceed the bytes left.
+func (d *binaryDecoder) length() (int, error) {
+	length, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("malformed length")
+	}
+	d.pos += n
+	if length > uint64(len(d.data)-d.pos) {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	return int(length), nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	length, err := d.length()
+	if err != nil {
+		return "", err
+	}
+	s := string(d.data[d.pos : d.pos+length])
+	d.pos += length
+	return s, nil
+}
+
+// fail wraps a decoding error with the offset it occurred at.
+func (d *binaryDecoder) fail(err error) error {
+	return &SerializationError{Message: fmt.Sprintf("failed to decode binary at offset %d: %v", d.pos, err)}
+}
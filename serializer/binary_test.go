@@ -0,0 +1,104 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     map[string]interface{}
+		useNumber bool
+		want      map[string]interface{}
+	}{
+		{"scalars", map[string]interface{}{"b": true, "f": false, "n": nil, "s": "héllo"}, false, map[string]interface{}{"b": true, "f": false, "n": nil, "s": "héllo"}},
+		{"numbers as floats", map[string]interface{}{"i": -3, "f": 1.5, "big": json.Number("123456789012345678901234")}, false, map[string]interface{}{"i": -3.0, "f": 1.5, "big": 1.2345678901234568e23}},
+		{"numbers with UseNumber", map[string]interface{}{"i": int64(math.MaxInt64), "f": 1.5, "big": json.Number("123456789012345678901234")}, true, map[string]interface{}{"i": json.Number("9223372036854775807"), "f": json.Number("1.5"), "big": json.Number("123456789012345678901234")}},
+		{"nested", map[string]interface{}{"list": []interface{}{1, "x", map[string]interface{}{"list": []interface{}{}}}, "obj": map[string]interface{}{}}, false, map[string]interface{}{"list": []interface{}{1.0, "x", map[string]interface{}{"list": []interface{}{}}}, "obj": map[string]interface{}{}}},
+		{"empty", map[string]interface{}{}, false, map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := EncodeBinary(tt.value)
+			if err != nil {
+				t.Fatalf("EncodeBinary() error = %v", err)
+			}
+			got, err := (&BaseSerializer{UseNumber: tt.useNumber}).DecodeBinary(encoded)
+			if err != nil {
+				t.Fatalf("DecodeBinary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeBinary() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeBinaryFieldTable(t *testing.T) {
+	value := map[string]interface{}{"b": map[string]interface{}{"a": 1}, "a": 2}
+	encoded, err := EncodeBinary(value)
+	if err != nil {
+		t.Fatalf("EncodeBinary() error = %v", err)
+	}
+	// Keys are sorted and each is written once: the table holds "a" and "b", and the nested
+	// object refers to "a" by its index
+	want := []byte(binaryMagic + "\x02\x01a\x01b" +
+		"\x08\x02" + // Object of two fields
+		"\x00\x03\x04" + // a: 2
+		"\x01\x08\x01\x00\x03\x02") // b: {a: 1}
+	if !bytes.Equal(encoded, want) {
+		t.Errorf("EncodeBinary() = %q, want %q", encoded, want)
+	}
+	again, _ := EncodeBinary(map[string]interface{}{"a": 2, "b": map[string]interface{}{"a": 1}})
+	if !bytes.Equal(again, encoded) {
+		t.Errorf("EncodeBinary() is not deterministic: %q != %q", again, encoded)
+	}
+}
+
+func TestDecodeBinaryInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"no magic", "{}", "not a binary document"},
+		{"unsupported version", "BSR\x02\x00\x08\x00", "unsupported version"},
+		{"truncated table", binaryMagic + "\x01\x05ab", "unexpected end of data"},
+		{"missing value", binaryMagic + "\x00", "unexpected end of data"},
+		{"unknown tag", binaryMagic + "\x00\x7f", "unknown value tag 127"},
+		{"field index out of range", binaryMagic + "\x00\x08\x01\x03\x00", "invalid field index"},
+		{"truncated float", binaryMagic + "\x00\x04\x00\x00", "unexpected end of data"},
+		{"huge list", binaryMagic + "\x00\x07\xff\xff\xff\xff\x0f", "unexpected end of data"},
+		{"not an object", binaryMagic + "\x00\x05\x01a", "top-level value is not an object"},
+		{"trailing bytes", binaryMagic + "\x00\x08\x00\x00", "1 trailing bytes"},
+		{"too deep", binaryMagic + "\x00" + strings.Repeat("\x07\x01", maxBinaryDepth+1) + "\x00", "nesting deeper"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&BaseSerializer{}).DecodeBinary([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("DecodeBinary() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializeToBinary(t *testing.T) {
+	s := &BaseSerializer{}
+	in := account{ID: 7, Name: "ana", Role: "admin"}
+	encoded, err := s.SerializeToBinary(in)
+	if err != nil {
+		t.Fatalf("SerializeToBinary() error = %v", err)
+	}
+	var out account
+	if err := s.DeserializeFromBinary(encoded, &out); err != nil {
+		t.Fatalf("DeserializeFromBinary() error = %v", err)
+	}
+	if out != in {
+		t.Errorf("DeserializeFromBinary() = %+v, want %+v", out, in)
+	}
+}
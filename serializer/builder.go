@@ -0,0 +1,735 @@
+package serializer
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/url"
+)
+
+// Builder assembles a serializer configuration step by step. Call Build to obtain an
+// ImmutableSerializer that can be shared safely across goroutines.
+type Builder struct {
+	config BaseSerializer
+}
+
+// NewSerializer starts building a serializer.
+func NewSerializer() *Builder {
+	return &Builder{}
+}
+
+// Fields sets the fields included in the output.
+func (b *Builder) Fields(fields ...string) *Builder {
+	b.config.Fields = append([]string(nil), fields...)
+	return b
+}
+
+// Exclude adds fields that are removed from the output.
+func (b *Builder) Exclude(fields ...string) *Builder {
+	b.config.ExcludedFields = append(b.config.ExcludedFields, fields...)
+	return b
+}
+
+// Validate adds validations for a field.
+func (b *Builder) Validate(field string, validations ...func(interface{}) error) *Builder {
+	if b.config.Validations == nil {
+		b.config.Validations = make(map[string][]func(interface{}) error)
+	}
+	b.config.Validations[field] = append(b.config.Validations[field], validations...)
+	return b
+}
+
+// EachElement adds validations checked against every item of a list field, e.g.
+// EachElement("tags", NotEmpty) (see Each).
+func (b *Builder) EachElement(field string, validations ...func(interface{}) error) *Builder {
+	return b.Validate(field, Each(validations...))
+}
+
+// EachValue adds validations checked against every value of an object field, e.g.
+// EachValue("metadata", NotEmpty), like the EachValue validation.
+func (b *Builder) EachValue(field string, validations ...func(interface{}) error) *Builder {
+	return b.Validate(field, EachValue(validations...))
+}
+
+// Rules sets the validation rules of a field as a compact string, e.g. "required|min:3|email"
+// (see ParseRules). Invalid rules are reported when validating.
+func (b *Builder) Rules(field, rules string) *Builder {
+	if b.config.Rules == nil {
+		b.config.Rules = make(map[string]string)
+	}
+	b.config.Rules[field] = rules
+	return b
+}
+
+// Sanitize adds sanitizers for the input strings of a field, e.g. Sanitize("bio", StripHTML,
+// CollapseWhitespace).
+func (b *Builder) Sanitize(field string, sanitizers ...func(string) string) *Builder {
+	if b.config.Sanitizers == nil {
+		b.config.Sanitizers = make(map[string][]func(string) string)
+	}
+	b.config.Sanitizers[field] = append(b.config.Sanitizers[field], sanitizers...)
+	return b
+}
+
+// ValidateTags adds the validations of the validate struct tags of v's type (see
+// TagValidations). It panics if a tag is invalid, as struct tags are fixed at compile time.
+func (b *Builder) ValidateTags(v interface{}) *Builder {
+	for field, validations := range MustTagValidations(v) {
+		b.Validate(field, validations...)
+	}
+	return b
+}
+
+// ValidateContext adds validations for a field that receive the request context, e.g. to check
+// that a username is unique in the database (see ValidationTimeout and ParallelValidations).
+func (b *Builder) ValidateContext(field string, validations ...func(context.Context, interface{}) error) *Builder {
+	if b.config.ContextValidations == nil {
+		b.config.ContextValidations = make(map[string][]func(context.Context, interface{}) error)
+	}
+	b.config.ContextValidations[field] = append(b.config.ContextValidations[field], validations...)
+	return b
+}
+
+// Nest sets the serializer validating the object, or the objects of the list, within a field
+// (see ValidateAll).
+func (b *Builder) Nest(field string, nested *ImmutableSerializer) *Builder {
+	if b.config.Nested == nil {
+		b.config.Nested = make(map[string]*BaseSerializer)
+	}
+	b.config.Nested[field] = nested.config
+	return b
+}
+
+// Constrain adds constraints checking several fields together, e.g.
+// Constrain(ExactlyOneOf("email", "phone")).
+func (b *Builder) Constrain(constraints ...Constraint) *Builder {
+	b.config.Constraints = append(b.config.Constraints, constraints...)
+	return b
+}
+
+// DecodeHook adds conversions applied by Deserialize to the input values, e.g.
+// DecodeHook(StringToTimeHook("2006-01-02")).
+func (b *Builder) DecodeHook(hooks ...DecodeHook) *Builder {
+	b.config.DecodeHooks = append(b.config.DecodeHooks, hooks...)
+	return b
+}
+
+// Transform sets the transformation for a field.
+func (b *Builder) Transform(field string, transform func(interface{}) interface{}) *Builder {
+	if b.config.Transformations == nil {
+		b.config.Transformations = make(map[string]func(interface{}) interface{})
+	}
+	b.config.Transformations[field] = transform
+	return b
+}
+
+// TransformEach sets a transformation applied to every item of a list field (see EachItem).
+func (b *Builder) TransformEach(field string, transform func(interface{}) interface{}) *Builder {
+	return b.Transform(field, EachItem(transform))
+}
+
+// Condition sets the condition for including a field.
+func (b *Builder) Condition(field string, condition func(map[string]interface{}) bool) *Builder {
+	if b.config.ConditionalFields == nil {
+		b.config.ConditionalFields = make(map[string]func(map[string]interface{}) bool)
+	}
+	b.config.ConditionalFields[field] = condition
+	return b
+}
+
+// SourceCondition sets a condition for including a field that also receives the value being
+// serialized, e.g. SourceCondition("email", func(src interface{}, _ map[string]interface{}) bool {
+// return src.(*User).canShowEmail() }).
+func (b *Builder) SourceCondition(field string, condition func(source interface{}, result map[string]interface{}) bool) *Builder {
+	if b.config.SourceConditionalFields == nil {
+		b.config.SourceConditionalFields = make(map[string]func(source interface{}, result map[string]interface{}) bool)
+	}
+	b.config.SourceConditionalFields[field] = condition
+	return b
+}
+
+// Compute adds a field computed from the serialized data.
+func (b *Builder) Compute(field string, compute func(data interface{}) (interface{}, error)) *Builder {
+	if b.config.ComputedFields == nil {
+		b.config.ComputedFields = make(map[string]func(data interface{}) (interface{}, error))
+	}
+	b.config.ComputedFields[field] = compute
+	return b
+}
+
+// ReadOnly marks fields as read-only.
+func (b *Builder) ReadOnly(fields ...string) *Builder {
+	b.config.ReadOnlyFields = append(b.config.ReadOnlyFields, fields...)
+	return b
+}
+
+// WriteOnly marks fields as write-only.
+func (b *Builder) WriteOnly(fields ...string) *Builder {
+	b.config.WriteOnlyFields = append(b.config.WriteOnlyFields, fields...)
+	return b
+}
+
+// Default sets the default value (or func() interface{} factory) for a field.
+func (b *Builder) Default(field string, value interface{}) *Builder {
+	if b.config.Defaults == nil {
+		b.config.Defaults = make(map[string]interface{})
+	}
+	b.config.Defaults[field] = value
+	return b
+}
+
+// Permit restricts a field to the given roles.
+func (b *Builder) Permit(field string, roles ...string) *Builder {
+	if b.config.FieldPermissions == nil {
+		b.config.FieldPermissions = make(map[string][]string)
+	}
+	b.config.FieldPermissions[field] = append(b.config.FieldPermissions[field], roles...)
+	return b
+}
+
+// Deprecate marks a field as deprecated.
+func (b *Builder) Deprecate(field, message string) *Builder {
+	if b.config.DeprecatedFields == nil {
+		b.config.DeprecatedFields = make(map[string]string)
+	}
+	b.config.DeprecatedFields[field] = message
+	return b
+}
+
+// Rename records that the field now written as to was called from: the field is also written
+// under from, and from is accepted on Deserialize with a deprecation warning (see RenamedFields).
+func (b *Builder) Rename(from, to string) *Builder {
+	if b.config.RenamedFields == nil {
+		b.config.RenamedFields = make(map[string]string)
+	}
+	b.config.RenamedFields[from] = to
+	return b
+}
+
+// Link adds a HAL link template, e.g. Link("self", "/users/{id}").
+func (b *Builder) Link(rel, template string) *Builder {
+	if b.config.Links == nil {
+		b.config.Links = make(map[string]string)
+	}
+	b.config.Links[rel] = template
+	return b
+}
+
+// Version sets the overrides of the named API version (see BaseSerializer.Version).
+func (b *Builder) Version(name string, opts ...Option) *Builder {
+	if b.config.Versions == nil {
+		b.config.Versions = make(map[string][]Option)
+	}
+	b.config.Versions[name] = append([]Option(nil), opts...)
+	return b
+}
+
+// Configure applies arbitrary changes to the configuration being built.
+func (b *Builder) Configure(configure func(*BaseSerializer)) *Builder {
+	configure(&b.config)
+	return b
+}
+
+// Build returns an ImmutableSerializer with a private copy of the configuration.
+// Later changes to the builder don't affect serializers that were already built.
+func (b *Builder) Build() *ImmutableSerializer {
+	return &ImmutableSerializer{config: b.config.clone()}
+}
+
+// ImmutableSerializer is a serializer whose configuration can't change after it is built,
+// so a single instance can be shared by concurrent requests.
+type ImmutableSerializer struct {
+	config *BaseSerializer
+}
+
+// Option overrides part of a serializer configuration for a derived view or a single Serialize call.
+// Options must not modify maps or slices in place, since they are shared with the parent.
+type Option func(*BaseSerializer)
+
+// view returns a shallow copy of the configuration with opts applied.
+func (s *BaseSerializer) view(opts []Option) *BaseSerializer {
+	config := *s
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &config
+}
+
+// WithFields overrides the fields included in the output.
+func WithFields(fields ...string) Option {
+	return func(s *BaseSerializer) {
+		s.Fields = append([]string(nil), fields...)
+	}
+}
+
+// WithExclude removes fields from the output.
+func WithExclude(fields ...string) Option {
+	return func(s *BaseSerializer) {
+		s.ExcludedFields = append(append([]string(nil), s.ExcludedFields...), fields...)
+	}
+}
+
+// WithTransformation overrides the transformation for a field.
+func WithTransformation(field string, transform func(interface{}) interface{}) Option {
+	return func(s *BaseSerializer) {
+		s.Transformations = writableMap(s.Transformations, 1)
+		s.Transformations[field] = transform
+	}
+}
+
+// WithCondition overrides the condition for including a field.
+func WithCondition(field string, condition func(map[string]interface{}) bool) Option {
+	return func(s *BaseSerializer) {
+		s.ConditionalFields = writableMap(s.ConditionalFields, 1)
+		s.ConditionalFields[field] = condition
+	}
+}
+
+// View returns a derived serializer with the given overrides applied. The view shares every
+// configuration it doesn't override with its parent, so it is cheap to create per request.
+func (s *ImmutableSerializer) View(opts ...Option) *ImmutableSerializer {
+	return &ImmutableSerializer{config: s.config.view(opts)}
+}
+
+// Version returns a derived serializer for the named API version (see BaseSerializer.Version).
+func (s *ImmutableSerializer) Version(name string) *ImmutableSerializer {
+	return &ImmutableSerializer{config: s.config.Version(name)}
+}
+
+// HasVersion reports whether the serializer defines the named version.
+func (s *ImmutableSerializer) HasVersion(name string) bool {
+	return s.config.HasVersion(name)
+}
+
+// Serialize serializes a struct into a map.
+func (s *ImmutableSerializer) Serialize(data interface{}) (map[string]interface{}, error) {
+	return s.config.Serialize(data)
+}
+
+// SerializeWith serializes a struct into a map, applying opts to this call only.
+func (s *ImmutableSerializer) SerializeWith(data interface{}, opts ...Option) (map[string]interface{}, error) {
+	return s.config.SerializeWith(data, opts...)
+}
+
+// SerializeWithContext serializes a struct into a map, passing ctx to the context-aware pipeline stages.
+func (s *ImmutableSerializer) SerializeWithContext(ctx context.Context, data interface{}, opts ...Option) (map[string]interface{}, error) {
+	return s.config.SerializeWithContext(ctx, data, opts...)
+}
+
+// Deserialize deserializes a map into a struct.
+func (s *ImmutableSerializer) Deserialize(input map[string]interface{}, out interface{}) error {
+	return s.config.Deserialize(input, out)
+}
+
+// DeserializeWithContext deserializes a map into a struct, passing ctx to the hooks.
+func (s *ImmutableSerializer) DeserializeWithContext(ctx context.Context, input map[string]interface{}, out interface{}) error {
+	return s.config.DeserializeWithContext(ctx, input, out)
+}
+
+// SerializeOrdered serializes a struct into an OrderedMap, applying opts to this call only.
+func (s *ImmutableSerializer) SerializeOrdered(data interface{}, opts ...Option) (*OrderedMap, error) {
+	return s.config.SerializeOrdered(data, opts...)
+}
+
+// SerializeWithSelection serializes data keeping only the fields of a sparse fieldset expression.
+func (s *ImmutableSerializer) SerializeWithSelection(data interface{}, selection string) (map[string]interface{}, error) {
+	return s.config.SerializeWithSelection(data, selection)
+}
+
+// SerializeAs serializes data and encodes it in the given format.
+func (s *ImmutableSerializer) SerializeAs(data interface{}, format Format, opts ...Option) ([]byte, error) {
+	return s.config.SerializeAs(data, format, opts...)
+}
+
+// SerializeAsWithContext serializes data and encodes it in the given format, passing ctx to the context-aware pipeline stages.
+func (s *ImmutableSerializer) SerializeAsWithContext(ctx context.Context, data interface{}, format Format, opts ...Option) ([]byte, error) {
+	return s.config.SerializeAsWithContext(ctx, data, format, opts...)
+}
+
+// DeserializeAny deserializes a self-describing payload without a target struct.
+func (s *ImmutableSerializer) DeserializeAny(input map[string]interface{}) (interface{}, error) {
+	return s.config.DeserializeAny(input)
+}
+
+// EncodeJSON encodes a serialized value as JSON with the configured layout.
+func (s *ImmutableSerializer) EncodeJSON(value interface{}) ([]byte, error) {
+	return s.config.EncodeJSON(value)
+}
+
+// EncodeAs encodes a serialized value in the given format with the configured settings.
+func (s *ImmutableSerializer) EncodeAs(value interface{}, format Format) ([]byte, error) {
+	return s.config.EncodeAs(value, format)
+}
+
+// DecodeJSON parses a JSON object into a map, applying the configured duplicate key policy.
+func (s *ImmutableSerializer) DecodeJSON(data []byte) (map[string]interface{}, error) {
+	return s.config.DecodeJSON(data)
+}
+
+// DecodeYAML parses a YAML mapping into a map, applying the configured duplicate key policy.
+func (s *ImmutableSerializer) DecodeYAML(data []byte) (map[string]interface{}, error) {
+	return s.config.DecodeYAML(data)
+}
+
+// DecodeXML parses an XML document into a map, reversing the XML written by EncodeSorted.
+func (s *ImmutableSerializer) DecodeXML(data []byte) (map[string]interface{}, error) {
+	return s.config.DecodeXML(data)
+}
+
+// DeprecationWarnings returns the Warning header values for the deprecated fields present in result.
+func (s *ImmutableSerializer) DeprecationWarnings(result map[string]interface{}) []string {
+	return s.config.DeprecationWarnings(result)
+}
+
+// Validate checks the provided data against the configured validations.
+func (s *ImmutableSerializer) Validate(data map[string]interface{}) error {
+	return s.config.Validate(data)
+}
+
+// ValidateWithContext checks the provided data against the configured validations, including the context-aware ones.
+func (s *ImmutableSerializer) ValidateWithContext(ctx context.Context, data map[string]interface{}) error {
+	return s.config.ValidateWithContext(ctx, data)
+}
+
+// ValidateAll checks the provided data against the configured validations, reporting every failure.
+func (s *ImmutableSerializer) ValidateAll(data map[string]interface{}) error {
+	return s.config.ValidateAll(data)
+}
+
+// ValidateAllWithContext checks the provided data like ValidateAll, including the context-aware validations.
+func (s *ImmutableSerializer) ValidateAllWithContext(ctx context.Context, data map[string]interface{}) error {
+	return s.config.ValidateAllWithContext(ctx, data)
+}
+
+// ValidateWithWarnings checks the provided data against the configured validations, also returning their warnings.
+func (s *ImmutableSerializer) ValidateWithWarnings(data map[string]interface{}) ([]*ValidationError, error) {
+	return s.config.ValidateWithWarnings(data)
+}
+
+// ValidateStruct checks a struct against the configured validations without serializing it first.
+func (s *ImmutableSerializer) ValidateStruct(data interface{}) error {
+	return s.config.ValidateStruct(data)
+}
+
+// ValidateStructWithContext checks a struct against the configured validations, including the context-aware ones.
+func (s *ImmutableSerializer) ValidateStructWithContext(ctx context.Context, data interface{}) error {
+	return s.config.ValidateStructWithContext(ctx, data)
+}
+
+// Diff serializes old and new and reports the fields that differ.
+func (s *ImmutableSerializer) Diff(old, new interface{}) (map[string]Change, error) {
+	return s.config.Diff(old, new)
+}
+
+// Merge layers overlay on top of base, ignoring the fields the serializer doesn't accept.
+func (s *ImmutableSerializer) Merge(base, overlay map[string]interface{}, strategy MergeStrategy) map[string]interface{} {
+	return s.config.Merge(base, overlay, strategy)
+}
+
+// SerializeJSONAPI renders data as a JSON:API document.
+func (s *ImmutableSerializer) SerializeJSONAPI(data interface{}) (map[string]interface{}, error) {
+	return s.config.SerializeJSONAPI(data)
+}
+
+// SerializeToGob serializes data and encodes the output with encoding/gob.
+func (s *ImmutableSerializer) SerializeToGob(data interface{}) ([]byte, error) {
+	return s.config.SerializeToGob(data)
+}
+
+// DeserializeFromGob decodes output written by SerializeToGob and deserializes it into out.
+func (s *ImmutableSerializer) DeserializeFromGob(data []byte, out interface{}) error {
+	return s.config.DeserializeFromGob(data, out)
+}
+
+// SerializeToBinary serializes data and encodes the output in the compact binary format.
+func (s *ImmutableSerializer) SerializeToBinary(data interface{}) ([]byte, error) {
+	return s.config.SerializeToBinary(data)
+}
+
+// DeserializeFromBinary decodes output written by SerializeToBinary and deserializes it into out.
+func (s *ImmutableSerializer) DeserializeFromBinary(data []byte, out interface{}) error {
+	return s.config.DeserializeFromBinary(data, out)
+}
+
+// DeserializeFromURLValues validates form data or a query string and deserializes it into out.
+func (s *ImmutableSerializer) DeserializeFromURLValues(values url.Values, out interface{}) error {
+	return s.config.DeserializeFromURLValues(values, out)
+}
+
+// DeserializeFromURLValuesWithContext is like DeserializeFromURLValues, passing ctx to the context-aware stages.
+func (s *ImmutableSerializer) DeserializeFromURLValuesWithContext(ctx context.Context, values url.Values, out interface{}) error {
+	return s.config.DeserializeFromURLValuesWithContext(ctx, values, out)
+}
+
+// DeserializeFromMultipart binds a parsed multipart form, including its uploaded files, to out.
+func (s *ImmutableSerializer) DeserializeFromMultipart(form *multipart.Form, out interface{}) error {
+	return s.config.DeserializeFromMultipart(form, out)
+}
+
+// DeserializeFromMultipartWithContext is like DeserializeFromMultipart, passing ctx to the context-aware stages.
+func (s *ImmutableSerializer) DeserializeFromMultipartWithContext(ctx context.Context, form *multipart.Form, out interface{}) error {
+	return s.config.DeserializeFromMultipartWithContext(ctx, form, out)
+}
+
+// DeserializeAs decodes data in the given format and deserializes it into out.
+func (s *ImmutableSerializer) DeserializeAs(data []byte, format Format, out interface{}) error {
+	return s.config.DeserializeAs(data, format, out)
+}
+
+// SerializeCompressed serializes data, encodes it in the given format and compresses it.
+func (s *ImmutableSerializer) SerializeCompressed(data interface{}, format Format, compression Compression) ([]byte, error) {
+	return s.config.SerializeCompressed(data, format, compression)
+}
+
+// DeserializeCompressed decompresses data written by SerializeCompressed and deserializes it into out.
+func (s *ImmutableSerializer) DeserializeCompressed(data []byte, format Format, compression Compression, out interface{}) error {
+	return s.config.DeserializeCompressed(data, format, compression, out)
+}
+
+// SerializeTo serializes data and writes it to w in the given format.
+func (s *ImmutableSerializer) SerializeTo(w io.Writer, data interface{}, format Format, opts ...Option) error {
+	return s.config.SerializeTo(w, data, format, opts...)
+}
+
+// DeserializeFrom reads a document in the given format from r and deserializes it into out.
+func (s *ImmutableSerializer) DeserializeFrom(r io.Reader, out interface{}, format Format) error {
+	return s.config.DeserializeFrom(r, out, format)
+}
+
+// SerializeToXML serializes a struct into an XML string.
+func (s *ImmutableSerializer) SerializeToXML(data interface{}) (string, error) {
+	return s.config.SerializeToXML(data)
+}
+
+// SerializeToYAML serializes a struct into a YAML string.
+func (s *ImmutableSerializer) SerializeToYAML(data interface{}) (string, error) {
+	return s.config.SerializeToYAML(data)
+}
+
+// SerializeToYAMLStream serializes the elements of a slice into a multi-document YAML stream.
+func (s *ImmutableSerializer) SerializeToYAMLStream(items interface{}) (string, error) {
+	return s.config.SerializeToYAMLStream(items)
+}
+
+// DecodeYAMLStream parses a multi-document YAML stream into one map per document.
+func (s *ImmutableSerializer) DecodeYAMLStream(data []byte) ([]map[string]interface{}, error) {
+	return s.config.DecodeYAMLStream(data)
+}
+
+// DeserializeYAMLStream deserializes the documents of a multi-document YAML stream into a slice.
+func (s *ImmutableSerializer) DeserializeYAMLStream(data []byte, out interface{}) error {
+	return s.config.DeserializeYAMLStream(data, out)
+}
+
+// Clone returns a deep copy of the serializer: changes to the maps and slices of either one, to
+// its Envelope and JSONAPI settings, or to the serializers in Nested (which are cloned in turn)
+// don't affect the other. The Registry, KeyProvider, XMLSchema, PolymorphicTypes and
+// Fetch.Client, which are meant to be shared, and the functions and values held by the
+// configuration, such as validations, Schema fields and Defaults, are shared with the copy.
+func (s *BaseSerializer) Clone() *BaseSerializer {
+	return s.clone()
+}
+
+// With returns a copy of the serializer with opts applied, leaving s unchanged.
+func (s *BaseSerializer) With(opts ...Option) *BaseSerializer {
+	c := s.clone()
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithFields returns a copy of the serializer that includes only the given fields.
+func (s *BaseSerializer) WithFields(fields ...string) *BaseSerializer {
+	return s.With(WithFields(fields...))
+}
+
+// WithExclude returns a copy of the serializer that also removes the given fields.
+func (s *BaseSerializer) WithExclude(fields ...string) *BaseSerializer {
+	return s.With(WithExclude(fields...))
+}
+
+// WithValidation returns a copy of the serializer with validations added to a field.
+func (s *BaseSerializer) WithValidation(field string, validations ...func(interface{}) error) *BaseSerializer {
+	c := s.clone()
+	if c.Validations == nil {
+		c.Validations = make(map[string][]func(interface{}) error)
+	}
+	c.Validations[field] = append(c.Validations[field], validations...)
+	return c
+}
+
+// WithTransformation returns a copy of the serializer with the transformation of a field replaced.
+func (s *BaseSerializer) WithTransformation(field string, transform func(interface{}) interface{}) *BaseSerializer {
+	return s.With(WithTransformation(field, transform))
+}
+
+// WithCondition returns a copy of the serializer with the condition for including a field replaced.
+func (s *BaseSerializer) WithCondition(field string, condition func(map[string]interface{}) bool) *BaseSerializer {
+	return s.With(WithCondition(field, condition))
+}
+
+// clone returns a copy of the configuration that shares no maps or slices with the original
+// (see Clone).
+func (s *BaseSerializer) clone() *BaseSerializer {
+	return s.cloneWith(make(map[*BaseSerializer]*BaseSerializer))
+}
+
+// cloneWith clones the configuration, reusing the copies of the serializers already cloned so
+// that Nested serializers referring to each other are copied once.
+func (s *BaseSerializer) cloneWith(clones map[*BaseSerializer]*BaseSerializer) *BaseSerializer {
+	if c, ok := clones[s]; ok {
+		return c
+	}
+	c := new(BaseSerializer)
+	clones[s] = c
+	*c = *s
+	c.Fields = copySlice(s.Fields)
+	c.ExcludedFields = copySlice(s.ExcludedFields)
+	c.Order = copySlice(s.Order)
+	c.XMLAttributes = copySlice(s.XMLAttributes)
+	c.XMLNamespaces = copyMap(s.XMLNamespaces)
+	c.Validations = copySliceMap(s.Validations)
+	c.Rules = copyMap(s.Rules)
+	c.Sanitizers = copySliceMap(s.Sanitizers)
+	c.Transformations = copyMap(s.Transformations)
+	c.ConditionalFields = copyMap(s.ConditionalFields)
+	c.ComputedFields = copyMap(s.ComputedFields)
+	c.Enums = copyEnums(s.Enums)
+	c.Schema = copyMap(s.Schema)
+	c.CoerceFields = copySlice(s.CoerceFields)
+	c.DecodeHooks = copySlice(s.DecodeHooks)
+	c.Links = copyMap(s.Links)
+	c.EncryptedFields = copySlice(s.EncryptedFields)
+	c.TimeFormat.Accept = copySlice(s.TimeFormat.Accept)
+	c.TimeFormats = copyTimeFormats(s.TimeFormats)
+	c.ReadOnlyFields = copySlice(s.ReadOnlyFields)
+	c.WriteOnlyFields = copySlice(s.WriteOnlyFields)
+	c.FieldPermissions = copySliceMap(s.FieldPermissions)
+	c.Defaults = copyMap(s.Defaults)
+	c.Fetch.Header = s.Fetch.Header.Clone()
+	c.Acronyms = copySlice(s.Acronyms)
+	c.FieldMeta = copyMap(s.FieldMeta)
+	c.FieldGroups = copySlice(s.FieldGroups)
+	c.Profiles = copyProfiles(s.Profiles)
+	c.PolymorphicTypes = copySlice(s.PolymorphicTypes)
+	c.Aliases = copyMap(s.Aliases)
+	c.Versions = copySliceMap(s.Versions)
+	c.DeprecatedFields = copyMap(s.DeprecatedFields)
+	c.RenamedFields = copyMap(s.RenamedFields)
+	c.MetadataTransformations = copyMap(s.MetadataTransformations)
+	c.MetadataConditionalFields = copyMap(s.MetadataConditionalFields)
+	c.ContextValidations = copySliceMap(s.ContextValidations)
+	if s.Nested != nil {
+		c.Nested = make(map[string]*BaseSerializer, len(s.Nested))
+		for field, nested := range s.Nested {
+			if nested != nil {
+				nested = nested.cloneWith(clones)
+			}
+			c.Nested[field] = nested
+		}
+	}
+	c.Constraints = copySlice(s.Constraints)
+	c.ContextTransformations = copyMap(s.ContextTransformations)
+	c.ContextConditionalFields = copyMap(s.ContextConditionalFields)
+	c.SourceConditionalFields = copyMap(s.SourceConditionalFields)
+	if s.Envelope != nil {
+		envelope := *s.Envelope
+		c.Envelope = &envelope
+	}
+	if s.JSONAPI != nil {
+		resource := *s.JSONAPI
+		resource.Relationships = copySlice(s.JSONAPI.Relationships)
+		c.JSONAPI = &resource
+	}
+	return c
+}
+
+// copySlice returns a copy of a slice, keeping nil slices nil.
+func copySlice[T any](values []T) []T {
+	if values == nil {
+		return nil
+	}
+	return append([]T(nil), values...)
+}
+
+// copyMap returns a copy of a map, keeping nil maps nil.
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	return writableMap(m, 0)
+}
+
+// writableMap returns a copy of a map with room for extra more entries, which can be written
+// even when m is nil.
+func writableMap[K comparable, V any](m map[K]V, extra int) map[K]V {
+	copied := make(map[K]V, len(m)+extra)
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// copySliceMap returns a copy of a map of slices that shares no slices with the original,
+// keeping nil maps nil.
+func copySliceMap[K comparable, V any](m map[K][]V) map[K][]V {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[K][]V, len(m))
+	for key, values := range m {
+		copied[key] = copySlice(values)
+	}
+	return copied
+}
+
+// copyEnums returns a copy of Enums that shares no values or labels with the original.
+func copyEnums(m map[string]EnumField) map[string]EnumField {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]EnumField, len(m))
+	for field, enum := range m {
+		copied[field] = EnumField{Values: copySlice(enum.Values), Labels: copyMap(enum.Labels)}
+	}
+	return copied
+}
+
+// copyTimeFormats returns a copy of TimeFormats that shares no accepted layouts with the original.
+func copyTimeFormats(m map[string]TimeFormat) map[string]TimeFormat {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]TimeFormat, len(m))
+	for field, format := range m {
+		format.Accept = copySlice(format.Accept)
+		copied[field] = format
+	}
+	return copied
+}
+
+// copyProfiles returns a copy of Profiles that shares no maps or slices with the original.
+func copyProfiles(m map[string]Profile) map[string]Profile {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]Profile, len(m))
+	for name, profile := range m {
+		profile.StringifyIntegers = copySlice(profile.StringifyIntegers)
+		profile.Flatten = copySlice(profile.Flatten)
+		profile.Omit = copySlice(profile.Omit)
+		profile.Rename = copyMap(profile.Rename)
+		profile.Transformations = copyMap(profile.Transformations)
+		if profile.EnumFallbacks != nil {
+			fallbacks := make(map[string]EnumFallback, len(profile.EnumFallbacks))
+			for field, fallback := range profile.EnumFallbacks {
+				fallback.Values = copySlice(fallback.Values)
+				fallbacks[field] = fallback
+			}
+			profile.EnumFallbacks = fallbacks
+		}
+		copied[name] = profile
+	}
+	return copied
+}
@@ -0,0 +1,225 @@
+package serializer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCloneIsDeep(t *testing.T) {
+	nested := &BaseSerializer{Fields: []string{"street"}}
+	original := &BaseSerializer{
+		Fields:      []string{"id"},
+		Validations: map[string][]func(interface{}) error{"id": {NotEmpty}},
+		Enums:       map[string]EnumField{"status": {Values: []interface{}{1}, Labels: map[interface{}]string{1: "active"}}},
+		TimeFormats: map[string]TimeFormat{"at": {Accept: []string{"2006-01-02"}}},
+		Profiles:    map[string]Profile{"legacy": {Omit: []string{"x"}, Rename: map[string]string{"a": "b"}}},
+		Versions:    map[string][]Option{"v1": {WithFields("id")}},
+		Envelope:    &Envelope{DataKey: "data"},
+		JSONAPI:     &JSONAPIResource{Type: "users", Relationships: []string{"team"}},
+		Nested:      map[string]*BaseSerializer{"address": nested},
+		Registry:    NewRegistry(),
+	}
+	before := func() []interface{} {
+		return []interface{}{
+			original.Fields, len(original.Validations["id"]), original.Enums["status"].Values, original.Enums["status"].Labels,
+			original.TimeFormats["at"].Accept, original.Profiles["legacy"].Omit, original.Profiles["legacy"].Rename,
+			len(original.Versions["v1"]), *original.Envelope, original.JSONAPI.Relationships, nested.Fields,
+		}
+	}
+	want := before()
+
+	c := original.Clone()
+	mutations := []struct {
+		name   string
+		mutate func()
+	}{
+		{"slice", func() { c.Fields[0] = "name" }},
+		{"map of slices", func() { c.Validations["id"] = append(c.Validations["id"][:0], NotEmpty) }},
+		{"enum values", func() { c.Enums["status"].Values[0] = 2 }},
+		{"enum labels", func() { c.Enums["status"].Labels[1] = "inactive" }},
+		{"time format layouts", func() { c.TimeFormats["at"].Accept[0] = "15:04" }},
+		{"profile slice", func() { c.Profiles["legacy"].Omit[0] = "y" }},
+		{"profile map", func() { c.Profiles["legacy"].Rename["a"] = "c" }},
+		{"version options", func() { c.Versions["v1"] = append(c.Versions["v1"][:0], WithFields("name")) }},
+		{"envelope", func() { c.Envelope.DataKey = "items" }},
+		{"JSON:API relationships", func() { c.JSONAPI.Relationships[0] = "owner" }},
+		{"nested serializer", func() { c.Nested["address"].Fields[0] = "city" }},
+	}
+	for _, m := range mutations {
+		m.mutate()
+		if got := before(); !reflect.DeepEqual(got, want) {
+			t.Errorf("mutating the %s of the clone changed the original: %v, want %v", m.name, got, want)
+			want = got
+		}
+	}
+	if c.Nested["address"] == nested {
+		t.Error("Clone() shares the nested serializer")
+	}
+	if c.Registry != original.Registry {
+		t.Error("Clone() copied the Registry, which is shared")
+	}
+}
+
+func TestCloneKeepsNilMaps(t *testing.T) {
+	c := (&BaseSerializer{}).Clone()
+	maps := map[string]interface{}{
+		"Validations":     c.Validations,
+		"Transformations": c.Transformations,
+		"Enums":           c.Enums,
+		"Profiles":        c.Profiles,
+		"Versions":        c.Versions,
+		"Nested":          c.Nested,
+		"Defaults":        c.Defaults,
+	}
+	for name, m := range maps {
+		if !reflect.ValueOf(m).IsNil() {
+			t.Errorf("Clone() allocated %s for a nil map", name)
+		}
+	}
+	if c.Envelope != nil || c.JSONAPI != nil {
+		t.Error("Clone() allocated Envelope or JSONAPI")
+	}
+}
+
+func TestCloneRecursiveNested(t *testing.T) {
+	tree := &BaseSerializer{Fields: []string{"name", "children"}}
+	tree.Nested = map[string]*BaseSerializer{"children": tree}
+
+	c := tree.Clone()
+	if c.Nested["children"] != c {
+		t.Errorf("Clone() of a recursive serializer = %p, want the clone itself %p", c.Nested["children"], c)
+	}
+}
+
+func TestOptionsOnNilMaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() *BaseSerializer
+	}{
+		{"WithValidation", func() *BaseSerializer { return (&BaseSerializer{}).WithValidation("id", NotEmpty) }},
+		{"WithTransformation", func() *BaseSerializer {
+			return (&BaseSerializer{}).WithTransformation("id", func(v interface{}) interface{} { return v })
+		}},
+		{"WithCondition", func() *BaseSerializer {
+			return (&BaseSerializer{}).WithCondition("id", func(map[string]interface{}) bool { return true })
+		}},
+		{"WithAliases", func() *BaseSerializer { return (&BaseSerializer{}).With(WithAliases(map[string]string{"id": "ID"})) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if s := tt.build(); s == nil {
+				t.Fatal("got nil serializer")
+			}
+		})
+	}
+}
+
+func TestSerializeWithOptions(t *testing.T) {
+	base := &BaseSerializer{ExcludedFields: []string{"role"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]interface{}
+	}{
+		{"no options", nil, map[string]interface{}{"id": 7.0, "name": "ana"}},
+		{"fields", []Option{WithFields("name")}, map[string]interface{}{"name": "ana"}},
+		{"exclude", []Option{WithExclude("name")}, map[string]interface{}{"id": 7.0}},
+		{"fields and exclude", []Option{WithFields("id", "name", "role"), WithExclude("id")}, map[string]interface{}{"name": "ana"}},
+	}
+	serializers := map[string]interface {
+		SerializeWith(interface{}, ...Option) (map[string]interface{}, error)
+	}{
+		"BaseSerializer":      base,
+		"ImmutableSerializer": &ImmutableSerializer{config: base},
+	}
+	for kind, s := range serializers {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				got, err := s.SerializeWith(account{ID: 7, Name: "ana", Role: "admin"}, tt.opts...)
+				if err != nil {
+					t.Fatalf("SerializeWith() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("SerializeWith() = %v, want %v", got, tt.want)
+				}
+				if !reflect.DeepEqual(base.ExcludedFields, []string{"role"}) || base.Fields != nil {
+					t.Errorf("SerializeWith() modified the serializer: %+v", base)
+				}
+			})
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	s := NewSerializer().
+		Fields("id", "name", "role", "label").
+		Exclude("role").
+		Validate("name", NotEmpty).
+		Rules("role", "oneOf:admin,member").
+		Transform("name", func(value interface{}) interface{} { return strings.ToUpper(value.(string)) }).
+		Condition("id", func(result map[string]interface{}) bool { return result["name"] != "" }).
+		Compute("label", func(data interface{}) (interface{}, error) { return "#" + strconv.Itoa(data.(account).ID), nil }).
+		ReadOnly("id").
+		Default("role", "member").
+		Build()
+
+	got, err := s.Serialize(account{ID: 7, Name: "ana", Role: "admin"})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if want := map[string]interface{}{"id": 7.0, "name": "ANA", "label": "#7"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize() = %v, want %v", got, want)
+	}
+	if err := s.Validate(map[string]interface{}{"name": ""}); ErrorCode(err) != CodeEmpty {
+		t.Errorf("Validate() error = %v, want %s", err, CodeEmpty)
+	}
+	if err := s.Validate(map[string]interface{}{"name": "ana", "role": "owner"}); ErrorCode(err) != CodeNotAllowed {
+		t.Errorf("Validate() error = %v, want %s", err, CodeNotAllowed)
+	}
+	var out account
+	if err := s.Deserialize(map[string]interface{}{"id": 9.0, "name": "ana"}, &out); err != nil || out != (account{Name: "ana", Role: "member"}) {
+		t.Errorf("Deserialize() = %+v, %v, want the default role and no id", out, err)
+	}
+}
+
+func TestBuildIsImmutable(t *testing.T) {
+	b := NewSerializer().Fields("id", "name").Validate("name", NotEmpty).Default("role", "member")
+	built := b.Build()
+
+	b.Fields("name", "role").
+		Exclude("name").
+		Validate("name", ValidEmail).
+		Transform("role", func(interface{}) interface{} { return "changed" }).
+		Default("role", "admin").
+		Configure(func(s *BaseSerializer) { s.ReadOnlyFields = append(s.ReadOnlyFields, "name") })
+	rebuilt := b.Build()
+
+	data := account{ID: 7, Name: "ana", Role: "admin"}
+	if got, err := built.Serialize(data); err != nil || !reflect.DeepEqual(got, map[string]interface{}{"id": 7.0, "name": "ana"}) {
+		t.Errorf("Serialize() after changing the builder = %v, %v, want the original configuration", got, err)
+	}
+	if err := built.Validate(map[string]interface{}{"name": "ana"}); err != nil {
+		t.Errorf("Validate() after changing the builder error = %v", err)
+	}
+	var out account
+	if err := built.Deserialize(map[string]interface{}{"name": "ana"}, &out); err != nil || out != (account{Name: "ana", Role: "member"}) {
+		t.Errorf("Deserialize() after changing the builder = %+v, %v", out, err)
+	}
+
+	if got, err := rebuilt.Serialize(data); err != nil || !reflect.DeepEqual(got, map[string]interface{}{"role": "changed"}) {
+		t.Errorf("Serialize() of the rebuilt serializer = %v, %v", got, err)
+	}
+	if err := rebuilt.Validate(map[string]interface{}{"name": "ana"}); ErrorCode(err) != CodeInvalidEmail {
+		t.Errorf("Validate() of the rebuilt serializer error = %v, want %s", err, CodeInvalidEmail)
+	}
+
+	view := built.View(WithFields("name"))
+	if got, err := built.Serialize(data); err != nil || len(got) != 2 {
+		t.Errorf("Serialize() after View = %v, %v, want the original fields", got, err)
+	}
+	if got, err := view.Serialize(data); err != nil || !reflect.DeepEqual(got, map[string]interface{}{"name": "ana"}) {
+		t.Errorf("View().Serialize() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,142 @@
+package serializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// BulkDeserializer deserializes large batches of inputs into values of type T, amortizing
+// allocations across items: intermediate maps are pooled and reused, results are appended to a
+// caller-provided slice, and streams are read with a single decoder. Every item goes through the
+// pipeline of Deserialize, except for Hooks. A BulkDeserializer is safe for concurrent use.
+type BulkDeserializer[T any] struct {
+	serializer *BaseSerializer
+	mapDecoder bool // T implements MapDecoder
+	pool       sync.Pool
+}
+
+// bulkState holds the scratch space reused between items.
+type bulkState struct {
+	input   map[string]interface{} // Objects read by DecodeStream
+	scratch map[string]interface{} // Prepared input of deserializeInto
+}
+
+// NewBulkDeserializer creates a BulkDeserializer for T that applies the configuration of s.
+func NewBulkDeserializer[T any](s *BaseSerializer) *BulkDeserializer[T] {
+	if s == nil {
+		s = &BaseSerializer{}
+	}
+	b := &BulkDeserializer[T]{
+		serializer: s,
+		mapDecoder: reflect.TypeOf((*T)(nil)).Implements(mapDecoderType),
+	}
+	b.pool.New = func() interface{} {
+		return &bulkState{
+			input:   make(map[string]interface{}),
+			scratch: make(map[string]interface{}),
+		}
+	}
+	return b
+}
+
+// DeserializeAll deserializes every input and appends the results to dst, which is
+// truncated first so its capacity can be reused between batches.
+// Failures are reported as a *BulkError carrying the index of the item.
+func (b *BulkDeserializer[T]) DeserializeAll(inputs []map[string]interface{}, dst []T) ([]T, error) {
+	state := b.pool.Get().(*bulkState)
+	defer b.pool.Put(state)
+
+	dst = grow(dst[:0], len(inputs))
+	for i, input := range inputs {
+		var zero T
+		dst = append(dst, zero)
+		if err := b.decodeMap(state, input, &dst[i]); err != nil {
+			return dst[:i], &BulkError{Index: i, Err: err}
+		}
+	}
+	return dst, nil
+}
+
+// DecodeStream reads a stream of JSON objects (newline-delimited or concatenated) and appends
+// the results to dst, which is truncated first so its capacity can be reused between batches.
+// Failures are reported as a *BulkError carrying the index of the item.
+func (b *BulkDeserializer[T]) DecodeStream(r io.Reader, dst []T) ([]T, error) {
+	state := b.pool.Get().(*bulkState)
+	defer b.pool.Put(state)
+
+	s := b.serializer
+	dec := json.NewDecoder(r)
+	if s.UseNumber {
+		dec.UseNumber()
+	}
+
+	dst = dst[:0]
+	for i := 0; ; i++ {
+		if !dec.More() {
+			// Consume trailing whitespace and detect garbage after the last object
+			if _, err := dec.Token(); err != io.EOF {
+				return dst, &BulkError{Index: i, Err: &SerializationError{Message: "failed to parse JSON stream: unexpected data"}}
+			}
+			return dst, nil
+		}
+
+		var zero T
+		dst = append(dst, zero)
+		out := &dst[len(dst)-1]
+
+		var err error
+		switch {
+		case s.DuplicateKeys == DuplicateKeysLastWins:
+			clearMap(state.input)
+			if decodeErr := dec.Decode(&state.input); decodeErr != nil {
+				err = &SerializationError{Message: fmt.Sprintf("failed to parse JSON stream: %v", decodeErr)}
+			} else {
+				err = b.decodeMap(state, state.input, out)
+			}
+		default:
+			var value interface{}
+			if value, err = decodeJSONValue(dec, s.DuplicateKeys, ""); err == nil {
+				input, ok := value.(map[string]interface{})
+				if !ok {
+					err = &SerializationError{Message: "failed to parse JSON stream: value is not an object"}
+				} else {
+					err = b.decodeMap(state, input, out)
+				}
+			}
+		}
+		if err != nil {
+			return dst[:len(dst)-1], &BulkError{Index: i, Err: err}
+		}
+	}
+}
+
+// decodeMap deserializes a single input into out like Deserialize, using the reusable state.
+func (b *BulkDeserializer[T]) decodeMap(state *bulkState, input map[string]interface{}, out *T) error {
+	if !b.serializer.needsPreparation() && !b.mapDecoder {
+		// Nothing to apply before decoding: skip copying the input
+		return b.serializer.decoder().decode(input, out)
+	}
+	clearMap(state.scratch)
+	return b.serializer.deserializeInto(context.Background(), input, out, state.scratch)
+}
+
+// grow makes sure dst has room for n more elements.
+func grow[T any](dst []T, n int) []T {
+	if cap(dst)-len(dst) >= n {
+		return dst
+	}
+	grown := make([]T, len(dst), len(dst)+n)
+	copy(grown, dst)
+	return grown
+}
+
+// clearMap removes every entry from m, keeping its allocated space.
+func clearMap(m map[string]interface{}) {
+	for key := range m {
+		delete(m, key)
+	}
+}
@@ -0,0 +1,125 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bulkItem struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Active   bool   `json:"active"`
+	Count    int64  `json:"count"`
+}
+
+func TestBulkDeserializerMatchesDeserialize(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      *BaseSerializer
+		inputs []map[string]interface{}
+	}{
+		{"plain", &BaseSerializer{}, []map[string]interface{}{
+			{"id": 1, "full_name": "x", "active": true},
+			{"ID": 2, "Full_Name": "y"},
+		}},
+		{"aliases", &BaseSerializer{Aliases: map[string]string{"full_name": "name"}}, []map[string]interface{}{
+			{"id": 1, "name": "x"},
+		}},
+		{"envelope", &BaseSerializer{Envelope: &Envelope{}}, []map[string]interface{}{
+			{"data": map[string]interface{}{"id": 1, "full_name": "x"}},
+		}},
+		{"key naming", &BaseSerializer{KeyNaming: NamingCamelCase}, []map[string]interface{}{
+			{"id": 1, "fullName": "x"},
+		}},
+		{"read-only fields and defaults", &BaseSerializer{ReadOnlyFields: []string{"id"}, Defaults: map[string]interface{}{"active": true}}, []map[string]interface{}{
+			{"id": 1, "full_name": "x"},
+		}},
+		{"sanitizers", &BaseSerializer{Sanitizers: map[string][]func(string) string{"full_name": {TrimSpace}}}, []map[string]interface{}{
+			{"id": 1, "full_name": "  x  "},
+		}},
+		{"coercion", &BaseSerializer{Coerce: true}, []map[string]interface{}{
+			{"id": "1", "full_name": "x", "active": "true"},
+		}},
+		{"weakly typed", &BaseSerializer{WeaklyTyped: true}, []map[string]interface{}{
+			{"id": "1", "active": "yes"},
+		}},
+		{"renamed fields", &BaseSerializer{RenamedFields: map[string]string{"name": "full_name"}}, []map[string]interface{}{
+			{"id": 1, "name": "x"},
+		}},
+		{"enum labels", &BaseSerializer{Enums: map[string]EnumField{"id": {Labels: map[interface{}]string{1: "first"}}}}, []map[string]interface{}{
+			{"id": "first", "full_name": "x"},
+		}},
+		{"schema", &BaseSerializer{Schema: map[string]Field{"id": IntField{}}}, []map[string]interface{}{
+			{"id": "1", "full_name": "x"},
+		}},
+		{"decode hooks", &BaseSerializer{DecodeHooks: []DecodeHook{TypeHook(func(v interface{}) (bool, error) { return v == "on", nil })}}, []map[string]interface{}{
+			{"id": 1, "active": "on"},
+		}},
+		{"use number", &BaseSerializer{UseNumber: true}, []map[string]interface{}{
+			{"id": 1, "count": json.Number("9007199254740993")},
+		}},
+		{"use number with the last key winning", &BaseSerializer{UseNumber: true, DuplicateKeys: DuplicateKeysLastWins}, []map[string]interface{}{
+			{"id": 1, "count": json.Number("9007199254740993")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([]bulkItem, len(tt.inputs))
+			var stream strings.Builder
+			for i, input := range tt.inputs {
+				if err := tt.s.Deserialize(input, &want[i]); err != nil {
+					t.Fatalf("Deserialize() error = %v", err)
+				}
+				encoded, _ := json.Marshal(input)
+				stream.Write(encoded)
+				stream.WriteByte('\n')
+			}
+
+			b := NewBulkDeserializer[bulkItem](tt.s)
+			got, err := b.DeserializeAll(tt.inputs, nil)
+			if err != nil {
+				t.Fatalf("DeserializeAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("DeserializeAll() = %+v, want %+v", got, want)
+			}
+
+			got, err = b.DecodeStream(strings.NewReader(stream.String()), got)
+			if err != nil {
+				t.Fatalf("DecodeStream() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("DecodeStream() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestBulkDeserializerErrors(t *testing.T) {
+	b := NewBulkDeserializer[bulkItem](&BaseSerializer{ReadOnlyFields: []string{"id"}, RejectReadOnly: true})
+	tests := []struct {
+		name   string
+		stream string
+		index  int
+		count  int // Items decoded before the failure
+	}{
+		{"read-only field", `{"full_name":"x"}` + "\n" + `{"ID":1}`, 1, 1},
+		{"invalid JSON", `{"full_name":"x"} {`, 1, 1},
+		{"not an object", `[1]`, 0, 0},
+		{"trailing data", `{"full_name":"x"} ]`, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.DecodeStream(strings.NewReader(tt.stream), nil)
+			bulkErr, ok := err.(*BulkError)
+			if !ok || bulkErr.Index != tt.index {
+				t.Fatalf("DecodeStream() error = %v, want a bulk error at %d", err, tt.index)
+			}
+			if len(got) != tt.count {
+				t.Errorf("DecodeStream() decoded %d items, want %d", len(got), tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package serializer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// SerializeCanonical serializes data and encodes the output as canonical JSON (see CanonicalJSON).
+func (s *BaseSerializer) SerializeCanonical(data interface{}) ([]byte, error) {
+	result, err := s.Serialize(data)
+	if err != nil {
+		return nil, err
+	}
+	return CanonicalJSON(result)
+}
+
+// CanonicalJSON encodes a value as canonical JSON following the JSON Canonicalization Scheme
+// (RFC 8785): no white space, object keys sorted by their UTF-16 code units, numbers written as
+// ECMAScript does and strings with minimal escaping. Numbers are IEEE 754 doubles, so integers
+// beyond 2^53 lose precision; serialize them as strings to sign them exactly.
+func CanonicalJSON(value interface{}) ([]byte, error) {
+	normalized, err := toJSONValueWith(reflect.ValueOf(value), sortedOptions)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode canonical JSON: %v", err), Err: err}
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, normalized); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode canonical JSON: %v", err), Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+// SignHMAC returns the hex-encoded HMAC-SHA256 of payload, usually canonical JSON, under key.
+func SignHMAC(payload, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMAC reports whether signature is the hex-encoded HMAC-SHA256 of payload under key. The
+// comparison takes constant time.
+func VerifyHMAC(payload, key []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// SHA256Digest returns the hex-encoded SHA-256 digest of payload.
+func SHA256Digest(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeCanonical writes a normalized value as canonical JSON.
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buf, v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %s", v)
+		}
+		return writeCanonicalNumber(buf, f)
+	case float64:
+		return writeCanonicalNumber(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value of type %T", value)
+	}
+	return nil
+}
+
+// writeCanonicalNumber writes f the way ECMAScript's Number.prototype.toString does.
+func writeCanonicalNumber(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unsupported number %v", f)
+	}
+	if f == 0 {
+		buf.WriteByte('0') // Also for -0
+		return nil
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		return nil
+	}
+
+	// Exponential notation without leading zeros in the exponent, e.g. 1e+21 and 1.5e-7
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+	buf.WriteString(mantissa + "e" + sign + digits)
+	return nil
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what JSON requires.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compares two strings by their UTF-16 code units, as RFC 8785 sorts object keys.
+func lessUTF16(a, b string) bool {
+	x, y := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
@@ -0,0 +1,101 @@
+package serializer
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"sorted keys without white space", map[string]interface{}{"b": 1, "a": []interface{}{true, nil}}, `{"a":[true,null],"b":1}`},
+		{"keys sorted by UTF-16 code units", map[string]interface{}{"\U0001F600": 1, "\uFB33": 2, "a": 3}, "{\"a\":3,\"\U0001F600\":1,\"\uFB33\":2}"},
+		{"minimal escaping", "é \"\\\n\x1f/<", `"é` + " " + `\"\\\n\u001f/<"`},
+		{"integers", []interface{}{0, -0.0, 1, -1, 1e20}, `[0,0,1,-1,100000000000000000000]`},
+		{"fractions", []interface{}{0.1, 1.5, 1e-6, -2.5e-7}, `[0.1,1.5,0.000001,-2.5e-7]`},
+		{"exponents", []interface{}{1e21, 1.5e300, 5e-324}, `[1e+21,1.5e+300,5e-324]`},
+		{"json.Number", json.Number("1.50"), `1.5`},
+		{"structs through their JSON form", struct {
+			B string `json:"b"`
+			A int    `json:"a"`
+		}{"x", 1}, `{"a":1,"b":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(tt.value)
+			if err != nil {
+				t.Fatalf("CanonicalJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"NaN", math.NaN()},
+		{"infinity", map[string]interface{}{"x": math.Inf(1)}},
+		{"invalid json.Number", json.Number("x")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := CanonicalJSON(tt.value); err == nil {
+				t.Errorf("CanonicalJSON() = %s, want an error", got)
+			}
+		})
+	}
+}
+
+func TestSignHMAC(t *testing.T) {
+	// RFC 4231, test case 2
+	signature := SignHMAC([]byte("what do ya want for nothing?"), []byte("Jefe"))
+	if want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; signature != want {
+		t.Fatalf("SignHMAC() = %s, want %s", signature, want)
+	}
+
+	tests := []struct {
+		name      string
+		payload   string
+		key       string
+		signature string
+		want      bool
+	}{
+		{"valid", "what do ya want for nothing?", "Jefe", signature, true},
+		{"other payload", "what do ya want for something?", "Jefe", signature, false},
+		{"other key", "what do ya want for nothing?", "jefe", signature, false},
+		{"not hex", "what do ya want for nothing?", "Jefe", "zz", false},
+		{"truncated", "what do ya want for nothing?", "Jefe", signature[:32], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyHMAC([]byte(tt.payload), []byte(tt.key), tt.signature); got != tt.want {
+				t.Errorf("VerifyHMAC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSHA256Digest(t *testing.T) {
+	if got, want := SHA256Digest([]byte("abc")), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("SHA256Digest() = %s, want %s", got, want)
+	}
+}
+
+func TestSerializeCanonical(t *testing.T) {
+	got, err := (&BaseSerializer{ExcludedFields: []string{"role"}}).SerializeCanonical(account{ID: 1, Name: "ana", Role: "admin"})
+	if err != nil {
+		t.Fatalf("SerializeCanonical() error = %v", err)
+	}
+	if want := `{"id":1,"name":"ana"}`; string(got) != want {
+		t.Errorf("SerializeCanonical() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FormatCodec converts serialized output to and from a wire format that isn't built in, such as
+// TOML or properties files. Encode receives maps, lists and scalars, as produced by Serialize.
+// Register codecs with RegisterFormat.
+type FormatCodec interface {
+	Encode(value map[string]interface{}) ([]byte, error)
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+// NativeCodec is a FormatCodec for a format with its own representation of some Go values, such
+// as the datetimes, binary data and ObjectIDs of BSON. SerializeAs keeps the values of the types
+// Native reports instead of converting them into strings, and passes the output to EncodeOrdered
+// with numbers as json.Number and the Order of the serializer. DeserializeAs reads the values
+// returned by Decode the way Serialize writes them in JSON, so validations see the same values in
+// every format.
+type NativeCodec interface {
+	FormatCodec
+	Native(t reflect.Type) bool
+	EncodeOrdered(value map[string]interface{}, order []string) ([]byte, error)
+}
+
+// registeredFormat is a FormatCodec with the media type and file extensions of its format.
+type registeredFormat struct {
+	codec      FormatCodec
+	mediaType  string
+	extensions []string
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[Format]*registeredFormat)
+)
+
+// RegisterFormat makes codec available under format to SerializeAs, SerializeTo, DeserializeAs
+// and DeserializeFrom, replacing any previous codec of the format. mediaType (e.g.
+// "text/x-java-properties") is returned by ContentType and recognized by FormatFromMediaType, so
+// the format can be negotiated over HTTP, and extensions (e.g. ".properties") are recognized by
+// FormatFromExtension. The built-in formats can't be replaced.
+func RegisterFormat(format Format, codec FormatCodec, mediaType string, extensions ...string) error {
+	switch {
+	case builtinFormat(format):
+		return &SerializationError{Message: fmt.Sprintf("format '%s' is built in and can't be replaced", format)}
+	case format == "" || codec == nil:
+		return &SerializationError{Message: "RegisterFormat needs a format name and a codec"}
+	}
+	if mediaType != "" {
+		parsed, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return &SerializationError{Message: fmt.Sprintf("invalid media type '%s' for format '%s': %v", mediaType, format, err), Err: err}
+		}
+		mediaType = parsed
+	}
+	registered := &registeredFormat{codec: codec, mediaType: mediaType}
+	for _, extension := range extensions {
+		extension = strings.ToLower(extension)
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		registered.extensions = append(registered.extensions, extension)
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[format] = registered
+	return nil
+}
+
+// LookupFormat returns the codec registered for format.
+func LookupFormat(format Format) (FormatCodec, bool) {
+	if registered := lookupFormat(format); registered != nil {
+		return registered.codec, true
+	}
+	return nil, false
+}
+
+func lookupFormat(format Format) *registeredFormat {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	return formats[format]
+}
+
+// findFormat returns the registered format matching match, or "".
+func findFormat(match func(*registeredFormat) bool) Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	for format, registered := range formats {
+		if match(registered) {
+			return format
+		}
+	}
+	return ""
+}
+
+// builtinFormat reports whether format is implemented by the package.
+func builtinFormat(format Format) bool {
+	switch format {
+	case FormatJSON, FormatXML, FormatYAML, FormatGob, FormatBinary:
+		return true
+	}
+	return false
+}
+
+// encodeWithCodec encodes serialized output in a registered format.
+func (s *BaseSerializer) encodeWithCodec(result map[string]interface{}, format Format, codec FormatCodec) ([]byte, error) {
+	// Reduce values set by transformations and hooks to maps, lists and scalars, and the native
+	// values of the format
+	opts := &encodeOptions{marshalers: true, numbers: s.UseNumber}
+	native, isNative := codec.(NativeCodec)
+	if isNative {
+		opts.numbers, opts.native = true, native.Native
+	}
+	normalized, err := toJSONValueWith(reflect.ValueOf(result), opts)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode %s: %v", format, err), Err: err}
+	}
+	object, _ := normalized.(map[string]interface{})
+	var encoded []byte
+	if isNative {
+		encoded, err = native.EncodeOrdered(object, s.Order)
+	} else {
+		encoded, err = codec.Encode(object)
+	}
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode %s: %v", format, err), Err: err}
+	}
+	return encoded, nil
+}
+
+// decodeWithCodec decodes input in a registered format.
+func (s *BaseSerializer) decodeWithCodec(data []byte, format Format, codec FormatCodec) (map[string]interface{}, error) {
+	input, err := codec.Decode(data)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to decode %s: %v", format, err), Err: err}
+	}
+	if _, ok := codec.(NativeCodec); ok {
+		// Read native values the way Serialize writes them in JSON
+		normalized, err := toJSONValueWith(reflect.ValueOf(input), &encodeOptions{numbers: s.UseNumber})
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to decode %s: %v", format, err), Err: err}
+		}
+		input, _ = normalized.(map[string]interface{})
+	}
+	return input, nil
+}
+
+// nativeValuesKey marks contexts of serializations encoded by a NativeCodec.
+type nativeValuesKey struct{}
+
+// withNativeValues returns a copy of ctx under which the serialized output keeps the values of the
+// types native reports instead of converting them into strings.
+func withNativeValues(ctx context.Context, native func(reflect.Type) bool) context.Context {
+	return context.WithValue(ctx, nativeValuesKey{}, native)
+}
+
+// nativeValues returns the function given to withNativeValues, or nil.
+func nativeValues(ctx context.Context) func(reflect.Type) bool {
+	native, _ := ctx.Value(nativeValuesKey{}).(func(reflect.Type) bool)
+	return native
+}
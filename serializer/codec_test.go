@@ -0,0 +1,195 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// propertiesCodec is a FormatCodec of flat key=value lines.
+type propertiesCodec struct{}
+
+func (propertiesCodec) Encode(value map[string]interface{}) ([]byte, error) {
+	lines := make([]string, 0, len(value))
+	for key, v := range value {
+		if _, ok := v.(map[string]interface{}); ok {
+			return nil, errors.New("nested objects are not supported")
+		}
+		lines = append(lines, fmt.Sprintf("%s=%v", key, v))
+	}
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func (propertiesCodec) Decode(data []byte) (map[string]interface{}, error) {
+	value := make(map[string]interface{})
+	for _, line := range strings.Split(string(data), "\n") {
+		key, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		value[key] = v
+	}
+	return value, nil
+}
+
+// nativeCodec is a NativeCodec keeping times, recording what it encodes and decoding it back.
+type nativeCodec struct {
+	encoded map[string]interface{}
+	order   []string
+}
+
+func (c *nativeCodec) Native(t reflect.Type) bool { return t == reflect.TypeOf(time.Time{}) }
+
+func (c *nativeCodec) Encode(value map[string]interface{}) ([]byte, error) {
+	return c.EncodeOrdered(value, nil)
+}
+
+func (c *nativeCodec) EncodeOrdered(value map[string]interface{}, order []string) ([]byte, error) {
+	c.encoded, c.order = value, order
+	return []byte("native"), nil
+}
+
+func (c *nativeCodec) Decode(data []byte) (map[string]interface{}, error) {
+	return c.encoded, nil
+}
+
+func TestRegisterFormat(t *testing.T) {
+	const format Format = "test-properties"
+	if err := RegisterFormat(format, propertiesCodec{}, "Text/X-Test-Properties; charset=utf-8", "TPROPS", ".tprop"); err != nil {
+		t.Fatalf("RegisterFormat() error = %v", err)
+	}
+
+	invalid := []struct {
+		name      string
+		format    Format
+		codec     FormatCodec
+		mediaType string
+	}{
+		{"built-in format", FormatJSON, propertiesCodec{}, ""},
+		{"no name", "", propertiesCodec{}, ""},
+		{"no codec", "test-none", nil, ""},
+		{"invalid media type", "test-bad", propertiesCodec{}, "text/"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := RegisterFormat(tt.format, tt.codec, tt.mediaType).(*SerializationError); !ok {
+				t.Errorf("RegisterFormat() error is not a SerializationError")
+			}
+			if tt.format != "" && tt.format != FormatJSON {
+				if _, ok := LookupFormat(tt.format); ok {
+					t.Errorf("RegisterFormat() registered %q after an error", tt.format)
+				}
+			}
+		})
+	}
+
+	lookups := []struct {
+		name string
+		got  Format
+	}{
+		{"media type", FormatFromMediaType("text/x-test-properties")},
+		{"extension", FormatFromExtension("app.TPROPS")},
+		{"dotted extension", FormatFromExtension("app.tprop")},
+	}
+	for _, tt := range lookups {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != format {
+				t.Errorf("lookup = %q, want %q", tt.got, format)
+			}
+		})
+	}
+	if got := format.ContentType(); got != "text/x-test-properties" {
+		t.Errorf("ContentType() = %q, want the registered media type", got)
+	}
+	if got := Format("test-unknown").ContentType(); got != "application/octet-stream" {
+		t.Errorf("ContentType() of an unknown format = %q", got)
+	}
+}
+
+func TestNativeCodec(t *testing.T) {
+	const format Format = "test-native"
+	codec := &nativeCodec{}
+	if err := RegisterFormat(format, codec, ""); err != nil {
+		t.Fatalf("RegisterFormat() error = %v", err)
+	}
+	type event struct {
+		ID   int        `json:"id"`
+		At   time.Time  `json:"at"`
+		Next *time.Time `json:"next"`
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := &BaseSerializer{Order: []string{"at"}}
+	if _, err := s.SerializeAs(event{ID: 7, At: at, Next: &at}, format); err != nil {
+		t.Fatalf("SerializeAs() error = %v", err)
+	}
+	want := map[string]interface{}{"id": json.Number("7"), "at": at, "next": at}
+	if !reflect.DeepEqual(codec.encoded, want) || !reflect.DeepEqual(codec.order, s.Order) {
+		t.Errorf("EncodeOrdered() received %#v with order %q, want %#v", codec.encoded, codec.order, want)
+	}
+
+	// Decoded values are read like JSON, and other formats keep converting times into strings
+	var got event
+	if err := s.DeserializeAs(nil, format, &got); err != nil || got.ID != 7 || !got.At.Equal(at) || got.Next == nil {
+		t.Errorf("DeserializeAs() = %+v, %v", got, err)
+	}
+	result, err := s.Serialize(event{At: at})
+	if err != nil || result["at"] != "2024-05-01T12:00:00Z" {
+		t.Errorf("Serialize() = %v, %v, want the time as a string", result, err)
+	}
+}
+
+func TestRegisteredFormatRoundTrip(t *testing.T) {
+	const format Format = "test-properties-round-trip"
+	if err := RegisterFormat(format, propertiesCodec{}, ""); err != nil {
+		t.Fatalf("RegisterFormat() error = %v", err)
+	}
+	s := &BaseSerializer{
+		Fields: []string{"name", "role", "since"},
+		ComputedFields: map[string]func(interface{}) (interface{}, error){
+			"since": func(interface{}) (interface{}, error) { return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), nil },
+		},
+	}
+	encoded, err := s.SerializeAs(account{Name: "ana", Role: "admin"}, format)
+	if err != nil {
+		t.Fatalf("SerializeAs() error = %v", err)
+	}
+	if want := "name=ana\nrole=admin\nsince=2024-05-01T00:00:00Z"; string(encoded) != want {
+		t.Errorf("SerializeAs() = %q, want %q", encoded, want)
+	}
+	var out account
+	if err := s.DeserializeAs(encoded, format, &out); err != nil || out != (account{Name: "ana", Role: "admin"}) {
+		t.Errorf("DeserializeAs() = %+v, %v", out, err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"encode error", func() error {
+			_, err := (&BaseSerializer{}).SerializeAs(struct {
+				Nested account `json:"nested"`
+			}{}, format)
+			return err
+		}},
+		{"decode error", func() error { return s.DeserializeAs([]byte("no equals sign"), format, &out) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if _, ok := err.(*SerializationError); !ok || !strings.Contains(err.Error(), string(format)) {
+				t.Errorf("error = %v, want a SerializationError naming the format", err)
+			}
+		})
+	}
+
+	codec, ok := LookupFormat(format)
+	if !ok || !reflect.DeepEqual(codec, propertiesCodec{}) {
+		t.Errorf("LookupFormat() = %v, %v, want the registered codec", codec, ok)
+	}
+}
@@ -0,0 +1,201 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// coerceInput converts the input values of coerced fields to the types of the fields of t.
+func (s *BaseSerializer) coerceInput(input map[string]interface{}, t reflect.Type) error {
+	t = baseType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, field := range schemaFor(t).fields {
+		value, exists := input[field.name]
+		if !exists || !(s.Coerce || s.WeaklyTyped || containsField(s.CoerceFields, field.name)) {
+			continue
+		}
+		coerced, err := coerceTo(value, field.typ, field.name, s.WeaklyTyped)
+		if err != nil {
+			return err
+		}
+		input[field.name] = coerced
+	}
+	return nil
+}
+
+// coerceTo converts a decoded value to a value encoding/json can decode into type t: numeric
+// strings become numbers, "true" and "false" booleans, numbers and booleans strings, and numbers
+// are checked against the range of t. With weak, "on", "yes" and "y" are also true, "off", "no",
+// "n" and "" false, and a single value where a list is expected becomes a list of one item.
+func coerceTo(value interface{}, t reflect.Type, path string, weak bool) (interface{}, error) {
+	t = baseType(t)
+	if value == nil || t.Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return value, nil
+	}
+
+	invalid := func(message, code string) error {
+		return &ValidationError{Field: path, Value: value, Message: message, Code: code}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := integerValue(value)
+		if !ok {
+			return nil, invalid("value is not an integer", CodeInvalidType)
+		}
+		if !n.IsInt64() || reflect.Zero(t).OverflowInt(n.Int64()) {
+			return nil, invalid(fmt.Sprintf("value is out of range for %s", t.Kind()), CodeOutOfRange)
+		}
+		return json.Number(n.String()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := integerValue(value)
+		if !ok {
+			return nil, invalid("value is not an integer", CodeInvalidType)
+		}
+		if n.Sign() < 0 || !n.IsUint64() || reflect.Zero(t).OverflowUint(n.Uint64()) {
+			return nil, invalid(fmt.Sprintf("value is out of range for %s", t.Kind()), CodeOutOfRange)
+		}
+		return json.Number(n.String()), nil
+	case reflect.Float32, reflect.Float64:
+		f, ok := numberValue(value)
+		if str, isString := value.(string); isString {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+			f, ok = parsed, err == nil
+		}
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, invalid("value is not a number", CodeInvalidType)
+		}
+		if reflect.Zero(t).OverflowFloat(f) {
+			return nil, invalid(fmt.Sprintf("value is out of range for %s", t.Kind()), CodeOutOfRange)
+		}
+		return f, nil
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+			if b, ok := weakBools[strings.ToLower(strings.TrimSpace(v))]; ok && weak {
+				return b, nil
+			}
+		default:
+			if n, ok := numberValue(value); ok && (n == 0 || n == 1) {
+				return n == 1, nil
+			}
+		}
+		return nil, invalid("value is not a boolean", CodeInvalidType)
+	case reflect.String:
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case json.Number:
+			return v.String(), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		return value, nil
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			if _, isString := value.(string); !weak || (isString && t.Elem().Kind() == reflect.Uint8) {
+				return value, nil // Strings for []byte are base64
+			}
+			items = []interface{}{value}
+		}
+		coerced := make([]interface{}, len(items))
+		for i, item := range items {
+			converted, err := coerceTo(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), weak)
+			if err != nil {
+				return nil, err
+			}
+			coerced[i] = converted
+		}
+		return coerced, nil
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			return value, nil
+		}
+		coerced := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			converted, err := coerceTo(item, t.Elem(), joinKeyPath(path, key), weak)
+			if err != nil {
+				return nil, err
+			}
+			coerced[key] = converted
+		}
+		return coerced, nil
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		coerced := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			coerced[key] = item
+		}
+		for _, field := range schemaFor(t).fields {
+			item, exists := coerced[field.name]
+			if !exists {
+				continue
+			}
+			converted, err := coerceTo(item, field.typ, joinKeyPath(path, field.name), weak)
+			if err != nil {
+				return nil, err
+			}
+			coerced[field.name] = converted
+		}
+		return coerced, nil
+	default:
+		return value, nil
+	}
+}
+
+// weakBools are the booleans accepted by weakly typed coercion besides those of strconv.ParseBool.
+var weakBools = map[string]bool{"on": true, "yes": true, "y": true, "off": false, "no": false, "n": false, "": false}
+
+// integerValue returns the whole number a decoded value or numeric string stands for.
+func integerValue(value interface{}) (*big.Int, bool) {
+	var text string
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		n, _ := big.NewFloat(v).Int(nil)
+		return n, true
+	case int:
+		return big.NewInt(int64(v)), true
+	case int64:
+		return big.NewInt(v), true
+	case json.Number:
+		text = v.String()
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		return nil, false
+	}
+
+	if n, ok := new(big.Int).SetString(text, 10); ok {
+		return n, true
+	}
+	f, _, err := big.ParseFloat(text, 10, 256, big.ToNearestEven)
+	if err != nil || !f.IsInt() {
+		return nil, false
+	}
+	if f.MantExp(nil) > 128 {
+		// Out of range for every integer type; don't expand exponents like 1e600000000
+		return new(big.Int).Lsh(big.NewInt(int64(f.Sign())), 128), true
+	}
+	n, _ := f.Int(nil)
+	return n, true
+}
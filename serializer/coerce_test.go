@@ -0,0 +1,112 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoerceTo(t *testing.T) {
+	type inner struct {
+		N int `json:"n"`
+	}
+	tests := []struct {
+		name  string
+		value interface{}
+		typ   interface{} // Value of the target type
+		weak  bool
+		want  interface{}
+	}{
+		{"numeric string to int", " 42 ", int(0), false, json.Number("42")},
+		{"whole float to int", 3.0, int8(0), false, json.Number("3")},
+		{"exponent to int", "1e3", int(0), false, json.Number("1000")},
+		{"big uint64", "18446744073709551615", uint64(0), false, json.Number("18446744073709551615")},
+		{"string to float", "1.5", float32(0), false, 1.5},
+		{"number to float", json.Number("2"), float64(0), false, 2.0},
+		{"string to bool", "TRUE", false, false, true},
+		{"number to bool", 0.0, false, false, false},
+		{"weak bool", "yes", false, true, true},
+		{"number to string", 1.5, "", false, "1.5"},
+		{"bool to string", true, "", false, "true"},
+		{"list items", []interface{}{"1", 2.0}, []int{}, false, []interface{}{json.Number("1"), json.Number("2")}},
+		{"single value stays without weak", "1", []int{}, false, "1"},
+		{"weak single value to list", "1", []int{}, true, []interface{}{json.Number("1")}},
+		{"base64 kept for bytes", "AQI=", []byte{}, true, "AQI="},
+		{"map values", map[string]interface{}{"a": "1"}, map[string]int{}, false, map[string]interface{}{"a": json.Number("1")}},
+		{"struct fields", map[string]interface{}{"n": "1", "other": "x"}, inner{}, false, map[string]interface{}{"n": json.Number("1"), "other": "x"}},
+		{"pointer element", "7", new(int), false, json.Number("7")},
+		{"unmarshalers kept", "2024-01-01T00:00:00Z", time.Time{}, false, "2024-01-01T00:00:00Z"},
+		{"nil kept", nil, int(0), false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceTo(tt.value, reflect.TypeOf(tt.typ), "field", tt.weak)
+			if err != nil {
+				t.Fatalf("coerceTo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coerceTo() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoerceToInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		typ   interface{}
+		code  string
+		path  string
+	}{
+		{"fraction to int", 1.5, int(0), CodeInvalidType, "field"},
+		{"word to int", "many", int(0), CodeInvalidType, "field"},
+		{"overflow int8", "128", int8(0), CodeOutOfRange, "field"},
+		{"negative uint", "-1", uint(0), CodeOutOfRange, "field"},
+		{"huge exponent", "1e600000000", int64(0), CodeOutOfRange, "field"},
+		{"huge negative exponent for uint", "-1e600000000", uint64(0), CodeOutOfRange, "field"},
+		{"overflow float32", 1e39, float32(0), CodeOutOfRange, "field"},
+		{"NaN string", "NaN", float64(0), CodeInvalidType, "field"},
+		{"weak bool without WeaklyTyped", "yes", false, CodeInvalidType, "field"},
+		{"number to bool", 2.0, false, CodeInvalidType, "field"},
+		{"list item path", []interface{}{"1", "x"}, []int{}, CodeInvalidType, "field[1]"},
+		{"map value path", map[string]interface{}{"k": "x"}, map[string]int{}, CodeInvalidType, "field.k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coerceTo(tt.value, reflect.TypeOf(tt.typ), "field", false)
+			validationErr, ok := err.(*ValidationError)
+			if !ok || validationErr.Code != tt.code || validationErr.Field != tt.path {
+				t.Errorf("coerceTo() error = %v, want a %s error on %s", err, tt.code, tt.path)
+			}
+		})
+	}
+}
+
+func TestDeserializeCoerceFields(t *testing.T) {
+	input := map[string]interface{}{"id": "7", "name": 12.0, "role": "admin"}
+	tests := []struct {
+		name    string
+		s       *BaseSerializer
+		want    account
+		wantErr bool
+	}{
+		{"Coerce", &BaseSerializer{Coerce: true}, account{ID: 7, Name: "12", Role: "admin"}, false},
+		{"CoerceFields", &BaseSerializer{CoerceFields: []string{"id", "name"}}, account{ID: 7, Name: "12", Role: "admin"}, false},
+		{"field not in CoerceFields", &BaseSerializer{CoerceFields: []string{"id"}}, account{}, true},
+		{"without coercion", &BaseSerializer{}, account{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got account
+			err := tt.s.Deserialize(input, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deserialize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Deserialize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
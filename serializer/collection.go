@@ -0,0 +1,92 @@
+package serializer
+
+import "context"
+
+// Pagination describes the page of a collection. Page-based and cursor-based pagination can be
+// used separately or together; zero fields are left out of the output.
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`        // Current page, starting at 1
+	PerPage    int    `json:"per_page,omitempty"`    // Items per page
+	Total      *int   `json:"total,omitempty"`       // Total number of items, when known (e.g. Ptr(95))
+	TotalPages int    `json:"total_pages,omitempty"` // Computed from Total and PerPage when not set
+	NextCursor string `json:"next_cursor,omitempty"` // Cursor of the next page
+	PrevCursor string `json:"prev_cursor,omitempty"` // Cursor of the previous page
+}
+
+// CollectionSerializer serializes a page of items into a standard paginated response:
+//
+//	{"items": [...], "pagination": {"page": 2, "per_page": 20, "total": 95, "total_pages": 5}}
+//
+// When Item has an Envelope, the items become its data and the pagination is added to its meta.
+type CollectionSerializer struct {
+	Item          *BaseSerializer // Serializer of the elements
+	ItemsKey      string          // Key of the serialized items; "items" when empty
+	PaginationKey string          // Key of the pagination details; "pagination" when empty
+}
+
+// NewCollectionSerializer creates a collection serializer whose elements are serialized with item.
+func NewCollectionSerializer(item *BaseSerializer) *CollectionSerializer {
+	return &CollectionSerializer{Item: item}
+}
+
+// Serialize serializes a slice or array of items along with their pagination details.
+func (c *CollectionSerializer) Serialize(items interface{}, page Pagination) (map[string]interface{}, error) {
+	return c.SerializeWithContext(context.Background(), items, page)
+}
+
+// SerializeWithContext is like Serialize, passing ctx to the item serializer.
+func (c *CollectionSerializer) SerializeWithContext(ctx context.Context, items interface{}, page Pagination) (map[string]interface{}, error) {
+	if MetadataFromContext(ctx) == nil {
+		ctx = ContextWithMetadata(ctx, NewMetadata())
+	}
+
+	results, err := c.Item.SerializeManyWithContext(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]interface{}, len(results))
+	for i, result := range results {
+		list[i] = result
+	}
+
+	if page.TotalPages == 0 && page.Total != nil && page.PerPage > 0 {
+		page.TotalPages = (*page.Total + page.PerPage - 1) / page.PerPage
+	}
+	pagination, err := structToMap(page, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if envelope := c.Item.Envelope; envelope != nil {
+		wrapped, err := envelope.Wrap(ctx, items, list)
+		if err != nil {
+			return nil, err
+		}
+		meta := map[string]interface{}{c.paginationKey(): pagination}
+		if provided, ok := wrapped[envelope.metaKey()].(map[string]interface{}); ok {
+			for key, value := range provided {
+				meta[key] = value
+			}
+		}
+		wrapped[envelope.metaKey()] = meta
+		return wrapped, nil
+	}
+
+	return map[string]interface{}{c.itemsKey(): list, c.paginationKey(): pagination}, nil
+}
+
+// itemsKey returns the key of the serialized items.
+func (c *CollectionSerializer) itemsKey() string {
+	if c.ItemsKey == "" {
+		return "items"
+	}
+	return c.ItemsKey
+}
+
+// paginationKey returns the key of the pagination details.
+func (c *CollectionSerializer) paginationKey() string {
+	if c.PaginationKey == "" {
+		return "pagination"
+	}
+	return c.PaginationKey
+}
@@ -0,0 +1,44 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCollectionSerializer(t *testing.T) {
+	items := []account{{ID: 1, Name: "ana"}, {ID: 2, Name: "bo"}}
+	serialized := []interface{}{map[string]interface{}{"id": 1.0, "name": "ana"}, map[string]interface{}{"id": 2.0, "name": "bo"}}
+	tests := []struct {
+		name       string
+		collection *CollectionSerializer
+		page       Pagination
+		want       map[string]interface{}
+	}{
+		{"total pages computed", NewCollectionSerializer(&BaseSerializer{Fields: []string{"id", "name"}}), Pagination{Page: 2, PerPage: 20, Total: Ptr(95)},
+			map[string]interface{}{"items": serialized, "pagination": map[string]interface{}{"page": 2.0, "per_page": 20.0, "total": 95.0, "total_pages": 5.0}}},
+		{"cursor and custom keys", &CollectionSerializer{Item: &BaseSerializer{Fields: []string{"id", "name"}}, ItemsKey: "results", PaginationKey: "paging"}, Pagination{NextCursor: "abc"},
+			map[string]interface{}{"results": serialized, "paging": map[string]interface{}{"next_cursor": "abc"}}},
+		{"envelope", NewCollectionSerializer(&BaseSerializer{Fields: []string{"id", "name"}, Envelope: &Envelope{
+			Meta: func(context.Context, interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"request_id": "r1"}, nil
+			},
+		}}), Pagination{Page: 1},
+			map[string]interface{}{"data": serialized, "meta": map[string]interface{}{"request_id": "r1", "pagination": map[string]interface{}{"page": 1.0}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.collection.Serialize(items, tt.page)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewCollectionSerializer(&BaseSerializer{}).Serialize(account{}, Pagination{}); err == nil {
+		t.Errorf("Serialize() of a single item error = nil, want an error")
+	}
+}
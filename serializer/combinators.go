@@ -0,0 +1,103 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// And returns a validation that passes when every rule passes, checking them in order and
+// returning the first failure.
+func And(rules ...func(interface{}) error) func(interface{}) error {
+	return func(value interface{}) error {
+		for _, rule := range rules {
+			if err := rule(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Or returns a validation that passes when at least one rule passes, e.g.
+// Or(UUIDField{}.Validate, Positive) for identifiers that are either UUIDs or numbers. When every
+// rule fails, the failures are joined with "or", leaving out the rules that don't apply to the
+// type of the value when others do, and the code is the one they share, or CodeInvalid.
+func Or(rules ...func(interface{}) error) func(interface{}) error {
+	return func(value interface{}) error {
+		if len(rules) == 0 {
+			return nil
+		}
+		var failures, mismatches []error
+		for _, rule := range rules {
+			err := rule(value)
+			switch {
+			case err == nil:
+				return nil
+			case ErrorCode(err) == CodeInvalidType:
+				mismatches = append(mismatches, err)
+			default:
+				failures = append(failures, err)
+			}
+		}
+		if len(failures) == 0 {
+			failures = mismatches
+		}
+		if len(failures) == 1 {
+			return failures[0]
+		}
+
+		code := validationCode(failures[0])
+		var messages []string
+		for _, err := range failures {
+			if validationCode(err) != code {
+				code = CodeInvalid
+			}
+			if !containsField(messages, err.Error()) {
+				messages = append(messages, err.Error())
+			}
+		}
+		return &CodedError{Code: code, Message: strings.Join(messages, " or "), Err: errors.Join(failures...)}
+	}
+}
+
+// Not returns a validation that passes when rule fails, e.g. Not(OneOf(reserved...)) rejects
+// reserved names with CodeNotAllowed. Failures of rule caused by the type of the value
+// (CodeInvalidType) are returned unchanged rather than passing.
+func Not(rule func(interface{}) error) func(interface{}) error {
+	return func(value interface{}) error {
+		err := rule(value)
+		if err == nil {
+			return NewCodedError(CodeNotAllowed, "value is not allowed")
+		}
+		if ErrorCode(err) == CodeInvalidType {
+			return err
+		}
+		return nil
+	}
+}
+
+// Optional returns a validation that accepts null and empty strings and checks other values
+// with rule. A field whose validations are all Optional may also be left out of the input, which
+// Validate otherwise reports as missing.
+func Optional(rule func(interface{}) error) func(interface{}) error {
+	return func(value interface{}) error {
+		if value == nil || value == "" || rule == nil {
+			return nil
+		}
+		return rule(value)
+	}
+}
+
+// optionalCode identifies the validations returned by Optional, which share their code.
+var optionalCode = reflect.ValueOf(Optional(nil)).Pointer()
+
+// optional reports whether every validation was returned by Optional.
+func optional(validations []func(interface{}) error) bool {
+	for _, validation := range validations {
+		if validation == nil || reflect.ValueOf(validation).Pointer() != optionalCode {
+			return false
+		}
+	}
+	return len(validations) > 0
+}
@@ -0,0 +1,72 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCombinators(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation func(interface{}) error
+		value      interface{}
+		wantCode   string
+		wantMsg    string
+	}{
+		{"and passes", And(NotEmpty, Not(OneOf("root"))), "ana", "", ""},
+		{"and returns the first failure", And(NotEmpty, Not(OneOf(""))), "", CodeEmpty, ""},
+		{"and of no rules", And(), nil, "", ""},
+		{"or passes", Or(Positive, NotEmpty), "x", "", ""},
+		{"or of no rules", Or(), nil, "", ""},
+		{"or leaves out type mismatches", Or(Positive, NotEmpty), "", CodeEmpty, "value cannot be empty"},
+		{"or of mismatches only", Or(Positive, NotEmpty), true, CodeInvalidType, "value is not a number or value is not a string"},
+		{"or with a shared code", Or(OneOf("a"), OneOf("b")), "c", CodeNotAllowed, "value must be one of [a] or value must be one of [b]"},
+		{"or with different codes", Or(NotEmpty, OneOf("a")), "", CodeInvalid, "value cannot be empty or "},
+		{"not passes", Not(OneOf("root")), "ana", "", ""},
+		{"not fails", Not(OneOf("root")), "root", CodeNotAllowed, ""},
+		{"not keeps type mismatches", Not(NotEmpty), 1.0, CodeInvalidType, ""},
+		{"optional null", Optional(NotEmpty), nil, "", ""},
+		{"optional empty string", Optional(Positive), "", "", ""},
+		{"optional value", Optional(Positive), -1.0, CodeNotPositive, ""},
+		{"optional without rule", Optional(nil), 1.0, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validation(tt.value)
+			if code := ErrorCode(err); code != tt.wantCode || (tt.wantCode == "") != (err == nil) {
+				t.Fatalf("validation() error = %v (code %q), want code %q", err, code, tt.wantCode)
+			}
+			if tt.wantMsg != "" && !strings.HasPrefix(err.Error(), tt.wantMsg) {
+				t.Errorf("validation() error = %q, want one starting with %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestOrJoinsDistinctMessages(t *testing.T) {
+	err := Or(OneOf("a"), OneOf("a"))("c")
+	if want := "value must be one of [a]"; err == nil || err.Error() != want {
+		t.Errorf("Or() error = %v, want %q", err, want)
+	}
+}
+
+func TestOptionalFieldsMayBeMissing(t *testing.T) {
+	tests := []struct {
+		name        string
+		validations []func(interface{}) error
+		wantCode    string
+	}{
+		{"optional", []func(interface{}) error{Optional(NotEmpty), Optional(nil)}, ""},
+		{"required", []func(interface{}) error{NotEmpty}, CodeRequired},
+		{"mixed", []func(interface{}) error{Optional(NotEmpty), NotEmpty}, CodeRequired},
+		{"no validations", []func(interface{}) error{}, CodeRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{Validations: map[string][]func(interface{}) error{"email": tt.validations}}
+			if code := ErrorCode(s.Validate(map[string]interface{}{})); code != tt.wantCode {
+				t.Errorf("Validate() code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
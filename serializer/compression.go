@@ -0,0 +1,158 @@
+package serializer
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compression identifies a compression algorithm.
+type Compression string
+
+// Gzip and Deflate are built in; other algorithms can be added with RegisterCompressor. Deflate
+// writes raw DEFLATE streams (RFC 1951), without the zlib header.
+const (
+	CompressionNone    Compression = ""
+	CompressionGzip    Compression = "gzip"
+	CompressionDeflate Compression = "deflate"
+)
+
+// MaxDecompressedSize is the largest payload the built-in Compressors decompress, so small
+// hostile inputs can't expand into huge allocations.
+var MaxDecompressedSize int64 = 64 << 20
+
+// Compressor compresses and decompresses payloads. Implementations must be safe for concurrent use.
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[Compression]Compressor{
+		CompressionGzip:    gzipCompressor{},
+		CompressionDeflate: deflateCompressor{},
+	}
+)
+
+// RegisterCompressor makes a Compressor available under the given name, replacing any previous one,
+// built-in ones included. It panics if compressor is nil or compression is CompressionNone, so that
+// a misconfiguration fails at startup rather than on the first payload.
+func RegisterCompressor(compression Compression, compressor Compressor) {
+	if compression == CompressionNone {
+		panic("serializer: RegisterCompressor with CompressionNone")
+	}
+	if compressor == nil {
+		panic(fmt.Sprintf("serializer: RegisterCompressor of a nil Compressor for '%s'", compression))
+	}
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[compression] = compressor
+}
+
+// compressorFor returns the Compressor registered under compression.
+func compressorFor(compression Compression) (Compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	compressor, ok := compressors[compression]
+	if !ok {
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported compression '%s'", compression)}
+	}
+	return compressor, nil
+}
+
+// SerializeCompressed serializes data, encodes it in the given format and compresses it. With
+// CompressionNone it is the same as SerializeAs.
+func (s *BaseSerializer) SerializeCompressed(data interface{}, format Format, compression Compression) ([]byte, error) {
+	encoded, err := s.SerializeAsWithContext(context.Background(), data, format)
+	if err != nil || compression == CompressionNone {
+		return encoded, err
+	}
+	compressor, err := compressorFor(compression)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := compressor.Compress(encoded)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to compress with %s: %v", compression, err)}
+	}
+	return compressed, nil
+}
+
+// DeserializeCompressed decompresses data written by SerializeCompressed and deserializes it into out.
+func (s *BaseSerializer) DeserializeCompressed(data []byte, format Format, compression Compression, out interface{}) error {
+	if compression != CompressionNone {
+		compressor, err := compressorFor(compression)
+		if err != nil {
+			return err
+		}
+		if data, err = compressor.Decompress(data); err != nil {
+			return &SerializationError{Message: fmt.Sprintf("failed to decompress with %s: %v", compression, err)}
+		}
+	}
+	return s.DeserializeAs(data, format, out)
+}
+
+// gzipCompressor is the built-in gzip Compressor.
+type gzipCompressor struct{}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readLimited(r)
+}
+
+// deflateCompressor is the built-in deflate Compressor.
+type deflateCompressor struct{}
+
+func (deflateCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (deflateCompressor) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return readLimited(r)
+}
+
+// readLimited reads r to the end, failing when it holds more than MaxDecompressedSize bytes.
+func readLimited(r io.Reader) ([]byte, error) {
+	decompressed, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decompressed)) > MaxDecompressedSize {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", MaxDecompressedSize)
+	}
+	return decompressed, nil
+}
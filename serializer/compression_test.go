@@ -0,0 +1,87 @@
+package serializer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	s := &BaseSerializer{}
+	in := patient{Name: strings.Repeat("Ana ", 100), SSN: "123-45-6789", Age: 42}
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionDeflate} {
+		t.Run(string(compression), func(t *testing.T) {
+			blob, err := s.SerializeCompressed(in, FormatJSON, compression)
+			if err != nil {
+				t.Fatalf("SerializeCompressed() error = %v", err)
+			}
+			if compression != CompressionNone && len(blob) >= len(in.Name) {
+				t.Errorf("SerializeCompressed() wrote %d bytes, want fewer than %d", len(blob), len(in.Name))
+			}
+			var out patient
+			if err := s.DeserializeCompressed(blob, FormatJSON, compression, &out); err != nil {
+				t.Fatalf("DeserializeCompressed() error = %v", err)
+			}
+			if out != in {
+				t.Errorf("DeserializeCompressed() = %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	defer func(size int64) { MaxDecompressedSize = size }(MaxDecompressedSize)
+	payload := bytes.Repeat([]byte("a"), 4096)
+	for _, compression := range []Compression{CompressionGzip, CompressionDeflate} {
+		t.Run(string(compression), func(t *testing.T) {
+			compressor, err := compressorFor(compression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compressed, err := compressor.Compress(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			MaxDecompressedSize = int64(len(payload))
+			if _, err := compressor.Decompress(compressed); err != nil {
+				t.Errorf("Decompress() at the limit error = %v", err)
+			}
+			MaxDecompressedSize = int64(len(payload)) - 1
+			if _, err := compressor.Decompress(compressed); err == nil {
+				t.Error("Decompress() over the limit error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestCompressionErrors(t *testing.T) {
+	s := &BaseSerializer{}
+	if _, err := s.SerializeCompressed(patient{}, FormatJSON, "lzma"); err == nil {
+		t.Error("SerializeCompressed() with an unknown compression error = nil, want an error")
+	}
+	for _, compression := range []Compression{CompressionGzip, CompressionDeflate} {
+		if err := s.DeserializeCompressed([]byte("not compressed"), FormatJSON, compression, &patient{}); err == nil {
+			t.Errorf("DeserializeCompressed() of garbage with %s error = nil, want an error", compression)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		compression Compression
+		compressor  Compressor
+	}{
+		{"none", CompressionNone, gzipCompressor{}},
+		{"nil compressor", "lz4", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterCompressor() didn't panic")
+				}
+			}()
+			RegisterCompressor(tt.compression, tt.compressor)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// computeFields adds the ComputedFields of data to its serialized result. Computed values are
+// converted like struct fields, so e.g. times become RFC 3339 strings.
+func (s *BaseSerializer) computeFields(data interface{}, result map[string]interface{}, opts *encodeOptions) error {
+	for field, compute := range s.ComputedFields {
+		value, err := compute(data)
+		if err != nil {
+			code := ErrorCode(err)
+			if code == "" {
+				code = CodeComputeFailed
+			}
+			return &TransformationError{Field: field, Message: err.Error(), Code: code, Err: err}
+		}
+		converted, err := toJSONValueWith(reflect.ValueOf(value), opts)
+		if err != nil {
+			return &SerializationError{Message: fmt.Sprintf("failed to serialize computed field '%s': %v", field, err)}
+		}
+		result[field] = converted
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputedFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		compute  func(data interface{}) (interface{}, error)
+		want     interface{}
+		wantCode string
+	}{
+		{"from the data", func(data interface{}) (interface{}, error) { return data.(account).Name + "@example.com", nil }, "ana@example.com", ""},
+		{"time as RFC 3339", func(interface{}) (interface{}, error) { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), nil }, "2024-05-01T12:00:00Z", ""},
+		{"struct as object", func(interface{}) (interface{}, error) { return account{ID: 1}, nil }, map[string]interface{}{"id": 1.0, "name": "", "role": ""}, ""},
+		{"error", func(interface{}) (interface{}, error) { return nil, errors.New("boom") }, nil, CodeComputeFailed},
+		{"coded error", func(interface{}) (interface{}, error) { return nil, &CodedError{Code: "no_email", Message: "no email"} }, nil, "no_email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{ComputedFields: map[string]func(data interface{}) (interface{}, error){"email": tt.compute}}
+			got, err := s.Serialize(account{ID: 7, Name: "ana"})
+			if tt.wantCode != "" {
+				var transformationErr *TransformationError
+				if !errors.As(err, &transformationErr) || transformationErr.Field != "email" || ErrorCode(err) != tt.wantCode {
+					t.Errorf("Serialize() error = %v, want a TransformationError of 'email' with code %q", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got["email"], tt.want) {
+				t.Errorf("email = %#v, want %#v", got["email"], tt.want)
+			}
+		})
+	}
+}
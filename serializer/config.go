@@ -0,0 +1,439 @@
+package serializer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidatorFactory builds a validation from the parameters given to a named validator in a
+// schema or configuration file, e.g. {"value": 3} for {name: minLength, value: 3}.
+type ValidatorFactory func(params map[string]interface{}) (func(interface{}) error, error)
+
+var (
+	validatorsMu sync.RWMutex
+	// validatorsVersion counts the calls to RegisterValidator, so that the caches of validations
+	// built from validators (rule strings and validate tags) can tell when they are stale.
+	validatorsVersion uint64
+	validators        = map[string]ValidatorFactory{
+		"notEmpty":  staticValidator(NotEmpty),
+		"positive":  staticValidator(Positive),
+		"email":     staticValidator(ValidEmail),
+		"password":  staticValidator(ValidPassword),
+		"oneOf":     oneOfValidator,
+		"minLength": lengthValidator(CodeMinLength, "min", "at least", func(n, limit int) bool { return n >= limit }),
+		"maxLength": lengthValidator(CodeMaxLength, "max", "at most", func(n, limit int) bool { return n <= limit }),
+		"min":       boundValidator(CodeMinValue, CodeMinLength, "min", "at least", func(n, limit float64) bool { return n >= limit }),
+		"max":       boundValidator(CodeMaxValue, CodeMaxLength, "max", "at most", func(n, limit float64) bool { return n <= limit }),
+		"pattern":   patternValidator,
+	}
+)
+
+// RegisterValidator makes a validator available by name in schema and configuration files,
+// replacing any previous one. The built-in validators are notEmpty, positive, email, password,
+// oneOf (values), minLength and maxLength (value, counting characters or list items), min and
+// max (value, compared with numbers or with the length of strings and lists) and pattern (value,
+// a regular expression). Validators can also be used in the rule strings of ParseRules.
+// Registering a validator invalidates the validations compiled from the rule strings of Rules and
+// from validate tags, which are built again on their next use.
+func RegisterValidator(name string, factory ValidatorFactory) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = factory
+	validatorsVersion++
+}
+
+// currentValidatorsVersion returns the number of calls to RegisterValidator so far.
+func currentValidatorsVersion() uint64 {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	return validatorsVersion
+}
+
+// configOptions are the top-level options of a configuration file.
+var configOptions = []string{"fields", "exclude", "order", "keyNaming", "coerce", "weaklyTyped", "useNumber", "rejectReadOnly", "maxDepth", "deterministic", "duplicateKeys"}
+
+// configFieldOptions are the options of configuration file fields, besides those of schema files.
+var configFieldOptions = []string{"alias", "when", "deprecated"}
+
+// namingStrategies are the values of the keyNaming option.
+var namingStrategies = map[string]NamingStrategy{
+	"":           NamingDefault,
+	"snake_case": NamingSnakeCase,
+	"camelCase":  NamingCamelCase,
+	"PascalCase": NamingPascalCase,
+	"kebab-case": NamingKebabCase,
+}
+
+// duplicateKeyPolicies are the values of the duplicateKeys option.
+var duplicateKeyPolicies = map[string]DuplicateKeyPolicy{
+	"":          DuplicateKeysLastWins,
+	"lastWins":  DuplicateKeysLastWins,
+	"firstWins": DuplicateKeysFirstWins,
+	"error":     DuplicateKeysError,
+}
+
+// LoadSerializer creates a serializer from a configuration file (see ParseSerializer), in JSON or
+// YAML according to its extension.
+func LoadSerializer(path string) (*BaseSerializer, error) {
+	format := FormatFromExtension(path)
+	if format != FormatJSON && format != FormatYAML {
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported configuration file '%s': expected a .json, .yaml or .yml file", path)}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to read configuration file: %v", err), Err: err}
+	}
+	return ParseSerializer(data, format)
+}
+
+// ParseSerializer creates a serializer from a configuration file, so output shapes and rules can
+// change without recompiling:
+//
+//	keyNaming: camelCase
+//	exclude: [internal_notes]
+//	fields:
+//	  id:       {type: int, readOnly: true}
+//	  name:     {type: string, required: true, alias: fullName}
+//	  password: {type: string, writeOnly: true, validators: [password]}
+//	  plan:     {type: enum, values: [free, premium]}
+//	  discount: {type: float, when: {field: plan, equals: premium}}
+//	  fax:      {type: string, deprecated: "use email instead"}
+//
+// Fields take the definitions of schema files (see ParseSchema) plus alias, which renames the
+// field in the output, deprecated, a deprecation message, and when, which includes the field only
+// when a condition on another serialized field holds: {field: f, equals: v}, {field: f,
+// notEquals: v}, {field: f, in: [v, ...]} or {field: f, exists: true}. A list of conditions must
+// all hold. The other options, such as exclude and order, set the BaseSerializer fields of the
+// same name; keyNaming is one of snake_case, camelCase, PascalCase and kebab-case, and
+// duplicateKeys one of lastWins, firstWins and error. The serializer can be configured further
+// in code.
+func ParseSerializer(data []byte, format Format) (*BaseSerializer, error) {
+	decoder := &BaseSerializer{DuplicateKeys: DuplicateKeysError}
+	var config map[string]interface{}
+	var err error
+	switch format {
+	case FormatJSON:
+		config, err = decoder.DecodeJSON(data)
+	case FormatYAML:
+		config, err = decoder.DecodeYAML(data)
+	default:
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported configuration format '%s'", format)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range objectKeys(config) {
+		if !containsField(configOptions, key) {
+			return nil, configError(fmt.Sprintf("unknown option '%s'", key))
+		}
+	}
+
+	fields, ok := config["fields"].(map[string]interface{})
+	if _, exists := config["fields"]; exists && !ok {
+		return nil, configError("option 'fields' must be an object")
+	}
+	schema := make(map[string]Field, len(fields))
+	for name, definition := range fields {
+		field, err := parseSchemaField(name, definition, configFieldOptions)
+		if err != nil {
+			return nil, err
+		}
+		schema[name] = field
+	}
+	s := NewSchemaSerializer(schema)
+	for _, name := range s.Fields {
+		if err := configureField(s, name, fields[name].(map[string]interface{})); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &specReader{path: "", spec: config}
+	s.Coerce = r.bool("coerce")
+	s.WeaklyTyped = r.bool("weaklyTyped")
+	s.UseNumber = r.bool("useNumber")
+	s.RejectReadOnly = r.bool("rejectReadOnly")
+	s.Deterministic = r.bool("deterministic")
+	if n := r.int("maxDepth"); n != nil {
+		s.MaxDepth = int(*n)
+	}
+	naming, ok := namingStrategies[r.string("keyNaming")]
+	if !ok {
+		return nil, configError(fmt.Sprintf("unknown keyNaming '%v'", config["keyNaming"]))
+	}
+	s.KeyNaming = naming
+	policy, ok := duplicateKeyPolicies[r.string("duplicateKeys")]
+	if !ok {
+		return nil, configError(fmt.Sprintf("unknown duplicateKeys '%v'", config["duplicateKeys"]))
+	}
+	s.DuplicateKeys = policy
+	if exclude, exists := config["exclude"]; exists {
+		if s.ExcludedFields, ok = stringList(exclude); !ok {
+			return nil, configError("option 'exclude' must be a list of strings")
+		}
+	}
+	if order, exists := config["order"]; exists {
+		if s.Order, ok = stringList(order); !ok {
+			return nil, configError("option 'order' must be a list of strings")
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return s, nil
+}
+
+// configureField applies the serializer-level options of a configuration field.
+func configureField(s *BaseSerializer, name string, spec map[string]interface{}) error {
+	r := &specReader{path: name, spec: spec}
+	if alias := r.string("alias"); alias != "" {
+		if s.Aliases == nil {
+			s.Aliases = make(map[string]string)
+		}
+		s.Aliases[name] = alias
+	}
+	if message := r.string("deprecated"); message != "" {
+		if s.DeprecatedFields == nil {
+			s.DeprecatedFields = make(map[string]string)
+		}
+		s.DeprecatedFields[name] = message
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	if when, ok := spec["when"]; ok {
+		condition, err := parseCondition(name, when)
+		if err != nil {
+			return err
+		}
+		if s.ConditionalFields == nil {
+			s.ConditionalFields = make(map[string]func(map[string]interface{}) bool)
+		}
+		s.ConditionalFields[name] = condition
+	}
+	return nil
+}
+
+// parseCondition converts the when option of the field at path into a conditional field.
+func parseCondition(path string, definition interface{}) (func(map[string]interface{}) bool, error) {
+	definitions, ok := definition.([]interface{})
+	if !ok {
+		definitions = []interface{}{definition}
+	}
+
+	conditions := make([]func(map[string]interface{}) bool, len(definitions))
+	for i, definition := range definitions {
+		spec, ok := definition.(map[string]interface{})
+		field, _ := spec["field"].(string)
+		if !ok || field == "" || len(spec) != 2 {
+			return nil, schemaFileError(path, "condition needs a field and one of equals, notEquals, in and exists")
+		}
+
+		switch {
+		case spec["equals"] != nil:
+			expected := spec["equals"]
+			conditions[i] = func(data map[string]interface{}) bool { return sameEnumValue(data[field], expected) }
+		case spec["notEquals"] != nil:
+			expected := spec["notEquals"]
+			conditions[i] = func(data map[string]interface{}) bool { return !sameEnumValue(data[field], expected) }
+		case spec["in"] != nil:
+			allowed, ok := spec["in"].([]interface{})
+			if !ok {
+				return nil, schemaFileError(path, "condition option 'in' must be a list")
+			}
+			conditions[i] = func(data map[string]interface{}) bool { return EnumField{Values: allowed}.Validate(data[field]) == nil }
+		case spec["exists"] != nil:
+			exists, ok := spec["exists"].(bool)
+			if !ok {
+				return nil, schemaFileError(path, "condition option 'exists' must be a boolean")
+			}
+			conditions[i] = func(data map[string]interface{}) bool { return (data[field] != nil) == exists }
+		default:
+			return nil, schemaFileError(path, "condition needs a field and one of equals, notEquals, in and exists")
+		}
+	}
+
+	return func(data map[string]interface{}) bool {
+		for _, condition := range conditions {
+			if !condition(data) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// parseValidators converts the validators option of the field at path.
+func parseValidators(path string, definition interface{}) ([]func(interface{}) error, error) {
+	definitions, ok := definition.([]interface{})
+	if !ok {
+		return nil, schemaFileError(path, "option 'validators' must be a list")
+	}
+
+	validations := make([]func(interface{}) error, 0, len(definitions))
+	for _, definition := range definitions {
+		var name string
+		var severity interface{}
+		params := map[string]interface{}{}
+		switch v := definition.(type) {
+		case string:
+			name = v
+		case map[string]interface{}:
+			name, _ = v["name"].(string)
+			severity = v["severity"]
+			for key, value := range v {
+				if key != "name" && key != "severity" {
+					params[key] = value
+				}
+			}
+		}
+
+		validatorsMu.RLock()
+		factory, ok := validators[name]
+		validatorsMu.RUnlock()
+		if !ok {
+			return nil, schemaFileError(path, fmt.Sprintf("unknown validator '%v'", definition))
+		}
+		validation, err := factory(params)
+		if err != nil {
+			return nil, schemaFileError(path, fmt.Sprintf("validator '%s': %v", name, err))
+		}
+		switch severity {
+		case nil, "error":
+		case "warning":
+			validation = AsWarning(validation)
+		default:
+			return nil, schemaFileError(path, fmt.Sprintf("validator '%s': severity must be error or warning", name))
+		}
+		validations = append(validations, validation)
+	}
+	return validations, nil
+}
+
+// staticValidator returns a factory of a validator without parameters.
+func staticValidator(validation func(interface{}) error) ValidatorFactory {
+	return func(params map[string]interface{}) (func(interface{}) error, error) {
+		if len(params) > 0 {
+			return nil, fmt.Errorf("takes no parameters")
+		}
+		return validation, nil
+	}
+}
+
+// oneOfValidator builds a OneOf validation from its values parameter.
+func oneOfValidator(params map[string]interface{}) (func(interface{}) error, error) {
+	values, ok := params["values"].([]interface{})
+	if !ok || len(params) != 1 {
+		return nil, fmt.Errorf("needs a list of values")
+	}
+	return OneOf(values...), nil
+}
+
+// lengthValidator returns a factory of validators comparing the length of strings and lists
+// with their value parameter, which their errors report as the parameter named param.
+func lengthValidator(code, param, relation string, ok func(n, limit int) bool) ValidatorFactory {
+	return func(params map[string]interface{}) (func(interface{}) error, error) {
+		limit, err := numberParam(params)
+		if err != nil {
+			return nil, err
+		}
+		return func(value interface{}) error {
+			var n int
+			switch v := value.(type) {
+			case string:
+				n = utf8.RuneCountInString(v)
+			case []interface{}:
+				n = len(v)
+			default:
+				return NewCodedError(CodeInvalidType, "value is not a string or a list")
+			}
+			if !ok(n, int(limit)) {
+				return NewCodedErrorWithParams(code, fmt.Sprintf("length must be %s %d", relation, int(limit)), Params{param: int(limit)})
+			}
+			return nil
+		}, nil
+	}
+}
+
+// boundValidator returns a factory of validators comparing numbers with their value parameter.
+// The length of strings and lists is compared instead, as by lengthValidator with lengthCode.
+func boundValidator(code, lengthCode, param, relation string, ok func(n, limit float64) bool) ValidatorFactory {
+	return func(params map[string]interface{}) (func(interface{}) error, error) {
+		limit, err := numberParam(params)
+		if err != nil {
+			return nil, err
+		}
+		length, err := lengthValidator(lengthCode, param, relation, func(n, limit int) bool { return ok(float64(n), float64(limit)) })(params)
+		if err != nil {
+			return nil, err
+		}
+		return func(value interface{}) error {
+			switch value.(type) {
+			case string, []interface{}:
+				return length(value)
+			}
+			n, isNumber := numberValue(value)
+			if !isNumber {
+				return NewCodedError(CodeInvalidType, "value is not a number")
+			}
+			if !ok(n, limit) {
+				return NewCodedErrorWithParams(code, fmt.Sprintf("value must be %s %v", relation, limit), Params{param: limit})
+			}
+			return nil
+		}, nil
+	}
+}
+
+// patternValidator builds a validation matching strings against its value parameter.
+func patternValidator(params map[string]interface{}) (func(interface{}) error, error) {
+	pattern, ok := params["value"].(string)
+	if !ok || len(params) != 1 {
+		return nil, fmt.Errorf("needs a regular expression value")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return func(value interface{}) error {
+		str, ok := value.(string)
+		if !ok {
+			return NewCodedError(CodeInvalidType, "value is not a string")
+		}
+		if !re.MatchString(str) {
+			return NewCodedError(CodeInvalid, fmt.Sprintf("value must match %s", pattern))
+		}
+		return nil
+	}, nil
+}
+
+// numberParam returns the numeric value parameter of a validator.
+func numberParam(params map[string]interface{}) (float64, error) {
+	limit, ok := numberValue(params["value"])
+	if !ok || len(params) != 1 {
+		return 0, fmt.Errorf("needs a numeric value")
+	}
+	return limit, nil
+}
+
+// stringList converts a decoded list of strings.
+func stringList(value interface{}) ([]string, bool) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	strs := make([]string, len(items))
+	for i, item := range items {
+		if strs[i], ok = item.(string); !ok {
+			return nil, false
+		}
+	}
+	return strs, true
+}
+
+// configError reports an invalid configuration file.
+func configError(message string) error {
+	return &SerializationError{Message: fmt.Sprintf("invalid serializer configuration: %s", message)}
+}
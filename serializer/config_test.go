@@ -0,0 +1,174 @@
+package serializer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const serializerYAML = `
+keyNaming: camelCase
+exclude: [internal_notes]
+order: [id]
+coerce: true
+maxDepth: 4
+duplicateKeys: firstWins
+fields:
+  id:             {type: int, readOnly: true}
+  full_name:      {type: string, required: true, alias: name}
+  plan:           {type: enum, values: [free, premium]}
+  discount:       {type: float, when: {field: plan, equals: premium}}
+  trial:          {type: bool, when: [{field: plan, in: [free]}, {field: discount, exists: false}]}
+  fax:            {type: string, deprecated: "use email instead"}
+  internal_notes: {type: string}
+`
+
+func TestParseSerializer(t *testing.T) {
+	s, err := ParseSerializer([]byte(serializerYAML), FormatYAML)
+	if err != nil {
+		t.Fatalf("ParseSerializer() error = %v", err)
+	}
+	if s.KeyNaming != NamingCamelCase || !s.Coerce || s.MaxDepth != 4 || s.DuplicateKeys != DuplicateKeysFirstWins ||
+		!reflect.DeepEqual(s.ExcludedFields, []string{"internal_notes"}) || !reflect.DeepEqual(s.Order, []string{"id"}) {
+		t.Errorf("ParseSerializer() options = %+v", s)
+	}
+	if s.Aliases["full_name"] != "name" || s.DeprecatedFields["fax"] != "use email instead" {
+		t.Errorf("ParseSerializer() aliases = %v, deprecations = %v", s.Aliases, s.DeprecatedFields)
+	}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want map[string]interface{}
+	}{
+		{"premium", map[string]interface{}{"id": 1, "full_name": "ana", "plan": "premium", "discount": 0.5, "trial": true, "internal_notes": "x"},
+			map[string]interface{}{"id": 1.0, "name": "ana", "plan": "premium", "discount": 0.5, "trial": nil, "fax": nil}},
+		{"free", map[string]interface{}{"id": 2, "full_name": "bo", "plan": "free", "discount": nil, "trial": true},
+			map[string]interface{}{"id": 2.0, "name": "bo", "plan": "free", "discount": nil, "trial": true, "fax": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Serialize(tt.data)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSerializerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"unknown option", `fieldz: {}`, "invalid serializer configuration: unknown option 'fieldz'"},
+		{"fields not an object", `fields: [id]`, "option 'fields' must be an object"},
+		{"wrong option type", `coerce: "yes"`, "option 'coerce' must be a boolean"},
+		{"unknown naming", `keyNaming: SCREAMING`, "unknown keyNaming 'SCREAMING'"},
+		{"unknown duplicate policy", `duplicateKeys: merge`, "unknown duplicateKeys 'merge'"},
+		{"exclude not a list", `exclude: notes`, "option 'exclude' must be a list of strings"},
+		{"order not strings", `order: [1]`, "option 'order' must be a list of strings"},
+		{"unknown field option", `fields: {id: {type: int, label: x}}`, "unknown option 'label' for type int"},
+		{"alias not a string", `fields: {id: {alias: 1}}`, "invalid schema field 'id': option 'alias' must be a string"},
+		{"condition without field", `fields: {id: {when: {equals: 1}}}`, "condition needs a field"},
+		{"condition with two tests", `fields: {id: {when: {field: a, equals: 1, exists: true}}}`, "condition needs a field"},
+		{"condition in not a list", `fields: {id: {when: {field: a, in: 1}}}`, "condition option 'in' must be a list"},
+		{"condition exists not a boolean", `fields: {id: {when: {field: a, exists: 1}}}`, "condition option 'exists' must be a boolean"},
+		{"duplicate option", "coerce: true\ncoerce: false\n", "duplicate key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSerializer([]byte(tt.data), FormatYAML); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSerializer() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := ParseSerializer([]byte(`{}`), FormatGob); err == nil {
+		t.Errorf("ParseSerializer() of gob error = nil")
+	}
+}
+
+func TestLoadSerializer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users.yml")
+	if err := os.WriteFile(path, []byte("fields:\n  id: {type: int}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSerializer(path)
+	if err != nil || !reflect.DeepEqual(s.Fields, []string{"id"}) {
+		t.Errorf("LoadSerializer() = %+v, %v", s, err)
+	}
+	if _, err := LoadSerializer(filepath.Join(dir, "users.xml")); err == nil || !strings.Contains(err.Error(), "unsupported configuration file") {
+		t.Errorf("LoadSerializer() of an .xml file error = %v", err)
+	}
+	if _, err := LoadSerializer(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to read configuration file") {
+		t.Errorf("LoadSerializer() of a missing file error = %v", err)
+	}
+}
+
+func TestBuiltinValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		value    interface{}
+		wantCode string
+	}{
+		{"notEmpty", nil, "", CodeEmpty},
+		{"positive", nil, 1, ""},
+		{"email", nil, "ana", CodeInvalidEmail},
+		{"password", nil, "Secret1!", ""},
+		{"oneOf", map[string]interface{}{"values": []interface{}{"a", "b"}}, "c", CodeNotAllowed},
+		{"minLength", map[string]interface{}{"value": 2}, "é", CodeMinLength},
+		{"maxLength", map[string]interface{}{"value": 1}, []interface{}{1, 2}, CodeMaxLength},
+		{"maxLength", map[string]interface{}{"value": 1}, 5, CodeInvalidType},
+		{"min", map[string]interface{}{"value": 2.5}, 2, CodeMinValue},
+		{"min", map[string]interface{}{"value": 2}, "ab", ""},
+		{"max", map[string]interface{}{"value": 2}, true, CodeInvalidType},
+		{"pattern", map[string]interface{}{"value": "^a+$"}, "aab", CodeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validation, err := validators[tt.name](tt.params)
+			if err != nil {
+				t.Fatalf("%s(%v) error = %v", tt.name, tt.params, err)
+			}
+			got := validation(tt.value)
+			if tt.wantCode == "" {
+				if got != nil {
+					t.Errorf("%s of %v error = %v, want nil", tt.name, tt.value, got)
+				}
+				return
+			}
+			if ErrorCode(got) != tt.wantCode {
+				t.Errorf("%s of %v error = %v, want %s", tt.name, tt.value, got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestBuiltinValidatorParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"notEmpty", map[string]interface{}{"value": 1}},
+		{"oneOf", map[string]interface{}{"values": "a"}},
+		{"minLength", map[string]interface{}{"value": "2"}},
+		{"max", map[string]interface{}{"value": 2, "extra": 1}},
+		{"pattern", map[string]interface{}{"value": "("}},
+		{"pattern", map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := validators[tt.name](tt.params); err == nil {
+				t.Errorf("%s(%v) error = nil, want an invalid parameter error", tt.name, tt.params)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Constraint checks several fields of the input together, such as "exactly one of email and
+// phone" or the uniqueness of a combination of fields. Check receives the whole input; its
+// failures are reported as a *ConstraintError naming Fields, with the code of the error
+// returned, or CodeInvalid.
+type Constraint struct {
+	Fields []string
+	Check  func(ctx context.Context, data map[string]interface{}) error
+}
+
+// ExactlyOneOf returns a constraint that requires exactly one of fields to be set. Fields that
+// are missing, null or empty strings are not set, as with Optional.
+func ExactlyOneOf(fields ...string) Constraint {
+	return Constraint{Fields: fields, Check: func(ctx context.Context, data map[string]interface{}) error {
+		if n := countSet(data, fields); n != 1 {
+			return NewCodedErrorWithParams(CodeExactlyOne, fmt.Sprintf("exactly one of %s must be set", quoteFields(fields)), Params{"fields": fields})
+		}
+		return nil
+	}}
+}
+
+// AtLeastOneOf returns a constraint that requires at least one of fields to be set.
+func AtLeastOneOf(fields ...string) Constraint {
+	return Constraint{Fields: fields, Check: func(ctx context.Context, data map[string]interface{}) error {
+		if countSet(data, fields) == 0 {
+			return NewCodedErrorWithParams(CodeAtLeastOne, fmt.Sprintf("at least one of %s must be set", quoteFields(fields)), Params{"fields": fields})
+		}
+		return nil
+	}}
+}
+
+// AtMostOneOf returns a constraint that accepts at most one of fields being set, for mutually
+// exclusive fields.
+func AtMostOneOf(fields ...string) Constraint {
+	return Constraint{Fields: fields, Check: func(ctx context.Context, data map[string]interface{}) error {
+		if countSet(data, fields) > 1 {
+			return NewCodedErrorWithParams(CodeAtMostOne, fmt.Sprintf("at most one of %s may be set", quoteFields(fields)), Params{"fields": fields})
+		}
+		return nil
+	}}
+}
+
+// UniqueTogether returns a constraint that rejects input whose combination of fields already
+// exists, like a unique index on several columns. exists receives the values of fields, keyed by
+// field, and reports whether a record has them, e.g. with a database query; its errors fail the
+// validation unchanged. The check is skipped when some of the fields are not set.
+func UniqueTogether(exists func(ctx context.Context, values map[string]interface{}) (bool, error), fields ...string) Constraint {
+	return Constraint{Fields: fields, Check: func(ctx context.Context, data map[string]interface{}) error {
+		if countSet(data, fields) < len(fields) {
+			return nil
+		}
+		values := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			values[field] = data[field]
+		}
+		found, err := exists(ctx, values)
+		if err != nil {
+			return &lookupError{err: err}
+		}
+		if found {
+			return NewCodedErrorWithParams(CodeNotUnique, fmt.Sprintf("the combination of %s already exists", quoteFields(fields)), Params{"fields": fields})
+		}
+		return nil
+	}}
+}
+
+// lookupError marks the failure of the lookup of UniqueTogether, which is returned unchanged
+// rather than as a ConstraintError.
+type lookupError struct {
+	err error
+}
+
+func (e *lookupError) Error() string {
+	return e.err.Error()
+}
+
+// validateConstraints checks the Constraints in order, passing each failure to report. It
+// returns false when report stops the validation.
+func (s *BaseSerializer) validateConstraints(ctx context.Context, data map[string]interface{}, report func(error) bool) bool {
+	for _, constraint := range s.Constraints {
+		if constraint.Check == nil {
+			continue
+		}
+		err := constraint.Check(ctx, data)
+		if err == nil {
+			continue
+		}
+		if lookup, ok := err.(*lookupError); ok {
+			err = lookup.err
+		} else {
+			err = &ConstraintError{
+				Fields:  append([]string(nil), constraint.Fields...),
+				Message: s.translateError(ctx, err),
+				Code:    validationCode(err),
+				Params:  ErrorParams(err),
+				Err:     err,
+			}
+		}
+		if !report(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// countSet returns the number of fields set in data, that is present and neither null nor an
+// empty string.
+func countSet(data map[string]interface{}, fields []string) int {
+	n := 0
+	for _, field := range fields {
+		if value, exists := data[field]; exists && value != nil && value != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// quoteFields lists field names for messages, e.g. 'email', 'phone'.
+func quoteFields(fields []string) string {
+	return "'" + strings.Join(fields, "', '") + "'"
+}
@@ -0,0 +1,82 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	errLookup := errors.New("database is down")
+	existing := func(ctx context.Context, values map[string]interface{}) (bool, error) {
+		if values["tenant"] == "down" {
+			return false, errLookup
+		}
+		return values["tenant"] == "acme" && values["email"] == "a@acme.io", nil
+	}
+	tests := []struct {
+		name       string
+		constraint Constraint
+		data       map[string]interface{}
+		wantCode   string
+	}{
+		{"exactly one", ExactlyOneOf("email", "phone"), map[string]interface{}{"email": "a@b.c", "phone": ""}, ""},
+		{"exactly one of none", ExactlyOneOf("email", "phone"), map[string]interface{}{"email": nil}, CodeExactlyOne},
+		{"exactly one of two", ExactlyOneOf("email", "phone"), map[string]interface{}{"email": "a@b.c", "phone": "1"}, CodeExactlyOne},
+		{"at least one", AtLeastOneOf("email", "phone"), map[string]interface{}{"phone": 0.0}, ""},
+		{"at least one of none", AtLeastOneOf("email", "phone"), map[string]interface{}{}, CodeAtLeastOne},
+		{"at most one of none", AtMostOneOf("email", "phone"), map[string]interface{}{}, ""},
+		{"at most one of two", AtMostOneOf("email", "phone"), map[string]interface{}{"email": "a@b.c", "phone": false}, CodeAtMostOne},
+		{"unique", UniqueTogether(existing, "tenant", "email"), map[string]interface{}{"tenant": "acme", "email": "b@acme.io"}, ""},
+		{"not unique", UniqueTogether(existing, "tenant", "email"), map[string]interface{}{"tenant": "acme", "email": "a@acme.io"}, CodeNotUnique},
+		{"unique check skipped", UniqueTogether(existing, "tenant", "email"), map[string]interface{}{"tenant": "down"}, ""},
+		{"custom check", Constraint{Fields: []string{"from", "to"}, Check: func(_ context.Context, data map[string]interface{}) error {
+			if data["from"].(float64) > data["to"].(float64) {
+				return errors.New("from must not be after to")
+			}
+			return nil
+		}}, map[string]interface{}{"from": 2.0, "to": 1.0}, CodeInvalid},
+		{"no check", Constraint{Fields: []string{"a"}}, map[string]interface{}{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&BaseSerializer{Constraints: []Constraint{tt.constraint}}).Validate(tt.data)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var constraintErr *ConstraintError
+			if !errors.As(err, &constraintErr) || constraintErr.Code != tt.wantCode {
+				t.Fatalf("Validate() error = %v, want a ConstraintError with code %q", err, tt.wantCode)
+			}
+			if !reflect.DeepEqual(constraintErr.Fields, tt.constraint.Fields) {
+				t.Errorf("Fields = %q, want %q", constraintErr.Fields, tt.constraint.Fields)
+			}
+		})
+	}
+
+	s := &BaseSerializer{Constraints: []Constraint{UniqueTogether(existing, "tenant", "email")}}
+	if err := s.Validate(map[string]interface{}{"tenant": "down", "email": "a@acme.io"}); err != errLookup {
+		t.Errorf("Validate() error = %v, want the lookup error unchanged", err)
+	}
+}
+
+func TestConstraintMessages(t *testing.T) {
+	err := (&BaseSerializer{Constraints: []Constraint{ExactlyOneOf("email", "phone")}}).Validate(map[string]interface{}{})
+	if want := "exactly one of 'email', 'phone' must be set"; err == nil || err.(*ConstraintError).Message != want {
+		t.Errorf("Validate() error = %v, want the message %q", err, want)
+	}
+	if params := ErrorParams(err); !reflect.DeepEqual(params["fields"], []string{"email", "phone"}) {
+		t.Errorf("Params = %v, want the fields", params)
+	}
+
+	// Every failure is reported by ValidateAll
+	s := &BaseSerializer{Constraints: []Constraint{AtLeastOneOf("b"), AtLeastOneOf("a")}}
+	var validationErrs *ValidationErrors
+	if err := s.ValidateAll(map[string]interface{}{}); !errors.As(err, &validationErrs) || len(validationErrs.Errors) != 2 {
+		t.Errorf("ValidateAll() error = %v, want both failures", err)
+	}
+}
@@ -0,0 +1,18 @@
+package serializer
+
+import "context"
+
+// metadataKey is the context key under which the per-call Metadata is stored.
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx that carries meta.
+func ContextWithMetadata(ctx context.Context, meta *Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, meta)
+}
+
+// MetadataFromContext returns the Metadata carried by ctx, or nil if there is none.
+// Context-aware pipeline stages use it to reach the per-call metadata bag.
+func MetadataFromContext(ctx context.Context) *Metadata {
+	meta, _ := ctx.Value(metadataKey{}).(*Metadata)
+	return meta
+}
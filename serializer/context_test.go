@@ -0,0 +1,26 @@
+package serializer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMetadataFromContext(t *testing.T) {
+	meta := NewMetadata()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want *Metadata
+	}{
+		{"none", context.Background(), nil},
+		{"carried", ContextWithMetadata(context.Background(), meta), meta},
+		{"nil metadata", ContextWithMetadata(context.Background(), nil), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MetadataFromContext(tt.ctx); got != tt.want {
+				t.Errorf("MetadataFromContext() = %p, want %p", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DuplicateKeyPolicy controls how duplicate keys in incoming JSON and YAML documents are handled.
+type DuplicateKeyPolicy int
+
+const (
+	DuplicateKeysLastWins  DuplicateKeyPolicy = iota // Keep the last value (encoding/json behavior)
+	DuplicateKeysFirstWins                           // Keep the first value
+	DuplicateKeysError                               // Reject the document
+)
+
+// DeserializeJSON decodes a JSON object and deserializes it into a struct.
+func (s *BaseSerializer) DeserializeJSON(data []byte, out interface{}) error {
+	input, err := s.DecodeJSON(data)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(input, out)
+}
+
+// DeserializeYAML decodes a YAML mapping and deserializes it into a struct.
+func (s *BaseSerializer) DeserializeYAML(data []byte, out interface{}) error {
+	input, err := s.DecodeYAML(data)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(input, out)
+}
+
+// DecodeJSON parses a JSON object into a map, applying the duplicate key policy at every level.
+// With UseNumber, numbers are decoded as json.Number.
+func (s *BaseSerializer) DecodeJSON(data []byte) (map[string]interface{}, error) {
+	return s.decodeJSONFrom(bytes.NewReader(data))
+}
+
+// decodeJSONFrom parses a JSON object read from r into a map, like DecodeJSON.
+func (s *BaseSerializer) decodeJSONFrom(r io.Reader) (map[string]interface{}, error) {
+	dec := json.NewDecoder(r)
+	if s.UseNumber {
+		dec.UseNumber()
+	}
+	value, err := decodeJSONValue(dec, s.DuplicateKeys, "")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &SerializationError{Message: "failed to parse JSON: unexpected data after top-level value"}
+	}
+
+	input, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &SerializationError{Message: "failed to parse JSON: top-level value is not an object"}
+	}
+	return input, nil
+}
+
+// decodeJSONValue reads the next JSON value from dec.
+func decodeJSONValue(dec *json.Decoder, policy DuplicateKeyPolicy, path string) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to parse JSON: %v", err), Err: err}
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		object := make(map[string]interface{})
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, &SerializationError{Message: fmt.Sprintf("failed to parse JSON: %v", err), Err: err}
+			}
+			key := keyToken.(string)
+			keyPath := joinKeyPath(path, key)
+
+			value, err := decodeJSONValue(dec, policy, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			if err := setDecodedKey(object, key, value, policy, keyPath); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse JSON: %v", err), Err: err}
+		}
+		return object, nil
+	case '[':
+		array := make([]interface{}, 0)
+		for dec.More() {
+			value, err := decodeJSONValue(dec, policy, fmt.Sprintf("%s[%d]", path, len(array)))
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse JSON: %v", err), Err: err}
+		}
+		return array, nil
+	default:
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to parse JSON: unexpected delimiter '%s'", delim)}
+	}
+}
+
+// DecodeYAML parses a YAML mapping into a map, applying the duplicate key policy at every level.
+// Aliases are replaced by the value of their anchor, and merge keys ("<<") add the keys of the
+// mappings they refer to that the mapping doesn't set itself.
+func (s *BaseSerializer) DecodeYAML(data []byte) (map[string]interface{}, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to parse YAML: %v", err), Err: err}
+	}
+	if document.Kind == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	value, err := (&yamlDecoder{policy: s.DuplicateKeys}).decode(&document, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	input, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &SerializationError{Message: "failed to parse YAML: top-level value is not a mapping"}
+	}
+	return input, nil
+}
+
+// maxXMLDepth bounds the nesting of the elements DecodeXML accepts.
+const maxXMLDepth = 1000
+
+// DecodeXML parses an XML document into a map, reversing the XML written by EncodeSorted and
+// SerializeToXML: the children of the root element become keys, elements whose children are all
+// XMLItem elements become lists and repeated elements become lists of their values. XML has no types,
+// so values are strings, and empty elements are nil. Attributes are ignored, except those listed
+// in XMLAttributes, which become keys of their element's object. Namespace prefixes are dropped.
+// When XMLSchema is set, the document must conform to it.
+func (s *BaseSerializer) DecodeXML(data []byte) (map[string]interface{}, error) {
+	if s.XMLSchema != nil {
+		if err := s.XMLSchema.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, &SerializationError{Message: "failed to parse XML: no root element"}
+		}
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: %v", err), Err: err}
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		value, err := decodeXMLElement(dec, start, "", s.xmlOptions(), 0)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			token, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: %v", err), Err: err}
+			}
+			if _, ok := token.(xml.StartElement); ok {
+				return nil, &SerializationError{Message: "failed to parse XML: unexpected data after root element"}
+			}
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return v, nil
+		case nil:
+			return map[string]interface{}{}, nil
+		default:
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: root element <%s> has no child elements", start.Name.Local)}
+		}
+	}
+}
+
+// decodeXMLElement converts the content of the element whose start was just read, at the dotted
+// path of the document.
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement, path string, opts *xmlOptions, depth int) (interface{}, error) {
+	if depth > maxXMLDepth {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: elements nested deeper than %d", maxXMLDepth)}
+	}
+
+	var (
+		text   strings.Builder
+		names  []string
+		values []interface{}
+	)
+	for _, attr := range start.Attr {
+		if containsField(opts.attributes, joinKeyPath(path, attr.Name.Local)) {
+			names = append(names, attr.Name.Local)
+			values = append(values, attr.Value)
+		}
+	}
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: %v", err), Err: err}
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			childPath := path
+			if t.Name.Local != opts.item {
+				childPath = joinKeyPath(path, t.Name.Local)
+			}
+			value, err := decodeXMLElement(dec, t, childPath, opts, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, t.Name.Local)
+			values = append(values, value)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			return xmlElementValue(text.String(), names, values, opts.item), nil
+		}
+	}
+}
+
+// xmlElementValue returns the value of an element from its text and child elements, item
+// elements being list elements.
+func xmlElementValue(text string, names []string, values []interface{}, item string) interface{} {
+	if len(names) == 0 {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return text
+	}
+
+	list := true
+	for _, name := range names {
+		if name != item {
+			list = false
+			break
+		}
+	}
+	if list {
+		return values
+	}
+
+	object := make(map[string]interface{}, len(names))
+	var repeated map[string]bool // Names of the elements collected into lists
+	for i, name := range names {
+		existing, exists := object[name]
+		switch {
+		case !exists:
+			object[name] = values[i]
+		case repeated[name]:
+			object[name] = append(existing.([]interface{}), values[i])
+		default:
+			if repeated == nil {
+				repeated = make(map[string]bool)
+			}
+			repeated[name] = true
+			object[name] = []interface{}{existing, values[i]}
+		}
+	}
+	return object
+}
+
+// maxYAMLAliasNodes bounds the nodes decoded through aliases, so that documents whose aliases
+// refer to each other can't expand exponentially.
+const maxYAMLAliasNodes = 1000000
+
+// yamlDecoder converts YAML nodes into plain Go values.
+type yamlDecoder struct {
+	policy     DuplicateKeyPolicy
+	aliasNodes int // Nodes decoded through aliases
+}
+
+// decode converts node, at path, expanding aliases and merge keys ("<<: *defaults").
+func (d *yamlDecoder) decode(node *yaml.Node, path string, aliased bool) (interface{}, error) {
+	if aliased {
+		if d.aliasNodes++; d.aliasNodes > maxYAMLAliasNodes {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse YAML: aliases expand to more than %d nodes", maxYAMLAliasNodes)}
+		}
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return d.decode(node.Content[0], path, aliased)
+	case yaml.AliasNode:
+		return d.decode(node.Alias, path, true)
+	case yaml.MappingNode:
+		object := make(map[string]interface{})
+		var merges []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if keyNode := node.Content[i]; keyNode.Kind == yaml.ScalarNode && keyNode.ShortTag() == "!!merge" {
+				merges = append(merges, node.Content[i+1])
+				continue
+			}
+			var key interface{}
+			if err := node.Content[i].Decode(&key); err != nil {
+				return nil, &SerializationError{Message: fmt.Sprintf("failed to parse YAML: %v", err), Err: err}
+			}
+			keyString := fmt.Sprint(key)
+			keyPath := joinKeyPath(path, keyString)
+
+			value, err := d.decode(node.Content[i+1], keyPath, aliased)
+			if err != nil {
+				return nil, err
+			}
+			if err := setDecodedKey(object, keyString, value, d.policy, keyPath); err != nil {
+				return nil, err
+			}
+		}
+		// Keys of the mapping override merged keys, and earlier merged mappings override later ones
+		for _, merge := range merges {
+			sources := []*yaml.Node{merge}
+			if merge.Kind == yaml.SequenceNode {
+				sources = merge.Content
+			}
+			for _, source := range sources {
+				value, err := d.decode(source, path, aliased)
+				if err != nil {
+					return nil, err
+				}
+				merged, ok := value.(map[string]interface{})
+				if !ok {
+					return nil, &SerializationError{Message: fmt.Sprintf("failed to parse YAML: merge key at '%s' does not refer to a mapping", path)}
+				}
+				for key, value := range merged {
+					if _, exists := object[key]; !exists {
+						object[key] = value
+					}
+				}
+			}
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := make([]interface{}, 0, len(node.Content))
+		for i, item := range node.Content {
+			value, err := d.decode(item, fmt.Sprintf("%s[%d]", path, i), aliased)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse YAML: %v", err), Err: err}
+		}
+		return value, nil
+	}
+}
+
+// setDecodedKey stores a decoded key according to the duplicate key policy.
+func setDecodedKey(object map[string]interface{}, key string, value interface{}, policy DuplicateKeyPolicy, path string) error {
+	if _, exists := object[key]; exists {
+		switch policy {
+		case DuplicateKeysError:
+			return &ValidationError{Field: path, Value: value, Message: "duplicate key", Code: CodeDuplicateKey}
+		case DuplicateKeysFirstWins:
+			return nil
+		}
+	}
+	object[key] = value
+	return nil
+}
+
+// joinKeyPath appends a key to a dotted field path.
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
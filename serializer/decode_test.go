@@ -0,0 +1,114 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        *BaseSerializer
+		data     string
+		want     map[string]interface{}
+		wantCode string
+		wantErr  string
+	}{
+		{"object", &BaseSerializer{}, `{"id": 7, "tags": ["a"], "lead": {"name": "ana"}}`,
+			map[string]interface{}{"id": 7.0, "tags": []interface{}{"a"}, "lead": map[string]interface{}{"name": "ana"}}, "", ""},
+		{"use number", &BaseSerializer{UseNumber: true}, `{"id": 12345678901234567890}`, map[string]interface{}{"id": json.Number("12345678901234567890")}, "", ""},
+		{"last wins", &BaseSerializer{}, `{"id": 1, "id": 2}`, map[string]interface{}{"id": 2.0}, "", ""},
+		{"first wins", &BaseSerializer{DuplicateKeys: DuplicateKeysFirstWins}, `{"lead": {"id": 1, "id": 2}}`, map[string]interface{}{"lead": map[string]interface{}{"id": 1.0}}, "", ""},
+		{"duplicate key", &BaseSerializer{DuplicateKeys: DuplicateKeysError}, `{"lead": {"id": 1, "id": 2}}`, nil, CodeDuplicateKey, "lead.id"},
+		{"not an object", &BaseSerializer{}, `[1]`, nil, "", "top-level value is not an object"},
+		{"trailing data", &BaseSerializer{}, `{} {}`, nil, "", "unexpected data after top-level value"},
+		{"malformed", &BaseSerializer{}, `{"id": }`, nil, "", "failed to parse JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.DecodeJSON([]byte(tt.data))
+			checkDecoded(t, "DecodeJSON", got, err, tt.want, tt.wantCode, tt.wantErr)
+		})
+	}
+}
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        *BaseSerializer
+		data     string
+		want     map[string]interface{}
+		wantCode string
+		wantErr  string
+	}{
+		{"mapping", &BaseSerializer{}, "id: 7\ntags: [a]\n", map[string]interface{}{"id": 7, "tags": []interface{}{"a"}}, "", ""},
+		{"empty", &BaseSerializer{}, "", map[string]interface{}{}, "", ""},
+		{"aliases and merge keys", &BaseSerializer{}, "base: &base {role: user, name: x}\nlead:\n  <<: *base\n  name: ana\n",
+			map[string]interface{}{"base": map[string]interface{}{"role": "user", "name": "x"}, "lead": map[string]interface{}{"role": "user", "name": "ana"}}, "", ""},
+		{"first wins", &BaseSerializer{DuplicateKeys: DuplicateKeysFirstWins}, "id: 1\nid: 2\n", map[string]interface{}{"id": 1}, "", ""},
+		{"duplicate key", &BaseSerializer{DuplicateKeys: DuplicateKeysError}, "id: 1\nid: 2\n", nil, CodeDuplicateKey, "id"},
+		{"merge of a scalar", &BaseSerializer{}, "lead:\n  <<: 1\n", nil, "", "merge key at 'lead' does not refer to a mapping"},
+		{"not a mapping", &BaseSerializer{}, "- 1\n", nil, "", "top-level value is not a mapping"},
+		{"malformed", &BaseSerializer{}, "id: [", nil, "", "failed to parse YAML"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.DecodeYAML([]byte(tt.data))
+			checkDecoded(t, "DecodeYAML", got, err, tt.want, tt.wantCode, tt.wantErr)
+		})
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       *BaseSerializer
+		data    string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"elements", &BaseSerializer{}, `<?xml version="1.0"?><object><id>7</id><note/><tags><item>a</item><item>b</item></tags></object>`,
+			map[string]interface{}{"id": "7", "note": nil, "tags": []interface{}{"a", "b"}}, ""},
+		{"repeated elements", &BaseSerializer{}, `<object><tag>a</tag><tag>b</tag><tag>c</tag></object>`,
+			map[string]interface{}{"tag": []interface{}{"a", "b", "c"}}, ""},
+		{"attributes", &BaseSerializer{XMLAttributes: []string{"lead.id"}}, `<object version="2"><lead id="1" kind="x"><name>ana</name></lead></object>`,
+			map[string]interface{}{"lead": map[string]interface{}{"id": "1", "name": "ana"}}, ""},
+		{"item element", &BaseSerializer{XMLItem: "member"}, `<team><members><member><name>ana</name></member></members></team>`,
+			map[string]interface{}{"members": []interface{}{map[string]interface{}{"name": "ana"}}}, ""},
+		{"namespaces", &BaseSerializer{}, `<t:object xmlns:t="urn:t"><t:id>7</t:id></t:object>`, map[string]interface{}{"id": "7"}, ""},
+		{"empty root", &BaseSerializer{}, `<object/>`, map[string]interface{}{}, ""},
+		{"text root", &BaseSerializer{}, `<object>7</object>`, nil, "root element <object> has no child elements"},
+		{"no root", &BaseSerializer{}, `<?xml version="1.0"?>`, nil, "no root element"},
+		{"second root", &BaseSerializer{}, `<object/><object/>`, nil, "unexpected data after root element"},
+		{"malformed", &BaseSerializer{}, `<object><id></object>`, nil, "failed to parse XML"},
+		{"too deep", &BaseSerializer{}, strings.Repeat("<a>", maxXMLDepth+2), nil, "nested deeper than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.DecodeXML([]byte(tt.data))
+			checkDecoded(t, "DecodeXML", got, err, tt.want, "", tt.wantErr)
+		})
+	}
+}
+
+// checkDecoded compares the result of a decoding function with the wanted map, error code or
+// error message.
+func checkDecoded(t *testing.T, name string, got map[string]interface{}, err error, want map[string]interface{}, wantCode, wantErr string) {
+	t.Helper()
+	switch {
+	case wantCode != "":
+		if ErrorCode(err) != wantCode || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s() error = %v, want %s at %s", name, err, wantCode, wantErr)
+		}
+	case wantErr != "":
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s() error = %v, want %q", name, err, wantErr)
+		}
+	case err != nil:
+		t.Errorf("%s() error = %v", name, err)
+	case !reflect.DeepEqual(got, want):
+		t.Errorf("%s() = %#v, want %#v", name, got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DecodeHook converts a value of the input before Deserialize stores it into a value of type to,
+// like the decode hooks of mapstructure. from is the type of v as decoded, e.g. string for a JSON
+// string or map[string]interface{} for an object. A hook returns v unchanged when it doesn't
+// apply, and an error for values it rejects.
+type DecodeHook func(from reflect.Type, to reflect.Type, v interface{}) (interface{}, error)
+
+// runDecodeHooks passes value through each hook in turn, for a target of type to.
+func runDecodeHooks(hooks []DecodeHook, value interface{}, to reflect.Type) (interface{}, error) {
+	for _, hook := range hooks {
+		converted, err := hook(reflect.TypeOf(value), to, value)
+		if err != nil {
+			return nil, err
+		}
+		value = converted
+	}
+	return value, nil
+}
+
+// StringToTimeHook returns a DecodeHook that parses strings stored into time.Time values with the
+// first of the Go time layouts that matches, e.g. StringToTimeHook("2006-01-02", time.RFC1123).
+func StringToTimeHook(layouts ...string) DecodeHook {
+	return func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok || to != timeType {
+			return v, nil
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("parsing time %q: matches none of the layouts %q", s, layouts)
+	}
+}
+
+// TypeHook returns a DecodeHook that converts every value stored into a T with convert, e.g.
+// TypeHook(func(v interface{}) (UserID, error) { return ParseUserID(v) }) for IDs read from
+// strings or numbers. Values that are already a T are kept as they are.
+func TypeHook[T any](convert func(v interface{}) (T, error)) DecodeHook {
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	return func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		if to != target || from == target {
+			return v, nil
+		}
+		return convert(v)
+	}
+}
@@ -0,0 +1,120 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roomID string
+
+type booking struct {
+	When time.Time `json:"when"`
+	Room roomID    `json:"room"`
+}
+
+func TestStringToTimeHook(t *testing.T) {
+	hook := StringToTimeHook("2006-01-02", time.RFC1123)
+	tests := []struct {
+		name  string
+		to    reflect.Type
+		value interface{}
+		want  interface{}
+	}{
+		{"first layout", timeType, "2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"second layout", timeType, "Wed, 01 May 2024 12:30:00 UTC", time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)},
+		{"not a string", timeType, 1.0, 1.0},
+		{"not a time", reflect.TypeOf(""), "2024-05-01", "2024-05-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hook(reflect.TypeOf(tt.value), tt.to, tt.value)
+			if err != nil {
+				t.Fatalf("hook() error = %v", err)
+			}
+			if gotTime, ok := got.(time.Time); ok {
+				if !gotTime.Equal(tt.want.(time.Time)) {
+					t.Errorf("hook() = %v, want %v", got, tt.want)
+				}
+			} else if got != tt.want {
+				t.Errorf("hook() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := hook(reflect.TypeOf(""), timeType, "yesterday"); err == nil || !strings.Contains(err.Error(), "matches none of the layouts") {
+		t.Errorf("hook() error = %v, want one naming the layouts", err)
+	}
+}
+
+func TestTypeHook(t *testing.T) {
+	hook := TypeHook(func(v interface{}) (roomID, error) {
+		switch v := v.(type) {
+		case float64:
+			return roomID(fmt.Sprintf("R-%d", int(v))), nil
+		case string:
+			return roomID(v), nil
+		}
+		return "", fmt.Errorf("invalid room %v", v)
+	})
+	target := reflect.TypeOf(roomID(""))
+	tests := []struct {
+		name    string
+		to      reflect.Type
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"converted", target, 12.0, roomID("R-12"), false},
+		{"already a T", target, roomID("R-1"), roomID("R-1"), false},
+		{"other target", reflect.TypeOf(0.0), 12.0, 12.0, false},
+		{"rejected", target, true, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hook(reflect.TypeOf(tt.value), tt.to, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("hook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("hook() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserializeDecodeHooks(t *testing.T) {
+	s := &BaseSerializer{DecodeHooks: []DecodeHook{
+		StringToTimeHook("2006-01-02"),
+		TypeHook(func(v interface{}) (roomID, error) { return roomID(fmt.Sprintf("R-%v", v)), nil }),
+	}}
+	tests := []struct {
+		name    string
+		input   map[string]interface{}
+		want    booking
+		wantErr string
+	}{
+		{"converted", map[string]interface{}{"when": "2024-05-01", "room": 12.0}, booking{When: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Room: "R-12"}, ""},
+		{"rejected", map[string]interface{}{"when": "2024-05-01T12:30:00Z"}, booking{}, "matches none of the layouts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got booking
+			err := s.Deserialize(tt.input, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Deserialize() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if !got.When.Equal(tt.want.When) || got.Room != tt.want.Room {
+				t.Errorf("Deserialize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
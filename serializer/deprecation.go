@@ -0,0 +1,129 @@
+package serializer
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DeprecationsKey is the key under which deprecation notices are added to the serialized output.
+const DeprecationsKey = "_deprecations"
+
+// Deprecations returns the deprecation messages for the deprecated fields present in a serialized
+// result, including the former keys of RenamedFields.
+func (s *BaseSerializer) Deprecations(result map[string]interface{}) map[string]string {
+	deprecations := make(map[string]string)
+	for field, message := range s.deprecatedFields() {
+		if _, exists := result[field]; exists {
+			deprecations[field] = message
+		} else if key := s.convertKey(field); key != field {
+			// The result may already use the serializer's naming strategy
+			if _, exists := result[key]; exists {
+				deprecations[key] = message
+			}
+		}
+	}
+	return deprecations
+}
+
+// DeprecationWarnings returns HTTP Warning header values (RFC 7234, code 299) for the deprecated
+// fields present in a serialized result, sorted by field name.
+func (s *BaseSerializer) DeprecationWarnings(result map[string]interface{}) []string {
+	deprecations := s.Deprecations(result)
+
+	fields := make([]string, 0, len(deprecations))
+	for field := range deprecations {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	warnings := make([]string, 0, len(fields))
+	for _, field := range fields {
+		text := fmt.Sprintf("field '%s' is deprecated", field)
+		if message := deprecations[field]; message != "" {
+			text += ": " + message
+		}
+		warnings = append(warnings, warningValue(text))
+	}
+	return warnings
+}
+
+// deprecatedFields returns the messages of DeprecatedFields, and of the former keys of
+// RenamedFields, which default to pointing at the current key.
+func (s *BaseSerializer) deprecatedFields() map[string]string {
+	if len(s.RenamedFields) == 0 {
+		return s.DeprecatedFields
+	}
+	fields := make(map[string]string, len(s.DeprecatedFields)+len(s.RenamedFields))
+	for former, current := range s.RenamedFields {
+		fields[former] = fmt.Sprintf("use '%s' instead", s.convertKey(current))
+	}
+	for field, message := range s.DeprecatedFields {
+		fields[field] = message
+	}
+	return fields
+}
+
+// applyRenames writes renamed fields of a serialized result under their former keys as well.
+func (s *BaseSerializer) applyRenames(result map[string]interface{}) {
+	for former, current := range s.RenamedFields {
+		if _, exists := result[former]; exists {
+			continue
+		}
+		if value, exists := result[current]; exists {
+			result[former] = value
+		}
+	}
+}
+
+// migrateInput moves the values sent under the former keys of renamed fields to the current
+// keys, which win when both are sent, and adds a warning with the deprecated code to warnings
+// for every deprecated key of input. Keys may be named by KeyNaming. input isn't modified.
+func (s *BaseSerializer) migrateInput(input map[string]interface{}, warnings *Warnings) map[string]interface{} {
+	deprecated := s.deprecatedFields()
+	migrated, copied := input, false
+	for _, key := range objectKeys(input) {
+		field, ok := s.deprecatedKey(deprecated, key)
+		if !ok {
+			continue
+		}
+		message := "field is deprecated"
+		if deprecated[field] != "" {
+			message += ": " + deprecated[field]
+		}
+		warnings.Add(&ValidationError{Field: key, Value: input[key], Message: message, Code: CodeDeprecated, Severity: SeverityWarning})
+
+		current, renamed := s.RenamedFields[field]
+		if !renamed {
+			continue
+		}
+		if !copied {
+			migrated = make(map[string]interface{}, len(input))
+			for k, v := range input {
+				migrated[k] = v
+			}
+			copied = true
+		}
+		delete(migrated, key)
+		if _, exists := input[s.convertKey(current)]; !exists {
+			migrated[s.convertKey(current)] = input[key]
+		}
+	}
+	return migrated
+}
+
+// deprecatedKey returns the field of deprecated that an input key stands for, under its own name
+// or as named by KeyNaming.
+func (s *BaseSerializer) deprecatedKey(deprecated map[string]string, key string) (string, bool) {
+	if _, ok := deprecated[key]; ok {
+		return key, true
+	}
+	if s.KeyNaming == NamingDefault {
+		return "", false
+	}
+	for field := range deprecated {
+		if s.convertKey(field) == key {
+			return field, true
+		}
+	}
+	return "", false
+}
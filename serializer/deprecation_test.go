@@ -0,0 +1,84 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDeprecations(t *testing.T) {
+	tests := []struct {
+		name         string
+		s            *BaseSerializer
+		want         map[string]interface{}
+		wantWarnings []string
+	}{
+		{"deprecated field", &BaseSerializer{DeprecatedFields: map[string]string{"role": "use permissions", "missing": ""}, IncludeDeprecations: true},
+			map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin", DeprecationsKey: map[string]string{"role": "use permissions"}},
+			[]string{`299 - "field 'role' is deprecated: use permissions"`}},
+		{"omitted", &BaseSerializer{DeprecatedFields: map[string]string{"role": ""}, OmitDeprecated: true, IncludeDeprecations: true},
+			map[string]interface{}{"id": 7.0, "name": "ana"}, []string{}},
+		{"renamed field", &BaseSerializer{RenamedFields: map[string]string{"username": "name"}, DeprecatedFields: map[string]string{"role": ""}, IncludeDeprecations: true},
+			map[string]interface{}{"id": 7.0, "name": "ana", "username": "ana", "role": "admin", DeprecationsKey: map[string]string{"username": "use 'name' instead", "role": ""}},
+			[]string{`299 - "field 'role' is deprecated"`, `299 - "field 'username' is deprecated: use 'name' instead"`}},
+		{"renamed and converted keys", &BaseSerializer{RenamedFields: map[string]string{"userName": "name"}, KeyNaming: NamingSnakeCase, IncludeDeprecations: true},
+			map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin", "user_name": "ana", DeprecationsKey: map[string]string{"user_name": "use 'name' instead"}},
+			[]string{`299 - "field 'user_name' is deprecated: use 'name' instead"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.Serialize(account{ID: 7, Name: "ana", Role: "admin"})
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+			if warnings := tt.s.DeprecationWarnings(got); !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("DeprecationWarnings() = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestDeserializeDeprecatedFields(t *testing.T) {
+	s := &BaseSerializer{RenamedFields: map[string]string{"username": "name"}, DeprecatedFields: map[string]string{"role": "use permissions"}}
+	tests := []struct {
+		name         string
+		input        map[string]interface{}
+		want         account
+		wantWarnings []string // Fields warned about
+	}{
+		{"current keys", map[string]interface{}{"name": "ana"}, account{Name: "ana"}, nil},
+		{"former key", map[string]interface{}{"username": "ana", "role": "admin"}, account{Name: "ana", Role: "admin"}, []string{"role", "username"}},
+		{"current key wins", map[string]interface{}{"username": "old", "name": "new"}, account{Name: "new"}, []string{"username"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := &Warnings{}
+			var got account
+			if err := s.DeserializeWithContext(ContextWithWarnings(context.Background(), warnings), tt.input, &got); err != nil {
+				t.Fatalf("DeserializeWithContext() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DeserializeWithContext() = %+v, want %+v", got, tt.want)
+			}
+			var fields []string
+			for _, warning := range warnings.List() {
+				if warning.Code != CodeDeprecated || warning.Severity != SeverityWarning {
+					t.Errorf("warning %+v is not a deprecation warning", warning)
+				}
+				fields = append(fields, warning.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantWarnings) {
+				t.Errorf("warned about %q, want %q", fields, tt.wantWarnings)
+			}
+		})
+	}
+
+	// Validate checks renamed fields under their current keys
+	v := &BaseSerializer{RenamedFields: map[string]string{"username": "name"}, Rules: map[string]string{"name": "required"}}
+	if err := v.Validate(map[string]interface{}{"username": "ana"}); err != nil {
+		t.Errorf("Validate() of a former key error = %v, want nil", err)
+	}
+}
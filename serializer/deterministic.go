@@ -0,0 +1,194 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// XMLRootElement and XMLItemElement name the elements EncodeSorted writes for the top-level value
+// and for the elements of lists.
+const (
+	XMLRootElement = "object"
+	XMLItemElement = "item"
+)
+
+// sortedOptions keep numbers exact while EncodeSorted normalizes values.
+var sortedOptions = &encodeOptions{marshalers: true, numbers: true}
+
+// EncodeSorted encodes serialized output (maps, lists and scalars) in the given format with the
+// keys of every object sorted, so equal values always produce the same bytes and can be hashed,
+// signed or compared. XML documents have an XMLRootElement root, one element per key and an
+// XMLItemElement element per list element.
+func EncodeSorted(value interface{}, format Format) ([]byte, error) {
+	return EncodeOrdered(value, format, nil)
+}
+
+// EncodeOrdered encodes serialized output like EncodeSorted, except that the top-level keys
+// listed in order come first, in that order. The other keys, and the keys of nested objects,
+// are sorted.
+func EncodeOrdered(value interface{}, format Format, order []string) ([]byte, error) {
+	if format == FormatYAML || format == FormatXML {
+		// Reduce values set by transformations and hooks to maps, lists and scalars
+		normalized, err := toJSONValueWith(reflect.ValueOf(value), sortedOptions)
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to encode %s: %v", strings.ToUpper(string(format)), err)}
+		}
+		value = normalized
+	}
+
+	switch format {
+	case FormatJSON:
+		// encoding/json writes map keys in sorted order
+		encoded, err := orderedJSON(value, order)
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to encode JSON: %v", err), Err: err}
+		}
+		return encoded, nil
+	case FormatYAML:
+		node, err := sortedYAMLNode(value, order)
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to encode YAML: %v", err), Err: err}
+		}
+		encoded, err := yaml.Marshal(node)
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to encode YAML: %v", err), Err: err}
+		}
+		return encoded, nil
+	case FormatXML:
+		encoded, err := encodeXML(value, XMLRootElement, &xmlOptions{item: XMLItemElement, order: order})
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to encode XML: %v", err), Err: err}
+		}
+		return encoded, nil
+	default:
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported format '%s'", format)}
+	}
+}
+
+// objectKeys returns the keys of an object in sorted order.
+func objectKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// orderedObjectKeys returns the keys of an object listed in order, in that order, followed by
+// the others in sorted order.
+func orderedObjectKeys(object map[string]interface{}, order []string) []string {
+	if len(order) == 0 {
+		return objectKeys(object)
+	}
+	keys := make([]string, 0, len(object))
+	for _, key := range order {
+		if _, ok := object[key]; ok && !containsField(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range objectKeys(object) {
+		if !containsField(order, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// orderedJSON encodes a serialized value as JSON, writing the top-level keys in the given order.
+func orderedJSON(value interface{}, order []string) ([]byte, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok || len(order) == 0 {
+		return json.Marshal(value)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range orderedObjectKeys(object, order) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		encoded, err := json.Marshal(object[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(encoded)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// sortedYAMLNode converts a serialized value into a YAML node whose mappings have sorted keys,
+// except for the top-level keys listed in order.
+func sortedYAMLNode(value interface{}, order []string) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range orderedObjectKeys(v, order) {
+			item, err := sortedYAMLNode(v[key], nil)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, item)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, element := range v {
+			item, err := sortedYAMLNode(element, nil)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node, nil
+	case json.Number:
+		// Integers beyond int64 are still ints for YAML up to uint64, and tagged as such so they
+		// decode again
+		tag := "!!int"
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err != nil {
+			if _, err := strconv.ParseUint(v.String(), 10, 64); err != nil {
+				tag = "!!float"
+			}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	default:
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		return &node, nil
+	}
+}
+
+// validXMLName reports whether name can be used as the name of an XML element or attribute,
+// with an optional namespace prefix (e.g. "ns:order").
+func validXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+			continue
+		}
+		if i > 0 && r == ':' && !strings.Contains(name[:i], ":") && i < len(name)-1 {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package serializer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEncodeOrdered(t *testing.T) {
+	value := map[string]interface{}{
+		"b":    map[string]interface{}{"z": 1, "a": []interface{}{true, nil}},
+		"id":   json.Number("12345678901234567890"),
+		"a":    1.5,
+		"name": "x",
+	}
+	tests := []struct {
+		name   string
+		format Format
+		order  []string
+		want   string
+	}{
+		{"JSON sorted", FormatJSON, nil, `{"a":1.5,"b":{"a":[true,null],"z":1},"id":12345678901234567890,"name":"x"}`},
+		{"JSON ordered", FormatJSON, []string{"name", "id", "missing", "name"}, `{"name":"x","id":12345678901234567890,"a":1.5,"b":{"a":[true,null],"z":1}}`},
+		{"YAML sorted", FormatYAML, nil, "a: 1.5\nb:\n    a:\n        - true\n        - null\n    z: 1\nid: 12345678901234567890\nname: x\n"},
+		{"YAML ordered", FormatYAML, []string{"name"}, "name: x\na: 1.5\nb:\n    a:\n        - true\n        - null\n    z: 1\nid: 12345678901234567890\n"},
+		{"XML ordered", FormatXML, []string{"name"}, "<object>\n  <name>x</name>\n  <a>1.5</a>\n  <b>\n    <a>\n      <item>true</item>\n      <item></item>\n    </a>\n    <z>1</z>\n  </b>\n  <id>12345678901234567890</id>\n</object>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeOrdered(value, tt.format, tt.order)
+			if err != nil {
+				t.Fatalf("EncodeOrdered() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeOrdered() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeSorted(value, Format("toml")); err == nil {
+		t.Errorf("EncodeSorted() with an unsupported format error = nil, want an error")
+	}
+}
+
+func TestValidXMLName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"order", true},
+		{"_private", true},
+		{"line-item.2", true},
+		{"ns:order", true},
+		{"", false},
+		{"2nd", false},
+		{"-x", false},
+		{"a:b:c", false},
+		{"a:", false},
+		{"a b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validXMLName(tt.name); got != tt.want {
+				t.Errorf("validXMLName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
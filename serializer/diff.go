@@ -0,0 +1,65 @@
+package serializer
+
+import "reflect"
+
+// ChangeType describes how a field differs between two serialized objects.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"    // The field is only present in the new object
+	ChangeRemoved  ChangeType = "removed"  // The field is only present in the old object
+	ChangeModified ChangeType = "modified" // The field is present in both with different values
+)
+
+// Change is a difference between two serialized objects.
+type Change struct {
+	Type ChangeType  `json:"type"`
+	Old  interface{} `json:"old,omitempty"` // Serialized value in the old object; nil when added
+	New  interface{} `json:"new,omitempty"` // Serialized value in the new object; nil when removed
+}
+
+// Diff serializes old and new with s and reports the fields that differ, keyed by their path.
+// Nested objects are compared field by field with dotted paths (e.g. "address.city"); other
+// values, including lists, are compared as a whole. Fields hidden by the serializer (excluded,
+// write-only, filtered by Fields or conditions) are not compared, and values are compared in
+// their serialized form, so transformations apply to both sides.
+func (s *BaseSerializer) Diff(old, new interface{}) (map[string]Change, error) {
+	from, err := s.Serialize(old)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.Serialize(new)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	diffMaps("", from, to, changes)
+	return changes, nil
+}
+
+// diffMaps adds the changes between from and to, whose keys are prefixed with path, to changes.
+func diffMaps(path string, from, to map[string]interface{}, changes map[string]Change) {
+	for key, old := range from {
+		if _, ok := to[key]; !ok {
+			changes[joinKeyPath(path, key)] = Change{Type: ChangeRemoved, Old: old}
+		}
+	}
+	for key, value := range to {
+		fieldPath := joinKeyPath(path, key)
+		old, ok := from[key]
+		switch {
+		case !ok:
+			changes[fieldPath] = Change{Type: ChangeAdded, New: value}
+		case reflect.DeepEqual(old, value):
+		default:
+			oldMap, oldIsMap := old.(map[string]interface{})
+			newMap, newIsMap := value.(map[string]interface{})
+			if oldIsMap && newIsMap {
+				diffMaps(fieldPath, oldMap, newMap, changes)
+			} else {
+				changes[fieldPath] = Change{Type: ChangeModified, Old: old, New: value}
+			}
+		}
+	}
+}
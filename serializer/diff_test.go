@@ -0,0 +1,59 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip,omitempty"`
+	}
+	type person struct {
+		Name     string   `json:"name"`
+		Password string   `json:"password"`
+		Tags     []string `json:"tags"`
+		Address  address  `json:"address"`
+	}
+	old := person{Name: "ana", Password: "a", Tags: []string{"x"}, Address: address{City: "Lima", Zip: "15001"}}
+
+	tests := []struct {
+		name string
+		new  person
+		want map[string]Change
+	}{
+		{"equal", old, map[string]Change{}},
+		{"modified", person{Name: "eva", Password: "a", Tags: []string{"x"}, Address: old.Address}, map[string]Change{
+			"name": {Type: ChangeModified, Old: "ana", New: "eva"},
+		}},
+		{"lists compared as a whole", person{Name: "ana", Password: "a", Tags: []string{"x", "y"}, Address: old.Address}, map[string]Change{
+			"tags": {Type: ChangeModified, Old: []interface{}{"x"}, New: []interface{}{"x", "y"}},
+		}},
+		{"nested paths", person{Name: "ana", Password: "a", Tags: []string{"x"}, Address: address{City: "Cusco"}}, map[string]Change{
+			"address.city": {Type: ChangeModified, Old: "Lima", New: "Cusco"},
+			"address.zip":  {Type: ChangeRemoved, Old: "15001"},
+		}},
+		{"hidden fields not compared", person{Name: "ana", Password: "b", Tags: []string{"x"}, Address: old.Address}, map[string]Change{}},
+	}
+	s := &BaseSerializer{WriteOnlyFields: []string{"password"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Diff(old, tt.new)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	added, err := s.Diff(person{Address: address{}}, person{Address: address{Zip: "1"}})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if want := (Change{Type: ChangeAdded, New: "1"}); added["address.zip"] != want {
+		t.Errorf("Diff()[address.zip] = %v, want %v", added["address.zip"], want)
+	}
+}
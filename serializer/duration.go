@@ -0,0 +1,155 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encodings of TimeFormat.Duration. Durations are written as integer nanoseconds, like
+// encoding/json does, when it is empty.
+const (
+	DurationString  = "string"  // Go duration strings, e.g. "1h30m0s"
+	DurationSeconds = "seconds" // Seconds, with a fraction for shorter durations, e.g. 5400
+	DurationMillis  = "millis"  // Milliseconds, with a fraction for shorter durations, e.g. 5400000
+)
+
+var (
+	clockDurationPattern   = regexp.MustCompile(`^(-)?(\d+):([0-5]\d):([0-5]\d(?:\.\d+)?)$`)
+	iso8601DurationPattern = regexp.MustCompile(`^(-)?P(?:(\d+(?:\.\d+)?)W)?(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+)
+
+// iso8601DurationUnits are the units of the groups of iso8601DurationPattern. Days are 24 hours.
+var iso8601DurationUnits = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+// formatDuration converts d into its serialized form in the given encoding.
+func formatDuration(d time.Duration, encoding string) (interface{}, error) {
+	switch encoding {
+	case DurationString:
+		return d.String(), nil
+	case DurationSeconds:
+		return d.Seconds(), nil
+	case DurationMillis:
+		return float64(d) / float64(time.Millisecond), nil
+	case "":
+		return float64(d), nil
+	default:
+		return nil, fmt.Errorf("unknown duration encoding %q", encoding)
+	}
+}
+
+// ParseDuration reads a duration written as a Go duration string ("1h30m"), a clock time
+// ("01:30:00"), or an ISO 8601 duration ("PT1H30M", with days taken as 24 hours). Strings holding
+// a plain number, and numbers, are read in the unit of encoding: seconds for DurationSeconds and
+// DurationString, whose strings carry their units, milliseconds for DurationMillis, and
+// nanoseconds, as encoding/json writes them, when encoding is empty.
+func ParseDuration(value interface{}, encoding string) (time.Duration, error) {
+	var n float64
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case float64:
+		n = v
+	case int64:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case json.Number:
+		if ns, err := v.Int64(); err == nil && encoding == "" {
+			return time.Duration(ns), nil // Exact, beyond the 2^53 nanoseconds of float64
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, NewCodedError(CodeInvalidDuration, "value is not a duration")
+		}
+		n = f
+	case string:
+		return parseDurationString(strings.TrimSpace(v), encoding)
+	default:
+		return 0, NewCodedError(CodeInvalidDuration, "value is not a duration")
+	}
+
+	switch encoding {
+	case DurationSeconds, DurationString:
+		n *= float64(time.Second)
+	case DurationMillis:
+		n *= float64(time.Millisecond)
+	}
+	if math.IsNaN(n) || n >= math.MaxInt64 || n < math.MinInt64 {
+		return 0, NewCodedError(CodeInvalidDuration, "duration is out of range")
+	}
+	return time.Duration(math.Round(n)), nil
+}
+
+// parseDurationString reads a duration string in any of the forms ParseDuration accepts.
+func parseDurationString(s string, encoding string) (time.Duration, error) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return ParseDuration(n, encoding)
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	if match := clockDurationPattern.FindStringSubmatch(s); match != nil {
+		hours, _ := strconv.ParseFloat(match[2], 64)
+		minutes, _ := strconv.ParseFloat(match[3], 64)
+		seconds, _ := strconv.ParseFloat(match[4], 64)
+		return signedDuration(match[1], hours*float64(time.Hour)+minutes*float64(time.Minute)+seconds*float64(time.Second))
+	}
+	if match := iso8601DurationPattern.FindStringSubmatch(strings.ToUpper(s)); match != nil && !strings.HasSuffix(strings.ToUpper(s), "T") {
+		var total float64
+		components := 0
+		for i, unit := range iso8601DurationUnits {
+			if match[i+2] != "" {
+				n, _ := strconv.ParseFloat(match[i+2], 64)
+				total += n * float64(unit)
+				components++
+			}
+		}
+		if components > 0 {
+			return signedDuration(match[1], total)
+		}
+	}
+	return 0, NewCodedErrorWithParams(CodeInvalidDuration, fmt.Sprintf("'%s' is not a duration", s), Params{"value": s})
+}
+
+// signedDuration converts nanoseconds into a Duration, negated when sign is "-".
+func signedDuration(sign string, ns float64) (time.Duration, error) {
+	if ns >= math.MaxInt64 {
+		return 0, NewCodedError(CodeInvalidDuration, "duration is out of range")
+	}
+	if sign == "-" {
+		ns = -ns
+	}
+	return time.Duration(math.Round(ns)), nil
+}
+
+// DurationField is a time.Duration. Input in any of the forms ParseDuration accepts is coerced
+// to the encoding given by Format (DurationString, DurationSeconds or DurationMillis;
+// nanoseconds when empty), and represented in it on Serialize.
+type DurationField struct {
+	Format   string
+	Required bool
+}
+
+func (f DurationField) Validate(value interface{}) error {
+	_, err := ParseDuration(value, f.Format)
+	return err
+}
+
+func (f DurationField) Coerce(value interface{}) (interface{}, error) {
+	d, err := ParseDuration(value, f.Format)
+	if err != nil {
+		return nil, err
+	}
+	return formatDuration(d, f.Format)
+}
+
+func (f DurationField) Represent(value interface{}) (interface{}, error) {
+	return f.Coerce(value)
+}
+
+func (f DurationField) IsRequired() bool { return f.Required }
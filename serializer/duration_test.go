@@ -0,0 +1,84 @@
+package serializer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		encoding string
+		want     time.Duration
+	}{
+		{"Go string", "1h30m", "", 90 * time.Minute},
+		{"clock", "01:30:00", "", 90 * time.Minute},
+		{"negative clock with fraction", "-0:00:01.5", "", -1500 * time.Millisecond},
+		{"ISO 8601", "PT1H30M", "", 90 * time.Minute},
+		{"ISO 8601 days and weeks", "p1w1dt0.5s", "", 8*24*time.Hour + 500*time.Millisecond},
+		{"nanoseconds", 1500.0, "", 1500},
+		{"exact json.Number", json.Number("9007199254740993"), "", 9007199254740993},
+		{"seconds", json.Number("1.5"), DurationSeconds, 1500 * time.Millisecond},
+		{"seconds string", " 90 ", DurationSeconds, 90 * time.Second},
+		{"millis", 250, DurationMillis, 250 * time.Millisecond},
+		{"unitless number with strings", 90.0, DurationString, 90 * time.Second},
+		{"unitless string with strings", "90", DurationString, 90 * time.Second},
+		{"unitless json.Number with strings", json.Number("90"), DurationString, 90 * time.Second},
+		{"Duration kept", time.Minute, DurationSeconds, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDuration(tt.value, tt.encoding)
+			if err != nil {
+				t.Fatalf("ParseDuration() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		encoding string
+	}{
+		{"not a duration", "soon", ""},
+		{"empty ISO 8601", "P", ""},
+		{"ISO 8601 without time", "PT", ""},
+		{"minutes out of range", "1:60:00", ""},
+		{"out of range", 1e300, DurationSeconds},
+		{"boolean", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseDuration(tt.value, tt.encoding); ErrorCode(err) != CodeInvalidDuration {
+				t.Errorf("ParseDuration() = %v, %v, want a %s error", got, err, CodeInvalidDuration)
+			}
+		})
+	}
+}
+
+func TestDurationFieldCoerce(t *testing.T) {
+	tests := []struct {
+		format string
+		want   interface{}
+	}{
+		{"", float64(90 * time.Second)},
+		{DurationString, "1m30s"},
+		{DurationSeconds, 90.0},
+		{DurationMillis, 90000.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := DurationField{Format: tt.format}.Coerce("PT1M30S")
+			if err != nil || got != tt.want {
+				t.Errorf("Coerce() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EmbeddedMode controls how the fields of embedded structs are serialized.
+type EmbeddedMode int
+
+const (
+	EmbeddedPromote EmbeddedMode = iota // Write the fields of embedded structs at the level of the struct, as encoding/json does
+	EmbeddedNest                        // Write each embedded struct as an object under its type name
+)
+
+// nestEmbedded moves the fields promoted from the embedded structs of v, a struct, into one object
+// per embedded struct in result, recursively. A nil embedded pointer is written as nil.
+func nestEmbedded(v reflect.Value, result map[string]interface{}, opts *encodeOptions) error {
+	t := v.Type()
+	schema := schemaFor(t)
+	if len(schema.embedded) == 0 {
+		return nil
+	}
+	for _, keys := range schema.embedded {
+		for _, key := range keys {
+			delete(result, key)
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !promotesFields(sf) {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				result[sf.Name] = nil
+				continue
+			}
+			field = field.Elem()
+		}
+		value, err := toJSONValueWith(field, opts)
+		if err != nil {
+			return &SerializationError{Message: fmt.Sprintf("failed to serialize embedded struct '%s': %v", sf.Name, err), Err: err}
+		}
+		object, _ := value.(map[string]interface{})
+		if object == nil {
+			object = make(map[string]interface{})
+		}
+		if err := nestEmbedded(field, object, opts); err != nil {
+			return err
+		}
+		result[sf.Name] = object
+	}
+	return nil
+}
+
+// promoteEmbedded reverses nestEmbedded for input to the struct type t: the objects of its embedded
+// structs, under their converted names, are merged into the input. Keys of the input take
+// precedence over the keys of the embedded objects.
+func (s *BaseSerializer) promoteEmbedded(input map[string]interface{}, t reflect.Type) map[string]interface{} {
+	t = baseType(t)
+	if t.Kind() != reflect.Struct || len(schemaFor(t).embedded) == 0 {
+		return input
+	}
+
+	promoted := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		promoted[key] = value
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !promotesFields(sf) {
+			continue
+		}
+		key := s.convertKey(sf.Name)
+		value, exists := promoted[key]
+		if !exists {
+			continue
+		}
+		object, ok := value.(map[string]interface{})
+		if value != nil && !ok {
+			continue
+		}
+		delete(promoted, key)
+		for name, item := range s.promoteEmbedded(object, sf.Type) {
+			if _, exists := promoted[name]; !exists {
+				promoted[name] = item
+			}
+		}
+	}
+	return promoted
+}
+
+// expandEmbedded replaces the names of embedded structs of data's type in fields with the keys of
+// the fields they promote, so that Fields and ExcludedFields can select embedded structs as a whole.
+func expandEmbedded(data interface{}, fields []string) []string {
+	t := reflect.TypeOf(data)
+	if len(fields) == 0 || t == nil || baseType(t).Kind() != reflect.Struct {
+		return fields
+	}
+	schema := schemaFor(baseType(t))
+	if len(schema.embedded) == 0 {
+		return fields
+	}
+
+	var expanded []string
+	for i, field := range fields {
+		keys, ok := schema.embedded[field]
+		if !ok || schema.byName[field] != nil {
+			if expanded != nil {
+				expanded = append(expanded, field)
+			}
+			continue
+		}
+		if expanded == nil {
+			expanded = append(make([]string, 0, len(fields)+len(keys)), fields[:i]...)
+		}
+		expanded = append(expanded, keys...)
+	}
+	if expanded == nil {
+		return fields
+	}
+	return expanded
+}
+
+// promotesFields reports whether encoding/json promotes the fields of the struct field sf: an
+// embedded struct, or pointer to one, without a JSON name.
+func promotesFields(sf reflect.StructField) bool {
+	if !sf.Anonymous {
+		return false
+	}
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return false
+	}
+	if name, _, _ := strings.Cut(tag, ","); validTagName(name) {
+		return false
+	}
+	ft := sf.Type
+	if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	return ft.Kind() == reflect.Struct
+}
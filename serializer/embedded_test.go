@@ -0,0 +1,79 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+type timestamps struct {
+	Created string `json:"created"`
+}
+
+type Audit struct {
+	timestamps
+	By string `json:"by"`
+}
+
+type document struct {
+	*Audit
+	Named `json:"named"`
+	Title string `json:"title"`
+}
+
+// Named is embedded with a JSON name, so its fields aren't promoted.
+type Named struct {
+	Name string `json:"name"`
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	data := document{Audit: &Audit{timestamps: timestamps{Created: "2024"}, By: "ana"}, Named: Named{Name: "n"}, Title: "t"}
+	tests := []struct {
+		name string
+		s    *BaseSerializer
+		data document
+		want map[string]interface{}
+	}{
+		{"promote", &BaseSerializer{}, data,
+			map[string]interface{}{"created": "2024", "by": "ana", "named": map[string]interface{}{"name": "n"}, "title": "t"}},
+		{"nest", &BaseSerializer{EmbeddedStructs: EmbeddedNest}, data,
+			map[string]interface{}{"Audit": map[string]interface{}{"by": "ana", "timestamps": map[string]interface{}{"created": "2024"}}, "named": map[string]interface{}{"name": "n"}, "title": "t"}},
+		{"nil pointer", &BaseSerializer{EmbeddedStructs: EmbeddedNest}, document{Title: "t"},
+			map[string]interface{}{"Audit": nil, "named": map[string]interface{}{"name": ""}, "title": "t"}},
+		{"fields select embedded structs", &BaseSerializer{Fields: []string{"Audit", "title"}}, data,
+			map[string]interface{}{"created": "2024", "by": "ana", "title": "t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.Serialize(tt.data)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserializeNestedEmbeddedStructs(t *testing.T) {
+	s := &BaseSerializer{EmbeddedStructs: EmbeddedNest, KeyNaming: NamingSnakeCase}
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  string // Created
+	}{
+		{"nested", map[string]interface{}{"audit": map[string]interface{}{"timestamps": map[string]interface{}{"created": "2024"}}}, "2024"},
+		{"top-level key wins", map[string]interface{}{"created": "2025", "audit": map[string]interface{}{"created": "2024"}}, "2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got document
+			if err := s.Deserialize(tt.input, &got); err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if got.Audit == nil || got.Created != tt.want {
+				t.Errorf("Deserialize() = %+v, want created %s", got.Audit, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks the serialized values of EncryptedFields.
+const encryptedPrefix = "enc:"
+
+// KeyProvider supplies the AES keys (16, 24 or 32 bytes) of EncryptedFields. Implementations can
+// hold the keys in memory (see StaticKeys) or fetch them from a KMS or Vault, and must be safe for
+// concurrent use.
+type KeyProvider interface {
+	// CurrentKey returns the key new values are encrypted with, and its id.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key with the given id, for values encrypted with an older key.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider holding its keys in memory. Keeping retired keys in Keys lets
+// values encrypted before a rotation be decrypted.
+type StaticKeys struct {
+	Current string            // Id of the key new values are encrypted with
+	Keys    map[string][]byte // Keys by id; ids can't contain ':'
+}
+
+// NewStaticKey returns a KeyProvider with a single key.
+func NewStaticKey(key []byte) *StaticKeys {
+	return &StaticKeys{Current: "default", Keys: map[string][]byte{"default": key}}
+}
+
+func (k *StaticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := k.Key(ctx, k.Current)
+	return k.Current, key, err
+}
+
+func (k *StaticKeys) Key(_ context.Context, id string) ([]byte, error) {
+	key, ok := k.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key '%s'", id)
+	}
+	return key, nil
+}
+
+// encryptFields replaces the values of EncryptedFields in a serialized result with their
+// AES-GCM encryption, written as "enc:<key id>:<base64 nonce and ciphertext>". The JSON encoding
+// of a value is encrypted, so its type is restored on Deserialize. Null values are kept.
+func (s *BaseSerializer) encryptFields(ctx context.Context, result map[string]interface{}) error {
+	for _, field := range s.EncryptedFields {
+		value, exists := result[field]
+		if !exists || value == nil {
+			continue
+		}
+		// The value is left out of errors so it doesn't end up in logs
+		fail := func(err error) error {
+			return &TransformationError{Field: field, Message: fmt.Sprintf("failed to encrypt: %v", err), Code: CodeEncryptionFailed, Err: err}
+		}
+		if s.KeyProvider == nil {
+			return fail(fmt.Errorf("no KeyProvider configured"))
+		}
+
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return fail(err)
+		}
+		id, key, err := s.KeyProvider.CurrentKey(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return fail(err)
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fail(err)
+		}
+		sealed := aead.Seal(nonce, nonce, plaintext, []byte(field))
+		result[field] = encryptedPrefix + id + ":" + base64.RawURLEncoding.EncodeToString(sealed)
+	}
+	return nil
+}
+
+// decryptFields replaces the encrypted values of EncryptedFields in the input with the values
+// they hold. Null values are kept, and values that aren't encrypted are rejected, so that clients
+// can't get around the encryption by sending plaintext.
+func (s *BaseSerializer) decryptFields(ctx context.Context, input map[string]interface{}) error {
+	for _, field := range s.EncryptedFields {
+		value, exists := input[field]
+		if !exists || value == nil {
+			continue
+		}
+		// The value is left out of errors so it doesn't end up in logs
+		encrypted, ok := value.(string)
+		if !ok || !strings.HasPrefix(encrypted, encryptedPrefix) {
+			return &ValidationError{Field: field, Message: s.translate(ctx, CodeNotEncrypted, nil, "value must be encrypted"), Code: CodeNotEncrypted}
+		}
+		fail := func(err error) error {
+			return &ValidationError{Field: field, Message: s.translate(ctx, CodeDecryptionFailed, nil, fmt.Sprintf("failed to decrypt: %v", err)), Code: CodeDecryptionFailed, Err: err}
+		}
+		if s.KeyProvider == nil {
+			return fail(fmt.Errorf("no KeyProvider configured"))
+		}
+
+		id, encoded, ok := strings.Cut(strings.TrimPrefix(encrypted, encryptedPrefix), ":")
+		if !ok {
+			return fail(fmt.Errorf("malformed value"))
+		}
+		sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil {
+			return fail(fmt.Errorf("malformed value"))
+		}
+		key, err := s.KeyProvider.Key(ctx, id)
+		if err != nil {
+			return fail(err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return fail(err)
+		}
+		if len(sealed) < aead.NonceSize() {
+			return fail(fmt.Errorf("malformed value"))
+		}
+		plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(field))
+		if err != nil {
+			return fail(fmt.Errorf("message authentication failed"))
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(plaintext))
+		if s.UseNumber {
+			dec.UseNumber()
+		}
+		var decrypted interface{}
+		if err := dec.Decode(&decrypted); err != nil {
+			return fail(err)
+		}
+		input[field] = decrypted
+	}
+	return nil
+}
+
+// newAEAD returns AES-GCM with key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,85 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type patient struct {
+	Name string `json:"name"`
+	SSN  string `json:"ssn"`
+	Age  int    `json:"age"`
+}
+
+func TestEncryptedFieldsRoundTrip(t *testing.T) {
+	s := &BaseSerializer{EncryptedFields: []string{"ssn", "age"}, KeyProvider: NewStaticKey(bytes.Repeat([]byte("k"), 32))}
+	in := patient{Name: "Ana", SSN: "123-45-6789", Age: 42}
+	serialized, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	for _, field := range []string{"ssn", "age"} {
+		if value, _ := serialized[field].(string); !strings.HasPrefix(value, "enc:default:") {
+			t.Errorf("Serialize()[%q] = %v, want an encrypted value", field, serialized[field])
+		}
+	}
+
+	var out patient
+	if err := s.Deserialize(serialized, &out); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if out != in {
+		t.Errorf("Deserialize() = %+v, want %+v", out, in)
+	}
+}
+
+func TestDeserializeEncryptedFieldsErrors(t *testing.T) {
+	key := NewStaticKey(bytes.Repeat([]byte("k"), 32))
+	s := &BaseSerializer{EncryptedFields: []string{"ssn"}, KeyProvider: key}
+	encrypted, err := s.Serialize(patient{SSN: "123-45-6789"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssn := encrypted["ssn"].(string)
+	tampered := ssn[:len(ssn)-2] + "AA"
+	if tampered == ssn {
+		tampered = ssn[:len(ssn)-2] + "BB"
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		code  string // Expected ValidationError code, or "" for success
+	}{
+		{"encrypted", map[string]interface{}{"ssn": ssn}, ""},
+		{"null", map[string]interface{}{"ssn": nil}, ""},
+		{"missing", map[string]interface{}{"name": "Ana"}, ""},
+		{"plaintext", map[string]interface{}{"ssn": "123-45-6789"}, CodeNotEncrypted},
+		{"plaintext in another case", map[string]interface{}{"SSN": "123-45-6789"}, CodeNotEncrypted},
+		{"not a string", map[string]interface{}{"ssn": 123456789.0}, CodeNotEncrypted},
+		{"tampered", map[string]interface{}{"ssn": tampered}, CodeDecryptionFailed},
+		{"malformed", map[string]interface{}{"ssn": "enc:default"}, CodeDecryptionFailed},
+		{"unknown key", map[string]interface{}{"ssn": strings.Replace(ssn, "enc:default:", "enc:old:", 1)}, CodeDecryptionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out patient
+			err := s.Deserialize(tt.input, &out)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Deserialize() error = %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Code != tt.code || validationErr.Field != "ssn" {
+				t.Fatalf("Deserialize() error = %v, want %s on ssn", err, tt.code)
+			}
+			if validationErr.Value != nil {
+				t.Errorf("Deserialize() error holds the value %v", validationErr.Value)
+			}
+		})
+	}
+}
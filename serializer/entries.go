@@ -0,0 +1,102 @@
+package serializer
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// keyPatterns caches the compiled KeyPattern of MapFields.
+var keyPatterns sync.Map
+
+// EachKey returns a validation that checks every key of an object with rules, which receive the
+// keys as strings. Keys are checked in sorted order and the first failure is reported with its
+// key, keeping its code, or with CodeInvalidKey when it has none.
+func EachKey(rules ...func(interface{}) error) func(interface{}) error {
+	rule := And(rules...)
+	return func(value interface{}) error {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return NewCodedError(CodeNotObject, "value is not an object")
+		}
+		for _, key := range objectKeys(object) {
+			if err := rule(key); err != nil {
+				code := validationCode(err)
+				if code == CodeInvalid {
+					code = CodeInvalidKey
+				}
+				return &CodedError{Code: code, Message: fmt.Sprintf("key '%s': %v", key, err), Err: err}
+			}
+		}
+		return nil
+	}
+}
+
+// EachValue returns a validation that checks every value of an object with rules, e.g.
+// EachValue(NotEmpty) for metadata with string values. Values are checked in the sorted order of
+// their keys and the first failure is reported with its key, keeping its code.
+func EachValue(rules ...func(interface{}) error) func(interface{}) error {
+	rule := And(rules...)
+	return func(value interface{}) error {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return NewCodedError(CodeNotObject, "value is not an object")
+		}
+		for _, key := range objectKeys(object) {
+			if err := rule(object[key]); err != nil {
+				return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("key '%s': %v", key, err), Err: err}
+			}
+		}
+		return nil
+	}
+}
+
+// KeyPattern returns a validation that checks that every key of an object matches pattern, e.g.
+// KeyPattern(`^[a-z][a-z0-9_]*$`). Like regexp.MustCompile, it panics when pattern is invalid.
+func KeyPattern(pattern string) func(interface{}) error {
+	re := regexp.MustCompile(pattern)
+	return func(value interface{}) error {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return NewCodedError(CodeNotObject, "value is not an object")
+		}
+		return matchKeys(object, re)
+	}
+}
+
+// MaxEntries returns a validation that checks that an object has at most n keys.
+func MaxEntries(n int) func(interface{}) error {
+	return func(value interface{}) error {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return NewCodedError(CodeNotObject, "value is not an object")
+		}
+		if len(object) > n {
+			return NewCodedErrorWithParams(CodeMaxLength, fmt.Sprintf("object must have at most %d entries", n), Params{"max": n})
+		}
+		return nil
+	}
+}
+
+// matchKeys checks the keys of object against re, in sorted order.
+func matchKeys(object map[string]interface{}, re *regexp.Regexp) error {
+	for _, key := range objectKeys(object) {
+		if !re.MatchString(key) {
+			return NewCodedErrorWithParams(CodeInvalidKey, fmt.Sprintf("key '%s' must match %s", key, re), Params{"key": key, "pattern": re.String()})
+		}
+	}
+	return nil
+}
+
+// keyPattern returns the compiled pattern, cached across validations.
+func keyPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := keyPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	keyPatterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,56 @@
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEntryValidations(t *testing.T) {
+	object := map[string]interface{}{"b": "x", "a": "", "C": "y"}
+	tests := []struct {
+		name       string
+		validation func(interface{}) error
+		value      interface{}
+		wantCode   string
+		wantKey    string // Key named in the message
+	}{
+		{"keys valid", EachKey(NotEmpty), object, "", ""},
+		{"key keeps its code", EachKey(Not(OneOf("b"))), object, CodeNotAllowed, "b"},
+		{"key without code", EachKey(func(interface{}) error { return errors.New("bad") }), object, CodeInvalidKey, "C"},
+		{"keys of a non-object", EachKey(NotEmpty), []interface{}{}, CodeNotObject, ""},
+		{"values checked in key order", EachValue(NotEmpty), object, CodeEmpty, "a"},
+		{"values valid", EachValue(NotEmpty), map[string]interface{}{"a": "x"}, "", ""},
+		{"values of a non-object", EachValue(NotEmpty), "x", CodeNotObject, ""},
+		{"key pattern", KeyPattern(`^[a-z]+$`), object, CodeInvalidKey, "C"},
+		{"key pattern valid", KeyPattern(`^[a-zA-Z]$`), object, "", ""},
+		{"key pattern of a non-object", KeyPattern(`.`), nil, CodeNotObject, ""},
+		{"max entries", MaxEntries(3), object, "", ""},
+		{"too many entries", MaxEntries(2), object, CodeMaxLength, ""},
+		{"max entries of a non-object", MaxEntries(1), 1.0, CodeNotObject, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validation(tt.value)
+			if code := ErrorCode(err); code != tt.wantCode || (tt.wantCode == "") != (err == nil) {
+				t.Fatalf("validation() error = %v (code %q), want code %q", err, code, tt.wantCode)
+			}
+			if tt.wantKey != "" && !strings.Contains(err.Error(), "'"+tt.wantKey+"'") {
+				t.Errorf("validation() error = %v, want one naming key %q", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestKeyPatternCache(t *testing.T) {
+	first, err := keyPattern(`^k\d+$`)
+	if err != nil {
+		t.Fatalf("keyPattern() error = %v", err)
+	}
+	if second, _ := keyPattern(`^k\d+$`); second != first {
+		t.Errorf("keyPattern() compiled the pattern again")
+	}
+	if _, err := keyPattern(`(`); err == nil {
+		t.Errorf("keyPattern() of an invalid pattern error = nil, want an error")
+	}
+}
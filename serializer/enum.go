@@ -0,0 +1,141 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// EnumField restricts a field to a set of allowed values, optionally written as display labels.
+// Serialize replaces values with their labels and Deserialize maps labels back to the values;
+// values without a label are written and accepted as they are.
+type EnumField struct {
+	Values []interface{}          // Allowed values, e.g. 1, 2, 3; values with a label don't need to be listed
+	Labels map[interface{}]string // Display labels of values, e.g. 1: "active"
+}
+
+// Validate checks that value is one of the allowed values or labels.
+func (f EnumField) Validate(value interface{}) error {
+	if _, ok := f.value(value); !ok {
+		return NewCodedErrorWithParams(CodeNotAllowed, fmt.Sprintf("value must be one of %v", f.Allowed()), Params{"allowed": f.Allowed()})
+	}
+	return nil
+}
+
+// Allowed returns the serialized form of every allowed value: its label, or the value itself.
+func (f EnumField) Allowed() []interface{} {
+	allowed := make([]interface{}, 0, len(f.Values)+len(f.Labels))
+	for _, value := range f.values() {
+		if label, ok := f.Labels[value]; ok {
+			allowed = append(allowed, label)
+		} else {
+			allowed = append(allowed, value)
+		}
+	}
+	return allowed
+}
+
+// Label returns the serialized form of value, reporting whether value is allowed.
+func (f EnumField) Label(value interface{}) (interface{}, bool) {
+	for _, candidate := range f.values() {
+		if sameEnumValue(value, candidate) {
+			if label, ok := f.Labels[candidate]; ok {
+				return label, true
+			}
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// value returns the allowed value a serialized value or label stands for.
+func (f EnumField) value(serialized interface{}) (interface{}, bool) {
+	if label, ok := serialized.(string); ok {
+		for value, candidate := range f.Labels {
+			if candidate == label {
+				return value, true
+			}
+		}
+	}
+	for _, candidate := range f.values() {
+		if _, labeled := f.Labels[candidate]; !labeled && sameEnumValue(serialized, candidate) {
+			return candidate, true
+		}
+	}
+	return nil, false
+}
+
+// values returns the allowed values: Values followed by the labeled values not listed there,
+// ordered by label.
+func (f EnumField) values() []interface{} {
+	values := append([]interface{}(nil), f.Values...)
+	labeled := make([]interface{}, 0, len(f.Labels))
+	for value := range f.Labels {
+		listed := false
+		for _, candidate := range f.Values {
+			if candidate == value {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			labeled = append(labeled, value)
+		}
+	}
+	sort.Slice(labeled, func(i, j int) bool { return f.Labels[labeled[i]] < f.Labels[labeled[j]] })
+	return append(values, labeled...)
+}
+
+// OneOf returns a validation that accepts only the given values. Numbers are compared by value,
+// so OneOf(1, 2) accepts the float64 and json.Number forms of decoded JSON.
+func OneOf(values ...interface{}) func(interface{}) error {
+	return EnumField{Values: values}.Validate
+}
+
+// sameEnumValue reports whether a serialized or decoded value equals an enum value.
+func sameEnumValue(value, candidate interface{}) bool {
+	normalized, err := toJSONValue(reflect.ValueOf(candidate))
+	if err != nil {
+		return false
+	}
+	if a, ok := numberValue(value); ok {
+		b, ok := numberValue(normalized)
+		return ok && a == b
+	}
+	return reflect.DeepEqual(value, normalized)
+}
+
+// labelEnums replaces the values of enum fields in a serialized result with their labels.
+func (s *BaseSerializer) labelEnums(result map[string]interface{}) error {
+	for field, enum := range s.Enums {
+		value, exists := result[field]
+		if !exists || value == nil {
+			continue
+		}
+		label, ok := enum.Label(value)
+		if !ok {
+			return &TransformationError{Field: field, Value: value, Message: "value is not allowed", Code: CodeNotAllowed}
+		}
+		result[field] = label
+	}
+	return nil
+}
+
+// unlabelEnums replaces the labels of enum fields in the input with the values they stand for.
+func (s *BaseSerializer) unlabelEnums(ctx context.Context, input map[string]interface{}) error {
+	for field, enum := range s.Enums {
+		serialized, exists := input[field]
+		if !exists || serialized == nil {
+			continue
+		}
+		value, ok := enum.value(serialized)
+		if !ok {
+			params := Params{"allowed": enum.Allowed()}
+			message := s.translate(ctx, CodeNotAllowed, params, fmt.Sprintf("value must be one of %v", enum.Allowed()))
+			return &ValidationError{Field: field, Value: serialized, Message: message, Code: CodeNotAllowed, Params: params}
+		}
+		input[field] = value
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEnumField(t *testing.T) {
+	status := EnumField{Values: []interface{}{0}, Labels: map[interface{}]string{2: "closed", 1: "active"}}
+	if got, want := status.Allowed(), []interface{}{0, "active", "closed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Allowed() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		label     interface{}
+		labelOK   bool
+		validates bool
+	}{
+		{"unlabeled value", 0, 0, true, true},
+		{"labeled int", 1, "active", true, false},
+		{"labeled float64", 2.0, "closed", true, false},
+		{"labeled json.Number", json.Number("1"), "active", true, false},
+		{"label", "active", nil, false, true},
+		{"unknown value", 3, nil, false, false},
+		{"unknown label", "archived", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, ok := status.Label(tt.value)
+			if ok != tt.labelOK || !reflect.DeepEqual(label, tt.label) {
+				t.Errorf("Label() = %v, %v, want %v, %v", label, ok, tt.label, tt.labelOK)
+			}
+			if err := status.Validate(tt.value); (err == nil) != tt.validates {
+				t.Errorf("Validate() error = %v, want valid %v", err, tt.validates)
+			}
+		})
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	validate := OneOf(1, 2, "x")
+	tests := []struct {
+		value interface{}
+		valid bool
+	}{
+		{1.0, true},
+		{json.Number("2"), true},
+		{int64(2), true},
+		{"x", true},
+		{"1", false},
+		{3, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if err := validate(tt.value); (err == nil) != tt.valid {
+			t.Errorf("OneOf()(%#v) error = %v, want valid %v", tt.value, err, tt.valid)
+		} else if err != nil && ErrorCode(err) != CodeNotAllowed {
+			t.Errorf("OneOf()(%#v) code = %q, want %q", tt.value, ErrorCode(err), CodeNotAllowed)
+		}
+	}
+}
+
+func TestEnumsRoundTrip(t *testing.T) {
+	type ticket struct {
+		ID     int `json:"id"`
+		Status int `json:"status"`
+	}
+	s := &BaseSerializer{Enums: map[string]EnumField{
+		"status": {Labels: map[interface{}]string{1: "open", 2: "closed"}},
+	}}
+	serialized, err := s.Serialize(ticket{ID: 1, Status: 2})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if serialized["status"] != "closed" {
+		t.Errorf("Serialize() status = %v, want %q", serialized["status"], "closed")
+	}
+	var got ticket
+	if err := s.Deserialize(map[string]interface{}{"id": 1, "status": "closed"}, &got); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if got.Status != 2 {
+		t.Errorf("Deserialize() status = %d, want 2", got.Status)
+	}
+
+	if _, err := s.Serialize(ticket{Status: 3}); ErrorCode(err) != CodeNotAllowed {
+		t.Errorf("Serialize() of an unknown value error = %v, want code %q", err, CodeNotAllowed)
+	}
+	err = s.Deserialize(map[string]interface{}{"status": "archived"}, &got)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "status" || validationErr.Code != CodeNotAllowed {
+		t.Errorf("Deserialize() of an unknown label error = %v, want a %s error on status", err, CodeNotAllowed)
+	}
+}
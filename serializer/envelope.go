@@ -0,0 +1,113 @@
+package serializer
+
+import "context"
+
+// MetaProvider builds the meta block of an envelope for the serialized data, e.g. pagination
+// details or the request id carried by ctx. ctx carries the call's Metadata, so values set by the
+// pipeline stages can be reported. A nil or empty result omits the block.
+type MetaProvider func(ctx context.Context, data interface{}) (map[string]interface{}, error)
+
+// Envelope wraps serialized output in a standard response object:
+//
+//	{"data": {...}, "meta": {...}}
+//
+// and errors in {"errors": [...]}.
+type Envelope struct {
+	DataKey   string       // Key of the serialized data; "data" when empty
+	MetaKey   string       // Key of the meta block; "meta" when empty
+	ErrorsKey string       // Key of the error list; "errors" when empty
+	Meta      MetaProvider // Builds the meta block; no meta block when nil
+}
+
+// Wrap returns the envelope of a serialized result (an object or a list) of data.
+func (e *Envelope) Wrap(ctx context.Context, data interface{}, result interface{}) (map[string]interface{}, error) {
+	wrapped := map[string]interface{}{e.dataKey(): result}
+	if e.Meta == nil {
+		return wrapped, nil
+	}
+	meta, err := e.Meta(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) > 0 {
+		wrapped[e.metaKey()] = meta
+	}
+	return wrapped, nil
+}
+
+// WrapError returns the envelope of err, listing its field errors as in a Problem.
+func (e *Envelope) WrapError(err error) map[string]interface{} {
+	problem := NewProblem(err)
+	errs := problem.Errors
+	if len(errs) == 0 {
+		errs = []ProblemError{{Code: ErrorCode(err), Message: problem.Detail}}
+	}
+	return map[string]interface{}{e.errorsKey(): errs}
+}
+
+// Unwrap returns the data of an enveloped input, or input itself when it isn't enveloped.
+func (e *Envelope) Unwrap(input map[string]interface{}) map[string]interface{} {
+	data, ok := input[e.dataKey()].(map[string]interface{})
+	if !ok {
+		return input
+	}
+	for key := range input {
+		if key != e.dataKey() && key != e.metaKey() {
+			return input
+		}
+	}
+	return data
+}
+
+// Metas combines meta providers into one; later providers win on conflicting keys.
+func Metas(providers ...MetaProvider) MetaProvider {
+	return func(ctx context.Context, data interface{}) (map[string]interface{}, error) {
+		combined := make(map[string]interface{})
+		for _, provider := range providers {
+			meta, err := provider(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range meta {
+				combined[key] = value
+			}
+		}
+		return combined, nil
+	}
+}
+
+// ContextMeta returns a meta provider that adds the value stored in the context under ctxKey
+// (e.g. a request id set by a middleware) as the meta key name. Missing values are left out.
+func ContextMeta(name string, ctxKey interface{}) MetaProvider {
+	return func(ctx context.Context, _ interface{}) (map[string]interface{}, error) {
+		value := ctx.Value(ctxKey)
+		if value == nil {
+			return nil, nil
+		}
+		return map[string]interface{}{name: value}, nil
+	}
+}
+
+// dataKey returns the key of the serialized data.
+func (e *Envelope) dataKey() string {
+	if e.DataKey == "" {
+		return "data"
+	}
+	return e.DataKey
+}
+
+// metaKey returns the key of the meta block.
+func (e *Envelope) metaKey() string {
+	if e.MetaKey == "" {
+		return "meta"
+	}
+	return e.MetaKey
+}
+
+// errorsKey returns the key of the error list.
+func (e *Envelope) errorsKey() string {
+	if e.ErrorsKey == "" {
+		return "errors"
+	}
+	return e.ErrorsKey
+}
@@ -0,0 +1,125 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestEnvelopeSerialize(t *testing.T) {
+	data := account{ID: 7, Name: "ana"}
+	errMeta := errors.New("no meta")
+	tests := []struct {
+		name     string
+		envelope *Envelope
+		want     map[string]interface{}
+		wantErr  error
+	}{
+		{"data only", &Envelope{}, map[string]interface{}{"data": map[string]interface{}{"id": 7.0, "name": "ana"}}, nil},
+		{"meta", &Envelope{Meta: Metas(
+			ContextMeta("request_id", requestIDKey{}),
+			ContextMeta("missing", "unset"),
+			func(ctx context.Context, data interface{}) (map[string]interface{}, error) {
+				seen, _ := MetadataFromContext(ctx).Get("seen")
+				return map[string]interface{}{"type": reflect.TypeOf(data).Name(), "seen": seen}, nil
+			},
+		)}, map[string]interface{}{
+			"data": map[string]interface{}{"id": 7.0, "name": "ana"},
+			"meta": map[string]interface{}{"request_id": "r1", "type": "account", "seen": "ana"},
+		}, nil},
+		{"empty meta", &Envelope{DataKey: "result", Meta: ContextMeta("missing", "unset")}, map[string]interface{}{"result": map[string]interface{}{"id": 7.0, "name": "ana"}}, nil},
+		{"later providers win", &Envelope{MetaKey: "_meta", Meta: Metas(
+			func(context.Context, interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"v": 1}, nil
+			},
+			func(context.Context, interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"v": 2}, nil
+			},
+		)}, map[string]interface{}{"data": map[string]interface{}{"id": 7.0, "name": "ana"}, "_meta": map[string]interface{}{"v": 2}}, nil},
+		{"meta error", &Envelope{Meta: Metas(func(context.Context, interface{}) (map[string]interface{}, error) { return nil, errMeta })}, nil, errMeta},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{
+				Fields:   []string{"id", "name"},
+				Envelope: tt.envelope,
+				MetadataTransformations: map[string]func(interface{}, *Metadata) interface{}{
+					"name": func(value interface{}, meta *Metadata) interface{} {
+						meta.Set("seen", value)
+						return value
+					},
+				},
+			}
+			ctx := context.WithValue(context.Background(), requestIDKey{}, "r1")
+			got, err := s.SerializeWithContext(ctx, data)
+			if err != tt.wantErr {
+				t.Fatalf("SerializeWithContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeWithContext() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvelopeUnwrap(t *testing.T) {
+	envelope := &Envelope{}
+	data := map[string]interface{}{"id": 7.0}
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{"enveloped", map[string]interface{}{"data": data, "meta": map[string]interface{}{}}, data},
+		{"data only", map[string]interface{}{"data": data}, data},
+		{"not enveloped", data, data},
+		{"other keys", map[string]interface{}{"data": data, "id": 1.0}, map[string]interface{}{"data": data, "id": 1.0}},
+		{"data is not an object", map[string]interface{}{"data": "x"}, map[string]interface{}{"data": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envelope.Unwrap(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unwrap() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	var out account
+	s := &BaseSerializer{Envelope: envelope}
+	if err := s.Deserialize(map[string]interface{}{"data": map[string]interface{}{"id": 7.0, "name": "ana"}}, &out); err != nil || out != (account{ID: 7, Name: "ana"}) {
+		t.Errorf("Deserialize() of an envelope = %+v, %v", out, err)
+	}
+	s.Validations = map[string][]func(interface{}) error{"name": {NotEmpty}}
+	if err := s.Validate(map[string]interface{}{"data": map[string]interface{}{"name": "ana"}}); err != nil {
+		t.Errorf("Validate() of an envelope error = %v", err)
+	}
+	if err := s.Validate(map[string]interface{}{"data": map[string]interface{}{"name": ""}}); err == nil {
+		t.Error("Validate() of an envelope with an empty name error = nil, want an error")
+	}
+}
+
+func TestEnvelopeWrapError(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope *Envelope
+		err      error
+		want     map[string]interface{}
+	}{
+		{"field errors", &Envelope{}, &ValidationError{Field: "name", Code: CodeRequired, Message: "field is missing"},
+			map[string]interface{}{"errors": []ProblemError{{Field: "name", Pointer: "/name", Code: CodeRequired, Message: "field is missing"}}}},
+		{"coded errors", &Envelope{}, &CodedError{Code: "quota", Message: "over quota"},
+			map[string]interface{}{"errors": []ProblemError{{Code: "quota", Message: "over quota"}}}},
+		{"other errors", &Envelope{ErrorsKey: "failures"}, &SerializationError{Message: "bad input"},
+			map[string]interface{}{"failures": []ProblemError{{Message: (&SerializationError{Message: "bad input"}).Error()}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.envelope.WrapError(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WrapError() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,34 +1,430 @@
 package serializer
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Sentinel errors for the kinds of failure callers commonly branch on. The error types of the
+// package match them with errors.Is according to their Code, e.g.
+// errors.Is(err, ErrFieldMissing) for a ValidationError with CodeRequired.
+var (
+	ErrFieldMissing = errors.New("field is missing")         // CodeRequired
+	ErrTypeMismatch = errors.New("value has the wrong type") // CodeInvalidType, CodeNotObject and CodeOutOfRange
+	ErrReadOnly     = errors.New("field is read-only")       // CodeReadOnly
+	ErrDuplicateKey = errors.New("duplicate key")            // CodeDuplicateKey
+	ErrNotAllowed   = errors.New("value is not allowed")     // CodeNotAllowed
+)
+
+// codeSentinels maps error codes to the sentinel errors they match.
+var codeSentinels = map[string]error{
+	CodeRequired:     ErrFieldMissing,
+	CodeInvalidType:  ErrTypeMismatch,
+	CodeNotObject:    ErrTypeMismatch,
+	CodeOutOfRange:   ErrTypeMismatch,
+	CodeReadOnly:     ErrReadOnly,
+	CodeDuplicateKey: ErrDuplicateKey,
+	CodeNotAllowed:   ErrNotAllowed,
+}
+
+// isCode reports whether target is the sentinel error of code.
+func isCode(code string, target error) bool {
+	sentinel, ok := codeSentinels[code]
+	return ok && sentinel == target
+}
 
 // ValidationError represents an error that occurred during validation.
 type ValidationError struct {
-	Field   string
-	Value   interface{}
-	Message string
+	Field    string   // Name of the field, or its path within nested objects, e.g. "items[2].price"
+	Path     []string // Tokens of Field within nested objects, e.g. ["items", "2", "price"]; nil for top-level fields
+	Value    interface{}
+	Message  string
+	Code     string   // Machine-readable reason, e.g. "required" or "invalid_email"
+	Params   Params   // Parameters of the message, e.g. {"min": 3} for CodeMinLength
+	Err      error    // Underlying error, e.g. the one returned by the failed validation
+	Severity Severity // SeverityWarning for the non-fatal issues of ValidateWithWarnings
 }
 
 func (e *ValidationError) Error() string {
+	if e.Severity == SeverityWarning {
+		return fmt.Sprintf("Validation warning on field '%s': %s (value: %v)", e.Field, e.Message, e.Value)
+	}
 	return fmt.Sprintf("Validation error on field '%s': %s (value: %v)", e.Field, e.Message, e.Value)
 }
 
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of the error's code.
+func (e *ValidationError) Is(target error) bool {
+	return isCode(e.Code, target)
+}
+
+// pathTokens returns the tokens of the location of the failed field, splitting Field when Path
+// is not set.
+func (e *ValidationError) pathTokens() []string {
+	if e.Path != nil {
+		return e.Path
+	}
+	return splitFieldPath(e.Field)
+}
+
+// ValidationErrors lists every failure found by ValidateAll, sorted by field. The failures are
+// usually *ValidationError and *ConstraintError values.
+type ValidationErrors struct {
+	Errors []error
+}
+
+func (e *ValidationErrors) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the failures, so that errors.Is and errors.As match any of them.
+func (e *ValidationErrors) Unwrap() []error {
+	return e.Errors
+}
+
 // TransformationError represents an error that occurred during a transformation.
 type TransformationError struct {
 	Field   string
 	Value   interface{}
 	Message string
+	Code    string // Machine-readable reason, e.g. "transformation_nil"
+	Err     error  // Underlying error, e.g. the one returned by a computed field
 }
 
 func (e *TransformationError) Error() string {
 	return fmt.Sprintf("Transformation error on field '%s': %s (value: %v)", e.Field, e.Message, e.Value)
 }
 
+// Unwrap returns the underlying error.
+func (e *TransformationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of the error's code.
+func (e *TransformationError) Is(target error) bool {
+	return isCode(e.Code, target)
+}
+
+// ConstraintError represents the failure of a Constraint, which involves several fields.
+type ConstraintError struct {
+	Fields  []string
+	Message string
+	Code    string // Machine-readable reason, e.g. "exactly_one"
+	Params  Params // Parameters of the message, e.g. {"fields": ["email", "phone"]}
+	Err     error  // Underlying error, e.g. the one returned by the constraint's check
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("Validation error on fields %s: %s", quoteFields(e.Fields), e.Message)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of the error's code.
+func (e *ConstraintError) Is(target error) bool {
+	return isCode(e.Code, target)
+}
+
+// Error codes set by the built-in validations and the serializer itself.
+const (
+	CodeInvalid            = "invalid"             // Default code of validation errors without one
+	CodeRequired           = "required"            // The field is missing
+	CodeReadOnly           = "read_only"           // A read-only field was sent with RejectReadOnly
+	CodeDuplicateKey       = "duplicate_key"       // A key appears twice with DuplicateKeysError
+	CodeDuplicateItem      = "duplicate_item"      // A list has the same item twice
+	CodeInvalidKey         = "invalid_key"         // A key of an object is not allowed
+	CodeInvalidType        = "invalid_type"        // The value has the wrong type
+	CodeNotObject          = "not_object"          // The value is not an object
+	CodeEmpty              = "empty"               // The value is empty
+	CodeNotPositive        = "not_positive"        // The number is zero or negative
+	CodeInvalidEmail       = "invalid_email"       // The value is not an e-mail address
+	CodeMinLength          = "min_length"          // The value is too short
+	CodeMaxLength          = "max_length"          // The value is too long
+	CodeMissingUppercase   = "missing_uppercase"   // The password has no upper case letter
+	CodeMissingLowercase   = "missing_lowercase"   // The password has no lower case letter
+	CodeMissingNumber      = "missing_number"      // The password has no digit
+	CodeMissingSpecial     = "missing_special"     // The password has no special character
+	CodeTransformationNil  = "transformation_nil"  // A transformation returned nil
+	CodeInvalidEnum        = "invalid_enum"        // An enum definition can't be serialized
+	CodeComputeFailed      = "compute_failed"      // A computed field returned an error
+	CodeInvalidTime        = "invalid_time"        // The value is not a time in an accepted layout
+	CodeInvalidDuration    = "invalid_duration"    // The value is not a duration in an accepted form
+	CodeNotAllowed         = "not_allowed"         // The value is not one of the allowed values
+	CodeMinValue           = "min_value"           // The number is too small
+	CodeMaxValue           = "max_value"           // The number is too large
+	CodeInvalidUUID        = "invalid_uuid"        // The value is not a UUID
+	CodeOutOfRange         = "out_of_range"        // The number doesn't fit the type of the field
+	CodeInvalidSelection   = "invalid_selection"   // A field selection expression can't be parsed
+	CodeEncryptionFailed   = "encryption_failed"   // An encrypted field couldn't be encrypted
+	CodeDecryptionFailed   = "decryption_failed"   // An encrypted field couldn't be decrypted
+	CodeNotEncrypted       = "not_encrypted"       // An encrypted field was sent as plaintext
+	CodeFileTooLarge       = "file_too_large"      // An uploaded file exceeds the maximum size
+	CodeInvalidFileType    = "invalid_file_type"   // An uploaded file has a media type or extension that isn't allowed
+	CodeValidationTimeout  = "validation_timeout"  // A context validation didn't finish before ValidationTimeout
+	CodeInvalidCurrency    = "invalid_currency"    // The value is not an ISO 4217 currency code, or not an accepted one
+	CodeInvalidAmount      = "invalid_amount"      // The amount of money is not a number, or has too many decimals
+	CodeInvalidGeometry    = "invalid_geometry"    // The value is not a valid GeoJSON geometry
+	CodeInvalidCoordinates = "invalid_coordinates" // A latitude or longitude is out of range
+	CodeExactlyOne         = "exactly_one"         // Not exactly one of a set of fields is set
+	CodeAtLeastOne         = "at_least_one"        // None of a set of fields is set
+	CodeAtMostOne          = "at_most_one"         // More than one of a set of mutually exclusive fields is set
+	CodeNotUnique          = "not_unique"          // A combination of fields that must be unique already exists
+	CodeDeprecated         = "deprecated"          // A deprecated field was sent (a warning)
+)
+
+// CodedError is an error carrying a machine-readable code. Validations return it to set the
+// Code of the resulting ValidationError; its message is what Error returns.
+type CodedError struct {
+	Code     string
+	Message  string
+	Params   Params   // Parameters of the message, used to translate it
+	Err      error    // Underlying error, if any
+	Severity Severity // SeverityWarning for errors returned by NewWarning
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of the error's code.
+func (e *CodedError) Is(target error) bool {
+	return isCode(e.Code, target)
+}
+
+// NewCodedError returns a *CodedError with the given code and message.
+func NewCodedError(code, message string) error {
+	return &CodedError{Code: code, Message: message}
+}
+
+// Params holds the parameters of an error message, such as the limit of CodeMinLength, by name.
+// Translators insert them into the translated message.
+type Params map[string]interface{}
+
+// NewCodedErrorWithParams returns a *CodedError with the given code, message and parameters, e.g.
+// NewCodedErrorWithParams(CodeMinLength, "length must be at least 3", Params{"min": 3}).
+func NewCodedErrorWithParams(code, message string, params Params) error {
+	return &CodedError{Code: code, Message: message, Params: params}
+}
+
+// ErrorParams returns the parameters of the message of err, looking through wrapped errors
+// depth-first like errors.Is, including the failures of a *ValidationErrors, or nil if it doesn't
+// carry any. The outermost parameters win.
+func ErrorParams(err error) Params {
+	var params Params
+	walkErrors(err, func(err error) bool {
+		switch e := err.(type) {
+		case *CodedError:
+			params = e.Params
+		case *ValidationError:
+			params = e.Params
+		case *ConstraintError:
+			params = e.Params
+		}
+		return params != nil
+	})
+	return params
+}
+
+// ErrorCode returns the machine-readable code of err, looking through wrapped errors depth-first
+// like errors.Is, including the failures of a *ValidationErrors, or "" if it doesn't carry one.
+// The outermost code wins.
+func ErrorCode(err error) string {
+	var code string
+	walkErrors(err, func(err error) bool {
+		switch e := err.(type) {
+		case *CodedError:
+			code = e.Code
+		case *ValidationError:
+			code = e.Code
+		case *TransformationError:
+			code = e.Code
+		case *ConstraintError:
+			code = e.Code
+		case *InputTypeError:
+			code = CodeNotObject
+		}
+		return code != ""
+	})
+	return code
+}
+
+// walkErrors calls visit with err and the errors it wraps, through both Unwrap() error and
+// Unwrap() []error, in the order errors.Is visits them, until visit returns true. It reports
+// whether visit did.
+func walkErrors(err error, visit func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+	if visit(err) {
+		return true
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrors(e.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if walkErrors(inner, visit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validationCode returns the code of an error returned by a validation, CodeInvalid when it has none.
+func validationCode(err error) string {
+	if code := ErrorCode(err); code != "" {
+		return code
+	}
+	return CodeInvalid
+}
+
 // SerializationError represents an error that occurred during serialization or deserialization.
 type SerializationError struct {
 	Message string
+	Err     error // Underlying error, e.g. from the JSON or YAML library
 }
 
 func (e *SerializationError) Error() string {
 	return fmt.Sprintf("Serialization error: %s", e.Message)
 }
+
+// Unwrap returns the underlying error.
+func (e *SerializationError) Unwrap() error {
+	return e.Err
+}
+
+// InputTypeError reports data that can't be serialized into an object: a scalar, or a slice or an
+// array, whose elements are serialized with SerializeMany. It matches ErrTypeMismatch.
+type InputTypeError struct {
+	Type    reflect.Type
+	Message string
+}
+
+func (e *InputTypeError) Error() string {
+	return fmt.Sprintf("Input type error for %v: %s", e.Type, e.Message)
+}
+
+// Is reports whether target is ErrTypeMismatch.
+func (e *InputTypeError) Is(target error) bool {
+	return isCode(CodeNotObject, target)
+}
+
+// newInputTypeError reports that data of type t isn't serialized into an object.
+func newInputTypeError(t reflect.Type) error {
+	kind := baseType(t).Kind()
+	if kind == reflect.Slice || kind == reflect.Array {
+		return &InputTypeError{Type: t, Message: "expected a struct or a map, got a list; use SerializeMany to serialize its elements"}
+	}
+	return &InputTypeError{Type: t, Message: fmt.Sprintf("expected a struct or a map, got %s", kind)}
+}
+
+// PatchError represents an error that occurred while applying or generating a patch.
+type PatchError struct {
+	Op      string
+	Path    string
+	Message string
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("Patch error on '%s' operation at '%s': %s", e.Op, e.Path, e.Message)
+}
+
+// ValidationSetError groups the errors found while validating a ValidationSet, keyed by object name.
+type ValidationSetError struct {
+	Errors map[string][]error
+}
+
+func (e *ValidationSetError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, err := range e.Errors[name] {
+			messages = append(messages, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return fmt.Sprintf("Validation set error: %s", strings.Join(messages, "; "))
+}
+
+// XSDError reports an element or attribute of an XML document that doesn't conform to an XMLSchema.
+type XSDError struct {
+	Path    string // Path of the element, e.g. "/order/items/item[2]"
+	Line    int    // Line of the element in the document
+	Message string
+}
+
+func (e *XSDError) Error() string {
+	return fmt.Sprintf("XSD error at '%s' (line %d): %s", e.Path, e.Line, e.Message)
+}
+
+// XSDValidationError groups the errors found while validating an XML document against an XMLSchema.
+type XSDValidationError struct {
+	Errors []*XSDError
+}
+
+func (e *XSDValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = fmt.Sprintf("%s (line %d): %s", err.Path, err.Line, err.Message)
+	}
+	return fmt.Sprintf("XSD validation error: %s", strings.Join(messages, "; "))
+}
+
+// FetchError represents an error that occurred while fetching a remote document.
+type FetchError struct {
+	URL        string
+	StatusCode int
+	Message    string
+	Err        error // Underlying error, e.g. from the HTTP client
+}
+
+func (e *FetchError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("Fetch error for '%s': %s (status: %d)", e.URL, e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("Fetch error for '%s': %s", e.URL, e.Message)
+}
+
+// Unwrap returns the underlying error.
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// BulkError represents an error that occurred while processing one item of a batch.
+type BulkError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("Bulk error on item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the failed item.
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorCodeAndParamsOfValidationErrors(t *testing.T) {
+	minLength := &ValidationError{Field: "name", Code: CodeMinLength, Params: Params{"min": 3}}
+	errs := &ValidationErrors{Errors: []error{errors.New("plain"), minLength, &ValidationError{Field: "age", Code: CodeRequired}}}
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantParams Params
+	}{
+		{"validation errors", errs, CodeMinLength, Params{"min": 3}},
+		{"wrapped validation errors", fmt.Errorf("create: %w", errs), CodeMinLength, Params{"min": 3}},
+		{"joined errors", errors.Join(errors.New("plain"), &ValidationError{Field: "age", Code: CodeRequired}), CodeRequired, nil},
+		{"no code", &ValidationErrors{Errors: []error{errors.New("plain")}}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.wantCode {
+				t.Errorf("ErrorCode() = %q, want %q", got, tt.wantCode)
+			}
+			if got := ErrorParams(tt.err); !reflect.DeepEqual(got, tt.wantParams) {
+				t.Errorf("ErrorParams() = %v, want %v", got, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestInputTypeError(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		wantList bool
+	}{
+		{"slice", []account{{ID: 1}}, true},
+		{"empty slice", []account{}, true},
+		{"array", [2]int{1, 2}, true},
+		{"pointer to slice", &[]int{1}, true},
+		{"nil pointer to slice", (*[]int)(nil), true},
+		{"number", 7, false},
+		{"string", "ana", false},
+		{"nil pointer to number", (*int)(nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&BaseSerializer{}).Serialize(tt.data)
+			var inputErr *InputTypeError
+			if !errors.As(err, &inputErr) {
+				t.Fatalf("Serialize() error = %v, want an InputTypeError", err)
+			}
+			if inputErr.Type != reflect.TypeOf(tt.data) {
+				t.Errorf("InputTypeError.Type = %v, want %T", inputErr.Type, tt.data)
+			}
+			if !errors.Is(err, ErrTypeMismatch) || ErrorCode(err) != CodeNotObject {
+				t.Errorf("Serialize() error = %v (%s), want ErrTypeMismatch with code %s", err, ErrorCode(err), CodeNotObject)
+			}
+			if got := strings.Contains(err.Error(), "SerializeMany"); got != tt.wantList {
+				t.Errorf("Serialize() error = %q, want a hint to SerializeMany: %v", err, tt.wantList)
+			}
+		})
+	}
+
+	for _, data := range []interface{}{nil, (*account)(nil), (*map[string]interface{})(nil)} {
+		if got, err := (&BaseSerializer{}).Serialize(data); err != nil || got != nil {
+			t.Errorf("Serialize(%#v) = %v, %v, want nil", data, got, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package serializer
+
+import "reflect"
+
+// isFlat reports whether a struct with the given fields is flat: every field is a boolean, number
+// or string declared on the struct itself, without custom marshaling or the ",string" option.
+// Flat structs skip the per-field checks of encodeValue, which can't apply to them.
+func isFlat(fields []schemaField) bool {
+	for i := range fields {
+		field := &fields[i]
+		if len(field.index) != 1 || field.quoted || !isPlainScalar(field.typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPlainScalar reports whether values of t are encoded by encodeScalar alone.
+func isPlainScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+	default:
+		return false
+	}
+	for _, custom := range []reflect.Type{marshalerType, jsonMarshalerType, textMarshalerType} {
+		if t.Implements(custom) || reflect.PtrTo(t).Implements(custom) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeFlat converts a flat struct into a map, reading its fields by their cached index.
+func encodeFlat(v reflect.Value, schema *structSchema, numbers bool) (interface{}, error) {
+	result := make(map[string]interface{}, len(schema.fields))
+	for i := range schema.fields {
+		field := &schema.fields[i]
+		fv := v.Field(field.index[0])
+		if field.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		value, err := encodeScalar(fv, numbers)
+		if err != nil {
+			return nil, err
+		}
+		result[field.name] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package serializer
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type flatStruct struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name,omitempty"`
+	Ratio  float32 `json:"ratio"`
+	Active bool    `json:"active"`
+}
+
+// level is a scalar encoded by its MarshalText method.
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+func TestIsFlat(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want bool
+	}{
+		{"scalars", reflect.TypeOf(flatStruct{}), true},
+		{"nested struct", reflect.TypeOf(struct{ A account }{}), false},
+		{"pointer", reflect.TypeOf(struct{ A *int }{}), false},
+		{"string option", reflect.TypeOf(struct {
+			A int `json:",string"`
+		}{}), false},
+		{"promoted field", reflect.TypeOf(struct{ flatStruct }{}), false},
+		{"Marshaler", reflect.TypeOf(struct{ A cents }{}), false},
+		{"TextMarshaler", reflect.TypeOf(struct{ A level }{}), false},
+		{"TextMarshaler slice", reflect.TypeOf(struct{ A net.IP }{}), false},
+		{"number string", reflect.TypeOf(struct{ A json.Number }{}), true},
+		{"Stringer", reflect.TypeOf(struct{ A time.Month }{}), true},
+		{"Duration", reflect.TypeOf(struct{ A time.Duration }{}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFlat(schemaFor(tt.typ).fields); got != tt.want {
+				t.Errorf("isFlat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFlat(t *testing.T) {
+	v := reflect.ValueOf(flatStruct{ID: 3, Ratio: 0.5, Active: true})
+	tests := []struct {
+		name    string
+		numbers bool
+		want    interface{}
+	}{
+		{"floats", false, map[string]interface{}{"id": 3.0, "ratio": 0.5, "active": true}},
+		{"numbers", true, map[string]interface{}{"id": json.Number("3"), "ratio": json.Number("0.5"), "active": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeFlat(v, schemaFor(v.Type()), tt.numbers)
+			if err != nil {
+				t.Fatalf("encodeFlat() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encodeFlat() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// FieldMeta holds presentation metadata for a field, used to order and group fields in
+// ordered output, generated schemas and generated documentation.
+type FieldMeta struct {
+	Order       int    // Position within the group; lower values come first
+	Group       string // Group label; groups are ordered as listed in FieldGroups
+	Title       string // Human-readable name
+	Description string // Longer explanation
+}
+
+// OrderedKeys sorts keys for presentation: by group (in FieldGroups order, ungrouped fields
+// last), then by Order, then alphabetically. Keys without metadata keep their relative
+// alphabetical order after the ones that have it within the same group.
+func (s *BaseSerializer) OrderedKeys(keys []string) []string {
+	groupRank := make(map[string]int, len(s.FieldGroups))
+	for i, group := range s.FieldGroups {
+		groupRank[group] = i
+	}
+	rank := func(key string) (int, bool, int) {
+		meta, ok := s.fieldMeta(key)
+		group, grouped := groupRank[meta.Group]
+		if !grouped {
+			group = len(s.FieldGroups)
+		}
+		return group, !ok, meta.Order
+	}
+
+	ordered := append([]string(nil), keys...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		gi, unknownI, oi := rank(ordered[i])
+		gj, unknownJ, oj := rank(ordered[j])
+		if gi != gj {
+			return gi < gj
+		}
+		if unknownI != unknownJ {
+			return !unknownI
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return ordered[i] < ordered[j]
+	})
+	return ordered
+}
+
+// SerializeOrderedJSON serializes data and encodes it as a JSON object whose top-level keys follow OrderedKeys.
+func (s *BaseSerializer) SerializeOrderedJSON(data interface{}) ([]byte, error) {
+	result, err := s.Serialize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(result))
+	for key := range result {
+		keys = append(keys, key)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range s.OrderedKeys(keys) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(result[key])
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to encode field '%s': %v", key, err)}
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return s.JSONEncoding.format(buf.Bytes())
+}
+
+// fieldMeta returns the metadata of a field, accepting either its original or its converted key.
+func (s *BaseSerializer) fieldMeta(key string) (FieldMeta, bool) {
+	if meta, ok := s.FieldMeta[key]; ok {
+		return meta, true
+	}
+	if s.KeyNaming != NamingDefault {
+		for field, meta := range s.FieldMeta {
+			if s.convertKey(field) == key {
+				return meta, true
+			}
+		}
+	}
+	return FieldMeta{}, false
+}
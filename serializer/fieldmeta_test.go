@@ -0,0 +1,68 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderedKeys(t *testing.T) {
+	s := &BaseSerializer{
+		FieldGroups: []string{"identity", "details"},
+		FieldMeta: map[string]FieldMeta{
+			"id":         {Group: "identity", Order: 1},
+			"Name":       {Group: "identity", Order: 2},
+			"role":       {Group: "details", Order: 1},
+			"created_at": {Group: "audit"},
+			"bio":        {Group: "details"},
+		},
+	}
+	tests := []struct {
+		name   string
+		naming NamingStrategy
+		keys   []string
+		want   []string
+	}{
+		{"groups, order and names", NamingDefault, []string{"zeta", "role", "created_at", "bio", "Name", "id", "alpha"},
+			[]string{"id", "Name", "bio", "role", "created_at", "alpha", "zeta"}},
+		{"converted keys", NamingSnakeCase, []string{"name", "id"}, []string{"id", "name"}},
+		{"no metadata", NamingDefault, []string{"b", "a"}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := *s
+			view.KeyNaming = tt.naming
+			keys := append([]string(nil), tt.keys...)
+			if got := view.OrderedKeys(keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderedKeys() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("OrderedKeys() reordered its input")
+			}
+		})
+	}
+}
+
+func TestSerializeOrderedJSON(t *testing.T) {
+	s := &BaseSerializer{FieldMeta: map[string]FieldMeta{"role": {Order: 1}, "name": {Order: 2}}}
+	tests := []struct {
+		name     string
+		encoding JSONEncoding
+		want     string
+	}{
+		{"compact", JSONEncoding{}, `{"role":"\u003cadmin\u003e","name":"ana","id":7}`},
+		{"indented", JSONEncoding{Indent: " ", NoEscapeHTML: true}, "{\n \"role\": \"<admin>\",\n \"name\": \"ana\",\n \"id\": 7\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := *s
+			view.JSONEncoding = tt.encoding
+			got, err := view.SerializeOrderedJSON(account{ID: 7, Name: "ana", Role: "<admin>"})
+			if err != nil {
+				t.Fatalf("SerializeOrderedJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SerializeOrderedJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
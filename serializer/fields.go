@@ -1,24 +1,434 @@
 package serializer
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Field interface for validating field values.
 type Field interface {
 	Validate(value interface{}) error
 }
 
+// Coercer is implemented by fields that convert incoming values (e.g. "42" to 42) before they
+// are validated and deserialized.
+type Coercer interface {
+	Coerce(value interface{}) (interface{}, error)
+}
+
+// Representer is implemented by fields that control how their value is written on Serialize.
+type Representer interface {
+	Represent(value interface{}) (interface{}, error)
+}
+
+// RequiredField is implemented by fields that can be marked as required.
+type RequiredField interface {
+	IsRequired() bool
+}
+
+// Ptr returns a pointer to value, for the optional bounds of IntField and FloatField.
+func Ptr[T any](value T) *T {
+	return &value
+}
+
 // StringField represents a string with validation options.
 type StringField struct {
 	MaxLength int
+	Required  bool
 }
 
 func (f StringField) Validate(value interface{}) error {
 	str, ok := value.(string)
 	if !ok {
-		return fmt.Errorf("not a valid string")
+		return NewCodedError(CodeInvalidType, "not a valid string")
 	}
 	if len(str) > f.MaxLength {
-		return fmt.Errorf("string exceeds max length")
+		return NewCodedErrorWithParams(CodeMaxLength, "string exceeds max length", Params{"max": f.MaxLength})
+	}
+	return nil
+}
+
+func (f StringField) IsRequired() bool { return f.Required }
+
+// IntField is a whole number, optionally bounded. Numeric strings are coerced.
+type IntField struct {
+	Min      *int64
+	Max      *int64
+	Required bool
+}
+
+func (f IntField) Validate(value interface{}) error {
+	coerced, err := f.Coerce(value)
+	if err != nil {
+		return err
+	}
+	n := coerced.(int64)
+	if f.Min != nil && n < *f.Min {
+		return NewCodedErrorWithParams(CodeMinValue, fmt.Sprintf("value must be at least %d", *f.Min), Params{"min": *f.Min})
+	}
+	if f.Max != nil && n > *f.Max {
+		return NewCodedErrorWithParams(CodeMaxValue, fmt.Sprintf("value must be at most %d", *f.Max), Params{"max": *f.Max})
+	}
+	return nil
+}
+
+func (f IntField) Coerce(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && v >= -1<<63 && v < 1<<63 {
+			return int64(v), nil
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return n, nil
+		}
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	}
+	return nil, NewCodedError(CodeInvalidType, "value is not an integer")
+}
+
+func (f IntField) IsRequired() bool { return f.Required }
+
+// FloatField is a number, optionally bounded. Numeric strings are coerced.
+type FloatField struct {
+	Min      *float64
+	Max      *float64
+	Required bool
+}
+
+func (f FloatField) Validate(value interface{}) error {
+	coerced, err := f.Coerce(value)
+	if err != nil {
+		return err
+	}
+	n := coerced.(float64)
+	if f.Min != nil && n < *f.Min {
+		return NewCodedErrorWithParams(CodeMinValue, fmt.Sprintf("value must be at least %v", *f.Min), Params{"min": *f.Min})
+	}
+	if f.Max != nil && n > *f.Max {
+		return NewCodedErrorWithParams(CodeMaxValue, fmt.Sprintf("value must be at most %v", *f.Max), Params{"max": *f.Max})
+	}
+	return nil
+}
+
+func (f FloatField) Coerce(value interface{}) (interface{}, error) {
+	if n, ok := numberValue(value); ok {
+		return n, nil
+	}
+	if str, ok := value.(string); ok {
+		if n, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+			return n, nil
+		}
+	}
+	return nil, NewCodedError(CodeInvalidType, "value is not a number")
+}
+
+func (f FloatField) IsRequired() bool { return f.Required }
+
+// BoolField is a boolean. The strings "true", "false", "1" and "0" and the numbers 1 and 0 are coerced.
+type BoolField struct {
+	Required bool
+}
+
+func (f BoolField) Validate(value interface{}) error {
+	_, err := f.Coerce(value)
+	return err
+}
+
+func (f BoolField) Coerce(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, nil
+		}
+	default:
+		if n, ok := numberValue(value); ok && (n == 0 || n == 1) {
+			return n == 1, nil
+		}
+	}
+	return nil, NewCodedError(CodeInvalidType, "value is not a boolean")
+}
+
+func (f BoolField) IsRequired() bool { return f.Required }
+
+// DateField is a date written in Layout ("2006-01-02" when empty). RFC 3339 times are accepted
+// on input; deserialized values are times, so the target field is usually a time.Time.
+type DateField struct {
+	Layout   string
+	Required bool
+}
+
+func (f DateField) Validate(value interface{}) error {
+	_, err := f.Coerce(value)
+	return err
+}
+
+func (f DateField) Coerce(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range []string{f.layout(), time.RFC3339Nano} {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return nil, NewCodedErrorWithParams(CodeInvalidTime, fmt.Sprintf("value is not a date in the format %s", f.layout()), Params{"layout": f.layout()})
+}
+
+func (f DateField) Represent(value interface{}) (interface{}, error) {
+	t, err := f.Coerce(value)
+	if err != nil {
+		return nil, err
+	}
+	return t.(time.Time).Format(f.layout()), nil
+}
+
+func (f DateField) IsRequired() bool { return f.Required }
+
+// layout returns the layout dates are written in.
+func (f DateField) layout() string {
+	if f.Layout == "" {
+		return "2006-01-02"
+	}
+	return f.Layout
+}
+
+// EmailField is an e-mail address. Surrounding white space is trimmed.
+type EmailField struct {
+	Required bool
+}
+
+func (f EmailField) Validate(value interface{}) error {
+	coerced, err := f.Coerce(value)
+	if err != nil {
+		return err
+	}
+	address, err := mail.ParseAddress(coerced.(string))
+	if err != nil || address.Address != coerced.(string) {
+		return NewCodedError(CodeInvalidEmail, "invalid email format")
+	}
+	return nil
+}
+
+func (f EmailField) Coerce(value interface{}) (interface{}, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, NewCodedError(CodeInvalidType, "value is not a string")
+	}
+	return strings.TrimSpace(str), nil
+}
+
+func (f EmailField) IsRequired() bool { return f.Required }
+
+// UUIDField is a UUID in its canonical 36 character form. Input is trimmed and lower-cased.
+type UUIDField struct {
+	Required bool
+}
+
+func (f UUIDField) Validate(value interface{}) error {
+	coerced, err := f.Coerce(value)
+	if err != nil {
+		return err
+	}
+	str := coerced.(string)
+	if len(str) != 36 {
+		return NewCodedError(CodeInvalidUUID, "invalid UUID format")
+	}
+	for i, c := range str {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return NewCodedError(CodeInvalidUUID, "invalid UUID format")
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", c) {
+				return NewCodedError(CodeInvalidUUID, "invalid UUID format")
+			}
+		}
 	}
 	return nil
 }
+
+func (f UUIDField) Coerce(value interface{}) (interface{}, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, NewCodedError(CodeInvalidType, "value is not a string")
+	}
+	return strings.ToLower(strings.TrimSpace(str)), nil
+}
+
+func (f UUIDField) IsRequired() bool { return f.Required }
+
+// SliceField is a list whose elements are all of the Elem field type. MaxItems is ignored when 0.
+type SliceField struct {
+	Elem        Field
+	MinItems    int
+	MaxItems    int
+	UniqueItems bool // Reject lists with the same item twice
+	Required    bool
+}
+
+func (f SliceField) Validate(value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return NewCodedError(CodeInvalidType, "value is not a list")
+	}
+	if len(items) < f.MinItems {
+		return NewCodedErrorWithParams(CodeMinLength, fmt.Sprintf("list must have at least %d items", f.MinItems), Params{"min": f.MinItems})
+	}
+	if f.MaxItems > 0 && len(items) > f.MaxItems {
+		return NewCodedErrorWithParams(CodeMaxLength, fmt.Sprintf("list must have at most %d items", f.MaxItems), Params{"max": f.MaxItems})
+	}
+	if f.UniqueItems {
+		if err := UniqueItems(items); err != nil {
+			return err
+		}
+	}
+	if f.Elem == nil {
+		return nil
+	}
+	for i, item := range items {
+		if err := f.Elem.Validate(item); err != nil {
+			return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("item %d: %v", i, err), Err: err}
+		}
+	}
+	return nil
+}
+
+func (f SliceField) Coerce(value interface{}) (interface{}, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, NewCodedError(CodeInvalidType, "value is not a list")
+	}
+	return mapItems(items, f.Elem, coerceValue)
+}
+
+func (f SliceField) Represent(value interface{}) (interface{}, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value, nil
+	}
+	return mapItems(items, f.Elem, representValue)
+}
+
+func (f SliceField) IsRequired() bool { return f.Required }
+
+// MapField is an object whose values are all of the Values field type. KeyPattern and MaxEntries
+// are ignored when empty and 0.
+type MapField struct {
+	Values     Field
+	KeyPattern string // Regular expression every key must match
+	MaxEntries int
+	Required   bool
+}
+
+func (f MapField) Validate(value interface{}) error {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return NewCodedError(CodeNotObject, "value is not an object")
+	}
+	if f.MaxEntries > 0 && len(object) > f.MaxEntries {
+		return NewCodedErrorWithParams(CodeMaxLength, fmt.Sprintf("object must have at most %d entries", f.MaxEntries), Params{"max": f.MaxEntries})
+	}
+	if f.KeyPattern != "" {
+		re, err := keyPattern(f.KeyPattern)
+		if err != nil {
+			return &CodedError{Code: CodeInvalid, Message: fmt.Sprintf("invalid key pattern: %v", err), Err: err}
+		}
+		if err := matchKeys(object, re); err != nil {
+			return err
+		}
+	}
+	if f.Values == nil {
+		return nil
+	}
+	for _, key := range objectKeys(object) {
+		item := object[key]
+		if err := f.Values.Validate(item); err != nil {
+			return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("key '%s': %v", key, err), Err: err}
+		}
+	}
+	return nil
+}
+
+func (f MapField) Coerce(value interface{}) (interface{}, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, NewCodedError(CodeNotObject, "value is not an object")
+	}
+	return mapValues(object, f.Values, coerceValue)
+}
+
+func (f MapField) Represent(value interface{}) (interface{}, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return value, nil
+	}
+	return mapValues(object, f.Values, representValue)
+}
+
+func (f MapField) IsRequired() bool { return f.Required }
+
+// coerceValue coerces value with field when it is a Coercer.
+func coerceValue(field Field, value interface{}) (interface{}, error) {
+	if c, ok := field.(Coercer); ok {
+		return c.Coerce(value)
+	}
+	return value, nil
+}
+
+// representValue represents value with field when it is a Representer.
+func representValue(field Field, value interface{}) (interface{}, error) {
+	if r, ok := field.(Representer); ok {
+		return r.Represent(value)
+	}
+	return value, nil
+}
+
+// mapItems converts every item of a list with convert.
+func mapItems(items []interface{}, field Field, convert func(Field, interface{}) (interface{}, error)) (interface{}, error) {
+	if field == nil {
+		return items, nil
+	}
+	converted := make([]interface{}, len(items))
+	for i, item := range items {
+		value, err := convert(field, item)
+		if err != nil {
+			return nil, &CodedError{Code: validationCode(err), Message: fmt.Sprintf("item %d: %v", i, err), Err: err}
+		}
+		converted[i] = value
+	}
+	return converted, nil
+}
+
+// mapValues converts every value of an object with convert.
+func mapValues(object map[string]interface{}, field Field, convert func(Field, interface{}) (interface{}, error)) (interface{}, error) {
+	if field == nil {
+		return object, nil
+	}
+	converted := make(map[string]interface{}, len(object))
+	for key, item := range object {
+		value, err := convert(field, item)
+		if err != nil {
+			return nil, &CodedError{Code: validationCode(err), Message: fmt.Sprintf("key '%s': %v", key, err), Err: err}
+		}
+		converted[key] = value
+	}
+	return converted, nil
+}
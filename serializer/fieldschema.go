@@ -0,0 +1,61 @@
+package serializer
+
+import "context"
+
+// validateSchema checks data against the Schema fields and reports missing required fields,
+// passing each failure to report. It returns false when report stops the validation.
+func (s *BaseSerializer) validateSchema(ctx context.Context, data map[string]interface{}, report func(error) bool) bool {
+	for name, field := range s.Schema {
+		value, exists := data[name]
+		if !exists || value == nil {
+			if required(field) && !report(&ValidationError{Field: name, Message: s.translate(ctx, CodeRequired, nil, "field is missing"), Code: CodeRequired}) {
+				return false
+			}
+			continue
+		}
+		if err := field.Validate(value); err != nil {
+			if err := s.validationFailure(ctx, name, value, err); err != nil && !report(err) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// coerceSchema replaces the input values of Coercer fields with their coerced form.
+func (s *BaseSerializer) coerceSchema(input map[string]interface{}) error {
+	for name, field := range s.Schema {
+		value, exists := input[name]
+		if !exists || value == nil {
+			continue
+		}
+		coerced, err := coerceValue(field, value)
+		if err != nil {
+			return &ValidationError{Field: name, Value: value, Message: err.Error(), Code: validationCode(err), Params: ErrorParams(err), Err: err}
+		}
+		input[name] = coerced
+	}
+	return nil
+}
+
+// representSchema replaces the serialized values of Representer fields with their representation.
+func (s *BaseSerializer) representSchema(result map[string]interface{}) error {
+	for name, field := range s.Schema {
+		value, exists := result[name]
+		if !exists || value == nil {
+			continue
+		}
+		represented, err := representValue(field, value)
+		if err != nil {
+			return &TransformationError{Field: name, Value: value, Message: err.Error(), Code: validationCode(err), Err: err}
+		}
+		result[name] = represented
+	}
+	return nil
+}
+
+// required reports whether a Schema field must be present.
+func required(field Field) bool {
+	r, ok := field.(RequiredField)
+	return ok && r.IsRequired()
+}
@@ -0,0 +1,95 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// upperField is a required string coerced to upper case and represented in lower case.
+type upperField struct{}
+
+func (upperField) Validate(value interface{}) error {
+	if s, ok := value.(string); !ok || s == "" {
+		return NewCodedError(CodeInvalidType, "not a code")
+	}
+	return nil
+}
+
+func (upperField) Coerce(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, NewCodedError(CodeInvalidType, "not a code")
+	}
+	return strings.ToUpper(s), nil
+}
+
+func (upperField) Represent(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("not a code")
+	}
+	return strings.ToLower(s), nil
+}
+
+func (upperField) IsRequired() bool { return true }
+
+func TestValidateSchema(t *testing.T) {
+	s := &BaseSerializer{Schema: map[string]Field{"role": upperField{}, "id": IntField{Min: Ptr[int64](1)}}}
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		wantField string
+		wantCode  string
+	}{
+		{"valid", map[string]interface{}{"role": "admin", "id": 1.0}, "", ""},
+		{"optional field missing", map[string]interface{}{"role": "admin"}, "", ""},
+		{"required field missing", map[string]interface{}{"id": 1.0}, "role", CodeRequired},
+		{"required field null", map[string]interface{}{"role": nil}, "role", CodeRequired},
+		{"invalid", map[string]interface{}{"role": "admin", "id": 0.0}, "id", CodeMinValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(tt.data)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField || validationErr.Code != tt.wantCode {
+				t.Errorf("Validate() error = %v, want a ValidationError of %q with code %q", err, tt.wantField, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSchemaCoerceAndRepresent(t *testing.T) {
+	s := &BaseSerializer{Schema: map[string]Field{"role": upperField{}, "name": upperField{}}}
+
+	var out account
+	if err := s.Deserialize(map[string]interface{}{"role": "admin", "name": "ana"}, &out); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if want := (account{Name: "ANA", Role: "ADMIN"}); out != want {
+		t.Errorf("Deserialize() = %+v, want %+v", out, want)
+	}
+	var validationErr *ValidationError
+	if err := s.Deserialize(map[string]interface{}{"role": 1.0, "name": "ana"}, &out); !errors.As(err, &validationErr) || validationErr.Field != "role" || validationErr.Code != CodeInvalidType {
+		t.Errorf("Deserialize() error = %v, want an invalid_type error of 'role'", err)
+	}
+
+	got, err := s.Serialize(account{ID: 7, Name: "ANA", Role: "ADMIN"})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if want := map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize() = %#v, want %#v", got, want)
+	}
+	var transformationErr *TransformationError
+	if _, err := (&BaseSerializer{Schema: map[string]Field{"id": upperField{}}}).Serialize(account{ID: 7}); !errors.As(err, &transformationErr) || transformationErr.Field != "id" {
+		t.Errorf("Serialize() error = %v, want a TransformationError of 'id'", err)
+	}
+}
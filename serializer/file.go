@@ -0,0 +1,170 @@
+package serializer
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// FileField is an uploaded file, or a list of them, bound from a multipart form by
+// DeserializeFromMultipart. Its validation checks the size, the media type and the extension of
+// every file; bounds left at their zero value aren't checked.
+//
+// The media type of a file is sniffed from its first 512 bytes with http.DetectContentType; the
+// Content-Type sent by the client is only used when the content isn't recognized. Text formats
+// such as CSV are sniffed as "text/plain", so allow that type for them.
+//
+// On Serialize, files are represented by their metadata: {"name", "size", "content_type", "url"}.
+// The serialized value can be a *multipart.FileHeader, the name or path of a stored file, or an
+// object with a "name" key.
+type FileField struct {
+	MaxSize      int64                    // Maximum size of each file in bytes
+	AllowedTypes []string                 // Allowed media types, e.g. "image/png", or "image/*" for every image type
+	Extensions   []string                 // Allowed file name extensions, e.g. ".png"; case is ignored
+	MaxFiles     int                      // Maximum number of files uploaded under the field
+	URL          func(name string) string // URL of a file in the output, given its name or path
+	Required     bool
+}
+
+func (f FileField) Validate(value interface{}) error {
+	var files []*multipart.FileHeader
+	switch v := value.(type) {
+	case *multipart.FileHeader:
+		files = []*multipart.FileHeader{v}
+	case []*multipart.FileHeader:
+		files = v
+	default:
+		return NewCodedError(CodeInvalidType, "value is not an uploaded file")
+	}
+	if f.MaxFiles > 0 && len(files) > f.MaxFiles {
+		return NewCodedErrorWithParams(CodeMaxLength, fmt.Sprintf("at most %d files can be uploaded", f.MaxFiles), Params{"max": f.MaxFiles})
+	}
+	for _, file := range files {
+		if err := f.validateFile(file); err != nil {
+			if len(files) == 1 {
+				return err
+			}
+			return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("file '%s': %v", file.Filename, err), Err: err}
+		}
+	}
+	return nil
+}
+
+func (f FileField) Represent(value interface{}) (interface{}, error) {
+	if items, ok := value.([]interface{}); ok {
+		return mapItems(items, FileField{URL: f.URL}, representValue)
+	}
+
+	info := make(map[string]interface{})
+	switch v := value.(type) {
+	case string:
+		info["name"] = path.Base(v)
+		f.addURL(info, v)
+	case map[string]interface{}:
+		// A *multipart.FileHeader, as converted by encoding/json, or stored file metadata
+		if name, ok := v["Filename"].(string); ok {
+			info["name"] = name
+			info["size"] = v["Size"]
+			if header, ok := v["Header"].(map[string]interface{}); ok {
+				if types, ok := header["Content-Type"].([]interface{}); ok && len(types) > 0 {
+					info["content_type"] = types[0]
+				}
+			}
+			f.addURL(info, name)
+			break
+		}
+		name, ok := v["name"].(string)
+		if !ok {
+			return nil, NewCodedError(CodeInvalidType, "value is not a file")
+		}
+		for key, item := range v {
+			info[key] = item
+		}
+		f.addURL(info, name)
+	default:
+		return nil, NewCodedError(CodeInvalidType, "value is not a file")
+	}
+	return info, nil
+}
+
+func (f FileField) IsRequired() bool { return f.Required }
+
+// validateFile checks a single uploaded file.
+func (f FileField) validateFile(file *multipart.FileHeader) error {
+	if file == nil {
+		return NewCodedError(CodeInvalidType, "value is not an uploaded file")
+	}
+	if f.MaxSize > 0 && file.Size > f.MaxSize {
+		return NewCodedErrorWithParams(CodeFileTooLarge, fmt.Sprintf("file exceeds %d bytes", f.MaxSize), Params{"max": f.MaxSize})
+	}
+	if len(f.Extensions) > 0 {
+		extension := strings.ToLower(path.Ext(file.Filename))
+		allowed := false
+		for _, candidate := range f.Extensions {
+			if strings.ToLower(candidate) == extension || "."+strings.ToLower(candidate) == extension {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return NewCodedErrorWithParams(CodeInvalidFileType, fmt.Sprintf("file extension must be one of %s", strings.Join(f.Extensions, ", ")), Params{"allowed": f.Extensions})
+		}
+	}
+	if len(f.AllowedTypes) > 0 {
+		mediaType, err := detectMediaType(file)
+		if err != nil {
+			return &CodedError{Code: CodeInvalidFileType, Message: fmt.Sprintf("file can't be read: %v", err), Err: err}
+		}
+		if !mediaTypeAllowed(mediaType, f.AllowedTypes) {
+			return NewCodedErrorWithParams(CodeInvalidFileType, fmt.Sprintf("file type %s is not allowed", mediaType), Params{"type": mediaType})
+		}
+	}
+	return nil
+}
+
+// addURL sets the "url" of file metadata when the field has a URL function.
+func (f FileField) addURL(info map[string]interface{}, name string) {
+	if f.URL != nil {
+		info["url"] = f.URL(name)
+	}
+}
+
+// detectMediaType sniffs the media type of an uploaded file, falling back to the Content-Type
+// declared by the client when the content isn't recognized.
+func detectMediaType(file *multipart.FileHeader) (string, error) {
+	content, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if mediaType == "application/octet-stream" {
+		if declared, _, err := mime.ParseMediaType(file.Header.Get("Content-Type")); err == nil {
+			mediaType = declared
+		}
+	}
+	return strings.ToLower(mediaType), nil
+}
+
+// mediaTypeAllowed reports whether mediaType matches one of allowed, where "type/*" matches every
+// subtype of type.
+func mediaTypeAllowed(mediaType string, allowed []string) bool {
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if candidate == mediaType || candidate == "*/*" ||
+			(strings.HasSuffix(candidate, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(candidate, "*"))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package serializer
+
+import (
+	"mime/multipart"
+	"reflect"
+	"testing"
+)
+
+func TestFileFieldValidate(t *testing.T) {
+	form := uploadForm(t, nil, map[string][][3]string{
+		"image": {{"photo.PNG", "application/octet-stream", string(pngHeader) + "data"}},
+		"data":  {{"rows.csv", "text/csv", "a,b\n1,2\n"}},
+		"blob":  {{"blob.bin", "application/x-custom", "\x00\x01\x02"}},
+		"many":  {{"a.png", "image/png", string(pngHeader)}, {"b.txt", "text/plain", "text"}},
+	})
+	image, csv, blob, many := form.File["image"][0], form.File["data"][0], form.File["blob"][0], form.File["many"]
+	tests := []struct {
+		name     string
+		field    FileField
+		value    interface{}
+		wantCode string
+	}{
+		{"no bounds", FileField{}, image, ""},
+		{"sniffed type", FileField{AllowedTypes: []string{"image/*"}, Extensions: []string{"png"}}, image, ""},
+		{"declared type of unrecognized content", FileField{AllowedTypes: []string{"application/x-custom"}}, blob, ""},
+		{"text sniffed as plain text", FileField{AllowedTypes: []string{"text/plain"}, Extensions: []string{".csv"}}, csv, ""},
+		{"disallowed type", FileField{AllowedTypes: []string{"image/png"}}, csv, CodeInvalidFileType},
+		{"disallowed extension", FileField{Extensions: []string{".jpg"}}, image, CodeInvalidFileType},
+		{"too large", FileField{MaxSize: 4}, image, CodeFileTooLarge},
+		{"several files", FileField{MaxFiles: 2}, many, ""},
+		{"too many files", FileField{MaxFiles: 1}, many, CodeMaxLength},
+		{"one of several files", FileField{AllowedTypes: []string{"image/png"}}, many, CodeInvalidFileType},
+		{"not a file", FileField{}, "photo.png", CodeInvalidType},
+		{"nil file", FileField{}, []*multipart.FileHeader{nil}, CodeInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.Validate(tt.value)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if ErrorCode(err) != tt.wantCode {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFileFieldRepresent(t *testing.T) {
+	field := FileField{URL: func(name string) string { return "/files/" + name }}
+	tests := []struct {
+		name     string
+		value    interface{}
+		want     interface{}
+		wantCode string
+	}{
+		{"path", "uploads/a.png", map[string]interface{}{"name": "a.png", "url": "/files/uploads/a.png"}, ""},
+		{"file header", map[string]interface{}{"Filename": "a.png", "Size": 12.0, "Header": map[string]interface{}{"Content-Type": []interface{}{"image/png"}}},
+			map[string]interface{}{"name": "a.png", "size": 12.0, "content_type": "image/png", "url": "/files/a.png"}, ""},
+		{"metadata", map[string]interface{}{"name": "a.png", "size": 12.0}, map[string]interface{}{"name": "a.png", "size": 12.0, "url": "/files/a.png"}, ""},
+		{"list", []interface{}{"a.png", "b.png"}, []interface{}{
+			map[string]interface{}{"name": "a.png", "url": "/files/a.png"},
+			map[string]interface{}{"name": "b.png", "url": "/files/b.png"},
+		}, ""},
+		{"object without name", map[string]interface{}{"size": 12.0}, nil, CodeInvalidType},
+		{"number", 12.0, nil, CodeInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := field.Represent(tt.value)
+			if tt.wantCode != "" {
+				if ErrorCode(err) != tt.wantCode {
+					t.Errorf("Represent() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Represent() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
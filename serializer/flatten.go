@@ -0,0 +1,147 @@
+package serializer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten merges nested objects and lists into a single level, joining the keys of nested
+// values with dots: {"address": {"city": "Lima"}, "tags": ["a"]} becomes
+// {"address.city": "Lima", "tags.0": "a"}. Empty objects and lists are kept as values. Dots and
+// backslashes within keys are escaped with a backslash, so that {"example.com": 1} becomes
+// {"example\.com": 1} and can't collide with a nested key.
+func Flatten(data map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		flattenValue(flat, escapeFlatKey(key), value)
+	}
+	return flat
+}
+
+// flattenValue adds value to flat under prefix, recursing into non-empty objects and lists.
+func flattenValue(flat map[string]interface{}, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			break
+		}
+		for key, item := range v {
+			flattenValue(flat, prefix+"."+escapeFlatKey(key), item)
+		}
+		return
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		for i, item := range v {
+			flattenValue(flat, prefix+"."+strconv.Itoa(i), item)
+		}
+		return
+	}
+	flat[prefix] = value
+}
+
+// Unflatten is the inverse of Flatten: dotted keys become nested objects, and objects whose keys
+// are exactly the indexes 0 to n-1 become lists. It fails with a *ValidationError with
+// CodeInvalidKey when a key also has nested keys, e.g. "a" and "a.b", since one of the values would
+// be lost. Keys escaped by Flatten are unescaped, and a backslash that doesn't escape a dot or a
+// backslash is kept.
+func Unflatten(data map[string]interface{}) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	// Shorter keys first, so that a value set for a prefix is found by the nested keys below it
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	root := flatNode{}
+	for _, key := range keys {
+		parent := root
+		segments := splitFlatKey(key)
+		for i, segment := range segments[:len(segments)-1] {
+			value, exists := parent[segment]
+			child, ok := value.(flatNode)
+			if exists && !ok {
+				escaped := make([]string, i+1)
+				for j := range escaped {
+					escaped[j] = escapeFlatKey(segments[j])
+				}
+				prefix := strings.Join(escaped, ".")
+				return nil, &ValidationError{Field: prefix, Value: value, Message: fmt.Sprintf("key conflicts with the nested key '%s'", key), Code: CodeInvalidKey}
+			}
+			if !ok {
+				child = flatNode{}
+				parent[segment] = child
+			}
+			parent = child
+		}
+		parent[segments[len(segments)-1]] = data[key]
+	}
+
+	nested := make(map[string]interface{}, len(root))
+	for key, value := range root {
+		nested[key] = value
+		if node, ok := value.(flatNode); ok {
+			nested[key] = node.restore()
+		}
+	}
+	return nested, nil
+}
+
+// flatKeyEscaper escapes the separator of flattened keys.
+var flatKeyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// escapeFlatKey escapes the dots and backslashes of a key of a nested object.
+func escapeFlatKey(key string) string {
+	return flatKeyEscaper.Replace(key)
+}
+
+// splitFlatKey splits a flattened key at its unescaped dots and unescapes the segments.
+func splitFlatKey(key string) []string {
+	var segments []string
+	var segment strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '\\' && i+1 < len(key) && (key[i+1] == '.' || key[i+1] == '\\'):
+			i++
+			segment.WriteByte(key[i])
+		case c == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(c)
+		}
+	}
+	return append(segments, segment.String())
+}
+
+// flatNode is an object built by Unflatten from dotted keys.
+type flatNode map[string]interface{}
+
+// restore converts the node into an object, or into a list when its keys are list indexes.
+func (n flatNode) restore() interface{} {
+	object := make(map[string]interface{}, len(n))
+	for key, value := range n {
+		object[key] = value
+		if node, ok := value.(flatNode); ok {
+			object[key] = node.restore()
+		}
+	}
+
+	items := make([]interface{}, len(object))
+	for key, item := range object {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(items) || strconv.Itoa(i) != key {
+			return object
+		}
+		items[i] = item
+	}
+	return items
+}
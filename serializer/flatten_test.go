@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name   string
+		nested map[string]interface{}
+		flat   map[string]interface{}
+	}{
+		{"scalars", map[string]interface{}{"a": 1, "b": nil}, map[string]interface{}{"a": 1, "b": nil}},
+		{"objects", map[string]interface{}{"address": map[string]interface{}{"city": "Lima", "geo": map[string]interface{}{"lat": 1.5}}},
+			map[string]interface{}{"address.city": "Lima", "address.geo.lat": 1.5}},
+		{"lists", map[string]interface{}{"tags": []interface{}{"a", map[string]interface{}{"b": true}}},
+			map[string]interface{}{"tags.0": "a", "tags.1.b": true}},
+		{"empty values kept", map[string]interface{}{"o": map[string]interface{}{}, "l": []interface{}{}},
+			map[string]interface{}{"o": map[string]interface{}{}, "l": []interface{}{}}},
+		{"dotted keys", map[string]interface{}{"hosts": map[string]interface{}{"example.com": 1, `a\b`: 2}},
+			map[string]interface{}{`hosts.example\.com`: 1, `hosts.a\\b`: 2}},
+		{"dotted key and nested key", map[string]interface{}{"a.b": 1, "a": map[string]interface{}{"b": 2}},
+			map[string]interface{}{`a\.b`: 1, "a.b": 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Flatten(tt.nested); !reflect.DeepEqual(got, tt.flat) {
+				t.Errorf("Flatten() = %v, want %v", got, tt.flat)
+			}
+			if got, err := Unflatten(tt.flat); err != nil || !reflect.DeepEqual(got, tt.nested) {
+				t.Errorf("Unflatten() = %v, %v, want %v", got, err, tt.nested)
+			}
+		})
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	tests := []struct {
+		name string
+		flat map[string]interface{}
+		want map[string]interface{}
+	}{
+		{"sparse indexes stay an object", map[string]interface{}{"l.0": "a", "l.2": "c"}, map[string]interface{}{"l": map[string]interface{}{"0": "a", "2": "c"}}},
+		{"leading zeros stay an object", map[string]interface{}{"l.00": "a"}, map[string]interface{}{"l": map[string]interface{}{"00": "a"}}},
+		{"unescaped backslash kept", map[string]interface{}{`a\b.c`: 1}, map[string]interface{}{`a\b`: map[string]interface{}{"c": 1}}},
+		{"nested lists", map[string]interface{}{"m.0.0": 1, "m.0.1": 2, "m.1.0": 3}, map[string]interface{}{"m": []interface{}{[]interface{}{1, 2}, []interface{}{3}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Unflatten(tt.flat); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unflatten() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnflattenConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		flat  map[string]interface{}
+		field string
+	}{
+		{"scalar and nested key", map[string]interface{}{"a": 1, "a.b": 2}, "a"},
+		{"deeper nested key", map[string]interface{}{"a.b": 1, "a.b.c.d": 2}, "a.b"},
+		{"null and nested key", map[string]interface{}{"a": nil, "a.0": 2}, "a"},
+		{"escaped prefix", map[string]interface{}{`a\.b`: 1, `a\.b.c`: 2}, `a\.b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unflatten(tt.flat)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field || validationErr.Code != CodeInvalidKey {
+				t.Errorf("Unflatten() = %v, %v, want a conflict on %q", got, err, tt.field)
+			}
+		})
+	}
+
+	s := &BaseSerializer{Flatten: true}
+	var got map[string]interface{}
+	if err := s.Deserialize(map[string]interface{}{"a": 1, "a.b": 2}, &got); ErrorCode(err) != CodeInvalidKey {
+		t.Errorf("Deserialize() error = %v, want %s", err, CodeInvalidKey)
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	type site struct {
+		Hosts map[string]string `json:"hosts"`
+	}
+	s := &BaseSerializer{Flatten: true}
+	in := site{Hosts: map[string]string{"example.com": "a", "api.example.com": "b"}}
+	flat, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	want := map[string]interface{}{`hosts.example\.com`: "a", `hosts.api\.example\.com`: "b"}
+	if !reflect.DeepEqual(flat, want) {
+		t.Fatalf("Serialize() = %#v, want %#v", flat, want)
+	}
+	var out site
+	if err := s.Deserialize(flat, &out); err != nil || !reflect.DeepEqual(out, in) {
+		t.Errorf("Deserialize() = %+v, %v, want %+v", out, err, in)
+	}
+}
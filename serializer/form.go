@@ -0,0 +1,274 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// DeserializeFromURLValues validates form data or a query string and deserializes it into out,
+// so HTML forms and query parameters bind like JSON bodies. Values are converted to the types of
+// the target's fields before the validations run, as with Coerce: "42" becomes a number for an
+// int field, and "on", as sent by checkboxes, true for a bool field.
+//
+// Repeated keys (tags=a&tags=b) and keys ending in "[]" fill slice fields; a single value does
+// too. Dotted keys fill nested structs and maps ("address.city"), and list indexes elements of
+// slices ("items.0.name"). Empty values of fields other than strings are left out, so Defaults and
+// required validations apply to them.
+func (s *BaseSerializer) DeserializeFromURLValues(values url.Values, out interface{}) error {
+	return s.DeserializeFromURLValuesWithContext(context.Background(), values, out)
+}
+
+// DeserializeFromURLValuesWithContext is like DeserializeFromURLValues, passing ctx to the
+// context-aware validations and hooks.
+func (s *BaseSerializer) DeserializeFromURLValuesWithContext(ctx context.Context, values url.Values, out interface{}) error {
+	return s.deserializeForm(ctx, values, nil, out)
+}
+
+// DeserializeFromMultipart binds a multipart form, as parsed by http.Request.ParseMultipartForm,
+// like DeserializeFromURLValues. Uploaded files are validated under their keys, as a
+// *multipart.FileHeader or, when several are uploaded under a key, a []*multipart.FileHeader (see
+// FileField). They are assigned to the top-level fields of type *multipart.FileHeader, which
+// receive the first file of their key, and []*multipart.FileHeader.
+func (s *BaseSerializer) DeserializeFromMultipart(form *multipart.Form, out interface{}) error {
+	return s.DeserializeFromMultipartWithContext(context.Background(), form, out)
+}
+
+// DeserializeFromMultipartWithContext is like DeserializeFromMultipart, passing ctx to the
+// context-aware validations and hooks.
+func (s *BaseSerializer) DeserializeFromMultipartWithContext(ctx context.Context, form *multipart.Form, out interface{}) error {
+	if form == nil {
+		return &SerializationError{Message: "DeserializeFromMultipart expects a parsed multipart form"}
+	}
+	return s.deserializeForm(ctx, form.Value, form.File, out)
+}
+
+// deserializeForm validates form values and files and deserializes them into out.
+func (s *BaseSerializer) deserializeForm(ctx context.Context, values url.Values, files map[string][]*multipart.FileHeader, out interface{}) error {
+	input, err := s.formInput(values, reflect.TypeOf(out))
+	if err != nil {
+		return err
+	}
+
+	validated := input
+	if len(files) > 0 {
+		validated = make(map[string]interface{}, len(input)+len(files))
+		for key, value := range input {
+			validated[key] = value
+		}
+		for key, headers := range files {
+			switch key = strings.TrimSuffix(key, "[]"); len(headers) {
+			case 0:
+			case 1:
+				validated[key] = headers[0]
+			default:
+				validated[key] = headers
+			}
+		}
+	}
+	if err := s.ValidateWithContext(ctx, validated); err != nil {
+		// Report files by name rather than with their content
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			switch v := validationErr.Value.(type) {
+			case *multipart.FileHeader:
+				validationErr.Value = v.Filename
+			case []*multipart.FileHeader:
+				names := make([]string, len(v))
+				for i, header := range v {
+					names[i] = header.Filename
+				}
+				validationErr.Value = names
+			}
+		}
+		return err
+	}
+
+	if err := s.DeserializeWithContext(ctx, input, out); err != nil {
+		return err
+	}
+	assignFiles(files, out)
+	return nil
+}
+
+// formInput converts form values into an input map for target type t, coercing each value to
+// the type of its field.
+func (s *BaseSerializer) formInput(values url.Values, t reflect.Type) (map[string]interface{}, error) {
+	flat := make(map[string]interface{}, len(values))
+	nested := false
+	for key, items := range values {
+		list := strings.HasSuffix(key, "[]")
+		key = strings.TrimSuffix(key, "[]")
+		if len(items) == 0 || key == "" {
+			continue
+		}
+		path := strings.Split(key, ".")
+		nested = nested || len(path) > 1
+
+		typ := s.formFieldType(t, path)
+		var value interface{}
+		switch {
+		case typ != nil && typ != bytesType && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array):
+			value = formList(items, typ.Elem())
+		case typ == nil && (list || len(items) > 1):
+			value = formList(items, nil)
+		default:
+			item, ok := formScalar(items[0], typ)
+			if !ok {
+				continue
+			}
+			value = item
+		}
+		if typ != nil {
+			coerced, err := coerceTo(value, typ, key, s.WeaklyTyped)
+			if err != nil {
+				return nil, err
+			}
+			value = coerced
+		}
+		flat[key] = value
+	}
+	if nested {
+		unflattened, err := Unflatten(flat)
+		if err != nil {
+			return nil, err
+		}
+		flat = unflattened
+	}
+
+	// Validations see numbers as in decoded JSON
+	normalized, err := toJSONValueWith(reflect.ValueOf(flat), &encodeOptions{numbers: s.UseNumber})
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to decode form: %v", err), Err: err}
+	}
+	input, _ := normalized.(map[string]interface{})
+	return input, nil
+}
+
+// formList converts the values of a repeated key into a list for elements of type elem, leaving
+// out empty values of elements other than strings.
+func formList(items []string, elem reflect.Type) []interface{} {
+	list := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if value, ok := formScalar(item, elem); ok {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// formScalar converts a form value for a field of type t, reporting false for empty values of
+// fields other than strings.
+func formScalar(item string, t reflect.Type) (interface{}, bool) {
+	if t == nil {
+		return item, true
+	}
+	t = baseType(t)
+	if t.Kind() == reflect.String {
+		return item, true
+	}
+	if strings.TrimSpace(item) == "" {
+		return nil, false
+	}
+	if t.Kind() == reflect.Bool && strings.EqualFold(item, "on") {
+		return true, true
+	}
+	return item, true
+}
+
+// formFieldType returns the type of the field a dotted form key refers to within t, or nil if it
+// doesn't match a field. Keys match the JSON names of fields, or the names converted with
+// KeyNaming.
+func (s *BaseSerializer) formFieldType(t reflect.Type, path []string) reflect.Type {
+	for _, segment := range path {
+		if t == nil {
+			return nil
+		}
+		t = baseType(t)
+		switch t.Kind() {
+		case reflect.Struct:
+			field := s.formField(t, segment)
+			if field == nil {
+				return nil
+			}
+			t = field.typ
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(segment); err != nil {
+				return nil
+			}
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// formField returns the field of struct type t that key refers to, or nil.
+func (s *BaseSerializer) formField(t reflect.Type, key string) *schemaField {
+	schema := schemaFor(t)
+	if field := schema.byName[key]; field != nil {
+		return field
+	}
+	if s.KeyNaming == NamingDefault {
+		return nil
+	}
+	for i := range schema.fields {
+		if s.convertKey(schema.fields[i].name) == key {
+			return &schema.fields[i]
+		}
+	}
+	return nil
+}
+
+// assignFiles sets the top-level *multipart.FileHeader and []*multipart.FileHeader fields of out
+// to the files uploaded under their keys. Keys match the JSON names of the fields, or their Go
+// names, ignoring case, for fields tagged json:"-".
+func assignFiles(files map[string][]*multipart.FileHeader, out interface{}) {
+	v := reflect.ValueOf(out)
+	if len(files) == 0 || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() || (sf.Type != fileHeaderType && sf.Type != fileHeadersType) {
+			continue
+		}
+		name := sf.Name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); tag != "-" && validTagName(tag) {
+			name = tag
+		}
+		headers := formFiles(files, name)
+		if len(headers) == 0 {
+			continue
+		}
+		if sf.Type == fileHeaderType {
+			v.Field(i).Set(reflect.ValueOf(headers[0]))
+		} else {
+			v.Field(i).Set(reflect.ValueOf(append([]*multipart.FileHeader(nil), headers...)))
+		}
+	}
+}
+
+// formFiles returns the files uploaded under name, or name followed by "[]", ignoring case.
+func formFiles(files map[string][]*multipart.FileHeader, name string) []*multipart.FileHeader {
+	for key, headers := range files {
+		if strings.EqualFold(strings.TrimSuffix(key, "[]"), name) {
+			return headers
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// pngHeader is the signature of a PNG image, enough for http.DetectContentType.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// uploadForm builds a parsed multipart form with the given values and files, each file given as
+// its name, declared content type and content.
+func uploadForm(t *testing.T, values map[string]string, files map[string][][3]string) *multipart.Form {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for key, value := range values {
+		if err := w.WriteField(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, uploads := range files {
+		for _, upload := range uploads {
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", `form-data; name="`+key+`"; filename="`+upload[0]+`"`)
+			header.Set("Content-Type", upload[1])
+			part, err := w.CreatePart(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write([]byte(upload[2]))
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form
+}
+
+type signup struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age"`
+	Terms   bool              `json:"terms"`
+	Tags    []string          `json:"tags"`
+	Scores  []int             `json:"scores"`
+	Address map[string]string `json:"address"`
+	Items   []orderLine       `json:"items"`
+}
+
+func TestDeserializeFromURLValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         *BaseSerializer
+		values    url.Values
+		want      signup
+		wantField string
+	}{
+		{"scalars", &BaseSerializer{}, url.Values{"name": {"ana"}, "age": {"42"}, "terms": {"on"}}, signup{Name: "ana", Age: 42, Terms: true}, ""},
+		{"repeated keys and brackets", &BaseSerializer{}, url.Values{"tags": {"a", "b"}, "scores[]": {"1", "", "2"}}, signup{Tags: []string{"a", "b"}, Scores: []int{1, 2}}, ""},
+		{"single value of a list", &BaseSerializer{}, url.Values{"tags": {"a"}}, signup{Tags: []string{"a"}}, ""},
+		{"dotted keys", &BaseSerializer{}, url.Values{"address.city": {"Lima"}, "items.0.qty": {"2.5"}},
+			signup{Address: map[string]string{"city": "Lima"}, Items: []orderLine{{Qty: 2.5}}}, ""},
+		{"empty number left out", &BaseSerializer{Defaults: map[string]interface{}{"age": 18}}, url.Values{"age": {" "}}, signup{Age: 18}, ""},
+		{"empty string kept", &BaseSerializer{}, url.Values{"name": {""}}, signup{}, ""},
+		{"converted keys", &BaseSerializer{KeyNaming: NamingPascalCase}, url.Values{"Age": {"7"}}, signup{Age: 7}, ""},
+		{"validation", &BaseSerializer{Validations: map[string][]func(interface{}) error{"age": {Positive}}}, url.Values{"age": {"-1"}}, signup{}, "age"},
+		{"invalid number", &BaseSerializer{}, url.Values{"age": {"x"}}, signup{}, "age"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got signup
+			err := tt.s.DeserializeFromURLValues(tt.values, &got)
+			if tt.wantField != "" {
+				if err == nil {
+					t.Errorf("DeserializeFromURLValues() error = nil, want a failure of %s", tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeserializeFromURLValues() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeserializeFromURLValues() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type upload struct {
+	Title       string                  `json:"title"`
+	Avatar      *multipart.FileHeader   `json:"-"`
+	Attachments []*multipart.FileHeader `json:"attachments"`
+}
+
+func TestDeserializeFromMultipart(t *testing.T) {
+	form := uploadForm(t, map[string]string{"title": "report"}, map[string][][3]string{
+		"avatar":        {{"me.png", "image/png", string(pngHeader)}},
+		"attachments[]": {{"a.txt", "text/plain", "a"}, {"b.txt", "text/plain", "b"}},
+	})
+	tests := []struct {
+		name      string
+		s         *BaseSerializer
+		wantValue interface{} // Value of the failure
+	}{
+		{"valid", &BaseSerializer{Schema: map[string]Field{"avatar": FileField{AllowedTypes: []string{"image/*"}}, "attachments": FileField{MaxFiles: 2}}}, nil},
+		{"invalid file", &BaseSerializer{Schema: map[string]Field{"avatar": FileField{MaxSize: 1}}}, "me.png"},
+		{"invalid files", &BaseSerializer{Schema: map[string]Field{"attachments": FileField{MaxFiles: 1}}}, []string{"a.txt", "b.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got upload
+			err := tt.s.DeserializeFromMultipart(form, &got)
+			if tt.wantValue != nil {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || !reflect.DeepEqual(validationErr.Value, tt.wantValue) {
+					t.Errorf("DeserializeFromMultipart() error = %v, want a failure with value %v", err, tt.wantValue)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeserializeFromMultipart() error = %v", err)
+			}
+			if got.Title != "report" || got.Avatar == nil || got.Avatar.Filename != "me.png" || len(got.Attachments) != 2 {
+				t.Errorf("DeserializeFromMultipart() = %+v", got)
+			}
+		})
+	}
+
+	if err := (&BaseSerializer{}).DeserializeFromMultipart(nil, &upload{}); err == nil {
+		t.Errorf("DeserializeFromMultipart(nil) error = nil")
+	}
+}
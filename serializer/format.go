@@ -0,0 +1,354 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format identifies a serialization format.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+	FormatYAML Format = "yaml"
+	FormatGob  Format = "gob" // Go-to-Go binary encoding; not negotiated over HTTP
+
+	// FormatBinary is the experimental self-describing binary format of EncodeBinary; it is not
+	// negotiated over HTTP.
+	FormatBinary Format = "binary"
+)
+
+// ContentType returns the media type used when sending documents in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatXML:
+		return "application/xml"
+	case FormatYAML:
+		return "application/yaml"
+	case FormatGob:
+		return "application/x-gob"
+	case FormatBinary:
+		return "application/x-bserializer"
+	default:
+		if registered := lookupFormat(f); registered != nil && registered.mediaType != "" {
+			return registered.mediaType
+		}
+		return "application/octet-stream"
+	}
+}
+
+// FormatFromMediaType returns the format of a media type such as "application/json",
+// "application/vnd.api+json" or "text/yaml", or of a format registered with RegisterFormat, or
+// "" if it isn't a supported format. Parameters like "; charset=utf-8" are ignored.
+func FormatFromMediaType(mediaType string) Format {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return FormatJSON
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return FormatXML
+	case strings.HasSuffix(mediaType, "/yaml") || strings.HasSuffix(mediaType, "/x-yaml") || strings.HasSuffix(mediaType, "+yaml"):
+		return FormatYAML
+	case mediaType == "":
+		return ""
+	default:
+		return findFormat(func(registered *registeredFormat) bool { return registered.mediaType == mediaType })
+	}
+}
+
+// FormatFromExtension returns the format of a file name such as "user.json" or "config.yml",
+// including the extensions of formats registered with RegisterFormat, or "" if its extension
+// isn't a supported format.
+func FormatFromExtension(name string) Format {
+	extension := strings.ToLower(filepath.Ext(name))
+	switch extension {
+	case ".json":
+		return FormatJSON
+	case ".xml":
+		return FormatXML
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".gob":
+		return FormatGob
+	case "":
+		return ""
+	default:
+		return findFormat(func(registered *registeredFormat) bool { return containsField(registered.extensions, extension) })
+	}
+}
+
+// Negotiate returns the supported format preferred by an HTTP Accept header (RFC 9110). Every
+// format takes the q value of the most specific media range matching it, so a media type overrides
+// "type/*", which overrides "*/*", and formats refused with q=0 are never selected. The format with
+// the highest q value wins; ties go to the format matched by the more specific range, then by the
+// earlier range in the header, then to JSON, XML, YAML and registered formats in that order.
+// An empty header selects JSON; "" is returned when no supported format is acceptable.
+func Negotiate(acceptHeader string) Format {
+	if strings.TrimSpace(acceptHeader) == "" {
+		return FormatJSON
+	}
+
+	ranges := parseAccept(acceptHeader)
+	var best Format
+	var bestMatch mediaRange
+	for _, format := range negotiableFormats() {
+		match, ok := matchRange(ranges, format)
+		if !ok || match.q == 0 {
+			continue
+		}
+		if best == "" || match.q > bestMatch.q ||
+			(match.q == bestMatch.q && (match.specificity > bestMatch.specificity ||
+				(match.specificity == bestMatch.specificity && match.position < bestMatch.position))) {
+			best, bestMatch = format, match
+		}
+	}
+	return best
+}
+
+// mediaRange is a media range of an Accept header.
+type mediaRange struct {
+	mediaType   string  // Lower-case media type, e.g. "application/json", "text/*" or "*/*"
+	q           float64 // Quality value, 1 by default
+	specificity int     // 0 for "*/*", 1 for "type/*" and 2 for a media type
+	position    int     // Index of the range in the header
+}
+
+// parseAccept returns the media ranges of an Accept header, skipping malformed ones.
+func parseAccept(acceptHeader string) []mediaRange {
+	var ranges []mediaRange
+	for i, part := range strings.Split(acceptHeader, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		r := mediaRange{mediaType: strings.ToLower(mediaType), q: 1, specificity: 2, position: i}
+		if value, ok := params["q"]; ok {
+			if r.q, err = strconv.ParseFloat(value, 64); err != nil || r.q < 0 || r.q > 1 {
+				continue
+			}
+		}
+		switch {
+		case r.mediaType == "*/*":
+			r.specificity = 0
+		case strings.HasSuffix(r.mediaType, "/*"):
+			r.specificity = 1
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// matchRange returns the most specific of ranges that matches format, the earliest among equally
+// specific ones.
+func matchRange(ranges []mediaRange, format Format) (mediaRange, bool) {
+	var best mediaRange
+	found := false
+	for _, r := range ranges {
+		if found && r.specificity <= best.specificity {
+			continue
+		}
+		var matches bool
+		switch r.specificity {
+		case 0:
+			matches = true
+		case 1:
+			prefix := strings.TrimSuffix(r.mediaType, "*")
+			for _, mediaType := range formatMediaTypes(format) {
+				if strings.HasPrefix(mediaType, prefix) {
+					matches = true
+				}
+			}
+		default:
+			matches = FormatFromMediaType(r.mediaType) == format
+		}
+		if matches {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// negotiableFormats returns the formats Negotiate chooses from, in order of preference: the
+// built-in text formats, then the registered formats with a media type, sorted by name.
+func negotiableFormats() []Format {
+	negotiable := []Format{FormatJSON, FormatXML, FormatYAML}
+	formatsMu.RLock()
+	registered := make([]string, 0, len(formats))
+	for format, r := range formats {
+		if r.mediaType != "" {
+			registered = append(registered, string(format))
+		}
+	}
+	formatsMu.RUnlock()
+	sort.Strings(registered)
+	for _, format := range registered {
+		negotiable = append(negotiable, Format(format))
+	}
+	return negotiable
+}
+
+// formatMediaTypes returns the media types that select format in an Accept header, as matched by
+// "type/*" ranges.
+func formatMediaTypes(format Format) []string {
+	switch format {
+	case FormatJSON:
+		return []string{"application/json"}
+	case FormatXML:
+		return []string{"application/xml", "text/xml"}
+	case FormatYAML:
+		return []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}
+	}
+	if registered := lookupFormat(format); registered != nil && registered.mediaType != "" {
+		return []string{registered.mediaType}
+	}
+	return nil
+}
+
+// SerializeAs serializes data and encodes it in the given format, built in or registered with
+// RegisterFormat. JSON is laid out according to JSONEncoding. opts override the configuration for
+// this call only.
+func (s *BaseSerializer) SerializeAs(data interface{}, format Format, opts ...Option) ([]byte, error) {
+	return s.SerializeAsWithContext(context.Background(), data, format, opts...)
+}
+
+// SerializeAsWithContext is like SerializeAs, passing ctx to the context-aware pipeline stages.
+// Slices and arrays are serialized like SerializeManyWithContext and encoded as a list in JSON,
+// YAML and XML, where every element is an XMLItem element of the root.
+func (s *BaseSerializer) SerializeAsWithContext(ctx context.Context, data interface{}, format Format, opts ...Option) ([]byte, error) {
+	if len(opts) > 0 {
+		s = s.view(opts)
+	}
+	if isList(data) {
+		return s.serializeListAs(ctx, data, format)
+	}
+	if format == FormatGob || format == FormatBinary {
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		if format == FormatGob {
+			return s.encodeGob(result)
+		}
+		return EncodeBinary(result)
+	}
+	if (s.Deterministic || len(s.Order) > 0) && format == FormatJSON {
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := EncodeOrdered(result, format, s.Order)
+		if err != nil {
+			return nil, err
+		}
+		return s.JSONEncoding.format(encoded)
+	}
+
+	switch format {
+	case FormatJSON:
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		return s.EncodeJSON(result)
+	case FormatXML:
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		return s.encodeXMLResult(result)
+	case FormatYAML:
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeOrdered(result, FormatYAML, s.Order)
+	default:
+		codec, ok := LookupFormat(format)
+		if !ok {
+			return nil, &SerializationError{Message: fmt.Sprintf("unsupported format '%s'", format)}
+		}
+		if native, ok := codec.(NativeCodec); ok {
+			ctx = withNativeValues(ctx, native.Native)
+		}
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		return s.encodeWithCodec(result, format, codec)
+	}
+}
+
+// EncodeAs encodes a serialized value, or a list of them, in the given format like SerializeAs
+// encodes its results: XML with XMLRoot and the XML settings, YAML in Order, JSON with
+// JSONEncoding, and single objects in the formats registered with RegisterFormat.
+func (s *BaseSerializer) EncodeAs(value interface{}, format Format) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		if s.Deterministic || len(s.Order) > 0 {
+			encoded, err := EncodeOrdered(value, FormatJSON, s.Order)
+			if err != nil {
+				return nil, err
+			}
+			return s.JSONEncoding.format(encoded)
+		}
+		return s.EncodeJSON(value)
+	case FormatXML:
+		return s.encodeXMLResult(value)
+	case FormatYAML:
+		return EncodeOrdered(value, FormatYAML, s.Order)
+	}
+	codec, ok := LookupFormat(format)
+	if !ok {
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported format '%s'", format)}
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &SerializationError{Message: fmt.Sprintf("format '%s' can only encode a single object", format)}
+	}
+	return s.encodeWithCodec(object, format, codec)
+}
+
+// serializeListAs serializes every element of the slice or array data and encodes the results as
+// a list in the given format.
+func (s *BaseSerializer) serializeListAs(ctx context.Context, data interface{}, format Format) ([]byte, error) {
+	if format != FormatJSON && format != FormatXML && format != FormatYAML {
+		return nil, &SerializationError{Message: fmt.Sprintf("format '%s' can only encode a single object", format)}
+	}
+	results, err := s.SerializeManyWithContext(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case format == FormatXML:
+		return s.encodeXMLResult(results)
+	case format == FormatYAML:
+		return EncodeOrdered(results, FormatYAML, s.Order)
+	case s.Deterministic || len(s.Order) > 0:
+		encoded, err := EncodeOrdered(results, FormatJSON, s.Order)
+		if err != nil {
+			return nil, err
+		}
+		return s.JSONEncoding.format(encoded)
+	default:
+		return s.EncodeJSON(results)
+	}
+}
+
+// isList reports whether data is a slice or an array other than a byte slice, which is serialized
+// element by element.
+func isList(data interface{}) bool {
+	v := reflect.ValueOf(data)
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8
+}
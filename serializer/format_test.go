@@ -0,0 +1,38 @@
+package serializer
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+	}{
+		{"", FormatJSON},
+		{"application/json", FormatJSON},
+		{"application/vnd.api+json", FormatJSON},
+		{"application/xml", FormatXML},
+		{"text/yaml", FormatYAML},
+		{"*/*", FormatJSON},
+		{"application/*", FormatJSON},
+		{"text/*", FormatXML},
+		{"application/xml;q=0.5, application/yaml;q=0.8", FormatYAML},
+		{"application/json;q=0, */*", FormatXML},
+		{"application/json;q=0, application/xml;q=0, */*", FormatYAML},
+		{"*/*;q=0", ""},
+		{"application/*;q=0, text/yaml;q=0.1", FormatYAML},
+		{"*/*, application/xml", FormatXML},
+		{"*/*;q=0.5, application/yaml;q=0.5", FormatYAML},
+		{"application/yaml, application/xml", FormatYAML},
+		{"text/html", ""},
+		{"text/html, application/xml;q=0.1", FormatXML},
+		{"application/json;q=2, application/xml", FormatXML},
+		{"application/json;q=abc", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := Negotiate(tt.accept); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MapEncoder is implemented by types that convert themselves into serialized maps without
+// reflection, such as the code written by bserializer-gen. It is used for structs when the
+// serializer has no depth limit, cycle detection, polymorphic types, registry or time formats,
+// which the generated code can't honour.
+type MapEncoder interface {
+	EncodeMap(numbers bool) (map[string]interface{}, error)
+}
+
+// MapDecoder is implemented by types that fill themselves from serialized maps without
+// reflection, such as the code written by bserializer-gen. DecodeMap must not retain input.
+type MapDecoder interface {
+	DecodeMap(input map[string]interface{}) error
+}
+
+var (
+	mapEncoderType = reflect.TypeOf((*MapEncoder)(nil)).Elem()
+
+	// numberOptions are serializeOptions writing numbers as json.Number.
+	numberOptions = &encodeOptions{marshalers: true, numbers: true}
+)
+
+// mapEncoder returns the MapEncoder of v when the options allow using it.
+func (e *encodeState) mapEncoder(v reflect.Value) (MapEncoder, bool) {
+	if o := e.opts; o != nil && (o.maxDepth > 0 || o.cycles || len(o.polymorphic) > 0 || o.registry != nil ||
+		o.times != nil || len(o.fieldTimes) > 0) {
+		return nil, false
+	}
+	if !v.CanInterface() || !v.Type().Implements(mapEncoderType) {
+		return nil, false
+	}
+	if v.CanAddr() {
+		// Avoids copying the struct into the interface
+		return v.Addr().Interface().(MapEncoder), true
+	}
+	return v.Interface().(MapEncoder), true
+}
+
+// Integer constrains the helpers of generated code to the signed integer types.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned constrains the helpers of generated code to the unsigned integer types.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float constrains the helpers of generated code to the floating-point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+// The Encode and Decode helpers below are called by the code bserializer-gen writes, and convert
+// values the way the reflection-based pipeline does.
+
+// EncodeInt converts a signed integer field.
+func EncodeInt[T Integer](i T, numbers bool) interface{} {
+	if numbers {
+		return json.Number(strconv.FormatInt(int64(i), 10))
+	}
+	return float64(i)
+}
+
+// EncodeUint converts an unsigned integer field.
+func EncodeUint[T Unsigned](u T, numbers bool) interface{} {
+	if numbers {
+		return json.Number(strconv.FormatUint(uint64(u), 10))
+	}
+	return float64(u)
+}
+
+// EncodeFloat32 converts a float32 field, writing its shortest representation.
+func EncodeFloat32[T ~float32](f T, numbers bool) (interface{}, error) {
+	return encodeFloat(float64(f), 32, numbers)
+}
+
+// EncodeFloat64 converts a float64 field.
+func EncodeFloat64[T ~float64](f T, numbers bool) (interface{}, error) {
+	return encodeFloat(float64(f), 64, numbers)
+}
+
+// encodeFloat converts a float of the given bit size like encoding/json does.
+func encodeFloat(f float64, bits int, numbers bool) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	if numbers {
+		return json.Number(formatFloat(f, bits)), nil
+	}
+	if bits == 32 {
+		// Match the shortest float32 representation encoding/json writes
+		f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+	}
+	return f, nil
+}
+
+// EncodeString converts a string field, replacing invalid UTF-8 like encoding/json does.
+func EncodeString[T ~string](s T) string {
+	return validUTF8(string(s))
+}
+
+// EncodeTime converts a time field into RFC 3339.
+func EncodeTime(t time.Time) (interface{}, error) {
+	if y := t.Year(); y < 0 || y >= 10000 {
+		return nil, fmt.Errorf("Time.MarshalJSON: year outside of range [0,9999]")
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// EncodeValue converts any other field with the reflection-based pipeline.
+func EncodeValue(value interface{}, numbers bool) (interface{}, error) {
+	opts := serializeOptions
+	if numbers {
+		opts = numberOptions
+	}
+	return toJSONValueWith(reflect.ValueOf(value), opts)
+}
+
+// IsEmpty reports whether value is empty under the omitempty option.
+func IsEmpty(value interface{}) bool {
+	return value == nil || isEmptyValue(reflect.ValueOf(value))
+}
+
+// MatchKey returns the field name among names that key refers to: the one equal to key, or else
+// the first one equal under case folding, as encoding/json matches keys. Otherwise key itself.
+func MatchKey(key string, names []string) string {
+	for _, name := range names {
+		if name == key {
+			return name
+		}
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return key
+}
+
+// DecodeInt stores a number in a signed integer field. Nulls leave the field unchanged.
+func DecodeInt[T Integer](field string, value interface{}, out *T) error {
+	if value == nil {
+		return nil
+	}
+	literal, ok := numberLiteral(value)
+	if !ok {
+		return decodeError(field, value, *out)
+	}
+	i, err := strconv.ParseInt(literal, 10, 64)
+	if err != nil || int64(T(i)) != i {
+		return decodeError(field, value, *out)
+	}
+	*out = T(i)
+	return nil
+}
+
+// DecodeUint stores a number in an unsigned integer field. Nulls leave the field unchanged.
+func DecodeUint[T Unsigned](field string, value interface{}, out *T) error {
+	if value == nil {
+		return nil
+	}
+	literal, ok := numberLiteral(value)
+	if !ok {
+		return decodeError(field, value, *out)
+	}
+	u, err := strconv.ParseUint(literal, 10, 64)
+	if err != nil || uint64(T(u)) != u {
+		return decodeError(field, value, *out)
+	}
+	*out = T(u)
+	return nil
+}
+
+// DecodeFloat stores a number in a float field. Nulls leave the field unchanged.
+func DecodeFloat[T Float](field string, value interface{}, out *T) error {
+	if value == nil {
+		return nil
+	}
+	literal, ok := numberLiteral(value)
+	if !ok {
+		return decodeError(field, value, *out)
+	}
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil || math.IsInf(float64(T(f)), 0) {
+		return decodeError(field, value, *out)
+	}
+	*out = T(f)
+	return nil
+}
+
+// DecodeString stores a string in a string field. Nulls leave the field unchanged.
+func DecodeString[T ~string](field string, value interface{}, out *T) error {
+	switch v := value.(type) {
+	case nil:
+	case string:
+		*out = T(v)
+	default:
+		return decodeError(field, value, *out)
+	}
+	return nil
+}
+
+// DecodeBool stores a boolean in a bool field. Nulls leave the field unchanged.
+func DecodeBool[T ~bool](field string, value interface{}, out *T) error {
+	switch v := value.(type) {
+	case nil:
+	case bool:
+		*out = T(v)
+	default:
+		return decodeError(field, value, *out)
+	}
+	return nil
+}
+
+// DecodeValue stores value in any other field, through its MapDecoder when it has one and value
+// is an object, and by reflection with the rules of encoding/json otherwise.
+func DecodeValue(field string, value interface{}, out interface{}) error {
+	if object, ok := value.(map[string]interface{}); ok {
+		if decoder, ok := out.(MapDecoder); ok {
+			return decoder.DecodeMap(object)
+		}
+	}
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return reflectDecoder{}.decode(value, out)
+	}
+	return reflectDecoder{}.decodeAt(value, v.Elem(), field)
+}
+
+// numberLiteral returns the JSON literal of a decoded number.
+func numberLiteral(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		return formatFloat(v, 64), true
+	case json.Number:
+		return string(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	}
+	return "", false
+}
+
+// decodeError reports a value that doesn't fit the field it is stored in.
+func decodeError(field string, value, target interface{}) error {
+	return &SerializationError{Message: fmt.Sprintf("failed to deserialize field '%s': cannot unmarshal %s into %T", field, jsonKind(value), target)}
+}
+
+// jsonKind names the JSON type of a decoded value.
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if _, ok := numberLiteral(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,178 @@
+package serializer
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// point is written like bserializer-gen code, counting the calls of its methods.
+type point struct {
+	X, Y     int
+	encoded  *int
+	decodedX int
+}
+
+func (p point) EncodeMap(numbers bool) (map[string]interface{}, error) {
+	*p.encoded++
+	return map[string]interface{}{"x": EncodeInt(p.X, numbers), "y": EncodeInt(p.Y, numbers)}, nil
+}
+
+func (p *point) DecodeMap(input map[string]interface{}) error {
+	for key, value := range input {
+		switch MatchKey(key, []string{"x", "y"}) {
+		case "x":
+			if err := DecodeInt("x", value, &p.X); err != nil {
+				return err
+			}
+		case "y":
+			if err := DecodeInt("y", value, &p.Y); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestMapEncoder(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           *BaseSerializer
+		wantEncoded int
+	}{
+		{"used", &BaseSerializer{}, 1},
+		{"unused with a depth limit", &BaseSerializer{MaxDepth: 5}, 0},
+		{"unused with cycle detection", &BaseSerializer{DetectCycles: true}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoded int
+			got, err := tt.s.Serialize(map[string]interface{}{"p": &point{X: 1, Y: 2, encoded: &encoded}})
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if encoded != tt.wantEncoded {
+				t.Errorf("EncodeMap called %d times, want %d", encoded, tt.wantEncoded)
+			}
+			if tt.wantEncoded > 0 && !reflect.DeepEqual(got["p"], map[string]interface{}{"x": 1.0, "y": 2.0}) {
+				t.Errorf("Serialize() = %v", got)
+			}
+		})
+	}
+}
+
+func TestEncodeHelpers(t *testing.T) {
+	type level int8
+	tests := []struct {
+		name string
+		got  func() (interface{}, error)
+		want interface{}
+	}{
+		{"int", func() (interface{}, error) { return EncodeInt(level(-3), false), nil }, -3.0},
+		{"int number", func() (interface{}, error) { return EncodeInt(int64(math.MaxInt64), true), nil }, json.Number("9223372036854775807")},
+		{"uint", func() (interface{}, error) { return EncodeUint(uint8(200), false), nil }, 200.0},
+		{"uint number", func() (interface{}, error) { return EncodeUint(uint64(math.MaxUint64), true), nil }, json.Number("18446744073709551615")},
+		{"float32", func() (interface{}, error) { return EncodeFloat32(float32(0.1), false) }, 0.1},
+		{"float32 number", func() (interface{}, error) { return EncodeFloat32(float32(0.1), true) }, json.Number("0.1")},
+		{"float64 number", func() (interface{}, error) { return EncodeFloat64(1e21, true) }, json.Number("1e+21")},
+		{"string", func() (interface{}, error) { return EncodeString("a\xffb"), nil }, "a�b"},
+		{"time", func() (interface{}, error) { return EncodeTime(time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)) }, "2024-01-02T03:04:05.000000006Z"},
+		{"value", func() (interface{}, error) { return EncodeValue([]uint16{1}, false) }, []interface{}{1.0}},
+		{"value number", func() (interface{}, error) { return EncodeValue(map[string]int{"a": 1}, true) }, map[string]interface{}{"a": json.Number("1")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, %v, want %#v", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeFloat64(math.NaN(), false); err == nil {
+		t.Errorf("EncodeFloat64(NaN) error = nil")
+	}
+	if _, err := EncodeTime(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("EncodeTime(year 10000) error = nil")
+	}
+	if !IsEmpty(nil) || !IsEmpty("") || !IsEmpty([]int{}) || IsEmpty(0.5) {
+		t.Errorf("IsEmpty() doesn't follow omitempty")
+	}
+}
+
+func TestMatchKey(t *testing.T) {
+	names := []string{"Name", "name", "id"}
+	tests := []struct{ key, want string }{
+		{"name", "name"},
+		{"NAME", "Name"},
+		{"ID", "id"},
+		{"other", "other"},
+	}
+	for _, tt := range tests {
+		if got := MatchKey(tt.key, names); got != tt.want {
+			t.Errorf("MatchKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeHelpers(t *testing.T) {
+	var (
+		i8  int8 = 5
+		u16 uint16
+		f32 float32
+		s   string
+		b   bool
+	)
+	tests := []struct {
+		name    string
+		decode  func() error
+		check   func() bool
+		wantErr string
+	}{
+		{"null leaves the field", func() error { return DecodeInt("i", nil, &i8) }, func() bool { return i8 == 5 }, ""},
+		{"int", func() error { return DecodeInt("i", 12.0, &i8) }, func() bool { return i8 == 12 }, ""},
+		{"int overflow", func() error { return DecodeInt("i", 300.0, &i8) }, nil, "cannot unmarshal number into int8"},
+		{"int fraction", func() error { return DecodeInt("i", json.Number("1.5"), &i8) }, nil, "cannot unmarshal number into int8"},
+		{"int from string", func() error { return DecodeInt("i", "1", &i8) }, nil, "field 'i': cannot unmarshal string into int8"},
+		{"uint", func() error { return DecodeUint("u", 65535, &u16) }, func() bool { return u16 == 65535 }, ""},
+		{"negative uint", func() error { return DecodeUint("u", -1.0, &u16) }, nil, "cannot unmarshal number into uint16"},
+		{"float", func() error { return DecodeFloat("f", int64(3), &f32) }, func() bool { return f32 == 3 }, ""},
+		{"float overflow", func() error { return DecodeFloat("f", 1e300, &f32) }, nil, "cannot unmarshal number into float32"},
+		{"string", func() error { return DecodeString("s", "x", &s) }, func() bool { return s == "x" }, ""},
+		{"string from object", func() error { return DecodeString("s", map[string]interface{}{}, &s) }, nil, "cannot unmarshal object into string"},
+		{"bool", func() error { return DecodeBool("b", true, &b) }, func() bool { return b }, ""},
+		{"bool from array", func() error { return DecodeBool("b", []interface{}{}, &b) }, nil, "cannot unmarshal array into bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decode()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !tt.check() {
+				t.Errorf("error = %v, or the field wasn't set", err)
+			}
+		})
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	var p point
+	if err := DecodeValue("p", map[string]interface{}{"X": 1.0, "y": json.Number("2")}, &p); err != nil || p.X != 1 || p.Y != 2 {
+		t.Errorf("DecodeValue() through DecodeMap = %+v, %v", p, err)
+	}
+
+	var tags []string
+	if err := DecodeValue("tags", []interface{}{"a"}, &tags); err != nil || !reflect.DeepEqual(tags, []string{"a"}) {
+		t.Errorf("DecodeValue() by reflection = %v, %v", tags, err)
+	}
+	if err := DecodeValue("tags", "a", &tags); err == nil || !strings.Contains(err.Error(), "tags") {
+		t.Errorf("DecodeValue() of a mismatched value error = %v, want one naming the field", err)
+	}
+}
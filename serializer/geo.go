@@ -0,0 +1,284 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// geoJSONTypes are the GeoJSON geometry types, with the depth of nesting of their coordinates:
+// 0 for a position, 1 for a list of positions, and so on.
+var geoJSONTypes = map[string]int{
+	"Point":           0,
+	"MultiPoint":      1,
+	"LineString":      1,
+	"MultiLineString": 2,
+	"Polygon":         2,
+	"MultiPolygon":    3,
+}
+
+// Point is a geographic position in WGS 84 degrees. It is written as a GeoJSON Point,
+// {"type": "Point", "coordinates": [lng, lat]}, longitude first, and read from a GeoJSON Point or
+// from an object with lat and lng (or lon, latitude and longitude) keys.
+type Point struct {
+	Lat float64 // Latitude, from -90 to 90
+	Lng float64 // Longitude, from -180 to 180
+}
+
+// Validate checks that the coordinates are within range.
+func (p Point) Validate() error {
+	return validPosition([]interface{}{p.Lng, p.Lat})
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.geoJSON())
+}
+
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil {
+		return nil
+	}
+	parsed, err := parsePoint(value)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// geoJSON returns the point as a GeoJSON Point object.
+func (p Point) geoJSON() map[string]interface{} {
+	return map[string]interface{}{"type": "Point", "coordinates": []interface{}{p.Lng, p.Lat}}
+}
+
+// PointField is a geographic position, as read and written by Point. Input is coerced to a
+// GeoJSON Point, and latitudes outside -90..90 and longitudes outside -180..180 are rejected.
+type PointField struct {
+	Required bool
+}
+
+func (f PointField) Validate(value interface{}) error {
+	_, err := parsePoint(value)
+	return err
+}
+
+func (f PointField) Coerce(value interface{}) (interface{}, error) {
+	p, err := parsePoint(value)
+	if err != nil {
+		return nil, err
+	}
+	return p.geoJSON(), nil
+}
+
+func (f PointField) Represent(value interface{}) (interface{}, error) {
+	return f.Coerce(value)
+}
+
+func (f PointField) IsRequired() bool { return f.Required }
+
+// GeoJSONField is a GeoJSON geometry (RFC 7946): a Point, MultiPoint, LineString,
+// MultiLineString, Polygon, MultiPolygon or GeometryCollection. Its validation checks the
+// structure of the coordinates, their ranges, and that the rings of polygons are closed and
+// have at least four positions. Types restricts the accepted geometry types; Features also
+// accepts Feature and FeatureCollection objects, whose geometries are checked the same way.
+type GeoJSONField struct {
+	Types    []string // Accepted geometry types, e.g. "Polygon"; every type when empty
+	Features bool     // Accept Feature and FeatureCollection objects too
+	Required bool
+}
+
+func (f GeoJSONField) Validate(value interface{}) error {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return NewCodedError(CodeInvalidGeometry, "value is not a GeoJSON object")
+	}
+	typ, _ := object["type"].(string)
+	switch typ {
+	case "Feature", "FeatureCollection":
+		if !f.Features {
+			return NewCodedError(CodeInvalidGeometry, fmt.Sprintf("GeoJSON type %s is not allowed", typ))
+		}
+		return f.validateFeature(object)
+	}
+	return f.validateGeometry(object)
+}
+
+func (f GeoJSONField) IsRequired() bool { return f.Required }
+
+// validateFeature checks a Feature, or the features of a FeatureCollection.
+func (f GeoJSONField) validateFeature(object map[string]interface{}) error {
+	if object["type"] == "FeatureCollection" {
+		features, ok := object["features"].([]interface{})
+		if !ok {
+			return NewCodedError(CodeInvalidGeometry, "FeatureCollection needs a list of features")
+		}
+		for i, feature := range features {
+			member, ok := feature.(map[string]interface{})
+			if !ok || member["type"] != "Feature" {
+				return NewCodedError(CodeInvalidGeometry, fmt.Sprintf("feature %d is not a Feature", i))
+			}
+			if err := f.validateFeature(member); err != nil {
+				return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("feature %d: %v", i, err), Err: err}
+			}
+		}
+		return nil
+	}
+	geometry, exists := object["geometry"]
+	if !exists {
+		return NewCodedError(CodeInvalidGeometry, "Feature needs a geometry")
+	}
+	if geometry == nil {
+		return nil // An unlocated feature
+	}
+	member, ok := geometry.(map[string]interface{})
+	if !ok {
+		return NewCodedError(CodeInvalidGeometry, "geometry is not a GeoJSON object")
+	}
+	return f.validateGeometry(member)
+}
+
+// validateGeometry checks a geometry object.
+func (f GeoJSONField) validateGeometry(object map[string]interface{}) error {
+	typ, _ := object["type"].(string)
+	if len(f.Types) > 0 && !containsField(f.Types, typ) {
+		return NewCodedError(CodeInvalidGeometry, fmt.Sprintf("geometry must be a %s", strings.Join(f.Types, " or ")))
+	}
+	if typ == "GeometryCollection" {
+		geometries, ok := object["geometries"].([]interface{})
+		if !ok {
+			return NewCodedError(CodeInvalidGeometry, "GeometryCollection needs a list of geometries")
+		}
+		for i, geometry := range geometries {
+			member, ok := geometry.(map[string]interface{})
+			if !ok {
+				return NewCodedError(CodeInvalidGeometry, fmt.Sprintf("geometry %d is not a GeoJSON object", i))
+			}
+			if err := (GeoJSONField{}).validateGeometry(member); err != nil {
+				return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("geometry %d: %v", i, err), Err: err}
+			}
+		}
+		return nil
+	}
+	depth, ok := geoJSONTypes[typ]
+	if !ok {
+		return NewCodedError(CodeInvalidGeometry, fmt.Sprintf("unknown GeoJSON geometry type '%v'", object["type"]))
+	}
+	return validCoordinates(typ, object["coordinates"], depth)
+}
+
+// validCoordinates checks coordinates nested depth levels deep for a geometry of type typ.
+func validCoordinates(typ string, coordinates interface{}, depth int) error {
+	if depth == 0 {
+		return validPosition(coordinates)
+	}
+	items, ok := coordinates.([]interface{})
+	if !ok {
+		return NewCodedError(CodeInvalidGeometry, fmt.Sprintf("coordinates of a %s must be a list", typ))
+	}
+	if depth == 1 {
+		// items are the positions of a point list, line or ring
+		switch typ {
+		case "LineString", "MultiLineString":
+			if len(items) < 2 {
+				return NewCodedError(CodeInvalidGeometry, "a line needs at least two positions")
+			}
+		case "Polygon", "MultiPolygon":
+			if err := validRing(items); err != nil {
+				return err
+			}
+		}
+	}
+	for _, item := range items {
+		if err := validCoordinates(typ, item, depth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validRing checks that a linear ring of a polygon is closed and has at least four positions.
+func validRing(positions []interface{}) error {
+	if len(positions) < 4 {
+		return NewCodedError(CodeInvalidGeometry, "a polygon ring needs at least four positions")
+	}
+	first, _ := positions[0].([]interface{})
+	last, _ := positions[len(positions)-1].([]interface{})
+	if len(first) < 2 || len(last) < 2 || !sameEnumValue(first[0], last[0]) || !sameEnumValue(first[1], last[1]) {
+		return NewCodedError(CodeInvalidGeometry, "a polygon ring must end at its first position")
+	}
+	return nil
+}
+
+// validPosition checks a GeoJSON position: a longitude, a latitude and an optional altitude.
+func validPosition(value interface{}) error {
+	position, ok := value.([]interface{})
+	if !ok || len(position) < 2 || len(position) > 3 {
+		return NewCodedError(CodeInvalidGeometry, "a position must be a list of longitude, latitude and optional altitude")
+	}
+	coordinates := make([]float64, len(position))
+	for i, item := range position {
+		n, ok := numberValue(item)
+		if !ok {
+			return NewCodedError(CodeInvalidGeometry, "coordinates must be numbers")
+		}
+		coordinates[i] = n
+	}
+	if coordinates[0] < -180 || coordinates[0] > 180 {
+		return NewCodedErrorWithParams(CodeInvalidCoordinates, fmt.Sprintf("longitude %v is outside -180 to 180", coordinates[0]), Params{"longitude": coordinates[0]})
+	}
+	if coordinates[1] < -90 || coordinates[1] > 90 {
+		return NewCodedErrorWithParams(CodeInvalidCoordinates, fmt.Sprintf("latitude %v is outside -90 to 90", coordinates[1]), Params{"latitude": coordinates[1]})
+	}
+	return nil
+}
+
+// parsePoint reads a Point from a GeoJSON Point or from an object with latitude and longitude
+// keys, and checks its coordinates.
+func parsePoint(value interface{}) (Point, error) {
+	var p Point
+	switch v := value.(type) {
+	case Point:
+		p = v
+	case *Point:
+		if v == nil {
+			return Point{}, NewCodedError(CodeInvalidType, "value is not a point")
+		}
+		p = *v
+	case map[string]interface{}:
+		if typ, exists := v["type"]; exists {
+			if typ != "Point" {
+				return Point{}, NewCodedError(CodeInvalidGeometry, "value is not a GeoJSON Point")
+			}
+			if err := validPosition(v["coordinates"]); err != nil {
+				return Point{}, err
+			}
+			position := v["coordinates"].([]interface{})
+			p.Lng, _ = numberValue(position[0])
+			p.Lat, _ = numberValue(position[1])
+			return p, nil
+		}
+		lat, latOK := numberValue(firstKey(v, "lat", "latitude"))
+		lng, lngOK := numberValue(firstKey(v, "lng", "lon", "longitude"))
+		if !latOK || !lngOK {
+			return Point{}, NewCodedError(CodeInvalidType, "a point needs numeric lat and lng")
+		}
+		p = Point{Lat: lat, Lng: lng}
+	default:
+		return Point{}, NewCodedError(CodeInvalidType, "value is not a point")
+	}
+	return p, p.Validate()
+}
+
+// firstKey returns the value of the first of keys present in object.
+func firstKey(object map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, exists := object[key]; exists {
+			return value
+		}
+	}
+	return nil
+}
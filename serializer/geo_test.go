@@ -0,0 +1,117 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPointField(t *testing.T) {
+	want := map[string]interface{}{"type": "Point", "coordinates": []interface{}{-77.03, -12.05}}
+	tests := []struct {
+		name  string
+		value interface{}
+		code  string // Expected error code, or "" when the value is valid
+	}{
+		{"GeoJSON", map[string]interface{}{"type": "Point", "coordinates": []interface{}{-77.03, -12.05}}, ""},
+		{"lat and lng", map[string]interface{}{"lat": -12.05, "lng": -77.03}, ""},
+		{"latitude and longitude", map[string]interface{}{"latitude": json.Number("-12.05"), "longitude": json.Number("-77.03")}, ""},
+		{"lon", map[string]interface{}{"lat": -12.05, "lon": -77.03}, ""},
+		{"Point", Point{Lat: -12.05, Lng: -77.03}, ""},
+		{"*Point", &Point{Lat: -12.05, Lng: -77.03}, ""},
+		{"nil *Point", (*Point)(nil), CodeInvalidType},
+		{"other GeoJSON type", map[string]interface{}{"type": "LineString", "coordinates": []interface{}{}}, CodeInvalidGeometry},
+		{"latitude out of range", map[string]interface{}{"lat": 91, "lng": 0}, CodeInvalidCoordinates},
+		{"longitude out of range", map[string]interface{}{"type": "Point", "coordinates": []interface{}{-181, 0}}, CodeInvalidCoordinates},
+		{"missing lng", map[string]interface{}{"lat": 1}, CodeInvalidType},
+		{"string", "-12.05,-77.03", CodeInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PointField{}.Coerce(tt.value)
+			if code := ErrorCode(err); code != tt.code {
+				t.Fatalf("Coerce() error = %v, want code %q", err, tt.code)
+			}
+			if tt.code == "" && !reflect.DeepEqual(got, want) {
+				t.Errorf("Coerce() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPointJSON(t *testing.T) {
+	p := Point{Lat: 1.5, Lng: -2}
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"coordinates":[-2,1.5],"type":"Point"}`; string(encoded) != want {
+		t.Errorf("json.Marshal() = %s, want %s", encoded, want)
+	}
+
+	tests := []struct {
+		input   string
+		want    Point
+		wantErr bool
+	}{
+		{string(encoded), p, false},
+		{`{"lat":1.5,"lng":-2}`, p, false},
+		{`null`, Point{Lat: 9, Lng: 9}, false},
+		{`{"lat":100,"lng":0}`, Point{}, true},
+		{`[1,2]`, Point{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Point{Lat: 9, Lng: 9}
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("json.Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoJSONField(t *testing.T) {
+	square := []interface{}{[]interface{}{0, 0}, []interface{}{1, 0}, []interface{}{1, 1}, []interface{}{0, 0}}
+	polygon := map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{square}}
+	line := map[string]interface{}{"type": "LineString", "coordinates": []interface{}{[]interface{}{0, 0}, []interface{}{1, 1, 10}}}
+	tests := []struct {
+		name  string
+		field GeoJSONField
+		value interface{}
+		code  string // Expected error code, or "" when the value is valid
+	}{
+		{"polygon", GeoJSONField{}, polygon, ""},
+		{"line with altitude", GeoJSONField{}, line, ""},
+		{"multi polygon", GeoJSONField{}, map[string]interface{}{"type": "MultiPolygon", "coordinates": []interface{}{[]interface{}{square}}}, ""},
+		{"geometry collection", GeoJSONField{}, map[string]interface{}{"type": "GeometryCollection", "geometries": []interface{}{polygon, line}}, ""},
+		{"allowed type", GeoJSONField{Types: []string{"Polygon"}}, polygon, ""},
+		{"type not allowed", GeoJSONField{Types: []string{"Polygon"}}, line, CodeInvalidGeometry},
+		{"feature collection", GeoJSONField{Features: true}, map[string]interface{}{"type": "FeatureCollection", "features": []interface{}{
+			map[string]interface{}{"type": "Feature", "geometry": polygon},
+			map[string]interface{}{"type": "Feature", "geometry": nil},
+		}}, ""},
+		{"features not allowed", GeoJSONField{}, map[string]interface{}{"type": "Feature", "geometry": polygon}, CodeInvalidGeometry},
+		{"feature without geometry", GeoJSONField{Features: true}, map[string]interface{}{"type": "Feature"}, CodeInvalidGeometry},
+		{"invalid feature member", GeoJSONField{Features: true}, map[string]interface{}{"type": "FeatureCollection", "features": []interface{}{polygon}}, CodeInvalidGeometry},
+		{"open ring", GeoJSONField{}, map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{square[:3]}}, CodeInvalidGeometry},
+		{"unclosed ring", GeoJSONField{}, map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{append(square[:3:3], []interface{}{0, 1})}}, CodeInvalidGeometry},
+		{"short line", GeoJSONField{}, map[string]interface{}{"type": "LineString", "coordinates": []interface{}{[]interface{}{0, 0}}}, CodeInvalidGeometry},
+		{"nested coordinates out of range", GeoJSONField{}, map[string]interface{}{"type": "MultiPoint", "coordinates": []interface{}{[]interface{}{0, 95}}}, CodeInvalidCoordinates},
+		{"collection member out of range", GeoJSONField{}, map[string]interface{}{"type": "GeometryCollection", "geometries": []interface{}{
+			map[string]interface{}{"type": "Point", "coordinates": []interface{}{200, 0}},
+		}}, CodeInvalidCoordinates},
+		{"unknown type", GeoJSONField{}, map[string]interface{}{"type": "Circle"}, CodeInvalidGeometry},
+		{"not an object", GeoJSONField{}, "POINT(0 0)", CodeInvalidGeometry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.field.Validate(tt.value); ErrorCode(err) != tt.code {
+				t.Errorf("Validate() error = %v, want code %q", err, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+func init() {
+	// Concrete types held by the interface values of serialized output
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(json.Number(""))
+}
+
+// SerializeToGob serializes data and encodes the output with encoding/gob, a compact binary
+// format for Go-to-Go caching and persistence. Values set by transformations and hooks are
+// reduced to maps, lists and scalars first, so no types need to be registered with gob.
+func (s *BaseSerializer) SerializeToGob(data interface{}) ([]byte, error) {
+	return s.SerializeAsWithContext(context.Background(), data, FormatGob)
+}
+
+// DeserializeFromGob decodes output written by SerializeToGob and deserializes it into out.
+func (s *BaseSerializer) DeserializeFromGob(data []byte, out interface{}) error {
+	input, err := s.DecodeGob(data)
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(input, out)
+}
+
+// DecodeGob decodes output written by SerializeToGob into a map.
+func (s *BaseSerializer) DecodeGob(data []byte) (map[string]interface{}, error) {
+	return decodeGobFrom(bytes.NewReader(data))
+}
+
+// decodeGobFrom decodes output written by SerializeToGob read from r into a map.
+func decodeGobFrom(r io.Reader) (map[string]interface{}, error) {
+	var input map[string]interface{}
+	if err := gob.NewDecoder(r).Decode(&input); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to decode gob: %v", err), Err: err}
+	}
+	return input, nil
+}
+
+// DeserializeAs decodes data in the given format and deserializes it into out. It is the
+// counterpart of SerializeAs for JSON, YAML, gob, the binary format and the formats registered
+// with RegisterFormat.
+func (s *BaseSerializer) DeserializeAs(data []byte, format Format, out interface{}) error {
+	switch format {
+	case FormatJSON:
+		return s.DeserializeJSON(data, out)
+	case FormatYAML:
+		return s.DeserializeYAML(data, out)
+	case FormatGob:
+		return s.DeserializeFromGob(data, out)
+	case FormatBinary:
+		return s.DeserializeFromBinary(data, out)
+	default:
+		codec, ok := LookupFormat(format)
+		if !ok {
+			return &SerializationError{Message: fmt.Sprintf("unsupported format '%s'", format)}
+		}
+		input, err := s.decodeWithCodec(data, format, codec)
+		if err != nil {
+			return err
+		}
+		return s.Deserialize(input, out)
+	}
+}
+
+// encodeGob encodes serialized output with encoding/gob.
+func (s *BaseSerializer) encodeGob(result map[string]interface{}) ([]byte, error) {
+	normalized, err := toJSONValueWith(reflect.ValueOf(result), &encodeOptions{marshalers: true, numbers: s.UseNumber})
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode gob: %v", err), Err: err}
+	}
+	object, _ := normalized.(map[string]interface{})
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(object); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode gob: %v", err), Err: err}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *BaseSerializer
+		want map[string]interface{}
+	}{
+		{"floats", &BaseSerializer{}, map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin", "at": "2024-05-01T00:00:00Z", "tags": []interface{}{map[string]interface{}{"n": 1.0}}}},
+		{"numbers", &BaseSerializer{UseNumber: true}, map[string]interface{}{"id": json.Number("7"), "name": "ana", "role": "admin", "at": "2024-05-01T00:00:00Z", "tags": []interface{}{map[string]interface{}{"n": json.Number("1")}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Values set by transformations and computed fields are reduced before encoding
+			tt.s.ComputedFields = map[string]func(interface{}) (interface{}, error){
+				"at":   func(interface{}) (interface{}, error) { return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), nil },
+				"tags": func(interface{}) (interface{}, error) { return []map[string]int{{"n": 1}}, nil },
+			}
+			encoded, err := tt.s.SerializeToGob(account{ID: 7, Name: "ana", Role: "admin"})
+			if err != nil {
+				t.Fatalf("SerializeToGob() error = %v", err)
+			}
+			got, err := tt.s.DecodeGob(encoded)
+			if err != nil {
+				t.Fatalf("DecodeGob() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeGob() = %#v, want %#v", got, tt.want)
+			}
+			var out account
+			if err := tt.s.DeserializeFromGob(encoded, &out); err != nil || out != (account{ID: 7, Name: "ana", Role: "admin"}) {
+				t.Errorf("DeserializeFromGob() = %+v, %v", out, err)
+			}
+		})
+	}
+}
+
+func TestDeserializeAs(t *testing.T) {
+	s := &BaseSerializer{}
+	want := account{ID: 7, Name: "ana", Role: "admin"}
+	tests := []struct {
+		name    string
+		format  Format
+		wantErr bool
+	}{
+		{"JSON", FormatJSON, false},
+		{"YAML", FormatYAML, false},
+		{"gob", FormatGob, false},
+		{"binary", FormatBinary, false},
+		{"unsupported", Format("ini"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("x")
+			if !tt.wantErr {
+				var err error
+				if data, err = s.SerializeAs(want, tt.format); err != nil {
+					t.Fatalf("SerializeAs() error = %v", err)
+				}
+			}
+			var got account
+			err := s.DeserializeAs(data, tt.format, &got)
+			if tt.wantErr {
+				if _, ok := err.(*SerializationError); !ok {
+					t.Errorf("DeserializeAs() error = %v, want a SerializationError", err)
+				}
+				return
+			}
+			if err != nil || got != want {
+				t.Errorf("DeserializeAs() = %+v, %v, want %+v", got, err, want)
+			}
+		})
+	}
+
+	if _, err := s.DecodeGob([]byte("garbage")); err == nil {
+		t.Errorf("DecodeGob() error = nil, want an error")
+	}
+}
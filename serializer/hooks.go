@@ -0,0 +1,35 @@
+package serializer
+
+import "context"
+
+// Hooks are callbacks run at fixed points of the serialization lifecycle. Any of them may be nil.
+// An error returned by a hook aborts the call and is returned unchanged.
+type Hooks struct {
+	PreSerialize    func(ctx context.Context, data interface{}) error                                // Before data is converted into a map
+	PostSerialize   func(ctx context.Context, data interface{}, result map[string]interface{}) error // On the final output, which it may change
+	PreDeserialize  func(ctx context.Context, input map[string]interface{}) error                    // On a copy of the input, which it may change
+	PostDeserialize func(ctx context.Context, input map[string]interface{}, out interface{}) error   // After out has been filled
+}
+
+// DeserializeWithContext deserializes input like Deserialize, passing ctx to the hooks.
+func (s *BaseSerializer) DeserializeWithContext(ctx context.Context, input map[string]interface{}, out interface{}) error {
+	if s.Hooks.PreDeserialize != nil {
+		copied := make(map[string]interface{}, len(input))
+		for key, value := range input {
+			copied[key] = value
+		}
+		if err := s.Hooks.PreDeserialize(ctx, copied); err != nil {
+			return err
+		}
+		input = copied
+	}
+
+	if err := s.deserialize(ctx, input, out); err != nil {
+		return err
+	}
+
+	if s.Hooks.PostDeserialize != nil {
+		return s.Hooks.PostDeserialize(ctx, input, out)
+	}
+	return nil
+}
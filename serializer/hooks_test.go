@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSerializeHooks(t *testing.T) {
+	errRejected := errors.New("rejected")
+	tests := []struct {
+		name    string
+		hooks   Hooks
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{"none", Hooks{}, map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin"}, nil},
+		{"post changes the output", Hooks{PostSerialize: func(_ context.Context, data interface{}, result map[string]interface{}) error {
+			result["kind"] = reflect.TypeOf(data).Name()
+			delete(result, "role")
+			return nil
+		}}, map[string]interface{}{"id": 7.0, "name": "ana", "kind": "account"}, nil},
+		{"pre aborts", Hooks{PreSerialize: func(context.Context, interface{}) error { return errRejected }}, nil, errRejected},
+		{"post aborts", Hooks{PostSerialize: func(context.Context, interface{}, map[string]interface{}) error { return errRejected }}, nil, errRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&BaseSerializer{Hooks: tt.hooks}).Serialize(account{ID: 7, Name: "ana", Role: "admin"})
+			if err != tt.wantErr {
+				t.Fatalf("Serialize() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserializeHooks(t *testing.T) {
+	errRejected := errors.New("rejected")
+	type ctxKey struct{}
+	tests := []struct {
+		name    string
+		hooks   Hooks
+		want    account
+		wantErr error
+	}{
+		{"pre changes a copy of the input", Hooks{PreDeserialize: func(_ context.Context, input map[string]interface{}) error {
+			input["role"] = "user"
+			return nil
+		}}, account{ID: 7, Name: "ana", Role: "user"}, nil},
+		{"post sees the result", Hooks{PostDeserialize: func(ctx context.Context, _ map[string]interface{}, out interface{}) error {
+			out.(*account).Role = ctx.Value(ctxKey{}).(string)
+			return nil
+		}}, account{ID: 7, Name: "ana", Role: "from context"}, nil},
+		{"pre aborts", Hooks{PreDeserialize: func(context.Context, map[string]interface{}) error { return errRejected }}, account{}, errRejected},
+		{"post aborts", Hooks{PostDeserialize: func(context.Context, map[string]interface{}, interface{}) error { return errRejected }}, account{ID: 7, Name: "ana"}, errRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := map[string]interface{}{"id": 7.0, "name": "ana"}
+			ctx := context.WithValue(context.Background(), ctxKey{}, "from context")
+			var got account
+			if err := (&BaseSerializer{Hooks: tt.hooks}).DeserializeWithContext(ctx, input, &got); err != tt.wantErr {
+				t.Fatalf("DeserializeWithContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DeserializeWithContext() = %+v, want %+v", got, tt.want)
+			}
+			if len(input) != 2 {
+				t.Errorf("DeserializeWithContext() changed its input: %v", input)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package serializer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// escapedTagPattern matches tags once escaped by html.EscapeString, capturing the closing slash
+// and the name.
+var escapedTagPattern = regexp.MustCompile(`&lt;(/?)([a-zA-Z][a-zA-Z0-9]*)\b.*?&gt;`)
+
+// EscapeHTML escapes <, >, &, ' and " so that a string is displayed as text when it is embedded
+// in an HTML page, as element content or as a quoted attribute value. It is the usual HTMLSafe
+// policy.
+func EscapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+// AllowTags returns an HTMLSafe policy that escapes strings like EscapeHTML but keeps the given
+// tags, e.g. AllowTags("b", "i", "em", "strong", "p", "br") for rich text. Kept tags lose their
+// attributes, so event handlers and javascript: URLs can't be smuggled in with them; a tag
+// such as <a href> that needs attributes can't be allowed.
+func AllowTags(tags ...string) func(string) string {
+	allowed := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		allowed[strings.ToLower(tag)] = true
+	}
+	return func(s string) string {
+		escaped := html.EscapeString(s)
+		return escapedTagPattern.ReplaceAllStringFunc(escaped, func(tag string) string {
+			match := escapedTagPattern.FindStringSubmatch(tag)
+			if !allowed[strings.ToLower(match[2])] {
+				return tag
+			}
+			return "<" + match[1] + strings.ToLower(match[2]) + ">"
+		})
+	}
+}
+
+// WithHTMLSafe overrides HTMLSafe, e.g. to escape the output of a serializer shared with JSON
+// endpoints when it is rendered into a page.
+func WithHTMLSafe(policy func(string) string) Option {
+	return func(s *BaseSerializer) {
+		s.HTMLSafe = policy
+	}
+}
+
+// htmlSafe applies policy to the keys and strings of result, within nested objects and lists too.
+// Keys may come from user data, such as the keys of a map field, and end up in pages as well.
+func htmlSafe(result map[string]interface{}, policy func(string) string) {
+	safe := htmlSafeValue(result, policy).(map[string]interface{})
+	for key := range result {
+		delete(result, key)
+	}
+	for key, value := range safe {
+		result[key] = value
+	}
+}
+
+// htmlSafeValue applies policy to a string, or to the keys and strings within an object or list,
+// which are copied rather than modified.
+func htmlSafeValue(value interface{}, policy func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return policy(v)
+	case map[string]interface{}:
+		safe := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			safe[policy(key)] = htmlSafeValue(item, policy)
+		}
+		return safe
+	case []interface{}:
+		safe := make([]interface{}, len(v))
+		for i, item := range v {
+			safe[i] = htmlSafeValue(item, policy)
+		}
+		return safe
+	default:
+		return value
+	}
+}
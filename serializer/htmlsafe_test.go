@@ -0,0 +1,85 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEscapeHTML(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain", "plain"},
+		{`<script>alert("x")</script>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{"Tom & Jerry's", "Tom &amp; Jerry&#39;s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := EscapeHTML(tt.input); got != tt.want {
+				t.Errorf("EscapeHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowTags(t *testing.T) {
+	policy := AllowTags("b", "EM", "br")
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"allowed tags", "<b>bold</b> and <em>em</em><br/>", "<b>bold</b> and <em>em</em><br>"},
+		{"case insensitive", "<B>x</B><Em>y</eM>", "<b>x</b><em>y</em>"},
+		{"attributes dropped", `<b onclick="steal()">x</b>`, "<b>x</b>"},
+		{"other tags escaped", "<i>x</i><script>y</script>", "&lt;i&gt;x&lt;/i&gt;&lt;script&gt;y&lt;/script&gt;"},
+		{"prefix of an allowed tag", "<bold>x</bold>", "&lt;bold&gt;x&lt;/bold&gt;"},
+		{"quoted angle bracket", `<b title=">" onclick=x>y</b>`, "<b>&#34; onclick=x&gt;y</b>"},
+		{"text escaped", "a < b & c", "a &lt; b &amp; c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy(tt.input); got != tt.want {
+				t.Errorf("AllowTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLSafe(t *testing.T) {
+	nested := map[string]interface{}{"bio": "<b>x</b>", "<img onerror=x>": "y"}
+	list := []interface{}{"<i>", 1.0}
+	result := map[string]interface{}{"name": "<ana>", "id": 7.0, "profile": nested, "tags": list, "<script>": true}
+	htmlSafe(result, EscapeHTML)
+
+	want := map[string]interface{}{
+		"name":           "&lt;ana&gt;",
+		"id":             7.0,
+		"profile":        map[string]interface{}{"bio": "&lt;b&gt;x&lt;/b&gt;", "&lt;img onerror=x&gt;": "y"},
+		"tags":           []interface{}{"&lt;i&gt;", 1.0},
+		"&lt;script&gt;": true,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("htmlSafe() = %#v, want %#v", result, want)
+	}
+	if nested["bio"] != "<b>x</b>" || list[0] != "<i>" {
+		t.Errorf("htmlSafe() modified nested values in place")
+	}
+
+	s := &BaseSerializer{HTMLSafe: EscapeHTML}
+	got, err := s.SerializeWith(account{Name: "<ana>"}, WithHTMLSafe(AllowTags()))
+	if err != nil || got["name"] != "&lt;ana&gt;" {
+		t.Errorf("Serialize() with WithHTMLSafe = %v, %v", got, err)
+	}
+	type labeled struct {
+		Labels map[string]string `json:"labels"`
+	}
+	got, err = s.Serialize(labeled{Labels: map[string]string{"<b>key</b>": "<i>value</i>"}})
+	if want := map[string]interface{}{"&lt;b&gt;key&lt;/b&gt;": "&lt;i&gt;value&lt;/i&gt;"}; err != nil || !reflect.DeepEqual(got["labels"], want) {
+		t.Errorf("Serialize() of map keys = %v, %v, want %v", got, err, want)
+	}
+	if got, _ := (&BaseSerializer{}).Serialize(account{Name: "<ana>"}); got["name"] != "<ana>" {
+		t.Errorf("Serialize() without HTMLSafe escaped %q", got["name"])
+	}
+}
@@ -0,0 +1,161 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Translator translates validation messages into the language of a locale. The message is
+// identified by its error code (e.g. CodeMinLength) and the parameters the validation reported
+// (e.g. {"min": 3}); message is the English text, to be returned when there is no translation.
+type Translator interface {
+	Translate(locale, code string, params Params, message string) string
+}
+
+// Catalog is a Translator backed by message templates keyed by locale and then by error code.
+// Templates refer to the parameters of the error in braces, e.g. "debe tener al menos {min}
+// caracteres". Lookups fall back from a regional locale ("es-CL") to its language ("es"), and
+// finally to the original message, which is also kept when the template refers to a parameter
+// the error doesn't carry.
+type Catalog map[string]map[string]string
+
+// Translate returns the translation of the message with the given code and parameters for locale.
+func (c Catalog) Translate(locale, code string, params Params, message string) string {
+	for locale != "" {
+		if template, ok := c[locale][code]; ok {
+			if translated, ok := expandTemplate(template, params); ok {
+				return translated
+			}
+			return message
+		}
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return message
+}
+
+// expandTemplate replaces the {name} placeholders of template with the values of params. It
+// returns false if a placeholder has no value.
+func expandTemplate(template string, params Params) (string, bool) {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		value, ok := params[template[start+1:start+end]]
+		if !ok {
+			return "", false
+		}
+		b.WriteString(template[:start])
+		b.WriteString(formatParam(value))
+		template = template[start+end+1:]
+	}
+	b.WriteString(template)
+	return b.String(), true
+}
+
+// formatParam formats a parameter of a message. Lists of names are quoted like in the English
+// messages.
+func formatParam(value interface{}) string {
+	if names, ok := value.([]string); ok {
+		return quoteFields(names)
+	}
+	return fmt.Sprint(value)
+}
+
+// DefaultCatalog contains translations of the messages produced by the built-in validations.
+// CodeInvalid has no entry, so the messages of custom validations are kept as they are.
+var DefaultCatalog = Catalog{
+	"es": {
+		CodeRequired:           "el campo es obligatorio",
+		CodeReadOnly:           "el campo es de solo lectura",
+		CodeDuplicateKey:       "clave duplicada",
+		CodeDuplicateItem:      "el elemento {index} repite el elemento {duplicate}",
+		CodeInvalidKey:         "la clave '{key}' no coincide con {pattern}",
+		CodeInvalidType:        "el valor no es del tipo esperado",
+		CodeNotObject:          "el valor no es un objeto",
+		CodeEmpty:              "el valor no puede estar vacío",
+		CodeNotPositive:        "el valor debe ser positivo",
+		CodeInvalidEmail:       "formato de correo electrónico inválido",
+		CodeMinLength:          "el largo debe ser al menos {min}",
+		CodeMaxLength:          "el largo debe ser como máximo {max}",
+		CodeMissingUppercase:   "la contraseña debe contener al menos una letra mayúscula",
+		CodeMissingLowercase:   "la contraseña debe contener al menos una letra minúscula",
+		CodeMissingNumber:      "la contraseña debe contener al menos un número",
+		CodeMissingSpecial:     "la contraseña debe contener al menos un carácter especial",
+		CodeInvalidTime:        "el valor no es una fecha válida",
+		CodeInvalidDuration:    "el valor no es una duración válida",
+		CodeNotAllowed:         "el valor no está permitido",
+		CodeMinValue:           "el valor debe ser al menos {min}",
+		CodeMaxValue:           "el valor debe ser como máximo {max}",
+		CodeInvalidUUID:        "formato de UUID inválido",
+		CodeOutOfRange:         "el número no cabe en el tipo del campo",
+		CodeDecryptionFailed:   "no se pudo descifrar el valor",
+		CodeNotEncrypted:       "el valor debe estar cifrado",
+		CodeFileTooLarge:       "el archivo supera los {max} bytes",
+		CodeInvalidFileType:    "el tipo de archivo no está permitido",
+		CodeValidationTimeout:  "la validación superó el tiempo límite",
+		CodeInvalidCurrency:    "la moneda no es válida",
+		CodeInvalidAmount:      "el monto no es válido",
+		CodeInvalidGeometry:    "la geometría no es válida",
+		CodeInvalidCoordinates: "las coordenadas están fuera de rango",
+		CodeExactlyOne:         "exactamente uno de {fields} debe estar presente",
+		CodeAtLeastOne:         "al menos uno de {fields} debe estar presente",
+		CodeAtMostOne:          "como máximo uno de {fields} puede estar presente",
+		CodeNotUnique:          "la combinación de {fields} ya existe",
+		CodeDeprecated:         "el campo está obsoleto",
+	},
+}
+
+// localeKey is the context key under which the caller's locale is stored.
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying the locale used to translate messages.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale carried by ctx, or "" if there is none.
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// translate translates the message of an error with the given code and parameters using the
+// serializer's Translator and the locale in ctx, falling back to the serializer's Locale.
+func (s *BaseSerializer) translate(ctx context.Context, code string, params Params, message string) string {
+	if s.Translator == nil {
+		return message
+	}
+	locale := LocaleFromContext(ctx)
+	if locale == "" {
+		locale = s.Locale
+	}
+	if locale == "" {
+		return message
+	}
+	return s.Translator.Translate(locale, code, params, message)
+}
+
+// translateError translates the message of an error returned by a validation. The prefixes added
+// by validations of lists and maps to errors of their items ("item 2: ...") are kept, and the
+// message of the item's error is translated.
+func (s *BaseSerializer) translateError(ctx context.Context, err error) string {
+	message := err.Error()
+	if inner := errors.Unwrap(err); inner != nil && ErrorCode(inner) == ErrorCode(err) {
+		if prefix, ok := strings.CutSuffix(message, inner.Error()); ok {
+			return prefix + s.translateError(ctx, inner)
+		}
+	}
+	return s.translate(ctx, validationCode(err), ErrorParams(err), message)
+}
@@ -0,0 +1,144 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCatalogTranslate(t *testing.T) {
+	catalog := Catalog{
+		"es": {CodeMinLength: "debe tener al menos {min} caracteres", CodeExactlyOne: "falta uno de {fields}"},
+	}
+	tests := []struct {
+		name   string
+		locale string
+		code   string
+		params Params
+		want   string
+	}{
+		{"parameter", "es", CodeMinLength, Params{"min": 3}, "debe tener al menos 3 caracteres"},
+		{"regional locale", "es-CL", CodeMinLength, Params{"min": 3}, "debe tener al menos 3 caracteres"},
+		{"underscore locale", "es_AR", CodeMinLength, Params{"min": 3}, "debe tener al menos 3 caracteres"},
+		{"list of fields", "es", CodeExactlyOne, Params{"fields": []string{"a", "b"}}, "falta uno de 'a', 'b'"},
+		{"missing parameter", "es", CodeMinLength, Params{"exclusive_min": 3}, "english"},
+		{"unknown code", "es", CodeInvalid, nil, "english"},
+		{"unknown locale", "fr", CodeMinLength, Params{"min": 3}, "english"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalog.Translate(tt.locale, tt.code, tt.params, "english"); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationMessagesTranslated(t *testing.T) {
+	key := NewStaticKey(bytes.Repeat([]byte("k"), 32))
+	tests := []struct {
+		name       string
+		serializer *BaseSerializer
+		data       map[string]interface{}
+		want       string
+		params     Params
+	}{
+		{
+			name:       "missing field",
+			serializer: &BaseSerializer{Validations: map[string][]func(interface{}) error{"email": {ValidEmail}}},
+			data:       map[string]interface{}{},
+			want:       "el campo es obligatorio",
+		},
+		{
+			name:       "fixed message",
+			serializer: &BaseSerializer{Validations: map[string][]func(interface{}) error{"email": {ValidEmail}}},
+			data:       map[string]interface{}{"email": "alice"},
+			want:       "formato de correo electrónico inválido",
+		},
+		{
+			name:       "parameterised message",
+			serializer: &BaseSerializer{Validations: map[string][]func(interface{}) error{"tags": {MinItems(2)}}},
+			data:       map[string]interface{}{"tags": []interface{}{"a"}},
+			want:       "el largo debe ser al menos 2",
+			params:     Params{"min": 2},
+		},
+		{
+			name:       "item prefix kept",
+			serializer: &BaseSerializer{Validations: map[string][]func(interface{}) error{"tags": {Each(NotEmpty)}}},
+			data:       map[string]interface{}{"tags": []interface{}{"a", ""}},
+			want:       "item 1: el valor no puede estar vacío",
+		},
+		{
+			name:       "custom message kept",
+			serializer: &BaseSerializer{Validations: map[string][]func(interface{}) error{"name": {func(interface{}) error { return errors.New("taken") }}}},
+			data:       map[string]interface{}{"name": "a"},
+			want:       "taken",
+		},
+		{
+			name:       "constraint",
+			serializer: &BaseSerializer{Constraints: []Constraint{ExactlyOneOf("email", "phone")}},
+			data:       map[string]interface{}{},
+			want:       "exactamente uno de 'email', 'phone' debe estar presente",
+			params:     Params{"fields": []string{"email", "phone"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.serializer.Translator = DefaultCatalog
+			err := tt.serializer.ValidateWithContext(WithLocale(context.Background(), "es-CL"), tt.data)
+			var message string
+			var params Params
+			var validationErr *ValidationError
+			var constraintErr *ConstraintError
+			switch {
+			case errors.As(err, &validationErr):
+				message, params = validationErr.Message, validationErr.Params
+			case errors.As(err, &constraintErr):
+				message, params = constraintErr.Message, constraintErr.Params
+			default:
+				t.Fatalf("ValidateWithContext() error = %v, want a validation error", err)
+			}
+			if message != tt.want {
+				t.Errorf("message = %q, want %q", message, tt.want)
+			}
+			if tt.params != nil && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("Params = %v, want %v", params, tt.params)
+			}
+		})
+	}
+
+	t.Run("not encrypted", func(t *testing.T) {
+		s := &BaseSerializer{EncryptedFields: []string{"ssn"}, KeyProvider: key, Translator: DefaultCatalog, Locale: "es"}
+		var out patient
+		var validationErr *ValidationError
+		err := s.Deserialize(map[string]interface{}{"ssn": "123-45-6789"}, &out)
+		if !errors.As(err, &validationErr) || validationErr.Message != "el valor debe estar cifrado" {
+			t.Errorf("Deserialize() error = %v, want a translated not_encrypted error", err)
+		}
+	})
+}
+
+func TestDefaultCatalogCoversValidationCodes(t *testing.T) {
+	// Codes of serialization failures, and the code of custom messages, are not translated
+	untranslated := map[string]bool{
+		CodeInvalid: true, CodeTransformationNil: true, CodeInvalidEnum: true, CodeComputeFailed: true,
+		CodeEncryptionFailed: true, CodeInvalidSelection: true,
+	}
+	codes := []string{
+		CodeInvalid, CodeRequired, CodeReadOnly, CodeDuplicateKey, CodeDuplicateItem, CodeInvalidKey,
+		CodeInvalidType, CodeNotObject, CodeEmpty, CodeNotPositive, CodeInvalidEmail, CodeMinLength,
+		CodeMaxLength, CodeMissingUppercase, CodeMissingLowercase, CodeMissingNumber, CodeMissingSpecial,
+		CodeTransformationNil, CodeInvalidEnum, CodeComputeFailed, CodeInvalidTime, CodeInvalidDuration,
+		CodeNotAllowed, CodeMinValue, CodeMaxValue, CodeInvalidUUID, CodeOutOfRange, CodeInvalidSelection,
+		CodeEncryptionFailed, CodeDecryptionFailed, CodeNotEncrypted, CodeFileTooLarge, CodeInvalidFileType,
+		CodeValidationTimeout, CodeInvalidCurrency, CodeInvalidAmount, CodeInvalidGeometry,
+		CodeInvalidCoordinates, CodeExactlyOne, CodeAtLeastOne, CodeAtMostOne, CodeNotUnique, CodeDeprecated,
+	}
+	for _, code := range codes {
+		if _, ok := DefaultCatalog["es"][code]; ok == untranslated[code] {
+			t.Errorf("DefaultCatalog[\"es\"] has %s: %v, want %v", code, ok, !untranslated[code])
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	goformat "go/format"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inferredType describes the Go type inferred for a value in a sample payload.
+type inferredType struct {
+	kind   string                   // "string", "int", "float", "bool", "time", "object", "array", "null" or "any"
+	fields map[string]*inferredType // Fields of an object
+	elem   *inferredType            // Element type of an array
+}
+
+// InferStruct produces a Go struct definition and a suggested serializer configuration from a sample
+// JSON or YAML payload. Nested objects become their own struct types.
+func InferStruct(sample []byte, format Format) (string, error) {
+	var value interface{}
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(sample))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return "", &SerializationError{Message: fmt.Sprintf("failed to parse JSON sample: %v", err), Err: err}
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(sample, &value); err != nil {
+			return "", &SerializationError{Message: fmt.Sprintf("failed to parse YAML sample: %v", err), Err: err}
+		}
+	default:
+		return "", &SerializationError{Message: fmt.Sprintf("unsupported sample format '%s'", format)}
+	}
+
+	root := inferType(value)
+	if root.kind == "array" && root.elem != nil {
+		root = root.elem // A list of objects describes the element type
+	}
+	if root.kind != "object" {
+		return "", &SerializationError{Message: "sample must be an object or a list of objects"}
+	}
+
+	g := &structGenerator{names: make(map[string]bool)}
+	g.generate("Root", root)
+	g.suggestSerializer("Root", root)
+
+	source, err := goformat.Source(g.buf.Bytes())
+	if err != nil {
+		return "", &SerializationError{Message: fmt.Sprintf("failed to format generated code: %v", err), Err: err}
+	}
+	return string(source), nil
+}
+
+// inferType infers the type of a decoded sample value.
+func inferType(value interface{}) *inferredType {
+	switch v := value.(type) {
+	case nil:
+		return &inferredType{kind: "null"}
+	case bool:
+		return &inferredType{kind: "bool"}
+	case int, int64, uint64:
+		return &inferredType{kind: "int"}
+	case float64:
+		if v == float64(int64(v)) {
+			return &inferredType{kind: "int"}
+		}
+		return &inferredType{kind: "float"}
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return &inferredType{kind: "int"}
+		}
+		return &inferredType{kind: "float"}
+	case time.Time:
+		return &inferredType{kind: "time"}
+	case string:
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			return &inferredType{kind: "time"}
+		}
+		return &inferredType{kind: "string"}
+	case map[string]interface{}:
+		t := &inferredType{kind: "object", fields: make(map[string]*inferredType)}
+		for key, item := range v {
+			t.fields[key] = inferType(item)
+		}
+		return t
+	case []interface{}:
+		t := &inferredType{kind: "array"}
+		for _, item := range v {
+			t.elem = mergeTypes(t.elem, inferType(item))
+		}
+		return t
+	default:
+		return &inferredType{kind: "any"}
+	}
+}
+
+// mergeTypes combines two inferred types, widening to "any" when they are incompatible.
+func mergeTypes(a, b *inferredType) *inferredType {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.kind == "null":
+		return b
+	case b.kind == "null":
+		return a
+	case a.kind == b.kind:
+		if a.kind == "object" {
+			merged := &inferredType{kind: "object", fields: make(map[string]*inferredType)}
+			for key, t := range a.fields {
+				merged.fields[key] = t
+			}
+			for key, t := range b.fields {
+				merged.fields[key] = mergeTypes(merged.fields[key], t)
+			}
+			return merged
+		}
+		if a.kind == "array" {
+			return &inferredType{kind: "array", elem: mergeTypes(a.elem, b.elem)}
+		}
+		return a
+	case (a.kind == "int" && b.kind == "float") || (a.kind == "float" && b.kind == "int"):
+		return &inferredType{kind: "float"}
+	default:
+		return &inferredType{kind: "any"}
+	}
+}
+
+// structGenerator writes Go source for inferred object types.
+type structGenerator struct {
+	buf   bytes.Buffer
+	names map[string]bool
+}
+
+// generate writes the struct type for t and any nested struct types it needs.
+func (g *structGenerator) generate(name string, t *inferredType) {
+	g.names[name] = true
+	keys := sortedKeys(t.fields)
+
+	var nested []func()
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	for _, key := range keys {
+		fieldName := exportedName(key)
+		goType := g.goType(fieldName, t.fields[key], &nested)
+		fmt.Fprintf(&g.buf, "\t%s %s `json:\"%s\" yaml:\"%s\"`\n", fieldName, goType, key, key)
+	}
+	g.buf.WriteString("}\n\n")
+
+	for _, write := range nested {
+		write()
+	}
+}
+
+// goType returns the Go type for t, scheduling nested struct definitions when needed.
+func (g *structGenerator) goType(fieldName string, t *inferredType, nested *[]func()) string {
+	switch t.kind {
+	case "string":
+		return "string"
+	case "int":
+		return "int64"
+	case "float":
+		return "float64"
+	case "bool":
+		return "bool"
+	case "time":
+		return "time.Time"
+	case "object":
+		name := g.uniqueName(fieldName)
+		*nested = append(*nested, func() { g.generate(name, t) })
+		return name
+	case "array":
+		if t.elem == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(singular(fieldName), t.elem, nested)
+	default:
+		return "interface{}"
+	}
+}
+
+// uniqueName reserves a type name that hasn't been used yet.
+func (g *structGenerator) uniqueName(name string) string {
+	if name == "" {
+		name = "Object"
+	}
+	candidate := name
+	for i := 2; g.names[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[candidate] = true
+	return candidate
+}
+
+// suggestSerializer writes a BaseSerializer configuration matching the root type.
+func (g *structGenerator) suggestSerializer(name string, t *inferredType) {
+	keys := sortedKeys(t.fields)
+
+	fmt.Fprintf(&g.buf, "var %sSerializer = serializer.BaseSerializer{\n", name)
+	g.buf.WriteString("\tFields: []string{")
+	for i, key := range keys {
+		if i > 0 {
+			g.buf.WriteString(", ")
+		}
+		fmt.Fprintf(&g.buf, "%q", key)
+	}
+	g.buf.WriteString("},\n")
+
+	var validations []string
+	for _, key := range keys {
+		if t.fields[key].kind == "string" && strings.Contains(strings.ToLower(key), "email") {
+			validations = append(validations, fmt.Sprintf("\t\t%q: {serializer.ValidEmail},\n", key))
+		}
+	}
+	if len(validations) > 0 {
+		g.buf.WriteString("\tValidations: map[string][]func(interface{}) error{\n")
+		g.buf.WriteString(strings.Join(validations, ""))
+		g.buf.WriteString("\t},\n")
+	}
+	g.buf.WriteString("}\n")
+}
+
+// sortedKeys returns the keys of an object type in alphabetical order.
+func sortedKeys(fields map[string]*inferredType) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// exportedName converts a payload key such as "user_id" or "firstName" into an exported Go identifier.
+func exportedName(key string) string {
+	name := ConvertKey(key, NamingPascalCase, nil)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Field" + name
+	}
+	return name
+}
+
+// singular derives an element type name from a plural field name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return name[:len(name)-1]
+	default:
+		return name + "Item"
+	}
+}
@@ -0,0 +1,81 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInferStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		sample  string
+		format  Format
+		want    []string // Lines of the generated source
+		wantErr bool
+	}{
+		{"JSON", `{"user_id": 9007199254740993, "price": 1.5, "active": true, "created_at": "2024-01-02T03:04:05Z", "contact_email": "a@b.c", "note": null}`, FormatJSON, []string{
+			"type Root struct {",
+			"Active       bool        `json:\"active\" yaml:\"active\"`",
+			"ContactEmail string      `json:\"contact_email\" yaml:\"contact_email\"`",
+			"CreatedAt    time.Time   `json:\"created_at\" yaml:\"created_at\"`",
+			"Note         interface{} `json:\"note\" yaml:\"note\"`",
+			"Price        float64     `json:\"price\" yaml:\"price\"`",
+			"UserID       int64       `json:\"user_id\" yaml:\"user_id\"`",
+			`Fields: []string{"active", "contact_email", "created_at", "note", "price", "user_id"},`,
+			`"contact_email": {serializer.ValidEmail},`,
+		}, false},
+		{"nested objects and lists", `[{"address": {"city": "Lima"}, "categories": [{"id": 1}, {"id": 2.5, "name": null}], "tags": [], "mixed": [1, "a"]}]`, FormatJSON, []string{
+			"Address    Address       `json:\"address\" yaml:\"address\"`",
+			"Categories []Category    `json:\"categories\" yaml:\"categories\"`",
+			"Mixed      []interface{} `json:\"mixed\" yaml:\"mixed\"`",
+			"Tags       []interface{} `json:\"tags\" yaml:\"tags\"`",
+			"type Address struct {",
+			"City string `json:\"city\" yaml:\"city\"`",
+			"type Category struct {",
+			"ID   float64     `json:\"id\" yaml:\"id\"`",
+		}, false},
+		{"YAML", "count: 3\n1st: x\nroot: {a: 1}\n", FormatYAML, []string{
+			"Count    int64  `json:\"count\" yaml:\"count\"`",
+			"Field1St string `json:\"1st\" yaml:\"1st\"`",
+			"Root     Root2  `json:\"root\" yaml:\"root\"`",
+			"type Root2 struct {",
+		}, false},
+		{"scalar sample", `1`, FormatJSON, nil, true},
+		{"malformed JSON", `{`, FormatJSON, nil, true},
+		{"malformed YAML", "a: [", FormatYAML, nil, true},
+		{"unsupported format", `{}`, FormatXML, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InferStruct([]byte(tt.sample), tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("InferStruct() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InferStruct() error = %v", err)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(got, line) {
+					t.Errorf("InferStruct() = %s\nwant a line %q", got, line)
+				}
+			}
+		})
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"Categories", "Category"},
+		{"Tags", "Tag"},
+		{"Address", "AddressItem"},
+		{"Data", "DataItem"},
+	}
+	for _, tt := range tests {
+		if got := singular(tt.name); got != tt.want {
+			t.Errorf("singular(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
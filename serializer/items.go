@@ -0,0 +1,95 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Each returns a validation that checks every item of a list with rules, e.g. Each(NotEmpty) for
+// a list of tags. The first failure is reported with the index of its item, keeping its code.
+func Each(rules ...func(interface{}) error) func(interface{}) error {
+	rule := And(rules...)
+	return func(value interface{}) error {
+		items, ok := value.([]interface{})
+		if !ok {
+			return NewCodedError(CodeInvalidType, "value is not a list")
+		}
+		for i, item := range items {
+			if err := rule(item); err != nil {
+				return &CodedError{Code: validationCode(err), Message: fmt.Sprintf("item %d: %v", i, err), Err: err}
+			}
+		}
+		return nil
+	}
+}
+
+// MinItems returns a validation that checks that a list has at least n items.
+func MinItems(n int) func(interface{}) error {
+	return func(value interface{}) error {
+		items, ok := value.([]interface{})
+		if !ok {
+			return NewCodedError(CodeInvalidType, "value is not a list")
+		}
+		if len(items) < n {
+			return NewCodedErrorWithParams(CodeMinLength, fmt.Sprintf("list must have at least %d items", n), Params{"min": n})
+		}
+		return nil
+	}
+}
+
+// MaxItems returns a validation that checks that a list has at most n items.
+func MaxItems(n int) func(interface{}) error {
+	return func(value interface{}) error {
+		items, ok := value.([]interface{})
+		if !ok {
+			return NewCodedError(CodeInvalidType, "value is not a list")
+		}
+		if len(items) > n {
+			return NewCodedErrorWithParams(CodeMaxLength, fmt.Sprintf("list must have at most %d items", n), Params{"max": n})
+		}
+		return nil
+	}
+}
+
+// UniqueItems checks that no two items of a list are equal. Numbers are compared by value, so
+// 1 and 1.0 are duplicates, and objects and lists by their contents.
+func UniqueItems(value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return NewCodedError(CodeInvalidType, "value is not a list")
+	}
+	for i := 1; i < len(items); i++ {
+		for j := 0; j < i; j++ {
+			if sameItem(items[i], items[j]) {
+				return NewCodedErrorWithParams(CodeDuplicateItem, fmt.Sprintf("item %d duplicates item %d", i, j), Params{"index": i, "duplicate": j})
+			}
+		}
+	}
+	return nil
+}
+
+// EachItem returns a transformation that applies transform to every item of a list, e.g.
+// EachItem(strings.ToUpper) for a list of codes. The list is copied; other values are returned
+// unchanged.
+func EachItem(transform func(interface{}) interface{}) func(interface{}) interface{} {
+	return func(value interface{}) interface{} {
+		items, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		transformed := make([]interface{}, len(items))
+		for i, item := range items {
+			transformed[i] = transform(item)
+		}
+		return transformed
+	}
+}
+
+// sameItem reports whether two decoded JSON values are equal, comparing numbers by value.
+func sameItem(a, b interface{}) bool {
+	if x, ok := numberValue(a); ok {
+		y, ok := numberValue(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
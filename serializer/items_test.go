@@ -0,0 +1,65 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestItemValidations(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation func(interface{}) error
+		value      interface{}
+		wantCode   string
+	}{
+		{"each valid", Each(Positive), []interface{}{1.0, 2.0}, ""},
+		{"each invalid item", Each(Positive), []interface{}{1.0, -2.0}, CodeNotPositive},
+		{"each of no rules", Each(), []interface{}{"x"}, ""},
+		{"each not a list", Each(Positive), "x", CodeInvalidType},
+		{"min items", MinItems(2), []interface{}{1.0, 2.0}, ""},
+		{"too few items", MinItems(2), []interface{}{1.0}, CodeMinLength},
+		{"max items", MaxItems(1), []interface{}{}, ""},
+		{"too many items", MaxItems(1), []interface{}{1.0, 2.0}, CodeMaxLength},
+		{"min items not a list", MinItems(1), map[string]interface{}{}, CodeInvalidType},
+		{"max items not a list", MaxItems(1), nil, CodeInvalidType},
+		{"unique", UniqueItems, []interface{}{1.0, "1", []interface{}{1.0}, map[string]interface{}{"a": 1.0}}, ""},
+		{"duplicate number", UniqueItems, []interface{}{1.0, json.Number("1"), 2.0}, CodeDuplicateItem},
+		{"duplicate object", UniqueItems, []interface{}{map[string]interface{}{"a": "x"}, map[string]interface{}{"a": "x"}}, CodeDuplicateItem},
+		{"unique not a list", UniqueItems, "x", CodeInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validation(tt.value)
+			if code := ErrorCode(err); code != tt.wantCode || (tt.wantCode == "") != (err == nil) {
+				t.Errorf("validation() error = %v (code %q), want code %q", err, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestEachReportsTheIndex(t *testing.T) {
+	err := Each(Positive)([]interface{}{1.0, 2.0, -3.0})
+	if err == nil || !strings.HasPrefix(err.Error(), "item 2: ") {
+		t.Errorf("Each() error = %v, want one naming item 2", err)
+	}
+	err = UniqueItems([]interface{}{"a", "b", "a"})
+	if params := ErrorParams(err); params["index"] != 2 || params["duplicate"] != 0 {
+		t.Errorf("UniqueItems() params = %v, want index 2 and duplicate 0", params)
+	}
+}
+
+func TestEachItem(t *testing.T) {
+	upper := EachItem(func(v interface{}) interface{} { return strings.ToUpper(v.(string)) })
+	list := []interface{}{"a", "b"}
+	if got := upper(list); !reflect.DeepEqual(got, []interface{}{"A", "B"}) {
+		t.Errorf("EachItem() = %#v, want [A B]", got)
+	}
+	if list[0] != "a" {
+		t.Errorf("EachItem() modified its input")
+	}
+	if got := upper("x"); got != "x" {
+		t.Errorf("EachItem() of a non-list = %#v, want it unchanged", got)
+	}
+}
@@ -0,0 +1,193 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents.
+const JSONAPIContentType = "application/vnd.api+json"
+
+// JSONAPIResource describes how the output of a serializer maps to a JSON:API resource object.
+type JSONAPIResource struct {
+	Type          string   // Resource type, e.g. "users"
+	IDField       string   // Field holding the resource id; "id" when empty
+	Relationships []string // Fields rendered as relationships; their values are serialized by the serializers registered for their types
+}
+
+// jsonAPIDocument collects the resources of a compound document.
+type jsonAPIDocument struct {
+	registry *Registry // Registry of the serializer rendering the document
+	primary  map[string]bool
+	seen     map[string]bool
+	included []interface{}
+}
+
+// SerializeJSONAPI renders data, a struct or a slice of structs, as a JSON:API document:
+//
+//	{"data": {"type": "articles", "id": "1", "attributes": {...}, "relationships": {...}}, "included": [...]}
+//
+// The serializer must have a JSONAPI resource. Relationship fields, at any depth, are serialized
+// with the serializers registered for their types in the Registry of s, which must describe a
+// JSONAPI resource as well; the related resources are added to "included" once.
+func (s *BaseSerializer) SerializeJSONAPI(data interface{}) (map[string]interface{}, error) {
+	return s.SerializeJSONAPIWithContext(context.Background(), data)
+}
+
+// SerializeJSONAPIWithContext is like SerializeJSONAPI, passing ctx to the context-aware pipeline stages.
+func (s *BaseSerializer) SerializeJSONAPIWithContext(ctx context.Context, data interface{}) (map[string]interface{}, error) {
+	if MetadataFromContext(ctx) == nil {
+		ctx = ContextWithMetadata(ctx, NewMetadata())
+	}
+	doc := &jsonAPIDocument{registry: s.registry(), primary: make(map[string]bool), seen: make(map[string]bool)}
+
+	var primary interface{}
+	v := indirectValue(reflect.ValueOf(data))
+	switch {
+	case !v.IsValid():
+		primary = nil
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		resources := make([]interface{}, v.Len())
+		for i := range resources {
+			resource, _, err := s.jsonAPIResource(ctx, v.Index(i).Interface(), doc, true)
+			if err != nil {
+				return nil, &BulkError{Index: i, Err: err}
+			}
+			resources[i] = resource
+		}
+		primary = resources
+	default:
+		resource, _, err := s.jsonAPIResource(ctx, data, doc, true)
+		if err != nil {
+			return nil, err
+		}
+		primary = resource
+	}
+
+	document := map[string]interface{}{"data": primary}
+	included := make([]interface{}, 0, len(doc.included))
+	for _, resource := range doc.included {
+		object := resource.(map[string]interface{})
+		if !doc.primary[jsonAPIKey(object["type"].(string), object["id"].(string))] {
+			included = append(included, resource)
+		}
+	}
+	if len(included) > 0 {
+		document["included"] = included
+	}
+	return document, nil
+}
+
+// jsonAPIResource builds the resource object of data, adding its related resources to doc. Related
+// resources already in doc are not built again; they are returned with their type and id only and
+// added is false.
+func (s *BaseSerializer) jsonAPIResource(ctx context.Context, data interface{}, doc *jsonAPIDocument, primary bool) (resource map[string]interface{}, added bool, err error) {
+	if s.JSONAPI == nil || s.JSONAPI.Type == "" {
+		return nil, false, &SerializationError{Message: fmt.Sprintf("no JSON:API resource type configured for %T", data)}
+	}
+	result, err := s.serializeObject(ctx, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	idField := s.JSONAPI.IDField
+	if idField == "" {
+		idField = "id"
+	}
+	idKey := s.outputKey(idField)
+	id, ok := result[idKey]
+	if !ok || id == nil {
+		return nil, false, &SerializationError{Message: fmt.Sprintf("%s resource has no %q field", s.JSONAPI.Type, idKey)}
+	}
+	delete(result, idKey)
+
+	resource = map[string]interface{}{"type": s.JSONAPI.Type, "id": fmt.Sprint(id)}
+	key := jsonAPIKey(s.JSONAPI.Type, resource["id"].(string))
+	if primary {
+		doc.primary[key] = true
+	} else if doc.seen[key] {
+		return resource, false, nil
+	}
+	doc.seen[key] = true
+
+	relationships := make(map[string]interface{}, len(s.JSONAPI.Relationships))
+	original := reflect.ValueOf(data)
+	for _, field := range s.JSONAPI.Relationships {
+		name := s.outputKey(field)
+		delete(result, name)
+		linkage, err := s.jsonAPIRelationship(ctx, originalChild(original, field), doc)
+		if err != nil {
+			return nil, false, err
+		}
+		relationships[name] = map[string]interface{}{"data": linkage}
+	}
+
+	resource["attributes"] = result
+	if len(relationships) > 0 {
+		resource["relationships"] = relationships
+	}
+	return resource, true, nil
+}
+
+// jsonAPIRelationship returns the resource linkage of a related value (a struct, a slice of
+// structs or nil) and adds the related resources to doc.
+func (s *BaseSerializer) jsonAPIRelationship(ctx context.Context, value reflect.Value, doc *jsonAPIDocument) (interface{}, error) {
+	value = indirectValue(value)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		linkages := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			linkage, err := s.jsonAPIRelationship(ctx, value.Index(i), doc)
+			if err != nil {
+				return nil, err
+			}
+			if linkage != nil {
+				linkages = append(linkages, linkage)
+			}
+		}
+		return linkages, nil
+	}
+
+	registered, ok := doc.registry.lookupType(value.Type())
+	related := baseSerializer(registered)
+	if !ok || related == nil || related.JSONAPI == nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("no JSON:API serializer registered for %s", value.Type())}
+	}
+
+	resource, added, err := related.jsonAPIResource(ctx, value.Interface(), doc, false)
+	if err != nil {
+		return nil, err
+	}
+	if added {
+		doc.included = append(doc.included, resource)
+	}
+	return map[string]interface{}{"type": resource["type"], "id": resource["id"]}, nil
+}
+
+// outputKey returns the key a top-level field is written as, after aliases and KeyNaming.
+func (s *BaseSerializer) outputKey(field string) string {
+	if alias, ok := s.Aliases[field]; ok {
+		field = alias
+	}
+	return s.convertKey(field)
+}
+
+// baseSerializer returns the configuration of a BaseSerializer or an ImmutableSerializer.
+func baseSerializer(s Serializer) *BaseSerializer {
+	switch v := s.(type) {
+	case *BaseSerializer:
+		return v
+	case *ImmutableSerializer:
+		return v.config
+	default:
+		return nil
+	}
+}
+
+// jsonAPIKey identifies a resource within a document.
+func jsonAPIKey(resourceType, id string) string {
+	return resourceType + "/" + id
+}
@@ -0,0 +1,99 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type author struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type article struct {
+	ID       int       `json:"id"`
+	Title    string    `json:"title"`
+	Author   *author   `json:"author"`
+	Coauthor *author   `json:"coauthor"`
+	Reviews  []account `json:"reviews"`
+}
+
+func TestSerializeJSONAPI(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(author{}, &BaseSerializer{JSONAPI: &JSONAPIResource{Type: "people"}})
+	registry.Register(account{}, &BaseSerializer{Fields: []string{"id", "name"}, JSONAPI: &JSONAPIResource{Type: "reviewers"}})
+	articles := &BaseSerializer{Registry: registry, JSONAPI: &JSONAPIResource{Type: "articles", Relationships: []string{"author", "coauthor", "reviews"}}}
+
+	ana := &author{ID: 1, Name: "ana"}
+	single := article{ID: 10, Title: "Go", Author: ana, Reviews: []account{{ID: 5, Name: "bo"}}}
+	got, err := articles.SerializeJSONAPI(single)
+	if err != nil {
+		t.Fatalf("SerializeJSONAPI() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "articles", "id": "10",
+			"attributes": map[string]interface{}{"title": "Go"},
+			"relationships": map[string]interface{}{
+				"author":   map[string]interface{}{"data": map[string]interface{}{"type": "people", "id": "1"}},
+				"coauthor": map[string]interface{}{"data": nil},
+				"reviews":  map[string]interface{}{"data": []interface{}{map[string]interface{}{"type": "reviewers", "id": "5"}}},
+			},
+		},
+		"included": []interface{}{
+			map[string]interface{}{"type": "people", "id": "1", "attributes": map[string]interface{}{"name": "ana"}},
+			map[string]interface{}{"type": "reviewers", "id": "5", "attributes": map[string]interface{}{"name": "bo"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SerializeJSONAPI() = %v, want %v", got, want)
+	}
+
+	// Related resources are included once
+	list, err := articles.SerializeJSONAPI([]article{{ID: 10, Author: ana, Coauthor: ana}, {ID: 11, Author: ana}})
+	if err != nil {
+		t.Fatalf("SerializeJSONAPI() of a list error = %v", err)
+	}
+	if data := list["data"].([]interface{}); len(data) != 2 || len(list["included"].([]interface{})) != 1 {
+		t.Errorf("SerializeJSONAPI() of a list = %v", list)
+	}
+
+	empty, err := articles.SerializeJSONAPI(nil)
+	if err != nil || !reflect.DeepEqual(empty, map[string]interface{}{"data": nil}) {
+		t.Errorf("SerializeJSONAPI(nil) = %v, %v", empty, err)
+	}
+}
+
+func TestSerializeJSONAPIErrors(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(author{}, &BaseSerializer{})
+	tests := []struct {
+		name    string
+		s       *BaseSerializer
+		data    interface{}
+		wantErr string
+	}{
+		{"no resource", &BaseSerializer{}, article{ID: 1}, "no JSON:API resource type configured"},
+		{"no id", &BaseSerializer{JSONAPI: &JSONAPIResource{Type: "articles", IDField: "slug"}}, article{ID: 1}, `articles resource has no "slug" field`},
+		{"unregistered relationship", &BaseSerializer{Registry: NewRegistry(), JSONAPI: &JSONAPIResource{Type: "articles", Relationships: []string{"author"}}},
+			article{ID: 1, Author: &author{ID: 2}}, "no JSON:API serializer registered for serializer.author"},
+		{"relationship without resource", &BaseSerializer{Registry: registry, JSONAPI: &JSONAPIResource{Type: "articles", Relationships: []string{"author"}}},
+			article{ID: 1, Author: &author{ID: 2}}, "no JSON:API serializer registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.s.SerializeJSONAPI(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SerializeJSONAPI() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, err := (&BaseSerializer{}).SerializeJSONAPI([]article{{ID: 1}})
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 0 {
+		t.Errorf("SerializeJSONAPI() of a list error = %v, want a BulkError", err)
+	}
+}
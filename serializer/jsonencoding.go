@@ -0,0 +1,94 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONEncoding controls the layout of JSON output.
+type JSONEncoding struct {
+	Prefix       string // Written at the start of every line of indented output
+	Indent       string // Indentation of nested values; output is compact when Indent and Prefix are empty
+	NoEscapeHTML bool   // Write <, > and & as they are instead of as \u003c, \u003e and \u0026
+}
+
+// EncodeJSON encodes a serialized value as JSON with the given layout.
+func EncodeJSON(value interface{}, encoding JSONEncoding) ([]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode JSON: %v", err), Err: err}
+	}
+	return encoding.format(encoded)
+}
+
+// EncodeJSON encodes a serialized value, or a list of them, as JSON with the layout of JSONEncoding.
+func (s *BaseSerializer) EncodeJSON(value interface{}) ([]byte, error) {
+	return EncodeJSON(value, s.JSONEncoding)
+}
+
+// WithJSONEncoding overrides the layout of JSON output.
+func WithJSONEncoding(encoding JSONEncoding) Option {
+	return func(s *BaseSerializer) {
+		s.JSONEncoding = encoding
+	}
+}
+
+// WithUseNumber overrides UseNumber.
+func WithUseNumber(useNumber bool) Option {
+	return func(s *BaseSerializer) {
+		s.UseNumber = useNumber
+	}
+}
+
+// format lays out compact JSON written by encoding/json.
+func (e JSONEncoding) format(compact []byte) ([]byte, error) {
+	if e.NoEscapeHTML {
+		compact = unescapeHTML(compact)
+	}
+	if e.Prefix == "" && e.Indent == "" {
+		return compact, nil
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact, e.Prefix, e.Indent); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode JSON: %v", err), Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+// newEncoder returns an encoder writing to w with the layout of e.
+func (e JSONEncoding) newEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!e.NoEscapeHTML)
+	enc.SetIndent(e.Prefix, e.Indent)
+	return enc
+}
+
+// htmlEscapes are the escapes encoding/json writes for HTML characters.
+var htmlEscapes = map[string]byte{`\u003c`: '<', `\u003e`: '>', `\u0026`: '&'}
+
+// unescapeHTML reverts the escaping of HTML characters in JSON written by encoding/json. Outside
+// of strings JSON has no backslashes, so every backslash starts an escape.
+func unescapeHTML(encoded []byte) []byte {
+	if !bytes.Contains(encoded, []byte(`\u00`)) {
+		return encoded
+	}
+	out := make([]byte, 0, len(encoded))
+	for i := 0; i < len(encoded); i++ {
+		if encoded[i] != '\\' || i+1 == len(encoded) {
+			out = append(out, encoded[i])
+			continue
+		}
+		if i+6 <= len(encoded) {
+			if c, ok := htmlEscapes[string(encoded[i:i+6])]; ok {
+				out = append(out, c)
+				i += 5
+				continue
+			}
+		}
+		out = append(out, encoded[i], encoded[i+1])
+		i++
+	}
+	return out
+}
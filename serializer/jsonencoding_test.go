@@ -0,0 +1,56 @@
+package serializer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	value := map[string]interface{}{"html": "<a>&</a>", "list": []interface{}{1}, "path": `C:\u003c`}
+	tests := []struct {
+		name     string
+		encoding JSONEncoding
+		want     string
+	}{
+		{"compact", JSONEncoding{}, `{"html":"\u003ca\u003e\u0026\u003c/a\u003e","list":[1],"path":"C:\\u003c"}`},
+		{"no HTML escaping", JSONEncoding{NoEscapeHTML: true}, `{"html":"<a>&</a>","list":[1],"path":"C:\\u003c"}`},
+		{"indented", JSONEncoding{Indent: "  ", NoEscapeHTML: true}, "{\n  \"html\": \"<a>&</a>\",\n  \"list\": [\n    1\n  ],\n  \"path\": \"C:\\\\u003c\"\n}"},
+		{"prefix", JSONEncoding{Prefix: "> ", Indent: "\t"}, "{\n> \t\"html\": \"\\u003ca\\u003e\\u0026\\u003c/a\\u003e\",\n> \t\"list\": [\n> \t\t1\n> \t],\n> \t\"path\": \"C:\\\\u003c\"\n> }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeJSON(value, tt.encoding)
+			if err != nil {
+				t.Fatalf("EncodeJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeJSON() = %s, want %s", got, tt.want)
+			}
+
+			// The streaming encoder lays out JSON the same way
+			var buf bytes.Buffer
+			if err := tt.encoding.newEncoder(&buf).Encode(value); err != nil || buf.String() != tt.want+"\n" {
+				t.Errorf("newEncoder() wrote %s, %v, want %s", buf.String(), err, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeJSON(map[string]interface{}{"f": func() {}}, JSONEncoding{}); err == nil {
+		t.Errorf("EncodeJSON() of a func error = nil, want an error")
+	}
+}
+
+func TestJSONEncodingOptions(t *testing.T) {
+	s := &BaseSerializer{JSONEncoding: JSONEncoding{Indent: "  "}}
+	got, err := s.SerializeAs(account{ID: 7, Name: "<ana>"}, FormatJSON, WithFields("id", "name"), WithJSONEncoding(JSONEncoding{NoEscapeHTML: true}))
+	if err != nil {
+		t.Fatalf("SerializeAs() error = %v", err)
+	}
+	if want := `{"id":7,"name":"<ana>"}`; string(got) != want {
+		t.Errorf("SerializeAs() = %s, want %s", got, want)
+	}
+	encoded, err := s.EncodeJSON([]interface{}{1})
+	if err != nil || string(encoded) != "[\n  1\n]" {
+		t.Errorf("EncodeJSON() = %s, %v", encoded, err)
+	}
+}
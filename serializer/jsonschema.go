@@ -0,0 +1,259 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONSchema generates a JSON Schema (draft 2020-12) describing the serialized form of the struct
+// type of data. Field selection, read-only/write-only roles, key naming, deprecations and
+// FieldMeta (as "title", "description", "x-group" and "x-order") are taken into account. Fields
+// that can be null, such as pointers, are marked with "x-nullable", and fields left out when empty
+// (",omitempty") with "x-omitempty".
+func (s *BaseSerializer) JSONSchema(data interface{}) (map[string]interface{}, error) {
+	t, err := structType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]interface{})
+	var keys []string
+	for _, field := range s.schemaFields(t) {
+		key := s.convertKey(field.name)
+		property := typeSchema(field.typ, map[reflect.Type]bool{t: true})
+		if containsField(s.ReadOnlyFields, field.name) {
+			property["readOnly"] = true
+		}
+		if containsField(s.WriteOnlyFields, field.name) {
+			property["writeOnly"] = true
+		}
+		if enum, ok := s.Enums[field.name]; ok {
+			property = map[string]interface{}{"enum": enum.Allowed()}
+		}
+		if _, deprecated := s.DeprecatedFields[field.name]; deprecated {
+			property["deprecated"] = true
+		}
+		annotateField(property, field)
+		if meta, ok := s.fieldMeta(field.name); ok {
+			if meta.Title != "" {
+				property["title"] = meta.Title
+			}
+			if meta.Description != "" {
+				property["description"] = meta.Description
+			}
+			if meta.Group != "" {
+				property["x-group"] = meta.Group
+			}
+		}
+		properties[key] = property
+		keys = append(keys, key)
+	}
+	for i, key := range s.OrderedKeys(keys) {
+		properties[key].(map[string]interface{})["x-order"] = i
+	}
+
+	var required []string
+	for field := range s.Validations {
+		required = append(required, s.convertKey(field))
+	}
+	for field := range s.ContextValidations {
+		if _, ok := s.Validations[field]; !ok {
+			required = append(required, s.convertKey(field))
+		}
+	}
+	sort.Strings(required)
+
+	schema := map[string]interface{}{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"title":      t.Name(),
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// Document generates Markdown documentation of the serialized fields of the struct type of data,
+// grouped and ordered according to FieldMeta and FieldGroups.
+func (s *BaseSerializer) Document(data interface{}) (string, error) {
+	schema, err := s.JSONSchema(data)
+	if err != nil {
+		return "", err
+	}
+	properties := schema["properties"].(map[string]interface{})
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return properties[keys[i]].(map[string]interface{})["x-order"].(int) < properties[keys[j]].(map[string]interface{})["x-order"].(int)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", schema["title"])
+	currentGroup := "\x00"
+	for _, key := range keys {
+		property := properties[key].(map[string]interface{})
+		group, _ := property["x-group"].(string)
+		if group != currentGroup {
+			if group == "" {
+				b.WriteString("\n## Other\n\n")
+			} else {
+				fmt.Fprintf(&b, "\n## %s\n\n", group)
+			}
+			b.WriteString("| Field | Type | Description |\n|---|---|---|\n")
+			currentGroup = group
+		}
+
+		var notes []string
+		if description, _ := property["description"].(string); description != "" {
+			notes = append(notes, description)
+		}
+		for _, flag := range []string{"readOnly", "writeOnly", "deprecated"} {
+			if property[flag] == true {
+				notes = append(notes, "_"+flag+"_")
+			}
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", key, schemaTypeName(property), strings.Join(notes, " "))
+	}
+	return b.String(), nil
+}
+
+// schemaFields returns the fields of t that appear in the serialized output.
+func (s *BaseSerializer) schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for _, field := range schemaFor(t).fields {
+		if len(s.Fields) > 0 && !containsField(s.Fields, field.name) {
+			continue
+		}
+		if containsField(s.ExcludedFields, field.name) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// structType returns the struct type of data, dereferencing pointers.
+func structType(data interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(data)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, &SerializationError{Message: fmt.Sprintf("%T is not a struct", data)}
+	}
+	return t, nil
+}
+
+// typeSchema returns the JSON Schema of a Go type as encoding/json serializes it.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == numberType:
+		return map[string]interface{}{"type": "number"}
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType),
+		t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return map[string]interface{}{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": elementSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": elementSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		properties := make(map[string]interface{})
+		for _, field := range schemaFor(t).fields {
+			property := typeSchema(field.typ, seen)
+			annotateField(property, field)
+			properties[field.name] = property
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// annotateField marks the schema of a struct field with "x-nullable" when its value can be null
+// and with "x-omitempty" when it is left out when empty.
+func annotateField(property map[string]interface{}, field schemaField) {
+	if field.typ.Kind() == reflect.Ptr {
+		property["x-nullable"] = true
+	}
+	if field.omitEmpty {
+		property["x-omitempty"] = true
+	}
+}
+
+// elementSchema returns the schema of the items of a list or map of type t, marked with
+// "x-nullable" when they are pointers.
+func elementSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	schema := typeSchema(t, seen)
+	if t.Kind() == reflect.Ptr {
+		schema["x-nullable"] = true
+	}
+	return schema
+}
+
+// schemaTypeName describes a property schema in a few words for documentation.
+func schemaTypeName(property map[string]interface{}) string {
+	kind, _ := property["type"].(string)
+	switch kind {
+	case "":
+		return "any"
+	case "array":
+		if items, ok := property["items"].(map[string]interface{}); ok {
+			return "array of " + schemaTypeName(items)
+		}
+	case "string":
+		if format, ok := property["format"].(string); ok {
+			return "string (" + format + ")"
+		}
+	}
+	return kind
+}
+
+// MarshalJSONSchema is a convenience wrapper returning the indented JSON encoding of JSONSchema.
+func (s *BaseSerializer) MarshalJSONSchema(data interface{}) ([]byte, error) {
+	schema, err := s.JSONSchema(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode JSON schema: %v", err), Err: err}
+	}
+	return encoded, nil
+}
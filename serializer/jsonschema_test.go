@@ -0,0 +1,60 @@
+package serializer
+
+import "testing"
+
+func TestJSONSchemaNullability(t *testing.T) {
+	type address struct {
+		City *string `json:"city"`
+		Zip  string  `json:"zip,omitempty"`
+	}
+	type person struct {
+		Name     string            `json:"name"`
+		Nickname *string           `json:"nickname"`
+		Email    string            `json:"email,omitempty"`
+		Address  address           `json:"address"`
+		Friends  []*person         `json:"friends"`
+		Scores   map[string]*int64 `json:"scores"`
+	}
+	schema, err := (&BaseSerializer{}).JSONSchema(person{})
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	property := func(path ...string) map[string]interface{} {
+		p := properties[path[0]].(map[string]interface{})
+		for _, key := range path[1:] {
+			switch key {
+			case "items", "additionalProperties":
+				p = p[key].(map[string]interface{})
+			default:
+				p = p["properties"].(map[string]interface{})[key].(map[string]interface{})
+			}
+		}
+		return p
+	}
+
+	tests := []struct {
+		path      []string
+		nullable  bool
+		omitEmpty bool
+	}{
+		{[]string{"name"}, false, false},
+		{[]string{"nickname"}, true, false},
+		{[]string{"email"}, false, true},
+		{[]string{"address"}, false, false},
+		{[]string{"address", "city"}, true, false},
+		{[]string{"address", "zip"}, false, true},
+		{[]string{"friends"}, false, false},
+		{[]string{"friends", "items"}, true, false},
+		{[]string{"scores", "additionalProperties"}, true, false},
+	}
+	for _, tt := range tests {
+		p := property(tt.path...)
+		if got := p["x-nullable"] == true; got != tt.nullable {
+			t.Errorf("%v x-nullable = %v, want %v", tt.path, got, tt.nullable)
+		}
+		if got := p["x-omitempty"] == true; got != tt.omitEmpty {
+			t.Errorf("%v x-omitempty = %v, want %v", tt.path, got, tt.omitEmpty)
+		}
+	}
+}
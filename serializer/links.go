@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// LinksKey is the key under which hypermedia links are added to the serialized output.
+const LinksKey = "_links"
+
+// renderLinks expands the Links templates with the field values of result into HAL link objects:
+// {"self": {"href": "/users/1"}}. Placeholders name fields, or dotted paths into nested objects, and
+// their values are escaped for use in a URL path. Links with a missing or null value are left out.
+func (s *BaseSerializer) renderLinks(result map[string]interface{}) map[string]interface{} {
+	links := make(map[string]interface{}, len(s.Links))
+	for rel, template := range s.Links {
+		if href, ok := expandLink(template, result); ok {
+			links[rel] = map[string]interface{}{"href": href}
+		}
+	}
+	return links
+}
+
+// expandLink replaces the {field} placeholders of template with the values in result.
+func expandLink(template string, result map[string]interface{}) (string, bool) {
+	var href strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			href.WriteString(template)
+			return href.String(), true
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			href.WriteString(template)
+			return href.String(), true
+		}
+		value, ok := linkValue(result, template[start+1:start+end])
+		if !ok {
+			return "", false
+		}
+		href.WriteString(template[:start])
+		href.WriteString(url.PathEscape(value))
+		template = template[start+end+1:]
+	}
+}
+
+// linkValue returns the value at a dotted path of result, formatted for a URL.
+func linkValue(result map[string]interface{}, path string) (string, bool) {
+	var value interface{} = result
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = object[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
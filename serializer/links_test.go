@@ -0,0 +1,62 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExpandLink(t *testing.T) {
+	result := map[string]interface{}{
+		"id":      7.0,
+		"big":     json.Number("12345678901234567890"),
+		"name":    "a b/c",
+		"active":  true,
+		"address": map[string]interface{}{"city": "Lima"},
+		"none":    nil,
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantOk   bool
+	}{
+		{"no placeholders", "/users", "/users", true},
+		{"number", "/users/{id}", "/users/7", true},
+		{"json.Number", "/big/{big}", "/big/12345678901234567890", true},
+		{"escaped string", "/users/{name}/posts", "/users/a%20b%2Fc/posts", true},
+		{"boolean", "/filter/{active}", "/filter/true", true},
+		{"nested path", "/cities/{address.city}", "/cities/Lima", true},
+		{"several placeholders", "/{id}/{address.city}", "/7/Lima", true},
+		{"unclosed brace", "/users/{id", "/users/{id", true},
+		{"missing field", "/users/{missing}", "", false},
+		{"null value", "/users/{none}", "", false},
+		{"path through a scalar", "/users/{id.x}", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := expandLink(tt.template, result)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("expandLink(%q) = %q, %v, want %q, %v", tt.template, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestSerializeLinks(t *testing.T) {
+	s := &BaseSerializer{
+		Fields: []string{"name"},
+		Links:  map[string]string{"self": "/accounts/{id}", "team": "/teams/{team}"},
+	}
+	got, err := s.Serialize(account{ID: 7, Name: "ana"})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"name":   "ana",
+		LinksKey: map[string]interface{}{"self": map[string]interface{}{"href": "/accounts/7"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize() = %#v, want %#v", got, want)
+	}
+}
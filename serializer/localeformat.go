@@ -0,0 +1,330 @@
+package serializer
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocaleFormat describes how numbers, amounts of money and dates are written in a locale.
+type LocaleFormat struct {
+	Decimal         string   // Decimal separator
+	Group           string   // Separator of groups of thousands
+	CurrencyPattern string   // Layout of amounts, where ¤ stands for the currency symbol and # for the number, e.g. "¤#" or "# ¤"
+	DateLayout      string   // time layout of short dates, e.g. "02/01/2006"
+	LongDateLayout  string   // time layout of dates written out, where January and Monday stand for the names in Months and Days
+	DateTimeLayout  string   // time layout of dates with the time of day
+	Months          []string // Names of the months from January, replacing the English names; English when empty
+	Days            []string // Names of the days from Sunday, replacing the English names; English when empty
+}
+
+var (
+	monthsES = []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"}
+	daysES   = []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"}
+	monthsPT = []string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"}
+	daysPT   = []string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"}
+	monthsFR = []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"}
+	daysFR   = []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"}
+	monthsDE = []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}
+	daysDE   = []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}
+	monthsIT = []string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"}
+	daysIT   = []string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"}
+)
+
+// LocaleFormats contains the formats of the supported locales, keyed like the locales of
+// WithLocale. Lookups fall back from a regional locale ("es-CL") to its language ("es"), and
+// finally to "en". Add or replace entries to support other locales.
+var LocaleFormats = map[string]LocaleFormat{
+	"en":    {Decimal: ".", Group: ",", CurrencyPattern: "¤#", DateLayout: "01/02/2006", LongDateLayout: "January 2, 2006", DateTimeLayout: "01/02/2006 3:04 PM"},
+	"en-GB": {Decimal: ".", Group: ",", CurrencyPattern: "¤#", DateLayout: "02/01/2006", LongDateLayout: "2 January 2006", DateTimeLayout: "02/01/2006 15:04"},
+	"es":    {Decimal: ",", Group: ".", CurrencyPattern: "# ¤", DateLayout: "02/01/2006", LongDateLayout: "2 de January de 2006", DateTimeLayout: "02/01/2006 15:04", Months: monthsES, Days: daysES},
+	"es-CL": {Decimal: ",", Group: ".", CurrencyPattern: "¤#", DateLayout: "02-01-2006", LongDateLayout: "2 de January de 2006", DateTimeLayout: "02-01-2006 15:04", Months: monthsES, Days: daysES},
+	"es-MX": {Decimal: ".", Group: ",", CurrencyPattern: "¤#", DateLayout: "02/01/2006", LongDateLayout: "2 de January de 2006", DateTimeLayout: "02/01/2006 15:04", Months: monthsES, Days: daysES},
+	"pt":    {Decimal: ",", Group: ".", CurrencyPattern: "# ¤", DateLayout: "02/01/2006", LongDateLayout: "2 de January de 2006", DateTimeLayout: "02/01/2006 15:04", Months: monthsPT, Days: daysPT},
+	"pt-BR": {Decimal: ",", Group: ".", CurrencyPattern: "¤ #", DateLayout: "02/01/2006", LongDateLayout: "2 de January de 2006", DateTimeLayout: "02/01/2006 15:04", Months: monthsPT, Days: daysPT},
+	"fr":    {Decimal: ",", Group: "\u202f", CurrencyPattern: "# ¤", DateLayout: "02/01/2006", LongDateLayout: "2 January 2006", DateTimeLayout: "02/01/2006 15:04", Months: monthsFR, Days: daysFR},
+	"de":    {Decimal: ",", Group: ".", CurrencyPattern: "# ¤", DateLayout: "02.01.2006", LongDateLayout: "2. January 2006", DateTimeLayout: "02.01.2006 15:04", Months: monthsDE, Days: daysDE},
+	"it":    {Decimal: ",", Group: ".", CurrencyPattern: "# ¤", DateLayout: "02/01/2006", LongDateLayout: "2 January 2006", DateTimeLayout: "02/01/2006 15:04", Months: monthsIT, Days: daysIT},
+}
+
+// currencySymbols are the symbols of common currencies; other currencies are written with
+// their code.
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "¥", "INR": "₹", "KRW": "₩",
+	"BRL": "R$", "CLP": "$", "MXN": "$", "ARS": "$", "COP": "$", "UYU": "$", "PEN": "S/",
+	"CAD": "$", "AUD": "$",
+}
+
+// currencyDecimals are the minor units of the currencies that don't use two decimals
+// (ISO 4217).
+var currencyDecimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// LocaleFormatFor returns the format of locale, falling back from a regional locale to its
+// language and then to "en".
+func LocaleFormatFor(locale string) LocaleFormat {
+	for locale != "" {
+		if format, ok := LocaleFormats[locale]; ok {
+			return format
+		}
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return LocaleFormats["en"]
+}
+
+// FormatNumber writes n rounded to decimals digits, with the separators of the locale.
+func (f LocaleFormat) FormatNumber(n float64, decimals int) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+	return f.formatDigits(strconv.FormatFloat(n, 'f', decimals, 64))
+}
+
+// FormatCurrency writes an amount of money in currency, an ISO 4217 code, with the digits of the
+// currency's minor unit and its symbol placed as in the locale. Halves are rounded away from
+// zero, as CurrencyField rounds them.
+func (f LocaleFormat) FormatCurrency(amount float64, currency string) string {
+	decimals := CurrencyDecimals(currency)
+	digits := strconv.FormatFloat(amount, 'f', decimals, 64)
+	if exact := new(big.Rat).SetFloat64(amount); exact != nil {
+		digits = exact.FloatString(decimals)
+	}
+	return f.formatCurrencyDigits(digits, currency)
+}
+
+// FormatDate writes the date of t in the short layout of the locale.
+func (f LocaleFormat) FormatDate(t time.Time) string {
+	return f.formatTime(t, f.DateLayout)
+}
+
+// FormatLongDate writes the date of t with the names of its month and day in the locale.
+func (f LocaleFormat) FormatLongDate(t time.Time) string {
+	return f.formatTime(t, f.LongDateLayout)
+}
+
+// FormatDateTime writes the date and time of day of t in the layout of the locale.
+func (f LocaleFormat) FormatDateTime(t time.Time) string {
+	return f.formatTime(t, f.DateTimeLayout)
+}
+
+// CurrencyDecimals returns the number of decimals of the minor unit of currency, an ISO 4217
+// code, e.g. 2 for USD and 0 for JPY and CLP.
+func CurrencyDecimals(currency string) int {
+	if decimals, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// formatCurrencyDigits writes an amount given as plain decimal digits in currency.
+func (f LocaleFormat) formatCurrencyDigits(digits, currency string) string {
+	currency = strings.ToUpper(currency)
+	pattern := f.CurrencyPattern
+	if pattern == "" {
+		pattern = "¤#"
+	}
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+		pattern = strings.Replace(pattern, "¤#", "¤ #", 1)
+	}
+	number := f.formatDigits(strings.TrimPrefix(digits, "-"))
+	formatted := strings.Replace(strings.Replace(pattern, "#", number, 1), "¤", symbol, 1)
+	if strings.HasPrefix(digits, "-") {
+		return "-" + formatted
+	}
+	return formatted
+}
+
+// formatDigits inserts the separators of the locale into a number written as plain decimal
+// digits.
+func (f LocaleFormat) formatDigits(digits string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.Group)
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString(f.Decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// formatTime formats t with layout, replacing the English names of months and days.
+func (f LocaleFormat) formatTime(t time.Time, layout string) string {
+	formatted := t.Format(layout)
+	if len(f.Months) == 12 && strings.Contains(layout, "January") {
+		formatted = strings.Replace(formatted, t.Month().String(), f.Months[t.Month()-1], 1)
+	}
+	if len(f.Days) == 7 && strings.Contains(layout, "Monday") {
+		formatted = strings.Replace(formatted, t.Weekday().String(), f.Days[t.Weekday()], 1)
+	}
+	return formatted
+}
+
+// Localizer is implemented by fields whose representation depends on the locale of the request
+// (see WithLocale). Serialize calls Localize after Represent when the context, or the
+// serializer's Locale, sets a locale.
+type Localizer interface {
+	Localize(value interface{}, format LocaleFormat) (interface{}, error)
+}
+
+// CurrencyField is an amount of money in Currency, an ISO 4217 code. Amounts are numbers, or
+// decimal strings, and are written unchanged unless a locale is set; then they're formatted for
+// the locale, e.g. "$1,234.50" in "en" or "1.234,50 €" for EUR in "es".
+type CurrencyField struct {
+	Currency string
+	Required bool
+}
+
+func (f CurrencyField) Validate(value interface{}) error {
+	if _, ok := decimalDigits(value); !ok {
+		return NewCodedError(CodeInvalidType, "value is not an amount")
+	}
+	return nil
+}
+
+func (f CurrencyField) Localize(value interface{}, format LocaleFormat) (interface{}, error) {
+	digits, ok := decimalDigits(value)
+	if !ok {
+		return nil, NewCodedError(CodeInvalidType, "value is not an amount")
+	}
+	// Round the decimal digits exactly rather than through float64
+	amount, _ := new(big.Rat).SetString(digits)
+	return format.formatCurrencyDigits(amount.FloatString(CurrencyDecimals(f.Currency)), f.Currency), nil
+}
+
+func (f CurrencyField) IsRequired() bool { return f.Required }
+
+// LocalizeNumber returns a context transformation that writes numbers with decimals digits and
+// the separators of the request's locale (see WithLocale), e.g. "1,234.5" in "en" and "1.234,5"
+// in "es". Other values are left unchanged.
+func LocalizeNumber(decimals int) func(context.Context, interface{}) interface{} {
+	return func(ctx context.Context, value interface{}) interface{} {
+		n, ok := numberValue(value)
+		if !ok || math.IsInf(n, 0) || math.IsNaN(n) {
+			return value
+		}
+		return LocaleFormatFor(LocaleFromContext(ctx)).FormatNumber(n, decimals)
+	}
+}
+
+// LocalizeCurrency returns a context transformation that writes amounts of money in currency
+// as in the request's locale (see LocaleFormat.FormatCurrency). Other values are left unchanged.
+func LocalizeCurrency(currency string) func(context.Context, interface{}) interface{} {
+	return func(ctx context.Context, value interface{}) interface{} {
+		localized, err := CurrencyField{Currency: currency}.Localize(value, LocaleFormatFor(LocaleFromContext(ctx)))
+		if err != nil {
+			return value
+		}
+		return localized
+	}
+}
+
+// LocalizeDate returns a context transformation that writes times, serialized as RFC 3339
+// strings, as short dates of the request's locale. Other values are left unchanged.
+func LocalizeDate() func(context.Context, interface{}) interface{} {
+	return localizeTime(LocaleFormat.FormatDate)
+}
+
+// LocalizeLongDate is like LocalizeDate, writing dates with the names of months and days.
+func LocalizeLongDate() func(context.Context, interface{}) interface{} {
+	return localizeTime(LocaleFormat.FormatLongDate)
+}
+
+// LocalizeDateTime is like LocalizeDate, writing the time of day too.
+func LocalizeDateTime() func(context.Context, interface{}) interface{} {
+	return localizeTime(LocaleFormat.FormatDateTime)
+}
+
+// localizeTime returns a context transformation writing times with format.
+func localizeTime(format func(LocaleFormat, time.Time) string) func(context.Context, interface{}) interface{} {
+	return func(ctx context.Context, value interface{}) interface{} {
+		var t time.Time
+		switch v := value.(type) {
+		case time.Time:
+			t = v
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return value
+			}
+			t = parsed
+		default:
+			return value
+		}
+		return format(LocaleFormatFor(LocaleFromContext(ctx)), t)
+	}
+}
+
+// decimalDigits returns a number, or a string holding a decimal number, as plain decimal digits.
+func decimalDigits(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		if _, err := strconv.ParseFloat(v, 64); err != nil || strings.ContainsAny(v, "eEnN") {
+			return "", false
+		}
+		return strings.TrimPrefix(v, "+"), true
+	case json.Number:
+		return decimalDigits(string(v))
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	}
+	n, ok := numberValue(value)
+	if !ok || math.IsInf(n, 0) || math.IsNaN(n) {
+		return "", false
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64), true
+}
+
+// localize applies the Localizer fields of the schema to result for the locale of ctx, which
+// carries the serializer's Locale when the caller sets none.
+func (s *BaseSerializer) localize(ctx context.Context, result map[string]interface{}) error {
+	locale := LocaleFromContext(ctx)
+	if locale == "" || len(s.Schema) == 0 {
+		return nil
+	}
+	format := LocaleFormatFor(locale)
+	for name, field := range s.Schema {
+		value, exists := result[name]
+		if !exists || value == nil {
+			continue
+		}
+		localized, err := localizeValue(field, value, format)
+		if err != nil {
+			return &TransformationError{Field: name, Value: value, Message: err.Error(), Code: validationCode(err), Err: err}
+		}
+		result[name] = localized
+	}
+	return nil
+}
+
+// localizeValue localizes value with field when it is a Localizer.
+func localizeValue(field Field, value interface{}, format LocaleFormat) (interface{}, error) {
+	if l, ok := field.(Localizer); ok {
+		return l.Localize(value, format)
+	}
+	return value, nil
+}
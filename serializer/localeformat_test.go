@@ -0,0 +1,118 @@
+package serializer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLocaleFormatFor(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string // Decimal separator and date layout of the expected format
+	}{
+		{"es-CL", ",02-01-2006"},
+		{"es_AR", ",02/01/2006"},
+		{"es-419-x", ",02/01/2006"},
+		{"en-GB", ".02/01/2006"},
+		{"ja", ".01/02/2006"},
+		{"", ".01/02/2006"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			format := LocaleFormatFor(tt.locale)
+			if got := format.Decimal + format.DateLayout; got != tt.want {
+				t.Errorf("LocaleFormatFor(%q) = %q, want %q", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocaleFormatNumbers(t *testing.T) {
+	tests := []struct {
+		locale   string
+		number   string
+		currency string // Currency of amount
+		amount   string
+		foreign  string // Amount in CHF, which is written with its code
+	}{
+		{"en", "-1,234,567.89", "USD", "$1,234.50", "CHF 1,234.50"},
+		{"es", "-1.234.567,89", "EUR", "1.234,50 €", "1.234,50 CHF"},
+		{"pt-BR", "-1.234.567,89", "BRL", "R$ 1.234,50", "CHF 1.234,50"},
+		{"fr", "-1\u202f234\u202f567,89", "EUR", "1\u202f234,50 €", "1\u202f234,50 CHF"},
+		{"de", "-1.234.567,89", "JPY", "1.235 ¥", "1.234,50 CHF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			format := LocaleFormatFor(tt.locale)
+			if got := format.FormatNumber(-1234567.891, 2); got != tt.number {
+				t.Errorf("FormatNumber() = %q, want %q", got, tt.number)
+			}
+			if got := format.FormatCurrency(1234.5, tt.currency); got != tt.amount {
+				t.Errorf("FormatCurrency() = %q, want %q", got, tt.amount)
+			}
+			if got := format.FormatCurrency(1234.5, "CHF"); got != tt.foreign {
+				t.Errorf("FormatCurrency(CHF) = %q, want %q", got, tt.foreign)
+			}
+		})
+	}
+}
+
+func TestLocaleFormatDates(t *testing.T) {
+	at := time.Date(2024, 3, 6, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		locale   string
+		date     string
+		longDate string
+		dateTime string
+	}{
+		{"en", "03/06/2024", "March 6, 2024", "03/06/2024 3:04 PM"},
+		{"es", "06/03/2024", "6 de marzo de 2024", "06/03/2024 15:04"},
+		{"de", "06.03.2024", "6. März 2024", "06.03.2024 15:04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			format := LocaleFormatFor(tt.locale)
+			if got := format.FormatDate(at); got != tt.date {
+				t.Errorf("FormatDate() = %q, want %q", got, tt.date)
+			}
+			if got := format.FormatLongDate(at); got != tt.longDate {
+				t.Errorf("FormatLongDate() = %q, want %q", got, tt.longDate)
+			}
+			if got := format.FormatDateTime(at); got != tt.dateTime {
+				t.Errorf("FormatDateTime() = %q, want %q", got, tt.dateTime)
+			}
+		})
+	}
+
+	days := LocaleFormat{LongDateLayout: "Monday 2 January", Months: monthsES, Days: daysES}
+	if got, want := days.FormatLongDate(at), "miércoles 6 marzo"; got != want {
+		t.Errorf("FormatLongDate() = %q, want %q", got, want)
+	}
+}
+
+func TestLocalizeTransformations(t *testing.T) {
+	ctx := WithLocale(context.Background(), "es")
+	tests := []struct {
+		name      string
+		transform func(context.Context, interface{}) interface{}
+		value     interface{}
+		want      interface{}
+	}{
+		{"number", LocalizeNumber(1), 1234.56, "1.234,6"},
+		{"number left alone", LocalizeNumber(1), "n/a", "n/a"},
+		{"currency rounded exactly", LocalizeCurrency("EUR"), "1234.505", "1.234,51 €"},
+		{"currency without decimals", LocalizeCurrency("CLP"), 1234.5, "1.235 $"},
+		{"date", LocalizeDate(), "2024-03-06T15:04:00Z", "06/03/2024"},
+		{"long date", LocalizeLongDate(), "2024-03-06T15:04:00Z", "6 de marzo de 2024"},
+		{"date time", LocalizeDateTime(), "2024-03-06T15:04:00Z", "06/03/2024 15:04"},
+		{"not a time", LocalizeDate(), "soon", "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transform(ctx, tt.value); got != tt.want {
+				t.Errorf("transform(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+package serializer
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	mapDecoderType      = reflect.TypeOf((*MapDecoder)(nil)).Elem()
+)
+
+// reflectDecoder decodes maps into structs by reflection, see decode.
+type reflectDecoder struct {
+	hooks []DecodeHook // Conversions applied to every value before it is decoded
+}
+
+// decode stores a decoded value into out, a non-nil pointer, following the rules of
+// encoding/json: keys match fields by their JSON names or case-insensitively, the ",string" option
+// is honored, nulls reset pointers, maps, slices and interfaces and leave other values unchanged,
+// and unknown keys are ignored. The value isn't encoded as JSON on the way, so values already of
+// the type of their field, such as time.Time, are stored as they are. Types with their own
+// UnmarshalJSON or UnmarshalText receive their JSON encoding.
+func (d reflectDecoder) decode(value interface{}, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return &SerializationError{Message: fmt.Sprintf("failed to deserialize into %T: not a non-nil pointer", out)}
+	}
+	return d.decodeAt(value, v.Elem(), "")
+}
+
+// decodeAt stores value into the settable v like decode, naming the value by path,
+// its location within the input, in errors.
+func (d reflectDecoder) decodeAt(value interface{}, v reflect.Value, path string) error {
+	err := d.decodeValue(value, v)
+	if failure, ok := err.(*decodeFailure); ok {
+		return failure.serializationError(path)
+	}
+	return err
+}
+
+// decodeValue stores value into the settable v. Its failures are *decodeFailure values
+// whose path is filled in on the way up, so that paths are only built for errors.
+func (d reflectDecoder) decodeValue(value interface{}, v reflect.Value) error {
+	t := v.Type()
+	if value != nil && len(d.hooks) > 0 {
+		converted, err := runDecodeHooks(d.hooks, value, t)
+		if err != nil {
+			return &decodeFailure{message: err.Error(), err: err}
+		}
+		value = converted
+	}
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		if !customDecoding(t) {
+			return nil
+		}
+	}
+
+	// Values of the field's own type, e.g. a time.Time, are kept rather than re-encoded.
+	// Maps and slices are copied, so that the result doesn't share them with the input, and
+	// those of interface{} values are decoded like other lists and objects.
+	if value != nil && t.Kind() != reflect.Interface && !holdsInterfaces(t) {
+		if rv := reflect.ValueOf(value); rv.Type() == t {
+			v.Set(shallowCopy(rv))
+			return nil
+		}
+	}
+
+	if t == timeType {
+		if s, ok := value.(string); ok {
+			parsed, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return &decodeFailure{message: err.Error(), err: err}
+			}
+			v.Set(reflect.ValueOf(parsed))
+			return nil
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok && reflect.PtrTo(t).Implements(mapDecoderType) {
+		return v.Addr().Interface().(MapDecoder).DecodeMap(object)
+	}
+	if customDecoding(t) {
+		return decodeJSONFallback(value, v)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return d.decodeValue(value, v.Elem())
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return decodeJSONFallback(value, v)
+		}
+		v.Set(reflect.ValueOf(plainValue(value)))
+		return nil
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return decodeMismatch(value, t)
+		}
+		return d.decodeStruct(object, v)
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return decodeMismatch(value, t)
+		}
+		return d.decodeMapValue(object, v)
+	case reflect.Slice:
+		if s, ok := value.(string); ok && t.Elem().Kind() == reflect.Uint8 {
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return &decodeFailure{message: err.Error(), err: err}
+			}
+			v.SetBytes(decoded)
+			return nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return decodeMismatch(value, t)
+		}
+		slice := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := d.decodeValue(item, slice.Index(i)); err != nil {
+				return failureAt(err, indexToken(i))
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return decodeMismatch(value, t)
+		}
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(items) {
+				v.Index(i).Set(reflect.Zero(t.Elem()))
+				continue
+			}
+			if err := d.decodeValue(items[i], v.Index(i)); err != nil {
+				return failureAt(err, indexToken(i))
+			}
+		}
+		return nil
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			if t == numberType {
+				if literal, ok := numberLiteral(value); ok {
+					v.SetString(literal)
+					return nil
+				}
+			}
+			return decodeMismatch(value, t)
+		}
+		if t == numberType {
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				return decodeMismatch(value, t)
+			}
+		}
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return decodeMismatch(value, t)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		literal, ok := numberLiteral(value)
+		n, err := strconv.ParseInt(literal, 10, 64)
+		if !ok || err != nil || v.OverflowInt(n) {
+			return decodeMismatch(value, t)
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		literal, ok := numberLiteral(value)
+		n, err := strconv.ParseUint(literal, 10, 64)
+		if !ok || err != nil || v.OverflowUint(n) {
+			return decodeMismatch(value, t)
+		}
+		v.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		literal, ok := numberLiteral(value)
+		f, err := strconv.ParseFloat(literal, t.Bits())
+		if !ok || err != nil || v.OverflowFloat(f) {
+			return decodeMismatch(value, t)
+		}
+		v.SetFloat(f)
+		return nil
+	default:
+		return decodeJSONFallback(value, v)
+	}
+}
+
+// decodeStruct stores the values of object into the fields of the struct v.
+func (d reflectDecoder) decodeStruct(object map[string]interface{}, v reflect.Value) error {
+	schema := schemaFor(v.Type())
+	for key, item := range object {
+		field := schema.lookup(key)
+		if field == nil {
+			continue // Unknown keys are ignored, as encoding/json does
+		}
+		if _, exact := object[field.name]; exact && field.name != key {
+			continue // The exact key wins, whatever the map order
+		}
+		target, err := settableField(v, field.index)
+		if err == nil {
+			if field.quoted {
+				err = decodeQuoted(item, target)
+			} else {
+				err = d.decodeValue(item, target)
+			}
+		}
+		if err != nil {
+			return failureAt(err, field.name)
+		}
+	}
+	return nil
+}
+
+// decodeMapValue stores the entries of object into the map v, adding to the entries it already
+// has like encoding/json does.
+func (d reflectDecoder) decodeMapValue(object map[string]interface{}, v reflect.Value) error {
+	t := v.Type()
+	switch t.Key().Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !reflect.PtrTo(t.Key()).Implements(textUnmarshalerType) {
+			return decodeMismatch(object, t)
+		}
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(object)))
+	}
+	for key, item := range object {
+		element := reflect.New(t.Elem()).Elem()
+		if err := d.decodeValue(item, element); err != nil {
+			return failureAt(err, key)
+		}
+		mapKey, err := decodeMapKey(key, t.Key())
+		if err != nil {
+			return failureAt(decodeMismatch(key, t.Key()), key)
+		}
+		v.SetMapIndex(mapKey, element)
+	}
+	return nil
+}
+
+// decodeMapKey converts an object key into a map key of type t.
+func decodeMapKey(key string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		k := reflect.New(t)
+		if err := k.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, err
+		}
+		return k.Elem(), nil
+	}
+	k := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		k.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || k.OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("invalid key %q", key)
+		}
+		k.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || k.OverflowUint(n) {
+			return reflect.Value{}, fmt.Errorf("invalid key %q", key)
+		}
+		k.SetUint(n)
+	}
+	return k, nil
+}
+
+// decodeQuoted stores the value of a field with the ",string" option, a string holding the JSON
+// literal of the value.
+func decodeQuoted(value interface{}, v reflect.Value) error {
+	if value == nil {
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return &decodeFailure{message: fmt.Sprintf("invalid use of ,string struct tag, trying to unmarshal unquoted value into %s", v.Type())}
+	}
+	if err := json.Unmarshal([]byte(s), v.Addr().Interface()); err != nil {
+		return &decodeFailure{message: fmt.Sprintf("invalid use of ,string struct tag, trying to unmarshal %q into %s", s, v.Type()), err: err}
+	}
+	return nil
+}
+
+// decodeJSONFallback stores value into v through its JSON encoding, for types with their own
+// UnmarshalJSON or UnmarshalText and for the kinds the reflection-based decoder leaves to
+// encoding/json.
+func decodeJSONFallback(value interface{}, v reflect.Value) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return &decodeFailure{message: fmt.Sprintf("failed to convert to JSON: %v", err), err: err}
+	}
+	if err := json.Unmarshal(encoded, v.Addr().Interface()); err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			err = &CodedError{Code: CodeInvalidType, Message: err.Error(), Err: typeErr}
+		}
+		return &decodeFailure{message: err.Error(), err: err}
+	}
+	return nil
+}
+
+// settableField returns the field of the struct v at index, allocating the embedded structs it
+// is promoted through. Like encoding/json, it fails for nil pointers to unexported embedded
+// structs, which can't be set.
+func settableField(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, &decodeFailure{message: fmt.Sprintf("cannot set embedded pointer to unexported struct: %v", v.Type().Elem())}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// lookup returns the field an input key is decoded into: the field named key, or else the first
+// whose name equals key under case folding, as encoding/json matches keys. It returns nil for
+// unknown keys.
+func (s *structSchema) lookup(key string) *schemaField {
+	if field := s.byName[key]; field != nil {
+		return field
+	}
+	for i := range s.fields {
+		if strings.EqualFold(s.fields[i].name, key) {
+			return &s.fields[i]
+		}
+	}
+	return nil
+}
+
+// inputFields returns the schema that input keys for target type t are matched against, or nil
+// when t isn't a struct or a pointer to one.
+func inputFields(t reflect.Type) *structSchema {
+	if t == nil {
+		return nil
+	}
+	if t = baseType(t); t.Kind() != reflect.Struct {
+		return nil
+	}
+	return schemaFor(t)
+}
+
+// fieldKey returns the name of the field of schema that key is decoded into, or key when it matches
+// none or schema is nil. A key that only matches under case folding is ignored when input also has
+// the exact name, so that the result doesn't depend on map order.
+func fieldKey(schema *structSchema, input map[string]interface{}, key string) (name string, ignored bool) {
+	if schema == nil {
+		return key, false
+	}
+	field := schema.lookup(key)
+	if field == nil || field.name == key {
+		return key, false
+	}
+	if _, exact := input[field.name]; exact {
+		return field.name, true
+	}
+	return field.name, false
+}
+
+// customDecoding reports whether values of type t decode themselves from JSON or text.
+func customDecoding(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return false // Pointers are allocated first, and their element is checked
+	}
+	return reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// plainValue converts a value stored in an interface{} field to what encoding/json would store:
+// numbers become float64, and objects and lists are copied.
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = plainValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = plainValue(item)
+		}
+		return copied
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return value
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return value
+	}
+}
+
+// holdsInterfaces reports whether t is a map or a slice of interface values.
+func holdsInterfaces(t reflect.Type) bool {
+	return (t.Kind() == reflect.Map || t.Kind() == reflect.Slice) && t.Elem().Kind() == reflect.Interface
+}
+
+// shallowCopy returns a copy of v when it is a map or a slice, and v otherwise.
+func shallowCopy(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		return copied
+	case v.Kind() == reflect.Map && !v.IsNil():
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			copied.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return copied
+	}
+	return v
+}
+
+// decodeFailure is an error of reflectDecoder.decodeValue, converted into a *SerializationError by
+// decodeAt.
+type decodeFailure struct {
+	tokens  []string // Path of the failed value, innermost first, with indices as "[i]"
+	message string
+	err     error // Cause of the failure, kept as the Err of the *SerializationError
+}
+
+func (f *decodeFailure) Error() string {
+	return f.message
+}
+
+// Unwrap returns the cause of the failure.
+func (f *decodeFailure) Unwrap() error {
+	return f.err
+}
+
+// serializationError converts the failure into a *SerializationError, prefixing its path with
+// path, the location of the decoded value.
+func (f *decodeFailure) serializationError(path string) *SerializationError {
+	for i := len(f.tokens) - 1; i >= 0; i-- {
+		if strings.HasPrefix(f.tokens[i], "[") {
+			path += f.tokens[i]
+		} else {
+			path = joinKeyPath(path, f.tokens[i])
+		}
+	}
+	location := "value"
+	if path != "" {
+		location = fmt.Sprintf("field '%s'", path)
+	}
+	return &SerializationError{Message: fmt.Sprintf("failed to deserialize %s: %s", location, f.message), Err: f.err}
+}
+
+// failureAt adds token to the path of err when it is a *decodeFailure.
+func failureAt(err error, token string) error {
+	if failure, ok := err.(*decodeFailure); ok {
+		failure.tokens = append(failure.tokens, token)
+	}
+	return err
+}
+
+// indexToken returns the path token of the i-th item of a list.
+func indexToken(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// decodeMismatch reports a value of the wrong JSON type for type t. Its cause is a *CodedError with
+// CodeInvalidType wrapping a *json.UnmarshalTypeError, so that the resulting *SerializationError
+// matches ErrTypeMismatch and the error of encoding/json.
+func decodeMismatch(value interface{}, t reflect.Type) error {
+	message := fmt.Sprintf("cannot unmarshal %s into Go value of type %s", jsonKind(value), t)
+	cause := &json.UnmarshalTypeError{Value: jsonKind(value), Type: t}
+	return &decodeFailure{message: message, err: &CodedError{Code: CodeInvalidType, Message: message, Err: cause}}
+}
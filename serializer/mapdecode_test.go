@@ -0,0 +1,171 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type decodeInner struct {
+	Value int `json:"value"`
+}
+
+type decodeEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type decodeTarget struct {
+	*decodeEmbedded
+	Name     string                 `json:"name"`
+	Count    int8                   `json:"count"`
+	Size     uint16                 `json:"size"`
+	Ratio    float32                `json:"ratio"`
+	Flag     bool                   `json:"flag"`
+	Quoted   int64                  `json:"quoted,string"`
+	Pointer  *decodeInner           `json:"pointer"`
+	Inner    decodeInner            `json:"inner"`
+	List     []decodeInner          `json:"list"`
+	Fixed    [2]int                 `json:"fixed"`
+	Bytes    []byte                 `json:"bytes"`
+	ByID     map[int]string         `json:"by_id"`
+	ByIP     map[string]net.IP      `json:"by_ip"`
+	Any      interface{}            `json:"any"`
+	Object   map[string]interface{} `json:"object"`
+	Number   json.Number            `json:"number"`
+	Raw      json.RawMessage        `json:"raw"`
+	When     time.Time              `json:"when"`
+	Duration time.Duration          `json:"duration"`
+}
+
+func TestReflectDecoderMatchesEncodingJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"scalars", `{"name":"a","count":-8,"size":65535,"ratio":0.5,"flag":true}`},
+		{"case-insensitive keys", `{"NAME":"a","Count":1}`},
+		{"unknown keys ignored", `{"name":"a","unknown":{"x":1}}`},
+		{"quoted number", `{"quoted":"42"}`},
+		{"nested structs", `{"pointer":{"value":1},"inner":{"value":2},"list":[{"value":3},{"value":4}]}`},
+		{"short array", `{"fixed":[1]}`},
+		{"long array", `{"fixed":[1,2,3]}`},
+		{"base64 bytes", `{"bytes":"AQID"}`},
+		{"typed map keys", `{"by_id":{"1":"a","-2":"b"}}`},
+		{"text unmarshalers", `{"by_ip":{"a":"10.0.0.1"}}`},
+		{"interfaces", `{"any":[1,"x",{"y":null}],"object":{"n":2}}`},
+		{"number and raw", `{"number":12.5,"raw":{"k":[1,2]}}`},
+		{"time", `{"when":"2024-05-01T12:30:00Z"}`},
+		{"nulls", `{"name":null,"pointer":null,"list":null,"any":null,"by_id":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want decodeTarget
+			if err := json.Unmarshal([]byte(tt.input), &want); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			var input map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.input), &input); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			var got decodeTarget
+			if err := (reflectDecoder{}).decode(input, &got); err != nil {
+				t.Fatalf("decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decode() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReflectDecoderKeepsTypedValues(t *testing.T) {
+	when := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	list := []decodeInner{{Value: 1}}
+	input := map[string]interface{}{"when": when, "list": list, "count": int64(3), "ratio": json.Number("1.5")}
+
+	var got decodeTarget
+	if err := (reflectDecoder{}).decode(input, &got); err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if !got.When.Equal(when) || got.When.Location() != when.Location() {
+		t.Errorf("When = %v, want %v in its own zone", got.When, when)
+	}
+	if got.Count != 3 || got.Ratio != 1.5 {
+		t.Errorf("Count, Ratio = %v, %v, want 3, 1.5", got.Count, got.Ratio)
+	}
+	list[0].Value = 2
+	if got.List[0].Value != 1 {
+		t.Errorf("List shares its backing array with the input")
+	}
+}
+
+func TestReflectDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  string
+	}{
+		{"wrong type", map[string]interface{}{"name": 1.0}, "field 'name': cannot unmarshal number into Go value of type string"},
+		{"overflow", map[string]interface{}{"count": 300.0}, "field 'count': cannot unmarshal number into Go value of type int8"},
+		{"fraction into integer", map[string]interface{}{"size": 1.5}, "field 'size'"},
+		{"negative into unsigned", map[string]interface{}{"size": -1.0}, "field 'size'"},
+		{"nested path", map[string]interface{}{"list": []interface{}{map[string]interface{}{}, map[string]interface{}{"value": "x"}}}, "field 'list[1].value'"},
+		{"map key", map[string]interface{}{"by_id": map[string]interface{}{"x": "a"}}, "field 'by_id.x'"},
+		{"unquoted string option", map[string]interface{}{"quoted": 42.0}, "invalid use of ,string struct tag"},
+		{"bad base64", map[string]interface{}{"bytes": "!"}, "field 'bytes'"},
+		{"bad time", map[string]interface{}{"when": "yesterday"}, "field 'when'"},
+		{"nil embedded pointer to unexported struct", map[string]interface{}{"embedded": "e"}, "cannot set embedded pointer to unexported struct"},
+		{"object into list", map[string]interface{}{"list": map[string]interface{}{}}, "cannot unmarshal object into Go value of type []serializer.decodeInner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got decodeTarget
+			err := (reflectDecoder{}).decode(tt.input, &got)
+			if _, ok := err.(*SerializationError); !ok || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("decode() error = %v, want a SerializationError containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if err := (reflectDecoder{}).decode(map[string]interface{}{}, decodeTarget{}); err == nil {
+		t.Errorf("decode() into a non-pointer error = nil, want an error")
+	}
+}
+
+func TestReflectDecoderErrorCauses(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+	}{
+		{"wrong type", map[string]interface{}{"name": 1.0}},
+		{"nested path", map[string]interface{}{"list": []interface{}{map[string]interface{}{"value": "x"}}}},
+		{"map key", map[string]interface{}{"by_id": map[string]interface{}{"x": "a"}}},
+		{"text unmarshaler", map[string]interface{}{"by_ip": map[string]interface{}{"a": 1.0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got decodeTarget
+			err := (reflectDecoder{}).decode(tt.input, &got)
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) || !errors.Is(err, ErrTypeMismatch) {
+				t.Errorf("decode() error = %v, want one wrapping a *json.UnmarshalTypeError and matching ErrTypeMismatch", err)
+			}
+		})
+	}
+}
+
+func TestReflectDecoderExactKeyWins(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		var got decodeTarget
+		if err := (reflectDecoder{}).decode(map[string]interface{}{"Name": "b", "name": "a", "NAME": "c"}, &got); err != nil {
+			t.Fatalf("decode() error = %v", err)
+		}
+		if got.Name != "a" {
+			t.Fatalf("Name = %q, want the exact key's value %q", got.Name, "a")
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package serializer
+
+import "reflect"
+
+// Marshaler is implemented by types that control their own representation in serialized output,
+// such as money amounts, phone numbers or encrypted strings. Serialize consults it before
+// json.Marshaler and the default encoding; the returned value is serialized in turn, so it may be
+// a scalar, a map, a slice or another struct.
+type Marshaler interface {
+	SerializeField() (interface{}, error)
+}
+
+var marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+
+// serializeOptions are the encode options of a serializer without depth or cycle settings.
+var serializeOptions = &encodeOptions{marshalers: true}
+
+// marshaler returns the Marshaler implemented by v or by its address, if any.
+func marshaler(v reflect.Value) (Marshaler, bool) {
+	t := v.Type()
+	if t.Implements(marshalerType) {
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface().(Marshaler), true
+	}
+	if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(marshalerType) {
+		return v.Addr().Interface().(Marshaler), true
+	}
+	return nil, false
+}
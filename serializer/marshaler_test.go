@@ -0,0 +1,61 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// cents serializes itself by value, as an amount with its currency.
+type cents int64
+
+func (c cents) SerializeField() (interface{}, error) {
+	if c < 0 {
+		return nil, errors.New("negative amount")
+	}
+	return map[string]interface{}{"amount": float64(c) / 100, "currency": "EUR"}, nil
+}
+
+// masked serializes itself through a pointer receiver.
+type masked string
+
+func (m *masked) SerializeField() (interface{}, error) {
+	return "***" + string(*m)[len(*m)-2:], nil
+}
+
+type invoice struct {
+	Total   cents   `json:"total"`
+	Card    masked  `json:"card"`
+	Refund  *cents  `json:"refund"`
+	Account *masked `json:"account"`
+}
+
+func TestMarshaler(t *testing.T) {
+	refund := cents(50)
+	tests := []struct {
+		name    string
+		data    invoice
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"value and pointer receivers", invoice{Total: 1250, Card: "4242424242424242", Refund: &refund},
+			map[string]interface{}{
+				"total":   map[string]interface{}{"amount": 12.5, "currency": "EUR"},
+				"card":    "***42",
+				"refund":  map[string]interface{}{"amount": 0.5, "currency": "EUR"},
+				"account": nil,
+			}, false},
+		{"error", invoice{Total: -1, Card: "00"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&BaseSerializer{}).Serialize(&tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Serialize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package serializer
+
+// MergeStrategy controls how Merge combines values present in both maps. Strategies are flags
+// and can be combined, e.g. MergeDeepMaps | MergeAppendSlices.
+type MergeStrategy int
+
+const (
+	MergeOverwrite    MergeStrategy = 0      // Overlay values replace base values
+	MergeAppendSlices MergeStrategy = 1 << 0 // Overlay lists are appended to base lists
+	MergeDeepMaps     MergeStrategy = 1 << 1 // Nested objects are merged key by key instead of replaced
+)
+
+// Merge layers overlay on top of base and returns the result; neither map is modified. Top-level
+// overlay fields that the serializer doesn't accept (outside Fields, when set, or read-only) are
+// ignored, so Merge can apply sparse updates from clients.
+func (s *BaseSerializer) Merge(base, overlay map[string]interface{}, strategy MergeStrategy) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		if !s.patchable(key) {
+			continue
+		}
+		if old, exists := merged[key]; exists {
+			value = mergeValues(old, value, strategy)
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// mergeValues combines a base value with the overlay value replacing it.
+func mergeValues(base, overlay interface{}, strategy MergeStrategy) interface{} {
+	switch v := overlay.(type) {
+	case []interface{}:
+		if items, ok := base.([]interface{}); ok && strategy&MergeAppendSlices != 0 {
+			return append(append(make([]interface{}, 0, len(items)+len(v)), items...), v...)
+		}
+	case map[string]interface{}:
+		if object, ok := base.(map[string]interface{}); ok && strategy&MergeDeepMaps != 0 {
+			merged := make(map[string]interface{}, len(object)+len(v))
+			for key, value := range object {
+				merged[key] = value
+			}
+			for key, value := range v {
+				if old, exists := merged[key]; exists {
+					value = mergeValues(old, value, strategy)
+				}
+				merged[key] = value
+			}
+			return merged
+		}
+	}
+	return overlay
+}
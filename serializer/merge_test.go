@@ -0,0 +1,61 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]interface{}{
+		"name": "ana",
+		"tags": []interface{}{"a"},
+		"address": map[string]interface{}{
+			"city": "Lima",
+			"geo":  map[string]interface{}{"lat": 1.0, "lng": 2.0},
+		},
+	}
+	overlay := map[string]interface{}{
+		"tags":    []interface{}{"b"},
+		"address": map[string]interface{}{"geo": map[string]interface{}{"lat": 3.0}},
+		"role":    "admin",
+	}
+
+	tests := []struct {
+		name     string
+		s        *BaseSerializer
+		strategy MergeStrategy
+		want     map[string]interface{}
+	}{
+		{"overwrite", &BaseSerializer{}, MergeOverwrite, map[string]interface{}{
+			"name": "ana", "tags": []interface{}{"b"}, "role": "admin",
+			"address": map[string]interface{}{"geo": map[string]interface{}{"lat": 3.0}},
+		}},
+		{"append slices", &BaseSerializer{}, MergeAppendSlices, map[string]interface{}{
+			"name": "ana", "tags": []interface{}{"a", "b"}, "role": "admin",
+			"address": map[string]interface{}{"geo": map[string]interface{}{"lat": 3.0}},
+		}},
+		{"deep maps", &BaseSerializer{}, MergeDeepMaps, map[string]interface{}{
+			"name": "ana", "tags": []interface{}{"b"}, "role": "admin",
+			"address": map[string]interface{}{"city": "Lima", "geo": map[string]interface{}{"lat": 3.0, "lng": 2.0}},
+		}},
+		{"read-only fields ignored", &BaseSerializer{ReadOnlyFields: []string{"role"}}, MergeDeepMaps | MergeAppendSlices, map[string]interface{}{
+			"name": "ana", "tags": []interface{}{"a", "b"},
+			"address": map[string]interface{}{"city": "Lima", "geo": map[string]interface{}{"lat": 3.0, "lng": 2.0}},
+		}},
+		{"fields outside the whitelist ignored", &BaseSerializer{Fields: []string{"name", "tags"}}, MergeOverwrite, map[string]interface{}{
+			"name": "ana", "tags": []interface{}{"b"},
+			"address": map[string]interface{}{"city": "Lima", "geo": map[string]interface{}{"lat": 1.0, "lng": 2.0}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Merge(base, overlay, tt.strategy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if len(base["tags"].([]interface{})) != 1 || len(base["address"].(map[string]interface{})) != 2 || len(overlay) != 3 {
+		t.Errorf("Merge() modified its arguments: base = %v, overlay = %v", base, overlay)
+	}
+}
@@ -0,0 +1,50 @@
+package serializer
+
+import "sync"
+
+// Metadata is a per-call bag of values shared by the stages of a serialization pipeline.
+// It is kept separate from the output map and is safe for concurrent use.
+type Metadata struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewMetadata creates an empty metadata bag. Its map is allocated by the first Set.
+func NewMetadata() *Metadata {
+	return &Metadata{}
+}
+
+// Get returns the value stored under key.
+func (m *Metadata) Get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set stores value under key.
+func (m *Metadata) Set(key string, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	m.values[key] = value
+}
+
+// Values returns a copy of all stored values.
+func (m *Metadata) Values() map[string]interface{} {
+	values := make(map[string]interface{})
+	if m == nil {
+		return values
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for key, value := range m.values {
+		values[key] = value
+	}
+	return values
+}
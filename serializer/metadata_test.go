@@ -0,0 +1,95 @@
+package serializer
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	var empty *Metadata
+	if value, ok := empty.Get("a"); ok || value != nil {
+		t.Errorf("nil Metadata Get() = %v, %v, want nil, false", value, ok)
+	}
+	if got := empty.Values(); got == nil || len(got) != 0 {
+		t.Errorf("nil Metadata Values() = %#v, want an empty map", got)
+	}
+
+	meta := NewMetadata()
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			meta.Set(key, key+key)
+		}(key)
+	}
+	wg.Wait()
+
+	tests := []struct {
+		key    string
+		want   interface{}
+		wantOk bool
+	}{
+		{"a", "aa", true},
+		{"c", "cc", true},
+		{"missing", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got, ok := meta.Get(tt.key); got != tt.want || ok != tt.wantOk {
+				t.Errorf("Get(%q) = %v, %v, want %v, %v", tt.key, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	values := meta.Values()
+	values["a"] = "changed"
+	if got, _ := meta.Get("a"); got != "aa" {
+		t.Errorf("Values() shares its map with the Metadata")
+	}
+}
+
+func TestSerializeWithMetadata(t *testing.T) {
+	s := &BaseSerializer{
+		MetadataTransformations: map[string]func(interface{}, *Metadata) interface{}{
+			"name": func(value interface{}, meta *Metadata) interface{} {
+				meta.Set("seen", value)
+				prefix, _ := meta.Get("prefix")
+				return prefix.(string) + value.(string)
+			},
+		},
+		MetadataConditionalFields: map[string]func(map[string]interface{}, *Metadata) bool{
+			"role": func(_ map[string]interface{}, meta *Metadata) bool {
+				_, ok := meta.Get("admin")
+				return ok
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{"condition unmet", map[string]interface{}{"prefix": "user:"}, map[string]interface{}{"id": 7.0, "name": "user:ana"}},
+		{"condition met", map[string]interface{}{"prefix": "", "admin": true}, map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := NewMetadata()
+			for key, value := range tt.values {
+				meta.Set(key, value)
+			}
+			got, err := s.SerializeWithMetadata(account{ID: 7, Name: "ana", Role: "admin"}, meta)
+			if err != nil {
+				t.Fatalf("SerializeWithMetadata() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeWithMetadata() = %#v, want %#v", got, tt.want)
+			}
+			if seen, _ := meta.Get("seen"); seen != "ana" {
+				t.Errorf("stage stored %v in the Metadata, want %q", seen, "ana")
+			}
+		})
+	}
+}
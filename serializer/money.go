@@ -0,0 +1,240 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// currencyCodes are the active ISO 4217 currency codes.
+var currencyCodes = makeSet(strings.Fields(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN
+	BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP
+	ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK
+	JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK
+	MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN
+	PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL THB
+	TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XAG
+	XAU XBA XBB XBC XBD XCD XDR XOF XPD XPF XPT XSU XTS XUA XXX YER ZAR ZMW ZWL`))
+
+// Money is an amount of money held exactly, as an integer number of minor units of its currency
+// (cents for USD), so amounts don't pick up rounding errors through float64.
+//
+// Money is written as {"amount": "10.50", "currency": "USD"}, with the amount as a decimal
+// string. It is read from that object, with the amount as a string or a number, from
+// {"minor": 1050, "currency": "USD"}, and from strings such as "10.50 USD" or "USD 10.50".
+// Amounts with more decimals than the currency has are rejected rather than rounded. The zero
+// Money, which has no currency, is written as null, and null is read as the zero Money.
+type Money struct {
+	Minor    int64  // Amount in minor units of Currency
+	Currency string // ISO 4217 currency code, e.g. "USD"
+}
+
+// NewMoney returns the Money for a decimal amount, e.g. NewMoney("10.50", "USD").
+func NewMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if err := validCurrency(currency); err != nil {
+		return Money{}, err
+	}
+	minor, err := minorUnits(amount, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// Amount returns the amount as a decimal string with the digits of the currency's minor unit,
+// e.g. "10.50".
+func (m Money) Amount() string {
+	decimals := CurrencyDecimals(m.Currency)
+	if decimals == 0 {
+		return strconv.FormatInt(m.Minor, 10)
+	}
+	return new(big.Rat).SetFrac(big.NewInt(m.Minor), pow10(decimals)).FloatString(decimals)
+}
+
+// String returns the amount followed by the currency, e.g. "10.50 USD".
+func (m Money) String() string {
+	return m.Amount() + " " + m.Currency
+}
+
+// IsZero reports whether m is the zero Money, without a currency.
+func (m Money) IsZero() bool {
+	return m.Minor == 0 && m.Currency == ""
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(map[string]string{"amount": m.Amount(), "currency": m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	if value == nil {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := parseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MoneyField is an amount of money in a currency, as written by Money. Input in any of the shapes
+// Money reads is accepted and coerced to {"amount": "10.50", "currency": "USD"}; Currencies
+// restricts the accepted currencies. On Serialize, the amount is written as a decimal string, or
+// as an integer number of minor units with MinorUnits ({"amount": 1050, "currency": "USD"}).
+// When a locale is set, the amount is formatted for it instead (see Localizer).
+type MoneyField struct {
+	Currencies []string // Accepted ISO 4217 codes; every code when empty
+	MinorUnits bool     // Write the amount in minor units rather than as a decimal string
+	Required   bool
+}
+
+func (f MoneyField) Validate(value interface{}) error {
+	_, err := f.money(value)
+	return err
+}
+
+func (f MoneyField) Coerce(value interface{}) (interface{}, error) {
+	m, err := f.money(value)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"amount": m.Amount(), "currency": m.Currency}, nil
+}
+
+func (f MoneyField) Represent(value interface{}) (interface{}, error) {
+	m, err := parseMoney(value)
+	if err != nil {
+		return nil, err
+	}
+	if f.MinorUnits {
+		return map[string]interface{}{"amount": m.Minor, "currency": m.Currency}, nil
+	}
+	return map[string]interface{}{"amount": m.Amount(), "currency": m.Currency}, nil
+}
+
+func (f MoneyField) Localize(value interface{}, format LocaleFormat) (interface{}, error) {
+	if object, ok := value.(map[string]interface{}); ok && f.MinorUnits {
+		// Represented in minor units
+		value = map[string]interface{}{"minor": object["amount"], "currency": object["currency"]}
+	}
+	m, err := parseMoney(value)
+	if err != nil {
+		return nil, err
+	}
+	return format.formatCurrencyDigits(m.Amount(), m.Currency), nil
+}
+
+func (f MoneyField) IsRequired() bool { return f.Required }
+
+// money parses value and checks its currency against Currencies.
+func (f MoneyField) money(value interface{}) (Money, error) {
+	m, err := parseMoney(value)
+	if err != nil {
+		return Money{}, err
+	}
+	if len(f.Currencies) > 0 {
+		for _, currency := range f.Currencies {
+			if strings.EqualFold(currency, m.Currency) {
+				return m, nil
+			}
+		}
+		return Money{}, NewCodedErrorWithParams(CodeInvalidCurrency, fmt.Sprintf("currency must be one of %s", strings.Join(f.Currencies, ", ")), Params{"allowed": f.Currencies})
+	}
+	return m, nil
+}
+
+// parseMoney reads Money from a Money value, an object with an amount or minor units and a
+// currency, or a string holding the amount and the currency.
+func parseMoney(value interface{}) (Money, error) {
+	switch v := value.(type) {
+	case Money:
+		return v, validCurrency(v.Currency)
+	case *Money:
+		if v != nil {
+			return *v, validCurrency(v.Currency)
+		}
+	case map[string]interface{}:
+		currency, ok := v["currency"].(string)
+		if !ok {
+			return Money{}, NewCodedError(CodeInvalidCurrency, "money needs a currency")
+		}
+		if minor, exists := v["minor"]; exists {
+			digits, ok := decimalDigits(minor)
+			n, err := strconv.ParseInt(digits, 10, 64)
+			if !ok || err != nil {
+				return Money{}, NewCodedError(CodeInvalidAmount, "minor units must be an integer")
+			}
+			currency = strings.ToUpper(strings.TrimSpace(currency))
+			return Money{Minor: n, Currency: currency}, validCurrency(currency)
+		}
+		amount, ok := decimalDigits(v["amount"])
+		if !ok {
+			return Money{}, NewCodedError(CodeInvalidAmount, "amount is not a number")
+		}
+		return NewMoney(amount, currency)
+	case string:
+		parts := strings.Fields(v)
+		if len(parts) == 2 {
+			if _, ok := decimalDigits(parts[1]); ok {
+				parts[0], parts[1] = parts[1], parts[0] // "USD 10.50"
+			}
+			return NewMoney(parts[0], parts[1])
+		}
+	}
+	return Money{}, NewCodedError(CodeInvalidType, "value is not an amount of money")
+}
+
+// minorUnits converts a decimal amount into minor units of currency, rejecting amounts with
+// more decimals than the currency has.
+func minorUnits(amount, currency string) (int64, error) {
+	digits, ok := decimalDigits(strings.TrimSpace(amount))
+	if !ok {
+		return 0, NewCodedError(CodeInvalidAmount, "amount is not a number")
+	}
+	r, _ := new(big.Rat).SetString(digits)
+	decimals := CurrencyDecimals(currency)
+	r.Mul(r, new(big.Rat).SetInt(pow10(decimals)))
+	if !r.IsInt() {
+		return 0, NewCodedErrorWithParams(CodeInvalidAmount, fmt.Sprintf("amount has more than %d decimals for %s", decimals, currency), Params{"decimals": decimals, "currency": currency})
+	}
+	if !r.Num().IsInt64() {
+		return 0, NewCodedError(CodeInvalidAmount, "amount is too large")
+	}
+	return r.Num().Int64(), nil
+}
+
+// validCurrency checks that currency is an active ISO 4217 code.
+func validCurrency(currency string) error {
+	if !currencyCodes[currency] {
+		return NewCodedErrorWithParams(CodeInvalidCurrency, fmt.Sprintf("'%s' is not an ISO 4217 currency code", currency), Params{"currency": currency})
+	}
+	return nil
+}
+
+// pow10 returns 10 to the power of n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// makeSet returns a set of items.
+func makeSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
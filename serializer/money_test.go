@@ -0,0 +1,147 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		code     string // Expected error code, or "" when the amount is valid
+	}{
+		{"10.50", "usd", Money{Minor: 1050, Currency: "USD"}, ""},
+		{"-0.5", "EUR", Money{Minor: -50, Currency: "EUR"}, ""},
+		{"1500", "CLP", Money{Minor: 1500, Currency: "CLP"}, ""},
+		{"1.234", "KWD", Money{Minor: 1234, Currency: "KWD"}, ""},
+		{"10.505", "USD", Money{}, CodeInvalidAmount},
+		{"1.5", "JPY", Money{}, CodeInvalidAmount},
+		{"1e3", "USD", Money{}, CodeInvalidAmount},
+		{"99999999999999999999", "USD", Money{}, CodeInvalidAmount},
+		{"10", "XYZ", Money{}, CodeInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			got, err := NewMoney(tt.amount, tt.currency)
+			if code := ErrorCode(err); code != tt.code {
+				t.Fatalf("NewMoney() error = %v, want code %q", err, tt.code)
+			}
+			if got != tt.want {
+				t.Errorf("NewMoney() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyAmount(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{Minor: 1050, Currency: "USD"}, "10.50 USD"},
+		{Money{Minor: -5, Currency: "USD"}, "-0.05 USD"},
+		{Money{Minor: 1500, Currency: "JPY"}, "1500 JPY"},
+		{Money{Minor: 1, Currency: "BHD"}, "0.001 BHD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{`{"amount":"10.50","currency":"USD"}`, Money{Minor: 1050, Currency: "USD"}},
+		{`{"amount":10.5,"currency":"USD"}`, Money{Minor: 1050, Currency: "USD"}},
+		{`{"minor":1050,"currency":"usd"}`, Money{Minor: 1050, Currency: "USD"}},
+		{`"10.50 USD"`, Money{Minor: 1050, Currency: "USD"}},
+		{`"USD 10.50"`, Money{Minor: 1050, Currency: "USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got Money
+			if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("json.Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+			encoded, _ := json.Marshal(got)
+			if want := `{"amount":"10.50","currency":"USD"}`; string(encoded) != want {
+				t.Errorf("json.Marshal() = %s, want %s", encoded, want)
+			}
+		})
+	}
+
+	encoded, err := json.Marshal(Money{})
+	if err != nil || string(encoded) != "null" {
+		t.Errorf("json.Marshal(Money{}) = %s, %v, want null", encoded, err)
+	}
+	decoded := Money{Minor: 1050, Currency: "USD"}
+	if err := json.Unmarshal(encoded, &decoded); err != nil || !decoded.IsZero() {
+		t.Errorf("json.Unmarshal(%s) = %+v, %v, want the zero Money", encoded, decoded, err)
+	}
+
+	type order struct {
+		Total Money `json:"total"`
+	}
+	s := &BaseSerializer{}
+	serialized, err := s.Serialize(order{})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if want := map[string]interface{}{"total": nil}; !reflect.DeepEqual(serialized, want) {
+		t.Errorf("Serialize() = %v, want %v", serialized, want)
+	}
+	got := order{Total: Money{Minor: 1, Currency: "USD"}}
+	if err := s.Deserialize(serialized, &got); err != nil || !got.Total.IsZero() {
+		t.Errorf("Deserialize() = %+v, %v, want the zero Money", got, err)
+	}
+
+	for _, input := range []string{`{"amount":"10.50"}`, `{"minor":1.5,"currency":"USD"}`, `"10.50"`, `true`} {
+		if err := json.Unmarshal([]byte(input), new(Money)); err == nil {
+			t.Errorf("json.Unmarshal(%s) error = nil, want an error", input)
+		}
+	}
+}
+
+func TestMoneyField(t *testing.T) {
+	field := MoneyField{Currencies: []string{"USD", "EUR"}}
+	if _, err := field.Coerce("10 CLP"); ErrorCode(err) != CodeInvalidCurrency {
+		t.Errorf("Coerce() of another currency error = %v, want code %q", err, CodeInvalidCurrency)
+	}
+
+	tests := []struct {
+		name  string
+		field MoneyField
+		want  interface{}
+	}{
+		{"decimal string", MoneyField{}, map[string]interface{}{"amount": "1234.50", "currency": "EUR"}},
+		{"minor units", MoneyField{MinorUnits: true}, map[string]interface{}{"amount": int64(123450), "currency": "EUR"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coerced, err := tt.field.Coerce(map[string]interface{}{"amount": "1234.5", "currency": "eur"})
+			if err != nil {
+				t.Fatalf("Coerce() error = %v", err)
+			}
+			represented, err := tt.field.Represent(coerced)
+			if err != nil || !reflect.DeepEqual(represented, tt.want) {
+				t.Fatalf("Represent() = %v, %v, want %v", represented, err, tt.want)
+			}
+			localized, err := tt.field.Localize(represented, LocaleFormatFor("es"))
+			if want := "1.234,50 €"; err != nil || localized != want {
+				t.Errorf("Localize() = %v, %v, want %q", localized, err, want)
+			}
+		})
+	}
+}
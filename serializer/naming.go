@@ -0,0 +1,297 @@
+package serializer
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy controls how keys are renamed in serialized output and matched on input.
+type NamingStrategy int
+
+const (
+	NamingDefault    NamingStrategy = iota // Keep keys as produced by the struct's JSON tags
+	NamingSnakeCase                        // user_id
+	NamingCamelCase                        // userId
+	NamingPascalCase                       // UserID
+	NamingKebabCase                        // user-id
+)
+
+// DefaultAcronyms is the acronym dictionary used when a serializer doesn't define its own.
+// Acronyms are recognized as single words when splitting keys, so "UserID" becomes "user_id"
+// rather than "user_i_d", and are written in upper case by NamingPascalCase.
+var DefaultAcronyms = []string{
+	"API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON",
+	"QPS", "RAM", "RPC", "SKU", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "URI", "URL", "UTF8",
+	"UUID", "VM", "XML", "XSRF", "XSS", "YAML",
+}
+
+// ConvertKey converts key to the given naming strategy. When acronyms is nil, DefaultAcronyms is used.
+func ConvertKey(key string, strategy NamingStrategy, acronyms []string) string {
+	if strategy == NamingDefault {
+		return key
+	}
+	if acronyms == nil {
+		acronyms = DefaultAcronyms
+	}
+
+	words := splitWords(key, acronyms)
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		switch strategy {
+		case NamingSnakeCase, NamingKebabCase:
+			words[i] = lower
+		case NamingCamelCase:
+			if i == 0 {
+				words[i] = lower
+			} else {
+				words[i] = strings.ToUpper(lower[:1]) + lower[1:]
+			}
+		case NamingPascalCase:
+			if acronym, ok := lookupAcronym(word, acronyms); ok {
+				words[i] = acronym
+			} else {
+				words[i] = strings.ToUpper(lower[:1]) + lower[1:]
+			}
+		}
+	}
+
+	switch strategy {
+	case NamingSnakeCase:
+		return strings.Join(words, "_")
+	case NamingKebabCase:
+		return strings.Join(words, "-")
+	default:
+		return strings.Join(words, "")
+	}
+}
+
+// splitWords splits a key into words on separators, case changes and known acronyms.
+func splitWords(key string, acronyms []string) []string {
+	runes := []rune(key)
+	var words []string
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			i++
+			continue
+		}
+		if n := matchAcronym(runes[i:], acronyms); n > 0 {
+			words = append(words, string(runes[i:i+n]))
+			i += n
+			continue
+		}
+
+		j := i + 1
+		switch {
+		case unicode.IsUpper(r):
+			for j < len(runes) && unicode.IsUpper(runes[j]) {
+				j++
+			}
+			if j-i > 1 && j < len(runes) && unicode.IsLower(runes[j]) {
+				j-- // "HTTPServer": the last upper case letter starts the next word
+			}
+			if j-i == 1 {
+				for j < len(runes) && (unicode.IsLower(runes[j]) || unicode.IsDigit(runes[j])) {
+					j++
+				}
+			}
+		case unicode.IsDigit(r):
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+		default:
+			for j < len(runes) && (unicode.IsLower(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+		}
+		words = append(words, string(runes[i:j]))
+		i = j
+	}
+	return words
+}
+
+// matchAcronym returns the length of the longest acronym (optionally pluralized with "s")
+// at the start of runes, or 0 if none matches.
+func matchAcronym(runes []rune, acronyms []string) int {
+	if len(runes) == 0 || !unicode.IsUpper(runes[0]) {
+		return 0
+	}
+
+	best := 0
+	for _, acronym := range acronyms {
+		a := []rune(acronym)
+		if len(a) > len(runes) || string(runes[:len(a)]) != acronym {
+			continue
+		}
+
+		n := len(a)
+		if n < len(runes) && runes[n] == 's' && (n+1 == len(runes) || !unicode.IsLower(runes[n+1])) {
+			n++ // "IDs"
+		} else if n < len(runes) && unicode.IsLower(runes[n]) {
+			continue // The acronym is the start of a longer word
+		}
+		if n > best {
+			best = n
+		}
+	}
+	return best
+}
+
+// lookupAcronym returns the dictionary spelling of word if it is an acronym or its plural.
+func lookupAcronym(word string, acronyms []string) (string, bool) {
+	for _, acronym := range acronyms {
+		if strings.EqualFold(word, acronym) {
+			return acronym, true
+		}
+		if strings.EqualFold(word, acronym+"s") {
+			return acronym + "s", true
+		}
+	}
+	return "", false
+}
+
+// convertKey converts a key using the serializer's naming strategy and acronyms.
+func (s *BaseSerializer) convertKey(key string) string {
+	return ConvertKey(key, s.KeyNaming, s.Acronyms)
+}
+
+// convertKeys renames the keys of result, the serialized form of a value of type t, using the
+// serializer's naming strategy. Nested objects are followed through the fields of t, so that only
+// the keys of struct fields are renamed and the keys of maps, which are data, are kept.
+func (s *BaseSerializer) convertKeys(result map[string]interface{}, t reflect.Type) map[string]interface{} {
+	fields, embedded := s.namedFields(t)
+	converted := make(map[string]interface{}, len(result))
+	for key, item := range result {
+		switch {
+		case fields[key] != nil:
+			item = s.convertValue(item, fields[key].typ)
+		case embedded[key] != nil:
+			item = s.convertValue(item, embedded[key])
+		default:
+			item = s.convertValue(item, nil)
+		}
+		converted[s.convertKey(key)] = item
+	}
+	return converted
+}
+
+// convertValue renames the struct field keys within value, a serialized value of type t. Values
+// whose type isn't known are kept unchanged.
+func (s *BaseSerializer) convertValue(value interface{}, t reflect.Type) interface{} {
+	t = namedType(t)
+	if t == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			return s.convertKeys(v, t)
+		case reflect.Map:
+			converted := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				converted[key] = s.convertValue(item, t.Elem())
+			}
+			return converted
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			converted := make([]interface{}, len(v))
+			for i, item := range v {
+				converted[i] = s.convertValue(item, t.Elem())
+			}
+			return converted
+		}
+	}
+	return value
+}
+
+// namedFields returns the fields of struct type t by JSON key, and the types of the embedded
+// structs that EmbeddedNest writes as objects by their Go names. Both are empty for other types.
+func (s *BaseSerializer) namedFields(t reflect.Type) (map[string]*schemaField, map[string]reflect.Type) {
+	t = namedType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	schema := schemaFor(t)
+	fields := make(map[string]*schemaField, len(schema.fields))
+	for i := range schema.fields {
+		fields[schema.fields[i].name] = &schema.fields[i]
+	}
+	var embedded map[string]reflect.Type
+	if s.EmbeddedStructs == EmbeddedNest {
+		embedded = make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			if sf := t.Field(i); promotesFields(sf) {
+				embedded[sf.Name] = sf.Type
+			}
+		}
+	}
+	return fields, embedded
+}
+
+// namedType dereferences t and returns nil when values of t aren't serialized with the keys of
+// their fields: interfaces and types with their own JSON or text encoding.
+func namedType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() == reflect.Interface {
+		return nil
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return nil
+	}
+	return t
+}
+
+// restoreKeys renames converted input keys back to the JSON keys of the target type t. Keys that
+// don't correspond to a field of t are kept unchanged, as are the keys of maps.
+func (s *BaseSerializer) restoreKeys(value interface{}, t reflect.Type) interface{} {
+	t = namedType(t)
+	if t == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			schema := schemaFor(t)
+			fields := make(map[string]*schemaField, len(schema.fields))
+			for i := range schema.fields {
+				fields[s.convertKey(schema.fields[i].name)] = &schema.fields[i]
+			}
+
+			restored := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				if field, ok := fields[key]; ok {
+					restored[field.name] = s.restoreKeys(item, field.typ)
+				} else {
+					restored[key] = item
+				}
+			}
+			return restored
+		case reflect.Map:
+			restored := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				restored[key] = s.restoreKeys(item, t.Elem())
+			}
+			return restored
+		}
+		return value
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return value
+		}
+		restored := make([]interface{}, len(v))
+		for i, item := range v {
+			restored[i] = s.restoreKeys(item, t.Elem())
+		}
+		return restored
+	default:
+		return value
+	}
+}
@@ -0,0 +1,101 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		snake  string
+		camel  string
+		pascal string
+		kebab  string
+	}{
+		{"user_id", "user_id", "userId", "UserID", "user-id"},
+		{"UserID", "user_id", "userId", "UserID", "user-id"},
+		{"userIDs", "user_ids", "userIds", "UserIDs", "user-ids"},
+		{"HTTPServer", "http_server", "httpServer", "HTTPServer", "http-server"},
+		{"XMLHttpRequest", "xml_http_request", "xmlHttpRequest", "XMLHTTPRequest", "xml-http-request"},
+		{"address2Line", "address2_line", "address2Line", "Address2Line", "address2-line"},
+		{"created-at", "created_at", "createdAt", "CreatedAt", "created-at"},
+		{"page 2", "page_2", "page2", "Page2", "page-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			for strategy, want := range map[NamingStrategy]string{
+				NamingDefault:    tt.key,
+				NamingSnakeCase:  tt.snake,
+				NamingCamelCase:  tt.camel,
+				NamingPascalCase: tt.pascal,
+				NamingKebabCase:  tt.kebab,
+			} {
+				if got := ConvertKey(tt.key, strategy, nil); got != want {
+					t.Errorf("ConvertKey(%q, %d) = %q, want %q", tt.key, strategy, got, want)
+				}
+			}
+		})
+	}
+
+	if got := ConvertKey("OrderSKU", NamingPascalCase, []string{}); got != "OrderSku" {
+		t.Errorf("ConvertKey() without acronyms = %q, want %q", got, "OrderSku")
+	}
+}
+
+func TestRestoreKeys(t *testing.T) {
+	type line struct {
+		ItemSKU string `json:"item_sku"`
+	}
+	type order struct {
+		OrderID int    `json:"order_id"`
+		Lines   []line `json:"lines"`
+	}
+	s := &BaseSerializer{KeyNaming: NamingCamelCase}
+	input := map[string]interface{}{"orderId": 1, "lines": []interface{}{map[string]interface{}{"itemSku": "a"}}, "other": true}
+	want := map[string]interface{}{"order_id": 1, "lines": []interface{}{map[string]interface{}{"item_sku": "a"}}, "other": true}
+	if got := s.restoreKeys(input, reflect.TypeOf(&order{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("restoreKeys() = %v, want %v", got, want)
+	}
+	if got := s.convertKeys(want, reflect.TypeOf(order{})); !reflect.DeepEqual(got, map[string]interface{}{"orderId": 1, "lines": []interface{}{map[string]interface{}{"itemSku": "a"}}, "other": true}) {
+		t.Errorf("convertKeys() = %v", got)
+	}
+}
+
+func TestKeyNamingKeepsMapKeys(t *testing.T) {
+	type item struct {
+		ItemName string            `json:"item_name"`
+		Labels   map[string]string `json:"labels"`
+	}
+	type catalog struct {
+		ItemsByID map[string]item   `json:"items_by_id"`
+		Labels    map[string]string `json:"labels"`
+		Extra     interface{}       `json:"extra_data"`
+	}
+	s := &BaseSerializer{KeyNaming: NamingCamelCase}
+	in := catalog{
+		ItemsByID: map[string]item{"ItemOne": {ItemName: "a", Labels: map[string]string{"HTTPHeader": "x"}}},
+		Labels:    map[string]string{"HTTPHeader": "x", "fooBar": "y"},
+		Extra:     map[string]interface{}{"someKey": 1.0},
+	}
+	got, err := s.Serialize(in)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"itemsById": map[string]interface{}{"ItemOne": map[string]interface{}{"itemName": "a", "labels": map[string]interface{}{"HTTPHeader": "x"}}},
+		"labels":    map[string]interface{}{"HTTPHeader": "x", "fooBar": "y"},
+		"extraData": map[string]interface{}{"someKey": 1.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Serialize() = %#v, want %#v", got, want)
+	}
+
+	var out catalog
+	if err := s.Deserialize(got, &out); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Deserialize() = %+v, want %+v", out, in)
+	}
+}
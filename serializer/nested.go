@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ValidateAll checks data like Validate but reports every failure instead of the first one, as a
+// *ValidationErrors sorted by field. Failures within Nested fields are reported with the path of
+// the value, e.g. "items[2].price" for the price of the third item of a list, or
+// "address.city" for a field of a nested object.
+func (s *BaseSerializer) ValidateAll(data map[string]interface{}) error {
+	return s.ValidateAllWithContext(context.Background(), data)
+}
+
+// ValidateAllWithContext is like ValidateAll, also running the context-aware validations.
+func (s *BaseSerializer) ValidateAllWithContext(ctx context.Context, data map[string]interface{}) error {
+	var errs []error
+	s.validate(ctx, data, func(err error) bool {
+		errs = append(errs, err)
+		return true
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errorField(errs[i]) < errorField(errs[j])
+	})
+	return &ValidationErrors{Errors: errs}
+}
+
+// validateNested validates the values of the Nested fields with their serializers, passing each
+// failure to report with its path. It returns false when report stops the validation.
+func (s *BaseSerializer) validateNested(ctx context.Context, data map[string]interface{}, report func(error) bool) bool {
+	for field, nested := range s.Nested {
+		value, exists := data[field]
+		if !exists || value == nil || nested == nil {
+			continue
+		}
+		if items, ok := value.([]interface{}); ok {
+			for i, item := range items {
+				if !validateNestedObject(ctx, nested, item, fmt.Sprintf("%s[%d]", field, i), []string{field, strconv.Itoa(i)}, report) {
+					return false
+				}
+			}
+			continue
+		}
+		if !validateNestedObject(ctx, nested, value, field, []string{field}, report) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateNestedObject validates an object at field, whose path tokens are path, with s.
+func validateNestedObject(ctx context.Context, s *BaseSerializer, value interface{}, field string, path []string, report func(error) bool) bool {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return report(&ValidationError{Field: field, Path: path, Value: value, Message: s.translate(ctx, CodeNotObject, nil, "value is not an object"), Code: CodeNotObject})
+	}
+	stopped := false
+	s.validate(ctx, object, func(err error) bool {
+		if !report(qualifyError(err, field, path)) {
+			stopped = true
+		}
+		return !stopped
+	})
+	return !stopped
+}
+
+// qualifyError prefixes the fields of a failure within a nested object with the object's field
+// and path tokens. Other errors are returned unchanged.
+func qualifyError(err error, field string, path []string) error {
+	switch e := err.(type) {
+	case *ValidationError:
+		qualified := *e
+		qualified.Field = field + "." + e.Field
+		qualified.Path = append(append([]string(nil), path...), e.pathTokens()...)
+		return &qualified
+	case *ConstraintError:
+		qualified := *e
+		qualified.Fields = make([]string, len(e.Fields))
+		for i, name := range e.Fields {
+			qualified.Fields[i] = field + "." + name
+		}
+		return &qualified
+	default:
+		return err
+	}
+}
+
+// errorField returns the field of a failure for sorting, the first one for a ConstraintError.
+func errorField(err error) string {
+	switch e := err.(type) {
+	case *ValidationError:
+		return e.Field
+	case *ConstraintError:
+		if len(e.Fields) > 0 {
+			return e.Fields[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateAll(t *testing.T) {
+	line := &BaseSerializer{
+		Validations: map[string][]func(interface{}) error{"price": {Positive}},
+		Constraints: []Constraint{ExactlyOneOf("sku", "code")},
+	}
+	address := &BaseSerializer{
+		Rules:  map[string]string{"city": "required"},
+		Nested: map[string]*BaseSerializer{"geo": {Validations: map[string][]func(interface{}) error{"lat": {Positive}}}},
+	}
+	s := &BaseSerializer{
+		Rules:  map[string]string{"name": "required"},
+		Nested: map[string]*BaseSerializer{"items": line, "address": address, "unchecked": nil},
+	}
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		want     []string // Fields of the failures, in order
+		pointers []string
+	}{
+		{"valid", map[string]interface{}{
+			"name":      "ana",
+			"items":     []interface{}{map[string]interface{}{"price": 1.0, "sku": "a"}},
+			"address":   map[string]interface{}{"city": "Lima"},
+			"unchecked": "x",
+		}, nil, nil},
+		{"missing nested values are skipped", map[string]interface{}{"name": "ana", "address": nil}, nil, nil},
+		{"every failure", map[string]interface{}{
+			"items":   []interface{}{map[string]interface{}{"price": 1.0, "sku": "a"}, map[string]interface{}{"price": -1.0, "sku": "b"}, "x"},
+			"address": map[string]interface{}{"geo": map[string]interface{}{"lat": -1.0}},
+		}, []string{"address.city", "address.geo.lat", "items[1].price", "items[2]", "name"},
+			[]string{"/address/city", "/address/geo/lat", "/items/1/price", "/items/2", "/name"}},
+		{"nested constraint", map[string]interface{}{
+			"name":  "ana",
+			"items": []interface{}{map[string]interface{}{"price": 1.0}},
+		}, []string{"items[0].sku"}, []string{"/items/0/sku", "/items/0/code"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateAll(tt.data)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("ValidateAll() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErrs *ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("ValidateAll() error = %v, want ValidationErrors", err)
+			}
+			var fields []string
+			for _, failure := range validationErrs.Errors {
+				fields = append(fields, errorField(failure))
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("ValidateAll() failed %q, want %q", fields, tt.want)
+			}
+			if got := ErrorPointers(err); !reflect.DeepEqual(got, tt.pointers) {
+				t.Errorf("ErrorPointers() = %q, want %q", got, tt.pointers)
+			}
+		})
+	}
+
+	// Validate stops at the first failure, nested ones included
+	err := s.Validate(map[string]interface{}{"name": "ana", "items": []interface{}{map[string]interface{}{"price": -1.0, "sku": "a"}, map[string]interface{}{"price": -2.0, "sku": "b"}}})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "items[0].price" {
+		t.Errorf("Validate() error = %v, want the failure of items[0].price", err)
+	}
+}
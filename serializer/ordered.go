@@ -0,0 +1,184 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is an object that keeps its keys in insertion order. It encodes to JSON and YAML
+// with the keys in that order, and decodes JSON objects keeping the order of their keys.
+// The zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap creates an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{}
+}
+
+// Set stores value under key. New keys are added at the end; existing keys keep their position.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value stored under key.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Delete removes key.
+func (m *OrderedMap) Delete(key string) {
+	if _, exists := m.values[key]; !exists {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in order.
+func (m *OrderedMap) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Map returns the keys and values as a plain map.
+func (m *OrderedMap) Map() map[string]interface{} {
+	values := make(map[string]interface{}, len(m.values))
+	for key, value := range m.values {
+		values[key] = value
+	}
+	return values
+}
+
+// MarshalJSON encodes the map as a JSON object with the keys in order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object, keeping the order of its top-level keys. Nested objects
+// are decoded as map[string]interface{}.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("OrderedMap: JSON value is not an object")
+	}
+
+	*m = OrderedMap{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		m.Set(token.(string), value)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// MarshalYAML encodes the map as a YAML mapping with the keys in order.
+func (m *OrderedMap) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range m.keys {
+		var value yaml.Node
+		if err := value.Encode(m.values[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+	return node, nil
+}
+
+// SerializeOrdered serializes data like Serialize, returning the result as an OrderedMap. Keys
+// follow Order, then Fields, then the declaration order of the struct's fields; keys added by the
+// pipeline, such as computed fields and links, come last in sorted order.
+func (s *BaseSerializer) SerializeOrdered(data interface{}, opts ...Option) (*OrderedMap, error) {
+	return s.SerializeOrderedWithContext(context.Background(), data, opts...)
+}
+
+// SerializeOrderedWithContext is like SerializeOrdered, passing ctx to the context-aware pipeline stages.
+func (s *BaseSerializer) SerializeOrderedWithContext(ctx context.Context, data interface{}, opts ...Option) (*OrderedMap, error) {
+	if len(opts) > 0 {
+		s = s.view(opts)
+	}
+	result, err := s.SerializeWithContext(ctx, data)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	ordered := &OrderedMap{values: result, keys: make([]string, 0, len(result))}
+	add := func(key string) {
+		if _, exists := result[key]; exists && !containsField(ordered.keys, key) {
+			ordered.keys = append(ordered.keys, key)
+		}
+	}
+	for _, key := range s.Order {
+		add(key)
+	}
+	for _, field := range s.Fields {
+		add(s.outputKey(field))
+	}
+	if t := reflect.TypeOf(data); t != nil && baseType(t).Kind() == reflect.Struct {
+		for _, field := range schemaFor(baseType(t)).fields {
+			add(s.outputKey(field.name))
+		}
+	}
+	if len(ordered.keys) < len(result) {
+		rest := make([]string, 0, len(result)-len(ordered.keys))
+		for key := range result {
+			if !containsField(ordered.keys, key) {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		ordered.keys = append(ordered.keys, rest...)
+	}
+	return ordered, nil
+}
@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap
+	m.Set("b", 1)
+	m.Set("a", map[string]interface{}{"z": 1, "y": 2})
+	m.Set("c", nil)
+	m.Set("b", 2)
+	m.Delete("c")
+	m.Delete("missing")
+
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("Keys() = %q, want [b a]", got)
+	}
+	if value, ok := m.Get("b"); !ok || value != 2 || m.Len() != 2 {
+		t.Errorf("Get(b) = %v, %v with Len() %d, want 2, true with Len() 2", value, ok, m.Len())
+	}
+	if _, ok := m.Get("c"); ok {
+		t.Errorf("Get(c) found a deleted key")
+	}
+	m.Map()["b"] = 3
+	if value, _ := m.Get("b"); value != 2 {
+		t.Errorf("Map() shares its values with the OrderedMap")
+	}
+
+	encoded, err := json.Marshal(&m)
+	if err != nil || string(encoded) != `{"b":2,"a":{"y":2,"z":1}}` {
+		t.Errorf("json.Marshal() = %s, %v", encoded, err)
+	}
+	yamlEncoded, err := yaml.Marshal(&m)
+	if err != nil || string(yamlEncoded) != "b: 2\na:\n    \"y\": 2\n    z: 1\n" {
+		t.Errorf("yaml.Marshal() = %q, %v", yamlEncoded, err)
+	}
+}
+
+func TestOrderedMapUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{"object", `{"z": 1, "a": {"y": 1, "b": 2}, "m": [1]}`, []string{"z", "a", "m"}, false},
+		{"empty", `{}`, nil, false},
+		{"not an object", `[1]`, nil, true},
+		{"malformed", `{"z": }`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewOrderedMap()
+			m.Set("stale", true)
+			err := json.Unmarshal([]byte(tt.data), m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(m.Keys(), tt.wantKeys) {
+				t.Errorf("Keys() = %q, want %q", m.Keys(), tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestSerializeOrdered(t *testing.T) {
+	computed := map[string]func(interface{}) (interface{}, error){
+		"label": func(interface{}) (interface{}, error) { return "x", nil },
+		"badge": func(interface{}) (interface{}, error) { return "y", nil },
+	}
+	tests := []struct {
+		name string
+		s    *BaseSerializer
+		opts []Option
+		want string
+	}{
+		{"declaration order", &BaseSerializer{}, nil, `{"id":7,"name":"ana","role":"admin"}`},
+		{"order first", &BaseSerializer{Order: []string{"role", "missing"}}, nil, `{"role":"admin","id":7,"name":"ana"}`},
+		{"fields", &BaseSerializer{Fields: []string{"name", "id"}}, nil, `{"name":"ana","id":7}`},
+		{"converted keys", &BaseSerializer{KeyNaming: NamingCamelCase, Order: []string{"name"}}, nil, `{"name":"ana","id":7,"role":"admin"}`},
+		{"pipeline keys last", &BaseSerializer{ComputedFields: computed}, nil, `{"id":7,"name":"ana","role":"admin","badge":"y","label":"x"}`},
+		{"options", &BaseSerializer{}, []Option{WithFields("role")}, `{"role":"admin"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.SerializeOrdered(account{ID: 7, Name: "ana", Role: "admin"}, tt.opts...)
+			if err != nil {
+				t.Fatalf("SerializeOrdered() error = %v", err)
+			}
+			encoded, err := json.Marshal(got)
+			if err != nil || string(encoded) != tt.want {
+				t.Errorf("SerializeOrdered() = %s, %v, want %s", encoded, err, tt.want)
+			}
+		})
+	}
+
+	if got, err := (&BaseSerializer{}).SerializeOrdered(nil); got != nil || err != nil {
+		t.Errorf("SerializeOrdered(nil) = %v, %v, want nil, nil", got, err)
+	}
+}
@@ -0,0 +1,682 @@
+package serializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation represents a single JSON Patch (RFC 6902) operation.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON encodes the operation, keeping "value" for operations that require it even when it is null.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	encoded := map[string]interface{}{"op": op.Op, "path": op.Path}
+	if op.From != "" {
+		encoded["from"] = op.From
+	}
+	if op.Value != nil || op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+		encoded["value"] = op.Value
+	}
+	return json.Marshal(encoded)
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) document to the struct pointed to by out.
+// The patch addresses the fields under the keys Serialize writes them as, see patchDocument, and
+// the patched document is read back through the input pipeline of Deserialize.
+func (s *BaseSerializer) ApplyMergePatch(patch []byte, out interface{}) error {
+	var doc interface{}
+	if err := json.Unmarshal(patch, &doc); err != nil {
+		return &SerializationError{Message: fmt.Sprintf("failed to parse merge patch: %v", err), Err: err}
+	}
+
+	ctx := context.Background()
+	current, err := s.patchDocument(ctx, out)
+	if err != nil {
+		return err
+	}
+
+	// Check the whitelist before touching anything
+	if fields, ok := doc.(map[string]interface{}); ok {
+		schema := inputFields(reflect.TypeOf(out))
+		canonical := make(map[string]interface{}, len(fields))
+		for _, key := range objectKeys(fields) {
+			field, docKey, ignored, err := s.patchField(schema, fields, key)
+			if err != nil {
+				return &PatchError{Op: "merge", Path: "/" + escapePointerToken(key), Message: err.Error()}
+			}
+			if !s.patchable(field) {
+				return &PatchError{Op: "merge", Path: "/" + escapePointerToken(key), Message: "field cannot be patched"}
+			}
+			if !ignored {
+				canonical[docKey] = fields[key]
+			}
+		}
+		doc = canonical
+	} else if !s.pointerPatchable(nil, "") {
+		return &PatchError{Op: "merge", Path: "", Message: "document cannot be replaced"}
+	}
+
+	merged, ok := mergePatch(current, doc).(map[string]interface{})
+	if !ok {
+		return &PatchError{Op: "merge", Path: "", Message: "patch must produce an object"}
+	}
+	return s.applyPatched(ctx, merged, out)
+}
+
+// CreateMergePatch generates a JSON Merge Patch (RFC 7386) document that turns original into modified.
+func (s *BaseSerializer) CreateMergePatch(original, modified interface{}) ([]byte, error) {
+	from, to, err := s.patchSources(context.Background(), original, modified)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := json.Marshal(diffMergePatch(from, to))
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode merge patch: %v", err), Err: err}
+	}
+	return patch, nil
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) document to the struct pointed to by out. Like
+// ApplyMergePatch, its pointers address the keys Serialize writes.
+func (s *BaseSerializer) ApplyJSONPatch(patch []byte, out interface{}) error {
+	var operations []PatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return &SerializationError{Message: fmt.Sprintf("failed to parse JSON patch: %v", err), Err: err}
+	}
+
+	ctx := context.Background()
+	current, err := s.patchDocument(ctx, out)
+	if err != nil {
+		return err
+	}
+
+	schema := inputFields(reflect.TypeOf(out))
+	var doc interface{} = current
+	for _, op := range operations {
+		if doc, err = s.applyOperation(doc, op, schema); err != nil {
+			return err
+		}
+	}
+
+	patched, ok := doc.(map[string]interface{})
+	if !ok {
+		return &PatchError{Op: "apply", Path: "", Message: "patch must produce an object"}
+	}
+	return s.applyPatched(ctx, patched, out)
+}
+
+// CreateJSONPatch generates a JSON Patch (RFC 6902) document that turns original into modified.
+func (s *BaseSerializer) CreateJSONPatch(original, modified interface{}) ([]byte, error) {
+	from, to, err := s.patchSources(context.Background(), original, modified)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := diffJSONPatch("", from, to, []PatchOperation{})
+	patch, err := json.Marshal(operations)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode JSON patch: %v", err), Err: err}
+	}
+	return patch, nil
+}
+
+// patchable reports whether a top-level field may be modified by a patch.
+func (s *BaseSerializer) patchable(field string) bool {
+	if containsField(s.ReadOnlyFields, field) {
+		return false
+	}
+	return len(s.Fields) == 0 || containsField(s.Fields, field)
+}
+
+// patchField returns the name of the field of schema that a top-level patch key addresses, and the
+// key of the field in the document built by patchDocument. Keys are those Serialize writes, after
+// Aliases and KeyNaming, or the former keys of RenamedFields, and are matched case-insensitively, as
+// the decoder matches input keys, so that changing the case of a key doesn't get around the
+// whitelist or the read-only fields. Like fieldKey, it reports a key as ignored when keys also has
+// the exact key of its field. It fails for keys that match no field.
+func (s *BaseSerializer) patchField(schema *structSchema, keys map[string]interface{}, key string) (field, docKey string, ignored bool, err error) {
+	if schema == nil {
+		return key, key, false, nil
+	}
+	found := schema.lookup(s.patchInputKey(schema, key))
+	if found == nil {
+		return "", "", false, fmt.Errorf("unknown field")
+	}
+	docKey = s.outputKey(found.name)
+	if key != docKey {
+		_, ignored = keys[docKey]
+	}
+	return found.name, docKey, ignored, nil
+}
+
+// patchInputKey returns the JSON key of the field of schema that a top-level key written by
+// Serialize stands for, reversing RenamedFields, Aliases and KeyNaming like Deserialize does, or
+// key itself when it stands for none.
+func (s *BaseSerializer) patchInputKey(schema *structSchema, key string) string {
+	if former, ok := s.deprecatedKey(s.RenamedFields, key); ok {
+		key = s.convertKey(s.RenamedFields[former])
+	}
+	for field, alias := range s.Aliases {
+		if s.convertKey(alias) == key {
+			return field
+		}
+	}
+	if s.KeyNaming != NamingDefault {
+		for i := range schema.fields {
+			if s.convertKey(schema.fields[i].name) == key {
+				return schema.fields[i].name
+			}
+		}
+	}
+	return key
+}
+
+// patchDocument returns v, the struct being patched, as the document patches address: its fields
+// under the keys Serialize writes them as, after Aliases and KeyNaming, with the values Deserialize
+// reads, see patchValues, and encrypted EncryptedFields.
+func (s *BaseSerializer) patchDocument(ctx context.Context, v interface{}) (map[string]interface{}, error) {
+	doc, err := s.patchValues(ctx, v)
+	if err != nil || doc == nil {
+		return doc, err
+	}
+	if len(s.EncryptedFields) > 0 {
+		if err := s.encryptFields(ctx, doc); err != nil {
+			return nil, err
+		}
+	}
+	return s.namePatchDocument(doc, reflect.TypeOf(v)), nil
+}
+
+// patchValues converts v into a map of its fields by their JSON keys, with the values Deserialize
+// reads: the labels of Enums, the representations of Schema fields and the formats of TimeFormat.
+// Transformations, conditional fields and Fields aren't applied, so that every field of v is kept.
+func (s *BaseSerializer) patchValues(ctx context.Context, v interface{}) (map[string]interface{}, error) {
+	values, err := structToMap(v, s.encodeOptions(ctx))
+	if err != nil || values == nil {
+		return values, err
+	}
+	if len(s.Enums) > 0 {
+		if err := s.labelEnums(values); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.Schema) > 0 {
+		if err := s.representSchema(values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// namePatchDocument renames the fields of a map built by patchValues from a value of type t to the
+// keys Serialize writes them as.
+func (s *BaseSerializer) namePatchDocument(doc map[string]interface{}, t reflect.Type) map[string]interface{} {
+	if len(s.Aliases) > 0 {
+		s.applyAliases(doc)
+	}
+	if s.KeyNaming != NamingDefault {
+		doc = s.convertKeys(doc, t)
+	}
+	return doc
+}
+
+// patchSources converts both sides of a diff into documents like patchDocument, restricted to the
+// patchable fields. Encrypted fields are only kept, and encrypted, when they differ, so that an
+// unchanged field doesn't show up in the patch with a new ciphertext.
+func (s *BaseSerializer) patchSources(ctx context.Context, original, modified interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	from, err := s.patchValues(ctx, original)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := s.patchValues(ctx, modified)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for field := range from {
+		if !s.patchable(field) {
+			delete(from, field)
+		}
+	}
+	for field := range to {
+		if !s.patchable(field) {
+			delete(to, field)
+		}
+	}
+	if len(s.EncryptedFields) > 0 {
+		for _, field := range s.EncryptedFields {
+			if reflect.DeepEqual(from[field], to[field]) {
+				delete(from, field)
+				delete(to, field)
+			}
+		}
+		if err := s.encryptFields(ctx, from); err != nil {
+			return nil, nil, err
+		}
+		if err := s.encryptFields(ctx, to); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s.namePatchDocument(from, reflect.TypeOf(original)), s.namePatchDocument(to, reflect.TypeOf(modified)), nil
+}
+
+// applyPatched validates the patched document and reads it back into out like Deserialize, so that
+// sanitizers, migrations, coercion, decode hooks and decryption apply. Fields removed by the patch
+// are reset, and out is left unchanged when the document can't be read.
+func (s *BaseSerializer) applyPatched(ctx context.Context, patched map[string]interface{}, out interface{}) error {
+	if err := s.ValidateWithContext(ctx, patched); err != nil {
+		return err
+	}
+
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return s.DeserializeWithContext(ctx, patched, out)
+	}
+
+	// Read-only fields were protected above and keep their current values, which Deserialize
+	// would drop or reject
+	var readOnly map[string]interface{}
+	if len(s.ReadOnlyFields) > 0 {
+		current, err := structToMap(out, nil)
+		if err != nil {
+			return err
+		}
+		readOnly = make(map[string]interface{}, len(s.ReadOnlyFields))
+		for _, field := range s.ReadOnlyFields {
+			delete(patched, s.outputKey(field))
+			if value, exists := current[field]; exists {
+				readOnly[field] = value
+			}
+		}
+	}
+
+	patchedOut := reflect.New(target.Elem().Type())
+	if err := s.DeserializeWithContext(ctx, patched, patchedOut.Interface()); err != nil {
+		return err
+	}
+	if len(readOnly) > 0 {
+		if err := s.decodeMap(readOnly, patchedOut.Interface()); err != nil {
+			return err
+		}
+	}
+	target.Elem().Set(patchedOut.Elem())
+	return nil
+}
+
+// applyOperation applies a single JSON Patch operation to doc and returns the resulting document.
+// The first token of its pointers is resolved to a field of schema, the struct being patched.
+func (s *BaseSerializer) applyOperation(doc interface{}, op PatchOperation, schema *structSchema) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	var field string
+	if err == nil {
+		field, err = s.pointerField(schema, path)
+	}
+	if err != nil {
+		return nil, &PatchError{Op: op.Op, Path: op.Path, Message: err.Error()}
+	}
+	if op.Op != "test" && !s.pointerPatchable(path, field) {
+		return nil, &PatchError{Op: op.Op, Path: op.Path, Message: "field cannot be patched"}
+	}
+
+	var result interface{}
+	switch op.Op {
+	case "add":
+		result, err = pointerSet(doc, path, op.Value, true)
+	case "remove":
+		result, _, err = pointerRemove(doc, path)
+	case "replace":
+		result, err = pointerSet(doc, path, op.Value, false)
+	case "move", "copy":
+		from, ferr := parsePointer(op.From)
+		var fromField string
+		if ferr == nil {
+			fromField, ferr = s.pointerField(schema, from)
+		}
+		if ferr != nil {
+			return nil, &PatchError{Op: op.Op, Path: op.From, Message: ferr.Error()}
+		}
+		if op.Op == "move" && !s.pointerPatchable(from, fromField) {
+			return nil, &PatchError{Op: op.Op, Path: op.From, Message: "field cannot be patched"}
+		}
+		if op.Op == "move" && isPointerPrefix(from, path) {
+			return nil, &PatchError{Op: op.Op, Path: op.Path, Message: "cannot move a value into one of its children"}
+		}
+
+		var value interface{}
+		if op.Op == "move" {
+			doc, value, err = pointerRemove(doc, from)
+		} else {
+			value, err = pointerGet(doc, from)
+			value = deepCopy(value)
+		}
+		if err == nil {
+			result, err = pointerSet(doc, path, value, true)
+		}
+	case "test":
+		var value interface{}
+		if value, err = pointerGet(doc, path); err == nil && !reflect.DeepEqual(value, op.Value) {
+			err = fmt.Errorf("value does not match")
+		}
+		result = doc
+	default:
+		err = fmt.Errorf("unsupported operation")
+	}
+
+	if err != nil {
+		return nil, &PatchError{Op: op.Op, Path: op.Path, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// pointerPatchable reports whether the top-level field addressed by a pointer may be modified.
+func (s *BaseSerializer) pointerPatchable(tokens []string, field string) bool {
+	if len(tokens) == 0 {
+		return len(s.Fields) == 0 && len(s.ReadOnlyFields) == 0
+	}
+	return s.patchable(field)
+}
+
+// pointerField replaces the first token of a pointer with the key of the field of schema it
+// addresses in the patched document, and returns the name of that field, see patchField.
+func (s *BaseSerializer) pointerField(schema *structSchema, tokens []string) (string, error) {
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	field, docKey, _, err := s.patchField(schema, nil, tokens[0])
+	if err != nil {
+		return "", err
+	}
+	tokens[0] = docKey
+	return field, nil
+}
+
+// isPointerPrefix reports whether the pointer prefix is a proper prefix of the pointer tokens, i.e.
+// whether tokens addresses a child of the location of prefix.
+func isPointerPrefix(prefix, tokens []string) bool {
+	if len(prefix) >= len(tokens) {
+		return false
+	}
+	for i, token := range prefix {
+		if tokens[i] != token {
+			return false
+		}
+	}
+	return true
+}
+
+// mergePatch applies an RFC 7386 merge patch to target.
+func mergePatch(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		targetMap = make(map[string]interface{})
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+		} else {
+			targetMap[key] = mergePatch(targetMap[key], value)
+		}
+	}
+	return targetMap
+}
+
+// diffMergePatch builds the RFC 7386 merge patch that turns from into to.
+func diffMergePatch(from, to map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key := range from {
+		if _, ok := to[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	for key, value := range to {
+		old, ok := from[key]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+
+		oldMap, oldIsMap := old.(map[string]interface{})
+		newMap, newIsMap := value.(map[string]interface{})
+		if ok && oldIsMap && newIsMap {
+			patch[key] = diffMergePatch(oldMap, newMap)
+		} else {
+			patch[key] = value
+		}
+	}
+	return patch
+}
+
+// diffJSONPatch appends the RFC 6902 operations that turn from into to.
+func diffJSONPatch(path string, from, to map[string]interface{}, operations []PatchOperation) []PatchOperation {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := path + "/" + escapePointerToken(key)
+		old, inFrom := from[key]
+		value, inTo := to[key]
+
+		switch {
+		case !inTo:
+			operations = append(operations, PatchOperation{Op: "remove", Path: fieldPath})
+		case !inFrom:
+			operations = append(operations, PatchOperation{Op: "add", Path: fieldPath, Value: value})
+		case !reflect.DeepEqual(old, value):
+			oldMap, oldIsMap := old.(map[string]interface{})
+			newMap, newIsMap := value.(map[string]interface{})
+			if oldIsMap && newIsMap {
+				operations = diffJSONPatch(fieldPath, oldMap, newMap, operations)
+			} else {
+				operations = append(operations, PatchOperation{Op: "replace", Path: fieldPath, Value: value})
+			}
+		}
+	}
+	return operations
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// escapePointerToken escapes a single reference token for use in a JSON Pointer.
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// pointerGet returns the value addressed by tokens.
+func pointerGet(doc interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path does not exist")
+			}
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("path does not exist")
+		}
+	}
+	return doc, nil
+}
+
+// pointerSet stores value at the location addressed by tokens. When insert is true, values are
+// added to objects and inserted into arrays; otherwise the location must already exist.
+func pointerSet(doc interface{}, tokens []string, value interface{}, insert bool) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	token := tokens[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(tokens) == 1 {
+			if _, ok := node[token]; !ok && !insert {
+				return nil, fmt.Errorf("path does not exist")
+			}
+			node[token] = value
+			return node, nil
+		}
+
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("path does not exist")
+		}
+		updated, err := pointerSet(child, tokens[1:], value, insert)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+	case []interface{}:
+		if len(tokens) == 1 && insert {
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+
+		index, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 1 {
+			node[index] = value
+			return node, nil
+		}
+		updated, err := pointerSet(node[index], tokens[1:], value, insert)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("path does not exist")
+	}
+}
+
+// pointerRemove removes the value addressed by tokens and returns the resulting document and the removed value.
+func pointerRemove(doc interface{}, tokens []string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	token := tokens[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path does not exist")
+		}
+		if len(tokens) == 1 {
+			delete(node, token)
+			return node, child, nil
+		}
+
+		updated, removed, err := pointerRemove(child, tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = updated
+		return node, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(tokens) == 1 {
+			removed := node[index]
+			return append(node[:index], node[index+1:]...), removed, nil
+		}
+
+		updated, removed, err := pointerRemove(node[index], tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[index] = updated
+		return node, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("path does not exist")
+	}
+}
+
+// arrayIndex parses an array reference token. When appending, "-" and len are accepted as the end of the array.
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if appending && token == "-" {
+		return length, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index > length || (!appending && index == length) {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+// deepCopy returns a copy of a decoded JSON value that shares no maps or slices with the original.
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,280 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  account // Zero when the patch is rejected
+		path  string  // Path of the expected PatchError
+	}{
+		{"writable field", `{"name":"b"}`, account{ID: 1, Name: "b", Role: "user"}, ""},
+		{"writable field in another case", `{"NAME":"b"}`, account{ID: 1, Name: "b", Role: "user"}, ""},
+		{"exact key wins", `{"Name":"c","name":"b"}`, account{ID: 1, Name: "b", Role: "user"}, ""},
+		{"removal", `{"name":null}`, account{ID: 1, Role: "user"}, ""},
+		{"read-only field", `{"role":"admin"}`, account{}, "/role"},
+		{"read-only field in another case", `{"Role":"admin"}`, account{}, "/Role"},
+		{"read-only field among others", `{"name":"b","ID":99}`, account{}, "/ID"},
+		{"unknown field", `{"admin":true}`, account{}, "/admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{ReadOnlyFields: []string{"id", "role"}}
+			got := account{ID: 1, Name: "a", Role: "user"}
+			err := s.ApplyMergePatch([]byte(tt.patch), &got)
+			if tt.want == (account{}) {
+				var patchErr *PatchError
+				if !errors.As(err, &patchErr) || patchErr.Path != tt.path {
+					t.Fatalf("ApplyMergePatch() error = %v, want patch error at %q", err, tt.path)
+				}
+				if got != (account{ID: 1, Name: "a", Role: "user"}) {
+					t.Errorf("ApplyMergePatch() modified the target on failure: %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyMergePatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyMergePatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  account // Zero when the patch is rejected
+		path  string  // Path of the expected PatchError
+	}{
+		{"replace", `[{"op":"replace","path":"/name","value":"b"}]`, account{ID: 1, Name: "b", Role: "user"}, ""},
+		{"replace in another case", `[{"op":"replace","path":"/Name","value":"b"}]`, account{ID: 1, Name: "b", Role: "user"}, ""},
+		{"test and remove", `[{"op":"test","path":"/NAME","value":"a"},{"op":"remove","path":"/name"}]`, account{ID: 1, Role: "user"}, ""},
+		{"read-only field", `[{"op":"replace","path":"/role","value":"admin"}]`, account{}, "/role"},
+		{"read-only field in another case", `[{"op":"add","path":"/Role","value":"admin"}]`, account{}, "/Role"},
+		{"move from read-only field", `[{"op":"move","from":"/ID","path":"/name"}]`, account{}, "/ID"},
+		{"copy to read-only field", `[{"op":"copy","from":"/name","path":"/rOlE"}]`, account{}, "/rOlE"},
+		{"unknown field", `[{"op":"add","path":"/admin","value":true}]`, account{}, "/admin"},
+		{"whole document", `[{"op":"replace","path":"","value":{}}]`, account{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{ReadOnlyFields: []string{"id", "role"}}
+			got := account{ID: 1, Name: "a", Role: "user"}
+			err := s.ApplyJSONPatch([]byte(tt.patch), &got)
+			if tt.want == (account{}) {
+				var patchErr *PatchError
+				if !errors.As(err, &patchErr) || patchErr.Path != tt.path {
+					t.Fatalf("ApplyJSONPatch() error = %v, want patch error at %q", err, tt.path)
+				}
+				if got != (account{ID: 1, Name: "a", Role: "user"}) {
+					t.Errorf("ApplyJSONPatch() modified the target on failure: %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyJSONPatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyJSONPatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMergePatchFieldsWhitelist(t *testing.T) {
+	s := &BaseSerializer{Fields: []string{"name"}}
+	for _, patch := range []string{`{"Role":"admin"}`, `{"ROLE":"admin","name":"b"}`} {
+		got := account{ID: 1, Name: "a", Role: "user"}
+		var patchErr *PatchError
+		if err := s.ApplyMergePatch([]byte(patch), &got); !errors.As(err, &patchErr) {
+			t.Errorf("ApplyMergePatch(%s) error = %v, want a patch error", patch, err)
+		}
+	}
+}
+
+type patchAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type patchProfile struct {
+	ID      int          `json:"id"`
+	Name    string       `json:"name"`
+	Address patchAddress `json:"address"`
+	Tags    []string     `json:"tags"`
+}
+
+func TestApplyJSONPatchMove(t *testing.T) {
+	s := &BaseSerializer{}
+	original := patchProfile{Name: "a", Address: patchAddress{City: "Paris"}}
+
+	got := original
+	if err := s.ApplyJSONPatch([]byte(`[{"op":"move","from":"/address/city","path":"/address/street"}]`), &got); err != nil {
+		t.Fatalf("ApplyJSONPatch() error = %v", err)
+	}
+	if want := (patchProfile{Name: "a", Address: patchAddress{Street: "Paris"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyJSONPatch() = %+v, want %+v", got, want)
+	}
+
+	got = original
+	err := s.ApplyJSONPatch([]byte(`[{"op":"move","from":"/address","path":"/address/city"}]`), &got)
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Path != "/address/city" || !strings.Contains(patchErr.Message, "children") {
+		t.Fatalf("ApplyJSONPatch() error = %v, want patch error for moving into a child at %q", err, "/address/city")
+	}
+	if !reflect.DeepEqual(got, original) {
+		t.Errorf("ApplyJSONPatch() modified the target on failure: %+v", got)
+	}
+}
+
+func TestCreatePatchRoundTrip(t *testing.T) {
+	original := patchProfile{ID: 1, Name: "a", Address: patchAddress{City: "Paris", Street: "Rue"}, Tags: []string{"x", "y"}}
+	tests := []struct {
+		name     string
+		modified patchProfile
+		want     patchProfile
+	}{
+		{"unchanged", original, original},
+		{"scalar", patchProfile{ID: 1, Name: "b", Address: original.Address, Tags: original.Tags}, patchProfile{ID: 1, Name: "b", Address: original.Address, Tags: original.Tags}},
+		{"nested field", patchProfile{ID: 1, Name: "a", Address: patchAddress{City: "Lyon", Street: "Rue"}, Tags: original.Tags}, patchProfile{ID: 1, Name: "a", Address: patchAddress{City: "Lyon", Street: "Rue"}, Tags: original.Tags}},
+		{"list and removal", patchProfile{ID: 1, Address: original.Address, Tags: []string{"z"}}, patchProfile{ID: 1, Address: original.Address, Tags: []string{"z"}}},
+		{"read-only field kept", patchProfile{ID: 2, Name: "a", Address: original.Address, Tags: original.Tags}, original},
+	}
+	s := &BaseSerializer{ReadOnlyFields: []string{"id"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergePatch, err := s.CreateMergePatch(original, tt.modified)
+			if err != nil {
+				t.Fatalf("CreateMergePatch() error = %v", err)
+			}
+			got := original
+			got.Tags = append([]string(nil), original.Tags...)
+			if err := s.ApplyMergePatch(mergePatch, &got); err != nil {
+				t.Fatalf("ApplyMergePatch(%s) error = %v", mergePatch, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyMergePatch(%s) = %+v, want %+v", mergePatch, got, tt.want)
+			}
+
+			jsonPatch, err := s.CreateJSONPatch(original, tt.modified)
+			if err != nil {
+				t.Fatalf("CreateJSONPatch() error = %v", err)
+			}
+			got = original
+			got.Tags = append([]string(nil), original.Tags...)
+			if err := s.ApplyJSONPatch(jsonPatch, &got); err != nil {
+				t.Fatalf("ApplyJSONPatch(%s) error = %v", jsonPatch, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyJSONPatch(%s) = %+v, want %+v", jsonPatch, got, tt.want)
+			}
+		})
+	}
+}
+
+type patchUser struct {
+	UserName string `json:"user_name"`
+	Email    string `json:"email"`
+	Nick     string `json:"nick"`
+}
+
+func TestPatchOutputNames(t *testing.T) {
+	s := &BaseSerializer{KeyNaming: NamingCamelCase, Aliases: map[string]string{"email": "mail"}, RenamedFields: map[string]string{"nickname": "nick"}}
+	original := patchUser{UserName: "a", Email: "a@example.com", Nick: "x"}
+	tests := []struct {
+		name  string
+		merge string
+		json  string
+		want  patchUser
+	}{
+		{"converted key", `{"userName":"b"}`, `[{"op":"replace","path":"/userName","value":"b"}]`, patchUser{UserName: "b", Email: "a@example.com", Nick: "x"}},
+		{"alias", `{"mail":"b@example.com"}`, `[{"op":"replace","path":"/mail","value":"b@example.com"}]`, patchUser{UserName: "a", Email: "b@example.com", Nick: "x"}},
+		{"former key", `{"nickname":"y"}`, `[{"op":"replace","path":"/nickname","value":"y"}]`, patchUser{UserName: "a", Email: "a@example.com", Nick: "y"}},
+		{"removal", `{"userName":null}`, `[{"op":"remove","path":"/userName"}]`, patchUser{Email: "a@example.com", Nick: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := original
+			if err := s.ApplyMergePatch([]byte(tt.merge), &got); err != nil {
+				t.Fatalf("ApplyMergePatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyMergePatch() = %+v, want %+v", got, tt.want)
+			}
+			got = original
+			if err := s.ApplyJSONPatch([]byte(tt.json), &got); err != nil {
+				t.Fatalf("ApplyJSONPatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyJSONPatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	modified := patchUser{UserName: "b", Email: "b@example.com", Nick: "x"}
+	mergePatch, err := s.CreateMergePatch(original, modified)
+	if err != nil || string(mergePatch) != `{"mail":"b@example.com","userName":"b"}` {
+		t.Errorf("CreateMergePatch() = %s, %v, want the keys written by Serialize", mergePatch, err)
+	}
+	jsonPatch, err := s.CreateJSONPatch(original, modified)
+	if err != nil || string(jsonPatch) != `[{"op":"replace","path":"/mail","value":"b@example.com"},{"op":"replace","path":"/userName","value":"b"}]` {
+		t.Errorf("CreateJSONPatch() = %s, %v, want the keys written by Serialize", jsonPatch, err)
+	}
+}
+
+func TestPatchDeserializePipeline(t *testing.T) {
+	type record struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+		SSN   string `json:"ssn"`
+	}
+	s := &BaseSerializer{
+		Sanitizers:      map[string][]func(string) string{"name": {strings.TrimSpace}},
+		Coerce:          true,
+		EncryptedFields: []string{"ssn"},
+		KeyProvider:     NewStaticKey(bytes.Repeat([]byte("k"), 32)),
+	}
+	original := record{Name: "a", Count: 1, SSN: "123"}
+
+	got := original
+	if err := s.ApplyMergePatch([]byte(`{"name":"  b ","count":"7"}`), &got); err != nil {
+		t.Fatalf("ApplyMergePatch() error = %v", err)
+	}
+	if want := (record{Name: "b", Count: 7, SSN: "123"}); got != want {
+		t.Errorf("ApplyMergePatch() = %+v, want %+v", got, want)
+	}
+
+	got = original
+	if err := s.ApplyJSONPatch([]byte(`[{"op":"replace","path":"/ssn","value":"456"}]`), &got); ErrorCode(err) != CodeNotEncrypted {
+		t.Errorf("ApplyJSONPatch() error = %v, want %s", err, CodeNotEncrypted)
+	}
+	if got != original {
+		t.Errorf("ApplyJSONPatch() modified the target on failure: %+v", got)
+	}
+
+	modified := record{Name: "a", Count: 1, SSN: "456"}
+	patch, err := s.CreateMergePatch(original, modified)
+	if err != nil {
+		t.Fatalf("CreateMergePatch() error = %v", err)
+	}
+	if strings.Contains(string(patch), "456") {
+		t.Errorf("CreateMergePatch() = %s, want the changed field encrypted", patch)
+	}
+	got = original
+	if err := s.ApplyMergePatch(patch, &got); err != nil || got != modified {
+		t.Errorf("ApplyMergePatch(%s) = %+v, %v, want %+v", patch, got, err, modified)
+	}
+	if patch, err := s.CreateMergePatch(original, original); err != nil || string(patch) != `{}` {
+		t.Errorf("CreateMergePatch() of equal values = %s, %v, want {}", patch, err)
+	}
+}
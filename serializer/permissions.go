@@ -0,0 +1,31 @@
+package serializer
+
+import "context"
+
+// rolesKey is the context key under which the caller's roles are stored.
+type rolesKey struct{}
+
+// WithRoles returns a copy of ctx carrying the roles of the caller, used to evaluate FieldPermissions.
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// RolesFromContext returns the roles carried by ctx.
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return roles
+}
+
+// permitted reports whether the roles in ctx allow field to be serialized.
+func (s *BaseSerializer) permitted(ctx context.Context, field string) bool {
+	allowed, restricted := s.FieldPermissions[field]
+	if !restricted {
+		return true
+	}
+	for _, role := range RolesFromContext(ctx) {
+		if containsField(allowed, role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFieldPermissions(t *testing.T) {
+	s := &BaseSerializer{FieldPermissions: map[string][]string{"role": {"admin", "auditor"}, "name": {}}}
+	tests := []struct {
+		name  string
+		roles []string
+		want  map[string]interface{}
+	}{
+		{"no roles", nil, map[string]interface{}{"id": 7.0}},
+		{"other role", []string{"user"}, map[string]interface{}{"id": 7.0}},
+		{"allowed role", []string{"user", "auditor"}, map[string]interface{}{"id": 7.0, "role": "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.roles != nil {
+				ctx = WithRoles(ctx, tt.roles...)
+			}
+			if got := RolesFromContext(ctx); !reflect.DeepEqual(got, tt.roles) {
+				t.Errorf("RolesFromContext() = %q, want %q", got, tt.roles)
+			}
+			got, err := s.SerializeWithContext(ctx, account{ID: 7, Name: "ana", Role: "admin"})
+			if err != nil {
+				t.Fatalf("SerializeWithContext() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeWithContext() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
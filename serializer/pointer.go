@@ -0,0 +1,120 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JSONPointer returns the RFC 6901 JSON Pointer of a location given by its tokens, e.g.
+// "/items/2/price" for JSONPointer("items", "2", "price"). "~" and "/" within tokens are escaped.
+func JSONPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escapePointerToken(token))
+	}
+	return b.String()
+}
+
+// ResolvePointer returns the value at pointer within a serialized map, such as the output of
+// Serialize or the input of Validate, e.g. the price of the third item for "/items/2/price".
+// The empty pointer addresses data itself.
+func ResolvePointer(data map[string]interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("invalid JSON pointer '%s'", pointer), Err: err}
+	}
+	value, err := pointerGet(data, tokens)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("can't resolve '%s': %v", pointer, err), Err: err}
+	}
+	return value, nil
+}
+
+// ErrorPointers returns the JSON Pointers of the locations of the failures in err: one for a
+// *ValidationError or *TransformationError, one per field for a *ConstraintError, and those of
+// every failure of a *ValidationErrors. Failures of the items of a Bulk call are prefixed with the
+// item's index, e.g. "/3/email". It returns nil when err has no location.
+func ErrorPointers(err error) []string {
+	var validationErrs *ValidationErrors
+	var validationErr *ValidationError
+	var constraintErr *ConstraintError
+	var transformationErr *TransformationError
+	var pointers []string
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, failure := range validationErrs.Errors {
+			pointers = append(pointers, ErrorPointers(failure)...)
+		}
+	case errors.As(err, &validationErr):
+		pointers = []string{validationErr.Pointer()}
+	case errors.As(err, &constraintErr):
+		pointers = constraintErr.Pointers()
+	case errors.As(err, &transformationErr):
+		pointers = []string{JSONPointer(transformationErr.Field)}
+	}
+	for i, pointer := range pointers {
+		pointers[i] = problemPointer(err, pointer)
+	}
+	return pointers
+}
+
+// Pointer returns the JSON Pointer of the failed field, e.g. "/items/2/price".
+func (e *ValidationError) Pointer() string {
+	return JSONPointer(e.pathTokens()...)
+}
+
+// Pointers returns the JSON Pointers of the fields of the constraint, e.g. "/email" and "/phone".
+func (e *ConstraintError) Pointers() []string {
+	pointers := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		pointers[i] = JSONPointer(splitFieldPath(field)...)
+	}
+	return pointers
+}
+
+// splitFieldPath splits a field path such as "items[2].price" into its tokens, "items", "2" and
+// "price". Keys that contain dots or brackets can't be told apart from paths, which is why
+// ValidationError keeps its Path as tokens.
+func splitFieldPath(field string) []string {
+	var tokens []string
+	start := 0
+	for i := 0; i < len(field); i++ {
+		switch field[i] {
+		case '.':
+			if i > start {
+				tokens = append(tokens, field[start:i])
+			}
+			start = i + 1
+		case '[':
+			end := strings.IndexByte(field[i:], ']')
+			if end < 0 || !isIndex(field[i+1:i+end]) {
+				continue
+			}
+			if i > start {
+				tokens = append(tokens, field[start:i])
+			}
+			tokens = append(tokens, field[i+1:i+end])
+			i += end
+			start = i + 1
+		}
+	}
+	if start < len(field) || len(tokens) == 0 {
+		tokens = append(tokens, field[start:])
+	}
+	return tokens
+}
+
+// isIndex reports whether s is a non-empty string of digits.
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestJSONPointer(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   string
+	}{
+		{"root", nil, ""},
+		{"path", []string{"items", "2", "price"}, "/items/2/price"},
+		{"escaped", []string{"a/b", "m~n"}, "/a~1b/m~0n"},
+		{"empty token", []string{""}, "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JSONPointer(tt.tokens...); got != tt.want {
+				t.Errorf("JSONPointer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePointer(t *testing.T) {
+	data := map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"price": 1.5}},
+		"a/b":   "slash",
+		"m~n":   "tilde",
+	}
+	tests := []struct {
+		name    string
+		pointer string
+		want    interface{}
+		wantErr bool
+	}{
+		{"whole document", "", data, false},
+		{"nested", "/items/0/price", 1.5, false},
+		{"escaped slash", "/a~1b", "slash", false},
+		{"escaped tilde", "/m~0n", "tilde", false},
+		{"no leading slash", "items", nil, true},
+		{"missing key", "/missing", nil, true},
+		{"index out of range", "/items/1", nil, true},
+		{"index into scalar", "/a~1b/0", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePointer(data, tt.pointer)
+			if tt.wantErr {
+				if _, ok := err.(*SerializationError); !ok {
+					t.Errorf("ResolvePointer() error = %v, want a SerializationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolvePointer() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolvePointer() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFieldPath(t *testing.T) {
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{"email", []string{"email"}},
+		{"items[2].price", []string{"items", "2", "price"}},
+		{"matrix[0][1]", []string{"matrix", "0", "1"}},
+		{"a.b.c", []string{"a", "b", "c"}},
+		{"tags[x]", []string{"tags[x]"}},
+		{"open[1", []string{"open[1"}},
+		{"", []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := splitFieldPath(tt.field); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitFieldPath(%q) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorPointers(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"validation error", &ValidationError{Field: "items[2].price"}, []string{"/items/2/price"}},
+		{"validation error path", &ValidationError{Field: "a.b", Path: []string{"a.b"}}, []string{"/a.b"}},
+		{"constraint error", &ConstraintError{Fields: []string{"email", "contact.phone"}}, []string{"/email", "/contact/phone"}},
+		{"transformation error", &TransformationError{Field: "total"}, []string{"/total"}},
+		{"validation errors", &ValidationErrors{Errors: []error{&ValidationError{Field: "a"}, &ConstraintError{Fields: []string{"b"}}}}, []string{"/a", "/b"}},
+		{"bulk error", &BulkError{Index: 3, Err: &ValidationError{Field: "email"}}, []string{"/3/email"}},
+		{"bulk validation errors", &BulkError{Index: 1, Err: &ValidationErrors{Errors: []error{&ValidationError{Field: "a"}, &ValidationError{Field: "b"}}}}, []string{"/1/a", "/1/b"}},
+		{"wrapped", fmt.Errorf("saving: %w", &ValidationError{Field: "name"}), []string{"/name"}},
+		{"no location", fmt.Errorf("boom"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorPointers(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ErrorPointers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// DefaultDiscriminatorKey is the discriminator key used when a PolymorphicType doesn't define one.
+const DefaultDiscriminatorKey = "type"
+
+// PolymorphicType describes an interface type whose values are serialized with a discriminator
+// key naming their concrete type, so interface-typed fields and heterogeneous lists round-trip
+// through Serialize and Deserialize.
+type PolymorphicType struct {
+	iface reflect.Type
+	key   string
+	types map[string]reflect.Type
+	names map[reflect.Type]string
+}
+
+// NewPolymorphicType creates a PolymorphicType for the interface type iface points to, e.g.
+// (*PaymentMethod)(nil). The discriminator is written under key, or DefaultDiscriminatorKey when
+// key is empty.
+func NewPolymorphicType(iface interface{}, key string) *PolymorphicType {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("serializer: NewPolymorphicType needs a pointer to an interface, got %T", iface))
+	}
+	if key == "" {
+		key = DefaultDiscriminatorKey
+	}
+	return &PolymorphicType{
+		iface: t.Elem(),
+		key:   key,
+		types: make(map[string]reflect.Type),
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// Register maps the discriminator value name to the type of sample. Registering a pointer
+// (e.g. &CreditCard{}) makes Deserialize store pointers in the interface, a value stores values.
+func (p *PolymorphicType) Register(name string, sample interface{}) *PolymorphicType {
+	t := reflect.TypeOf(sample)
+	if !t.Implements(p.iface) {
+		panic(fmt.Sprintf("serializer: %s doesn't implement %s", t, p.iface))
+	}
+	p.types[name] = t
+	p.names[baseType(t)] = name
+	return p
+}
+
+// Key returns the discriminator key.
+func (p *PolymorphicType) Key() string {
+	return p.key
+}
+
+// baseType dereferences pointer types.
+func baseType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// polymorphicFor returns the PolymorphicType registered for the interface type t, if any.
+func polymorphicFor(types []*PolymorphicType, t reflect.Type) *PolymorphicType {
+	for _, p := range types {
+		if p.iface == t {
+			return p
+		}
+	}
+	return nil
+}
+
+// addDiscriminator adds the discriminator of the value held by the interface v to its encoded form.
+func addDiscriminator(p *PolymorphicType, v reflect.Value, encoded interface{}) interface{} {
+	object, ok := encoded.(map[string]interface{})
+	if !ok {
+		return encoded
+	}
+	if name, ok := p.names[baseType(v.Elem().Type())]; ok {
+		object[p.key] = name
+	}
+	return encoded
+}
+
+// decodePolymorphic deserializes input into out like decodeMap, building the concrete values of
+// interface-typed fields from their discriminators. Concrete types with a serializer in the
+// registry are deserialized by it.
+func (s *BaseSerializer) decodePolymorphic(input map[string]interface{}, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return s.decodeMap(input, out)
+	}
+	return s.decodeInto(input, v.Elem(), "")
+}
+
+// decodeInto decodes the plain value into target, which must be settable.
+func (s *BaseSerializer) decodeInto(value interface{}, target reflect.Value, path string) error {
+	t := target.Type()
+	if !s.hasPolymorphic(t, make(map[reflect.Type]bool)) {
+		return s.decoder().decodeAt(value, target, path)
+	}
+
+	if value == nil {
+		target.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		p := polymorphicFor(s.PolymorphicTypes, t)
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return &ValidationError{Field: path, Value: value, Message: "value is not an object", Code: CodeNotObject}
+		}
+		name, _ := object[p.key].(string)
+		concrete, ok := p.types[name]
+		if !ok {
+			return &ValidationError{Field: joinKeyPath(path, p.key), Value: object[p.key], Message: "unknown type", Code: CodeInvalidType}
+		}
+		created := reflect.New(baseType(concrete))
+		if registered, ok := s.registry().lookupType(baseType(concrete)); ok {
+			fields := make(map[string]interface{}, len(object))
+			for key, item := range object {
+				if key != p.key {
+					fields[key] = item
+				}
+			}
+			if err := registered.Deserialize(fields, created.Interface()); err != nil {
+				return err
+			}
+		} else {
+			if s.KeyNaming != NamingDefault {
+				object = s.restoreKeys(object, concrete).(map[string]interface{})
+			}
+			if err := s.decodeInto(object, created.Elem(), path); err != nil {
+				return err
+			}
+		}
+		if concrete.Kind() == reflect.Ptr {
+			target.Set(created)
+		} else {
+			target.Set(created.Elem())
+		}
+	case reflect.Ptr:
+		created := reflect.New(t.Elem())
+		if err := s.decodeInto(value, created.Elem(), path); err != nil {
+			return err
+		}
+		target.Set(created)
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return &ValidationError{Field: path, Value: value, Message: "value is not a list", Code: CodeInvalidType}
+		}
+		if t.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(t, len(items), len(items)))
+		}
+		for i, item := range items {
+			if i >= target.Len() {
+				break // Extra elements are ignored, as encoding/json does for arrays
+			}
+			if err := s.decodeInto(item, target.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return &ValidationError{Field: path, Value: value, Message: "value is not an object", Code: CodeNotObject}
+		}
+		result := reflect.MakeMapWithSize(t, len(object))
+		for key, item := range object {
+			element := reflect.New(t.Elem()).Elem()
+			if err := s.decodeInto(item, element, joinKeyPath(path, key)); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), element)
+		}
+		target.Set(result)
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return &ValidationError{Field: path, Value: value, Message: "value is not an object", Code: CodeNotObject}
+		}
+
+		// Decode the plain fields with encoding/json, then the polymorphic ones by hand
+		plain := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			plain[key] = item
+		}
+		var polymorphic []schemaField
+		for _, field := range schemaFor(t).fields {
+			if s.hasPolymorphic(field.typ, make(map[reflect.Type]bool)) {
+				polymorphic = append(polymorphic, field)
+				delete(plain, field.name)
+			}
+		}
+		if err := s.decodeMap(plain, target.Addr().Interface()); err != nil {
+			return err
+		}
+		for _, field := range polymorphic {
+			item, exists := object[field.name]
+			if !exists {
+				continue
+			}
+			if err := s.decodeInto(item, fieldByIndexAlloc(target, field.index), joinKeyPath(path, field.name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasPolymorphic reports whether values of type t may contain a registered interface type.
+func (s *BaseSerializer) hasPolymorphic(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return polymorphicFor(s.PolymorphicTypes, t) != nil
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return s.hasPolymorphic(t.Elem(), seen)
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && s.hasPolymorphic(t.Elem(), seen)
+	case reflect.Struct:
+		if t.Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+			return false
+		}
+		for _, field := range schemaFor(t).fields {
+			if s.hasPolymorphic(field.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldByIndexAlloc returns the field of the struct v at index, allocating nil embedded pointers.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
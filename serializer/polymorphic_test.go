@@ -0,0 +1,130 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+type paymentMethod interface{ kind() string }
+
+type creditCard struct {
+	Number string `json:"number"`
+}
+
+func (creditCard) kind() string { return "card" }
+
+type bankTransfer struct {
+	IBAN string `json:"iban"`
+}
+
+func (*bankTransfer) kind() string { return "transfer" }
+
+type checkout struct {
+	Primary  paymentMethod            `json:"primary"`
+	Backups  []paymentMethod          `json:"backups"`
+	ByRegion map[string]paymentMethod `json:"by_region"`
+	Total    float64                  `json:"total"`
+}
+
+func newPaymentMethods() *PolymorphicType {
+	return NewPolymorphicType((*paymentMethod)(nil), "").
+		Register("card", creditCard{}).
+		Register("transfer", &bankTransfer{})
+}
+
+func TestPolymorphicRoundTrip(t *testing.T) {
+	s := &BaseSerializer{PolymorphicTypes: []*PolymorphicType{newPaymentMethods()}}
+	data := checkout{
+		Primary:  creditCard{Number: "4242"},
+		Backups:  []paymentMethod{&bankTransfer{IBAN: "DE89"}, creditCard{Number: "1111"}},
+		ByRegion: map[string]paymentMethod{"eu": &bankTransfer{IBAN: "FR76"}},
+		Total:    9.5,
+	}
+	serialized, err := s.Serialize(data)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if want := map[string]interface{}{"type": "card", "number": "4242"}; !reflect.DeepEqual(serialized["primary"], want) {
+		t.Errorf("Serialize() primary = %v, want %v", serialized["primary"], want)
+	}
+
+	var got checkout
+	if err := s.Deserialize(serialized, &got); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("Deserialize() = %+v, want %+v", got, data)
+	}
+}
+
+func TestDeserializePolymorphic(t *testing.T) {
+	methods := newPaymentMethods()
+	registry := NewRegistry()
+	registry.Register(creditCard{}, &BaseSerializer{Defaults: map[string]interface{}{"number": "0000"}})
+	tests := []struct {
+		name     string
+		s        *BaseSerializer
+		input    map[string]interface{}
+		want     checkout
+		wantCode string
+		wantPath string
+	}{
+		{"nil", &BaseSerializer{PolymorphicTypes: []*PolymorphicType{methods}}, map[string]interface{}{"primary": nil, "total": 1.0}, checkout{Total: 1}, "", ""},
+		{"converted keys", &BaseSerializer{PolymorphicTypes: []*PolymorphicType{methods}, KeyNaming: NamingCamelCase},
+			map[string]interface{}{"byRegion": map[string]interface{}{"eu": map[string]interface{}{"type": "card", "number": "1"}}},
+			checkout{ByRegion: map[string]paymentMethod{"eu": creditCard{Number: "1"}}}, "", ""},
+		{"registered serializer", &BaseSerializer{PolymorphicTypes: []*PolymorphicType{methods}, Registry: registry},
+			map[string]interface{}{"primary": map[string]interface{}{"type": "card"}}, checkout{Primary: creditCard{Number: "0000"}}, "", ""},
+		{"unknown type", &BaseSerializer{PolymorphicTypes: []*PolymorphicType{methods}},
+			map[string]interface{}{"backups": []interface{}{map[string]interface{}{"type": "cash"}}}, checkout{}, CodeInvalidType, "backups[0].type"},
+		{"not an object", &BaseSerializer{PolymorphicTypes: []*PolymorphicType{methods}}, map[string]interface{}{"primary": "card"}, checkout{}, CodeNotObject, "primary"},
+		{"not a list", &BaseSerializer{PolymorphicTypes: []*PolymorphicType{methods}}, map[string]interface{}{"backups": "card"}, checkout{}, CodeInvalidType, "backups"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got checkout
+			err := tt.s.Deserialize(tt.input, &got)
+			if tt.wantCode != "" {
+				if ErrorCode(err) != tt.wantCode || !containsPath(err, tt.wantPath) {
+					t.Errorf("Deserialize() error = %v, want %s at %s", err, tt.wantCode, tt.wantPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Deserialize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// containsPath reports whether err is a ValidationError of the field at path.
+func containsPath(err error, path string) bool {
+	validationErr, ok := err.(*ValidationError)
+	return ok && validationErr.Field == path
+}
+
+func TestNewPolymorphicTypePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"not an interface pointer", func() { NewPolymorphicType(creditCard{}, "") }},
+		{"not an implementation", func() { NewPolymorphicType((*paymentMethod)(nil), "").Register("transfer", bankTransfer{}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+	if key := NewPolymorphicType((*paymentMethod)(nil), "kind").Key(); key != "kind" {
+		t.Errorf("Key() = %q, want kind", key)
+	}
+}
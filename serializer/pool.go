@@ -0,0 +1,24 @@
+package serializer
+
+import "sync"
+
+// Maps larger than this aren't pooled, so a single large document doesn't keep its memory alive.
+const maxPooledMapSize = 64
+
+var mapPool = sync.Pool{New: func() interface{} { return make(map[string]interface{}) }}
+
+// getMap returns an empty map from the pool, for maps that don't outlive a call.
+func getMap() map[string]interface{} {
+	return mapPool.Get().(map[string]interface{})
+}
+
+// putMap empties a map and returns it to the pool. It must no longer be referenced.
+func putMap(m map[string]interface{}) {
+	if len(m) > maxPooledMapSize {
+		return
+	}
+	for key := range m {
+		delete(m, key)
+	}
+	mapPool.Put(m)
+}
@@ -0,0 +1,32 @@
+package serializer
+
+import "testing"
+
+func TestMapPool(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"small", 3},
+		{"largest pooled", maxPooledMapSize},
+		{"too large", maxPooledMapSize + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := getMap()
+			if len(m) != 0 {
+				t.Fatalf("getMap() returned %d entries, want an empty map", len(m))
+			}
+			for i := 0; i < tt.size; i++ {
+				m[string(rune('a'+i%26))+string(rune('0'+i/26))] = i
+			}
+			putMap(m)
+			if tt.size <= maxPooledMapSize && len(m) != 0 {
+				t.Errorf("putMap() left %d entries, want the map emptied", len(m))
+			}
+			if tt.size > maxPooledMapSize && len(m) != tt.size {
+				t.Errorf("putMap() changed a map too large to pool")
+			}
+		})
+	}
+}
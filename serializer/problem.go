@@ -0,0 +1,159 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ProblemContentType is the media type of problem documents.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem document describing a failed request.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemError `json:"errors,omitempty"` // Extension member listing every field error
+}
+
+// ProblemError is a single field error within a Problem.
+type ProblemError struct {
+	Field   string `json:"field,omitempty"`
+	Pointer string `json:"pointer,omitempty"` // JSON Pointer of the field, e.g. "/items/2/price"
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewProblem describes err as a problem document. Validation errors, including every error of a
+// *ValidationSetError and the item errors of a *BulkError, are listed in Errors with their codes.
+func NewProblem(err error) *Problem {
+	problem := &Problem{Type: "about:blank"}
+
+	var validationErrs *ValidationErrors
+	var setErr *ValidationSetError
+	var validationErr *ValidationError
+	var transformationErr *TransformationError
+	var constraintErr *ConstraintError
+	var patchErr *PatchError
+	var fetchErr *FetchError
+	var serializationErr *SerializationError
+	switch {
+	case errors.As(err, &validationErrs):
+		problem.Status = http.StatusUnprocessableEntity
+		for _, failure := range validationErrs.Errors {
+			problem.Errors = append(problem.Errors, failureProblemErrors(err, failure)...)
+		}
+	case errors.As(err, &setErr):
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Errors = setProblemErrors(setErr)
+	case errors.As(err, &validationErr):
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Errors = []ProblemError{{Field: problemField(err, validationErr.Field), Pointer: problemPointer(err, validationErr.Pointer()), Code: validationErr.Code, Message: validationErr.Message}}
+	case errors.As(err, &constraintErr):
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Errors = failureProblemErrors(err, constraintErr)
+	case errors.As(err, &transformationErr):
+		problem.Status = http.StatusInternalServerError
+		problem.Detail = "the response could not be serialized"
+		problem.Errors = []ProblemError{{Field: problemField(err, transformationErr.Field), Pointer: problemPointer(err, JSONPointer(transformationErr.Field)), Code: transformationErr.Code, Message: transformationErr.Message}}
+	case errors.As(err, &patchErr):
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Detail = err.Error()
+	case errors.As(err, &fetchErr):
+		problem.Status = http.StatusBadGateway
+		problem.Detail = err.Error()
+	case errors.As(err, &serializationErr):
+		problem.Status = http.StatusBadRequest
+		problem.Detail = err.Error()
+	default:
+		problem.Status = http.StatusInternalServerError
+		if err != nil {
+			problem.Detail = err.Error()
+		}
+	}
+
+	problem.Title = http.StatusText(problem.Status)
+	if problem.Detail == "" && len(problem.Errors) > 0 {
+		problem.Detail = fmt.Sprintf("%d field(s) failed validation", len(problem.Errors))
+	}
+	return problem
+}
+
+// MarshalProblem renders err as a JSON problem document (see NewProblem).
+func MarshalProblem(err error) []byte {
+	encoded, _ := json.Marshal(NewProblem(err)) // A Problem only holds strings and ints
+	return encoded
+}
+
+// setProblemErrors lists the errors of a ValidationSetError sorted by object name,
+// prefixing fields with the object name.
+func setProblemErrors(setErr *ValidationSetError) []ProblemError {
+	names := make([]string, 0, len(setErr.Errors))
+	for name := range setErr.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problemErrors []ProblemError
+	for _, name := range names {
+		for _, err := range setErr.Errors[name] {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) && validationErr.Field != name {
+				problemErrors = append(problemErrors, ProblemError{Field: name + "." + validationErr.Field, Pointer: JSONPointer(splitFieldPath(name)...) + validationErr.Pointer(), Code: validationErr.Code, Message: validationErr.Message})
+				continue
+			}
+			code := ErrorCode(err)
+			if code == "" {
+				code = CodeInvalid
+			}
+			message := err.Error()
+			if validationErr != nil {
+				message = validationErr.Message
+			}
+			problemErrors = append(problemErrors, ProblemError{Field: name, Pointer: JSONPointer(splitFieldPath(name)...), Code: code, Message: message})
+		}
+	}
+	return problemErrors
+}
+
+// failureProblemErrors describes a failure listed in a ValidationErrors.
+func failureProblemErrors(err, failure error) []ProblemError {
+	var validationErr *ValidationError
+	var constraintErr *ConstraintError
+	switch {
+	case errors.As(failure, &validationErr):
+		return []ProblemError{{Field: problemField(err, validationErr.Field), Pointer: problemPointer(err, validationErr.Pointer()), Code: validationErr.Code, Message: validationErr.Message}}
+	case errors.As(failure, &constraintErr):
+		problemErrors := make([]ProblemError, len(constraintErr.Fields))
+		pointers := constraintErr.Pointers()
+		for i, field := range constraintErr.Fields {
+			problemErrors[i] = ProblemError{Field: problemField(err, field), Pointer: problemPointer(err, pointers[i]), Code: constraintErr.Code, Message: constraintErr.Message}
+		}
+		return problemErrors
+	default:
+		return []ProblemError{{Code: validationCode(failure), Message: failure.Error()}}
+	}
+}
+
+// problemPointer prefixes pointer with the index of the failed item when err is a *BulkError.
+func problemPointer(err error, pointer string) string {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		return fmt.Sprintf("/%d%s", bulkErr.Index, pointer)
+	}
+	return pointer
+}
+
+// problemField prefixes field with the index of the failed item when err is a *BulkError.
+func problemField(err error, field string) string {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		return fmt.Sprintf("[%d].%s", bulkErr.Index, field)
+	}
+	return field
+}
@@ -0,0 +1,78 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+		wantErrors []ProblemError
+	}{
+		{"validation error", &ValidationError{Field: "items[2].price", Message: "value must be positive", Code: CodeNotPositive}, http.StatusUnprocessableEntity,
+			"1 field(s) failed validation", []ProblemError{{Field: "items[2].price", Pointer: "/items/2/price", Code: CodeNotPositive, Message: "value must be positive"}}},
+		{"validation errors", &ValidationErrors{Errors: []error{
+			&ValidationError{Field: "name", Message: "value cannot be empty", Code: CodeEmpty},
+			&ConstraintError{Fields: []string{"email", "phone"}, Message: "exactly one is required", Code: CodeExactlyOne},
+			errors.New("failed"),
+		}}, http.StatusUnprocessableEntity, "4 field(s) failed validation", []ProblemError{
+			{Field: "name", Pointer: "/name", Code: CodeEmpty, Message: "value cannot be empty"},
+			{Field: "email", Pointer: "/email", Code: CodeExactlyOne, Message: "exactly one is required"},
+			{Field: "phone", Pointer: "/phone", Code: CodeExactlyOne, Message: "exactly one is required"},
+			{Code: CodeInvalid, Message: "failed"},
+		}},
+		{"validation set", &ValidationSetError{Errors: map[string][]error{
+			"shipping": {&ValidationError{Field: "city", Message: "value cannot be empty", Code: CodeEmpty}},
+			"billing":  {errors.New("failed"), &ValidationError{Field: "billing", Message: "value is required", Code: CodeRequired}},
+		}}, http.StatusUnprocessableEntity, "3 field(s) failed validation", []ProblemError{
+			{Field: "billing", Pointer: "/billing", Code: CodeInvalid, Message: "failed"},
+			{Field: "billing", Pointer: "/billing", Code: CodeRequired, Message: "value is required"},
+			{Field: "shipping.city", Pointer: "/shipping/city", Code: CodeEmpty, Message: "value cannot be empty"},
+		}},
+		{"bulk item", &BulkError{Index: 3, Err: &ValidationError{Field: "name", Message: "value cannot be empty", Code: CodeEmpty}}, http.StatusUnprocessableEntity,
+			"1 field(s) failed validation", []ProblemError{{Field: "[3].name", Pointer: "/3/name", Code: CodeEmpty, Message: "value cannot be empty"}}},
+		{"constraint", &ConstraintError{Fields: []string{"email"}, Message: "required", Code: CodeAtLeastOne}, http.StatusUnprocessableEntity,
+			"1 field(s) failed validation", []ProblemError{{Field: "email", Pointer: "/email", Code: CodeAtLeastOne, Message: "required"}}},
+		{"transformation", &TransformationError{Field: "total", Message: "transformation returned nil", Code: "transformation_nil"}, http.StatusInternalServerError,
+			"the response could not be serialized", []ProblemError{{Field: "total", Pointer: "/total", Code: "transformation_nil", Message: "transformation returned nil"}}},
+		{"patch", &PatchError{Op: "remove", Path: "/id", Message: "path not found"}, http.StatusUnprocessableEntity, (&PatchError{Op: "remove", Path: "/id", Message: "path not found"}).Error(), nil},
+		{"fetch", &FetchError{URL: "http://example.com", Message: "timeout"}, http.StatusBadGateway, (&FetchError{URL: "http://example.com", Message: "timeout"}).Error(), nil},
+		{"serialization", &SerializationError{Message: "failed to parse JSON"}, http.StatusBadRequest, "Serialization error: failed to parse JSON", nil},
+		{"wrapped", fmt.Errorf("bind: %w", &SerializationError{Message: "failed to parse JSON"}), http.StatusBadRequest, "bind: Serialization error: failed to parse JSON", nil},
+		{"other", errors.New("boom"), http.StatusInternalServerError, "boom", nil},
+		{"nil", nil, http.StatusInternalServerError, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewProblem(tt.err)
+			if got.Type != "about:blank" || got.Status != tt.wantStatus || got.Title != http.StatusText(tt.wantStatus) || got.Detail != tt.wantDetail {
+				t.Errorf("NewProblem() = %+v, want status %d and detail %q", got, tt.wantStatus, tt.wantDetail)
+			}
+			if !reflect.DeepEqual(got.Errors, tt.wantErrors) {
+				t.Errorf("NewProblem() errors = %+v, want %+v", got.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestMarshalProblem(t *testing.T) {
+	var got map[string]interface{}
+	if err := json.Unmarshal(MarshalProblem(&ValidationError{Field: "name", Message: "value cannot be empty", Code: CodeEmpty}), &got); err != nil {
+		t.Fatalf("MarshalProblem() is not JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"type": "about:blank", "title": "Unprocessable Entity", "status": 422.0, "detail": "1 field(s) failed validation",
+		"errors": []interface{}{map[string]interface{}{"field": "name", "pointer": "/name", "code": CodeEmpty, "message": "value cannot be empty"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarshalProblem() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+package serializer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Profile is a set of downgrades applied to the serialized output for a class of clients, so one
+// serializer can serve both current and legacy consumers during a migration.
+// Fields are addressed by their JSON key; nested fields use dotted paths ("author.id"), and paths
+// crossing a list apply to every element.
+type Profile struct {
+	StringifyIntegers []string                                 // Integer fields written as strings (e.g. int64 IDs for JavaScript clients)
+	EnumFallbacks     map[string]EnumFallback                  // Fields whose unknown values are replaced by a fallback
+	Flatten           []string                                 // Nested objects merged into their parent as "<field>_<key>"
+	Rename            map[string]string                        // Fields renamed to the key the client expects
+	Omit              []string                                 // Fields the client doesn't understand
+	Transformations   map[string]func(interface{}) interface{} // Arbitrary per-field rewrites
+}
+
+// EnumFallback replaces values that are not in Values with Fallback.
+type EnumFallback struct {
+	Values   []interface{} // Values known to the client
+	Fallback interface{}   // Value written instead of an unknown one
+}
+
+// profileKey is the context key under which the selected profile is stored.
+type profileKey struct{}
+
+// WithProfile returns a copy of ctx selecting the named entry of Profiles for serialization.
+func WithProfile(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, profileKey{}, name)
+}
+
+// ProfileFromContext returns the profile name carried by ctx, or "" if there is none.
+func ProfileFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(profileKey{}).(string)
+	return name
+}
+
+// applyProfile applies the profile selected in ctx to result. data is the value being serialized,
+// used to stringify integers without the precision loss of their float64 representation.
+func (s *BaseSerializer) applyProfile(ctx context.Context, data interface{}, result map[string]interface{}) error {
+	profile, ok := s.Profiles[ProfileFromContext(ctx)]
+	if !ok {
+		return nil
+	}
+	original := reflect.ValueOf(data)
+
+	for _, field := range profile.Omit {
+		walkPath(result, original, field, func(parent map[string]interface{}, key string, _ reflect.Value) {
+			delete(parent, key)
+		})
+	}
+
+	var err error
+	for field, transform := range profile.Transformations {
+		walkPath(result, original, field, func(parent map[string]interface{}, key string, _ reflect.Value) {
+			value, exists := parent[key]
+			if !exists || err != nil {
+				return
+			}
+			if parent[key] = transform(value); parent[key] == nil {
+				err = &TransformationError{Field: field, Value: value, Message: "transformation returned nil", Code: CodeTransformationNil}
+			}
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	for field, enum := range profile.EnumFallbacks {
+		known := make([]interface{}, 0, len(enum.Values))
+		for _, value := range enum.Values {
+			normalized, err := toJSONValue(reflect.ValueOf(value))
+			if err != nil {
+				return &TransformationError{Field: field, Value: value, Message: "invalid enum value", Code: CodeInvalidEnum}
+			}
+			known = append(known, normalized)
+		}
+		walkPath(result, original, field, func(parent map[string]interface{}, key string, _ reflect.Value) {
+			value, exists := parent[key]
+			if !exists || value == nil {
+				return
+			}
+			for _, candidate := range known {
+				if reflect.DeepEqual(value, candidate) {
+					return
+				}
+			}
+			parent[key] = enum.Fallback
+		})
+	}
+
+	for _, field := range profile.StringifyIntegers {
+		walkPath(result, original, field, func(parent map[string]interface{}, key string, source reflect.Value) {
+			switch number := parent[key].(type) {
+			case float64:
+				parent[key] = formatInteger(number, source)
+			case json.Number:
+				parent[key] = number.String()
+			}
+		})
+	}
+
+	for _, field := range profile.Flatten {
+		walkPath(result, original, field, func(parent map[string]interface{}, key string, _ reflect.Value) {
+			nested, ok := parent[key].(map[string]interface{})
+			if !ok {
+				return
+			}
+			delete(parent, key)
+			for nestedKey, value := range nested {
+				parent[key+"_"+nestedKey] = value
+			}
+		})
+	}
+
+	for field, name := range profile.Rename {
+		walkPath(result, original, field, func(parent map[string]interface{}, key string, _ reflect.Value) {
+			if value, exists := parent[key]; exists {
+				delete(parent, key)
+				parent[name] = value
+			}
+		})
+	}
+	return nil
+}
+
+// walkPath calls fn with the map holding the last segment of a dotted path and that key, along
+// with the corresponding value of the original Go data (invalid when it can't be resolved).
+func walkPath(value interface{}, original reflect.Value, path string, fn func(parent map[string]interface{}, key string, original reflect.Value)) {
+	head, rest, nested := strings.Cut(path, ".")
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child := originalChild(original, head)
+		if !nested {
+			fn(v, head, child)
+			return
+		}
+		if item, ok := v[head]; ok {
+			walkPath(item, child, rest, fn)
+		}
+	case []interface{}:
+		original = indirectValue(original)
+		for i, item := range v {
+			var child reflect.Value
+			if original.IsValid() && (original.Kind() == reflect.Slice || original.Kind() == reflect.Array) && i < original.Len() {
+				child = original.Index(i)
+			}
+			walkPath(item, child, path, fn)
+		}
+	}
+}
+
+// originalChild returns the field or map entry serialized under key, or an invalid value.
+func originalChild(v reflect.Value, key string) reflect.Value {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return reflect.Value{}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := schemaFor(v.Type()).byName[key]
+		if !ok {
+			return reflect.Value{}
+		}
+		child, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			return reflect.Value{}
+		}
+		return child
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		return v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+	default:
+		return reflect.Value{}
+	}
+}
+
+// indirectValue follows pointers and interfaces, returning an invalid value for nil.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// formatInteger formats a serialized number as a decimal string, using the original integer
+// when it is available so values beyond 2^53 keep every digit.
+func formatInteger(number float64, original reflect.Value) string {
+	original = indirectValue(original)
+	if original.IsValid() {
+		switch original.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if float64(original.Int()) == number {
+				return strconv.FormatInt(original.Int(), 10)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if float64(original.Uint()) == number {
+				return strconv.FormatUint(original.Uint(), 10)
+			}
+		}
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
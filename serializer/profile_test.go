@@ -0,0 +1,86 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type ticket struct {
+	ID     int64     `json:"id"`
+	Status string    `json:"status"`
+	Owner  account   `json:"owner"`
+	Watch  []account `json:"watch"`
+}
+
+func TestProfiles(t *testing.T) {
+	data := ticket{ID: 9007199254740993, Status: "triaged", Owner: account{ID: 1, Name: "ana", Role: "admin"}, Watch: []account{{ID: 2, Name: "bo"}}}
+	tests := []struct {
+		name    string
+		profile Profile
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"stringify integers", Profile{StringifyIntegers: []string{"id", "watch.id"}, Omit: []string{"owner", "status", "watch.name", "watch.role"}},
+			map[string]interface{}{"id": "9007199254740993", "watch": []interface{}{map[string]interface{}{"id": "2"}}}, false},
+		{"enum fallback", Profile{EnumFallbacks: map[string]EnumFallback{"status": {Values: []interface{}{"open", "closed"}, Fallback: "open"}}, Omit: []string{"id", "owner", "watch"}},
+			map[string]interface{}{"status": "open"}, false},
+		{"known enum value", Profile{EnumFallbacks: map[string]EnumFallback{"owner.role": {Values: []interface{}{"admin"}, Fallback: "user"}}, Omit: []string{"id", "status", "watch", "owner.id", "owner.name"}},
+			map[string]interface{}{"owner": map[string]interface{}{"role": "admin"}}, false},
+		{"flatten and rename", Profile{Flatten: []string{"owner"}, Rename: map[string]string{"owner_name": "ownerName"}, Omit: []string{"id", "status", "watch", "owner.role"}},
+			map[string]interface{}{"owner_id": 1.0, "ownerName": "ana"}, false},
+		{"transformations", Profile{Transformations: map[string]func(interface{}) interface{}{"watch.name": func(v interface{}) interface{} { return v.(string) + "!" }}, Omit: []string{"id", "status", "owner", "watch.id", "watch.role"}},
+			map[string]interface{}{"watch": []interface{}{map[string]interface{}{"name": "bo!"}}}, false},
+		{"nil transformation", Profile{Transformations: map[string]func(interface{}) interface{}{"status": func(interface{}) interface{} { return nil }}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{Profiles: map[string]Profile{"legacy": tt.profile}}
+			got, err := s.SerializeWithContext(WithProfile(context.Background(), "legacy"), data)
+			if tt.wantErr {
+				if ErrorCode(err) != CodeTransformationNil {
+					t.Errorf("SerializeWithContext() error = %v, want %s", err, CodeTransformationNil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SerializeWithContext() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeWithContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Without a selected profile the output is unchanged
+	s := &BaseSerializer{Profiles: map[string]Profile{"legacy": {Omit: []string{"id"}}}}
+	if got, err := s.Serialize(data); err != nil || got["id"] == nil {
+		t.Errorf("Serialize() = %v, %v, want the id", got, err)
+	}
+	if got := ProfileFromContext(context.Background()); got != "" {
+		t.Errorf("ProfileFromContext() = %q, want empty", got)
+	}
+}
+
+func TestFormatInteger(t *testing.T) {
+	var big uint64 = 1<<64 - 1
+	tests := []struct {
+		name     string
+		number   float64
+		original interface{}
+		want     string
+	}{
+		{"int", 12, 12, "12"},
+		{"uint", float64(big), big, "18446744073709551615"},
+		{"pointer", 7, func() *int64 { n := int64(7); return &n }(), "7"},
+		{"mismatched original", 3, 4, "3"},
+		{"no original", 1e21, nil, "1000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatInteger(tt.number, reflect.ValueOf(tt.original)); got != tt.want {
+				t.Errorf("formatInteger() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
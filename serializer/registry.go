@@ -0,0 +1,128 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Registry maps struct types and names to the serializers that handle them. Serializers resolve
+// nested structs and polymorphic values through their Registry (DefaultRegistry when unset), and
+// httpserializer resolves a nil serializer through DefaultRegistry. A Registry is safe for
+// concurrent use; serializers are usually registered from init functions.
+type Registry struct {
+	mu     sync.RWMutex
+	byType map[reflect.Type]Serializer
+	byName map[string]Serializer
+}
+
+// DefaultRegistry is the registry used by serializers without a Registry and by the package-level
+// registration functions.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byType: make(map[reflect.Type]Serializer),
+		byName: make(map[string]Serializer),
+	}
+}
+
+// Register registers s for the struct type of sample, e.g. User{} or &User{}.
+func (r *Registry) Register(sample interface{}, s Serializer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byType[baseType(reflect.TypeOf(sample))] = s
+}
+
+// RegisterName registers s under name.
+func (r *Registry) RegisterName(name string, s Serializer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byName[name] = s
+}
+
+// Lookup returns the serializer registered for the type of data. Pointers are dereferenced, and
+// slices and arrays resolve to the serializer of their element type.
+func (r *Registry) Lookup(data interface{}) (Serializer, bool) {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return nil, false
+	}
+	t = baseType(t)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = baseType(t.Elem())
+	}
+	return r.lookupType(t)
+}
+
+// LookupName returns the serializer registered under name.
+func (r *Registry) LookupName(name string) (Serializer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.byName[name]
+	return s, ok
+}
+
+// lookupType returns the serializer registered for the struct type t.
+func (r *Registry) lookupType(t reflect.Type) (Serializer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.byType[t]
+	return s, ok
+}
+
+// empty reports whether no serializer is registered for a type.
+func (r *Registry) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.byType) == 0
+}
+
+// RegisterSerializer registers s for the struct type of sample in DefaultRegistry.
+func RegisterSerializer(sample interface{}, s Serializer) {
+	DefaultRegistry.Register(sample, s)
+}
+
+// RegisterSerializerName registers s under name in DefaultRegistry.
+func RegisterSerializerName(name string, s Serializer) {
+	DefaultRegistry.RegisterName(name, s)
+}
+
+// LookupSerializer returns the serializer registered in DefaultRegistry for the type of data.
+func LookupSerializer(data interface{}) (Serializer, bool) {
+	return DefaultRegistry.Lookup(data)
+}
+
+// LookupSerializerName returns the serializer registered in DefaultRegistry under name.
+func LookupSerializerName(name string) (Serializer, bool) {
+	return DefaultRegistry.LookupName(name)
+}
+
+// registry returns the registry the serializer resolves nested serializers from.
+func (s *BaseSerializer) registry() *Registry {
+	if s.Registry != nil {
+		return s.Registry
+	}
+	return DefaultRegistry
+}
+
+// serializeRegistered serializes a nested value with the serializer registered for its type.
+func serializeRegistered(ctx context.Context, s Serializer, data interface{}) (interface{}, error) {
+	// The request's field selection applies to the top-level output, not to the nested serializer
+	ctx = WithSelection(ctx, nil)
+
+	var result map[string]interface{}
+	var err error
+	if cs, ok := s.(interface {
+		SerializeWithContext(ctx context.Context, data interface{}, opts ...Option) (map[string]interface{}, error)
+	}); ok {
+		result, err = cs.SerializeWithContext(ctx, data)
+	} else {
+		result, err = s.Serialize(data)
+	}
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return result, nil
+}
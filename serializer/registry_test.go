@@ -0,0 +1,88 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type team struct {
+	Name    string    `json:"name"`
+	Lead    account   `json:"lead"`
+	Members []account `json:"members"`
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	accounts := &BaseSerializer{Fields: []string{"id"}}
+	teams := &BaseSerializer{}
+	registry.Register(&account{}, accounts)
+	registry.RegisterName("team", teams)
+
+	tests := []struct {
+		name   string
+		data   interface{}
+		want   Serializer
+		wantOk bool
+	}{
+		{"value", account{}, accounts, true},
+		{"pointer", &account{}, accounts, true},
+		{"slice", []account{}, accounts, true},
+		{"array of pointers", [2]*account{}, accounts, true},
+		{"unregistered", team{}, nil, false},
+		{"nil", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := registry.Lookup(tt.data)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Lookup() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+	if got, ok := registry.LookupName("team"); got != teams || !ok {
+		t.Errorf("LookupName() = %v, %v, want the team serializer", got, ok)
+	}
+	if _, ok := registry.LookupName("account"); ok {
+		t.Errorf("LookupName() found a serializer registered by type")
+	}
+}
+
+func TestRegistryNestedSerializers(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(account{}, &BaseSerializer{Fields: []string{"id", "name"}})
+	data := team{
+		Name:    "core",
+		Lead:    account{ID: 1, Name: "ana", Role: "admin"},
+		Members: []account{{ID: 2, Name: "bo", Role: "dev"}},
+	}
+	want := map[string]interface{}{
+		"name":    "core",
+		"lead":    map[string]interface{}{"id": 1.0, "name": "ana"},
+		"members": []interface{}{map[string]interface{}{"id": 2.0, "name": "bo"}},
+	}
+
+	s := &BaseSerializer{Registry: registry}
+	got, err := s.Serialize(data)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize() = %#v, want %#v", got, want)
+	}
+
+	// The selection of the request applies to the top-level output only
+	got, err = s.SerializeWithContext(WithSelection(context.Background(), Selection{"lead": nil}), data)
+	if err != nil {
+		t.Fatalf("SerializeWithContext() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]interface{}{"lead": want["lead"]}) {
+		t.Errorf("SerializeWithContext() = %#v, want the lead serialized by its serializer", got)
+	}
+
+	// The top-level value isn't resolved through the registry
+	got, err = s.Serialize(account{ID: 1, Name: "ana", Role: "admin"})
+	if err != nil || got["role"] != "admin" {
+		t.Errorf("Serialize() of a registered type = %v, %v, want every field", got, err)
+	}
+}
@@ -0,0 +1,152 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultMaxFetchSize is the largest remote document DeserializeFromURL reads when FetchOptions.MaxSize is zero.
+const DefaultMaxFetchSize = 10 << 20
+
+// FetchOptions configures how DeserializeFromURL retrieves remote documents.
+type FetchOptions struct {
+	Client     *http.Client  // HTTP client; http.DefaultClient when nil
+	Header     http.Header   // Extra request headers
+	MaxSize    int64         // Maximum body size in bytes; DefaultMaxFetchSize when zero
+	Retries    int           // Additional attempts after network errors, 429 and 5xx responses
+	RetryDelay time.Duration // Delay before the first retry, doubled on each attempt; 500ms when zero
+}
+
+// DeserializeFromURL fetches a JSON or YAML document, validates it and deserializes it into out
+// with DeserializeWithContext, so hooks, warnings and key providers see ctx. The format is detected
+// from the Content-Type header, then the URL extension, then the body itself.
+func (s *BaseSerializer) DeserializeFromURL(ctx context.Context, url string, out interface{}) error {
+	body, contentType, err := s.fetch(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	var input map[string]interface{}
+	switch detectFormat(contentType, url, body) {
+	case FormatYAML:
+		input, err = s.DecodeYAML(body)
+	default:
+		input, err = s.DecodeJSON(body)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.ValidateWithContext(ctx, input); err != nil {
+		return err
+	}
+	return s.DeserializeWithContext(ctx, input, out)
+}
+
+// fetch retrieves url, retrying transient failures, and returns the body and its content type.
+func (s *BaseSerializer) fetch(ctx context.Context, url string) ([]byte, string, error) {
+	opts := s.Fetch
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFetchSize
+	}
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = 500 * time.Millisecond
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, "", &FetchError{URL: url, Message: ctx.Err().Error()}
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		body, contentType, retry, err := fetchOnce(ctx, client, opts.Header, url, maxSize)
+		if err == nil {
+			return body, contentType, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return nil, "", lastErr
+}
+
+// fetchOnce performs a single request and reports whether a failure is worth retrying.
+func fetchOnce(ctx context.Context, client *http.Client, header http.Header, url string, maxSize int64) ([]byte, string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", false, &FetchError{URL: url, Message: err.Error(), Err: err}
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", ctx.Err() == nil, &FetchError{URL: url, Message: err.Error(), Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, "", retry, &FetchError{URL: url, StatusCode: resp.StatusCode, Message: "unexpected response status"}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", true, &FetchError{URL: url, Message: fmt.Sprintf("failed to read body: %v", err), Err: err}
+	}
+	if int64(len(body)) > maxSize {
+		return nil, "", false, &FetchError{URL: url, Message: fmt.Sprintf("body exceeds %d bytes", maxSize)}
+	}
+	return body, resp.Header.Get("Content-Type"), false, nil
+}
+
+// detectFormat guesses whether a document is JSON or YAML.
+func detectFormat(contentType, url string, body []byte) Format {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			return FormatJSON
+		case strings.Contains(mediaType, "yaml"):
+			return FormatYAML
+		}
+	}
+
+	urlPath := url
+	if i := strings.IndexAny(urlPath, "?#"); i >= 0 {
+		urlPath = urlPath[:i]
+	}
+	switch strings.ToLower(path.Ext(urlPath)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		return FormatJSON
+	}
+	return FormatYAML
+}
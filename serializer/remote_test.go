@@ -0,0 +1,174 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeserializeFromURL(t *testing.T) {
+	var attempts int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/account.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id": 7, "name": "ana"}`))
+	})
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write([]byte("id: 7\nname: ana\n"))
+	})
+	mux.HandleFunc("/account.yml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("id: 7\nname: ana\n"))
+	})
+	mux.HandleFunc("/sniffed", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(` {"id": 7, "name": "ana"}`))
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id": 7, "name": "ana"}`))
+	})
+	mux.HandleFunc("/defaulted", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "ana"}`))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "` + strings.Repeat("a", 100) + `"}`))
+	})
+	mux.HandleFunc("/invalid", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 0, "name": "ana"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	header := http.Header{"Authorization": {"Bearer token"}}
+	tests := []struct {
+		name       string
+		fetch      FetchOptions
+		path       string
+		wantStatus int  // Status of the FetchError
+		wantErr    bool // Another error
+	}{
+		{"JSON extension and headers", FetchOptions{Header: header}, "/account.json?v=1", 0, false},
+		{"YAML content type", FetchOptions{}, "/account", 0, false},
+		{"YAML extension", FetchOptions{}, "/account.yml", 0, false},
+		{"sniffed JSON", FetchOptions{}, "/sniffed", 0, false},
+		{"default", FetchOptions{}, "/defaulted", 0, false},
+		{"retried", FetchOptions{Retries: 2, RetryDelay: time.Millisecond}, "/flaky", 0, false},
+		{"not retried", FetchOptions{Retries: 2, RetryDelay: time.Millisecond}, "/missing", http.StatusNotFound, false},
+		{"unauthorized", FetchOptions{}, "/account.json", http.StatusUnauthorized, false},
+		{"too large", FetchOptions{MaxSize: 50}, "/large", 0, true},
+		{"invalid", FetchOptions{}, "/invalid", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{
+				Fetch:       tt.fetch,
+				Validations: map[string][]func(interface{}) error{"id": {Positive}},
+				Defaults:    map[string]interface{}{"id": 7},
+			}
+			var got account
+			err := s.DeserializeFromURL(context.Background(), server.URL+tt.path, &got)
+			switch {
+			case tt.wantStatus != 0:
+				var fetchErr *FetchError
+				if !errors.As(err, &fetchErr) || fetchErr.StatusCode != tt.wantStatus {
+					t.Errorf("DeserializeFromURL() error = %v, want status %d", err, tt.wantStatus)
+				}
+			case tt.wantErr:
+				if err == nil {
+					t.Errorf("DeserializeFromURL() error = nil, want an error")
+				}
+			case err != nil:
+				t.Errorf("DeserializeFromURL() error = %v", err)
+			case got != account{ID: 7, Name: "ana"}:
+				t.Errorf("DeserializeFromURL() = %+v", got)
+			}
+		})
+	}
+	if attempts != 3 {
+		t.Errorf("flaky endpoint requested %d times, want 3", attempts)
+	}
+}
+
+func TestDeserializeFromURLContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 7, "username": "ana"}`))
+	}))
+	defer server.Close()
+
+	var hookCtx context.Context
+	s := &BaseSerializer{
+		RenamedFields: map[string]string{"username": "name"},
+		Hooks: Hooks{PostDeserialize: func(ctx context.Context, input map[string]interface{}, out interface{}) error {
+			hookCtx = ctx
+			return nil
+		}},
+	}
+	warnings := &Warnings{}
+	ctx := context.WithValue(ContextWithWarnings(context.Background(), warnings), requestIDKey{}, "req-1")
+	var got account
+	if err := s.DeserializeFromURL(ctx, server.URL, &got); err != nil {
+		t.Fatalf("DeserializeFromURL() error = %v", err)
+	}
+	if got != (account{ID: 7, Name: "ana"}) {
+		t.Errorf("DeserializeFromURL() = %+v, want the renamed field", got)
+	}
+	if hookCtx == nil || hookCtx.Value(requestIDKey{}) != "req-1" {
+		t.Error("PostDeserialize didn't receive the caller's context")
+	}
+	if list := warnings.List(); len(list) != 1 || list[0].Field != "username" {
+		t.Errorf("warnings = %v, want the rename of username", list)
+	}
+}
+
+func TestDeserializeFromURLCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s := &BaseSerializer{Fetch: FetchOptions{Retries: 5, RetryDelay: time.Minute}}
+	start := time.Now()
+	err := s.DeserializeFromURL(ctx, server.URL, &account{})
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || time.Since(start) > 10*time.Second {
+		t.Errorf("DeserializeFromURL() error = %v after %v, want a FetchError once canceled", err, time.Since(start))
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		url         string
+		body        string
+		want        Format
+	}{
+		{"JSON content type", "application/json; charset=utf-8", "/a.yaml", "a: 1", FormatJSON},
+		{"JSON suffix", "application/problem+json", "/a", "a: 1", FormatJSON},
+		{"YAML content type", "text/yaml", "/a.json", "{}", FormatYAML},
+		{"extension", "text/plain", "/a.YML?format=json#x", "{}", FormatYAML},
+		{"JSON body", "", "/a", "\n {\"a\": 1}", FormatJSON},
+		{"YAML body", "", "/a", "a: 1", FormatYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectFormat(tt.contentType, tt.url, []byte(tt.body)); got != tt.want {
+				t.Errorf("detectFormat() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package serializer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compiledRules caches the validations of the rule strings in Rules, keyed by the string. Entries
+// compiled before the last RegisterValidator are stale and compiled again.
+var compiledRules sync.Map
+
+// ruleSet is a parsed rule string.
+type ruleSet struct {
+	validations []func(interface{}) error
+	required    bool
+	err         error
+	version     uint64 // validatorsVersion when the rules were compiled
+}
+
+// ParseRules converts validation rules written as a compact string, such as
+// "required|min:3|max:50|email", into validations. Rules are separated by "|" and take their
+// parameter after a ":"; "\|" stands for a "|" within a parameter, e.g. in a pattern.
+//
+// The rules are the validators available to schema and configuration files (see
+// RegisterValidator), plus required. oneOf takes its values separated by commas
+// ("oneOf:admin,user"); the other parameters are passed as the value, as a number when they are
+// numeric. Without required, the validations are Optional: the field may be missing, null or an
+// empty string. With required, it must be present and not null or empty.
+func ParseRules(rules string) ([]func(interface{}) error, error) {
+	set := parseRules(rules)
+	if set.err != nil {
+		return nil, set.err
+	}
+	return set.compile(), nil
+}
+
+// MustParseRules is like ParseRules but panics if the rules are invalid. It simplifies the
+// initialization of validations in variable declarations.
+func MustParseRules(rules string) []func(interface{}) error {
+	validations, err := ParseRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return validations
+}
+
+// rulesValidations returns the validations of a rule string of Rules, parsing it on first use.
+func rulesValidations(rules string) ([]func(interface{}) error, error) {
+	version := currentValidatorsVersion()
+	if cached, ok := compiledRules.Load(rules); ok {
+		if set := cached.(*ruleSet); set.version == version {
+			return set.validations, set.err
+		}
+	}
+	validations, err := ParseRules(rules)
+	compiledRules.Store(rules, &ruleSet{validations: validations, err: err, version: version})
+	return validations, err
+}
+
+// parseRules parses a rule string into the validations of its rules other than required.
+func parseRules(rules string) *ruleSet {
+	set := &ruleSet{}
+	for _, rule := range splitRules(rules) {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, arg, hasArg := strings.Cut(rule, ":")
+		name = strings.TrimSpace(name)
+		if name == "required" && !hasArg {
+			set.required = true
+			continue
+		}
+
+		params := map[string]interface{}{}
+		if hasArg {
+			switch name {
+			case "oneOf":
+				values := make([]interface{}, 0)
+				for _, item := range strings.Split(arg, ",") {
+					values = append(values, ruleValue(strings.TrimSpace(item)))
+				}
+				params["values"] = values
+			case "pattern":
+				params["value"] = arg
+			default:
+				params["value"] = ruleValue(arg)
+			}
+		}
+
+		validatorsMu.RLock()
+		factory, ok := validators[name]
+		validatorsMu.RUnlock()
+		if !ok {
+			set.err = &SerializationError{Message: fmt.Sprintf("invalid validation rule '%s': unknown validator '%s'", rule, name)}
+			return set
+		}
+		validation, err := factory(params)
+		if err != nil {
+			set.err = &SerializationError{Message: fmt.Sprintf("invalid validation rule '%s': %v", rule, err), Err: err}
+			return set
+		}
+		set.validations = append(set.validations, validation)
+	}
+	return set
+}
+
+// compile returns the validations of the rule set, checked first by requiredRule when it is
+// required and made Optional otherwise.
+func (set *ruleSet) compile() []func(interface{}) error {
+	validations := make([]func(interface{}) error, 0, len(set.validations)+1)
+	if set.required {
+		validations = append(validations, requiredRule)
+		return append(validations, set.validations...)
+	}
+	for _, validation := range set.validations {
+		validations = append(validations, Optional(validation))
+	}
+	return validations
+}
+
+// splitRules splits a rule string at the "|" separators that aren't escaped.
+func splitRules(rules string) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(rules); i++ {
+		switch {
+		case rules[i] == '\\' && i+1 < len(rules) && rules[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case rules[i] == '|':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(rules[i])
+		}
+	}
+	return append(parts, current.String())
+}
+
+// ruleValue converts a rule parameter into a number when it is numeric.
+func ruleValue(arg string) interface{} {
+	if n, err := strconv.ParseFloat(arg, 64); err == nil {
+		return n
+	}
+	return arg
+}
+
+// requiredRule rejects null values, empty strings and empty lists.
+func requiredRule(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return NewCodedError(CodeRequired, "field is missing")
+	case string:
+		if v == "" {
+			return NewCodedError(CodeEmpty, "value cannot be empty")
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return NewCodedError(CodeEmpty, "value cannot be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		value interface{}
+		code  string // Expected code, or "" when the value is valid
+	}{
+		{"required missing", "required|min:3", nil, CodeRequired},
+		{"required too short", "required|min:3", "ab", CodeMinLength},
+		{"required valid", "required|min:3", "abc", ""},
+		{"optional empty", "min:3|email", "", ""},
+		{"optional invalid", "min:3|email", "abc", CodeInvalidEmail},
+		{"number bound", "max:10", 11.0, CodeMaxValue},
+		{"oneOf", "oneOf:admin,user", "guest", CodeNotAllowed},
+		{"escaped separator", `pattern:^(a\|b)$`, "b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validations, err := ParseRules(tt.rules)
+			if err != nil {
+				t.Fatalf("ParseRules() error = %v", err)
+			}
+			var got error
+			for _, validation := range validations {
+				if got = validation(tt.value); got != nil {
+					break
+				}
+			}
+			if code := ErrorCode(got); code != tt.code {
+				t.Errorf("validation error = %v, want code %q", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"unknown validator", "required|nope", "unknown validator 'nope'"},
+		{"bad parameter", "min:abc", "invalid validation rule 'min:abc'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRules(tt.rules); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseRules() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterValidatorInvalidatesCaches(t *testing.T) {
+	const name = "testEven"
+	s := &BaseSerializer{Rules: map[string]string{"n": "required|" + name}}
+	type tagged struct {
+		N int `json:"n" validate:"min=2"`
+	}
+
+	// Compiled before the validator exists, the rule is unknown
+	if err := s.Validate(map[string]interface{}{"n": 3.0}); err == nil {
+		t.Fatal("Validate() succeeded with an unknown validator")
+	}
+	RegisterValidator(name, staticValidator(func(value interface{}) error {
+		if n, _ := value.(float64); int(n)%2 != 0 {
+			return NewCodedError("odd", "value must be even")
+		}
+		return nil
+	}))
+	if err := s.Validate(map[string]interface{}{"n": 3.0}); ErrorCode(err) != "odd" {
+		t.Errorf("Validate() error = %v, want the registered validator's", err)
+	}
+	if err := s.Validate(map[string]interface{}{"n": 4.0}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	// Validate tags use the min validator, which can be replaced too
+	if _, err := TagValidations(tagged{}); err != nil {
+		t.Fatalf("TagValidations() error = %v", err)
+	}
+	original := validators["min"]
+	defer RegisterValidator("min", original)
+	replaced := errors.New("replaced")
+	RegisterValidator("min", func(map[string]interface{}) (func(interface{}) error, error) {
+		return func(interface{}) error { return replaced }, nil
+	})
+	validations, err := TagValidations(tagged{})
+	if err != nil {
+		t.Fatalf("TagValidations() error = %v", err)
+	}
+	if err := validations["n"][0](5.0); !errors.Is(err, replaced) {
+		t.Errorf("tag validation error = %v, want the replaced validator's", err)
+	}
+}
@@ -0,0 +1,94 @@
+package serializer
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	htmlBlockPattern = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>|<!--.*?-->`)
+	htmlTagPattern   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// TrimSpace removes leading and trailing white space.
+func TrimSpace(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// CollapseWhitespace replaces runs of white space, including line breaks and tabs, with a single
+// space and trims the string.
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// StripHTML removes HTML tags and comments, and script and style elements with their content,
+// leaving the text. Character references such as &amp; are kept as they are, so stripping is
+// idempotent; encode the output as HTML where it is displayed. A "<" that doesn't start a tag,
+// as in "a < b", is kept.
+func StripHTML(s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	return htmlTagPattern.ReplaceAllString(htmlBlockPattern.ReplaceAllString(s, ""), "")
+}
+
+// NormalizeUnicode converts a string to Unicode normalization form NFC, composing letters
+// followed by combining marks into their precomposed forms, so that "e" followed by U+0301 and "é"
+// compare equal. Input from macOS and some mobile keyboards arrives decomposed.
+func NormalizeUnicode(s string) string {
+	return norm.NFC.String(s)
+}
+
+// LowercaseEmail trims an e-mail address and converts it to lower case, so that addresses
+// differing in case are stored and compared alike. The local part is technically case
+// sensitive, but mail providers treat it case-insensitively.
+func LowercaseEmail(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// sanitizeInput returns a copy of input with the Sanitizers of its fields applied to their
+// strings, or input itself when no sanitizer applies.
+func (s *BaseSerializer) sanitizeInput(input map[string]interface{}) map[string]interface{} {
+	if len(s.Sanitizers) == 0 {
+		return input
+	}
+	var sanitized map[string]interface{}
+	for field, sanitizers := range s.Sanitizers {
+		value, exists := input[field]
+		if !exists || len(sanitizers) == 0 {
+			continue
+		}
+		if sanitized == nil {
+			sanitized = make(map[string]interface{}, len(input))
+			for key, value := range input {
+				sanitized[key] = value
+			}
+		}
+		sanitized[field] = sanitizeValue(value, sanitizers)
+	}
+	if sanitized == nil {
+		return input
+	}
+	return sanitized
+}
+
+// sanitizeValue applies sanitizers to a string, or to the strings of a list.
+func sanitizeValue(value interface{}, sanitizers []func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, sanitize := range sanitizers {
+			v = sanitize(v)
+		}
+		return v
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = sanitizeValue(item, sanitizers)
+		}
+		return items
+	default:
+		return value
+	}
+}
@@ -0,0 +1,51 @@
+package serializer
+
+import "testing"
+
+func TestNormalizeUnicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", "plain", "plain"},
+		{"precomposed", "caf\u00e9", "caf\u00e9"},
+		{"latin acute", "cafe\u0301", "caf\u00e9"},
+		{"latin caron", "C\u030cesky\u0301", "\u010cesk\u00fd"},
+		{"vietnamese stacked marks", "e\u0302\u0301", "\u1ebf"},
+		{"greek tonos", "\u03b1\u0301", "\u03ac"},
+		{"reordered marks", "a\u0302\u0323", "\u1ead"},
+		{"hangul jamo", "\u1100\u1161", "\uac00"},
+		{"mark without composition", "x\u0301", "x\u0301"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeUnicode(tt.input); got != tt.want {
+				t.Errorf("NormalizeUnicode(%+q) = %+q, want %+q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		sanitize func(string) string
+		input    string
+		want     string
+	}{
+		{"trim space", TrimSpace, "  a b \n", "a b"},
+		{"collapse whitespace", CollapseWhitespace, " a \t\n b  c ", "a b c"},
+		{"strip tags", StripHTML, "<b>bold</b> text", "bold text"},
+		{"strip script", StripHTML, "a<script>alert(1)</script>b", "ab"},
+		{"keep less-than", StripHTML, "a < b", "a < b"},
+		{"lowercase email", LowercaseEmail, " Ana@Example.COM ", "ana@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sanitize(tt.input); got != tt.want {
+				t.Errorf("sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
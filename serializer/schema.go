@@ -0,0 +1,736 @@
+package serializer
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// structSchema is the precomputed description of a struct type, built once per type.
+type structSchema struct {
+	fields   []schemaField
+	byName   map[string]*schemaField
+	embedded map[string][]string // Keys promoted from each embedded struct, by the struct's field name
+	flat     bool                // Every field is a scalar without custom encoding, see encodeFlat
+}
+
+// schemaField describes a struct field as encoding/json sees it.
+type schemaField struct {
+	name      string       // JSON key
+	index     []int        // Index sequence for reflect.Value.FieldByIndex
+	typ       reflect.Type // Field type
+	omitEmpty bool         // ",omitempty" option
+	quoted    bool         // ",string" option on a scalar field
+	tagged    bool         // The name comes from the JSON tag
+	embedded  []string     // Field names of the embedded structs the field is promoted from, outermost first
+}
+
+// schemaCache holds the schema of every struct type seen so far, keyed by reflect.Type.
+var schemaCache sync.Map
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	numberType        = reflect.TypeOf(json.Number(""))
+	bytesType         = reflect.TypeOf([]byte(nil))
+)
+
+// schemaFor returns the cached schema for struct type t, building it on first use.
+func schemaFor(t reflect.Type) *structSchema {
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*structSchema)
+	}
+	schema, _ := schemaCache.LoadOrStore(t, buildSchema(t))
+	return schema.(*structSchema)
+}
+
+// buildSchema computes the serialized fields of t following the encoding/json rules,
+// including promotion of fields from embedded structs and the dominance rules for name conflicts.
+func buildSchema(t reflect.Type) *structSchema {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+		names []string
+	}
+
+	var fields []schemaField
+	current := []embedded{}
+	next := []embedded{{typ: t}}
+	count := map[reflect.Type]int{}
+	nextCount := map[reflect.Type]int{}
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				if !validTagName(name) {
+					name = ""
+				}
+
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				quoted := false
+				if hasTagOption(opts, "string") {
+					switch ft.Kind() {
+					case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64, reflect.String:
+						quoted = true
+					}
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					field := schemaField{
+						name:      name,
+						index:     index,
+						typ:       sf.Type,
+						omitEmpty: hasTagOption(opts, "omitempty"),
+						quoted:    quoted,
+						tagged:    name != "",
+						embedded:  e.names,
+					}
+					if field.name == "" {
+						field.name = sf.Name
+					}
+					fields = append(fields, field)
+					if count[e.typ] > 1 {
+						// Several embedded structs of the same type at this depth: the
+						// duplicate annihilates the field during the dominance pass.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					names := append(append([]string(nil), e.names...), sf.Name)
+					next = append(next, embedded{typ: ft, index: index, names: names})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		if a.tagged != b.tagged {
+			return a.tagged
+		}
+		return indexLess(a.index, b.index)
+	})
+
+	// Keep the dominant field for every name
+	dominant := fields[:0:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		group := fields[i:j]
+		if len(group) == 1 || len(group[0].index) < len(group[1].index) || group[0].tagged != group[1].tagged {
+			dominant = append(dominant, group[0])
+		}
+		i = j
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		return indexLess(dominant[i].index, dominant[j].index)
+	})
+
+	schema := &structSchema{fields: dominant, byName: make(map[string]*schemaField, len(dominant))}
+	for i := range schema.fields {
+		field := &schema.fields[i]
+		schema.byName[field.name] = field
+		for _, name := range field.embedded {
+			if schema.embedded == nil {
+				schema.embedded = make(map[string][]string)
+			}
+			schema.embedded[name] = append(schema.embedded[name], field.name)
+		}
+	}
+	schema.flat = isFlat(schema.fields)
+	return schema
+}
+
+// indexLess orders index sequences in struct declaration order.
+func indexLess(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// hasTagOption reports whether a comma-separated tag option list contains option.
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var current string
+		current, opts, _ = strings.Cut(opts, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+// validTagName reports whether name is accepted by encoding/json as a key.
+func validTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// fieldValue returns the value of a schema field, and false when it is unreachable
+// through a nil embedded pointer.
+func fieldValue(v reflect.Value, field *schemaField) (reflect.Value, bool) {
+	for i, x := range field.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// maxEncodeDepth bounds recursion so self-referencing values fail instead of overflowing the stack.
+const maxEncodeDepth = 1000
+
+// encodeOptions tunes toJSONValue beyond the encoding/json behavior.
+type encodeOptions struct {
+	maxDepth    int                                              // Objects and lists nested deeper are replaced by nil; unlimited when 0
+	cycles      bool                                             // Replace references back to an enclosing value with a placeholder
+	reference   func(value interface{}, path string) interface{} // Placeholder for a cyclic reference; {"$ref": path} when nil
+	marshalers  bool                                             // Consult the Marshaler interface
+	polymorphic []*PolymorphicType                               // Interface types serialized with a discriminator
+	registry    *Registry                                        // Serializers applied to nested structs
+	ctx         context.Context                                  // Context passed to the nested serializers
+	times       *TimeFormat                                      // Encoding of times; RFC 3339 when nil
+	fieldTimes  map[string]TimeFormat                            // Encoding of times within top-level fields
+	numbers     bool                                             // Write numbers as json.Number instead of float64
+	native      func(reflect.Type) bool                          // Types whose values are kept as they are (see NativeCodec)
+}
+
+// encodeState holds the state of a single toJSONValue conversion.
+type encodeState struct {
+	opts      *encodeOptions
+	level     int                 // Number of enclosing objects and lists
+	path      []string            // JSON Pointer tokens of the value being converted, tracked with cycles
+	ancestors map[cycleKey]string // JSON Pointers of the references being converted, tracked with cycles
+	times     *TimeFormat         // Encoding of the times being converted
+}
+
+// cycleKey identifies a pointer, map or slice for cycle detection, like encoding/json does.
+type cycleKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// toJSONValue converts a Go value into the plain value (maps, slices, float64, string, bool, nil)
+// that a json.Marshal and json.Unmarshal round-trip into an interface{} would produce,
+// using the cached struct schemas instead of encoding and parsing JSON.
+func toJSONValue(v reflect.Value) (interface{}, error) {
+	return toJSONValueWith(v, nil)
+}
+
+// toJSONValueWith is like toJSONValue, applying depth limits and cycle detection from opts when not nil.
+func toJSONValueWith(v reflect.Value, opts *encodeOptions) (interface{}, error) {
+	e := &encodeState{opts: opts}
+	if opts != nil {
+		if opts.cycles {
+			e.ancestors = make(map[cycleKey]string)
+		}
+		e.times = opts.times
+	}
+	return e.encodeValue(v, 0)
+}
+
+// encodeValue converts v following the encoding/json rules.
+func (e *encodeState) encodeValue(v reflect.Value, depth int) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if depth > maxEncodeDepth {
+		return nil, fmt.Errorf("encountered a cycle via %s", v.Type())
+	}
+	t := v.Type()
+
+	// Custom marshalers take precedence over the default encoding
+	if e.opts != nil && e.opts.marshalers {
+		if m, ok := marshaler(v); ok {
+			value, err := m.SerializeField()
+			if err != nil {
+				return nil, fmt.Errorf("%s.SerializeField: %v", t, err)
+			}
+			return e.encodeValue(reflect.ValueOf(value), depth+1)
+		}
+	}
+	if e.opts != nil && e.opts.native != nil {
+		if t.Kind() == reflect.Ptr && !v.IsNil() && e.opts.native(t.Elem()) {
+			return v.Elem().Interface(), nil
+		}
+		if e.opts.native(t) {
+			return v.Interface(), nil
+		}
+	}
+	if e.times != nil && t.Kind() == reflect.Ptr && t.Elem() == timeType && !v.IsNil() {
+		return e.times.format(v.Elem().Interface().(time.Time))
+	}
+	if e.times != nil && e.times.Duration != "" {
+		if t == durationType {
+			return formatDuration(time.Duration(v.Int()), e.times.Duration)
+		}
+		if t.Kind() == reflect.Ptr && t.Elem() == durationType && !v.IsNil() {
+			return formatDuration(time.Duration(v.Elem().Int()), e.times.Duration)
+		}
+	}
+	if t == timeType {
+		tm := v.Interface().(time.Time)
+		if e.times != nil {
+			return e.times.format(tm)
+		}
+		if y := tm.Year(); y < 0 || y >= 10000 {
+			return nil, fmt.Errorf("Time.MarshalJSON: year outside of range [0,9999]")
+		}
+		return tm.Format(time.RFC3339Nano), nil
+	}
+	numbers := e.opts != nil && e.opts.numbers
+	if t.Implements(jsonMarshalerType) && !(t.Kind() == reflect.Ptr && v.IsNil()) {
+		return decodeMarshaler(v.Interface().(json.Marshaler), numbers)
+	}
+	if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return decodeMarshaler(v.Addr().Interface().(json.Marshaler), numbers)
+	}
+	if t.Implements(textMarshalerType) && !(t.Kind() == reflect.Ptr && v.IsNil()) {
+		return marshalText(v.Interface().(encoding.TextMarshaler))
+	}
+	if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(textMarshalerType) {
+		return marshalText(v.Addr().Interface().(encoding.TextMarshaler))
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return encodeScalar(v, numbers)
+	case reflect.Struct:
+		if e.opts != nil && e.opts.registry != nil && e.level > 0 && v.CanInterface() {
+			if registered, ok := e.opts.registry.lookupType(t); ok {
+				return e.nested(func() (interface{}, error) { return serializeRegistered(e.opts.ctx, registered, v.Interface()) })
+			}
+		}
+		if encoder, ok := e.mapEncoder(v); ok {
+			return e.nested(func() (interface{}, error) { return encoder.EncodeMap(numbers) })
+		}
+		return e.nested(func() (interface{}, error) { return e.encodeStruct(v, depth) })
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.reference(v, cycleKey{v.Pointer(), 0, t}, func() (interface{}, error) {
+			return e.nested(func() (interface{}, error) { return e.encodeMap(v, depth) })
+		})
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PtrTo(t.Elem()).Implements(jsonMarshalerType) &&
+			!reflect.PtrTo(t.Elem()).Implements(textMarshalerType) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		return e.reference(v, cycleKey{v.Pointer(), v.Len(), t}, func() (interface{}, error) {
+			return e.nested(func() (interface{}, error) { return e.encodeArray(v, depth) })
+		})
+	case reflect.Array:
+		return e.nested(func() (interface{}, error) { return e.encodeArray(v, depth) })
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if e.opts != nil {
+			if p := polymorphicFor(e.opts.polymorphic, t); p != nil {
+				encoded, err := e.encodeValue(v.Elem(), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				return addDiscriminator(p, v, encoded), nil
+			}
+		}
+		return e.encodeValue(v.Elem(), depth+1)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.reference(v, cycleKey{v.Pointer(), 0, t}, func() (interface{}, error) {
+			return e.encodeValue(v.Elem(), depth+1)
+		})
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", t)
+	}
+}
+
+// encodeScalar converts a boolean, number or string like encoding/json does.
+func encodeScalar(v reflect.Value, numbers bool) (interface{}, error) {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if numbers {
+			return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+		}
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if numbers {
+			return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+		}
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat(v.Float(), t.Bits(), numbers)
+	case reflect.String:
+		if t == numberType {
+			number := v.String()
+			if number == "" {
+				number = "0"
+			}
+			f, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number literal %q", number)
+			}
+			if numbers {
+				return json.Number(number), nil
+			}
+			return f, nil
+		}
+		return validUTF8(v.String()), nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", t)
+	}
+}
+
+// nested converts an object or a list with encode, replacing it with nil beyond the maximum depth.
+func (e *encodeState) nested(encode func() (interface{}, error)) (interface{}, error) {
+	if e.opts != nil && e.opts.maxDepth > 0 && e.level >= e.opts.maxDepth {
+		return nil, nil
+	}
+	e.level++
+	value, err := encode()
+	e.level--
+	return value, err
+}
+
+// reference converts a pointer, map or slice with encode, replacing it with a placeholder when it
+// refers back to a value that is still being converted.
+func (e *encodeState) reference(v reflect.Value, key cycleKey, encode func() (interface{}, error)) (interface{}, error) {
+	if e.ancestors == nil {
+		return encode()
+	}
+	if path, seen := e.ancestors[key]; seen {
+		if e.opts.reference != nil {
+			return e.opts.reference(v.Interface(), path), nil
+		}
+		return map[string]interface{}{"$ref": "#" + path}, nil
+	}
+
+	e.ancestors[key] = e.pointer()
+	value, err := encode()
+	delete(e.ancestors, key)
+	return value, err
+}
+
+// pointer returns the JSON Pointer of the value being converted.
+func (e *encodeState) pointer() string {
+	return JSONPointer(e.path...)
+}
+
+// push enters the member named token, when paths are tracked.
+func (e *encodeState) push(token string) {
+	if e.ancestors != nil {
+		e.path = append(e.path, token)
+	}
+}
+
+// pushIndex enters the list element at index i, when paths are tracked.
+func (e *encodeState) pushIndex(i int) {
+	if e.ancestors != nil {
+		e.path = append(e.path, strconv.Itoa(i))
+	}
+}
+
+// pop leaves the member entered by push or pushIndex.
+func (e *encodeState) pop() {
+	if e.ancestors != nil {
+		e.path = e.path[:len(e.path)-1]
+	}
+}
+
+// encodeStruct converts a struct into a map using its cached schema.
+func (e *encodeState) encodeStruct(v reflect.Value, depth int) (interface{}, error) {
+	schema := schemaFor(v.Type())
+	if schema.flat {
+		return encodeFlat(v, schema, e.opts != nil && e.opts.numbers)
+	}
+	result := make(map[string]interface{}, len(schema.fields))
+	for i := range schema.fields {
+		field := &schema.fields[i]
+		fv, ok := fieldValue(v, field)
+		if !ok || (field.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		if field.quoted {
+			value, err := encodeQuoted(fv)
+			if err != nil {
+				return nil, err
+			}
+			result[field.name] = value
+			continue
+		}
+
+		e.push(field.name)
+		times := e.times
+		if format, ok := e.fieldTimeFormat(field.name); ok {
+			e.times = format
+		}
+		value, err := e.encodeValue(fv, depth+1)
+		e.times = times
+		e.pop()
+		if err != nil {
+			return nil, err
+		}
+		result[field.name] = value
+	}
+	return result, nil
+}
+
+// encodeQuoted converts a field with the ",string" option.
+func encodeQuoted(v reflect.Value) (interface{}, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.String && v.Type() != numberType {
+		quoted, err := json.Marshal(validUTF8(v.String()))
+		if err != nil {
+			return nil, err
+		}
+		return string(quoted), nil
+	}
+	encoded, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// fieldTimeFormat returns the time format configured for a field of the top-level struct.
+func (e *encodeState) fieldTimeFormat(field string) (*TimeFormat, bool) {
+	if e.opts == nil || e.level != 1 {
+		return nil, false
+	}
+	if _, ok := e.opts.fieldTimes[field]; !ok {
+		return nil, false
+	}
+	// Copied only when found, since taking its address moves the copy to the heap
+	format := e.opts.fieldTimes[field]
+	return &format, true
+}
+
+// encodeMap converts a map, turning its keys into strings like encoding/json does.
+func (e *encodeState) encodeMap(v reflect.Value, depth int) (interface{}, error) {
+	result := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		e.push(key)
+		value, err := e.encodeValue(iter.Value(), depth+1)
+		e.pop()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// mapKey converts a map key to the string encoding/json uses.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type: %s", k.Type())
+}
+
+// encodeArray converts a slice or array into a []interface{}.
+func (e *encodeState) encodeArray(v reflect.Value, depth int) (interface{}, error) {
+	result := make([]interface{}, v.Len())
+	for i := range result {
+		e.pushIndex(i)
+		value, err := e.encodeValue(v.Index(i), depth+1)
+		e.pop()
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+// decodeMarshaler converts the output of a json.Marshaler into a plain value.
+func decodeMarshaler(m json.Marshaler, numbers bool) (interface{}, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if numbers {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&value)
+	} else {
+		err = json.Unmarshal(data, &value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// formatFloat formats f like encoding/json writes floats of the given bit size.
+func formatFloat(f float64, bits int) string {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21)) {
+		format = 'e'
+	}
+	b := strconv.AppendFloat(nil, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9, as encoding/json does
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return string(b)
+}
+
+// marshalText converts the output of an encoding.TextMarshaler into a string.
+func marshalText(m encoding.TextMarshaler) (interface{}, error) {
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return validUTF8(string(text)), nil
+}
+
+// validUTF8 replaces every invalid byte with U+FFFD, as encoding/json does.
+func validUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isEmptyValue reports whether v is empty for the purposes of the ",omitempty" option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package serializer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaBase struct {
+	ID      int    `json:"id"`
+	Created string `json:"created,omitempty"`
+}
+
+type schemaItem struct {
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price,string"`
+}
+
+type schemaOrder struct {
+	schemaBase
+	Number   string            `json:"number"`
+	Items    []schemaItem      `json:"items"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Note     *string           `json:"note"`
+	Paid     time.Time         `json:"paid"`
+	Raw      []byte            `json:"raw"`
+	Total    json.Number       `json:"total"`
+	internal int
+	Ignored  string `json:"-"`
+}
+
+func TestToJSONValueMatchesJSONRoundTrip(t *testing.T) {
+	note := "fragile"
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"flat", account{ID: 1, Name: "a", Role: "user"}},
+		{"pointer", &account{ID: 2}},
+		{"nil pointer", (*account)(nil)},
+		{"nested", schemaOrder{
+			schemaBase: schemaBase{ID: 7},
+			Number:     "SO-7",
+			Items:      []schemaItem{{"A", 1.5}, {"B", 2}},
+			Tags:       map[string]string{"gift": "yes"},
+			Note:       &note,
+			Paid:       time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			Raw:        []byte("raw"),
+			Total:      "3.5",
+			internal:   1,
+			Ignored:    "x",
+		}},
+		{"empty", schemaOrder{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toJSONValue(reflect.ValueOf(tt.value))
+			if err != nil {
+				t.Fatalf("toJSONValue() error = %v", err)
+			}
+			encoded, _ := json.Marshal(tt.value)
+			var want interface{}
+			if err := json.Unmarshal(encoded, &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("toJSONValue() = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+// BenchmarkSchemaFor shows what the schema cache saves on every call: looking up a cached schema
+// against computing it again.
+func BenchmarkSchemaFor(b *testing.B) {
+	t := reflect.TypeOf(schemaOrder{})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			schemaFor(t)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buildSchema(t)
+		}
+	})
+}
+
+// BenchmarkStructToMap compares the conversion of structs into maps with the cached schemas
+// against the json.Marshal and json.Unmarshal round trip it replaces.
+func BenchmarkStructToMap(b *testing.B) {
+	note := "fragile"
+	values := []struct {
+		name  string
+		value interface{}
+	}{
+		{"Flat", account{ID: 1, Name: "a", Role: "user"}},
+		{"Nested", schemaOrder{
+			schemaBase: schemaBase{ID: 7},
+			Number:     "SO-7",
+			Items:      []schemaItem{{"A", 1.5}, {"B", 2}},
+			Tags:       map[string]string{"gift": "yes"},
+			Note:       &note,
+			Paid:       time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+	for _, v := range values {
+		b.Run(v.name+"/schema", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := structToMap(v.value, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(v.name+"/json-round-trip", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				encoded, err := json.Marshal(v.value)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var m map[string]interface{}
+				if err := json.Unmarshal(encoded, &m); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type cycleNode struct {
+	Name     string                 `json:"name"`
+	Next     *cycleNode             `json:"next,omitempty"`
+	Children []interface{}          `json:"children,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+}
+
+func TestSerializeCycles(t *testing.T) {
+	pointer := &cycleNode{Name: "a"}
+	pointer.Next = pointer
+
+	meta := map[string]interface{}{"k": "v"}
+	meta["self"] = meta
+	inMap := &cycleNode{Name: "m", Meta: meta}
+
+	children := make([]interface{}, 2)
+	children[0] = "x"
+	children[1] = children
+	inSlice := &cycleNode{Name: "s", Children: children}
+
+	shared := &cycleNode{Name: "shared"}
+	diamond := &cycleNode{Name: "d", Next: shared, Children: []interface{}{shared}}
+
+	tests := []struct {
+		name   string
+		data   interface{}
+		cyclic bool
+		want   map[string]interface{}
+	}{
+		{"pointer", pointer, true, map[string]interface{}{"name": "a", "next": map[string]interface{}{"$ref": "#"}}},
+		{"map", inMap, true, map[string]interface{}{"name": "m", "meta": map[string]interface{}{"k": "v", "self": map[string]interface{}{"$ref": "#/meta"}}}},
+		{"slice", inSlice, true, map[string]interface{}{"name": "s", "children": []interface{}{"x", map[string]interface{}{"$ref": "#/children"}}}},
+		{"shared value isn't a cycle", diamond, false, map[string]interface{}{
+			"name": "d", "next": map[string]interface{}{"name": "shared"}, "children": []interface{}{map[string]interface{}{"name": "shared"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{DetectCycles: true}
+			got, err := s.Serialize(tt.data)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+
+			_, err = (&BaseSerializer{}).Serialize(tt.data)
+			if tt.cyclic && (err == nil || !strings.Contains(err.Error(), "encountered a cycle")) {
+				t.Errorf("Serialize() without DetectCycles error = %v, want a cycle error", err)
+			} else if !tt.cyclic && err != nil {
+				t.Errorf("Serialize() without DetectCycles error = %v", err)
+			}
+		})
+	}
+
+	s := &BaseSerializer{DetectCycles: true, CycleReference: func(value interface{}, path string) interface{} {
+		return "cycle to " + path + " via " + value.(*cycleNode).Name
+	}}
+	got, err := s.Serialize(pointer)
+	if want := map[string]interface{}{"name": "a", "next": "cycle to  via a"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize() with CycleReference = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestSerializeMaxDepth(t *testing.T) {
+	data := &cycleNode{Name: "1", Next: &cycleNode{Name: "2", Next: &cycleNode{Name: "3"}}, Children: []interface{}{[]interface{}{"deep"}}}
+	tests := []struct {
+		maxDepth int
+		want     map[string]interface{}
+	}{
+		{1, map[string]interface{}{"name": "1", "next": nil, "children": nil}},
+		{2, map[string]interface{}{"name": "1", "next": map[string]interface{}{"name": "2", "next": nil}, "children": []interface{}{nil}}},
+		{0, map[string]interface{}{
+			"name": "1", "next": map[string]interface{}{"name": "2", "next": map[string]interface{}{"name": "3"}}, "children": []interface{}{[]interface{}{"deep"}},
+		}},
+	}
+	for _, tt := range tests {
+		s := &BaseSerializer{MaxDepth: tt.maxDepth}
+		if got, err := s.Serialize(data); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Serialize() with MaxDepth %d = %v, %v, want %v", tt.maxDepth, got, err, tt.want)
+		}
+	}
+
+	// A cycle is cut off by the depth limit rather than reported as an error
+	pointer := &cycleNode{Name: "a"}
+	pointer.Next = pointer
+	s := &BaseSerializer{MaxDepth: 2}
+	if got, err := s.Serialize(pointer); err != nil || !reflect.DeepEqual(got, map[string]interface{}{"name": "a", "next": map[string]interface{}{"name": "a", "next": nil}}) {
+		t.Errorf("Serialize() of a cycle with MaxDepth = %v, %v", got, err)
+	}
+}
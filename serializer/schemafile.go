@@ -0,0 +1,362 @@
+package serializer
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// schemaOptions lists the options of each field type in a schema file, besides the options
+// every type accepts.
+var schemaOptions = map[string][]string{
+	"string":   {"maxLength"},
+	"int":      {"min", "max"},
+	"float":    {"min", "max"},
+	"bool":     nil,
+	"date":     {"layout"},
+	"duration": {"format"},
+	"email":    nil,
+	"uuid":     nil,
+	"currency": {"currency"},
+	"money":    {"currencies", "minorUnits"},
+	"point":    nil,
+	"geojson":  {"types", "features"},
+	"enum":     {"values"},
+	"slice":    {"items", "minItems", "maxItems", "uniqueItems"},
+	"map":      {"values", "keyPattern", "maxEntries"},
+	"file":     {"maxSize", "allowedTypes", "extensions", "maxFiles"},
+	"any":      nil,
+}
+
+// commonSchemaOptions are accepted by every field type.
+var commonSchemaOptions = []string{"type", "required", "default", "readOnly", "writeOnly", "validators", "rules"}
+
+// LoadSchema reads a schema file (see ParseSchema), in JSON or YAML according to its extension.
+func LoadSchema(path string) (map[string]Field, error) {
+	format := FormatFromExtension(path)
+	if format != FormatJSON && format != FormatYAML {
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported schema file '%s': expected a .json, .yaml or .yml file", path)}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to read schema file: %v", err), Err: err}
+	}
+	return ParseSchema(data, format)
+}
+
+// ParseSchema parses a schema file into the field types of NewSchemaSerializer. The file maps
+// field names to their definitions:
+//
+//	name:  {type: string, required: true, maxLength: 50}
+//	age:   {type: int, min: 0, max: 150}
+//	role:  {type: enum, values: [admin, editor, viewer], default: viewer}
+//	tags:  {type: slice, items: {type: string}, maxItems: 10}
+//	attrs: {type: map, values: {type: any}}
+//	code:  {type: string, validators: [notEmpty, {name: pattern, value: "^[A-Z]{3}$"}]}
+//	login: {type: string, rules: "required|min:3|max:50"}
+//
+// The types are string, int, float, bool, date (with a layout), duration (with a format: string,
+// seconds or millis), email, uuid, currency (with a currency code), money, point, geojson, enum,
+// slice, map, file and any, the default. Every type
+// accepts required, validators (see RegisterValidator; a validator with severity: warning only
+// warns, see AsWarning), rules (a rule string, see ParseRules), and default, readOnly and
+// writeOnly, which set the options of a SchemaField. Unknown types and options are rejected.
+func ParseSchema(data []byte, format Format) (map[string]Field, error) {
+	s := &BaseSerializer{DuplicateKeys: DuplicateKeysError}
+	var definitions map[string]interface{}
+	var err error
+	switch format {
+	case FormatJSON:
+		definitions, err = s.DecodeJSON(data)
+	case FormatYAML:
+		definitions, err = s.DecodeYAML(data)
+	default:
+		return nil, &SerializationError{Message: fmt.Sprintf("unsupported schema format '%s'", format)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string]Field, len(definitions))
+	for name, definition := range definitions {
+		field, err := parseSchemaField(name, definition, nil)
+		if err != nil {
+			return nil, err
+		}
+		schema[name] = field
+	}
+	return schema, nil
+}
+
+// parseSchemaField converts the definition of the field at path, which may also hold the extra
+// options.
+func parseSchemaField(path string, definition interface{}, extra []string) (Field, error) {
+	spec, ok := definition.(map[string]interface{})
+	if !ok {
+		return nil, schemaFileError(path, "definition is not an object")
+	}
+	typ := "any"
+	if t, exists := spec["type"]; exists {
+		typ, _ = t.(string)
+	}
+	options, ok := schemaOptions[typ]
+	if !ok {
+		return nil, schemaFileError(path, fmt.Sprintf("unknown type '%v'", spec["type"]))
+	}
+	for _, key := range objectKeys(spec) {
+		if !containsField(commonSchemaOptions, key) && !containsField(options, key) && !containsField(extra, key) {
+			return nil, schemaFileError(path, fmt.Sprintf("unknown option '%s' for type %s", key, typ))
+		}
+	}
+
+	r := &specReader{path: path, spec: spec}
+	required := r.bool("required")
+	var field Field
+	switch typ {
+	case "string":
+		maxLength := math.MaxInt
+		if n := r.int("maxLength"); n != nil {
+			maxLength = int(*n)
+		}
+		field = StringField{MaxLength: maxLength, Required: required}
+	case "int":
+		field = IntField{Min: r.int("min"), Max: r.int("max"), Required: required}
+	case "float":
+		field = FloatField{Min: r.float("min"), Max: r.float("max"), Required: required}
+	case "bool":
+		field = BoolField{Required: required}
+	case "date":
+		field = DateField{Layout: r.string("layout"), Required: required}
+	case "duration":
+		format := r.string("format")
+		if _, err := formatDuration(0, format); err != nil {
+			return nil, schemaFileError(path, fmt.Sprintf("unknown duration format '%s'", format))
+		}
+		field = DurationField{Format: format, Required: required}
+	case "email":
+		field = EmailField{Required: required}
+	case "uuid":
+		field = UUIDField{Required: required}
+	case "currency":
+		field = CurrencyField{Currency: r.string("currency"), Required: required}
+	case "point":
+		field = PointField{Required: required}
+	case "geojson":
+		field = GeoJSONField{Types: r.strings("types"), Features: r.bool("features"), Required: required}
+	case "money":
+		field = MoneyField{Currencies: r.strings("currencies"), MinorUnits: r.bool("minorUnits"), Required: required}
+	case "enum":
+		values, ok := spec["values"].([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, schemaFileError(path, "enum needs a list of values")
+		}
+		field = EnumField{Values: values}
+	case "slice":
+		slice := SliceField{UniqueItems: r.bool("uniqueItems"), Required: required}
+		if n := r.int("minItems"); n != nil {
+			slice.MinItems = int(*n)
+		}
+		if n := r.int("maxItems"); n != nil {
+			slice.MaxItems = int(*n)
+		}
+		if items, ok := spec["items"]; ok {
+			elem, err := parseSchemaField(path+".items", items, nil)
+			if err != nil {
+				return nil, err
+			}
+			slice.Elem = elem
+		}
+		field = slice
+	case "map":
+		m := MapField{KeyPattern: r.string("keyPattern"), Required: required}
+		if _, err := keyPattern(m.KeyPattern); err != nil {
+			return nil, schemaFileError(path, fmt.Sprintf("invalid keyPattern: %v", err))
+		}
+		if n := r.int("maxEntries"); n != nil {
+			m.MaxEntries = int(*n)
+		}
+		if values, ok := spec["values"]; ok {
+			elem, err := parseSchemaField(path+".values", values, nil)
+			if err != nil {
+				return nil, err
+			}
+			m.Values = elem
+		}
+		field = m
+	case "file":
+		file := FileField{AllowedTypes: r.strings("allowedTypes"), Extensions: r.strings("extensions"), Required: required}
+		if n := r.int("maxSize"); n != nil {
+			file.MaxSize = *n
+		}
+		if n := r.int("maxFiles"); n != nil {
+			file.MaxFiles = int(*n)
+		}
+		field = file
+	case "any":
+		field = anyField{}
+	}
+	if required && (typ == "enum" || typ == "any") {
+		field = requiredField{field}
+	}
+	if definition, ok := spec["rules"]; ok {
+		rules, isString := definition.(string)
+		if !isString {
+			return nil, schemaFileError(path, "option 'rules' must be a string")
+		}
+		set := parseRules(rules)
+		if set.err != nil {
+			return nil, schemaFileError(path, set.err.Error())
+		}
+		field = validatedField{Field: field, validations: set.compile(), required: set.required}
+	}
+	if validators, ok := spec["validators"]; ok {
+		validations, err := parseValidators(path, validators)
+		if err != nil {
+			return nil, err
+		}
+		field = validatedField{Field: field, validations: validations}
+	}
+
+	if _, ok := spec["default"]; ok || r.bool("readOnly") || r.bool("writeOnly") {
+		field = SchemaField{Field: field, Default: spec["default"], ReadOnly: r.bool("readOnly"), WriteOnly: r.bool("writeOnly")}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return field, nil
+}
+
+// specReader reads the options of a field definition, or of a configuration file when path is
+// empty, keeping the first error.
+type specReader struct {
+	path string
+	spec map[string]interface{}
+	err  error
+}
+
+func (r *specReader) bool(key string) bool {
+	value, ok := r.spec[key]
+	if !ok {
+		return false
+	}
+	b, ok := value.(bool)
+	if !ok {
+		r.fail(key, "a boolean")
+	}
+	return b
+}
+
+func (r *specReader) string(key string) string {
+	value, ok := r.spec[key]
+	if !ok {
+		return ""
+	}
+	str, ok := value.(string)
+	if !ok {
+		r.fail(key, "a string")
+	}
+	return str
+}
+
+func (r *specReader) strings(key string) []string {
+	value, ok := r.spec[key]
+	if !ok {
+		return nil
+	}
+	strs, ok := stringList(value)
+	if !ok {
+		r.fail(key, "a list of strings")
+	}
+	return strs
+}
+
+func (r *specReader) float(key string) *float64 {
+	value, ok := r.spec[key]
+	if !ok {
+		return nil
+	}
+	n, ok := numberValue(value)
+	if !ok {
+		r.fail(key, "a number")
+		return nil
+	}
+	return &n
+}
+
+func (r *specReader) int(key string) *int64 {
+	n := r.float(key)
+	if n == nil {
+		return nil
+	}
+	if *n != math.Trunc(*n) || math.Abs(*n) >= 1<<53 {
+		r.fail(key, "an integer")
+		return nil
+	}
+	i := int64(*n)
+	return &i
+}
+
+// fail records that the option key doesn't hold the expected kind of value.
+func (r *specReader) fail(key, expected string) {
+	message := fmt.Sprintf("option '%s' must be %s", key, expected)
+	switch {
+	case r.err != nil:
+	case r.path == "":
+		r.err = configError(message)
+	default:
+		r.err = schemaFileError(r.path, message)
+	}
+}
+
+// schemaFileError reports an invalid definition in a schema file.
+func schemaFileError(path, message string) error {
+	return &SerializationError{Message: fmt.Sprintf("invalid schema field '%s': %s", path, message)}
+}
+
+// anyField accepts any value.
+type anyField struct{}
+
+func (anyField) Validate(interface{}) error { return nil }
+
+// requiredField marks a field type without a Required option as required.
+type requiredField struct {
+	Field
+}
+
+func (requiredField) IsRequired() bool { return true }
+
+// validatedField runs validations after the checks of its field type. required makes the field
+// required whatever its type.
+type validatedField struct {
+	Field
+	validations []func(interface{}) error
+	required    bool
+}
+
+func (f validatedField) Validate(value interface{}) error {
+	if err := f.Field.Validate(value); err != nil {
+		return err
+	}
+	for _, validation := range f.validations {
+		if err := validation(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f validatedField) Coerce(value interface{}) (interface{}, error) {
+	return coerceValue(f.Field, value)
+}
+
+func (f validatedField) Represent(value interface{}) (interface{}, error) {
+	return representValue(f.Field, value)
+}
+
+func (f validatedField) Localize(value interface{}, format LocaleFormat) (interface{}, error) {
+	return localizeValue(f.Field, value, format)
+}
+
+func (f validatedField) IsRequired() bool {
+	return f.required || required(f.Field)
+}
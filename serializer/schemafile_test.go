@@ -0,0 +1,127 @@
+package serializer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const schemaYAML = `
+name:  {type: string, required: true, maxLength: 5}
+age:   {type: int, min: 0, max: 150}
+score: {type: float, max: 1}
+role:  {type: enum, values: [admin, editor], default: editor, required: true}
+tags:  {type: slice, items: {type: string, maxLength: 3}, maxItems: 2, uniqueItems: true}
+attrs: {type: map, values: {type: int}, keyPattern: "^[a-z]+$", maxEntries: 2}
+code:  {type: string, validators: [notEmpty, {name: pattern, value: "^[A-Z]{3}$"}]}
+login: {type: string, rules: "required|min:3"}
+nick:  {type: string, validators: [{name: notEmpty, severity: warning}]}
+id:    {type: uuid, readOnly: true}
+`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema([]byte(schemaYAML), FormatYAML)
+	if err != nil {
+		t.Fatalf("ParseSchema() error = %v", err)
+	}
+	tests := []struct {
+		field    string
+		value    interface{}
+		wantCode string
+	}{
+		{"name", "ana", ""},
+		{"name", "anabel", CodeMaxLength},
+		{"age", 200, CodeMaxValue},
+		{"score", 0.5, ""},
+		{"role", "viewer", CodeNotAllowed},
+		{"tags", []interface{}{"a", "b", "c"}, CodeMaxLength},
+		{"tags", []interface{}{"abcd"}, CodeMaxLength},
+		{"tags", []interface{}{"a", "a"}, CodeDuplicateItem},
+		{"attrs", map[string]interface{}{"a": 1, "B": 2}, CodeInvalidKey},
+		{"code", "ABC", ""},
+		{"code", "abc", CodeInvalid},
+		{"login", "ab", CodeMinLength},
+		{"nick", "", CodeEmpty},
+		{"id", "123e4567-e89b-12d3-a456-426614174000", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			err := schema[tt.field].Validate(tt.value)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("Validate(%v) error = %v, want nil", tt.value, err)
+				}
+				return
+			}
+			if code := ErrorCode(err); code != tt.wantCode {
+				t.Errorf("Validate(%v) error = %v (%s), want %s", tt.value, err, code, tt.wantCode)
+			}
+		})
+	}
+
+	for field, want := range map[string]bool{"name": true, "role": true, "login": true, "age": false, "id": false} {
+		if got := required(schema[field]); got != want {
+			t.Errorf("required(%s) = %v, want %v", field, got, want)
+		}
+	}
+	if !IsWarning(schema["nick"].Validate("")) {
+		t.Errorf("validator with severity warning doesn't warn")
+	}
+	if field, ok := schema["role"].(SchemaField); !ok || field.Default != "editor" {
+		t.Errorf("role = %#v, want a SchemaField with a default", schema["role"])
+	}
+	if field, ok := schema["id"].(SchemaField); !ok || !field.ReadOnly {
+		t.Errorf("id = %#v, want a read-only SchemaField", schema["id"])
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"not an object", `name: string`, "invalid schema field 'name': definition is not an object"},
+		{"unknown type", `name: {type: text}`, "unknown type 'text'"},
+		{"unknown option", `name: {type: int, maxLength: 3}`, "unknown option 'maxLength' for type int"},
+		{"wrong option type", `name: {type: string, maxLength: "3"}`, "option 'maxLength' must be a number"},
+		{"fractional integer", `name: {type: int, min: 1.5}`, "option 'min' must be an integer"},
+		{"enum without values", `role: {type: enum}`, "enum needs a list of values"},
+		{"nested item", `tags: {type: slice, items: {type: nope}}`, "invalid schema field 'tags.items': unknown type 'nope'"},
+		{"invalid key pattern", `attrs: {type: map, keyPattern: "["}`, "invalid keyPattern"},
+		{"duration format", `wait: {type: duration, format: hours}`, "unknown duration format 'hours'"},
+		{"rules not a string", `name: {rules: [required]}`, "option 'rules' must be a string"},
+		{"unknown validator", `name: {validators: [nope]}`, "unknown validator 'nope'"},
+		{"validator severity", `name: {validators: [{name: notEmpty, severity: fatal}]}`, "severity must be error or warning"},
+		{"duplicate field", "name: {}\nname: {}\n", "duplicate key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSchema([]byte(tt.data), FormatYAML); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSchema() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := ParseSchema([]byte(`{}`), FormatXML); err == nil {
+		t.Errorf("ParseSchema() of XML error = nil")
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(path, []byte(`{"name": {"type": "string", "required": true}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadSchema(path)
+	if err != nil || !required(schema["name"]) {
+		t.Errorf("LoadSchema() = %v, %v", schema, err)
+	}
+	if _, err := LoadSchema(filepath.Join(dir, "schema.toml")); err == nil || !strings.Contains(err.Error(), "unsupported schema file") {
+		t.Errorf("LoadSchema() of a .toml file error = %v", err)
+	}
+	if _, err := LoadSchema(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read schema file") {
+		t.Errorf("LoadSchema() of a missing file error = %v", err)
+	}
+}
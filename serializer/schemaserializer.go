@@ -0,0 +1,90 @@
+package serializer
+
+import "sort"
+
+// SchemaField attaches serializer options to a field type in a schema given to NewSchemaSerializer.
+type SchemaField struct {
+	Field     Field                             // Field type validating, coercing and representing the value; any value when nil
+	Default   interface{}                       // Value (or func() interface{} factory) used when the field is missing on Deserialize
+	ReadOnly  bool                              // Serialized but ignored on Deserialize
+	WriteOnly bool                              // Accepted on Deserialize but never serialized
+	Transform func(interface{}) interface{}     // Transformation applied on Serialize
+	Condition func(map[string]interface{}) bool // Condition for including the field
+}
+
+func (f SchemaField) Validate(value interface{}) error {
+	if f.Field == nil {
+		return nil
+	}
+	return f.Field.Validate(value)
+}
+
+func (f SchemaField) Coerce(value interface{}) (interface{}, error) {
+	if f.Field == nil {
+		return value, nil
+	}
+	return coerceValue(f.Field, value)
+}
+
+func (f SchemaField) Represent(value interface{}) (interface{}, error) {
+	if f.Field == nil {
+		return value, nil
+	}
+	return representValue(f.Field, value)
+}
+
+func (f SchemaField) Localize(value interface{}, format LocaleFormat) (interface{}, error) {
+	if f.Field == nil {
+		return value, nil
+	}
+	return localizeValue(f.Field, value, format)
+}
+
+func (f SchemaField) IsRequired() bool {
+	return f.Field != nil && required(f.Field)
+}
+
+// NewSchemaSerializer creates a serializer from a declarative schema. The schema's keys become
+// Fields, its field types validate, coerce and represent the values (see Schema), and the options
+// of SchemaField entries set Defaults, read-only and write-only fields, Transformations and
+// ConditionalFields. The result can be configured further like any BaseSerializer.
+func NewSchemaSerializer(schema map[string]Field) *BaseSerializer {
+	s := &BaseSerializer{Schema: make(map[string]Field, len(schema))}
+	for name, field := range schema {
+		s.Schema[name] = field
+		s.Fields = append(s.Fields, name)
+
+		options, ok := field.(SchemaField)
+		if !ok {
+			continue
+		}
+		if options.Default != nil {
+			if s.Defaults == nil {
+				s.Defaults = make(map[string]interface{})
+			}
+			s.Defaults[name] = options.Default
+		}
+		if options.ReadOnly {
+			s.ReadOnlyFields = append(s.ReadOnlyFields, name)
+		}
+		if options.WriteOnly {
+			s.WriteOnlyFields = append(s.WriteOnlyFields, name)
+		}
+		if options.Transform != nil {
+			if s.Transformations == nil {
+				s.Transformations = make(map[string]func(interface{}) interface{})
+			}
+			s.Transformations[name] = options.Transform
+		}
+		if options.Condition != nil {
+			if s.ConditionalFields == nil {
+				s.ConditionalFields = make(map[string]func(map[string]interface{}) bool)
+			}
+			s.ConditionalFields[name] = options.Condition
+		}
+	}
+	sort.Strings(s.Fields)
+	sort.Strings(s.ReadOnlyFields)
+	sort.Strings(s.WriteOnlyFields)
+	return s
+}
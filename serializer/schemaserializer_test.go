@@ -0,0 +1,105 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewSchemaSerializer(t *testing.T) {
+	s := NewSchemaSerializer(map[string]Field{
+		"id":   SchemaField{Field: IntField{Min: Ptr[int64](1)}, ReadOnly: true},
+		"name": SchemaField{Field: StringField{MaxLength: 10, Required: true}, Transform: func(v interface{}) interface{} { return strings.ToUpper(v.(string)) }},
+		"role": SchemaField{Default: "user", Condition: func(result map[string]interface{}) bool { return result["role"] != "user" }},
+	})
+	if want := []string{"id", "name", "role"}; !reflect.DeepEqual(s.Fields, want) {
+		t.Errorf("Fields = %q, want %q", s.Fields, want)
+	}
+	if want := []string{"id"}; !reflect.DeepEqual(s.ReadOnlyFields, want) {
+		t.Errorf("ReadOnlyFields = %q, want %q", s.ReadOnlyFields, want)
+	}
+
+	serializeTests := []struct {
+		name string
+		data account
+		want map[string]interface{}
+	}{
+		{"condition met", account{ID: 7, Name: "ana", Role: "admin"}, map[string]interface{}{"id": 7.0, "name": "ANA", "role": "admin"}},
+		{"condition unmet", account{ID: 7, Name: "ana", Role: "user"}, map[string]interface{}{"id": 7.0, "name": "ANA", "role": nil}},
+	}
+	for _, tt := range serializeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Serialize(tt.data)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	var got account
+	if err := s.Deserialize(map[string]interface{}{"id": 9.0, "name": "ana"}, &got); err != nil || got != (account{Name: "ana", Role: "user"}) {
+		t.Errorf("Deserialize() = %+v, %v, want the default role and no id", got, err)
+	}
+
+	validateTests := []struct {
+		name      string
+		input     map[string]interface{}
+		wantField string
+	}{
+		{"valid", map[string]interface{}{"id": 9.0, "name": "ana"}, ""},
+		{"required", map[string]interface{}{"role": "admin"}, "name"},
+		{"invalid", map[string]interface{}{"name": "a very long name"}, "name"},
+		{"out of range", map[string]interface{}{"id": 0.0, "name": "ana"}, "id"},
+	}
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(tt.input)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want a ValidationError of %q", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestSchemaField(t *testing.T) {
+	tests := []struct {
+		name         string
+		field        SchemaField
+		value        interface{}
+		wantCoerced  interface{}
+		wantRequired bool
+		wantErr      bool
+	}{
+		{"any value", SchemaField{}, "x", "x", false, false},
+		{"coerced", SchemaField{Field: IntField{Required: true}}, "42", int64(42), true, false},
+		{"invalid", SchemaField{Field: IntField{}}, "x", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.field.Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			coerced, err := tt.field.Coerce(tt.value)
+			if (err != nil) != tt.wantErr || (!tt.wantErr && coerced != tt.wantCoerced) {
+				t.Errorf("Coerce() = %#v, %v, want %#v", coerced, err, tt.wantCoerced)
+			}
+			if represented, err := tt.field.Represent(tt.value); err != nil || represented != tt.value {
+				t.Errorf("Represent() = %#v, %v, want the value unchanged", represented, err)
+			}
+			if got := tt.field.IsRequired(); got != tt.wantRequired {
+				t.Errorf("IsRequired() = %v, want %v", got, tt.wantRequired)
+			}
+		})
+	}
+}
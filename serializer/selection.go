@@ -0,0 +1,169 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Selection is a sparse fieldset: the output keys to keep, each with the selection of its nested
+// fields, or nil to keep the whole value. Lists are filtered element by element.
+type Selection map[string]Selection
+
+// selectionKey is the context key under which the request's Selection is stored.
+type selectionKey struct{}
+
+// WithSelection returns a copy of ctx carrying the fields selected for the request. The selection
+// replaces the serializer's Fields list for the call.
+func WithSelection(ctx context.Context, selection Selection) context.Context {
+	return context.WithValue(ctx, selectionKey{}, selection)
+}
+
+// SelectionFromContext returns the Selection carried by ctx, or nil if there is none.
+func SelectionFromContext(ctx context.Context) Selection {
+	selection, _ := ctx.Value(selectionKey{}).(Selection)
+	return selection
+}
+
+// SerializeWithSelection serializes data keeping only the fields of a sparse fieldset expression
+// such as "id,name,address{city,zip}" or, as in ?fields= query parameters, "id,name,address.city".
+// Fields are named as they appear in the output, and the selection replaces the Fields list; an
+// empty expression keeps the serializer's configuration.
+func (s *BaseSerializer) SerializeWithSelection(data interface{}, selection string) (map[string]interface{}, error) {
+	parsed, err := ParseSelection(selection)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if parsed != nil {
+		ctx = WithSelection(ctx, parsed)
+	}
+	return s.SerializeWithContext(ctx, data)
+}
+
+// ParseSelection parses a sparse fieldset expression: a comma-separated list of fields, each
+// optionally followed by the braced selection of its nested fields. Dotted names select nested
+// fields as well, so "address.city" is the same as "address{city}". An empty expression yields nil.
+func ParseSelection(expr string) (Selection, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	p := &selectionParser{expr: expr}
+	selection, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.expr) {
+		return nil, p.errorf("unexpected %q", p.expr[p.pos])
+	}
+	return selection, nil
+}
+
+// selectionParser is a recursive descent parser of sparse fieldset expressions.
+type selectionParser struct {
+	expr string
+	pos  int
+}
+
+// parseList parses fields up to the end of the expression or a closing brace.
+func (p *selectionParser) parseList() (Selection, error) {
+	selection := make(Selection)
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.expr) && !strings.ContainsRune(",{} \t", rune(p.expr[p.pos])) {
+			p.pos++
+		}
+		name := p.expr[start:p.pos]
+		if name == "" {
+			return nil, p.errorf("expected a field name")
+		}
+
+		var nested Selection
+		if p.skipSpace(); p.pos < len(p.expr) && p.expr[p.pos] == '{' {
+			p.pos++
+			var err error
+			if nested, err = p.parseList(); err != nil {
+				return nil, err
+			}
+			if p.pos >= len(p.expr) || p.expr[p.pos] != '}' {
+				return nil, p.errorf("missing '}'")
+			}
+			p.pos++
+		}
+		selection.add(strings.Split(name, "."), nested)
+
+		if p.skipSpace(); p.pos >= len(p.expr) || p.expr[p.pos] != ',' {
+			return selection, nil
+		}
+		p.pos++
+	}
+}
+
+// skipSpace advances past white space.
+func (p *selectionParser) skipSpace() {
+	for p.pos < len(p.expr) && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// errorf returns a validation error for the expression at the current position.
+func (p *selectionParser) errorf(format string, args ...interface{}) error {
+	message := fmt.Sprintf("invalid field selection at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+	return &ValidationError{Field: "fields", Value: p.expr, Message: message, Code: CodeInvalidSelection}
+}
+
+// add selects the field at path with the given nested selection. Selecting a whole value takes
+// precedence over selecting some of its fields.
+func (s Selection) add(path []string, nested Selection) {
+	key := path[0]
+	current, exists := s[key]
+	if exists && current == nil {
+		return
+	}
+	if len(path) == 1 && nested == nil {
+		s[key] = nil
+		return
+	}
+	if current == nil {
+		current = make(Selection)
+		s[key] = current
+	}
+	if len(path) > 1 {
+		current.add(path[1:], nested)
+		return
+	}
+	for nestedKey, value := range nested {
+		current.add([]string{nestedKey}, value)
+	}
+}
+
+// apply returns the parts of a serialized value that are selected.
+func (s Selection) apply(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v // The result of a nil pointer stays nil
+		}
+		selected := make(map[string]interface{}, len(s))
+		for key, nested := range s {
+			item, exists := v[key]
+			if !exists {
+				continue
+			}
+			if nested != nil {
+				item = nested.apply(item)
+			}
+			selected[key] = item
+		}
+		return selected
+	case []interface{}:
+		selected := make([]interface{}, len(v))
+		for i, item := range v {
+			selected[i] = s.apply(item)
+		}
+		return selected
+	default:
+		return value
+	}
+}
@@ -0,0 +1,123 @@
+package serializer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want Selection
+	}{
+		{"empty", "  ", nil},
+		{"flat", "id,name", Selection{"id": nil, "name": nil}},
+		{"braced", "id, address { city , zip }", Selection{"id": nil, "address": {"city": nil, "zip": nil}}},
+		{"dotted", "address.city,address.zip", Selection{"address": {"city": nil, "zip": nil}}},
+		{"dotted and braced", "a.b{c},a.d", Selection{"a": {"b": {"c": nil}, "d": nil}}},
+		{"whole value wins", "address.city,address", Selection{"address": nil}},
+		{"whole value wins when first", "address,address{city}", Selection{"address": nil}},
+		{"nested whole value wins", "a{b.c},a.b", Selection{"a": {"b": nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelection(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSelection() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSelection() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectionInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"trailing comma", "id,"},
+		{"leading comma", ",id"},
+		{"unclosed brace", "address{city"},
+		{"empty braces", "address{}"},
+		{"stray brace", "id}"},
+		{"missing comma", "id name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSelection(tt.expr)
+			if code := ErrorCode(err); code != CodeInvalidSelection {
+				t.Errorf("ParseSelection() error = %v (code %q), want code %q", err, code, CodeInvalidSelection)
+			}
+		})
+	}
+}
+
+func TestSelectionApply(t *testing.T) {
+	selection := Selection{"id": nil, "tags": {"name": nil}, "missing": nil}
+	value := map[string]interface{}{
+		"id":    1,
+		"name":  "a",
+		"tags":  []interface{}{map[string]interface{}{"name": "x", "color": "red"}, "plain"},
+		"other": true,
+	}
+	want := map[string]interface{}{
+		"id":   1,
+		"tags": []interface{}{map[string]interface{}{"name": "x"}, "plain"},
+	}
+	if got := selection.apply(value); !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSerializeWithSelection(t *testing.T) {
+	data := team{
+		Name:    "core",
+		Lead:    account{ID: 1, Name: "ana", Role: "admin"},
+		Members: []account{{ID: 2, Name: "bo", Role: "dev"}},
+	}
+	tests := []struct {
+		name string
+		expr string
+		want map[string]interface{}
+	}{
+		{"top-level", "name", map[string]interface{}{"name": "core"}},
+		{"nested", "lead.name,members{id}", map[string]interface{}{
+			"lead":    map[string]interface{}{"name": "ana"},
+			"members": []interface{}{map[string]interface{}{"id": 2.0}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&BaseSerializer{}).SerializeWithSelection(data, tt.expr)
+			if err != nil {
+				t.Fatalf("SerializeWithSelection() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeWithSelection() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	s := &BaseSerializer{Fields: []string{"name"}}
+	got, err := s.SerializeWithSelection(data, "")
+	if err != nil || !reflect.DeepEqual(got, map[string]interface{}{"name": "core"}) {
+		t.Errorf("SerializeWithSelection() with an empty expression = %#v, %v, want the configured fields", got, err)
+	}
+	if _, err := s.SerializeWithSelection(data, "lead{"); ErrorCode(err) != CodeInvalidSelection {
+		t.Errorf("SerializeWithSelection() error = %v, want code %q", err, CodeInvalidSelection)
+	}
+}
+
+func TestSelectionFromContext(t *testing.T) {
+	if got := SelectionFromContext(context.Background()); got != nil {
+		t.Errorf("SelectionFromContext() = %#v, want nil", got)
+	}
+	selection := Selection{"id": nil}
+	if got := SelectionFromContext(WithSelection(context.Background(), selection)); !reflect.DeepEqual(got, selection) {
+		t.Errorf("SelectionFromContext() = %#v, want %#v", got, selection)
+	}
+}
@@ -1,11 +1,10 @@
 package serializer
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"context"
 	"fmt"
-
-	"gopkg.in/yaml.v3" // YAML library, install using: go get gopkg.in/yaml.v3
+	"reflect"
+	"time"
 )
 
 // Custom error types for better error handling
@@ -22,23 +21,213 @@ type Serializer interface {
 // BaseSerializer is the default implementation of Serializer.
 type BaseSerializer struct {
 	Fields            []string                                     // Included fields
+	ExcludedFields    []string                                     // Fields removed from the output
 	Validations       map[string][]func(interface{}) error         // Multiple validations per field
+	Rules             map[string]string                            // Validation rules by field as compact strings, e.g. "required|min:3|max:50|email" (see ParseRules)
+	Sanitizers        map[string][]func(string) string             // Cleanup of the input strings of fields, applied before validation and deserialization (see StripHTML)
 	Transformations   map[string]func(interface{}) interface{}     // Transformations by field
 	ConditionalFields map[string]func(map[string]interface{}) bool // Conditional inclusion of fields
+
+	ComputedFields map[string]func(data interface{}) (interface{}, error) // Fields added to the output, computed from the serialized data
+	Enums          map[string]EnumField                                   // Allowed values and display labels of enum fields
+	Schema         map[string]Field                                       // Field types validating, coercing and representing each field (see IntField)
+
+	TimeFormat  TimeFormat            // Layout, time zone and accepted input layouts of times, and encoding of durations
+	TimeFormats map[string]TimeFormat // TimeFormat overrides for the times and durations within top-level fields
+
+	UseNumber    bool         // Keep numbers as json.Number in the output and in decoded input, so they round-trip exactly
+	JSONEncoding JSONEncoding // Indentation and HTML escaping of JSON output
+
+	Coerce       bool     // Convert input values to the types of the target's fields on Deserialize (e.g. "42" to an int)
+	CoerceFields []string // Fields coerced on Deserialize when Coerce is not set
+	WeaklyTyped  bool     // Coerce every field leniently on Deserialize: also "on"/"yes" as true and single values as lists
+
+	DecodeHooks []DecodeHook // Conversions applied by Deserialize to the input values before they are stored (see StringToTimeHook)
+
+	ReadOnlyFields  []string // Fields that are serialized but ignored on Deserialize
+	WriteOnlyFields []string // Fields that are accepted on Deserialize but never serialized
+	RejectReadOnly  bool     // Return an error instead of ignoring read-only fields on Deserialize
+
+	FieldPermissions map[string][]string // Roles allowed to see each field (see WithRoles)
+
+	Defaults      map[string]interface{} // Values (or func() interface{} factories) for fields missing on Deserialize and Validate
+	DuplicateKeys DuplicateKeyPolicy     // Handling of duplicate keys in incoming JSON and YAML
+	Fetch         FetchOptions           // HTTP settings for DeserializeFromURL
+
+	KeyNaming    NamingStrategy // Naming convention for output keys (and accepted input keys)
+	ColumnNaming NamingStrategy // Naming convention of SQL columns produced by SQLValues
+	Acronyms     []string       // Acronym dictionary for KeyNaming and ColumnNaming; DefaultAcronyms when nil
+
+	Flatten         bool         // Write nested objects and lists as dotted keys (e.g. "address.city"); Deserialize accepts them
+	EmbeddedStructs EmbeddedMode // Promote the fields of embedded structs (default) or nest them under the struct's name
+
+	FieldMeta   map[string]FieldMeta // Order, group and descriptions of fields for ordered output, schemas and docs
+	FieldGroups []string             // Order of the groups used in FieldMeta
+
+	Profiles map[string]Profile // Output downgrades by client profile (see WithProfile)
+
+	MaxDepth       int                                              // Maximum nesting of objects and lists in the output (the top-level object is 1); deeper values become nil
+	DetectCycles   bool                                             // Replace references back to an enclosing value with a placeholder instead of failing
+	CycleReference func(value interface{}, path string) interface{} // Placeholder for cyclic references; {"$ref": "#<JSON Pointer>"} when nil
+
+	PolymorphicTypes []*PolymorphicType // Interface types serialized with a discriminator and rebuilt on Deserialize
+	Registry         *Registry          // Serializers applied to nested structs and polymorphic values (DefaultRegistry when nil)
+
+	Aliases  map[string]string   // Output keys renamed on Serialize and accepted under the alias on Deserialize
+	Versions map[string][]Option // Overrides applied by Version, keyed by version name
+
+	Hooks Hooks // Callbacks run before and after Serialize and Deserialize
+
+	Workers int // Goroutines used by SerializeMany; elements are serialized sequentially when <= 1
+
+	Translator Translator // Translates validation messages (e.g. DefaultCatalog)
+	Locale     string     // Locale used when the context doesn't carry one (see WithLocale)
+
+	DeprecatedFields    map[string]string // Deprecation messages by field
+	IncludeDeprecations bool              // Add a "_deprecations" block to the serialized output
+	OmitDeprecated      bool              // Leave deprecated fields, and the former keys of RenamedFields, out of the serialized output
+	RenamedFields       map[string]string // Current keys of renamed fields by former key; both are written, and the former is accepted on Deserialize
+
+	TypeInfo    bool   // Add "_type", "_version" and "_types" metadata to the serialized output (see DeserializeAny)
+	TypeVersion string // Value of "_version" when TypeInfo is set
+
+	Envelope *Envelope        // Wraps the output of Serialize as {"data": ..., "meta": ...}; Deserialize and Validate accept both forms
+	JSONAPI  *JSONAPIResource // Resource type, id and relationships used by SerializeJSONAPI
+
+	Links map[string]string // HAL link templates added as "_links", e.g. "self": "/users/{id}"
+
+	Deterministic bool     // Encode the serialized output with sorted keys in every format (see EncodeSorted)
+	Order         []string // Top-level keys written first, in this order, by SerializeAs in every format; the others follow sorted (see EncodeOrdered)
+
+	XMLRoot        string            // Root element of XML output; XMLRootElement when empty
+	XMLItem        string            // Element of list items in XML output and input; XMLItemElement when empty
+	XMLAttributes  []string          // Dotted paths of the scalar fields written and read as XML attributes (e.g. "id", "address.type")
+	XMLNamespaces  map[string]string // Namespaces declared on the XML root element, by prefix; "" is the default namespace
+	XMLDeclaration bool              // Start XML output with an <?xml ...?> declaration
+	XMLSchema      *XMLSchema        // XSD that XML output and input must conform to (see ParseXSD)
+
+	HTMLSafe func(string) string // Applied to every string and key of the output embedded in server-rendered pages, e.g. EscapeHTML or AllowTags
+
+	EncryptedFields []string    // Fields encrypted with AES-GCM on Serialize and decrypted on Deserialize
+	KeyProvider     KeyProvider // Keys of EncryptedFields
+
+	MetadataTransformations   map[string]func(interface{}, *Metadata) interface{}     // Transformations with access to per-call metadata
+	MetadataConditionalFields map[string]func(map[string]interface{}, *Metadata) bool // Conditional fields with access to per-call metadata
+
+	ContextValidations       map[string][]func(context.Context, interface{}) error         // Validations with access to the request context
+	ContextTransformations   map[string]func(context.Context, interface{}) interface{}     // Transformations with access to the request context
+	ContextConditionalFields map[string]func(context.Context, map[string]interface{}) bool // Conditional fields with access to the request context
+
+	Nested      map[string]*BaseSerializer // Serializers validating the objects, or lists of objects, within top-level fields (see ValidateAll)
+	Constraints []Constraint               // Checks of several fields together, run after the validations of single fields (see ExactlyOneOf)
+
+	ValidationTimeout   time.Duration // Deadline shared by the ContextValidations of a call; unlimited when 0
+	ParallelValidations bool          // Run the ContextValidations of different fields concurrently
+
+	SourceConditionalFields map[string]func(source interface{}, result map[string]interface{}) bool // Conditional fields with access to the value being serialized, including unexported state
 }
 
 // Serialize serializes a struct into a map with optional field filtering, transformations, and conditional fields.
+// data is a struct or a map, or a pointer to one; maps go through the same pipeline as structs,
+// with their keys converted to strings as by encoding/json. Slices, arrays and scalars are rejected
+// with an *InputTypeError.
 func (s *BaseSerializer) Serialize(data interface{}) (map[string]interface{}, error) {
-	// Convert struct to JSON
-	jsonData, err := json.Marshal(data)
+	return s.SerializeWithContext(context.Background(), data)
+}
+
+// SerializeWith serializes a struct like Serialize, with opts overriding the configuration for this
+// call only, e.g. SerializeWith(user, WithFields("id", "name")). s isn't modified, so a shared
+// serializer can be customized per request.
+func (s *BaseSerializer) SerializeWith(data interface{}, opts ...Option) (map[string]interface{}, error) {
+	return s.SerializeWithContext(context.Background(), data, opts...)
+}
+
+// SerializeWithMetadata serializes a struct like Serialize, sharing meta with every pipeline stage of the call.
+func (s *BaseSerializer) SerializeWithMetadata(data interface{}, meta *Metadata) (map[string]interface{}, error) {
+	if meta == nil {
+		meta = NewMetadata()
+	}
+	return s.SerializeWithContext(ContextWithMetadata(context.Background(), meta), data)
+}
+
+// SerializeWithContext serializes a struct like Serialize, passing ctx to the context-aware pipeline stages.
+func (s *BaseSerializer) SerializeWithContext(ctx context.Context, data interface{}, opts ...Option) (map[string]interface{}, error) {
+	if len(opts) > 0 {
+		s = s.view(opts)
+	}
+	if s.Envelope == nil {
+		return s.serializeObject(ctx, data)
+	}
+	if MetadataFromContext(ctx) == nil {
+		ctx = ContextWithMetadata(ctx, NewMetadata())
+	}
+	result, err := s.serializeObject(ctx, data)
 	if err != nil {
-		return nil, &SerializationError{Message: fmt.Sprintf("failed to serialize struct: %v", err)}
+		return nil, err
 	}
+	return s.Envelope.Wrap(ctx, data, result)
+}
 
-	// Convert JSON to a map
-	var result map[string]interface{}
-	if err := json.Unmarshal(jsonData, &result); err != nil {
-		return nil, &SerializationError{Message: fmt.Sprintf("failed to convert JSON to map: %v", err)}
+// serializeObject runs the serialization pipeline on data, without the Envelope.
+func (s *BaseSerializer) serializeObject(ctx context.Context, data interface{}) (map[string]interface{}, error) {
+	meta := MetadataFromContext(ctx)
+	if meta == nil {
+		meta = NewMetadata()
+		ctx = ContextWithMetadata(ctx, meta)
+	}
+	if s.Locale != "" && LocaleFromContext(ctx) == "" {
+		ctx = WithLocale(ctx, s.Locale)
+	}
+
+	if s.Hooks.PreSerialize != nil {
+		if err := s.Hooks.PreSerialize(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := s.encodeOptions(ctx)
+	result, err := structToMap(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nest the fields of embedded structs
+	if s.EmbeddedStructs == EmbeddedNest && result != nil {
+		if v := reflect.Indirect(reflect.ValueOf(data)); v.Kind() == reflect.Struct {
+			if err := nestEmbedded(v, result, opts); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Add computed fields
+	if len(s.ComputedFields) > 0 && result != nil {
+		if err := s.computeFields(data, result, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// Expand links while every field is still present
+	var links map[string]interface{}
+	if len(s.Links) > 0 && result != nil {
+		links = s.renderLinks(result)
+	}
+
+	// Replace enum values with their labels
+	if len(s.Enums) > 0 && result != nil {
+		if err := s.labelEnums(result); err != nil {
+			return nil, err
+		}
+	}
+
+	// Represent values of the schema's field types
+	if len(s.Schema) > 0 && result != nil {
+		if err := s.representSchema(result); err != nil {
+			return nil, err
+		}
+		if err := s.localize(ctx, result); err != nil {
+			return nil, err
+		}
 	}
 
 	// Apply transformations
@@ -51,12 +240,41 @@ func (s *BaseSerializer) Serialize(data interface{}) (map[string]interface{}, er
 						Field:   field,
 						Value:   value,
 						Message: "transformation returned nil",
+						Code:    CodeTransformationNil,
 					}
 				}
 				result[field] = transformedValue
 			}
 		}
 	}
+	for field, transform := range s.MetadataTransformations {
+		if value, exists := result[field]; exists {
+			transformedValue := transform(value, meta)
+			if transformedValue == nil {
+				return nil, &TransformationError{
+					Field:   field,
+					Value:   value,
+					Message: "transformation returned nil",
+					Code:    CodeTransformationNil,
+				}
+			}
+			result[field] = transformedValue
+		}
+	}
+	for field, transform := range s.ContextTransformations {
+		if value, exists := result[field]; exists {
+			transformedValue := transform(ctx, value)
+			if transformedValue == nil {
+				return nil, &TransformationError{
+					Field:   field,
+					Value:   value,
+					Message: "transformation returned nil",
+					Code:    CodeTransformationNil,
+				}
+			}
+			result[field] = transformedValue
+		}
+	}
 
 	// Apply conditional fields
 	if s.ConditionalFields != nil {
@@ -66,77 +284,457 @@ func (s *BaseSerializer) Serialize(data interface{}) (map[string]interface{}, er
 			}
 		}
 	}
+	for field, condition := range s.MetadataConditionalFields {
+		if include := condition(result, meta); !include {
+			delete(result, field)
+		}
+	}
+	for field, condition := range s.ContextConditionalFields {
+		if include := condition(ctx, result); !include {
+			delete(result, field)
+		}
+	}
+	for field, condition := range s.SourceConditionalFields {
+		if include := condition(data, result); !include {
+			delete(result, field)
+		}
+	}
+
+	// Remove fields the caller's roles don't allow
+	for field := range s.FieldPermissions {
+		if !s.permitted(ctx, field) {
+			delete(result, field)
+		}
+	}
 
-	// Filter fields if necessary
-	if len(s.Fields) > 0 {
+	// Remove write-only and excluded fields
+	for _, field := range s.WriteOnlyFields {
+		delete(result, field)
+	}
+	fields, excluded := s.Fields, s.ExcludedFields
+	if s.EmbeddedStructs == EmbeddedPromote {
+		// Select embedded structs by name although their fields are promoted
+		fields, excluded = expandEmbedded(data, fields), expandEmbedded(data, excluded)
+	}
+	for _, field := range excluded {
+		delete(result, field)
+	}
+
+	// Filter fields if necessary; a per-request selection replaces Fields
+	selection := SelectionFromContext(ctx)
+	if len(fields) > 0 && selection == nil {
 		filtered := make(map[string]interface{})
-		for _, field := range s.Fields {
+		for _, field := range fields {
+			if containsField(s.WriteOnlyFields, field) || containsField(excluded, field) || !s.permitted(ctx, field) {
+				continue
+			}
 			if value, ok := result[field]; ok {
 				filtered[field] = value
 			} else {
 				filtered[field] = nil // Default to nil if field is missing
 			}
 		}
-		return filtered, nil
+		result = filtered
+	}
+
+	// Encrypt sensitive fields
+	if len(s.EncryptedFields) > 0 {
+		if err := s.encryptFields(ctx, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// Downgrade the output for the client's profile
+	if err := s.applyProfile(ctx, data, result); err != nil {
+		return nil, err
+	}
+
+	// Drop deprecated fields, or keep writing renamed fields under their former keys
+	if s.OmitDeprecated {
+		for field := range s.DeprecatedFields {
+			delete(result, field)
+		}
+	} else if len(s.RenamedFields) > 0 {
+		s.applyRenames(result)
+	}
+
+	// Rename aliased fields
+	if len(s.Aliases) > 0 {
+		s.applyAliases(result)
+	}
+
+	// Rename keys
+	if s.KeyNaming != NamingDefault {
+		result = s.convertKeys(result, reflect.TypeOf(data))
+	}
+
+	// Keep the fields selected for the request
+	if selection != nil {
+		result = selection.apply(result).(map[string]interface{})
+	}
+
+	// Merge nested values into dotted keys
+	if s.Flatten {
+		result = Flatten(result)
+	}
+
+	// Add hypermedia links
+	if len(links) > 0 {
+		result[LinksKey] = links
+	}
+
+	// Report deprecated fields
+	if s.IncludeDeprecations {
+		if deprecations := s.Deprecations(result); len(deprecations) > 0 {
+			result[DeprecationsKey] = deprecations
+		}
+	}
+
+	// Describe the payload's types
+	if s.TypeInfo {
+		s.addTypeInfo(data, result)
+	}
+
+	// Make strings safe to embed in HTML pages
+	if s.HTMLSafe != nil {
+		htmlSafe(result, s.HTMLSafe)
+	}
+
+	if s.Hooks.PostSerialize != nil {
+		if err := s.Hooks.PostSerialize(ctx, data, result); err != nil {
+			return nil, err
+		}
 	}
 
 	return result, nil
 }
 
-// SerializeToXML serializes a struct into an XML string.
-func (s *BaseSerializer) SerializeToXML(data interface{}) (string, error) {
-	xmlData, err := xml.MarshalIndent(data, "", "  ")
+// structToMap converts a struct into a map matching its JSON representation.
+// Struct layouts are reflected once per type and cached (see schemaFor). opts may be nil for
+// the plain encoding/json behavior.
+func structToMap(data interface{}, opts *encodeOptions) (map[string]interface{}, error) {
+	value, err := toJSONValueWith(reflect.ValueOf(data), opts)
 	if err != nil {
-		return "", &SerializationError{Message: fmt.Sprintf("failed to serialize to XML: %v", err)}
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to serialize struct: %v", err), Err: err}
+	}
+	if value == nil {
+		// A nil pointer to a struct or a map is serialized as null, like the value it points to
+		if t := reflect.TypeOf(data); t != nil {
+			if kind := baseType(t).Kind(); kind != reflect.Struct && kind != reflect.Map && kind != reflect.Interface {
+				return nil, newInputTypeError(t)
+			}
+		}
+		return nil, nil
+	}
+
+	result, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, newInputTypeError(reflect.TypeOf(data))
+	}
+	return result, nil
+}
+
+// encodeOptions returns the options used to convert serialized data into maps.
+func (s *BaseSerializer) encodeOptions(ctx context.Context) *encodeOptions {
+	registry := s.registry()
+	if registry.empty() {
+		registry = nil
+	}
+	if s.MaxDepth <= 0 && !s.DetectCycles && len(s.PolymorphicTypes) == 0 && registry == nil && !s.usesTimeFormats() && !s.UseNumber && nativeValues(ctx) == nil {
+		return serializeOptions
+	}
+	var times *TimeFormat
+	if !s.TimeFormat.isZero() {
+		times = &s.TimeFormat
+	}
+	return &encodeOptions{
+		maxDepth:    s.MaxDepth,
+		cycles:      s.DetectCycles,
+		reference:   s.CycleReference,
+		marshalers:  true,
+		polymorphic: s.PolymorphicTypes,
+		registry:    registry,
+		ctx:         ctx,
+		times:       times,
+		fieldTimes:  s.TimeFormats,
+		numbers:     s.UseNumber,
+		native:      nativeValues(ctx),
 	}
-	return string(xmlData), nil
 }
 
-// SerializeToYAML serializes a struct into a YAML string.
-func (s *BaseSerializer) SerializeToYAML(data interface{}) (string, error) {
-	yamlData, err := yaml.Marshal(data)
-	if err != nil {
-		return "", &SerializationError{Message: fmt.Sprintf("failed to serialize to YAML: %v", err)}
+// containsField reports whether field is present in fields.
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
 	}
-	return string(yamlData), nil
+	return false
+}
+
+// SerializeToXML serializes a struct into an XML string. The output of the serialization
+// pipeline is encoded, so Fields, transformations and conditional fields apply as in Serialize.
+// The document has an XMLRoot root element, one element per key and an XMLItem element per
+// list element.
+func (s *BaseSerializer) SerializeToXML(data interface{}) (string, error) {
+	encoded, err := s.SerializeAs(data, FormatXML)
+	return string(encoded), err
+}
+
+// SerializeToYAML serializes a struct into a YAML string. The output of the serialization
+// pipeline is encoded, so Fields, transformations and conditional fields apply as in Serialize.
+func (s *BaseSerializer) SerializeToYAML(data interface{}) (string, error) {
+	encoded, err := s.SerializeAs(data, FormatYAML)
+	return string(encoded), err
 }
 
 // Deserialize deserializes a map into a struct.
 func (s *BaseSerializer) Deserialize(input map[string]interface{}, out interface{}) error {
-	jsonData, err := json.Marshal(input)
+	return s.DeserializeWithContext(context.Background(), input, out)
+}
+
+// deserialize fills out from input, applying key naming, read-only fields and defaults.
+func (s *BaseSerializer) deserialize(ctx context.Context, input map[string]interface{}, out interface{}) error {
+	dst := getMap()
+	defer putMap(dst)
+	return s.deserializeInto(ctx, input, out, dst)
+}
+
+// needsPreparation reports whether deserialize does anything to an input besides decoding it.
+// When it doesn't, callers such as BulkDeserializer may decode inputs as they are. Settings read by
+// deserialize and prepareInput must be added here.
+func (s *BaseSerializer) needsPreparation() bool {
+	return s.Envelope != nil || s.Flatten || s.EmbeddedStructs == EmbeddedNest ||
+		len(s.RenamedFields) > 0 || len(s.DeprecatedFields) > 0 || len(s.Aliases) > 0 ||
+		s.KeyNaming != NamingDefault || len(s.EncryptedFields) > 0 || len(s.ReadOnlyFields) > 0 ||
+		len(s.Defaults) > 0 || len(s.Sanitizers) > 0 || len(s.Enums) > 0 || len(s.Schema) > 0 ||
+		s.Coerce || s.WeaklyTyped || len(s.CoerceFields) > 0 || s.usesTimeFormats() ||
+		len(s.PolymorphicTypes) > 0
+}
+
+// deserializeInto is deserialize, writing the prepared input into dst, an empty map.
+func (s *BaseSerializer) deserializeInto(ctx context.Context, input map[string]interface{}, out interface{}, dst map[string]interface{}) error {
+	writable, err := s.prepareInput(ctx, s.sanitizeInput(input), reflect.TypeOf(out), dst)
 	if err != nil {
-		return &SerializationError{Message: fmt.Sprintf("failed to convert map to JSON: %v", err)}
+		return err
 	}
-	if err := json.Unmarshal(jsonData, out); err != nil {
-		return &SerializationError{Message: fmt.Sprintf("failed to deserialize JSON to struct: %v", err)}
+	if err := s.unlabelEnums(ctx, writable); err != nil {
+		return err
 	}
-	return nil
+	if err := s.coerceSchema(writable); err != nil {
+		return err
+	}
+	if s.Coerce || s.WeaklyTyped || len(s.CoerceFields) > 0 {
+		if err := s.coerceInput(writable, reflect.TypeOf(out)); err != nil {
+			return err
+		}
+	}
+	if s.usesTimeFormats() {
+		if err := s.parseTimes(writable, reflect.TypeOf(out)); err != nil {
+			return err
+		}
+	}
+	if len(s.PolymorphicTypes) > 0 {
+		return s.decodePolymorphic(writable, out)
+	}
+	return s.decodeMap(writable, out)
+}
+
+// prepareInput applies key naming, read-only fields and defaults to an input map for target type t,
+// writing the result into dst.
+func (s *BaseSerializer) prepareInput(ctx context.Context, input map[string]interface{}, t reflect.Type, dst map[string]interface{}) (map[string]interface{}, error) {
+	if s.Envelope != nil {
+		input = s.Envelope.Unwrap(input)
+	}
+	if s.Flatten {
+		unflattened, err := Unflatten(input)
+		if err != nil {
+			return nil, err
+		}
+		input = unflattened
+	}
+	if s.EmbeddedStructs == EmbeddedNest {
+		input = s.promoteEmbedded(input, t)
+	}
+
+	// Move the former keys of renamed fields to the current ones, and warn about deprecated fields
+	if len(s.RenamedFields) > 0 || len(s.DeprecatedFields) > 0 {
+		input = s.migrateInput(input, WarningsFromContext(ctx))
+	}
+
+	// Match aliased and renamed keys to the target's fields
+	if len(s.Aliases) > 0 {
+		input = s.restoreAliases(input)
+	}
+	if s.KeyNaming != NamingDefault {
+		input = s.restoreKeys(input, t).(map[string]interface{})
+	}
+
+	// Keys are matched to the target's fields case-insensitively, as the decoder does, so the
+	// checks below apply whatever the case of the key
+	schema := inputFields(t)
+	for key, value := range input {
+		field, ignored := fieldKey(schema, input, key)
+		if ignored {
+			continue
+		}
+
+		// Handle read-only fields
+		if containsField(s.ReadOnlyFields, field) {
+			if s.RejectReadOnly {
+				return nil, &ValidationError{Field: field, Value: value, Message: s.translate(ctx, CodeReadOnly, nil, "field is read-only"), Code: CodeReadOnly}
+			}
+			continue
+		}
+		dst[field] = value
+	}
+
+	// Decrypt once keys are matched to fields, so that no case of a key skips decryption
+	if len(s.EncryptedFields) > 0 {
+		if err := s.decryptFields(ctx, dst); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply defaults for missing fields
+	for field, value := range s.Defaults {
+		if _, exists := dst[field]; exists {
+			continue
+		}
+		dst[field] = defaultValue(value)
+	}
+
+	return dst, nil
+}
+
+// applyDefaults returns data with the Defaults of the fields it lacks, so that validation sees the
+// values Deserialize will store. data is copied when a default is added.
+func (s *BaseSerializer) applyDefaults(data map[string]interface{}) map[string]interface{} {
+	var defaulted map[string]interface{}
+	for field, value := range s.Defaults {
+		if _, exists := data[field]; exists {
+			continue
+		}
+		if defaulted == nil {
+			defaulted = make(map[string]interface{}, len(data)+len(s.Defaults))
+			for key, value := range data {
+				defaulted[key] = value
+			}
+		}
+		defaulted[field] = defaultValue(value)
+	}
+	if defaulted == nil {
+		return data
+	}
+	return defaulted
+}
+
+// defaultValue returns a value of Defaults, calling it when it is a factory.
+func defaultValue(value interface{}) interface{} {
+	if factory, ok := value.(func() interface{}); ok {
+		return factory()
+	}
+	return value
+}
+
+// decodeMap converts a map into a struct through its MapDecoder, or by reflection with the
+// DecodeHooks of s.
+func (s *BaseSerializer) decodeMap(input map[string]interface{}, out interface{}) error {
+	if decoder, ok := out.(MapDecoder); ok {
+		return decoder.DecodeMap(input)
+	}
+	return s.decoder().decode(input, out)
+}
+
+// decoder returns the reflection-based decoder of Deserialize.
+func (s *BaseSerializer) decoder() reflectDecoder {
+	return reflectDecoder{hooks: s.DecodeHooks}
 }
 
 // Validate checks the provided data against the validations defined in the serializer.
 func (s *BaseSerializer) Validate(data map[string]interface{}) error {
-	if s.Validations == nil {
-		return nil // No validations defined
-	}
+	return s.ValidateWithContext(context.Background(), data)
+}
+
+// ValidateWithContext checks the provided data like Validate, also running the context-aware validations.
+func (s *BaseSerializer) ValidateWithContext(ctx context.Context, data map[string]interface{}) error {
+	var first error
+	s.validate(ctx, data, func(err error) bool {
+		first = err
+		return false
+	})
+	return first
+}
 
+// validate runs every validation of data, passing each failure to report, and stops when report
+// returns false.
+func (s *BaseSerializer) validate(ctx context.Context, data map[string]interface{}, report func(error) bool) {
+	if s.Envelope != nil {
+		data = s.Envelope.Unwrap(data)
+	}
+	data = s.sanitizeInput(data)
+	if len(s.RenamedFields) > 0 {
+		data = s.migrateInput(data, nil) // Deserialize reports the deprecation warnings
+	}
+	data = s.applyDefaults(data)
 	for field, validations := range s.Validations {
-		if value, exists := data[field]; exists {
-			for _, validation := range validations {
-				if err := validation(value); err != nil {
-					return &ValidationError{
-						Field:   field,
-						Value:   value,
-						Message: err.Error(),
-					}
+		if err := s.validateField(ctx, data, field, validations); err != nil && !report(err) {
+			return
+		}
+	}
+	for field, rules := range s.Rules {
+		validations, err := rulesValidations(rules)
+		if err == nil {
+			err = s.validateField(ctx, data, field, validations)
+		}
+		if err != nil && !report(err) {
+			return
+		}
+	}
+
+	if !s.validateSchema(ctx, data, report) {
+		return
+	}
+
+	for field, enum := range s.Enums {
+		if value, exists := data[field]; exists && value != nil {
+			if err := enum.Validate(value); err != nil {
+				if err := s.validationFailure(ctx, field, value, err); err != nil && !report(err) {
+					return
 				}
 			}
-		} else {
-			return &ValidationError{
-				Field:   field,
-				Message: "field is missing",
-			}
 		}
 	}
 
+	if err := s.validateContextFields(ctx, data); err != nil && !report(err) {
+		return
+	}
+	if !s.validateNested(ctx, data, report) {
+		return
+	}
+	s.validateConstraints(ctx, data, report)
+}
+
+// validateField runs the validations of a field, which must be present in data unless they are
+// all Optional.
+func (s *BaseSerializer) validateField(ctx context.Context, data map[string]interface{}, field string, validations []func(interface{}) error) error {
+	value, exists := data[field]
+	if !exists {
+		if optional(validations) {
+			return nil
+		}
+		return &ValidationError{
+			Field:   field,
+			Message: s.translate(ctx, CodeRequired, nil, "field is missing"),
+			Code:    CodeRequired,
+		}
+	}
+	for _, validation := range validations {
+		if err := validation(value); err != nil {
+			if err := s.validationFailure(ctx, field, value, err); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,158 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type account struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func TestDeserializeReadOnlyFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  map[string]interface{}
+		reject bool
+		want   account
+		field  string // Field of the expected ValidationError, if any
+	}{
+		{"ignored", map[string]interface{}{"id": 99, "name": "a", "role": "admin"}, false, account{Name: "a"}, ""},
+		{"ignored in another case", map[string]interface{}{"ID": 99, "Name": "a", "Role": "admin"}, false, account{Name: "a"}, ""},
+		{"rejected", map[string]interface{}{"name": "a", "role": "admin"}, true, account{}, "role"},
+		{"rejected in another case", map[string]interface{}{"name": "a", "ROLE": "admin"}, true, account{}, "role"},
+		{"exact key wins", map[string]interface{}{"name": "a", "Name": "b"}, false, account{Name: "a"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{ReadOnlyFields: []string{"id", "role"}, RejectReadOnly: tt.reject}
+			var got account
+			err := s.Deserialize(tt.input, &got)
+			if tt.field != "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.field || validationErr.Code != CodeReadOnly {
+					t.Fatalf("Deserialize() error = %v, want read-only error on %q", err, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Deserialize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	s := &BaseSerializer{
+		Validations: map[string][]func(interface{}) error{"role": {NotEmpty}, "name": {NotEmpty}},
+		Defaults:    map[string]interface{}{"role": "member", "name": func() interface{} { return "" }},
+	}
+	tests := []struct {
+		name    string
+		input   map[string]interface{}
+		wantErr string // Field of the expected ValidationError, if any
+	}{
+		{"defaults satisfy required fields", map[string]interface{}{"name": "ana"}, ""},
+		{"input wins over defaults", map[string]interface{}{"name": "ana", "role": ""}, "role"},
+		{"factory defaults are validated", map[string]interface{}{"role": "admin"}, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make(map[string]interface{}, len(tt.input))
+			for key, value := range tt.input {
+				input[key] = value
+			}
+			err := s.Validate(input)
+			var validationErr *ValidationError
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() error = %v", err)
+			case tt.wantErr != "" && (!errors.As(err, &validationErr) || validationErr.Field != tt.wantErr):
+				t.Errorf("Validate() error = %v, want an error on %q", err, tt.wantErr)
+			}
+			if len(input) != len(tt.input) {
+				t.Errorf("Validate() modified its input: %v", input)
+			}
+		})
+	}
+}
+
+func TestDeserializeTypeMismatch(t *testing.T) {
+	type record struct {
+		ID    int64 `json:"id"`
+		Count int   `json:"count"`
+	}
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+	}{
+		{"string into integer", map[string]interface{}{"id": "abc"}},
+		{"fraction into integer", map[string]interface{}{"count": 1.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got record
+			err := (&BaseSerializer{}).Deserialize(tt.input, &got)
+			if !errors.Is(err, ErrTypeMismatch) || ErrorCode(err) != CodeInvalidType {
+				t.Errorf("Deserialize() error = %v, want one matching ErrTypeMismatch with code %q", err, CodeInvalidType)
+			}
+		})
+	}
+}
+
+type sourceAccount struct {
+	ID       int    `json:"id"`
+	Email    string `json:"email"`
+	Balance  int    `json:"balance"`
+	verified bool
+	owner    string
+}
+
+func (a sourceAccount) OwnedBy(user string) bool { return a.owner == user }
+
+func TestSourceConditionalFields(t *testing.T) {
+	verifiedOnly := func(source interface{}, _ map[string]interface{}) bool {
+		return source.(*sourceAccount).verified
+	}
+	ownerOnly := func(source interface{}, result map[string]interface{}) bool {
+		return source.(*sourceAccount).OwnedBy("ana") && result["balance"] != nil
+	}
+	tests := []struct {
+		name string
+		s    Serializer
+		data *sourceAccount
+		want []string
+	}{
+		{"unexported state shows fields", &BaseSerializer{SourceConditionalFields: map[string]func(interface{}, map[string]interface{}) bool{"email": verifiedOnly, "balance": ownerOnly}},
+			&sourceAccount{ID: 1, Email: "a@example.com", Balance: 5, verified: true, owner: "ana"}, []string{"balance", "email", "id"}},
+		{"unexported state hides fields", &BaseSerializer{SourceConditionalFields: map[string]func(interface{}, map[string]interface{}) bool{"email": verifiedOnly, "balance": ownerOnly}},
+			&sourceAccount{ID: 1, Email: "a@example.com", Balance: 5, owner: "bob"}, []string{"id"}},
+		{"builder", NewSerializer().SourceCondition("email", verifiedOnly).SourceCondition("balance", ownerOnly).Build(),
+			&sourceAccount{ID: 1, Email: "a@example.com", Balance: 5, verified: true, owner: "bob"}, []string{"email", "id"}},
+		{"builder hides fields", NewSerializer().SourceCondition("email", verifiedOnly).Build(),
+			&sourceAccount{ID: 1, Email: "a@example.com"}, []string{"balance", "id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.Serialize(tt.data)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			keys := make([]string, 0, len(got))
+			for key := range got {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("Serialize() = %v, want the fields %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package serializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// Rows is the part of *sql.Rows used to read query results. Rows are not closed by the serializer.
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// SerializeRows reads every row of a query result, keyed by column name, and runs it through
+// validations and the serialization pipeline. []byte column values are read as strings.
+// Failures are reported as a *BulkError carrying the index of the row.
+func (s *BaseSerializer) SerializeRows(rows Rows) ([]map[string]interface{}, error) {
+	return s.SerializeRowsWithContext(context.Background(), rows)
+}
+
+// SerializeRowsWithContext is like SerializeRows, passing ctx to the context-aware pipeline stages.
+func (s *BaseSerializer) SerializeRowsWithContext(ctx context.Context, rows Rows) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	err := eachRow(rows, true, func(row map[string]interface{}) error {
+		if err := s.ValidateWithContext(ctx, row); err != nil {
+			return err
+		}
+		result, err := s.serializeObject(ctx, row)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
+
+// ScanRows reads every row of a query result into a value of type T, mapping column names to
+// fields like Deserialize maps keys (JSON tags, the serializer's KeyNaming, Defaults and read-only
+// fields apply). Failures are reported as a *BulkError carrying the index of the row.
+func ScanRows[T any](s *BaseSerializer, rows Rows) ([]T, error) {
+	var results []T
+	err := eachRow(rows, false, func(row map[string]interface{}) error {
+		var out T
+		if err := s.Deserialize(row, &out); err != nil {
+			return err
+		}
+		results = append(results, out)
+		return nil
+	})
+	return results, err
+}
+
+// eachRow scans the rows into maps and calls fn for each one. With plain, values are converted to
+// their JSON representation like serialized structs; otherwise they are kept as scanned, so 64-bit
+// integers keep their precision when decoded into structs.
+func eachRow(rows Rows, plain bool, fn func(row map[string]interface{}) error) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return &SerializationError{Message: fmt.Sprintf("failed to read columns: %v", err), Err: err}
+	}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for index := 0; rows.Next(); index++ {
+		if err := rows.Scan(dest...); err != nil {
+			return &BulkError{Index: index, Err: &SerializationError{Message: fmt.Sprintf("failed to scan row: %v", err), Err: err}}
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			value := values[i]
+			if b, ok := value.([]byte); ok {
+				value = string(b) // Drivers reuse the buffer between rows
+			}
+			if plain {
+				converted, err := toJSONValue(reflect.ValueOf(value))
+				if err != nil {
+					return &BulkError{Index: index, Err: &SerializationError{Message: fmt.Sprintf("failed to convert column '%s': %v", column, err)}}
+				}
+				value = converted
+			}
+			row[column] = value
+		}
+		if err := fn(row); err != nil {
+			return &BulkError{Index: index, Err: err}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return &SerializationError{Message: fmt.Sprintf("failed to read rows: %v", err), Err: err}
+	}
+	return nil
+}
+
+// sqlIdentifierPattern matches the column names SQLValues returns, so that they can be written
+// into statements unquoted.
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLValues validates input and converts it into column names and arguments for an INSERT or
+// UPDATE statement, sorted by column. Only declared fields become columns: those of Fields when it
+// is set, and otherwise those with Validations, Rules, ContextValidations, a Schema or Defaults.
+// Other keys are rejected with CodeInvalidKey, as are column names that aren't plain SQL
+// identifiers, since clients choose the keys. Keys are renamed with ColumnNaming, read-only fields
+// are dropped (or rejected with RejectReadOnly), Defaults fill missing columns, objects and lists
+// are encoded as JSON, and whole numbers are passed as int64.
+func (s *BaseSerializer) SQLValues(input map[string]interface{}) ([]string, []interface{}, error) {
+	if err := s.Validate(input); err != nil {
+		return nil, nil, err
+	}
+
+	readOnly := make(map[string]bool, len(s.ReadOnlyFields))
+	for _, field := range s.ReadOnlyFields {
+		readOnly[s.columnName(field)] = true
+	}
+	declared := s.sqlFields()
+
+	values := make(map[string]interface{}, len(input)+len(s.Defaults))
+	for _, key := range objectKeys(input) {
+		value := input[key]
+		if containsField(s.ExcludedFields, key) {
+			continue
+		}
+		column := s.columnName(key)
+		if readOnly[column] {
+			if s.RejectReadOnly {
+				return nil, nil, &ValidationError{Field: key, Value: value, Message: s.translate(context.Background(), CodeReadOnly, nil, "field is read-only"), Code: CodeReadOnly}
+			}
+			continue
+		}
+		if !declared[key] {
+			return nil, nil, &ValidationError{Field: key, Message: "field is not allowed", Code: CodeInvalidKey}
+		}
+		values[column] = value
+	}
+	for field, value := range s.Defaults {
+		column := s.columnName(field)
+		if _, exists := values[column]; exists || readOnly[column] || !declared[field] {
+			continue
+		}
+		values[column] = defaultValue(value)
+	}
+
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		if !sqlIdentifierPattern.MatchString(column) {
+			return nil, nil, &ValidationError{Field: column, Message: "field is not a valid column name", Code: CodeInvalidKey}
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		arg, err := sqlArg(values[column])
+		if err != nil {
+			return nil, nil, &SerializationError{Message: fmt.Sprintf("failed to convert column '%s': %v", column, err)}
+		}
+		args[i] = arg
+	}
+	return columns, args, nil
+}
+
+// sqlFields returns the fields SQLValues writes: Fields when set, and otherwise the fields with
+// validations, a Schema or Defaults.
+func (s *BaseSerializer) sqlFields() map[string]bool {
+	fields := make(map[string]bool)
+	if len(s.Fields) > 0 {
+		for _, field := range s.Fields {
+			fields[field] = true
+		}
+		return fields
+	}
+	for field := range s.Validations {
+		fields[field] = true
+	}
+	for field := range s.Rules {
+		fields[field] = true
+	}
+	for field := range s.ContextValidations {
+		fields[field] = true
+	}
+	for field := range s.Schema {
+		fields[field] = true
+	}
+	for field := range s.Defaults {
+		fields[field] = true
+	}
+	return fields
+}
+
+// columnName converts a key to a column name using ColumnNaming.
+func (s *BaseSerializer) columnName(key string) string {
+	return ConvertKey(key, s.ColumnNaming, s.Acronyms)
+}
+
+// sqlArg converts a decoded value into a database/sql argument.
+func sqlArg(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(encoded), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+			return int64(v), nil
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,162 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeRows is a query result held in memory.
+type fakeRows struct {
+	columns []string
+	rows    [][]interface{}
+	next    int
+	scanErr error
+	err     error
+}
+
+func (r *fakeRows) Columns() ([]string, error) { return r.columns, nil }
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	if r.scanErr != nil {
+		return r.scanErr
+	}
+	for i, value := range r.rows[r.next-1] {
+		*dest[i].(*interface{}) = value
+	}
+	return nil
+}
+
+func (r *fakeRows) Err() error { return r.err }
+
+func TestSerializeRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         *BaseSerializer
+		rows      *fakeRows
+		want      []map[string]interface{}
+		wantIndex int // Index of the BulkError, or -1
+		wantErr   bool
+	}{
+		{"rows", &BaseSerializer{ExcludedFields: []string{"secret"}}, &fakeRows{columns: []string{"id", "name", "secret"}, rows: [][]interface{}{
+			{int64(1), []byte("ana"), "x"}, {int64(2), nil, "y"},
+		}}, []map[string]interface{}{{"id": 1.0, "name": "ana"}, {"id": 2.0, "name": nil}}, -1, false},
+		{"validation failure", &BaseSerializer{Validations: map[string][]func(interface{}) error{"id": {Positive}}}, &fakeRows{columns: []string{"id"}, rows: [][]interface{}{
+			{int64(1)}, {int64(0)},
+		}}, nil, 1, false},
+		{"scan failure", &BaseSerializer{}, &fakeRows{columns: []string{"id"}, rows: [][]interface{}{{int64(1)}}, scanErr: errors.New("bad column")}, nil, 0, false},
+		{"rows failure", &BaseSerializer{}, &fakeRows{columns: []string{"id"}, err: errors.New("connection lost")}, nil, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.SerializeRows(tt.rows)
+			switch {
+			case tt.wantIndex >= 0:
+				var bulkErr *BulkError
+				if !errors.As(err, &bulkErr) || bulkErr.Index != tt.wantIndex {
+					t.Errorf("SerializeRows() error = %v, want a failure of row %d", err, tt.wantIndex)
+				}
+			case tt.wantErr:
+				if err == nil {
+					t.Errorf("SerializeRows() error = nil, want an error")
+				}
+			case err != nil:
+				t.Errorf("SerializeRows() error = %v", err)
+			case !reflect.DeepEqual(got, tt.want):
+				t.Errorf("SerializeRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanRows(t *testing.T) {
+	type user struct {
+		ID       int64  `json:"id"`
+		FullName string `json:"full_name"`
+		Role     string `json:"role"`
+	}
+	s := &BaseSerializer{Defaults: map[string]interface{}{"role": "member"}}
+	got, err := ScanRows[user](s, &fakeRows{columns: []string{"id", "full_name"}, rows: [][]interface{}{
+		{int64(9007199254740993), []byte("Ana")},
+	}})
+	if err != nil {
+		t.Fatalf("ScanRows() error = %v", err)
+	}
+	if want := []user{{ID: 9007199254740993, FullName: "Ana", Role: "member"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanRows() = %+v, want %+v", got, want)
+	}
+
+	_, err = ScanRows[user](s, &fakeRows{columns: []string{"id"}, rows: [][]interface{}{{int64(1)}, {"x"}}})
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 {
+		t.Errorf("ScanRows() error = %v, want a failure of row 1", err)
+	}
+}
+
+func TestSQLValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           *BaseSerializer
+		input       map[string]interface{}
+		wantColumns []string
+		wantArgs    []interface{}
+		wantCode    string
+	}{
+		{"values", &BaseSerializer{ColumnNaming: NamingSnakeCase, Fields: []string{"fullName", "age", "ratio", "tags", "meta"}}, map[string]interface{}{
+			"fullName": "ana", "age": 42.0, "ratio": 0.5, "tags": []interface{}{"a"}, "meta": map[string]interface{}{"k": 1.0},
+		}, []string{"age", "full_name", "meta", "ratio", "tags"}, []interface{}{int64(42), "ana", `{"k":1}`, 0.5, `["a"]`}, ""},
+		{"read-only, excluded and defaults", &BaseSerializer{Fields: []string{"id", "name", "role", "created"}, ReadOnlyFields: []string{"id"}, ExcludedFields: []string{"secret"}, Defaults: map[string]interface{}{
+			"role": "member", "id": 1, "created": func() interface{} { return "now" },
+		}}, map[string]interface{}{"id": 7.0, "secret": "x", "name": "ana"}, []string{"created", "name", "role"}, []interface{}{"now", "ana", "member"}, ""},
+		{"rejected read-only", &BaseSerializer{ReadOnlyFields: []string{"id"}, RejectReadOnly: true}, map[string]interface{}{"id": 7.0}, nil, nil, CodeReadOnly},
+		{"invalid", &BaseSerializer{Validations: map[string][]func(interface{}) error{"name": {NotEmpty}}}, map[string]interface{}{"name": ""}, nil, nil, CodeEmpty},
+		{"validated fields", &BaseSerializer{Validations: map[string][]func(interface{}) error{"name": {NotEmpty}}, Defaults: map[string]interface{}{"role": "member"}},
+			map[string]interface{}{"name": "ana"}, []string{"name", "role"}, []interface{}{"ana", "member"}, ""},
+		{"undeclared field", &BaseSerializer{Validations: map[string][]func(interface{}) error{"name": {NotEmpty}}}, map[string]interface{}{"name": "ana", "admin": true}, nil, nil, CodeInvalidKey},
+		{"hostile key", &BaseSerializer{Fields: []string{"name"}}, map[string]interface{}{"name": "x", "id) VALUES (1); DROP TABLE users; --": 1.0}, nil, nil, CodeInvalidKey},
+		{"invalid column name", &BaseSerializer{Fields: []string{"full name"}}, map[string]interface{}{"full name": "ana"}, nil, nil, CodeInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, args, err := tt.s.SQLValues(tt.input)
+			if tt.wantCode != "" {
+				if ErrorCode(err) != tt.wantCode {
+					t.Errorf("SQLValues() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SQLValues() error = %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.wantColumns) || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("SQLValues() = %q, %#v, want %q, %#v", columns, args, tt.wantColumns, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSQLArg(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{1.0, int64(1)},
+		{1.5, 1.5},
+		{1e300, 1e300},
+		{json.Number("12345678901234567"), int64(12345678901234567)},
+		{json.Number("1.25"), 1.25},
+		{"x", "x"},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got, err := sqlArg(tt.value); err != nil || got != tt.want {
+			t.Errorf("sqlArg(%v) = %#v, %v, want %#v", tt.value, got, err, tt.want)
+		}
+	}
+}
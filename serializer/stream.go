@@ -0,0 +1,64 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// SerializeTo serializes data and writes it to w in the given format, e.g. straight into an HTTP
+// response or a file. opts override the configuration for this call only.
+func (s *BaseSerializer) SerializeTo(w io.Writer, data interface{}, format Format, opts ...Option) error {
+	return s.SerializeToWithContext(context.Background(), w, data, format, opts...)
+}
+
+// SerializeToWithContext is like SerializeTo, passing ctx to the context-aware pipeline stages.
+// JSON is encoded directly into w and, as with json.Encoder, ends with a newline.
+func (s *BaseSerializer) SerializeToWithContext(ctx context.Context, w io.Writer, data interface{}, format Format, opts ...Option) error {
+	if len(opts) > 0 {
+		s = s.view(opts)
+	}
+	if format == FormatJSON && !s.Deterministic && len(s.Order) == 0 {
+		result, err := s.SerializeWithContext(ctx, data)
+		if err != nil {
+			return err
+		}
+		if err := s.JSONEncoding.newEncoder(w).Encode(result); err != nil {
+			return &SerializationError{Message: fmt.Sprintf("failed to write JSON: %v", err), Err: err}
+		}
+		return nil
+	}
+
+	encoded, err := s.SerializeAsWithContext(ctx, data, format)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(encoded); err != nil {
+		return &SerializationError{Message: fmt.Sprintf("failed to write %s: %v", format, err)}
+	}
+	return nil
+}
+
+// DeserializeFrom reads a document in the given format from r and deserializes it into out. JSON
+// and gob are decoded as they are read; other formats are read in full first. r is read to the
+// end, so limit untrusted input, e.g. with http.MaxBytesReader.
+func (s *BaseSerializer) DeserializeFrom(r io.Reader, out interface{}, format Format) error {
+	var input map[string]interface{}
+	var err error
+	switch format {
+	case FormatJSON:
+		input, err = s.decodeJSONFrom(r)
+	case FormatGob:
+		input, err = decodeGobFrom(r)
+	default:
+		data, readErr := io.ReadAll(r)
+		if readErr != nil {
+			return &SerializationError{Message: fmt.Sprintf("failed to read %s: %v", format, readErr)}
+		}
+		return s.DeserializeAs(data, format, out)
+	}
+	if err != nil {
+		return err
+	}
+	return s.Deserialize(input, out)
+}
@@ -0,0 +1,81 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestSerializeTo(t *testing.T) {
+	data := account{ID: 7, Name: "<ana>", Role: "admin"}
+	tests := []struct {
+		name   string
+		s      *BaseSerializer
+		format Format
+		opts   []Option
+		want   string
+	}{
+		{"JSON", &BaseSerializer{}, FormatJSON, nil, "{\"id\":7,\"name\":\"\\u003cana\\u003e\",\"role\":\"admin\"}\n"},
+		{"JSON with options", &BaseSerializer{}, FormatJSON, []Option{WithFields("id")}, "{\"id\":7}\n"},
+		{"JSON encoding", &BaseSerializer{JSONEncoding: JSONEncoding{Indent: " "}}, FormatJSON, []Option{WithFields("id")}, "{\n \"id\": 7\n}\n"},
+		{"ordered JSON", &BaseSerializer{Order: []string{"role"}}, FormatJSON, nil, "{\"role\":\"admin\",\"id\":7,\"name\":\"\\u003cana\\u003e\"}"},
+		{"YAML", &BaseSerializer{}, FormatYAML, []Option{WithFields("id")}, "id: 7\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.s.SerializeTo(&buf, data, tt.format, tt.opts...); err != nil {
+				t.Fatalf("SerializeTo() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("SerializeTo() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		var serializationErr *SerializationError
+		if err := (&BaseSerializer{}).SerializeTo(failingWriter{}, data, format); !errors.As(err, &serializationErr) || !strings.Contains(err.Error(), "disk full") {
+			t.Errorf("SerializeTo() %s error = %v, want a SerializationError", format, err)
+		}
+	}
+}
+
+func TestDeserializeFrom(t *testing.T) {
+	s := &BaseSerializer{}
+	want := account{ID: 7, Name: "ana", Role: "admin"}
+	var gob bytes.Buffer
+	if err := s.SerializeTo(&gob, want, FormatGob); err != nil {
+		t.Fatalf("SerializeTo() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		input   string
+		format  Format
+		wantErr bool
+	}{
+		{"JSON", `{"id":7,"name":"ana","role":"admin"}`, FormatJSON, false},
+		{"YAML", "id: 7\nname: ana\nrole: admin\n", FormatYAML, false},
+		{"gob", gob.String(), FormatGob, false},
+		{"invalid JSON", `{"id":`, FormatJSON, true},
+		{"invalid gob", "not gob", FormatGob, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got account
+			err := s.DeserializeFrom(strings.NewReader(tt.input), &got, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeserializeFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != want {
+				t.Errorf("DeserializeFrom() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package serializer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ValidateStruct runs the serializer's validations against a struct without serializing it first.
+// Validations may be keyed by JSON key or by Go field name; every field is present, so only keys
+// that match no field are reported as missing. Values are passed to the validations in their
+// serialized form (e.g. numbers as float64), like Validate receives them.
+func (s *BaseSerializer) ValidateStruct(data interface{}) error {
+	return s.ValidateStructWithContext(context.Background(), data)
+}
+
+// ValidateStructWithContext is like ValidateStruct, also running the context-aware validations.
+func (s *BaseSerializer) ValidateStructWithContext(ctx context.Context, data interface{}) error {
+	v := indirectValue(reflect.ValueOf(data))
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return &SerializationError{Message: fmt.Sprintf("%T is not a struct", data)}
+	}
+
+	values := make(map[string]interface{})
+	goNames := make(map[string]interface{})
+	for _, field := range schemaFor(v.Type()).fields {
+		fieldValue, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			continue // Promoted through a nil embedded pointer
+		}
+		value, err := toJSONValueWith(fieldValue, serializeOptions)
+		if err != nil {
+			return &SerializationError{Message: fmt.Sprintf("failed to serialize field '%s': %v", field.name, err)}
+		}
+		values[field.name] = value
+		goNames[v.Type().FieldByIndex(field.index).Name] = value
+	}
+	for name, value := range goNames {
+		if _, exists := values[name]; !exists {
+			values[name] = value
+		}
+	}
+
+	return s.ValidateWithContext(ctx, values)
+}
@@ -0,0 +1,53 @@
+package serializer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateStruct(t *testing.T) {
+	notEmpty := func(value interface{}) error {
+		if value == "" {
+			return errors.New("must not be empty")
+		}
+		return nil
+	}
+	tests := []struct {
+		name        string
+		validations map[string][]func(interface{}) error
+		data        interface{}
+		wantField   string
+		wantCode    string
+	}{
+		{"valid", map[string][]func(interface{}) error{"name": {notEmpty}, "id": {Positive}}, account{ID: 1, Name: "ana"}, "", ""},
+		{"by JSON key", map[string][]func(interface{}) error{"name": {notEmpty}}, account{ID: 1}, "name", CodeInvalid},
+		{"by Go name", map[string][]func(interface{}) error{"Name": {notEmpty}}, &account{ID: 1}, "Name", CodeInvalid},
+		{"numbers as float64", map[string][]func(interface{}) error{"id": {Positive}}, account{ID: -1, Name: "ana"}, "id", ""},
+		{"empty fields are present", map[string][]func(interface{}) error{"role": {}}, account{}, "", ""},
+		{"unknown key is missing", map[string][]func(interface{}) error{"email": {notEmpty}}, account{}, "email", CodeRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&BaseSerializer{Validations: tt.validations}).ValidateStruct(tt.data)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateStruct() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+				t.Fatalf("ValidateStruct() error = %v, want a ValidationError of %q", err, tt.wantField)
+			}
+			if tt.wantCode != "" && validationErr.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", validationErr.Code, tt.wantCode)
+			}
+		})
+	}
+
+	for _, data := range []interface{}{nil, map[string]interface{}{}, (*account)(nil)} {
+		if _, ok := (&BaseSerializer{}).ValidateStruct(data).(*SerializationError); !ok {
+			t.Errorf("ValidateStruct(%#v) error is not a SerializationError", data)
+		}
+	}
+}
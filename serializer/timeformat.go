@@ -0,0 +1,202 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Layouts of TimeFormat that write times as numbers instead of strings.
+const (
+	TimeUnix      = "unix"      // Seconds since the Unix epoch
+	TimeUnixMilli = "unixmilli" // Milliseconds since the Unix epoch
+)
+
+// TimeFormat controls how time.Time values are written and read.
+type TimeFormat struct {
+	Layout   string         // Layout used on Serialize (a time layout, TimeUnix or TimeUnixMilli); time.RFC3339Nano when empty
+	Location *time.Location // Times are converted to it on Serialize; strings without a zone are parsed in it (UTC when nil)
+	Accept   []string       // Additional layouts accepted on Deserialize, tried in order after Layout
+	Duration string         // Encoding of time.Duration values (DurationString, DurationSeconds or DurationMillis); nanoseconds when empty
+}
+
+// isZero reports whether f leaves the default encoding of times unchanged.
+func (f *TimeFormat) isZero() bool {
+	return f.Layout == "" && f.Location == nil && len(f.Accept) == 0 && f.Duration == ""
+}
+
+// format converts t into its serialized form.
+func (f *TimeFormat) format(t time.Time) (interface{}, error) {
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	switch f.Layout {
+	case TimeUnix:
+		return float64(t.Unix()), nil
+	case TimeUnixMilli:
+		return float64(t.UnixMilli()), nil
+	case "":
+		if y := t.Year(); y < 0 || y >= 10000 {
+			return nil, fmt.Errorf("Time.MarshalJSON: year outside of range [0,9999]")
+		}
+		return t.Format(time.RFC3339Nano), nil
+	default:
+		return t.Format(f.Layout), nil
+	}
+}
+
+// parse reads a time written as a string in one of the accepted layouts, or as a Unix timestamp
+// in the unit of Layout (seconds unless Layout is TimeUnixMilli). time.Time values are kept.
+func (f *TimeFormat) parse(value interface{}) (time.Time, bool) {
+	location := f.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	var stamp int64
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case float64:
+		stamp = int64(v)
+	case int64:
+		stamp = v
+	case int:
+		stamp = int64(v)
+	case json.Number:
+		number, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		stamp = number
+	case string:
+		layouts := make([]string, 0, len(f.Accept)+2)
+		if f.Layout != "" && f.Layout != TimeUnix && f.Layout != TimeUnixMilli {
+			layouts = append(layouts, f.Layout)
+		}
+		layouts = append(append(layouts, f.Accept...), time.RFC3339Nano)
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, v, location); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	default:
+		return time.Time{}, false
+	}
+	if f.Layout == TimeUnixMilli {
+		return time.UnixMilli(stamp).In(location), true
+	}
+	return time.Unix(stamp, 0).In(location), true
+}
+
+// timeFormat returns the format of times in the given top-level field.
+func (s *BaseSerializer) timeFormat(field string) *TimeFormat {
+	if format, ok := s.TimeFormats[field]; ok {
+		return &format
+	}
+	return &s.TimeFormat
+}
+
+// usesTimeFormats reports whether times are encoded or parsed differently from encoding/json.
+func (s *BaseSerializer) usesTimeFormats() bool {
+	return !s.TimeFormat.isZero() || len(s.TimeFormats) > 0
+}
+
+// parseTimes rewrites the times in input that target time.Time fields of t, given in any of the
+// accepted layouts, into the RFC 3339 strings encoding/json reads, and the durations that target
+// time.Duration fields, in any of the forms ParseDuration accepts, into nanoseconds.
+func (s *BaseSerializer) parseTimes(input map[string]interface{}, t reflect.Type) error {
+	t = baseType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, field := range schemaFor(t).fields {
+		value, exists := input[field.name]
+		if !exists {
+			continue
+		}
+		parsed, err := parseTimeValue(value, field.typ, s.timeFormat(field.name), field.name)
+		if err != nil {
+			return err
+		}
+		input[field.name] = parsed
+	}
+	return nil
+}
+
+// parseTimeValue converts the times within value, which is decoded into type t.
+func parseTimeValue(value interface{}, t reflect.Type, format *TimeFormat, path string) (interface{}, error) {
+	t = baseType(t)
+	if value == nil {
+		return nil, nil
+	}
+
+	switch {
+	case t == timeType:
+		parsed, ok := format.parse(value)
+		if !ok {
+			return nil, &ValidationError{Field: path, Value: value, Message: "invalid time", Code: CodeInvalidTime}
+		}
+		return parsed.Format(time.RFC3339Nano), nil
+	case t == durationType:
+		parsed, err := ParseDuration(value, format.Duration)
+		if err != nil {
+			return nil, &ValidationError{Field: path, Value: value, Message: "invalid duration", Code: CodeInvalidDuration}
+		}
+		return json.Number(strconv.FormatInt(int64(parsed), 10)), nil
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return value, nil
+		}
+		converted := make([]interface{}, len(items))
+		for i, item := range items {
+			parsed, err := parseTimeValue(item, t.Elem(), format, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = parsed
+		}
+		return converted, nil
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		converted := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			parsed, err := parseTimeValue(item, t.Elem(), format, joinKeyPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			converted[key] = parsed
+		}
+		return converted, nil
+	case t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(jsonUnmarshalerType):
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		converted := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			converted[key] = item
+		}
+		for _, field := range schemaFor(t).fields {
+			item, exists := converted[field.name]
+			if !exists {
+				continue
+			}
+			parsed, err := parseTimeValue(item, field.typ, format, joinKeyPath(path, field.name))
+			if err != nil {
+				return nil, err
+			}
+			converted[field.name] = parsed
+		}
+		return converted, nil
+	default:
+		return value, nil
+	}
+}
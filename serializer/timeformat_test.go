@@ -0,0 +1,114 @@
+package serializer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type schedule struct {
+	Start    time.Time            `json:"start"`
+	Stops    []time.Time          `json:"stops"`
+	Timeout  time.Duration        `json:"timeout"`
+	Deadline map[string]time.Time `json:"deadline"`
+}
+
+func TestTimeFormatSerialize(t *testing.T) {
+	lima := time.FixedZone("PET", -5*3600)
+	at := time.Date(2024, 3, 6, 15, 4, 5, 0, time.UTC)
+	in := schedule{Start: at, Stops: []time.Time{at}, Timeout: 90 * time.Second}
+	tests := []struct {
+		name        string
+		s           *BaseSerializer
+		wantStart   interface{}
+		wantTimeout interface{}
+	}{
+		{"default", &BaseSerializer{}, "2024-03-06T15:04:05Z", float64(90 * time.Second)},
+		{"layout and location", &BaseSerializer{TimeFormat: TimeFormat{Layout: "2006-01-02 15:04", Location: lima}}, "2024-03-06 10:04", float64(90 * time.Second)},
+		{"unix", &BaseSerializer{TimeFormat: TimeFormat{Layout: TimeUnix, Duration: DurationString}}, float64(at.Unix()), "1m30s"},
+		{"unix milli", &BaseSerializer{TimeFormat: TimeFormat{Layout: TimeUnixMilli, Duration: DurationSeconds}}, float64(at.UnixMilli()), 90.0},
+		{"per-field override", &BaseSerializer{TimeFormats: map[string]TimeFormat{"start": {Layout: time.DateOnly}}}, "2024-03-06", float64(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.Serialize(in)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if got["start"] != tt.wantStart || got["timeout"] != tt.wantTimeout {
+				t.Errorf("Serialize() start, timeout = %#v, %#v, want %#v, %#v", got["start"], got["timeout"], tt.wantStart, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestTimeFormatDeserialize(t *testing.T) {
+	lima := time.FixedZone("PET", -5*3600)
+	tests := []struct {
+		name   string
+		format TimeFormat
+		input  map[string]interface{}
+		want   schedule
+	}{
+		{"accepted layouts in location", TimeFormat{Layout: time.DateOnly, Location: lima, Accept: []string{"02/01/2006 15:04"}},
+			map[string]interface{}{"start": "06/03/2024 10:04", "stops": []interface{}{"2024-03-06"}, "deadline": map[string]interface{}{"a": "2024-03-06T10:00:00Z"}},
+			schedule{
+				Start:    time.Date(2024, 3, 6, 10, 4, 0, 0, lima),
+				Stops:    []time.Time{time.Date(2024, 3, 6, 0, 0, 0, 0, lima)},
+				Deadline: map[string]time.Time{"a": time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)},
+			}},
+		{"unix seconds", TimeFormat{Layout: TimeUnix}, map[string]interface{}{"start": 1709737445.0}, schedule{Start: time.Unix(1709737445, 0)}},
+		{"unix milliseconds", TimeFormat{Layout: TimeUnixMilli}, map[string]interface{}{"start": int64(1709737445000)}, schedule{Start: time.Unix(1709737445, 0)}},
+		{"durations", TimeFormat{Duration: DurationSeconds}, map[string]interface{}{"timeout": "PT1M30S"}, schedule{Timeout: 90 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got schedule
+			if err := (&BaseSerializer{TimeFormat: tt.format}).Deserialize(tt.input, &got); err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if !got.Start.Equal(tt.want.Start) || got.Timeout != tt.want.Timeout || len(got.Stops) != len(tt.want.Stops) || len(got.Deadline) != len(tt.want.Deadline) {
+				t.Fatalf("Deserialize() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got.Stops {
+				if !got.Stops[i].Equal(tt.want.Stops[i]) {
+					t.Errorf("Stops[%d] = %v, want %v", i, got.Stops[i], tt.want.Stops[i])
+				}
+			}
+			for key := range got.Deadline {
+				if !got.Deadline[key].Equal(tt.want.Deadline[key]) {
+					t.Errorf("Deadline[%s] = %v, want %v", key, got.Deadline[key], tt.want.Deadline[key])
+				}
+			}
+		})
+	}
+}
+
+func TestTimeFormatDeserializeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		field string
+		code  string
+	}{
+		{"time", map[string]interface{}{"start": "yesterday"}, "start", CodeInvalidTime},
+		{"time in list", map[string]interface{}{"stops": []interface{}{"2024-03-06", true}}, "stops[1]", CodeInvalidTime},
+		{"time in map", map[string]interface{}{"deadline": map[string]interface{}{"a": "soon"}}, "deadline.a", CodeInvalidTime},
+		{"duration", map[string]interface{}{"timeout": "long"}, "timeout", CodeInvalidDuration},
+	}
+	s := &BaseSerializer{TimeFormat: TimeFormat{Layout: time.DateOnly}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got schedule
+			err := s.Deserialize(tt.input, &got)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field || validationErr.Code != tt.code {
+				t.Errorf("Deserialize() error = %v, want a %s error on %s", err, tt.code, tt.field)
+			}
+		})
+	}
+
+	if _, err := (&BaseSerializer{}).Serialize(schedule{Start: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}); err == nil {
+		t.Errorf("Serialize() of year 10000 error = nil, want an error")
+	}
+}
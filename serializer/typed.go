@@ -0,0 +1,66 @@
+package serializer
+
+import "fmt"
+
+// TypedSerializer wraps a Serializer with a compile-time checked type, so callers pass and
+// receive values of type T instead of interface{} and output pointers.
+type TypedSerializer[T any] struct {
+	Serializer Serializer // Underlying serializer; a zero BaseSerializer is used when nil
+}
+
+// NewTypedSerializer creates a TypedSerializer for T backed by s.
+func NewTypedSerializer[T any](s Serializer) *TypedSerializer[T] {
+	return &TypedSerializer[T]{Serializer: s}
+}
+
+// Serialize serializes a value of type T into a map, applying opts to this call only. Options
+// need an underlying serializer with a SerializeWith method, such as BaseSerializer.
+func (t *TypedSerializer[T]) Serialize(data T, opts ...Option) (map[string]interface{}, error) {
+	s := t.serializer()
+	if len(opts) == 0 {
+		return s.Serialize(data)
+	}
+	optioned, ok := s.(optionSerializer)
+	if !ok {
+		return nil, &SerializationError{Message: fmt.Sprintf("%T doesn't accept per-call options", s)}
+	}
+	return optioned.SerializeWith(data, opts...)
+}
+
+// optionSerializer is implemented by serializers that accept per-call options.
+type optionSerializer interface {
+	SerializeWith(data interface{}, opts ...Option) (map[string]interface{}, error)
+}
+
+// Deserialize deserializes a map into a new value of type T.
+func (t *TypedSerializer[T]) Deserialize(input map[string]interface{}) (T, error) {
+	var out T
+	if err := t.serializer().Deserialize(input, &out); err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
+
+// Validate checks the provided data against the underlying serializer's validations.
+func (t *TypedSerializer[T]) Validate(data map[string]interface{}) error {
+	return t.serializer().Validate(data)
+}
+
+// SerializeToXML serializes a value of type T into an XML string.
+func (t *TypedSerializer[T]) SerializeToXML(data T) (string, error) {
+	return t.serializer().SerializeToXML(data)
+}
+
+// SerializeToYAML serializes a value of type T into a YAML string.
+func (t *TypedSerializer[T]) SerializeToYAML(data T) (string, error) {
+	return t.serializer().SerializeToYAML(data)
+}
+
+// serializer returns the underlying serializer, defaulting to an empty BaseSerializer.
+func (t *TypedSerializer[T]) serializer() Serializer {
+	if t.Serializer == nil {
+		return &BaseSerializer{}
+	}
+	return t.Serializer
+}
@@ -0,0 +1,59 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypedSerializer(t *testing.T) {
+	tests := []struct {
+		name   string
+		typed  *TypedSerializer[account]
+		fields []string
+	}{
+		{"default serializer", NewTypedSerializer[account](nil), []string{"id", "name", "role"}},
+		{"configured serializer", NewTypedSerializer[account](&BaseSerializer{Fields: []string{"id", "name"}}), []string{"id", "name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := account{ID: 7, Name: "ana", Role: "admin"}
+			serialized, err := tt.typed.Serialize(in)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if len(serialized) != len(tt.fields) {
+				t.Errorf("Serialize() = %v, want the fields %q", serialized, tt.fields)
+			}
+			out, err := tt.typed.Deserialize(map[string]interface{}{"id": 7.0, "name": "ana", "role": "admin"})
+			if err != nil || out != in {
+				t.Errorf("Deserialize() = %+v, %v, want %+v", out, err, in)
+			}
+			if xml, err := tt.typed.SerializeToXML(in); err != nil || !strings.Contains(xml, "<name>ana</name>") {
+				t.Errorf("SerializeToXML() = %q, %v", xml, err)
+			}
+			if yaml, err := tt.typed.SerializeToYAML(in); err != nil || !strings.Contains(yaml, "name: ana") {
+				t.Errorf("SerializeToYAML() = %q, %v", yaml, err)
+			}
+		})
+	}
+
+	typed := NewTypedSerializer[account](&BaseSerializer{Rules: map[string]string{"name": "required"}})
+	if err := typed.Validate(map[string]interface{}{}); ErrorCode(err) != CodeRequired {
+		t.Errorf("Validate() error = %v, want code %q", err, CodeRequired)
+	}
+	out, err := typed.Deserialize(map[string]interface{}{"id": "x"})
+	if err == nil || out != (account{}) {
+		t.Errorf("Deserialize() = %+v, %v, want the zero value and an error", out, err)
+	}
+	if got, err := typed.Serialize(account{ID: 1, Name: "ana"}, WithFields("name")); err != nil || len(got) != 1 {
+		t.Errorf("Serialize() with options = %v, %v, want only name", got, err)
+	}
+
+	plain := NewTypedSerializer[account](struct{ Serializer }{&BaseSerializer{}})
+	if _, err := plain.Serialize(account{ID: 1}); err != nil {
+		t.Errorf("Serialize() error = %v", err)
+	}
+	if _, err := plain.Serialize(account{ID: 1}, WithFields("name")); err == nil {
+		t.Error("Serialize() with options of a serializer without SerializeWith succeeded")
+	}
+}
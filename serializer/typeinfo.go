@@ -0,0 +1,233 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keys under which TypeInfo adds type metadata to the serialized output.
+const (
+	TypeKey      = "_type"    // Name of the serialized type
+	VersionKey   = "_version" // TypeVersion of the serializer
+	TypeHintsKey = "_types"   // Type hint of every field, e.g. "int64", "time" or "[]string"
+)
+
+// payloadTypes maps registered type names to their types, and payloadNames the reverse.
+var (
+	payloadTypes sync.Map
+	payloadNames sync.Map
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// RegisterType registers the type of sample under name, so self-describing payloads with that
+// "_type" are deserialized into it by DeserializeAny. When name is empty, the qualified Go
+// type name (e.g. "models.User") is used. Registering is usually done from init functions.
+func RegisterType(name string, sample interface{}) {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name == "" {
+		name = t.String()
+	}
+	payloadTypes.Store(name, t)
+	payloadNames.Store(t, name)
+}
+
+// typeName returns the name under which t is registered, or its qualified Go type name.
+func typeName(t reflect.Type) string {
+	if name, ok := payloadNames.Load(t); ok {
+		return name.(string)
+	}
+	return t.String()
+}
+
+// PayloadType returns the "_type" and "_version" of a self-describing payload.
+func PayloadType(input map[string]interface{}) (name, version string) {
+	name, _ = input[TypeKey].(string)
+	version, _ = input[VersionKey].(string)
+	return name, version
+}
+
+// addTypeInfo adds "_type", "_version" and "_types" to the serialized result of data. The results
+// of nil pointers are left as they are, even when Fields or a Selection made them objects.
+func (s *BaseSerializer) addTypeInfo(data interface{}, result map[string]interface{}) {
+	if result == nil {
+		return
+	}
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+
+	hints := make(map[string]interface{})
+	for _, field := range schemaFor(t).fields {
+		key := s.convertKey(field.name)
+		if _, exists := result[key]; exists {
+			hints[key] = typeHint(field.typ)
+		}
+	}
+
+	result[TypeKey] = typeName(t)
+	if s.TypeVersion != "" {
+		result[VersionKey] = s.TypeVersion
+	}
+	result[TypeHintsKey] = hints
+}
+
+// typeHint describes the Go type of a field in a form DeserializeAny can turn back into a type.
+func typeHint(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return "time"
+	case t == durationType:
+		return "duration"
+	case t == numberType:
+		return "number"
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType),
+		t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return "any"
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return t.Kind().String()
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "[]" + typeHint(t.Elem())
+	case reflect.Map:
+		return "map[string]" + typeHint(t.Elem())
+	case reflect.Struct:
+		if _, ok := payloadNames.Load(t); ok {
+			return typeName(t)
+		}
+		return "object"
+	default:
+		return "any"
+	}
+}
+
+// hintType returns the Go type described by a type hint. Unknown hints map to interface{}.
+func hintType(hint string) reflect.Type {
+	switch hint {
+	case "time":
+		return timeType
+	case "duration":
+		return durationType
+	case "number":
+		return numberType
+	case "bytes":
+		return reflect.TypeOf([]byte(nil))
+	case "object":
+		return reflect.TypeOf(map[string]interface{}(nil))
+	case "bool":
+		return reflect.TypeOf(false)
+	case "string":
+		return reflect.TypeOf("")
+	case "int":
+		return reflect.TypeOf(int(0))
+	case "int8":
+		return reflect.TypeOf(int8(0))
+	case "int16":
+		return reflect.TypeOf(int16(0))
+	case "int32":
+		return reflect.TypeOf(int32(0))
+	case "int64":
+		return reflect.TypeOf(int64(0))
+	case "uint":
+		return reflect.TypeOf(uint(0))
+	case "uint8":
+		return reflect.TypeOf(uint8(0))
+	case "uint16":
+		return reflect.TypeOf(uint16(0))
+	case "uint32":
+		return reflect.TypeOf(uint32(0))
+	case "uint64":
+		return reflect.TypeOf(uint64(0))
+	case "float32":
+		return reflect.TypeOf(float32(0))
+	case "float64":
+		return reflect.TypeOf(float64(0))
+	}
+
+	switch {
+	case strings.HasPrefix(hint, "[]"):
+		return reflect.SliceOf(hintType(hint[2:]))
+	case strings.HasPrefix(hint, "map[string]"):
+		return reflect.MapOf(reflect.TypeOf(""), hintType(hint[len("map[string]"):]))
+	}
+	if t, ok := payloadTypes.Load(hint); ok {
+		return t.(reflect.Type)
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+// DeserializeAny deserializes a self-describing payload without a target struct. When its "_type"
+// is registered with RegisterType, a pointer to a new value of that type is returned. Otherwise the
+// payload is returned as a map whose values are converted to the types named in "_types"
+// (e.g. int64, time.Time or []string). The type metadata keys are not part of the result.
+func (s *BaseSerializer) DeserializeAny(input map[string]interface{}) (interface{}, error) {
+	name, _ := PayloadType(input)
+	hints, _ := input[TypeHintsKey].(map[string]interface{})
+
+	fields := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		if key != TypeKey && key != VersionKey && key != TypeHintsKey {
+			fields[key] = value
+		}
+	}
+
+	if t, ok := payloadTypes.Load(name); ok {
+		out := reflect.New(t.(reflect.Type))
+		if err := s.Deserialize(fields, out.Interface()); err != nil {
+			return nil, err
+		}
+		return out.Interface(), nil
+	}
+
+	for key, value := range fields {
+		hint, _ := hints[key].(string)
+		if hint == "" || value == nil {
+			continue
+		}
+		converted, err := convertHinted(value, hintType(hint))
+		if err != nil {
+			return nil, &ValidationError{Field: key, Value: value, Message: fmt.Sprintf("value doesn't match type hint '%s'", hint), Code: CodeInvalidType}
+		}
+		fields[key] = converted
+	}
+	return fields, nil
+}
+
+// convertHinted converts a plain decoded value into a value of type t.
+func convertHinted(value interface{}, t reflect.Type) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.New(t)
+	if err := json.Unmarshal(encoded, out.Interface()); err != nil {
+		return nil, err
+	}
+	return out.Elem().Interface(), nil
+}
@@ -0,0 +1,47 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSerializeTypeInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      interface{}
+		fields    []string
+		selection string
+		want      map[string]interface{}
+	}{
+		{"struct", account{ID: 1, Name: "a"}, nil, "", map[string]interface{}{
+			"id": float64(1), "name": "a", "role": "",
+			TypeKey: "serializer.account", VersionKey: "v2",
+			TypeHintsKey: map[string]interface{}{"id": "int", "name": "string", "role": "string"},
+		}},
+		{"selection", &account{ID: 1, Name: "a"}, nil, "id", map[string]interface{}{
+			"id": float64(1), TypeKey: "serializer.account", VersionKey: "v2",
+			TypeHintsKey: map[string]interface{}{"id": "int"},
+		}},
+		{"nil pointer", (*account)(nil), nil, "", nil},
+		{"nil pointer with a selection", (*account)(nil), nil, "id,name", nil},
+		{"nil pointer with fields", (*account)(nil), []string{"id"}, "", map[string]interface{}{"id": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &BaseSerializer{TypeInfo: true, TypeVersion: "v2", Fields: tt.fields}
+			var got map[string]interface{}
+			var err error
+			if tt.selection != "" {
+				got, err = s.SerializeWithSelection(tt.data, tt.selection)
+			} else {
+				got, err = s.Serialize(tt.data)
+			}
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
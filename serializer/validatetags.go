@@ -0,0 +1,329 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// tagValidationsCache caches the validations of the validate tags of each struct type. Entries
+// built before the last RegisterValidator are stale, since min, max and len use its validators.
+var tagValidationsCache sync.Map
+
+// tagValidationSet is the result of reading the validate tags of a struct type.
+type tagValidationSet struct {
+	validations map[string][]func(interface{}) error
+	err         error
+	version     uint64 // validatorsVersion when the tags were read
+}
+
+var (
+	alphaPattern    = regexp.MustCompile(`^[a-zA-Z]+$`)
+	alphaNumPattern = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	numericPattern  = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+)
+
+// tagValidators builds the validations of the validate tags, given their parameter.
+var tagValidators = map[string]func(param string) (func(interface{}) error, error){
+	"email":      noParamTag(ValidEmail),
+	"uuid":       noParamTag(UUIDField{}.Validate),
+	"url":        noParamTag(validURL),
+	"alpha":      noParamTag(formatValidation(alphaPattern, "value must contain only letters")),
+	"alphanum":   noParamTag(formatValidation(alphaNumPattern, "value must contain only letters and digits")),
+	"numeric":    noParamTag(formatValidation(numericPattern, "value must be numeric")),
+	"lowercase":  noParamTag(caseValidation(strings.ToLower, "value must be lower case")),
+	"uppercase":  noParamTag(caseValidation(strings.ToUpper, "value must be upper case")),
+	"min":        registeredTag("min"),
+	"gte":        registeredTag("min"),
+	"max":        registeredTag("max"),
+	"lte":        registeredTag("max"),
+	"gt":         boundTag(boundValidator(CodeMinValue, CodeMinLength, "exclusive_min", "greater than", func(n, limit float64) bool { return n > limit })),
+	"lt":         boundTag(boundValidator(CodeMaxValue, CodeMaxLength, "exclusive_max", "less than", func(n, limit float64) bool { return n < limit })),
+	"len":        lenTag,
+	"eq":         func(param string) (func(interface{}) error, error) { return OneOf(ruleValue(param)), nil },
+	"ne":         func(param string) (func(interface{}) error, error) { return Not(OneOf(ruleValue(param))), nil },
+	"oneof":      oneOfTag,
+	"contains":   substringTag(strings.Contains, "contain"),
+	"startswith": substringTag(strings.HasPrefix, "start with"),
+	"endswith":   substringTag(strings.HasSuffix, "end with"),
+}
+
+// TagValidations converts the validate struct tags of v's type, written for
+// github.com/go-playground/validator, into Validations keyed by the JSON names of the fields:
+//
+//	type User struct {
+//		Name  string `json:"name" validate:"required,min=3,max=50"`
+//		Email string `json:"email" validate:"omitempty,email"`
+//		Role  string `json:"role" validate:"oneof=admin editor"`
+//	}
+//
+// Failures are then reported by Validate as a ValidationError with the field, value and code,
+// like other validations. The supported tags are required, omitempty, email, uuid, url, alpha,
+// alphanum, numeric, lowercase, uppercase, min, max, gte, lte, gt, lt and len (comparing numbers,
+// or the length of strings and lists), eq, ne, oneof, contains, startswith and endswith, and
+// alternatives separated by "|" (see Or). Other tags, such as dive or cross-field tags, are
+// reported as an error rather than ignored. Only top-level fields are read.
+//
+// As with go-playground/validator, the rules apply to empty values too: a field tagged min=3
+// rejects an empty string, and is reported as missing when it isn't in the input. omitempty skips
+// the rules for zero values (missing, null, "", 0, false, and empty lists and objects), and
+// required rejects them.
+func TagValidations(v interface{}) (map[string][]func(interface{}) error, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, &SerializationError{Message: fmt.Sprintf("%T is not a struct", v)}
+	}
+	version := currentValidatorsVersion()
+	if cached, ok := tagValidationsCache.Load(t); ok {
+		if set := cached.(*tagValidationSet); set.version == version {
+			return copySliceMap(set.validations), set.err
+		}
+	}
+
+	set := &tagValidationSet{version: version}
+	set.validations, set.err = parseValidateTags(t)
+	tagValidationsCache.Store(t, set)
+	return copySliceMap(set.validations), set.err
+}
+
+// MustTagValidations is like TagValidations but panics if a tag is invalid.
+func MustTagValidations(v interface{}) map[string][]func(interface{}) error {
+	validations, err := TagValidations(v)
+	if err != nil {
+		panic(err)
+	}
+	return validations
+}
+
+// parseValidateTags reads the validate tags of the fields of struct type t.
+func parseValidateTags(t reflect.Type) (map[string][]func(interface{}) error, error) {
+	result := make(map[string][]func(interface{}) error)
+	for _, field := range schemaFor(t).fields {
+		tag := t.FieldByIndex(field.index).Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		validations, err := parseValidateTag(tag)
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("invalid validate tag of field '%s': %v", field.name, err), Err: err}
+		}
+		if len(validations) > 0 {
+			result[field.name] = validations
+		}
+	}
+	return result, nil
+}
+
+// parseValidateTag converts a validate tag into validations. The validations are preceded by
+// requiredTag when the tag contains required, and skip zero values when it contains omitempty.
+func parseValidateTag(tag string) ([]func(interface{}) error, error) {
+	var validations []func(interface{}) error
+	required, omitEmpty := false, false
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		switch rule {
+		case "":
+			continue
+		case "required":
+			required = true
+			continue
+		case "omitempty":
+			omitEmpty = true
+			continue
+		}
+
+		var alternatives []func(interface{}) error
+		for _, alternative := range strings.Split(rule, "|") {
+			name, param, _ := strings.Cut(alternative, "=")
+			build, ok := tagValidators[name]
+			if !ok {
+				return nil, fmt.Errorf("unsupported rule '%s'", name)
+			}
+			// Commas and pipes are escaped within parameters as in go-playground/validator
+			param = strings.NewReplacer("0x2C", ",", "0x7C", "|").Replace(param)
+			validation, err := build(param)
+			if err != nil {
+				return nil, fmt.Errorf("rule '%s': %v", alternative, err)
+			}
+			alternatives = append(alternatives, validation)
+		}
+		if len(alternatives) == 1 {
+			validations = append(validations, alternatives[0])
+		} else {
+			validations = append(validations, Or(alternatives...))
+		}
+	}
+
+	if required {
+		return append([]func(interface{}) error{requiredTag}, validations...), nil
+	}
+	if omitEmpty {
+		for i, validation := range validations {
+			validations[i] = omitZero(validation)
+		}
+	}
+	return validations, nil
+}
+
+// requiredTag implements the required tag, which rejects zero values like go-playground/validator.
+func requiredTag(value interface{}) error {
+	if err := requiredRule(value); err != nil {
+		return err
+	}
+	if isZeroValue(value) {
+		return NewCodedError(CodeRequired, "field is required")
+	}
+	return nil
+}
+
+// omitZero makes validation skip zero values, for the omitempty tag. It is Optional, so that the
+// field may also be missing.
+func omitZero(validation func(interface{}) error) func(interface{}) error {
+	return Optional(func(value interface{}) error {
+		if isZeroValue(value) {
+			return nil
+		}
+		return validation(value)
+	})
+}
+
+// isZeroValue reports whether a deserialized or serialized value is the zero value of its type:
+// null, "", 0, false, or an empty list or object.
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	}
+	return isEmptyValue(reflect.ValueOf(value))
+}
+
+// noParamTag returns the builder of a tag without a parameter.
+func noParamTag(validation func(interface{}) error) func(string) (func(interface{}) error, error) {
+	return func(param string) (func(interface{}) error, error) {
+		if param != "" {
+			return nil, fmt.Errorf("takes no parameter")
+		}
+		return validation, nil
+	}
+}
+
+// registeredTag returns the builder of a tag implemented by a validator of RegisterValidator.
+func registeredTag(name string) func(string) (func(interface{}) error, error) {
+	return func(param string) (func(interface{}) error, error) {
+		validatorsMu.RLock()
+		factory := validators[name]
+		validatorsMu.RUnlock()
+		return factory(map[string]interface{}{"value": ruleValue(param)})
+	}
+}
+
+// boundTag returns the builder of a tag comparing numbers or lengths with its parameter.
+func boundTag(factory ValidatorFactory) func(string) (func(interface{}) error, error) {
+	return func(param string) (func(interface{}) error, error) {
+		return factory(map[string]interface{}{"value": ruleValue(param)})
+	}
+}
+
+// lenTag builds the validation of the len tag, which requires an exact number or length.
+func lenTag(param string) (func(interface{}) error, error) {
+	atLeast, err := registeredTag("min")(param)
+	if err != nil {
+		return nil, err
+	}
+	atMost, err := registeredTag("max")(param)
+	if err != nil {
+		return nil, err
+	}
+	return And(atLeast, atMost), nil
+}
+
+// oneOfTag builds the validation of the oneof tag, whose values are separated by spaces and may
+// be quoted with single quotes.
+func oneOfTag(param string) (func(interface{}) error, error) {
+	var values []interface{}
+	for param = strings.TrimSpace(param); param != ""; param = strings.TrimSpace(param) {
+		if strings.HasPrefix(param, "'") {
+			end := strings.Index(param[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value")
+			}
+			values = append(values, param[1:end+1])
+			param = param[end+2:]
+			continue
+		}
+		value, rest, _ := strings.Cut(param, " ")
+		values = append(values, ruleValue(value))
+		param = rest
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("needs a list of values")
+	}
+	return OneOf(values...), nil
+}
+
+// substringTag returns the builder of a tag checking strings against a substring.
+func substringTag(match func(s, substr string) bool, relation string) func(string) (func(interface{}) error, error) {
+	return func(param string) (func(interface{}) error, error) {
+		return func(value interface{}) error {
+			str, ok := value.(string)
+			if !ok {
+				return NewCodedError(CodeInvalidType, "value is not a string")
+			}
+			if !match(str, param) {
+				return NewCodedError(CodeInvalid, fmt.Sprintf("value must %s '%s'", relation, param))
+			}
+			return nil
+		}, nil
+	}
+}
+
+// formatValidation returns a validation matching strings against re, failing with message.
+func formatValidation(re *regexp.Regexp, message string) func(interface{}) error {
+	return func(value interface{}) error {
+		str, ok := value.(string)
+		if !ok {
+			return NewCodedError(CodeInvalidType, "value is not a string")
+		}
+		if !re.MatchString(str) {
+			return NewCodedError(CodeInvalid, message)
+		}
+		return nil
+	}
+}
+
+// caseValidation returns a validation requiring strings to be unchanged by convert.
+func caseValidation(convert func(string) string, message string) func(interface{}) error {
+	return func(value interface{}) error {
+		str, ok := value.(string)
+		if !ok {
+			return NewCodedError(CodeInvalidType, "value is not a string")
+		}
+		if convert(str) != str {
+			return NewCodedError(CodeInvalid, message)
+		}
+		return nil
+	}
+}
+
+// validURL checks that a string is an absolute URL.
+func validURL(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return NewCodedError(CodeInvalidType, "value is not a string")
+	}
+	u, err := url.ParseRequestURI(str)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+		return NewCodedError(CodeInvalid, "invalid URL")
+	}
+	return nil
+}
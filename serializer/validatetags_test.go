@@ -0,0 +1,167 @@
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseValidateTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		value    interface{}
+		wantCode string
+	}{
+		{"required", nil, CodeRequired},
+		{"required", "", CodeEmpty},
+		{"required", 0.0, CodeRequired},
+		{"required", false, CodeRequired},
+		{"required", map[string]interface{}{}, CodeRequired},
+		{"required", 1.0, ""},
+		{"required", true, ""},
+		{"email", nil, CodeInvalidType},
+		{"email", "", CodeInvalidEmail},
+		{"omitempty,email", "ana", CodeInvalidEmail},
+		{"omitempty,email", "", ""},
+		{"omitempty,email", nil, ""},
+		{"omitempty,min=3", 0.0, ""},
+		{"omitempty,min=3", 2.0, CodeMinValue},
+		{"omitempty,required,min=3", "", CodeEmpty},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", ""},
+		{"url", "https://example.com/a", ""},
+		{"url", "/relative", CodeInvalid},
+		{"url", "mailto:ana@example.com", ""},
+		{"alpha", "abc", ""},
+		{"alpha", "ab1", CodeInvalid},
+		{"alphanum", "ab1", ""},
+		{"numeric", "-1.5", ""},
+		{"numeric", "1e3", CodeInvalid},
+		{"lowercase", "Ab", CodeInvalid},
+		{"uppercase", "AB", ""},
+		{"uppercase", 1.0, CodeInvalidType},
+		{"min=3", "ab", CodeMinLength},
+		{"gte=3", 3.0, ""},
+		{"max=2", []interface{}{1.0, 2.0, 3.0}, CodeMaxLength},
+		{"lte=2", 2.5, CodeMaxValue},
+		{"gt=2", 2.0, CodeMinValue},
+		{"gt=2", "abc", ""},
+		{"lt=2", "ab", CodeMaxLength},
+		{"len=2", "ab", ""},
+		{"len=2", "abc", CodeMaxLength},
+		{"eq=5", 5.0, ""},
+		{"ne=admin", "admin", CodeNotAllowed},
+		{"oneof=admin 'power user' 3", "power user", ""},
+		{"oneof=admin 'power user' 3", 3.0, ""},
+		{"oneof=admin 'power user' 3", "user", CodeNotAllowed},
+		{"contains=@", "a@b", ""},
+		{"startswith=a0x2Cb", "a,bc", ""},
+		{"endswith=z", "abc", CodeInvalid},
+		{"email|uuid", "ana@example.com", ""},
+		{"required,email|uuid", "ana", CodeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			validations, err := parseValidateTag(tt.tag)
+			if err != nil {
+				t.Fatalf("parseValidateTag(%q) error = %v", tt.tag, err)
+			}
+			var got error
+			for _, validation := range validations {
+				if got = validation(tt.value); got != nil {
+					break
+				}
+			}
+			if tt.wantCode == "" {
+				if got != nil {
+					t.Errorf("%q of %v error = %v, want nil", tt.tag, tt.value, got)
+				}
+				return
+			}
+			if code := ErrorCode(got); code != tt.wantCode {
+				t.Errorf("%q of %v error = %v (%s), want %s", tt.tag, tt.value, got, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParseValidateTagErrors(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr string
+	}{
+		{"dive", "unsupported rule 'dive'"},
+		{"email=x", "rule 'email=x': takes no parameter"},
+		{"oneof=", "needs a list of values"},
+		{"oneof='admin", "unterminated quoted value"},
+		{"min=abc", "rule 'min=abc'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if _, err := parseValidateTag(tt.tag); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseValidateTag(%q) error = %v, want %q", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagValidations(t *testing.T) {
+	type user struct {
+		Name  string `json:"name" validate:"required,min=2"`
+		Email string `json:"email" validate:"omitempty,email"`
+		Note  string `json:"note" validate:"omitempty"`
+		Skip  string `json:"skip" validate:"-"`
+	}
+	validations, err := TagValidations(&user{})
+	if err != nil {
+		t.Fatalf("TagValidations() error = %v", err)
+	}
+	if len(validations) != 2 || validations["name"] == nil || validations["email"] == nil {
+		t.Errorf("TagValidations() = %v, want validations of name and email", validations)
+	}
+
+	s := &BaseSerializer{Validations: validations}
+	if err := s.Validate(map[string]interface{}{"email": "ana@example.com"}); ErrorCode(err) != CodeRequired {
+		t.Errorf("Validate() error = %v, want %s", err, CodeRequired)
+	}
+
+	type zero struct {
+		Name  string `json:"name" validate:"min=3"`
+		Email string `json:"email" validate:"email"`
+		Code  string `json:"code" validate:"len=4"`
+		Count int    `json:"count" validate:"required"`
+		Note  string `json:"note" validate:"omitempty,min=3"`
+	}
+	s = &BaseSerializer{Validations: MustTagValidations(zero{})}
+	err = s.ValidateAll(map[string]interface{}{"name": "", "email": "", "code": "", "count": 0.0, "note": ""})
+	var errs *ValidationErrors
+	if !errors.As(err, &errs) || len(errs.Errors) != 4 {
+		t.Fatalf("ValidateAll() error = %v, want errors on name, email, code and count", err)
+	}
+	for _, fieldErr := range errs.Errors {
+		var validationErr *ValidationError
+		if errors.As(fieldErr, &validationErr) && validationErr.Field == "note" {
+			t.Errorf("ValidateAll() error on the omitempty field: %v", fieldErr)
+		}
+	}
+	if err := s.ValidateAll(map[string]interface{}{"name": "ana", "email": "ana@example.com", "code": "abcd", "count": 1.0}); err != nil {
+		t.Errorf("ValidateAll() error = %v", err)
+	}
+
+	type invalid struct {
+		Tags []string `json:"tags" validate:"dive,required"`
+	}
+	if _, err := TagValidations(invalid{}); err == nil || !strings.Contains(err.Error(), "invalid validate tag of field 'tags'") {
+		t.Errorf("TagValidations() error = %v, want an invalid tag error", err)
+	}
+	if _, err := TagValidations("user"); err == nil {
+		t.Errorf("TagValidations() of a string error = nil")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("MustTagValidations() didn't panic")
+			}
+		}()
+		MustTagValidations(invalid{})
+	}()
+}
@@ -1,7 +1,8 @@
 package serializer
 
 import (
-	"fmt"
+	"encoding/json"
+	"reflect"
 	"strings"
 )
 
@@ -9,34 +10,62 @@ import (
 func NotEmpty(value interface{}) error {
 	str, ok := value.(string)
 	if !ok {
-		return fmt.Errorf("value is not a string")
+		return NewCodedError(CodeInvalidType, "value is not a string")
 	}
 	if str == "" {
-		return fmt.Errorf("value cannot be empty")
+		return NewCodedError(CodeEmpty, "value cannot be empty")
 	}
 	return nil
 }
 
 // Positive checks if a field is a positive number.
 func Positive(value interface{}) error {
-	num, ok := value.(float64) // JSON numbers are parsed as float64
+	num, ok := numberValue(value)
 	if !ok {
-		return fmt.Errorf("value is not a number")
+		return NewCodedError(CodeInvalidType, "value is not a number")
 	}
 	if num <= 0 {
-		return fmt.Errorf("value must be positive")
+		return NewCodedError(CodeNotPositive, "value must be positive")
 	}
 	return nil
 }
 
+// numberValue returns a decoded JSON number, parsed as float64 or kept as json.Number with UseNumber,
+// or a number of any other numeric kind, such as the integers of YAML and the int64, uint or
+// float32 values set by hooks and transformations.
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case nil:
+		return 0, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
 // ValidEmail checks if a string is in a valid email format.
 func ValidEmail(value interface{}) error {
 	str, ok := value.(string)
 	if !ok {
-		return fmt.Errorf("value is not a string")
+		return NewCodedError(CodeInvalidType, "value is not a string")
 	}
 	if !strings.Contains(str, "@") {
-		return fmt.Errorf("invalid email format")
+		return NewCodedError(CodeInvalidEmail, "invalid email format")
 	}
 	return nil
 }
@@ -45,22 +74,22 @@ func ValidEmail(value interface{}) error {
 func ValidPassword(value interface{}) error {
 	str, ok := value.(string)
 	if !ok {
-		return fmt.Errorf("value is not a string")
+		return NewCodedError(CodeInvalidType, "value is not a string")
 	}
 	if len(str) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
+		return NewCodedErrorWithParams(CodeMinLength, "password must be at least 8 characters long", Params{"min": 8})
 	}
 	if !strings.ContainsAny(str, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
-		return fmt.Errorf("password must contain at least one uppercase letter")
+		return NewCodedError(CodeMissingUppercase, "password must contain at least one uppercase letter")
 	}
 	if !strings.ContainsAny(str, "abcdefghijklmnopqrstuvwxyz") {
-		return fmt.Errorf("password must contain at least one lowercase letter")
+		return NewCodedError(CodeMissingLowercase, "password must contain at least one lowercase letter")
 	}
 	if !strings.ContainsAny(str, "0123456789") {
-		return fmt.Errorf("password must contain at least one number")
+		return NewCodedError(CodeMissingNumber, "password must contain at least one number")
 	}
 	if !strings.ContainsAny(str, "!@#$%^&*()_+=-") {
-		return fmt.Errorf("password must contain at least one special character")
+		return NewCodedError(CodeMissingSpecial, "password must contain at least one special character")
 	}
 	return nil
 }
@@ -0,0 +1,66 @@
+package serializer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNumberValue(t *testing.T) {
+	type celsius float32
+	tests := []struct {
+		name  string
+		value interface{}
+		want  float64
+		ok    bool
+	}{
+		{"float64", 1.5, 1.5, true},
+		{"json.Number", json.Number("42"), 42, true},
+		{"invalid json.Number", json.Number("x"), 0, false},
+		{"int", 7, 7, true},
+		{"int8", int8(-8), -8, true},
+		{"int16", int16(16), 16, true},
+		{"int32", int32(-32), -32, true},
+		{"int64", int64(1) << 40, 1 << 40, true},
+		{"uint", uint(3), 3, true},
+		{"uint8", uint8(255), 255, true},
+		{"uint16", uint16(16), 16, true},
+		{"uint32", uint32(32), 32, true},
+		{"uint64", uint64(1) << 63, 1 << 63, true},
+		{"float32", float32(0.5), 0.5, true},
+		{"named numeric type", celsius(-4), -4, true},
+		{"string", "1", 0, false},
+		{"bool", true, 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := numberValue(tt.value)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("numberValue(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPositive(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		code  string // Expected code, or "" when the value is valid
+	}{
+		{"positive float", 0.5, ""},
+		{"positive int64", int64(3), ""},
+		{"positive uint", uint(1), ""},
+		{"zero uint64", uint64(0), CodeNotPositive},
+		{"negative int32", int32(-1), CodeNotPositive},
+		{"negative float32", float32(-0.5), CodeNotPositive},
+		{"not a number", "1", CodeInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ErrorCode(Positive(tt.value)); code != tt.code {
+				t.Errorf("Positive(%#v) code = %q, want %q", tt.value, code, tt.code)
+			}
+		})
+	}
+}
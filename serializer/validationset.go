@@ -0,0 +1,101 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValidationSet validates several related objects together, such as an order and its items,
+// and reports the errors grouped per object.
+type ValidationSet struct {
+	entries []validationEntry
+	rules   []validationRule
+}
+
+// validationEntry is an object registered in a ValidationSet.
+type validationEntry struct {
+	name       string
+	serializer *BaseSerializer
+	data       interface{}
+}
+
+// validationRule is a cross-object rule registered in a ValidationSet.
+type validationRule struct {
+	name string
+	rule func(objects map[string]interface{}) error
+}
+
+// NewValidationSet creates an empty ValidationSet.
+func NewValidationSet() *ValidationSet {
+	return &ValidationSet{}
+}
+
+// Add registers an object under name, validated with the given serializer. data may be a struct,
+// a map or a slice of them, in which case every element is validated.
+func (v *ValidationSet) Add(name string, s *BaseSerializer, data interface{}) *ValidationSet {
+	v.entries = append(v.entries, validationEntry{name: name, serializer: s, data: data})
+	return v
+}
+
+// Rule registers a cross-object rule. The rule receives every registered object in its serialized
+// form (maps, or slices of maps), keyed by name; a returned error is reported under name.
+func (v *ValidationSet) Rule(name string, rule func(objects map[string]interface{}) error) *ValidationSet {
+	v.rules = append(v.rules, validationRule{name: name, rule: rule})
+	return v
+}
+
+// Validate validates every object and then runs the cross-object rules. It returns a
+// *ValidationSetError when anything fails.
+func (v *ValidationSet) Validate() error {
+	errs := make(map[string][]error)
+	objects := make(map[string]interface{}, len(v.entries))
+
+	for _, entry := range v.entries {
+		value, err := toSerializedValue(entry.data)
+		if err != nil {
+			return err
+		}
+		objects[entry.name] = value
+
+		switch data := value.(type) {
+		case map[string]interface{}:
+			if err := entry.serializer.Validate(data); err != nil {
+				errs[entry.name] = append(errs[entry.name], err)
+			}
+		case []interface{}:
+			for i, item := range data {
+				name := fmt.Sprintf("%s[%d]", entry.name, i)
+				itemMap, ok := item.(map[string]interface{})
+				if !ok {
+					errs[name] = append(errs[name], &ValidationError{Field: name, Value: item, Message: "value is not an object", Code: CodeNotObject})
+					continue
+				}
+				if err := entry.serializer.Validate(itemMap); err != nil {
+					errs[name] = append(errs[name], err)
+				}
+			}
+		default:
+			errs[entry.name] = append(errs[entry.name], &ValidationError{Field: entry.name, Value: value, Message: "value is not an object", Code: CodeNotObject})
+		}
+	}
+
+	for _, rule := range v.rules {
+		if err := rule.rule(objects); err != nil {
+			errs[rule.name] = append(errs[rule.name], err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationSetError{Errors: errs}
+	}
+	return nil
+}
+
+// toSerializedValue converts data into its JSON representation (maps, slices and scalars).
+func toSerializedValue(data interface{}) (interface{}, error) {
+	value, err := toJSONValue(reflect.ValueOf(data))
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to serialize value: %v", err), Err: err}
+	}
+	return value, nil
+}
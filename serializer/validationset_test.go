@@ -0,0 +1,68 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type orderLine struct {
+	SKU string  `json:"sku"`
+	Qty float64 `json:"qty"`
+}
+
+func TestValidationSet(t *testing.T) {
+	orders := &BaseSerializer{Rules: map[string]string{"name": "required"}}
+	lines := &BaseSerializer{Validations: map[string][]func(interface{}) error{"qty": {Positive}}}
+	hasLines := func(objects map[string]interface{}) error {
+		if len(objects["lines"].([]interface{})) == 0 {
+			return errors.New("an order needs lines")
+		}
+		return nil
+	}
+	tests := []struct {
+		name  string
+		order interface{}
+		lines interface{}
+		want  []string // Names of the failed objects
+	}{
+		{"valid", account{Name: "ana"}, []orderLine{{SKU: "a", Qty: 1}}, nil},
+		{"invalid object", map[string]interface{}{"id": 1}, []orderLine{{SKU: "a", Qty: 1}}, []string{"order"}},
+		{"invalid items", account{Name: "ana"}, []orderLine{{Qty: 1}, {Qty: 0}, {Qty: -1}}, []string{"lines[1]", "lines[2]"}},
+		{"items that aren't objects", account{Name: "ana"}, []interface{}{"x"}, []string{"lines[0]"}},
+		{"not an object", "x", []orderLine{{Qty: 1}}, []string{"order"}},
+		{"cross-object rule", account{Name: "ana"}, []orderLine{}, []string{"lines"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewValidationSet().
+				Add("order", orders, tt.order).
+				Add("lines", lines, tt.lines).
+				Rule("lines", hasLines).
+				Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var setErr *ValidationSetError
+			if !errors.As(err, &setErr) {
+				t.Fatalf("Validate() error = %v, want a ValidationSetError", err)
+			}
+			var got []string
+			for name := range setErr.Errors {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() failed %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := NewValidationSet().Add("bad", orders, map[string]interface{}{"f": func() {}}).Validate().(*SerializationError); !ok {
+		t.Errorf("Validate() of an unserializable object is not a SerializationError")
+	}
+}
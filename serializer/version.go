@@ -0,0 +1,60 @@
+package serializer
+
+// Version returns a serializer for the named API version: a copy of s with the options of
+// Versions[name] applied, e.g. s.Version("v2").Serialize(user). Versions share every
+// configuration they don't override with s. Unknown versions yield an unmodified copy; use
+// HasVersion to reject them.
+func (s *BaseSerializer) Version(name string) *BaseSerializer {
+	return s.view(s.Versions[name])
+}
+
+// HasVersion reports whether the serializer defines the named version.
+func (s *BaseSerializer) HasVersion(name string) bool {
+	_, ok := s.Versions[name]
+	return ok
+}
+
+// WithAliases renames fields in the output, mapping JSON keys to the keys they're written as.
+// Deserialize accepts the aliases in place of the original keys.
+func WithAliases(aliases map[string]string) Option {
+	return func(s *BaseSerializer) {
+		s.Aliases = writableMap(s.Aliases, len(aliases))
+		for field, alias := range aliases {
+			s.Aliases[field] = alias
+		}
+	}
+}
+
+// applyAliases renames the aliased fields of a serialized result.
+func (s *BaseSerializer) applyAliases(result map[string]interface{}) {
+	values := make(map[string]interface{}, len(s.Aliases))
+	for field := range s.Aliases {
+		if value, exists := result[field]; exists {
+			values[field] = value
+			delete(result, field)
+		}
+	}
+	for field, value := range values {
+		result[s.Aliases[field]] = value
+	}
+}
+
+// restoreAliases renames aliased input keys back to the keys of their fields, as named by KeyNaming.
+func (s *BaseSerializer) restoreAliases(input map[string]interface{}) map[string]interface{} {
+	restored := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		restored[key] = value
+	}
+	values := make(map[string]interface{}, len(s.Aliases))
+	for field, alias := range s.Aliases {
+		key := s.convertKey(alias)
+		if value, exists := input[key]; exists {
+			values[field] = value
+			delete(restored, key)
+		}
+	}
+	for field, value := range values {
+		restored[s.convertKey(field)] = value
+	}
+	return restored
+}
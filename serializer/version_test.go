@@ -0,0 +1,77 @@
+package serializer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	s := &BaseSerializer{
+		ExcludedFields: []string{"role"},
+		Versions: map[string][]Option{
+			"v1": {WithAliases(map[string]string{"name": "full_name"})},
+			"v2": {WithFields("id", "name", "role")},
+		},
+	}
+	data := account{ID: 7, Name: "ana", Role: "admin"}
+	tests := []struct {
+		version string
+		want    map[string]interface{}
+		wantHas bool
+	}{
+		{"v1", map[string]interface{}{"id": 7.0, "full_name": "ana"}, true},
+		{"v2", map[string]interface{}{"id": 7.0, "name": "ana"}, true},
+		{"v3", map[string]interface{}{"id": 7.0, "name": "ana"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := s.HasVersion(tt.version); got != tt.wantHas {
+				t.Errorf("HasVersion() = %v, want %v", got, tt.wantHas)
+			}
+			got, err := s.Version(tt.version).Serialize(data)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+	if s.Aliases != nil {
+		t.Errorf("Version() changed the serializer's Aliases: %v", s.Aliases)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	s := &BaseSerializer{}
+	aliased := s.Version("")
+	WithAliases(map[string]string{"name": "full_name", "role": "kind"})(aliased)
+
+	got, err := aliased.Serialize(account{ID: 7, Name: "ana", Role: "admin"})
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if want := map[string]interface{}{"id": 7.0, "full_name": "ana", "kind": "admin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize() = %#v, want %#v", got, want)
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  account
+	}{
+		{"aliases", map[string]interface{}{"id": 7.0, "full_name": "ana", "kind": "admin"}, account{ID: 7, Name: "ana", Role: "admin"}},
+		{"original keys", map[string]interface{}{"name": "ana"}, account{Name: "ana"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out account
+			if err := aliased.Deserialize(tt.input, &out); err != nil {
+				t.Fatalf("Deserialize() error = %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("Deserialize() = %+v, want %+v", out, tt.want)
+			}
+		})
+	}
+}
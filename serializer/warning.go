@@ -0,0 +1,129 @@
+package serializer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Severity is the level of a validation failure.
+type Severity int
+
+const (
+	SeverityError   Severity = iota // The value is rejected
+	SeverityWarning                 // The value is accepted and the issue is reported as a warning
+)
+
+// NewWarning returns an error that validations return to report a non-fatal issue, such as a
+// deprecated value or one that was corrected. Validate accepts the value and records the issue
+// in the Warnings of the context (see ValidateWithWarnings).
+func NewWarning(code, message string) error {
+	return &CodedError{Code: code, Message: message, Severity: SeverityWarning}
+}
+
+// AsWarning returns a validation that reports the failures of validation as warnings, e.g.
+// AsWarning(NotEmpty) accepts empty values but warns about them.
+func AsWarning(validation func(interface{}) error) func(interface{}) error {
+	return func(value interface{}) error {
+		err := validation(value)
+		if err == nil || IsWarning(err) {
+			return err
+		}
+		return &CodedError{Code: validationCode(err), Message: err.Error(), Err: err, Severity: SeverityWarning}
+	}
+}
+
+// IsWarning reports whether err, or an error it wraps, has SeverityWarning.
+func IsWarning(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch e := err.(type) {
+		case *CodedError:
+			if e.Severity == SeverityWarning {
+				return true
+			}
+		case *ValidationError:
+			if e.Severity == SeverityWarning {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Warnings collects the warnings of a call. It is safe for concurrent use, and a nil *Warnings
+// discards them.
+type Warnings struct {
+	mu   sync.Mutex
+	list []*ValidationError
+}
+
+// Add records a warning.
+func (w *Warnings) Add(warning *ValidationError) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, warning)
+}
+
+// List returns the recorded warnings in the order they were added.
+func (w *Warnings) List() []*ValidationError {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]*ValidationError(nil), w.list...)
+}
+
+// WarningHeader returns the warning as an HTTP Warning header value (RFC 7234, code 299), e.g.
+// 299 - "username: field is deprecated: use 'email' instead". The value isn't included.
+func (e *ValidationError) WarningHeader() string {
+	text := e.Message
+	if e.Field != "" {
+		text = e.Field + ": " + text
+	}
+	return warningValue(text)
+}
+
+// warningValue returns text as an HTTP Warning header value with code 299.
+func warningValue(text string) string {
+	return fmt.Sprintf(`299 - "%s"`, strings.ReplaceAll(text, `"`, `\"`))
+}
+
+type warningsKey struct{}
+
+// ContextWithWarnings returns a copy of ctx that collects warnings into w.
+func ContextWithWarnings(ctx context.Context, w *Warnings) context.Context {
+	return context.WithValue(ctx, warningsKey{}, w)
+}
+
+// WarningsFromContext returns the Warnings carried by ctx, or nil if there are none. Hooks and
+// context-aware stages use it to report their own warnings.
+func WarningsFromContext(ctx context.Context) *Warnings {
+	w, _ := ctx.Value(warningsKey{}).(*Warnings)
+	return w
+}
+
+// ValidateWithWarnings checks data like Validate, also returning the warnings of the validations
+// that passed with one.
+func (s *BaseSerializer) ValidateWithWarnings(data map[string]interface{}) ([]*ValidationError, error) {
+	warnings := &Warnings{}
+	err := s.ValidateWithContext(ContextWithWarnings(context.Background(), warnings), data)
+	return warnings.List(), err
+}
+
+// validationFailure converts the error returned by a validation of field into a ValidationError.
+// Warnings are recorded in the Warnings of ctx instead, and yield nil.
+func (s *BaseSerializer) validationFailure(ctx context.Context, field string, value interface{}, err error) error {
+	failure := &ValidationError{Field: field, Value: value, Message: s.translateError(ctx, err), Code: validationCode(err), Params: ErrorParams(err), Err: err}
+	if IsWarning(err) {
+		failure.Severity = SeverityWarning
+		WarningsFromContext(ctx).Add(failure)
+		return nil
+	}
+	return failure
+}
@@ -0,0 +1,153 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestIsWarning(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"warning", NewWarning("legacy", "legacy value"), true},
+		{"wrapped warning", fmt.Errorf("check: %w", NewWarning("legacy", "legacy value")), true},
+		{"coded error", NewCodedError(CodeEmpty, "value cannot be empty"), false},
+		{"warning validation error", &ValidationError{Field: "name", Severity: SeverityWarning}, true},
+		{"validation error", &ValidationError{Field: "name"}, false},
+		{"plain error", errors.New("failed"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWarning(tt.err); got != tt.want {
+				t.Errorf("IsWarning(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsWarning(t *testing.T) {
+	validation := AsWarning(NotEmpty)
+	tests := []struct {
+		name     string
+		value    interface{}
+		wantCode string
+	}{
+		{"valid", "ana", ""},
+		{"empty", "", CodeEmpty},
+		{"invalid type", 7, CodeInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation(tt.value)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("AsWarning(NotEmpty)(%v) error = %v, want nil", tt.value, err)
+				}
+				return
+			}
+			if !IsWarning(err) || ErrorCode(err) != tt.wantCode {
+				t.Errorf("AsWarning(NotEmpty)(%v) error = %v, want a %s warning", tt.value, err, tt.wantCode)
+			}
+		})
+	}
+
+	// A warning is returned unchanged
+	warning := NewWarning("legacy", "legacy value")
+	if err := AsWarning(func(interface{}) error { return warning })(nil); err != warning {
+		t.Errorf("AsWarning() of a warning = %v, want it unchanged", err)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	var discarded *Warnings
+	discarded.Add(&ValidationError{Field: "name"})
+	if got := discarded.List(); got != nil {
+		t.Errorf("nil Warnings List() = %v, want nil", got)
+	}
+
+	warnings := &Warnings{}
+	first, second := &ValidationError{Field: "name"}, &ValidationError{Field: "role"}
+	warnings.Add(first)
+	warnings.Add(second)
+	list := warnings.List()
+	if len(list) != 2 || list[0] != first || list[1] != second {
+		t.Fatalf("List() = %v, want the warnings in order", list)
+	}
+	list[0] = nil
+	if warnings.List()[0] != first {
+		t.Errorf("List() shares its slice with Warnings")
+	}
+}
+
+func TestWarningHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ValidationError
+		want string
+	}{
+		{"field", &ValidationError{Field: "username", Message: "field is deprecated"}, `299 - "username: field is deprecated"`},
+		{"no field", &ValidationError{Message: "legacy request"}, `299 - "legacy request"`},
+		{"quotes", &ValidationError{Field: "name", Message: `use "full_name"`}, `299 - "name: use \"full_name\""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.WarningHeader(); got != tt.want {
+				t.Errorf("WarningHeader() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWithWarnings(t *testing.T) {
+	s := &BaseSerializer{Validations: map[string][]func(interface{}) error{
+		"name": {AsWarning(NotEmpty)},
+		"role": {func(value interface{}) error {
+			if value == "root" {
+				return NewWarning("legacy", "role 'root' is deprecated")
+			}
+			return nil
+		}},
+		"id": {Positive},
+	}}
+	tests := []struct {
+		name         string
+		data         map[string]interface{}
+		wantWarnings []string // Fields warned about
+		wantField    string
+	}{
+		{"valid", map[string]interface{}{"id": 1, "name": "ana", "role": "admin"}, nil, ""},
+		{"warnings", map[string]interface{}{"id": 1, "name": "", "role": "root"}, []string{"name", "role"}, ""},
+		{"error", map[string]interface{}{"id": 0, "name": "ana", "role": "admin"}, nil, "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := s.ValidateWithWarnings(tt.data)
+			var fields []string
+			for _, warning := range warnings {
+				if warning.Severity != SeverityWarning {
+					t.Errorf("warning %+v has severity %v", warning, warning.Severity)
+				}
+				fields = append(fields, warning.Field)
+			}
+			sort.Strings(fields)
+			if fmt.Sprint(fields) != fmt.Sprint(tt.wantWarnings) {
+				t.Errorf("ValidateWithWarnings() warned about %q, want %q", fields, tt.wantWarnings)
+			}
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateWithWarnings() error = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+				t.Errorf("ValidateWithWarnings() error = %v, want a failure of %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// xmlOptions control how serialized output is written as XML.
+type xmlOptions struct {
+	item        string            // Element of list items
+	order       []string          // Order of the top-level keys (see EncodeOrdered)
+	attributes  []string          // Paths of the fields written as attributes
+	namespaces  map[string]string // Namespace declarations of the root element, by prefix
+	declaration bool              // Start with an XML declaration
+}
+
+// encodeXML writes a serialized value as an indented XML document with the given root element,
+// one element per key, in sorted order, and an item element per list element.
+func encodeXML(value interface{}, root string, opts *xmlOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if opts.declaration {
+		buf.WriteString(xml.Header)
+	}
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	start := xml.StartElement{Name: xml.Name{Local: root}}
+	prefixes := make([]string, 0, len(opts.namespaces))
+	for prefix := range opts.namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		name := "xmlns"
+		if prefix != "" {
+			name += ":" + prefix
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: opts.namespaces[prefix]})
+	}
+	if err := opts.encode(enc, start, "", value, opts.order); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes value as the element start, with the keys of objects in sorted order except for
+// the keys listed in order. path is the dotted path of value in the document.
+func (o *xmlOptions) encode(enc *xml.Encoder, start xml.StartElement, path string, value interface{}, order []string) error {
+	if !validXMLName(start.Name.Local) {
+		return fmt.Errorf("'%s' is not a valid element name", start.Name.Local)
+	}
+
+	object, _ := value.(map[string]interface{})
+	keys := orderedObjectKeys(object, order)
+	if len(o.attributes) > 0 {
+		elements := keys[:0:0]
+		for _, key := range keys {
+			if !containsField(o.attributes, joinKeyPath(path, key)) {
+				elements = append(elements, key)
+				continue
+			}
+			switch object[key].(type) {
+			case nil:
+			case map[string]interface{}, []interface{}:
+				return fmt.Errorf("field '%s' is written as an attribute but is not a scalar", joinKeyPath(path, key))
+			default:
+				if !validXMLName(key) {
+					return fmt.Errorf("'%s' is not a valid attribute name", key)
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key}, Value: fmt.Sprint(object[key])})
+			}
+		}
+		keys = elements
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for _, key := range keys {
+			if err := o.encode(enc, xml.StartElement{Name: xml.Name{Local: key}}, joinKeyPath(path, key), v[key], nil); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := o.encode(enc, xml.StartElement{Name: xml.Name{Local: o.item}}, path, item, nil); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// encodeXMLResult encodes the serialized output of s, or a list of them, as XML, with the XML
+// options of s.
+func (s *BaseSerializer) encodeXMLResult(result interface{}) ([]byte, error) {
+	// Reduce values set by transformations and hooks to maps, lists and scalars
+	normalized, err := toJSONValueWith(reflect.ValueOf(result), sortedOptions)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode XML: %v", err), Err: err}
+	}
+	root := s.XMLRoot
+	if root == "" {
+		root = XMLRootElement
+	}
+	encoded, err := encodeXML(normalized, root, s.xmlOptions())
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to encode XML: %v", err), Err: err}
+	}
+	if s.XMLSchema != nil {
+		if err := s.XMLSchema.Validate(encoded); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+// xmlOptions returns the options of the XML documents s writes and reads.
+func (s *BaseSerializer) xmlOptions() *xmlOptions {
+	opts := &xmlOptions{
+		item:        s.XMLItem,
+		order:       s.Order,
+		attributes:  s.XMLAttributes,
+		namespaces:  s.XMLNamespaces,
+		declaration: s.XMLDeclaration,
+	}
+	if opts.item == "" {
+		opts.item = XMLItemElement
+	}
+	return opts
+}
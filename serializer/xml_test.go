@@ -0,0 +1,66 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSerializeToXML(t *testing.T) {
+	data := team{Name: "core", Lead: account{ID: 1, Name: "ana"}, Members: []account{{ID: 2, Name: "bo"}}}
+	tests := []struct {
+		name    string
+		s       *BaseSerializer
+		want    string
+		wantErr string
+	}{
+		{"defaults", &BaseSerializer{Fields: []string{"name", "members"}},
+			"<object>\n  <members>\n    <item>\n      <id>2</id>\n      <name>bo</name>\n      <role></role>\n    </item>\n  </members>\n  <name>core</name>\n</object>", ""},
+		{"root, item and order", &BaseSerializer{Fields: []string{"name", "members"}, XMLRoot: "team", XMLItem: "member", Order: []string{"name"}},
+			"<team>\n  <name>core</name>\n  <members>\n    <member>\n      <id>2</id>\n      <name>bo</name>\n      <role></role>\n    </member>\n  </members>\n</team>", ""},
+		{"attributes", &BaseSerializer{Fields: []string{"name", "lead"}, XMLAttributes: []string{"name", "lead.id"}},
+			"<object name=\"core\">\n  <lead id=\"1\">\n    <name>ana</name>\n    <role></role>\n  </lead>\n</object>", ""},
+		{"declaration and namespaces", &BaseSerializer{Fields: []string{"name"}, XMLDeclaration: true, XMLNamespaces: map[string]string{"": "urn:team", "x": "urn:x"}},
+			xmlHeader + "<object xmlns=\"urn:team\" xmlns:x=\"urn:x\">\n  <name>core</name>\n</object>", ""},
+		{"non-scalar attribute", &BaseSerializer{XMLAttributes: []string{"lead"}}, "", "field 'lead' is written as an attribute but is not a scalar"},
+		{"invalid root", &BaseSerializer{XMLRoot: "1team"}, "", "'1team' is not a valid element name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.SerializeToXML(data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("SerializeToXML() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SerializeToXML() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SerializeToXML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+func TestXMLRoundTrip(t *testing.T) {
+	// XML values are strings, so integers are coerced back
+	s := &BaseSerializer{XMLItem: "member", XMLAttributes: []string{"lead.id"}, Coerce: true}
+	encoded, err := s.SerializeToXML(team{Name: "core", Lead: account{ID: 1, Name: "ana"}, Members: []account{{ID: 2, Name: "bo"}}})
+	if err != nil {
+		t.Fatalf("SerializeToXML() error = %v", err)
+	}
+	decoded, err := s.DecodeXML([]byte(encoded))
+	if err != nil {
+		t.Fatalf("DecodeXML() error = %v", err)
+	}
+	var got team
+	if err := s.Deserialize(decoded, &got); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if got.Name != "core" || got.Lead.ID != 1 || got.Lead.Name != "ana" || len(got.Members) != 1 || got.Members[0].Name != "bo" {
+		t.Errorf("round trip = %+v", got)
+	}
+}
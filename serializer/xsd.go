@@ -0,0 +1,1095 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// xsiNamespace is the namespace of the xsi:* attributes, which every element accepts.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// XMLSchema is an XSD that XML documents are validated against (see ParseXSD).
+type XMLSchema struct {
+	elements map[string]*xsdElement // Top-level elements, by local name
+}
+
+// LoadXSD reads an XSD file (see ParseXSD).
+func LoadXSD(path string) (*XMLSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to read XSD file: %v", err), Err: err}
+	}
+	return ParseXSD(data)
+}
+
+// ParseXSD parses an XSD. It supports the subset of XML Schema that describes documents such as
+// the output of SerializeToXML:
+//
+//   - top-level and nested xs:element declarations, with type, ref, minOccurs and maxOccurs
+//   - named and anonymous xs:complexType with an xs:sequence, xs:all or xs:choice of elements,
+//     xs:attribute declarations (use="required"), mixed content and xs:simpleContent extensions
+//   - minOccurs and maxOccurs on xs:sequence and xs:choice, which repeat the whole group
+//   - named and anonymous xs:simpleType restrictions with the enumeration, pattern, length,
+//     minLength, maxLength, minInclusive, maxInclusive, minExclusive and maxExclusive facets;
+//     range facets compare numbers exactly, and dates and times chronologically
+//   - the built-in string, boolean, numeric, date, dateTime and time types, and anyType
+//
+// Elements and attributes are matched by local name and namespace. Top-level elements are in the
+// targetNamespace of the schema, as are local elements and attributes that are qualified, by
+// elementFormDefault, attributeFormDefault or their form attribute; the others are in no
+// namespace. Types are referred to by local name. Other constructs, such as xs:import, xs:group,
+// xs:any and xs:complexContent, are rejected.
+func ParseXSD(data []byte) (*XMLSchema, error) {
+	var root xsdNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XSD: %v", err), Err: err}
+	}
+	if root.XMLName.Local != "schema" {
+		return nil, invalidXSD("root element is <%s>, expected <schema>", root.XMLName.Local)
+	}
+
+	p := &xsdParser{
+		namespace:           root.attr("targetNamespace"),
+		qualifiedElements:   root.attr("elementFormDefault") == "qualified",
+		qualifiedAttributes: root.attr("attributeFormDefault") == "qualified",
+		declarations:        make(map[string]xsdNode),
+		elementNodes:        make(map[string]xsdNode),
+		types:               make(map[string]*xsdType),
+		elements:            make(map[string]*xsdElement),
+	}
+	for _, child := range root.Children {
+		name := child.attr("name")
+		switch child.XMLName.Local {
+		case "annotation":
+		case "element":
+			p.elementNodes[name] = child
+		case "complexType", "simpleType":
+			p.declarations[name] = child
+		default:
+			return nil, invalidXSD("unsupported construct <%s>", child.XMLName.Local)
+		}
+	}
+	// Resolve every declaration now, so that errors surface when parsing
+	for name := range p.declarations {
+		if _, err := p.resolveType(name); err != nil {
+			return nil, err
+		}
+	}
+	for name := range p.elementNodes {
+		if _, err := p.globalElement(name); err != nil {
+			return nil, err
+		}
+	}
+	return &XMLSchema{elements: p.elements}, nil
+}
+
+// Validate checks that an XML document conforms to the schema, returning an XSDValidationError
+// listing the offending elements and attributes.
+func (x *XMLSchema) Validate(data []byte) error {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return err
+	}
+	v := &xsdValidator{}
+	path := "/" + root.name
+	if element, ok := x.elements[root.name]; ok && element.namespace != root.space {
+		v.fail(path, root.line, "%s", wrongNamespace("element", root.name, element.namespace))
+	} else if ok {
+		v.element(root, element, path)
+	} else {
+		v.fail(path, root.line, "element '%s' is not declared in the schema", root.name)
+	}
+	if len(v.errors) > 0 {
+		return &XSDValidationError{Errors: v.errors}
+	}
+	return nil
+}
+
+// invalidXSD reports an XSD that can't be parsed.
+func invalidXSD(format string, args ...interface{}) error {
+	return &SerializationError{Message: "invalid XSD: " + fmt.Sprintf(format, args...)}
+}
+
+// xsdNode is an element of an XSD document.
+type xsdNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []xsdNode  `xml:",any"`
+}
+
+func (n xsdNode) attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// xsdElement is an element declaration.
+type xsdElement struct {
+	name      string
+	namespace string // Namespace of the element; empty when unqualified
+	typ       *xsdType
+	ref       *xsdElement // Top-level element of a ref, whose type is resolved later when recursive
+	min, max  int         // Occurrences; max is -1 when unbounded
+}
+
+// elementType returns the type of the element.
+func (e *xsdElement) elementType() *xsdType {
+	if e.ref != nil {
+		return e.ref.typ
+	}
+	return e.typ
+}
+
+// xsdType is the type of an element.
+type xsdType struct {
+	any        bool           // anyType: any attributes and content
+	simple     *xsdSimpleType // Type of the text content; nil for element-only content
+	attributes []*xsdAttribute
+	content    *xsdGroup // Child elements; nil for none
+	mixed      bool      // Text is allowed between child elements
+}
+
+// xsdAttribute is an attribute declaration.
+type xsdAttribute struct {
+	name      string
+	namespace string // Namespace of the attribute; empty when unqualified
+	typ       *xsdSimpleType
+	required  bool
+}
+
+// xsdGroup is an xs:sequence, xs:all or xs:choice of elements.
+type xsdGroup struct {
+	kind     string
+	elements []*xsdElement
+	min, max int // Occurrences of the whole group; max is -1 when unbounded
+}
+
+// xsdSimpleType is a built-in type or a restriction of another simple type.
+type xsdSimpleType struct {
+	builtin     string         // Name of the built-in type, when base is nil
+	base        *xsdSimpleType // Restricted type
+	enumeration []string
+	patterns    []*regexp.Regexp
+
+	length, minLength, maxLength                           *int
+	minInclusive, maxInclusive, minExclusive, maxExclusive *string // Compared as values of the type
+}
+
+// xsdParser resolves the declarations of an XSD, keeping the resolved ones so that types can
+// refer to each other recursively.
+type xsdParser struct {
+	namespace           string             // targetNamespace of the schema
+	qualifiedElements   bool               // Local elements are in the target namespace by default
+	qualifiedAttributes bool               // Attributes are in the target namespace by default
+	declarations        map[string]xsdNode // Named types
+	elementNodes        map[string]xsdNode // Top-level elements
+	types               map[string]*xsdType
+	elements            map[string]*xsdElement
+}
+
+// resolveType returns the named or built-in type ref, e.g. "xs:string" or "tns:Address".
+func (p *xsdParser) resolveType(ref string) (*xsdType, error) {
+	name := localName(ref)
+	if t, ok := p.types[name]; ok {
+		return t, nil
+	}
+	if node, ok := p.declarations[name]; ok {
+		if node.XMLName.Local == "simpleType" {
+			simple, err := p.simpleType(node)
+			if err != nil {
+				return nil, err
+			}
+			p.types[name] = &xsdType{simple: simple}
+			return p.types[name], nil
+		}
+		t := &xsdType{}
+		p.types[name] = t
+		if err := p.complexType(node, t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	switch {
+	case name == "anyType":
+		return &xsdType{any: true}, nil
+	case xsdBuiltins[name] != nil:
+		return &xsdType{simple: &xsdSimpleType{builtin: name}}, nil
+	}
+	return nil, invalidXSD("unknown type '%s'", ref)
+}
+
+// resolveSimpleType returns the simple type ref.
+func (p *xsdParser) resolveSimpleType(ref string) (*xsdSimpleType, error) {
+	t, err := p.resolveType(ref)
+	if err != nil {
+		return nil, err
+	}
+	if t.simple == nil || len(t.attributes) > 0 {
+		return nil, invalidXSD("type '%s' is not a simple type", ref)
+	}
+	return t.simple, nil
+}
+
+// globalElement returns the top-level element name.
+func (p *xsdParser) globalElement(name string) (*xsdElement, error) {
+	if element, ok := p.elements[name]; ok {
+		return element, nil
+	}
+	node, ok := p.elementNodes[name]
+	if !ok {
+		return nil, invalidXSD("unknown element '%s'", name)
+	}
+	element := &xsdElement{name: name, namespace: p.namespace, min: 1, max: 1}
+	p.elements[name] = element
+	t, err := p.elementType(node)
+	if err != nil {
+		return nil, err
+	}
+	element.typ = t
+	return element, nil
+}
+
+// element converts an element declaration nested in a group.
+func (p *xsdParser) element(node xsdNode) (*xsdElement, error) {
+	min, max, err := occurs(node)
+	if err != nil {
+		return nil, err
+	}
+	if ref := node.attr("ref"); ref != "" {
+		global, err := p.globalElement(localName(ref))
+		if err != nil {
+			return nil, err
+		}
+		return &xsdElement{name: global.name, namespace: global.namespace, ref: global, min: min, max: max}, nil
+	}
+	name := node.attr("name")
+	if name == "" {
+		return nil, invalidXSD("element without a name")
+	}
+	t, err := p.elementType(node)
+	if err != nil {
+		return nil, err
+	}
+	return &xsdElement{name: name, namespace: p.formNamespace(node, p.qualifiedElements), typ: t, min: min, max: max}, nil
+}
+
+// formNamespace returns the namespace of a local element or attribute declaration: the target
+// namespace when it is qualified, by its form attribute or else by default.
+func (p *xsdParser) formNamespace(node xsdNode, qualifiedByDefault bool) string {
+	switch node.attr("form") {
+	case "qualified":
+		return p.namespace
+	case "unqualified":
+		return ""
+	}
+	if qualifiedByDefault {
+		return p.namespace
+	}
+	return ""
+}
+
+// elementType returns the type of an element declaration, given by its type attribute or an
+// anonymous type, or anyType.
+func (p *xsdParser) elementType(node xsdNode) (*xsdType, error) {
+	if ref := node.attr("type"); ref != "" {
+		return p.resolveType(ref)
+	}
+	for _, child := range node.Children {
+		switch child.XMLName.Local {
+		case "annotation":
+		case "complexType":
+			t := &xsdType{}
+			return t, p.complexType(child, t)
+		case "simpleType":
+			simple, err := p.simpleType(child)
+			if err != nil {
+				return nil, err
+			}
+			return &xsdType{simple: simple}, nil
+		default:
+			return nil, invalidXSD("unsupported construct <%s> in element '%s'", child.XMLName.Local, node.attr("name"))
+		}
+	}
+	return &xsdType{any: true}, nil
+}
+
+// complexType fills t with the content and attributes of a complex type.
+func (p *xsdParser) complexType(node xsdNode, t *xsdType) error {
+	t.mixed = node.attr("mixed") == "true"
+	for _, child := range node.Children {
+		switch child.XMLName.Local {
+		case "annotation":
+		case "sequence", "all", "choice":
+			if t.content != nil || t.simple != nil {
+				return invalidXSD("complex type '%s' has more than one content model", node.attr("name"))
+			}
+			group, err := p.group(child)
+			if err != nil {
+				return err
+			}
+			t.content = group
+		case "attribute":
+			attribute, err := p.attribute(child)
+			if err != nil {
+				return err
+			}
+			t.attributes = append(t.attributes, attribute)
+		case "simpleContent":
+			if t.content != nil || t.simple != nil {
+				return invalidXSD("complex type '%s' has more than one content model", node.attr("name"))
+			}
+			if err := p.simpleContent(child, t); err != nil {
+				return err
+			}
+		default:
+			return invalidXSD("unsupported construct <%s>", child.XMLName.Local)
+		}
+	}
+	return nil
+}
+
+// simpleContent fills t with an xs:simpleContent extension: text of its base type, with attributes.
+func (p *xsdParser) simpleContent(node xsdNode, t *xsdType) error {
+	for _, child := range node.Children {
+		switch child.XMLName.Local {
+		case "annotation":
+		case "extension":
+			base, err := p.resolveType(child.attr("base"))
+			if err != nil {
+				return err
+			}
+			if base.simple == nil {
+				return invalidXSD("simple content base '%s' is not a simple type", child.attr("base"))
+			}
+			t.simple = base.simple
+			t.attributes = append(t.attributes, base.attributes...)
+			for _, attributeNode := range child.Children {
+				switch attributeNode.XMLName.Local {
+				case "annotation":
+				case "attribute":
+					attribute, err := p.attribute(attributeNode)
+					if err != nil {
+						return err
+					}
+					t.attributes = append(t.attributes, attribute)
+				default:
+					return invalidXSD("unsupported construct <%s> in simple content", attributeNode.XMLName.Local)
+				}
+			}
+		default:
+			return invalidXSD("unsupported construct <%s> in simple content", child.XMLName.Local)
+		}
+	}
+	return nil
+}
+
+// group converts an xs:sequence, xs:all or xs:choice.
+func (p *xsdParser) group(node xsdNode) (*xsdGroup, error) {
+	min, max, err := occurs(node)
+	if err != nil {
+		return nil, err
+	}
+	group := &xsdGroup{kind: node.XMLName.Local, min: min, max: max}
+	for _, child := range node.Children {
+		switch child.XMLName.Local {
+		case "annotation":
+		case "element":
+			element, err := p.element(child)
+			if err != nil {
+				return nil, err
+			}
+			group.elements = append(group.elements, element)
+		default:
+			return nil, invalidXSD("unsupported construct <%s> in <%s>", child.XMLName.Local, group.kind)
+		}
+	}
+	return group, nil
+}
+
+// attribute converts an attribute declaration.
+func (p *xsdParser) attribute(node xsdNode) (*xsdAttribute, error) {
+	attribute := &xsdAttribute{
+		name:      node.attr("name"),
+		namespace: p.formNamespace(node, p.qualifiedAttributes),
+		required:  node.attr("use") == "required",
+	}
+	if attribute.name == "" {
+		return nil, invalidXSD("attribute without a name")
+	}
+	if ref := node.attr("type"); ref != "" {
+		typ, err := p.resolveSimpleType(ref)
+		if err != nil {
+			return nil, err
+		}
+		attribute.typ = typ
+	}
+	for _, child := range node.Children {
+		switch child.XMLName.Local {
+		case "annotation":
+		case "simpleType":
+			typ, err := p.simpleType(child)
+			if err != nil {
+				return nil, err
+			}
+			attribute.typ = typ
+		default:
+			return nil, invalidXSD("unsupported construct <%s> in attribute '%s'", child.XMLName.Local, attribute.name)
+		}
+	}
+	if attribute.typ == nil {
+		attribute.typ = &xsdSimpleType{builtin: "anySimpleType"}
+	}
+	return attribute, nil
+}
+
+// simpleType converts an xs:simpleType restriction.
+func (p *xsdParser) simpleType(node xsdNode) (*xsdSimpleType, error) {
+	for _, child := range node.Children {
+		switch child.XMLName.Local {
+		case "annotation":
+		case "restriction":
+			return p.restriction(child)
+		default:
+			return nil, invalidXSD("unsupported construct <%s> in simple type", child.XMLName.Local)
+		}
+	}
+	return nil, invalidXSD("simple type '%s' has no restriction", node.attr("name"))
+}
+
+// restriction converts an xs:restriction of a simple type and its facets.
+func (p *xsdParser) restriction(node xsdNode) (*xsdSimpleType, error) {
+	t := &xsdSimpleType{}
+	if ref := node.attr("base"); ref != "" {
+		base, err := p.resolveSimpleType(ref)
+		if err != nil {
+			return nil, err
+		}
+		t.base = base
+	}
+	for _, facet := range node.Children {
+		value := facet.attr("value")
+		var err error
+		switch facet.XMLName.Local {
+		case "annotation", "whiteSpace":
+		case "simpleType":
+			t.base, err = p.simpleType(facet)
+		case "enumeration":
+			t.enumeration = append(t.enumeration, value)
+		case "pattern":
+			var pattern *regexp.Regexp
+			// XSD patterns match the whole value
+			if pattern, err = regexp.Compile("^(?:" + value + ")$"); err == nil {
+				t.patterns = append(t.patterns, pattern)
+			}
+		case "length":
+			t.length, err = facetInt(value)
+		case "minLength":
+			t.minLength, err = facetInt(value)
+		case "maxLength":
+			t.maxLength, err = facetInt(value)
+		case "minInclusive":
+			t.minInclusive = &value
+		case "maxInclusive":
+			t.maxInclusive = &value
+		case "minExclusive":
+			t.minExclusive = &value
+		case "maxExclusive":
+			t.maxExclusive = &value
+		default:
+			return nil, invalidXSD("unsupported facet <%s>", facet.XMLName.Local)
+		}
+		if err != nil {
+			return nil, invalidXSD("facet <%s value=%q>: %v", facet.XMLName.Local, value, err)
+		}
+	}
+	if t.base == nil {
+		return nil, invalidXSD("restriction without a base type")
+	}
+	if err := t.checkRange(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkRange checks that the range facets of a restriction are values of its type, and that the
+// type is ordered.
+func (t *xsdSimpleType) checkRange() error {
+	builtin := t.builtinName()
+	for _, facet := range []struct {
+		name  string
+		value *string
+	}{
+		{"minInclusive", t.minInclusive},
+		{"maxInclusive", t.maxInclusive},
+		{"minExclusive", t.minExclusive},
+		{"maxExclusive", t.maxExclusive},
+	} {
+		if facet.value == nil {
+			continue
+		}
+		if xsdOrderings[builtin] == nil {
+			return invalidXSD("facet <%s> on type '%s', which is not ordered", facet.name, builtin)
+		}
+		if err := xsdBuiltins[builtin](*facet.value); err != nil {
+			return invalidXSD("facet <%s value=%q>: %v", facet.name, *facet.value, err)
+		}
+	}
+	return nil
+}
+
+func facetInt(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("not a non-negative integer")
+	}
+	return &n, nil
+}
+
+// occurs returns the minOccurs and maxOccurs of a declaration, with -1 for unbounded.
+func occurs(node xsdNode) (int, int, error) {
+	min, max := 1, 1
+	if value := node.attr("minOccurs"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, invalidXSD("invalid minOccurs %q", value)
+		}
+		min = n
+	}
+	if value := node.attr("maxOccurs"); value == "unbounded" {
+		max = -1
+	} else if value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, invalidXSD("invalid maxOccurs %q", value)
+		}
+		max = n
+	}
+	if max >= 0 && min > max {
+		return 0, 0, invalidXSD("minOccurs %d is greater than maxOccurs %d", min, max)
+	}
+	return min, max, nil
+}
+
+// localName strips the namespace prefix of a qualified name.
+func localName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// validate checks a value against the type and its base types.
+func (t *xsdSimpleType) validate(value string) error {
+	if t.base != nil {
+		if err := t.base.validate(value); err != nil {
+			return err
+		}
+	} else if err := xsdBuiltins[t.builtin](value); err != nil {
+		return err
+	}
+
+	if t.builtinName() != "string" {
+		value = strings.TrimSpace(value)
+	}
+	if len(t.enumeration) > 0 && !containsField(t.enumeration, value) {
+		return fmt.Errorf("value '%s' is not one of %s", value, strings.Join(t.enumeration, ", "))
+	}
+	if len(t.patterns) > 0 {
+		matched := false
+		for _, pattern := range t.patterns {
+			matched = matched || pattern.MatchString(value)
+		}
+		if !matched {
+			return fmt.Errorf("value '%s' does not match the pattern", value)
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	switch {
+	case t.length != nil && length != *t.length:
+		return fmt.Errorf("length must be %d", *t.length)
+	case t.minLength != nil && length < *t.minLength:
+		return fmt.Errorf("length must be at least %d", *t.minLength)
+	case t.maxLength != nil && length > *t.maxLength:
+		return fmt.Errorf("length must be at most %d", *t.maxLength)
+	}
+
+	compare := xsdOrderings[t.builtinName()]
+	for _, bound := range []struct {
+		value   *string
+		valid   func(int) bool
+		message string
+	}{
+		{t.minInclusive, func(c int) bool { return c >= 0 }, "value must be at least %s"},
+		{t.maxInclusive, func(c int) bool { return c <= 0 }, "value must be at most %s"},
+		{t.minExclusive, func(c int) bool { return c > 0 }, "value must be greater than %s"},
+		{t.maxExclusive, func(c int) bool { return c < 0 }, "value must be less than %s"},
+	} {
+		if bound.value == nil {
+			continue
+		}
+		// Incomparable values, such as NaN, are out of every range
+		if c, ok := compare(value, strings.TrimSpace(*bound.value)); !ok || !bound.valid(c) {
+			return fmt.Errorf(bound.message, strings.TrimSpace(*bound.value))
+		}
+	}
+	return nil
+}
+
+// builtinName returns the built-in type the type derives from.
+func (t *xsdSimpleType) builtinName() string {
+	for t.base != nil {
+		t = t.base
+	}
+	return t.builtin
+}
+
+var (
+	xsdIntegerPattern = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	xsdDecimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+)
+
+// xsdBuiltins checks the values of the supported built-in types.
+var xsdBuiltins = map[string]func(string) error{
+	"anySimpleType":    func(string) error { return nil },
+	"string":           func(string) error { return nil },
+	"normalizedString": func(string) error { return nil },
+	"token":            func(string) error { return nil },
+	"anyURI":           func(string) error { return nil },
+	"language":         func(string) error { return nil },
+	"Name":             func(string) error { return nil },
+	"NCName":           func(string) error { return nil },
+	"ID":               func(string) error { return nil },
+	"IDREF":            func(string) error { return nil },
+	"boolean": func(value string) error {
+		switch strings.TrimSpace(value) {
+		case "true", "false", "1", "0":
+			return nil
+		}
+		return fmt.Errorf("value '%s' is not a boolean", value)
+	},
+	"decimal": func(value string) error {
+		if !xsdDecimalPattern.MatchString(strings.TrimSpace(value)) {
+			return fmt.Errorf("value '%s' is not a decimal", value)
+		}
+		return nil
+	},
+	"float":              xsdFloat,
+	"double":             xsdFloat,
+	"integer":            xsdInteger(nil, nil),
+	"long":               xsdInteger(big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)),
+	"int":                xsdInteger(big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)),
+	"short":              xsdInteger(big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16)),
+	"byte":               xsdInteger(big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)),
+	"nonNegativeInteger": xsdInteger(big.NewInt(0), nil),
+	"positiveInteger":    xsdInteger(big.NewInt(1), nil),
+	"nonPositiveInteger": xsdInteger(nil, big.NewInt(0)),
+	"negativeInteger":    xsdInteger(nil, big.NewInt(-1)),
+	"unsignedLong":       xsdInteger(big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)),
+	"unsignedInt":        xsdInteger(big.NewInt(0), big.NewInt(math.MaxUint32)),
+	"unsignedShort":      xsdInteger(big.NewInt(0), big.NewInt(math.MaxUint16)),
+	"unsignedByte":       xsdInteger(big.NewInt(0), big.NewInt(math.MaxUint8)),
+	"date":               xsdTime("date"),
+	"dateTime":           xsdTime("dateTime"),
+	"time":               xsdTime("time"),
+}
+
+// xsdTimeLayouts are the layouts of the date and time types, without and with a time zone.
+var xsdTimeLayouts = map[string][]string{
+	"date":     {"2006-01-02", "2006-01-02Z07:00"},
+	"dateTime": {"2006-01-02T15:04:05.999999999", time.RFC3339Nano},
+	"time":     {"15:04:05.999999999", "15:04:05.999999999Z07:00"},
+}
+
+// xsdOrderings compare the values of the ordered built-in types, which accept range facets. They
+// return false for incomparable values.
+var xsdOrderings = map[string]func(a, b string) (int, bool){
+	"float":              compareXSDFloat,
+	"double":             compareXSDFloat,
+	"decimal":            compareXSDDecimal,
+	"integer":            compareXSDDecimal,
+	"long":               compareXSDDecimal,
+	"int":                compareXSDDecimal,
+	"short":              compareXSDDecimal,
+	"byte":               compareXSDDecimal,
+	"nonNegativeInteger": compareXSDDecimal,
+	"positiveInteger":    compareXSDDecimal,
+	"nonPositiveInteger": compareXSDDecimal,
+	"negativeInteger":    compareXSDDecimal,
+	"unsignedLong":       compareXSDDecimal,
+	"unsignedInt":        compareXSDDecimal,
+	"unsignedShort":      compareXSDDecimal,
+	"unsignedByte":       compareXSDDecimal,
+	"date":               compareXSDTime("date"),
+	"dateTime":           compareXSDTime("dateTime"),
+	"time":               compareXSDTime("time"),
+}
+
+func xsdFloat(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "INF" || value == "-INF" || value == "NaN" {
+		return nil
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil || strings.ContainsAny(value, "xXpP_") || strings.EqualFold(value, "inf") {
+		return fmt.Errorf("value '%s' is not a number", value)
+	}
+	return nil
+}
+
+// xsdInteger checks integers in [min, max], where a nil bound is unbounded.
+func xsdInteger(min, max *big.Int) func(string) error {
+	return func(value string) error {
+		value = strings.TrimSpace(value)
+		if !xsdIntegerPattern.MatchString(value) {
+			return fmt.Errorf("value '%s' is not an integer", value)
+		}
+		n, _ := new(big.Int).SetString(value, 10)
+		if (min != nil && n.Cmp(min) < 0) || (max != nil && n.Cmp(max) > 0) {
+			return fmt.Errorf("value '%s' is out of range", value)
+		}
+		return nil
+	}
+}
+
+func xsdTime(name string) func(string) error {
+	return func(value string) error {
+		if _, ok := parseXSDTime(name, value); !ok {
+			return fmt.Errorf("value '%s' is not a valid %s", value, name)
+		}
+		return nil
+	}
+}
+
+// parseXSDTime parses a value of the date or time type name. Values without a time zone are
+// read as UTC.
+func parseXSDTime(name, value string) (time.Time, bool) {
+	for _, layout := range xsdTimeLayouts[name] {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func compareXSDTime(name string) func(a, b string) (int, bool) {
+	return func(a, b string) (int, bool) {
+		x, okX := parseXSDTime(name, a)
+		y, okY := parseXSDTime(name, b)
+		return x.Compare(y), okX && okY
+	}
+}
+
+// compareXSDDecimal compares decimals and integers exactly.
+func compareXSDDecimal(a, b string) (int, bool) {
+	x, okX := new(big.Rat).SetString(strings.TrimSpace(a))
+	y, okY := new(big.Rat).SetString(strings.TrimSpace(b))
+	if !okX || !okY {
+		return 0, false
+	}
+	return x.Cmp(y), true
+}
+
+func compareXSDFloat(a, b string) (int, bool) {
+	x, y := parseXSDFloat(a), parseXSDFloat(b)
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return 0, false
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
+
+// parseXSDFloat parses a float or double, returning NaN for invalid values.
+func parseXSDFloat(value string) float64 {
+	switch value = strings.TrimSpace(value); value {
+	case "INF":
+		return math.Inf(1)
+	case "-INF":
+		return math.Inf(-1)
+	}
+	if xsdFloat(value) != nil {
+		return math.NaN()
+	}
+	n, _ := strconv.ParseFloat(value, 64)
+	return n
+}
+
+// xmlTreeNode is an element of a parsed XML document.
+type xmlTreeNode struct {
+	name     string
+	space    string // Namespace
+	line     int
+	attrs    []xml.Attr
+	children []*xmlTreeNode
+	text     strings.Builder
+}
+
+// parseXMLTree parses the root element of an XML document.
+func parseXMLTree(data []byte) (*xmlTreeNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		root  *xmlTreeNode
+		stack []*xmlTreeNode
+	)
+	for {
+		line, _ := dec.InputPos()
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: %v", err), Err: err}
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) > maxXMLDepth {
+				return nil, &SerializationError{Message: fmt.Sprintf("failed to parse XML: elements nested deeper than %d", maxXMLDepth)}
+			}
+			node := &xmlTreeNode{name: t.Name.Local, space: t.Name.Space, line: line, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			} else {
+				return nil, &SerializationError{Message: "failed to parse XML: more than one root element"}
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, &SerializationError{Message: "failed to parse XML: no root element"}
+	}
+	return root, nil
+}
+
+// xsdValidator collects the errors of a document.
+type xsdValidator struct {
+	errors []*XSDError
+}
+
+func (v *xsdValidator) fail(path string, line int, format string, args ...interface{}) {
+	v.errors = append(v.errors, &XSDError{Path: path, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// element validates node, at path, against its declaration.
+func (v *xsdValidator) element(node *xmlTreeNode, element *xsdElement, path string) {
+	t := element.elementType()
+	if t.any {
+		return
+	}
+
+	for _, attr := range node.attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") || attr.Name.Space == xsiNamespace {
+			continue
+		}
+		declaration := t.attribute(attr.Name.Local)
+		if declaration == nil {
+			v.fail(path, node.line, "unexpected attribute '%s'", attr.Name.Local)
+			continue
+		}
+		if declaration.namespace != attr.Name.Space {
+			v.fail(path, node.line, "%s", wrongNamespace("attribute", attr.Name.Local, declaration.namespace))
+			continue
+		}
+		if err := declaration.typ.validate(attr.Value); err != nil {
+			v.fail(path, node.line, "attribute '%s': %v", attr.Name.Local, err)
+		}
+	}
+	for _, declaration := range t.attributes {
+		if declaration.required && !hasXMLAttr(node.attrs, declaration) {
+			v.fail(path, node.line, "missing attribute '%s'", declaration.name)
+		}
+	}
+
+	if t.simple != nil {
+		if len(node.children) > 0 {
+			v.fail(path, node.line, "unexpected element '%s' in simple content", node.children[0].name)
+		} else if err := t.simple.validate(node.text.String()); err != nil {
+			v.fail(path, node.line, "%v", err)
+		}
+		return
+	}
+	if !t.mixed && strings.TrimSpace(node.text.String()) != "" {
+		v.fail(path, node.line, "unexpected text content")
+	}
+
+	declarations := v.content(node, t.content, path)
+	counts := make(map[string]int)
+	for _, child := range node.children {
+		counts[child.name]++
+	}
+	seen := make(map[string]int)
+	for i, child := range node.children {
+		childPath := path + "/" + child.name
+		if seen[child.name]++; counts[child.name] > 1 {
+			childPath += fmt.Sprintf("[%d]", seen[child.name])
+		}
+		if declarations[i] == nil {
+			if declared := t.content.element(child.name); declared != nil && !declared.matches(child) {
+				v.fail(childPath, child.line, "%s", wrongNamespace("element", child.name, declared.namespace))
+			} else {
+				v.fail(childPath, child.line, "unexpected element '%s'", child.name)
+			}
+			continue
+		}
+		v.element(child, declarations[i], childPath)
+	}
+}
+
+// content matches the children of node against its content model, returning the declaration of
+// each child, or nil for the unexpected ones, and reporting missing elements.
+func (v *xsdValidator) content(node *xmlTreeNode, group *xsdGroup, path string) []*xsdElement {
+	declarations := make([]*xsdElement, len(node.children))
+	if group == nil || (len(node.children) == 0 && group.min == 0) {
+		return declarations
+	}
+
+	switch group.kind {
+	case "sequence":
+		i := 0
+		for repeat := 0; group.max < 0 || repeat < group.max; repeat++ {
+			start := i
+			var missing []string
+			for _, element := range group.elements {
+				count := 0
+				for i < len(node.children) && element.matches(node.children[i]) && (element.max < 0 || count < element.max) {
+					declarations[i] = element
+					i++
+					count++
+				}
+				if count < element.min {
+					missing = append(missing, element.name)
+				}
+			}
+			if i == start && repeat >= group.min {
+				// Nothing left for another occurrence of the sequence
+				break
+			}
+			for _, name := range missing {
+				v.fail(path, node.line, "missing element '%s'", name)
+			}
+			if i == start {
+				break
+			}
+		}
+	case "all":
+		counts := make(map[string]int)
+		for i, child := range node.children {
+			if element := group.element(child.name); element != nil && element.matches(child) && (element.max < 0 || counts[child.name] < element.max) {
+				declarations[i] = element
+				counts[child.name]++
+			}
+		}
+		for _, element := range group.elements {
+			if counts[element.name] < element.min {
+				v.fail(path, node.line, "missing element '%s'", element.name)
+			}
+		}
+	case "choice":
+		// Each occurrence of the choice is a run of one of its elements, up to its maxOccurs
+		var chosen *xsdElement
+		occurrences, count := 0, 0
+		for i, child := range node.children {
+			element := group.element(child.name)
+			if element == nil || !element.matches(child) {
+				continue
+			}
+			if element != chosen || (element.max >= 0 && count == element.max) {
+				if group.max >= 0 && occurrences == group.max {
+					continue
+				}
+				if chosen != nil && count < chosen.min {
+					v.fail(path, node.line, "missing element '%s'", chosen.name)
+				}
+				chosen, count = element, 0
+				occurrences++
+			}
+			declarations[i] = element
+			count++
+		}
+		switch {
+		case chosen != nil && count < chosen.min:
+			v.fail(path, node.line, "missing element '%s'", chosen.name)
+		case occurrences < group.min:
+			optional := false
+			names := make([]string, len(group.elements))
+			for i, element := range group.elements {
+				names[i] = element.name
+				optional = optional || element.min == 0
+			}
+			// A choice of an optional element is satisfied by an empty occurrence
+			if !optional {
+				v.fail(path, node.line, "missing one of the elements %s", strings.Join(names, ", "))
+			}
+		}
+	}
+	return declarations
+}
+
+// matches reports whether node is an occurrence of the element.
+func (e *xsdElement) matches(node *xmlTreeNode) bool {
+	return node.name == e.name && node.space == e.namespace
+}
+
+// attribute returns the declaration of the attribute name.
+func (t *xsdType) attribute(name string) *xsdAttribute {
+	for _, attribute := range t.attributes {
+		if attribute.name == name {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// element returns the declaration of the element name, in any namespace.
+func (g *xsdGroup) element(name string) *xsdElement {
+	if g == nil {
+		return nil
+	}
+	for _, element := range g.elements {
+		if element.name == name {
+			return element
+		}
+	}
+	return nil
+}
+
+func hasXMLAttr(attrs []xml.Attr, declaration *xsdAttribute) bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == declaration.name && attr.Name.Space == declaration.namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// wrongNamespace describes an element or attribute found outside of the namespace it is declared in.
+func wrongNamespace(kind, name, namespace string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s '%s' must not be in a namespace", kind, name)
+	}
+	return fmt.Sprintf("%s '%s' must be in namespace '%s'", kind, name, namespace)
+}
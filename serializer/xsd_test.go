@@ -0,0 +1,207 @@
+package serializer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// xsdFor wraps declarations in a schema.
+func xsdFor(attrs, declarations string) string {
+	return `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" ` + attrs + `>` + declarations + `</xs:schema>`
+}
+
+func TestXMLSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		errors []string // Expected messages; none when the document is valid
+	}{
+		{
+			name:   "long in range",
+			schema: xsdFor("", `<xs:element name="n" type="xs:long"/>`),
+			doc:    `<n>9223372036854775807</n>`,
+		},
+		{
+			name:   "long above range",
+			schema: xsdFor("", `<xs:element name="n" type="xs:long"/>`),
+			doc:    `<n>9223372036854775808</n>`,
+			errors: []string{"value '9223372036854775808' is out of range"},
+		},
+		{
+			name:   "unsignedLong in range",
+			schema: xsdFor("", `<xs:element name="n" type="xs:unsignedLong"/>`),
+			doc:    `<n>18446744073709551615</n>`,
+		},
+		{
+			name:   "unsignedLong above range",
+			schema: xsdFor("", `<xs:element name="n" type="xs:unsignedLong"/>`),
+			doc:    `<n>18446744073709551616</n>`,
+			errors: []string{"value '18446744073709551616' is out of range"},
+		},
+		{
+			name:   "negative unsignedLong",
+			schema: xsdFor("", `<xs:element name="n" type="xs:unsignedLong"/>`),
+			doc:    `<n>-1</n>`,
+			errors: []string{"value '-1' is out of range"},
+		},
+		{
+			name: "decimal facet compared exactly",
+			schema: xsdFor("", `<xs:element name="n"><xs:simpleType><xs:restriction base="xs:decimal">
+				<xs:maxInclusive value="0.30000000000000000001"/></xs:restriction></xs:simpleType></xs:element>`),
+			doc:    `<n>0.30000000000000000002</n>`,
+			errors: []string{"value must be at most 0.30000000000000000001"},
+		},
+		{
+			name: "integer facet compared exactly",
+			schema: xsdFor("", `<xs:element name="n"><xs:simpleType><xs:restriction base="xs:integer">
+				<xs:maxExclusive value="9007199254740993"/></xs:restriction></xs:simpleType></xs:element>`),
+			doc:    `<n>9007199254740993</n>`,
+			errors: []string{"value must be less than 9007199254740993"},
+		},
+		{
+			name: "date facet",
+			schema: xsdFor("", `<xs:element name="d"><xs:simpleType><xs:restriction base="xs:date">
+				<xs:minInclusive value="2024-01-01"/></xs:restriction></xs:simpleType></xs:element>`),
+			doc: `<d>2024-06-30</d>`,
+		},
+		{
+			name: "date before facet",
+			schema: xsdFor("", `<xs:element name="d"><xs:simpleType><xs:restriction base="xs:date">
+				<xs:minInclusive value="2024-01-01"/></xs:restriction></xs:simpleType></xs:element>`),
+			doc:    `<d>2023-12-31</d>`,
+			errors: []string{"value must be at least 2024-01-01"},
+		},
+		{
+			name: "dateTime facet",
+			schema: xsdFor("", `<xs:element name="d"><xs:simpleType><xs:restriction base="xs:dateTime">
+				<xs:maxExclusive value="2024-01-01T00:00:00Z"/></xs:restriction></xs:simpleType></xs:element>`),
+			doc: `<d>2024-01-01T01:00:00+02:00</d>`,
+		},
+		{
+			name: "double facet rejects NaN",
+			schema: xsdFor("", `<xs:element name="n"><xs:simpleType><xs:restriction base="xs:double">
+				<xs:minInclusive value="0"/></xs:restriction></xs:simpleType></xs:element>`),
+			doc:    `<n>NaN</n>`,
+			errors: []string{"value must be at least 0"},
+		},
+		{
+			name:   "target namespace",
+			schema: xsdFor(`targetNamespace="urn:a" elementFormDefault="qualified"`, `<xs:element name="a"><xs:complexType><xs:sequence><xs:element name="b" type="xs:string"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<a xmlns="urn:a"><b>x</b></a>`,
+		},
+		{
+			name:   "root outside target namespace",
+			schema: xsdFor(`targetNamespace="urn:a"`, `<xs:element name="a" type="xs:string"/>`),
+			doc:    `<a xmlns="urn:b">x</a>`,
+			errors: []string{"element 'a' must be in namespace 'urn:a'"},
+		},
+		{
+			name:   "unqualified local element",
+			schema: xsdFor(`targetNamespace="urn:a"`, `<xs:element name="a"><xs:complexType><xs:sequence><xs:element name="b" type="xs:string"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<t:a xmlns:t="urn:a"><b>x</b></t:a>`,
+		},
+		{
+			name:   "qualified local element without namespace",
+			schema: xsdFor(`targetNamespace="urn:a" elementFormDefault="qualified"`, `<xs:element name="a"><xs:complexType><xs:sequence><xs:element name="b" type="xs:string"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<t:a xmlns:t="urn:a"><b>x</b></t:a>`,
+			errors: []string{"missing element 'b'", "element 'b' must be in namespace 'urn:a'"},
+		},
+		{
+			name:   "qualified attribute",
+			schema: xsdFor(`targetNamespace="urn:a"`, `<xs:element name="a"><xs:complexType><xs:attribute name="id" form="qualified" use="required"/></xs:complexType></xs:element>`),
+			doc:    `<t:a xmlns:t="urn:a" id="1"/>`,
+			errors: []string{"attribute 'id' must be in namespace 'urn:a'", "missing attribute 'id'"},
+		},
+		{
+			name:   "repeated sequence",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:sequence minOccurs="2" maxOccurs="3"><xs:element name="k"/><xs:element name="v"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<r><k/><v/><k/><v/></r>`,
+		},
+		{
+			name:   "sequence below minOccurs",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:sequence minOccurs="2" maxOccurs="3"><xs:element name="k"/><xs:element name="v"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<r><k/><v/></r>`,
+			errors: []string{"missing element 'k'", "missing element 'v'"},
+		},
+		{
+			name:   "sequence above maxOccurs",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:sequence maxOccurs="2"><xs:element name="k"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<r><k/><k/><k/></r>`,
+			errors: []string{"unexpected element 'k'"},
+		},
+		{
+			name:   "incomplete repetition",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:sequence maxOccurs="unbounded"><xs:element name="k"/><xs:element name="v"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<r><k/><v/><k/></r>`,
+			errors: []string{"missing element 'v'"},
+		},
+		{
+			name:   "element maxOccurs in sequence",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:sequence><xs:element name="k" maxOccurs="2"/></xs:sequence></xs:complexType></xs:element>`),
+			doc:    `<r><k/><k/><k/></r>`,
+			errors: []string{"unexpected element 'k'"},
+		},
+		{
+			name:   "choice above maxOccurs",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:choice maxOccurs="2"><xs:element name="a"/><xs:element name="b"/></xs:choice></xs:complexType></xs:element>`),
+			doc:    `<r><a/><b/><a/></r>`,
+			errors: []string{"unexpected element 'a'"},
+		},
+		{
+			name:   "single choice",
+			schema: xsdFor("", `<xs:element name="r"><xs:complexType><xs:choice><xs:element name="a"/><xs:element name="b"/></xs:choice></xs:complexType></xs:element>`),
+			doc:    `<r><a/><b/></r>`,
+			errors: []string{"unexpected element 'b'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseXSD([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("ParseXSD() error = %v", err)
+			}
+			err = schema.Validate([]byte(tt.doc))
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			var xsdErr *XSDValidationError
+			if !errors.As(err, &xsdErr) {
+				t.Fatalf("Validate() error = %v, want an XSD validation error", err)
+			}
+			var got []string
+			for _, e := range xsdErr.Errors {
+				got = append(got, e.Message)
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.errors, "; ") {
+				t.Errorf("Validate() errors = %q, want %q", got, tt.errors)
+			}
+		})
+	}
+}
+
+func TestParseXSDInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"not a schema", `<element/>`, "expected <schema>"},
+		{"range facet on string", xsdFor("", `<xs:simpleType name="s"><xs:restriction base="xs:string"><xs:minInclusive value="a"/></xs:restriction></xs:simpleType>`), "not ordered"},
+		{"facet outside the type", xsdFor("", `<xs:simpleType name="s"><xs:restriction base="xs:date"><xs:minInclusive value="2024-13-01"/></xs:restriction></xs:simpleType>`), "minInclusive"},
+		{"unknown type", xsdFor("", `<xs:element name="a" type="xs:unknown"/>`), "unknown type"},
+		{"invalid maxOccurs", xsdFor("", `<xs:element name="a"><xs:complexType><xs:sequence maxOccurs="x"/></xs:complexType></xs:element>`), "invalid maxOccurs"},
+		{"unsupported construct", xsdFor("", `<xs:import namespace="urn:b"/>`), "unsupported construct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseXSD([]byte(tt.schema)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseXSD() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SerializeToYAMLStream serializes every element of a slice or array like SerializeMany and
+// encodes the results as a multi-document YAML stream, one document per element separated by
+// "---" lines, as used for Kubernetes-style manifests.
+func (s *BaseSerializer) SerializeToYAMLStream(items interface{}) (string, error) {
+	results, err := s.SerializeMany(items)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	for i, result := range results {
+		encoded, err := EncodeOrdered(result, FormatYAML, s.Order)
+		if err != nil {
+			return "", &BulkError{Index: i, Err: err}
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(encoded)
+	}
+	return buf.String(), nil
+}
+
+// DecodeYAMLStream parses a multi-document YAML stream into one map per document, like
+// DecodeYAML. Empty documents are skipped.
+func (s *BaseSerializer) DecodeYAMLStream(data []byte) ([]map[string]interface{}, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var documents []map[string]interface{}
+	for {
+		var document yaml.Node
+		err := dec.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, &SerializationError{Message: fmt.Sprintf("failed to parse YAML: %v", err), Err: err}
+		}
+
+		value, err := (&yamlDecoder{policy: s.DuplicateKeys}).decode(&document, "", false)
+		if err != nil {
+			return nil, &BulkError{Index: len(documents), Err: err}
+		}
+		if value == nil {
+			continue
+		}
+		input, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, &BulkError{Index: len(documents), Err: &SerializationError{Message: "failed to parse YAML: document is not a mapping"}}
+		}
+		documents = append(documents, input)
+	}
+}
+
+// DeserializeYAMLStream decodes a multi-document YAML stream and deserializes each document into
+// a new element appended to the slice out points to.
+// Failures are reported as a *BulkError carrying the index of the document.
+func (s *BaseSerializer) DeserializeYAMLStream(data []byte, out interface{}) error {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return &SerializationError{Message: "DeserializeYAMLStream expects a pointer to a slice"}
+	}
+	slice = slice.Elem()
+
+	documents, err := s.DecodeYAMLStream(data)
+	if err != nil {
+		return err
+	}
+	elemType := slice.Type().Elem()
+	for i, document := range documents {
+		var elem reflect.Value
+		if elemType.Kind() == reflect.Ptr {
+			elem = reflect.New(elemType.Elem())
+		} else {
+			elem = reflect.New(elemType)
+		}
+		if err := s.Deserialize(document, elem.Interface()); err != nil {
+			return &BulkError{Index: i, Err: err}
+		}
+		if elemType.Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return nil
+}
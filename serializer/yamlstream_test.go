@@ -0,0 +1,97 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSerializeToYAMLStream(t *testing.T) {
+	s := &BaseSerializer{Fields: []string{"id", "name"}, Order: []string{"name"}}
+	tests := []struct {
+		name  string
+		items interface{}
+		want  string
+	}{
+		{"documents", []account{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, "name: a\nid: 1\n---\nname: b\nid: 2\n"},
+		{"single", []account{{ID: 1, Name: "a"}}, "name: a\nid: 1\n"},
+		{"empty", []account{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.SerializeToYAMLStream(tt.items)
+			if err != nil {
+				t.Fatalf("SerializeToYAMLStream() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SerializeToYAMLStream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeYAMLStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		want      []map[string]interface{}
+		wantIndex int // Index of the BulkError, or -1
+		wantErr   bool
+	}{
+		{"documents", "id: 1\n---\nid: 2\n", []map[string]interface{}{{"id": 1}, {"id": 2}}, -1, false},
+		{"empty documents skipped", "---\n---\nid: 1\n---\n", []map[string]interface{}{{"id": 1}}, -1, false},
+		{"empty stream", "", nil, -1, false},
+		{"not a mapping", "id: 1\n---\n- a\n", nil, 1, true},
+		{"duplicate key", "id: 1\n---\nid: 1\nid: 2\n", nil, 1, true},
+		{"syntax error", "id: [\n", nil, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&BaseSerializer{DuplicateKeys: DuplicateKeysError}).DecodeYAMLStream([]byte(tt.data))
+			if tt.wantErr {
+				var bulkErr *BulkError
+				if tt.wantIndex >= 0 && (!errors.As(err, &bulkErr) || bulkErr.Index != tt.wantIndex) {
+					t.Errorf("DecodeYAMLStream() error = %v, want a BulkError of document %d", err, tt.wantIndex)
+				}
+				if err == nil {
+					t.Errorf("DecodeYAMLStream() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeYAMLStream() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeYAMLStream() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserializeYAMLStream(t *testing.T) {
+	s := &BaseSerializer{}
+	data := []byte("id: 1\nname: a\n---\nid: 2\nname: b\n")
+
+	var values []account
+	if err := s.DeserializeYAMLStream(data, &values); err != nil {
+		t.Fatalf("DeserializeYAMLStream() error = %v", err)
+	}
+	if want := []account{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}; !reflect.DeepEqual(values, want) {
+		t.Errorf("DeserializeYAMLStream() = %+v, want %+v", values, want)
+	}
+	pointers := []*account{{ID: 9}}
+	if err := s.DeserializeYAMLStream(data, &pointers); err != nil || len(pointers) != 3 || pointers[2].Name != "b" {
+		t.Errorf("DeserializeYAMLStream() into pointers = %v, %v, want the documents appended", pointers, err)
+	}
+
+	var bulkErr *BulkError
+	if err := s.DeserializeYAMLStream([]byte("id: 1\n---\nid: x\n"), &values); !errors.As(err, &bulkErr) || bulkErr.Index != 1 {
+		t.Errorf("DeserializeYAMLStream() error = %v, want a BulkError of document 1", err)
+	}
+	for _, out := range []interface{}{values, &account{}, (*[]account)(nil)} {
+		if err := s.DeserializeYAMLStream(data, out); err == nil || !strings.Contains(err.Error(), "pointer to a slice") {
+			t.Errorf("DeserializeYAMLStream(%T) error = %v, want a pointer to a slice error", out, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package snappycompressor compresses payloads in the snappy block format with
+// github.com/golang/snappy. Importing it registers the "snappy" compression with
+// serializer.RegisterCompressor, so SerializeCompressed and DeserializeCompressed accept
+// CompressionSnappy; packages that don't import it don't depend on golang/snappy.
+package snappycompressor
+
+import (
+	"fmt"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"github.com/golang/snappy"
+)
+
+// CompressionSnappy is the compression registered by the package.
+const CompressionSnappy serializer.Compression = "snappy"
+
+func init() {
+	serializer.RegisterCompressor(CompressionSnappy, Compressor{})
+}
+
+// Compressor is the snappy serializer.Compressor. Decompress refuses payloads larger than
+// serializer.MaxDecompressedSize.
+type Compressor struct{}
+
+// Compress compresses data as a snappy block.
+func (Compressor) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+// Decompress decompresses a snappy block.
+func (Compressor) Decompress(data []byte) ([]byte, error) {
+	// The decoded length is read from the header, so oversized payloads are refused before allocating
+	size, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if int64(size) > serializer.MaxDecompressedSize {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", serializer.MaxDecompressedSize)
+	}
+	return snappy.Decode(nil, data)
+}
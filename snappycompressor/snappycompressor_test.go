@@ -0,0 +1,55 @@
+package snappycompressor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alun-dra/bserializer/serializer"
+)
+
+type session struct {
+	User  string `json:"user"`
+	Token string `json:"token"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := &serializer.BaseSerializer{}
+	in := session{User: strings.Repeat("Ana ", 100), Token: "abc"}
+	blob, err := s.SerializeCompressed(in, serializer.FormatJSON, CompressionSnappy)
+	if err != nil {
+		t.Fatalf("SerializeCompressed() error = %v", err)
+	}
+	if len(blob) >= len(in.User) {
+		t.Errorf("SerializeCompressed() wrote %d bytes, want fewer than %d", len(blob), len(in.User))
+	}
+	var out session
+	if err := s.DeserializeCompressed(blob, serializer.FormatJSON, CompressionSnappy, &out); err != nil {
+		t.Fatalf("DeserializeCompressed() error = %v", err)
+	}
+	if out != in {
+		t.Errorf("DeserializeCompressed() = %+v, want %+v", out, in)
+	}
+	if err := s.DeserializeCompressed([]byte("not compressed"), serializer.FormatJSON, CompressionSnappy, &out); err == nil {
+		t.Error("DeserializeCompressed() of garbage error = nil, want an error")
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	defer func(size int64) { serializer.MaxDecompressedSize = size }(serializer.MaxDecompressedSize)
+	payload := bytes.Repeat([]byte("a"), 4096)
+	var compressor serializer.Compressor = Compressor{}
+	compressed, err := compressor.Compress(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serializer.MaxDecompressedSize = int64(len(payload))
+	if _, err := compressor.Decompress(compressed); err != nil {
+		t.Errorf("Decompress() at the limit error = %v", err)
+	}
+	serializer.MaxDecompressedSize = int64(len(payload)) - 1
+	if _, err := compressor.Decompress(compressed); err == nil {
+		t.Error("Decompress() over the limit error = nil, want an error")
+	}
+}
@@ -0,0 +1,60 @@
+// Package zstdcompressor compresses payloads as zstd frames (RFC 8878) with the zstd package of
+// github.com/klauspost/compress. Importing it registers the "zstd" compression with
+// serializer.RegisterCompressor, so SerializeCompressed and DeserializeCompressed accept
+// CompressionZstd; packages that don't import it don't depend on klauspost/compress.
+package zstdcompressor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/alun-dra/bserializer/serializer"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionZstd is the compression registered by the package.
+const CompressionZstd serializer.Compression = "zstd"
+
+func init() {
+	serializer.RegisterCompressor(CompressionZstd, &Compressor{})
+}
+
+// Compressor is the zstd serializer.Compressor. Its encoder is created on first use and shared, as
+// EncodeAll is safe for concurrent use. Decompress refuses payloads larger than
+// serializer.MaxDecompressedSize.
+type Compressor struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	err     error
+}
+
+// Compress compresses data into a single zstd frame.
+func (c *Compressor) Compress(data []byte) ([]byte, error) {
+	c.once.Do(func() {
+		c.encoder, c.err = zstd.NewWriter(nil)
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+// Decompress decompresses the zstd frames of data.
+func (c *Compressor) Decompress(data []byte) ([]byte, error) {
+	limit := serializer.MaxDecompressedSize
+	r, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	decompressed, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decompressed)) > limit {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", limit)
+	}
+	return decompressed, nil
+}